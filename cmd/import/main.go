@@ -0,0 +1,72 @@
+// Command import upserts products by SKU from a CSV file; see
+// services.CSVColumns for the columns.
+//
+// Usage:
+//
+//	DB_URL=... go run ./cmd/import [-dry-run] [-principal name] products.csv
+//
+// It prints the skipped rows to stderr and exits with status 1 if there
+// are any. Hooks registered by the server do not run.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/andro-kes/inventory_service/internal/auth"
+	"github.com/andro-kes/inventory_service/internal/services"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "validate the rows without writing them")
+	principal := flag.String("principal", "import", "the principal recorded as created_by and updated_by")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: import [-dry-run] [-principal name] products.csv")
+		os.Exit(2)
+	}
+
+	dbURL := os.Getenv("DB_URL")
+	if dbURL == "" {
+		fmt.Fprintln(os.Stderr, "DB_URL is not found")
+		os.Exit(2)
+	}
+
+	ctx := auth.NewContext(context.Background(), *principal)
+	pool, err := pgxpool.New(ctx, dbURL)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer pool.Close()
+
+	report, err := run(ctx, pool, flag.Arg(0), *dryRun)
+	if report != nil {
+		for _, e := range report.Errors {
+			fmt.Fprintln(os.Stderr, e)
+		}
+		fmt.Printf("read %d row(s), imported %d, skipped %d\n", report.Rows, report.Imported, len(report.Errors))
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		pool.Close()
+		os.Exit(1)
+	}
+	if len(report.Errors) > 0 {
+		pool.Close()
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, pool *pgxpool.Pool, path string, dryRun bool) (*services.ImportReport, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return services.NewProductService(ctx, pool).ImportCSV(ctx, f, dryRun)
+}
@@ -0,0 +1,76 @@
+// Command migrate manages the database schema.
+//
+// Usage:
+//
+//	DB_URL=... go run ./cmd/migrate up|down|status
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/andro-kes/inventory_service/internal/migrations"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: migrate up|down|status")
+		os.Exit(2)
+	}
+
+	dbURL := os.Getenv("DB_URL")
+	if dbURL == "" {
+		fmt.Fprintln(os.Stderr, "DB_URL is not found")
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, dbURL)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer pool.Close()
+
+	if err := run(ctx, pool, os.Args[1]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		pool.Close()
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, pool *pgxpool.Pool, command string) error {
+	m, err := migrations.New(pool)
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "up":
+		applied, err := m.Up(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("applied %d migration(s)\n", applied)
+	case "down":
+		return m.Down(ctx)
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%s\t%s\n", s.Version, s.Name, applied)
+		}
+	default:
+		return fmt.Errorf("unknown command %q", command)
+	}
+
+	return nil
+}
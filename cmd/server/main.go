@@ -2,22 +2,62 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/andro-kes/inventory_service/internal/auth"
+	"github.com/andro-kes/inventory_service/internal/buildinfo"
+	"github.com/andro-kes/inventory_service/internal/certs"
+	"github.com/andro-kes/inventory_service/internal/events"
 	"github.com/andro-kes/inventory_service/internal/inverr"
 	"github.com/andro-kes/inventory_service/internal/logger"
+	"github.com/andro-kes/inventory_service/internal/metrics"
+	"github.com/andro-kes/inventory_service/internal/migrations"
+	"github.com/andro-kes/inventory_service/internal/orders"
+	"github.com/andro-kes/inventory_service/internal/repo"
 	"github.com/andro-kes/inventory_service/internal/rpc"
+	"github.com/andro-kes/inventory_service/internal/scheduler"
+	"github.com/andro-kes/inventory_service/internal/services"
 	pb "github.com/andro-kes/inventory_service/proto"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/encoding"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 )
 
 func main() {
+	version := flag.Bool("version", false, "print the build info and exit")
+	flag.Parse()
+	if *version {
+		fmt.Println(buildinfo.String(buildinfo.Get()))
+		return
+	}
+
 	cfg := logger.Config{
 		Level:        "debug",
 		Encoding:     "console",
@@ -33,7 +73,8 @@ func main() {
 	}
 	defer zl.Sync()
 
-	zl.Info("Start inventory service...")
+	info := buildinfo.Get()
+	zl.Info("Start inventory service...", zap.String("version", info.GetVersion()), zap.String("commit", info.GetCommit()))
 
 	dbURL := os.Getenv("DB_URL")
 	if dbURL == "" {
@@ -42,6 +83,19 @@ func main() {
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
+	// workers run until ctx is cancelled on shutdown.
+	var workers rpc.Workers
+
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" {
+		tp, err := NewTracerProvider(ctx)
+		if err != nil {
+			panic("failed to init tracing: " + err.Error())
+		}
+		defer tp.Shutdown(context.Background())
+		otel.SetTracerProvider(tp)
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+		zl.Info("tracing enabled")
+	}
 
 	pool, err := NewPool(ctx, zl, dbURL)
 	if err != nil {
@@ -49,6 +103,12 @@ func main() {
 	}
 	defer pool.Close()
 
+	if migrate, _ := strconv.ParseBool(os.Getenv("MIGRATE_ON_START")); migrate {
+		if err := Migrate(ctx, zl, pool); err != nil {
+			panic(err.Error())
+		}
+	}
+
 	addr := os.Getenv("GRPC_ADDR")
 	if addr == "" {
 		panic("GRPC_ADDR must be set")
@@ -58,10 +118,317 @@ func main() {
 		panic("listen error: " + err.Error())
 	}
 
-	grpcServer := grpc.NewServer()
-	inventoryService := rpc.NewInventoryService(ctx, pool)
+	authenticate := auth.HeaderAuthenticator(os.Getenv("AUTH_HEADER"))
+	if keysFile := os.Getenv("API_KEYS_FILE"); keysFile != "" {
+		keys, err := LoadAPIKeys(keysFile)
+		if err != nil {
+			panic("failed to load API keys: " + err.Error())
+		}
+		authenticate = auth.FirstOf(auth.APIKeyAuthenticator(keys), authenticate)
+		zl.Info("API key authentication enabled", zap.Int("keys", len(keys)))
+	}
+	timeouts := rpc.DefaultTimeouts
+	if v := os.Getenv("RPC_TIMEOUTS"); v != "" {
+		var err error
+		if timeouts, err = rpc.ParseTimeouts(v); err != nil {
+			panic("invalid RPC_TIMEOUTS: " + err.Error())
+		}
+	}
+	level := 0
+	if v := os.Getenv("GRPC_COMPRESSION_LEVEL"); v != "" {
+		var err error
+		if level, err = strconv.Atoi(v); err != nil {
+			panic("invalid GRPC_COMPRESSION_LEVEL: " + err.Error())
+		}
+	}
+	if err := rpc.RegisterCompressors(level); err != nil {
+		panic("invalid GRPC_COMPRESSION_LEVEL: " + err.Error())
+	}
+	unary := []grpc.UnaryServerInterceptor{
+		timeouts.UnaryInterceptor(),
+		auth.UnaryServerInterceptor(authenticate),
+		rpc.UnaryErrorInterceptor(),
+	}
+	stream := []grpc.StreamServerInterceptor{auth.StreamServerInterceptor(authenticate), rpc.StreamErrorInterceptor()}
+	if compressor := os.Getenv("GRPC_COMPRESSION"); compressor != "" {
+		if encoding.GetCompressor(compressor) == nil {
+			panic("unknown GRPC_COMPRESSION: " + compressor)
+		}
+		unary = append(unary, rpc.UnaryCompressionInterceptor(compressor))
+		stream = append(stream, rpc.StreamCompressionInterceptor(compressor))
+		zl.Info("response compression enabled", zap.String("compressor", compressor), zap.Int("level", level))
+	}
+	keepalive, err := LoadKeepalive()
+	if err != nil {
+		panic("invalid keepalive configuration: " + err.Error())
+	}
+	serverOpts := []grpc.ServerOption{
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	}
+	serverOpts = append(serverOpts, keepalive.ServerOptions()...)
+	for name, option := range map[string]func(int) grpc.ServerOption{
+		"GRPC_MAX_RECV_MSG_SIZE": grpc.MaxRecvMsgSize,
+		"GRPC_MAX_SEND_MSG_SIZE": grpc.MaxSendMsgSize,
+	} {
+		if v := os.Getenv(name); v != "" {
+			size, err := strconv.Atoi(v)
+			if err != nil || size <= 0 {
+				panic("invalid " + name + ": " + v)
+			}
+			serverOpts = append(serverOpts, option(size))
+		}
+	}
+	if certFile := os.Getenv("TLS_CERT_FILE"); certFile != "" {
+		keyFile := os.Getenv("TLS_KEY_FILE")
+		if keyFile == "" {
+			panic("TLS_KEY_FILE is required with TLS_CERT_FILE")
+		}
+		interval := 30 * time.Second
+		if v := os.Getenv("TLS_RELOAD_INTERVAL"); v != "" {
+			if interval, err = time.ParseDuration(v); err != nil || interval <= 0 {
+				panic("invalid TLS_RELOAD_INTERVAL: " + v)
+			}
+		}
+		reloader, err := certs.NewReloader(certFile, keyFile, os.Getenv("TLS_CLIENT_CA_FILE"))
+		if err != nil {
+			panic("failed to load TLS certificates: " + err.Error())
+		}
+		reloader.OnReload = func() {
+			zl.Info("TLS certificates reloaded", zap.String("cert", certFile))
+		}
+		workers.Go(func() {
+			reloader.Run(ctx, interval, func(err error) {
+				zl.Warn("failed to reload TLS certificates", zap.Error(err))
+			})
+		})
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(reloader.TLSConfig())))
+		zl.Info("TLS enabled", zap.Bool("mtls", os.Getenv("TLS_CLIENT_CA_FILE") != ""), zap.Duration("reload_interval", interval))
+	} else if plaintext, _ := strconv.ParseBool(os.Getenv("GRPC_ALLOW_PLAINTEXT")); plaintext {
+		zl.Warn("TLS disabled, serving plaintext gRPC")
+	} else {
+		panic("TLS_CERT_FILE is not set; set GRPC_ALLOW_PLAINTEXT=true to serve without TLS")
+	}
+	grpcServer := grpc.NewServer(serverOpts...)
+	var repoOpts []repo.Option
+	if timeout := os.Getenv("STATEMENT_TIMEOUT"); timeout != "" {
+		d, err := time.ParseDuration(timeout)
+		if err != nil {
+			panic("invalid STATEMENT_TIMEOUT: " + err.Error())
+		}
+		repoOpts = append(repoOpts, repo.WithStatementTimeout(d))
+	}
+
+	productService := services.NewProductService(ctx, pool, repoOpts...)
+	// Extensions of the product writes are registered on
+	// productService.Hooks here; see services.Hooks.
+	if brokers := os.Getenv("KAFKA_BROKERS"); brokers != "" {
+		writer, err := NewEventWriter(strings.Split(brokers, ","))
+		if err != nil {
+			panic("failed to init event publishing: " + err.Error())
+		}
+		topics := events.DefaultTopics
+		if v := os.Getenv("KAFKA_TOPIC_PRODUCTS"); v != "" {
+			topics.Products = v
+		}
+		if v := os.Getenv("KAFKA_TOPIC_STOCK"); v != "" {
+			topics.Stock = v
+		}
+		buffer := 1000
+		if v := os.Getenv("EVENTS_BUFFER"); v != "" {
+			if buffer, err = strconv.Atoi(v); err != nil || buffer <= 0 {
+				panic("invalid EVENTS_BUFFER: " + v)
+			}
+		}
+		publisher := events.NewPublisher(writer, topics, buffer)
+		publisher.OnError = func(err error) {
+			zl.Warn("failed to publish events", zap.Error(err))
+		}
+		// Run outlives ctx to flush the events of drained RPCs.
+		workers.Go(func() {
+			publisher.Run(context.Background())
+			_ = writer.Close()
+		})
+		productService.Events = publisher
+		zl.Info("event publishing enabled", zap.String("products_topic", topics.Products), zap.String("stock_topic", topics.Stock))
+	}
+
+	if group := os.Getenv("KAFKA_CONSUMER_GROUP"); group != "" {
+		brokers := os.Getenv("KAFKA_BROKERS")
+		if brokers == "" {
+			panic("KAFKA_BROKERS is required with KAFKA_CONSUMER_GROUP")
+		}
+		topics := orders.DefaultTopics
+		if v := os.Getenv("KAFKA_TOPIC_ORDER_PLACED"); v != "" {
+			topics.Placed = v
+		}
+		if v := os.Getenv("KAFKA_TOPIC_ORDER_CANCELLED"); v != "" {
+			topics.Cancelled = v
+		}
+		if v := os.Getenv("KAFKA_TOPIC_ORDERS_DLQ"); v != "" {
+			topics.DeadLetter = v
+		}
+		reader, err := NewOrderReader(strings.Split(brokers, ","), group, []string{topics.Placed, topics.Cancelled})
+		if err != nil {
+			panic("failed to init order events: " + err.Error())
+		}
+		dlq, err := NewEventWriter(strings.Split(brokers, ","))
+		if err != nil {
+			panic("failed to init order events: " + err.Error())
+		}
+		consumer := orders.NewConsumer(reader, productService, dlq, topics)
+		consumer.OnError = func(msg orders.Message, err error) {
+			zl.Warn("failed to handle order event", zap.String("topic", msg.Topic),
+				zap.Int("partition", msg.Partition), zap.Int64("offset", msg.Offset), zap.Error(err))
+		}
+		workers.Go(func() {
+			if err := consumer.Run(ctx); err != nil && ctx.Err() == nil {
+				zl.Error("order events consumer stopped", zap.Error(err))
+			}
+			_ = reader.Close()
+			_ = dlq.Close()
+		})
+		zl.Info("order events consumer enabled", zap.String("group", group),
+			zap.String("placed_topic", topics.Placed), zap.String("cancelled_topic", topics.Cancelled))
+	}
+
+	if v := os.Getenv("IDEMPOTENCY_KEY_TTL"); v != "" {
+		if productService.IdempotencyTTL, err = time.ParseDuration(v); err != nil || productService.IdempotencyTTL <= 0 {
+			panic("invalid IDEMPOTENCY_KEY_TTL: " + v)
+		}
+	}
+
+	baseCurrency := repo.DefaultCurrency
+	if v := os.Getenv("BASE_CURRENCY"); v != "" {
+		baseCurrency = v
+	}
+	ratesTTL := envDuration("EXCHANGE_RATES_TTL", 10*time.Minute)
+	rates := services.NewCachedRates(NewRateProvider(ctx, pool, baseCurrency), ratesTTL)
+	rates.OnError = func(err error) {
+		zl.Warn("failed to refresh exchange rates, serving the previous ones", zap.Error(err))
+	}
+	productService.Currency = services.NewConverter(baseCurrency, rates)
+
+	// Maintenance jobs run on every replica; their batches lock rows with
+	// SKIP LOCKED or are idempotent, so replicas do not conflict.
+	sched := scheduler.New()
+	sched.OnError = func(job string, err error) {
+		zl.Warn("scheduled job failed", zap.String("job", job), zap.Error(err))
+	}
+	addJob := func(name string, interval time.Duration, run func(ctx context.Context) error) {
+		// Jitter spreads the runs of replicas started together.
+		if err := sched.Add(scheduler.Job{Name: name, Interval: interval, Jitter: interval / 10, Run: run}); err != nil {
+			panic(err.Error())
+		}
+	}
+	logCount := func(msg string, n int64) {
+		if n > 0 {
+			zl.Info(msg, zap.Int64("count", n))
+		}
+	}
+	addJob("release_expired_reservations", envDuration("RESERVATION_EXPIRY_INTERVAL", time.Minute), func(ctx context.Context) error {
+		n, err := productService.ReleaseExpired(ctx, 500)
+		logCount("expired reservations released", n)
+		return err
+	})
+	addJob("purge_idempotency_keys", envDuration("IDEMPOTENCY_CLEANUP_INTERVAL", 10*time.Minute), func(ctx context.Context) error {
+		n, err := productService.PurgeIdempotencyKeys(ctx, 1000)
+		logCount("expired idempotency keys purged", n)
+		return err
+	})
+	addJob("refresh_exchange_rates", envDuration("EXCHANGE_RATES_REFRESH_INTERVAL", ratesTTL/2), rates.Refresh)
+	if os.Getenv("PURGE_DELETED_AFTER") != "" {
+		retention := envDuration("PURGE_DELETED_AFTER", 0)
+		addJob("purge_deleted_products", envDuration("PURGE_INTERVAL", time.Hour), func(ctx context.Context) error {
+			n, err := productService.Purge(ctx, retention)
+			logCount("deleted products purged", n)
+			return err
+		})
+	}
+	if os.Getenv("STOCK_SNAPSHOT_INTERVAL") != "" {
+		addJob("snapshot_stock", envDuration("STOCK_SNAPSHOT_INTERVAL", 0), func(ctx context.Context) error {
+			takenAt, n, err := productService.SnapshotStock(ctx)
+			if err == nil {
+				zl.Info("stock snapshot taken", zap.Time("taken_at", takenAt), zap.Int64("products", n))
+			}
+			return err
+		})
+	}
+	reorderPolicy := repo.ReorderPolicy{
+		Window: envDuration("REORDER_OUTFLOW_WINDOW", repo.DefaultReorderPolicy.Window),
+		Cover:  envDuration("REORDER_COVER", repo.DefaultReorderPolicy.Cover),
+	}
+	addJob("refresh_reorder_suggestions", envDuration("REORDER_SUGGESTIONS_INTERVAL", time.Hour), func(ctx context.Context) error {
+		n, err := productService.RefreshReorderSuggestions(ctx, reorderPolicy)
+		if err == nil {
+			zl.Info("reorder suggestions refreshed", zap.Int64("products", n))
+		}
+		return err
+	})
+	prometheus.MustRegister(sched)
+	workers.Go(func() { sched.Run(ctx) })
+
+	// One LISTEN connection feeds all WatchProducts streams and the cache.
+	changes := repo.NewChangeHub()
+	handleChange := changes.Publish
+	var service rpc.ProductService = productService
+	store, closeStore, err := NewCacheStore(os.Getenv("CACHE_BACKEND"))
+	if err != nil {
+		panic("invalid cache configuration: " + err.Error())
+	}
+	if store != nil {
+		defer closeStore()
+		ttl, listTTL := time.Minute, 5*time.Second
+		for name, d := range map[string]*time.Duration{"CACHE_TTL": &ttl, "CACHE_LIST_TTL": &listTTL} {
+			if v := os.Getenv(name); v != "" {
+				if *d, err = time.ParseDuration(v); err != nil || *d <= 0 {
+					panic("invalid " + name + ": " + v)
+				}
+			}
+		}
+		cached := services.NewCachedService(productService, store, ttl, listTTL)
+		handleChange = func(c repo.Change) {
+			cached.Invalidate(ctx, c)
+			changes.Publish(c)
+		}
+		service = cached
+		zl.Info("product cache enabled", zap.String("backend", fmt.Sprintf("%T", store)), zap.Duration("ttl", ttl), zap.Duration("list_ttl", listTTL))
+	}
+	inventoryService := rpc.NewInventoryService(service)
+	listener := repo.NewChangeListener(pool.Config().ConnConfig, handleChange)
+	workers.Go(func() { _ = listener.Run(ctx) })
+	inventoryService.Changes = changes
+
 	pb.RegisterInventoryServiceServer(grpcServer, inventoryService)
 
+	healthServer := rpc.NewHealth(pool)
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	workers.Go(func() { healthServer.Run(ctx, 10*time.Second) })
+
+	if enabled, _ := strconv.ParseBool(os.Getenv("GRPC_REFLECTION")); enabled {
+		reflection.Register(grpcServer)
+		zl.Info("gRPC reflection enabled")
+	}
+
+	if metricsAddr := os.Getenv("METRICS_ADDR"); metricsAddr != "" {
+		metricsServer := NewMetricsServer(metricsAddr)
+		go func() {
+			if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				zl.Error("metrics server failed", zap.Error(err))
+			}
+		}()
+		defer metricsServer.Close()
+		zl.Info("metrics enabled", zap.String("addr", metricsAddr))
+	}
+
+	drainTimeout := 30 * time.Second
+	if v := os.Getenv("DRAIN_TIMEOUT"); v != "" {
+		if drainTimeout, err = time.ParseDuration(v); err != nil || drainTimeout <= 0 {
+			panic("invalid DRAIN_TIMEOUT: " + v)
+		}
+	}
+
 	serveErr := make(chan error, 1)
 	go func() {
 		if err := grpcServer.Serve(listen); err != nil {
@@ -80,7 +447,323 @@ func main() {
 		panic("failed to start inventory service")
 	}
 
-	grpcServer.GracefulStop()
+	// Fail the probes first so that no new traffic is routed here.
+	healthServer.Shutdown()
+	// Watch streams never end on their own.
+	changes.Close()
+	deadline := time.Now().Add(drainTimeout)
+	if !rpc.Drain(grpcServer, drainTimeout) {
+		zl.Warn("in-flight RPCs cancelled after drain timeout", zap.Duration("timeout", drainTimeout))
+	}
+	productService.Events.Close()
+	cancel()
+	if !workers.Wait(time.Until(deadline)) {
+		zl.Warn("background workers did not stop before drain timeout")
+	}
+	zl.Info("Server stopped")
+}
+
+// Migrate applies the pending schema migrations.
+func Migrate(ctx context.Context, zl *zap.Logger, pool *pgxpool.Pool) error {
+	m, err := migrations.New(pool)
+	if err != nil {
+		return err
+	}
+
+	applied, err := m.Up(ctx)
+	if err != nil {
+		zl.Error("failed to apply migrations", zap.Error(err))
+		return err
+	}
+
+	zl.Info("migrations applied", zap.Int("count", applied))
+	return nil
+}
+
+// LoadAPIKeys reads the hashed API keys in the format of auth.ParseAPIKeys
+// from path, e.g. a mounted secret.
+func LoadAPIKeys(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return auth.ParseAPIKeys(f)
+}
+
+// NewCacheStore returns the store of the product cache for backend:
+// "memory", holding up to CACHE_MAX_ENTRIES keys, or "redis" at
+// REDIS_URL. An empty backend means redis if REDIS_URL is set; otherwise
+// the store is nil and nothing is cached. close releases the store.
+func NewCacheStore(backend string) (store services.CacheStore, close func(), err error) {
+	if backend == "" && os.Getenv("REDIS_URL") != "" {
+		backend = "redis"
+	}
+	switch backend {
+	case "":
+		return nil, nil, nil
+	case "memory":
+		maxEntries := 10000
+		if v := os.Getenv("CACHE_MAX_ENTRIES"); v != "" {
+			if maxEntries, err = strconv.Atoi(v); err != nil || maxEntries <= 0 {
+				return nil, nil, fmt.Errorf("invalid CACHE_MAX_ENTRIES: %s", v)
+			}
+		}
+		return services.NewMemoryStore(maxEntries), func() {}, nil
+	case "redis":
+		redisURL := os.Getenv("REDIS_URL")
+		if redisURL == "" {
+			return nil, nil, errors.New("REDIS_URL is required with CACHE_BACKEND=redis")
+		}
+		client, err := NewRedis(redisURL)
+		if err != nil {
+			return nil, nil, err
+		}
+		return services.NewRedisStore(client), func() { _ = client.Close() }, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown CACHE_BACKEND %q", backend)
+	}
+}
+
+// NewRateProvider returns the source of the exchange rates against base:
+// the JSON API at EXCHANGE_RATES_URL if set, see services.HTTPRates, and
+// the exchange_rates table otherwise.
+func NewRateProvider(ctx context.Context, pool *pgxpool.Pool, base string) services.RateProvider {
+	if url := os.Getenv("EXCHANGE_RATES_URL"); url != "" {
+		return &services.HTTPRates{URL: url, Base: base, Client: &http.Client{Timeout: 5 * time.Second}}
+	}
+	return repo.NewRateRepo(ctx, pool)
+}
+
+// NewRedis returns a client for the Redis server at redisURL, e.g.
+// redis://localhost:6379/0.
+func NewRedis(redisURL string) (*redis.Client, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, err
+	}
+	return redis.NewClient(opts), nil
+}
+
+// serviceName is the service.name of the spans unless OTEL_SERVICE_NAME
+// is set.
+const serviceName = "inventory-service"
+
+// NewTracerProvider returns a provider exporting spans over OTLP to the
+// collector configured by the OTEL_EXPORTER_OTLP_* variables, over gRPC
+// unless OTEL_EXPORTER_OTLP_PROTOCOL is http/protobuf. Sampling follows
+// OTEL_TRACES_SAMPLER and the parent span of incoming requests.
+func NewTracerProvider(ctx context.Context) (*sdktrace.TracerProvider, error) {
+	exporter, err := NewSpanExporter(ctx, os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL"))
+	if err != nil {
+		return nil, err
+	}
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(serviceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	), nil
+}
+
+// LoadKeepalive returns rpc.DefaultKeepalive overridden by the
+// GRPC_MAX_CONNECTION_* and GRPC_KEEPALIVE_* variables.
+func LoadKeepalive() (rpc.Keepalive, error) {
+	k := rpc.DefaultKeepalive
+	durations := map[string]*time.Duration{
+		"GRPC_MAX_CONNECTION_IDLE":      &k.MaxConnectionIdle,
+		"GRPC_MAX_CONNECTION_AGE":       &k.MaxConnectionAge,
+		"GRPC_MAX_CONNECTION_AGE_GRACE": &k.MaxConnectionAgeGrace,
+		"GRPC_KEEPALIVE_TIME":           &k.Time,
+		"GRPC_KEEPALIVE_TIMEOUT":        &k.Timeout,
+		"GRPC_KEEPALIVE_MIN_TIME":       &k.MinTime,
+	}
+	for name, d := range durations {
+		v := os.Getenv(name)
+		if v == "" {
+			continue
+		}
+		var err error
+		if *d, err = time.ParseDuration(v); err != nil {
+			return k, fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+	if v := os.Getenv("GRPC_KEEPALIVE_PERMIT_WITHOUT_STREAM"); v != "" {
+		var err error
+		if k.PermitWithoutStream, err = strconv.ParseBool(v); err != nil {
+			return k, fmt.Errorf("invalid GRPC_KEEPALIVE_PERMIT_WITHOUT_STREAM: %w", err)
+		}
+	}
+	return k, k.Validate()
+}
+
+// envDuration returns the positive duration of the environment variable
+// name, or def if it is not set.
+func envDuration(name string, def time.Duration) time.Duration {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		panic("invalid " + name + ": " + v)
+	}
+	return d
+}
+
+// eventWriteCloser is an events.Writer holding connections to Kafka until
+// it is closed.
+type eventWriteCloser interface {
+	events.Writer
+	io.Closer
+}
+
+// NewEventWriter returns the producer publishing events to brokers. The
+// records are partitioned by key, so the events of a product stay in one
+// partition.
+func NewEventWriter(brokers []string) (eventWriteCloser, error) {
+	if len(brokers) == 0 || brokers[0] == "" {
+		return nil, errors.New("no Kafka brokers")
+	}
+	return &eventWriter{
+		w: &kafka.Writer{
+			Addr:         kafka.TCP(brokers...),
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+			// The Publisher batches the events itself; waiting for more
+			// would only delay them.
+			BatchTimeout: 10 * time.Millisecond,
+		},
+	}, nil
+}
+
+// eventWriter adapts a kafka-go Writer to events.Writer.
+type eventWriter struct {
+	w *kafka.Writer
+}
+
+func (ew *eventWriter) WriteMessages(ctx context.Context, msgs ...events.Message) error {
+	records := make([]kafka.Message, len(msgs))
+	for i, msg := range msgs {
+		records[i] = kafka.Message{
+			Topic:   msg.Topic,
+			Key:     msg.Key,
+			Value:   msg.Value,
+			Headers: kafkaHeaders(msg.Headers),
+		}
+	}
+	return ew.w.WriteMessages(ctx, records...)
+}
+
+func (ew *eventWriter) Close() error {
+	return ew.w.Close()
+}
+
+// orderReadCloser is an orders.Reader holding its consumer group
+// membership until it is closed.
+type orderReadCloser interface {
+	orders.Reader
+	io.Closer
+}
+
+// NewOrderReader returns the consumer of group reading topics from
+// brokers.
+func NewOrderReader(brokers []string, group string, topics []string) (orderReadCloser, error) {
+	if len(brokers) == 0 || brokers[0] == "" {
+		return nil, errors.New("no Kafka brokers")
+	}
+	cfg := kafka.ReaderConfig{
+		Brokers:     brokers,
+		GroupID:     group,
+		GroupTopics: topics,
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &orderReader{r: kafka.NewReader(cfg)}, nil
+}
+
+// orderReader adapts a kafka-go Reader to orders.Reader.
+type orderReader struct {
+	r *kafka.Reader
+}
+
+func (or *orderReader) FetchMessage(ctx context.Context) (orders.Message, error) {
+	m, err := or.r.FetchMessage(ctx)
+	if err != nil {
+		return orders.Message{}, err
+	}
+	headers := make([]events.Header, len(m.Headers))
+	for i, h := range m.Headers {
+		headers[i] = events.Header{Key: h.Key, Value: h.Value}
+	}
+	return orders.Message{
+		Topic:     m.Topic,
+		Partition: m.Partition,
+		Offset:    m.Offset,
+		Key:       m.Key,
+		Value:     m.Value,
+		Headers:   headers,
+	}, nil
+}
+
+func (or *orderReader) CommitMessages(ctx context.Context, msgs ...orders.Message) error {
+	records := make([]kafka.Message, len(msgs))
+	for i, msg := range msgs {
+		records[i] = kafka.Message{
+			Topic:     msg.Topic,
+			Partition: msg.Partition,
+			Offset:    msg.Offset,
+		}
+	}
+	return or.r.CommitMessages(ctx, records...)
+}
+
+// Close leaves the consumer group, so its partitions are reassigned at
+// once.
+func (or *orderReader) Close() error {
+	return or.r.Close()
+}
+
+// kafkaHeaders converts the headers of an event to kafka-go ones.
+func kafkaHeaders(headers []events.Header) []kafka.Header {
+	out := make([]kafka.Header, len(headers))
+	for i, h := range headers {
+		out[i] = kafka.Header{Key: h.Key, Value: h.Value}
+	}
+	return out
+}
+
+// NewSpanExporter returns the OTLP exporter for protocol, grpc by default.
+func NewSpanExporter(ctx context.Context, protocol string) (sdktrace.SpanExporter, error) {
+	switch protocol {
+	case "", "grpc":
+		return otlptracegrpc.New(ctx)
+	case "http/protobuf":
+		return otlptracehttp.New(ctx)
+	default:
+		return nil, fmt.Errorf("unsupported OTEL_EXPORTER_OTLP_PROTOCOL %q", protocol)
+	}
+}
+
+// NewMetricsServer returns a server for the Prometheus metrics on
+// /metrics and the expvar counters on /debug/vars.
+func NewMetricsServer(addr string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.Handle("/debug/vars", http.DefaultServeMux)
+	return &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
 }
 
 func NewPool(ctx context.Context, zl *zap.Logger, dbURL string) (*pgxpool.Pool, error) {
@@ -93,12 +776,26 @@ func NewPool(ctx context.Context, zl *zap.Logger, dbURL string) (*pgxpool.Pool,
 	cfg.MinConns = 2
 	cfg.MaxConnLifetime = 30 * time.Minute
 	cfg.HealthCheckPeriod = 1 * time.Minute
+	cfg.AfterConnect = repo.PrepareStatements
+	if v := os.Getenv("SLOW_QUERY_THRESHOLD"); v != "" {
+		threshold, err := time.ParseDuration(v)
+		if err != nil {
+			zl.Error("invalid SLOW_QUERY_THRESHOLD", zap.Error(err))
+			return nil, inverr.InvalidPoolConfig
+		}
+		tracer := repo.NewSlowQueryTracer(zl, threshold)
+		tracer.Explain, _ = strconv.ParseBool(os.Getenv("EXPLAIN_SLOW_QUERIES"))
+		cfg.ConnConfig.Tracer = tracer
+	}
+	poolMetrics := metrics.NewPoolCollector(cfg)
 
 	pool, err := pgxpool.NewWithConfig(ctx, cfg)
 	if err != nil {
 		zl.Error(err.Error())
 		return nil, inverr.CreatePoolError
 	}
+	poolMetrics.Attach(pool)
+	prometheus.MustRegister(poolMetrics)
 
 	attempts := 5
 	delay := time.Second
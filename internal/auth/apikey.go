@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// APIKeyHeader is the metadata key APIKeyAuthenticator reads.
+const APIKeyHeader = "x-api-key"
+
+// APIKeyPrefix prefixes the principal of requests authenticated by an API
+// key, e.g. apikey:nightly-import, so they are told apart from users in
+// logs and audit columns.
+const APIKeyPrefix = "apikey:"
+
+// HashAPIKey returns the hex SHA-256 hash of key as stored in the key
+// file.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// ParseAPIKeys reads API keys as "<name> <sha256 hex>" lines and returns
+// the key names by hash. Blank lines and lines starting with # are
+// skipped.
+func ParseAPIKeys(r io.Reader) (map[string]string, error) {
+	keys := make(map[string]string)
+	names := make(map[string]bool)
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: expected \"<name> <sha256 hex>\"", line)
+		}
+		name, hash := fields[0], strings.ToLower(fields[1])
+		if sum, err := hex.DecodeString(hash); err != nil || len(sum) != sha256.Size {
+			return nil, fmt.Errorf("line %d: invalid SHA-256 hash of key %s", line, name)
+		}
+		if names[name] {
+			return nil, fmt.Errorf("line %d: duplicate key name %s", line, name)
+		}
+		if _, ok := keys[hash]; ok {
+			return nil, fmt.Errorf("line %d: duplicate key hash of %s", line, name)
+		}
+		names[name] = true
+		keys[hash] = name
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// APIKeyAuthenticator accepts the static API keys whose hashes are in
+// keys, as returned by ParseAPIKeys, passed in the x-api-key metadata key.
+// The principal is APIKeyPrefix followed by the key name. Requests without
+// a key are anonymous; unknown keys are rejected.
+func APIKeyAuthenticator(keys map[string]string) Authenticator {
+	return func(ctx context.Context, md metadata.MD) (string, error) {
+		values := md.Get(APIKeyHeader)
+		if len(values) == 0 {
+			return "", nil
+		}
+		if len(values) > 1 {
+			return "", status.Errorf(codes.Unauthenticated, "multiple %s values", APIKeyHeader)
+		}
+		name, ok := keys[HashAPIKey(strings.TrimSpace(values[0]))]
+		if !ok {
+			return "", status.Error(codes.Unauthenticated, "invalid API key")
+		}
+		return APIKeyPrefix + name, nil
+	}
+}
+
+// FirstOf tries authenticators in order and returns the first principal
+// resolved. An error of any authenticator rejects the request.
+func FirstOf(authenticators ...Authenticator) Authenticator {
+	return func(ctx context.Context, md metadata.MD) (string, error) {
+		for _, authenticate := range authenticators {
+			principal, err := authenticate(ctx, md)
+			if err != nil || principal != "" {
+				return principal, err
+			}
+		}
+		return "", nil
+	}
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// TestParseAPIKeys tests the key file format.
+func TestParseAPIKeys(t *testing.T) {
+	keys, err := ParseAPIKeys(strings.NewReader("# batch jobs\n\nnightly-import " + HashAPIKey("secret") + "\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(keys) != 1 || keys[HashAPIKey("secret")] != "nightly-import" {
+		t.Errorf("Unexpected keys: %v", keys)
+	}
+
+	for _, file := range []string{
+		"nightly-import",
+		"nightly-import abc",
+		"a " + HashAPIKey("1") + "\na " + HashAPIKey("2"),
+		"a " + HashAPIKey("1") + "\nb " + HashAPIKey("1"),
+	} {
+		if _, err := ParseAPIKeys(strings.NewReader(file)); err == nil {
+			t.Errorf("Expected an error for %q", file)
+		}
+	}
+}
+
+// TestAPIKeyAuthenticator tests that known keys resolve to their name and
+// fall back to the principal header when absent.
+func TestAPIKeyAuthenticator(t *testing.T) {
+	authenticate := FirstOf(
+		APIKeyAuthenticator(map[string]string{HashAPIKey("secret"): "nightly-import"}),
+		HeaderAuthenticator(""),
+	)
+
+	cases := []struct {
+		name     string
+		md       metadata.MD
+		expected string
+		code     codes.Code
+	}{
+		{name: "anonymous", md: metadata.MD{}},
+		{name: "key", md: metadata.Pairs(APIKeyHeader, "secret"), expected: "apikey:nightly-import"},
+		{name: "key over header", md: metadata.Pairs(APIKeyHeader, "secret", DefaultHeader, "alice"), expected: "apikey:nightly-import"},
+		{name: "header", md: metadata.Pairs(DefaultHeader, "alice"), expected: "alice"},
+		{name: "unknown key", md: metadata.Pairs(APIKeyHeader, "guess", DefaultHeader, "alice"), code: codes.Unauthenticated},
+		{name: "spoofed key", md: metadata.Pairs(DefaultHeader, "apikey:nightly-import"), code: codes.Unauthenticated},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			principal, err := authenticate(context.Background(), tc.md)
+			if status.Code(err) != tc.code {
+				t.Fatalf("Expected code %v, got: %v", tc.code, err)
+			}
+			if principal != tc.expected {
+				t.Errorf("Expected principal %q, got: %q", tc.expected, principal)
+			}
+		})
+	}
+}
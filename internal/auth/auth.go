@@ -0,0 +1,111 @@
+// Package auth carries the authenticated principal of a request in its
+// context.
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultHeader is the metadata key HeaderAuthenticator reads when none is
+// configured.
+const DefaultHeader = "x-principal"
+
+type principalKey struct{}
+
+// NewContext returns a copy of ctx carrying principal.
+func NewContext(ctx context.Context, principal string) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// Principal returns the principal stored in ctx, or "" for anonymous
+// requests.
+func Principal(ctx context.Context) string {
+	principal, _ := ctx.Value(principalKey{}).(string)
+	return principal
+}
+
+// Authenticator resolves the principal of an incoming request. It returns
+// "" for anonymous requests and an error for invalid credentials.
+type Authenticator func(ctx context.Context, md metadata.MD) (string, error)
+
+// HeaderAuthenticator trusts the principal passed in the header metadata
+// key, e.g. by an authenticating gateway in front of the service.
+// Principals posing as API keys are rejected.
+func HeaderAuthenticator(header string) Authenticator {
+	if header == "" {
+		header = DefaultHeader
+	}
+	header = strings.ToLower(header)
+
+	return func(ctx context.Context, md metadata.MD) (string, error) {
+		values := md.Get(header)
+		if len(values) == 0 {
+			return "", nil
+		}
+		if len(values) > 1 {
+			return "", status.Errorf(codes.Unauthenticated, "multiple %s values", header)
+		}
+		principal := strings.TrimSpace(values[0])
+		if strings.HasPrefix(principal, APIKeyPrefix) {
+			return "", status.Errorf(codes.Unauthenticated, "%s must not start with %s", header, APIKeyPrefix)
+		}
+		return principal, nil
+	}
+}
+
+// UnaryServerInterceptor stores the principal resolved by authenticate in
+// the request context. Requests failing authentication are rejected with
+// codes.Unauthenticated.
+func UnaryServerInterceptor(authenticate Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticated(ctx, authenticate)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is UnaryServerInterceptor for streaming RPCs.
+func StreamServerInterceptor(authenticate Authenticator) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticated(ss.Context(), authenticate)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticated returns ctx carrying the principal resolved by
+// authenticate.
+func authenticated(ctx context.Context, authenticate Authenticator) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	principal, err := authenticate(ctx, md)
+	if err != nil {
+		if _, ok := status.FromError(err); !ok {
+			err = status.Error(codes.Unauthenticated, err.Error())
+		}
+		return nil, err
+	}
+	if principal != "" {
+		ctx = NewContext(ctx, principal)
+	}
+	return ctx, nil
+}
+
+// serverStream replaces the context of a grpc.ServerStream.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// TestUnaryServerInterceptor tests that the principal header reaches the
+// handler context.
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor(HeaderAuthenticator(""))
+	handler := func(ctx context.Context, req any) (any, error) {
+		return Principal(ctx), nil
+	}
+
+	cases := []struct {
+		name     string
+		md       metadata.MD
+		expected string
+		code     codes.Code
+	}{
+		{name: "anonymous", md: metadata.MD{}},
+		{name: "principal", md: metadata.Pairs(DefaultHeader, " alice "), expected: "alice"},
+		{name: "ambiguous", md: metadata.Pairs(DefaultHeader, "alice", DefaultHeader, "bob"), code: codes.Unauthenticated},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), tc.md)
+			resp, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+			if status.Code(err) != tc.code {
+				t.Fatalf("Expected code %v, got: %v", tc.code, err)
+			}
+			if err == nil && resp != tc.expected {
+				t.Errorf("Expected principal %q, got: %q", tc.expected, resp)
+			}
+		})
+	}
+}
+
+// TestStreamServerInterceptor tests that the principal header reaches the
+// stream context and invalid credentials close the stream.
+func TestStreamServerInterceptor(t *testing.T) {
+	interceptor := StreamServerInterceptor(HeaderAuthenticator(""))
+	var principal string
+	handler := func(srv any, ss grpc.ServerStream) error {
+		principal = Principal(ss.Context())
+		return nil
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(DefaultHeader, "alice"))
+	if err := interceptor(nil, &fakeStream{ctx: ctx}, &grpc.StreamServerInfo{}, handler); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if principal != "alice" {
+		t.Errorf("Expected principal alice, got: %q", principal)
+	}
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(DefaultHeader, APIKeyPrefix+"x"))
+	err := interceptor(nil, &fakeStream{ctx: ctx}, &grpc.StreamServerInfo{}, handler)
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("Expected Unauthenticated, got: %v", err)
+	}
+}
+
+type fakeStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,64 @@
+// Package buildinfo holds the version of the build, injected at link time:
+//
+//	go build -ldflags "\
+//		-X github.com/andro-kes/inventory_service/internal/buildinfo.Version=v1.4.0 \
+//		-X github.com/andro-kes/inventory_service/internal/buildinfo.Commit=$(git rev-parse HEAD) \
+//		-X github.com/andro-kes/inventory_service/internal/buildinfo.Date=$(date -u +%Y-%m-%dT%H:%M:%SZ)" \
+//		./cmd/server
+package buildinfo
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+
+	pb "github.com/andro-kes/inventory_service/proto"
+)
+
+// Set with -ldflags "-X". Without them Commit and Date fall back to the
+// VCS stamp of go build, if any.
+var (
+	Version = "dev"
+	Commit  = ""
+	Date    = ""
+)
+
+// SchemaVersion is the version of proto/inventory.proto. Bump it with
+// every change of the API, so that clients can tell which RPCs and fields
+// a server knows.
+var SchemaVersion = "12"
+
+// Get returns the build info of the running binary.
+func Get() *pb.ServerInfo {
+	info := &pb.ServerInfo{
+		Version:       Version,
+		Commit:        Commit,
+		BuildDate:     Date,
+		SchemaVersion: SchemaVersion,
+		GoVersion:     runtime.Version(),
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch {
+			case s.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = s.Value
+			case s.Key == "vcs.time" && info.BuildDate == "":
+				info.BuildDate = s.Value
+			}
+		}
+	}
+	return info
+}
+
+// String formats info for --version.
+func String(info *pb.ServerInfo) string {
+	return fmt.Sprintf("inventory_service %s (commit %s, built %s, schema %s, %s)",
+		info.GetVersion(), orUnknown(info.GetCommit()), orUnknown(info.GetBuildDate()), info.GetSchemaVersion(), info.GetGoVersion())
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
@@ -0,0 +1,22 @@
+package buildinfo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	Version, Commit, Date = "v1.4.0", "abc123", "2026-10-18T12:00:00Z"
+	defer func() { Version, Commit, Date = "dev", "", "" }()
+
+	info := Get()
+	if info.GetVersion() != "v1.4.0" || info.GetCommit() != "abc123" || info.GetBuildDate() != "2026-10-18T12:00:00Z" {
+		t.Errorf("Expected the injected build info, got: %v", info)
+	}
+	if info.GetSchemaVersion() != SchemaVersion || info.GetGoVersion() == "" {
+		t.Errorf("Expected the schema and Go versions, got: %v", info)
+	}
+	if s := String(info); !strings.Contains(s, "v1.4.0") || !strings.Contains(s, "abc123") {
+		t.Errorf("Expected the version and commit in %q", s)
+	}
+}
@@ -0,0 +1,174 @@
+// Package certs provides the TLS configuration of the gRPC server and
+// reloads the certificate files when they change, so renewed certificates
+// are picked up without a restart.
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Reloader serves a certificate and key pair and, for mutual TLS, a client
+// CA bundle, re-reading them when the files change. Handshakes started
+// after a reload use the new files; established connections are kept.
+type Reloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+
+	// OnReload, if set, is called by Run after the files were reloaded.
+	OnReload func()
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	versions  []fileVersion
+}
+
+// fileVersion identifies the content of a file by size and modification
+// time. os.Stat follows symlinks, so the atomic symlink swap of a mounted
+// Kubernetes secret is seen as a change.
+type fileVersion struct {
+	size    int64
+	modTime time.Time
+}
+
+// NewReloader loads certFile and keyFile and, if caFile is not empty, the
+// PEM bundle of CAs client certificates must chain to.
+func NewReloader(certFile, keyFile, caFile string) (*Reloader, error) {
+	r := &Reloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		caFile:   caFile,
+	}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Reloader) files() []string {
+	files := []string{r.certFile, r.keyFile}
+	if r.caFile != "" {
+		files = append(files, r.caFile)
+	}
+	return files
+}
+
+func (r *Reloader) stat() ([]fileVersion, error) {
+	files := r.files()
+	versions := make([]fileVersion, len(files))
+	for i, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		versions[i] = fileVersion{size: info.Size(), modTime: info.ModTime()}
+	}
+	return versions, nil
+}
+
+// Reload re-reads the files if any of them changed since the last load
+// and reports whether they were reloaded. On error the previous
+// certificates stay in use.
+func (r *Reloader) Reload() (bool, error) {
+	versions, err := r.stat()
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.RLock()
+	unchanged := versionsEqual(versions, r.versions)
+	r.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("load certificate: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.caFile != "" {
+		pem, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return false, fmt.Errorf("load client CA: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return false, errors.New("load client CA: no certificates found in " + r.caFile)
+		}
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.versions = versions
+	r.mu.Unlock()
+	return true, nil
+}
+
+func versionsEqual(a, b []fileVersion) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].size != b[i].size || !a[i].modTime.Equal(b[i].modTime) {
+			return false
+		}
+	}
+	return true
+}
+
+// Run calls Reload every interval until ctx is done. Reload errors are
+// passed to onError, e.g. for a renewal caught halfway through writing.
+func (r *Reloader) Run(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		reloaded, err := r.Reload()
+		switch {
+		case err != nil && onError != nil:
+			onError(err)
+		case reloaded && r.OnReload != nil:
+			r.OnReload()
+		}
+	}
+}
+
+// TLSConfig returns a server configuration using the current files on
+// every handshake. With a client CA bundle clients must present a
+// certificate chaining to it.
+func (r *Reloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+
+			cfg := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*r.cert},
+				NextProtos:   []string{"h2"},
+			}
+			if r.clientCAs != nil {
+				cfg.ClientCAs = r.clientCAs
+				cfg.ClientAuth = tls.RequireAndVerifyClientCert
+			}
+			return cfg, nil
+		},
+	}
+}
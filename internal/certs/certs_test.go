@@ -0,0 +1,263 @@
+package certs
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// issuer signs test certificates.
+type issuer struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newCA(t *testing.T) *issuer {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &issuer{cert: cert, key: key}
+}
+
+// issue returns the PEM certificate and key of a leaf for name.
+func (ca *issuer) issue(t *testing.T, name string, serial int64, usage x509.ExtKeyUsage) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func writeFile(t *testing.T, path string, data []byte, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// handshake connects a client with clientCert, if any, to server and
+// returns the server certificate the client saw. The server writes a byte
+// after its handshake, so a rejected client certificate fails the read
+// even with TLS 1.3, where the client finishes first.
+func handshake(t *testing.T, server *tls.Config, ca *issuer, clientCert []tls.Certificate) (*x509.Certificate, error) {
+	t.Helper()
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", server)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = conn.Write([]byte{1})
+	}()
+
+	conn, err := tls.Dial("tcp", ln.Addr().String(), &tls.Config{
+		ServerName:   "inventory",
+		RootCAs:      roots,
+		Certificates: clientCert,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Read(make([]byte, 1)); err != nil {
+		return nil, err
+	}
+	return conn.ConnectionState().PeerCertificates[0], nil
+}
+
+// TestReloader tests mutual TLS and that a renewed server certificate is
+// served after Reload.
+func TestReloader(t *testing.T) {
+	ca := newCA(t)
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	caFile := filepath.Join(dir, "ca.crt")
+
+	modTime := time.Now().Add(-time.Minute)
+	certPEM, keyPEM := ca.issue(t, "inventory", 2, x509.ExtKeyUsageServerAuth)
+	writeFile(t, certFile, certPEM, modTime)
+	writeFile(t, keyFile, keyPEM, modTime)
+	writeFile(t, caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw}), modTime)
+
+	r, err := NewReloader(certFile, keyFile, caFile)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	server := r.TLSConfig()
+
+	if _, err := handshake(t, server, ca, nil); err == nil {
+		t.Fatal("Expected a handshake without a client certificate to fail")
+	}
+
+	clientCertPEM, clientKeyPEM := ca.issue(t, "client", 3, x509.ExtKeyUsageClientAuth)
+	clientCert, err := tls.X509KeyPair(clientCertPEM, clientKeyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	peer, err := handshake(t, server, ca, []tls.Certificate{clientCert})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if peer.SerialNumber.Int64() != 2 {
+		t.Errorf("Expected serial 2, got: %v", peer.SerialNumber)
+	}
+
+	if reloaded, err := r.Reload(); err != nil || reloaded {
+		t.Errorf("Expected no reload of unchanged files, got: %v, %v", reloaded, err)
+	}
+
+	certPEM, keyPEM = ca.issue(t, "inventory", 4, x509.ExtKeyUsageServerAuth)
+	writeFile(t, certFile, certPEM, modTime.Add(time.Second))
+	writeFile(t, keyFile, keyPEM, modTime.Add(time.Second))
+	if reloaded, err := r.Reload(); err != nil || !reloaded {
+		t.Fatalf("Expected a reload, got: %v, %v", reloaded, err)
+	}
+
+	peer, err = handshake(t, server, ca, []tls.Certificate{clientCert})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if peer.SerialNumber.Int64() != 4 {
+		t.Errorf("Expected the renewed serial 4, got: %v", peer.SerialNumber)
+	}
+}
+
+// TestReloaderKeepsCertOnError tests that a broken renewal keeps the
+// previous certificate.
+func TestReloaderKeepsCertOnError(t *testing.T) {
+	ca := newCA(t)
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+
+	modTime := time.Now().Add(-time.Minute)
+	certPEM, keyPEM := ca.issue(t, "inventory", 2, x509.ExtKeyUsageServerAuth)
+	writeFile(t, certFile, certPEM, modTime)
+	writeFile(t, keyFile, keyPEM, modTime)
+
+	r, err := NewReloader(certFile, keyFile, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	writeFile(t, keyFile, []byte("garbage"), modTime.Add(time.Second))
+	if _, err := r.Reload(); err == nil {
+		t.Fatal("Expected an error")
+	}
+
+	peer, err := handshake(t, r.TLSConfig(), ca, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if peer.SerialNumber.Int64() != 2 {
+		t.Errorf("Expected serial 2, got: %v", peer.SerialNumber)
+	}
+}
+
+// TestReloaderRun tests that Run picks up renewed files and reports it.
+func TestReloaderRun(t *testing.T) {
+	ca := newCA(t)
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+
+	modTime := time.Now().Add(-time.Minute)
+	certPEM, keyPEM := ca.issue(t, "inventory", 2, x509.ExtKeyUsageServerAuth)
+	writeFile(t, certFile, certPEM, modTime)
+	writeFile(t, keyFile, keyPEM, modTime)
+
+	r, err := NewReloader(certFile, keyFile, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	reloaded := make(chan struct{}, 1)
+	r.OnReload = func() {
+		select {
+		case reloaded <- struct{}{}:
+		default:
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// A tick between the two writes sees a mismatched pair and fails;
+	// the next one reloads.
+	go r.Run(ctx, 10*time.Millisecond, nil)
+
+	certPEM, keyPEM = ca.issue(t, "inventory", 3, x509.ExtKeyUsageServerAuth)
+	writeFile(t, keyFile, keyPEM, modTime.Add(time.Second))
+	writeFile(t, certFile, certPEM, modTime.Add(time.Second))
+	select {
+	case <-reloaded:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the renewed files to be reloaded")
+	}
+
+	peer, err := handshake(t, r.TLSConfig(), ca, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if peer.SerialNumber.Int64() != 3 {
+		t.Errorf("Expected the renewed serial 3, got: %v", peer.SerialNumber)
+	}
+}
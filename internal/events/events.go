@@ -0,0 +1,224 @@
+// Package events publishes the domain events of the inventory, e.g.
+// ProductCreated or StockChanged, so that other services, like search
+// indexing and analytics, learn about changes without polling the
+// database.
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/andro-kes/inventory_service/internal/repo"
+	pb "github.com/andro-kes/inventory_service/proto"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// TypeHeader is the header naming the message of an event, e.g.
+// "inventory.ProductCreated", so consumers can decode the value.
+const TypeHeader = "event-type"
+
+// Header is a header of a Message.
+type Header struct {
+	Key   string
+	Value []byte
+}
+
+// Message is a Kafka record.
+type Message struct {
+	Topic   string
+	Key     []byte
+	Value   []byte
+	Headers []Header
+}
+
+// Writer writes records to Kafka. It is the part of a Kafka producer,
+// e.g. the Writer of kafka-go, the Publisher uses.
+type Writer interface {
+	WriteMessages(ctx context.Context, msgs ...Message) error
+}
+
+// Topics are the topics events are published to.
+type Topics struct {
+	// Products receives ProductCreated, ProductUpdated and ProductDeleted.
+	Products string
+	// Stock receives StockChanged.
+	Stock string
+}
+
+var DefaultTopics = Topics{
+	Products: "inventory.products",
+	Stock:    "inventory.stock",
+}
+
+// maxBatch bounds the messages of one WriteMessages call.
+const maxBatch = 100
+
+// ErrQueueFull is reported through OnError for events dropped because
+// the writer fell behind.
+var ErrQueueFull = errors.New("event queue full")
+
+// Publisher queues events and writes them to Kafka in the background, so
+// that requests do not wait for the brokers. Events are published after
+// their change commits and without an outbox: events queued when the
+// process dies, or dropped on a full queue, are lost. Consumers should
+// treat them as hints and read the product for its current state.
+//
+// The methods of a nil Publisher do nothing.
+type Publisher struct {
+	Writer Writer
+	Topics Topics
+	// OnError is called with the failures of writes and dropped events.
+	OnError func(err error)
+
+	mu     sync.Mutex
+	queue  chan Message
+	closed bool
+	now    func() time.Time
+}
+
+// NewPublisher returns a Publisher writing to w that queues up to buffer
+// events. Call Run to write them.
+func NewPublisher(w Writer, topics Topics, buffer int) *Publisher {
+	return &Publisher{
+		Writer: w,
+		Topics: topics,
+		queue:  make(chan Message, max(buffer, 1)),
+		now:    time.Now,
+	}
+}
+
+func (p *Publisher) ProductCreated(product *pb.Product) {
+	if p == nil {
+		return
+	}
+	p.publish(p.Topics.Products, product.GetId(), &pb.ProductCreated{
+		Product:    product,
+		OccurredAt: timestamppb.New(p.now()),
+	})
+}
+
+// ProductUpdated publishes the update of the fields paths of product.
+func (p *Publisher) ProductUpdated(product *pb.Product, paths []string) {
+	if p == nil {
+		return
+	}
+	p.publish(p.Topics.Products, product.GetId(), &pb.ProductUpdated{
+		Product:       product,
+		ChangedFields: paths,
+		OccurredAt:    timestamppb.New(p.now()),
+	})
+}
+
+func (p *Publisher) ProductDeleted(id string) {
+	if p == nil {
+		return
+	}
+	p.publish(p.Topics.Products, id, &pb.ProductDeleted{
+		Id:         id,
+		OccurredAt: timestamppb.New(p.now()),
+	})
+}
+
+// StockChanged publishes a change of the quantity of product id by delta
+// to quantity, for a movement reason of the stock ledger.
+func (p *Publisher) StockChanged(id string, delta, quantity int32, reason string) {
+	if p == nil {
+		return
+	}
+	p.publish(p.Topics.Stock, id, &pb.StockChanged{
+		ProductId:  id,
+		Delta:      delta,
+		Quantity:   quantity,
+		Reason:     stockReasons[reason],
+		OccurredAt: timestamppb.New(p.now()),
+	})
+}
+
+// stockReasons maps the movement reasons of the stock ledger to the
+// reasons of StockChanged.
+var stockReasons = map[string]pb.AdjustStockRequest_Reason{
+	repo.ReasonReceived:    pb.AdjustStockRequest_RECEIVED,
+	repo.ReasonReturned:    pb.AdjustStockRequest_RETURNED,
+	repo.ReasonDamaged:     pb.AdjustStockRequest_DAMAGED,
+	repo.ReasonLost:        pb.AdjustStockRequest_LOST,
+	repo.ReasonSold:        pb.AdjustStockRequest_SOLD,
+	repo.ReasonCorrection:  pb.AdjustStockRequest_CORRECTION,
+	repo.ReasonDecrement:   pb.AdjustStockRequest_SOLD,
+	repo.ReasonReservation: pb.AdjustStockRequest_SOLD,
+}
+
+// publish queues event, keyed by the product id, without blocking.
+func (p *Publisher) publish(topic, id string, event proto.Message) {
+	value, err := proto.Marshal(event)
+	if err != nil {
+		p.fail(err)
+		return
+	}
+	msg := Message{
+		Topic: topic,
+		Key:   []byte(id),
+		Value: value,
+		Headers: []Header{
+			{Key: TypeHeader, Value: []byte(event.ProtoReflect().Descriptor().FullName())},
+		},
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return
+	}
+	select {
+	case p.queue <- msg:
+	default:
+		p.fail(ErrQueueFull)
+	}
+}
+
+// Run writes the queued events in batches until Close, and returns once
+// the events queued before Close are written. Failed batches are
+// reported to OnError and not retried; Writer is expected to retry
+// transient errors itself.
+func (p *Publisher) Run(ctx context.Context) {
+	for msg := range p.queue {
+		batch := []Message{msg}
+	fill:
+		for len(batch) < maxBatch {
+			select {
+			case msg, ok := <-p.queue:
+				if !ok {
+					break fill
+				}
+				batch = append(batch, msg)
+			default:
+				break fill
+			}
+		}
+		if err := p.Writer.WriteMessages(ctx, batch...); err != nil {
+			p.fail(err)
+		}
+	}
+}
+
+// Close stops queueing events; later ones are dropped silently. Run
+// returns after writing the events queued so far.
+func (p *Publisher) Close() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.closed {
+		p.closed = true
+		close(p.queue)
+	}
+}
+
+func (p *Publisher) fail(err error) {
+	if p.OnError != nil {
+		p.OnError(err)
+	}
+}
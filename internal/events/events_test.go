@@ -0,0 +1,102 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andro-kes/inventory_service/internal/repo"
+	pb "github.com/andro-kes/inventory_service/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+type fakeWriter struct {
+	mu      sync.Mutex
+	batches [][]Message
+	err     error
+}
+
+func (w *fakeWriter) WriteMessages(ctx context.Context, msgs ...Message) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.batches = append(w.batches, msgs)
+	return w.err
+}
+
+func (w *fakeWriter) messages() []Message {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var msgs []Message
+	for _, b := range w.batches {
+		msgs = append(msgs, b...)
+	}
+	return msgs
+}
+
+func TestPublisher(t *testing.T) {
+	w := &fakeWriter{}
+	p := NewPublisher(w, DefaultTopics, 10)
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	p.now = func() time.Time { return at }
+
+	p.ProductCreated(&pb.Product{Id: "p1", Name: "chair"})
+	p.ProductUpdated(&pb.Product{Id: "p1", Name: "table"}, []string{"name"})
+	p.StockChanged("p1", -2, 3, repo.ReasonSold)
+	p.ProductDeleted("p1")
+	p.Close()
+	p.Run(t.Context())
+
+	msgs := w.messages()
+	require.Len(t, msgs, 4)
+	for _, m := range msgs {
+		assert.Equal(t, "p1", string(m.Key))
+	}
+	assert.Equal(t, []string{"inventory.products", "inventory.products", "inventory.stock", "inventory.products"},
+		[]string{msgs[0].Topic, msgs[1].Topic, msgs[2].Topic, msgs[3].Topic})
+	assert.Equal(t, []Header{{Key: TypeHeader, Value: []byte("inventory.ProductCreated")}}, msgs[0].Headers)
+
+	var updated pb.ProductUpdated
+	require.NoError(t, proto.Unmarshal(msgs[1].Value, &updated))
+	assert.Equal(t, "table", updated.GetProduct().GetName())
+	assert.Equal(t, []string{"name"}, updated.GetChangedFields())
+	assert.Equal(t, at, updated.GetOccurredAt().AsTime())
+
+	var stock pb.StockChanged
+	require.NoError(t, proto.Unmarshal(msgs[2].Value, &stock))
+	assert.Equal(t, int32(-2), stock.GetDelta())
+	assert.Equal(t, int32(3), stock.GetQuantity())
+	assert.Equal(t, pb.AdjustStockRequest_SOLD, stock.GetReason())
+}
+
+func TestPublisherErrors(t *testing.T) {
+	w := &fakeWriter{err: errors.New("broker down")}
+	p := NewPublisher(w, DefaultTopics, 1)
+	var errs []error
+	p.OnError = func(err error) { errs = append(errs, err) }
+
+	p.ProductDeleted("p1")
+	// The queue holds one event until Run.
+	p.ProductDeleted("p2")
+	p.Close()
+	// Closed publishers drop events silently.
+	p.ProductDeleted("p3")
+	p.Run(t.Context())
+
+	assert.Len(t, w.messages(), 1)
+	require.Len(t, errs, 2)
+	assert.ErrorIs(t, errs[0], ErrQueueFull)
+	assert.EqualError(t, errs[1], "broker down")
+}
+
+func TestNilPublisher(t *testing.T) {
+	var p *Publisher
+	assert.NotPanics(t, func() {
+		p.ProductCreated(&pb.Product{Id: "p1"})
+		p.StockChanged("p1", 1, 1, repo.ReasonReceived)
+		p.Close()
+	})
+}
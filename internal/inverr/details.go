@@ -0,0 +1,77 @@
+package inverr
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Domain is the ErrorInfo domain of the errors of the service.
+const Domain = "inventory"
+
+// Reasons of the ErrorInfo details, stable for clients to switch on
+// instead of parsing messages.
+const (
+	ReasonInvalidArgument         = "INVALID_ARGUMENT"
+	ReasonInsufficientStock       = "INSUFFICIENT_STOCK"
+	ReasonDuplicateSKU            = "DUPLICATE_SKU"
+	ReasonDuplicateBarcode        = "DUPLICATE_BARCODE"
+	ReasonDuplicateVariant        = "DUPLICATE_VARIANT"
+	ReasonInvalidStatusTransition = "INVALID_STATUS_TRANSITION"
+	ReasonReservationConfirmed    = "RESERVATION_CONFIRMED"
+	ReasonReservationReleased     = "RESERVATION_RELEASED"
+	ReasonReservationExpired      = "RESERVATION_EXPIRED"
+	ReasonCategoryInUse           = "CATEGORY_IN_USE"
+	ReasonDatabaseUnavailable     = "DATABASE_UNAVAILABLE"
+	ReasonDatabaseOverloaded      = "DATABASE_OVERLOADED"
+	ReasonStockConflict           = "STOCK_CONFLICT"
+)
+
+// InvalidField returns an InvalidArgument error whose BadRequest detail
+// blames field, e.g. "price_money.nanos" or "translations[1].locale".
+// Field paths are relative to the product, variant or request being
+// validated.
+func InvalidField(field, format string, args ...any) error {
+	msg := fmt.Sprintf(format, args...)
+	return withDetails(status.New(codes.InvalidArgument, msg),
+		&errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{{
+				Field:       field,
+				Description: msg,
+			}},
+		},
+		&errdetails.ErrorInfo{Reason: ReasonInvalidArgument, Domain: Domain},
+	).Err()
+}
+
+// WithReason returns an error of code whose ErrorInfo detail carries
+// reason and metadata, e.g. the conflicting SKU.
+func WithReason(code codes.Code, reason string, metadata map[string]string, format string, args ...any) error {
+	return withDetails(status.Newf(code, format, args...),
+		&errdetails.ErrorInfo{Reason: reason, Domain: Domain, Metadata: metadata},
+	).Err()
+}
+
+// Throttled returns an error of code, e.g. Unavailable, whose RetryInfo
+// detail asks clients to wait retryDelay before retrying.
+func Throttled(code codes.Code, reason string, retryDelay time.Duration, format string, args ...any) error {
+	return withDetails(status.Newf(code, format, args...),
+		&errdetails.ErrorInfo{Reason: reason, Domain: Domain},
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(retryDelay)},
+	).Err()
+}
+
+// withDetails attaches details to st, or returns st as is if they cannot
+// be marshaled.
+func withDetails(st *status.Status, details ...protoadapt.MessageV1) *status.Status {
+	detailed, err := st.WithDetails(details...)
+	if err != nil {
+		return st
+	}
+	return detailed
+}
@@ -0,0 +1,68 @@
+package inverr
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TestInvalidField tests that validation errors name the field in a
+// BadRequest detail.
+func TestInvalidField(t *testing.T) {
+	st := status.Convert(InvalidField("price_money.nanos", "price nanos must be whole cents: %d", 1))
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("Expected InvalidArgument, got: %v", st.Code())
+	}
+
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, detail := range st.Details() {
+		if br, ok := detail.(*errdetails.BadRequest); ok {
+			violations = br.GetFieldViolations()
+		}
+	}
+	if len(violations) != 1 || violations[0].GetField() != "price_money.nanos" {
+		t.Errorf("Expected a violation of price_money.nanos, got: %v", violations)
+	}
+}
+
+// TestInvErrorStatus tests that InvErrors convert to their code, with a
+// BadRequest detail for request fields.
+func TestInvErrorStatus(t *testing.T) {
+	if code := status.Code(ListProductsError); code != codes.Internal {
+		t.Errorf("Expected Internal, got: %v", code)
+	}
+
+	st := status.Convert(InvalidPageToken)
+	if st.Code() != codes.InvalidArgument || st.Message() != "invalid page token" {
+		t.Errorf("Expected InvalidArgument invalid page token, got: %v", st)
+	}
+	if len(st.Details()) == 0 {
+		t.Error("Expected a BadRequest detail")
+	}
+}
+
+// TestThrottled tests that throttling errors carry the reason and the
+// retry delay.
+func TestThrottled(t *testing.T) {
+	st := status.Convert(Throttled(codes.Unavailable, ReasonDatabaseUnavailable, time.Second, "retry later"))
+
+	var info *errdetails.ErrorInfo
+	var retry *errdetails.RetryInfo
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			info = d
+		case *errdetails.RetryInfo:
+			retry = d
+		}
+	}
+	if info.GetReason() != ReasonDatabaseUnavailable || info.GetDomain() != Domain {
+		t.Errorf("Expected ErrorInfo %s, got: %v", ReasonDatabaseUnavailable, info)
+	}
+	if retry.GetRetryDelay().AsDuration() != time.Second {
+		t.Errorf("Expected a retry delay of 1s, got: %v", retry.GetRetryDelay())
+	}
+}
@@ -2,11 +2,14 @@ package inverr
 
 import (
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type InvError struct {
 	msg      string
 	grpcCode codes.Code
+	// field is the request field an InvalidArgument error blames.
+	field string
 }
 
 func New(msg string, code codes.Code) *InvError {
@@ -16,6 +19,15 @@ func New(msg string, code codes.Code) *InvError {
 	}
 }
 
+// NewField returns an InvalidArgument error blaming the request field.
+func NewField(msg, field string) *InvError {
+	return &InvError{
+		msg:      msg,
+		grpcCode: codes.InvalidArgument,
+		field:    field,
+	}
+}
+
 func (ie *InvError) Error() string {
 	if ie == nil {
 		return "<nil>"
@@ -23,11 +35,44 @@ func (ie *InvError) Error() string {
 	return ie.msg
 }
 
+// GRPCStatus returns the status of ie, with a BadRequest detail if it
+// blames a field.
+func (ie *InvError) GRPCStatus() *status.Status {
+	if ie.field != "" {
+		return status.Convert(InvalidField(ie.field, "%s", ie.msg))
+	}
+	return status.New(ie.grpcCode, ie.msg)
+}
+
 var (
 	InvalidPoolConfig = New("failed to parse config", codes.Internal)
 	CreatePoolError   = New("failed to create pool", codes.Internal)
 
-	CreateProductError = New("failed to create product", codes.Internal)
-	DeleteProductError = New("failed to delete product", codes.Internal)
-	ListProductsError  = New("failed to list product", codes.Internal)
+	CreateProductError     = New("failed to create product", codes.Internal)
+	DeleteProductError     = New("failed to delete product", codes.Internal)
+	ListProductsError      = New("failed to list product", codes.Internal)
+	GetProductError        = New("failed to get product", codes.Internal)
+	UpdateProductError     = New("failed to update product", codes.Internal)
+	PurchaseError          = New("failed to purchase product", codes.Internal)
+	AdjustStockError       = New("failed to adjust stock", codes.Internal)
+	ReserveStockError      = New("failed to reserve stock", codes.Internal)
+	GetReservationError    = New("failed to get reservation", codes.Internal)
+	UpdateReservationError = New("failed to update reservation", codes.Internal)
+	CheckAvailabilityError = New("failed to check availability", codes.Internal)
+	ImportProductsError    = New("failed to import products", codes.Internal)
+	ListTagsError          = New("failed to list tags", codes.Internal)
+	MergeTagsError         = New("failed to merge tags", codes.Internal)
+	ListAuditError         = New("failed to list audit entries", codes.Internal)
+	ListReorderError       = New("failed to list reorder suggestions", codes.Internal)
+	ReceiveLotError        = New("failed to receive lot", codes.Internal)
+	ListLotsError          = New("failed to list lots", codes.Internal)
+	CreateVariantError     = New("failed to create variant", codes.Internal)
+	GetVariantError        = New("failed to get variant", codes.Internal)
+	UpdateVariantError     = New("failed to update variant", codes.Internal)
+	DeleteVariantError     = New("failed to delete variant", codes.Internal)
+	ListVariantsError      = New("failed to list variants", codes.Internal)
+	InvalidPageToken       = NewField("invalid page token", "page_token")
+	InvalidPageSize        = NewField("page size must not be negative", "page_size")
+	InvalidOrderBy         = NewField("invalid order_by", "order_by")
+	InvalidTTL             = NewField("ttl must be positive", "ttl")
 )
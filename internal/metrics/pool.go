@@ -0,0 +1,128 @@
+// Package metrics exports service internals to Prometheus.
+package metrics
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const poolNamespace = "pgxpool"
+
+var (
+	acquiredConnsDesc = poolDesc("acquired_conns", "Connections currently acquired from the pool.")
+	idleConnsDesc     = poolDesc("idle_conns", "Idle connections in the pool.")
+	constructingDesc  = poolDesc("constructing_conns", "Connections currently being established.")
+	totalConnsDesc    = poolDesc("total_conns", "Connections in the pool, acquired, idle or constructing.")
+	maxConnsDesc      = poolDesc("max_conns", "Maximum size of the pool.")
+	acquiresDesc      = poolDesc("acquires_total", "Successful acquires from the pool.")
+	acquireSecsDesc   = poolDesc("acquire_duration_seconds_total", "Total time spent in successful acquires.")
+	emptyAcquiresDesc = poolDesc("empty_acquires_total", "Successful acquires that waited for a connection.")
+	emptyWaitSecsDesc = poolDesc("empty_acquire_wait_seconds_total", "Total time acquires waited for a connection.")
+	canceledDesc      = poolDesc("canceled_acquires_total", "Acquires canceled by their context.")
+	newConnsDesc      = poolDesc("new_conns_total", "Connection attempts made by the pool.")
+	connectErrsDesc   = poolDesc("connect_errors_total", "Connection attempts that failed.")
+	lifetimeDesc      = poolDesc("max_lifetime_destroys_total", "Connections closed for exceeding MaxConnLifetime.")
+	idleDestroysDesc  = poolDesc("max_idle_destroys_total", "Connections closed for exceeding MaxConnIdleTime.")
+)
+
+func poolDesc(name, help string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(poolNamespace, "", name), help, nil, nil)
+}
+
+// PoolCollector is a prometheus.Collector reading the stats of a pgxpool
+// on every scrape.
+type PoolCollector struct {
+	pool      atomic.Pointer[pgxpool.Pool]
+	connected atomic.Int64
+
+	mu         sync.Mutex
+	connectErr int64
+}
+
+// NewPoolCollector returns a collector for the pool that will be created
+// from cfg. It wraps cfg.AfterConnect to count established connections,
+// so it must be called after AfterConnect is set. Attach the pool once it
+// is created.
+func NewPoolCollector(cfg *pgxpool.Config) *PoolCollector {
+	c := &PoolCollector{}
+	next := cfg.AfterConnect
+	cfg.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		if next != nil {
+			if err := next(ctx, conn); err != nil {
+				return err
+			}
+		}
+		c.connected.Add(1)
+		return nil
+	}
+	return c
+}
+
+// Attach makes the collector report the stats of pool.
+func (c *PoolCollector) Attach(pool *pgxpool.Pool) {
+	c.pool.Store(pool)
+}
+
+// Describe implements prometheus.Collector.
+func (c *PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{
+		acquiredConnsDesc, idleConnsDesc, constructingDesc, totalConnsDesc, maxConnsDesc,
+		acquiresDesc, acquireSecsDesc, emptyAcquiresDesc, emptyWaitSecsDesc, canceledDesc,
+		newConnsDesc, connectErrsDesc, lifetimeDesc, idleDestroysDesc,
+	} {
+		ch <- d
+	}
+}
+
+// Collect implements prometheus.Collector. Nothing is reported until a
+// pool is attached.
+func (c *PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	pool := c.pool.Load()
+	if pool == nil {
+		return
+	}
+	s := pool.Stat()
+
+	gauge := func(d *prometheus.Desc, v int32) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.GaugeValue, float64(v))
+	}
+	counter := func(d *prometheus.Desc, v float64) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.CounterValue, v)
+	}
+
+	gauge(acquiredConnsDesc, s.AcquiredConns())
+	gauge(idleConnsDesc, s.IdleConns())
+	gauge(constructingDesc, s.ConstructingConns())
+	gauge(totalConnsDesc, s.TotalConns())
+	gauge(maxConnsDesc, s.MaxConns())
+	counter(acquiresDesc, float64(s.AcquireCount()))
+	counter(acquireSecsDesc, s.AcquireDuration().Seconds())
+	counter(emptyAcquiresDesc, float64(s.EmptyAcquireCount()))
+	counter(emptyWaitSecsDesc, s.EmptyAcquireWaitTime().Seconds())
+	counter(canceledDesc, float64(s.CanceledAcquireCount()))
+	counter(newConnsDesc, float64(s.NewConnsCount()))
+	counter(connectErrsDesc, float64(c.connectErrors(s)))
+	counter(lifetimeDesc, float64(s.MaxLifetimeDestroyCount()))
+	counter(idleDestroysDesc, float64(s.MaxIdleDestroyCount()))
+}
+
+// connectErrors derives the failed connection attempts, which pgxpool
+// does not count, from the attempts that are neither established nor in
+// progress. connected is read after s, so attempts finishing in between
+// make the result too low, never too high; keeping the maximum makes the
+// counter monotonic.
+func (c *PoolCollector) connectErrors(s *pgxpool.Stat) int64 {
+	n := s.NewConnsCount() - int64(s.ConstructingConns()) - c.connected.Load()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if n > c.connectErr {
+		c.connectErr = n
+	}
+	return c.connectErr
+}
@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// TestPoolCollector tests that a failed connection attempt is reported as
+// a connect error.
+func TestPoolCollector(t *testing.T) {
+	cfg, err := pgxpool.ParseConfig("postgres://user@127.0.0.1:1/db?connect_timeout=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewPoolCollector(cfg)
+
+	if n := testutil.CollectAndCount(c); n != 0 {
+		t.Errorf("Expected no metrics before Attach, got: %d", n)
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	c.Attach(pool)
+
+	if _, err := pool.Acquire(ctx); err == nil {
+		t.Fatal("Expected the connection to fail")
+	}
+
+	expected := `
+# HELP pgxpool_connect_errors_total Connection attempts that failed.
+# TYPE pgxpool_connect_errors_total counter
+pgxpool_connect_errors_total 1
+# HELP pgxpool_new_conns_total Connection attempts made by the pool.
+# TYPE pgxpool_new_conns_total counter
+pgxpool_new_conns_total 1
+# HELP pgxpool_acquired_conns Connections currently acquired from the pool.
+# TYPE pgxpool_acquired_conns gauge
+pgxpool_acquired_conns 0
+`
+	err = testutil.CollectAndCompare(c, strings.NewReader(expected),
+		"pgxpool_connect_errors_total", "pgxpool_new_conns_total", "pgxpool_acquired_conns")
+	if err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,310 @@
+// Package migrations applies the embedded database schema migrations.
+//
+// Migrations live in sql/ as NNNN_name.up.sql and NNNN_name.down.sql
+// pairs and are applied in version order. Applied versions are recorded in
+// the schema_migrations table.
+package migrations
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andro-kes/inventory_service/internal/repo/builder"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+// lockID is the advisory lock that serializes migrations run concurrently
+// by several instances.
+const lockID = 7_250_301
+
+const createTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version    bigint PRIMARY KEY,
+    name       text NOT NULL,
+    applied_at timestamptz NOT NULL DEFAULT now()
+)`
+
+// DB is the part of pgxpool.Pool and pgx.Conn the migrator uses.
+type DB interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// Status describes a migration and whether it has been applied.
+type Status struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time // nil when pending
+}
+
+type migration struct {
+	version int64
+	name    string
+	up      string
+	down    string
+}
+
+type Migrator struct {
+	DB         DB
+	migrations []migration
+}
+
+// New returns a Migrator for the embedded migrations.
+func New(db DB) (*Migrator, error) {
+	migrations, err := load(files)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		DB:         db,
+		migrations: migrations,
+	}, nil
+}
+
+// Up applies all pending migrations, each in its own transaction, and
+// returns how many were applied.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	if _, err := m.DB.Exec(ctx, createTable); err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	for _, mig := range m.migrations {
+		ok, err := m.apply(ctx, mig)
+		if err != nil {
+			return applied, fmt.Errorf("migration %04d_%s: %w", mig.version, mig.name, err)
+		}
+		if ok {
+			applied++
+		}
+	}
+
+	return applied, nil
+}
+
+// Down rolls back the most recently applied migration. It does nothing
+// when no migration is applied.
+func (m *Migrator) Down(ctx context.Context) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := len(statuses) - 1; i >= 0; i-- {
+		if statuses[i].AppliedAt == nil {
+			continue
+		}
+		mig := m.migrations[i]
+		if err := m.revert(ctx, mig); err != nil {
+			return fmt.Errorf("migration %04d_%s: %w", mig.version, mig.name, err)
+		}
+		return nil
+	}
+
+	return nil
+}
+
+// Status lists every embedded migration in version order.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	if _, err := m.DB.Exec(ctx, createTable); err != nil {
+		return nil, err
+	}
+
+	sql, args := builder.NewSQLBuilder().
+		Select("version", "applied_at").
+		From("schema_migrations").
+		Build()
+
+	rows, err := m.DB.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	appliedAt := make(map[int64]time.Time)
+	for rows.Next() {
+		var (
+			version int64
+			at      time.Time
+		)
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		appliedAt[version] = at
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, len(m.migrations))
+	for i, mig := range m.migrations {
+		statuses[i] = Status{Version: mig.version, Name: mig.name}
+		if at, ok := appliedAt[mig.version]; ok {
+			statuses[i].AppliedAt = &at
+		}
+	}
+
+	return statuses, nil
+}
+
+// apply runs mig unless another instance applied it first and reports
+// whether it ran.
+func (m *Migrator) apply(ctx context.Context, mig migration) (bool, error) {
+	tx, err := m.lock(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	sql, args := builder.NewSQLBuilder().
+		Select("version").
+		From("schema_migrations").
+		Where("version = ?", mig.version).
+		Build()
+
+	var version int64
+	err = tx.QueryRow(ctx, sql, args...).Scan(&version)
+	if err == nil {
+		return false, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return false, err
+	}
+
+	if _, err := tx.Exec(ctx, mig.up); err != nil {
+		return false, err
+	}
+
+	sql, args = builder.NewSQLBuilder().
+		Insert("schema_migrations").
+		Columns("version", "name").
+		Values(mig.version, mig.name).
+		Build()
+
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit(ctx)
+}
+
+func (m *Migrator) revert(ctx context.Context, mig migration) error {
+	tx, err := m.lock(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if _, err := tx.Exec(ctx, mig.down); err != nil {
+		return err
+	}
+
+	sql, args := builder.NewSQLBuilder().
+		Delete().
+		From("schema_migrations").
+		Where("version = ?", mig.version).
+		Build()
+
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// lock begins a transaction holding the migrations advisory lock.
+func (m *Migrator) lock(ctx context.Context) (pgx.Tx, error) {
+	tx, err := m.DB.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", lockID); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, err
+	}
+	return tx, nil
+}
+
+// load reads the migrations of fsys sorted by version. Every version must
+// have both an up and a down file.
+func load(fsys fs.FS) ([]migration, error) {
+	names, err := fs.Glob(fsys, "sql/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*migration)
+	for _, name := range names {
+		base := path.Base(name)
+		stem, direction, ok := cutDirection(base)
+		if !ok {
+			return nil, fmt.Errorf("migration %s: expected .up.sql or .down.sql suffix", base)
+		}
+		num, title, ok := strings.Cut(stem, "_")
+		version, err := strconv.ParseInt(num, 10, 64)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("migration %s: expected NNNN_name prefix", base)
+		}
+
+		body, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &migration{version: version, name: title}
+			byVersion[version] = mig
+		}
+		if mig.name != title {
+			return nil, fmt.Errorf("migration %s: version %d is used by %s", base, version, mig.name)
+		}
+		if direction == "up" {
+			mig.up = string(body)
+		} else {
+			mig.down = string(body)
+		}
+	}
+
+	migrations := make([]migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.up == "" || mig.down == "" {
+			return nil, fmt.Errorf("migration %04d_%s: missing up or down file", mig.version, mig.name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+
+	return migrations, nil
+}
+
+func cutDirection(name string) (string, string, bool) {
+	if stem, ok := strings.CutSuffix(name, ".up.sql"); ok {
+		return stem, "up", true
+	}
+	if stem, ok := strings.CutSuffix(name, ".down.sql"); ok {
+		return stem, "down", true
+	}
+	return "", "", false
+}
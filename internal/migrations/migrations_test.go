@@ -0,0 +1,139 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+// TestLoadEmbedded tests that the embedded migrations are complete and ordered.
+func TestLoadEmbedded(t *testing.T) {
+	migrations, err := load(files)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("Expected embedded migrations")
+	}
+	for i, mig := range migrations {
+		if mig.version != int64(i+1) {
+			t.Errorf("Expected version %d, got: %d (%s)", i+1, mig.version, mig.name)
+		}
+	}
+}
+
+// TestLoadInvalid tests that malformed migration sets are rejected.
+func TestLoadInvalid(t *testing.T) {
+	cases := map[string]fstest.MapFS{
+		"missing down": {
+			"sql/0001_a.up.sql": {Data: []byte("SELECT 1")},
+		},
+		"bad suffix": {
+			"sql/0001_a.sql": {Data: []byte("SELECT 1")},
+		},
+		"bad version": {
+			"sql/first_a.up.sql":   {Data: []byte("SELECT 1")},
+			"sql/first_a.down.sql": {Data: []byte("SELECT 1")},
+		},
+		"duplicate version": {
+			"sql/0001_a.up.sql":   {Data: []byte("SELECT 1")},
+			"sql/0001_a.down.sql": {Data: []byte("SELECT 1")},
+			"sql/0001_b.up.sql":   {Data: []byte("SELECT 1")},
+			"sql/0001_b.down.sql": {Data: []byte("SELECT 1")},
+		},
+	}
+
+	for name, fsys := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := load(fsys); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+// TestUp tests that Up applies only pending migrations.
+func TestUp(t *testing.T) {
+	mock, err := pgxmock.NewPool(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer mock.Close()
+
+	m := &Migrator{
+		DB: mock,
+		migrations: []migration{
+			{version: 1, name: "a", up: "CREATE TABLE a ()", down: "DROP TABLE a"},
+			{version: 2, name: "b", up: "CREATE TABLE b ()", down: "DROP TABLE b"},
+		},
+	}
+
+	mock.ExpectExec(createTable).WillReturnResult(pgxmock.NewResult("CREATE", 0))
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SELECT pg_advisory_xact_lock($1)").WithArgs(lockID).WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectQuery("SELECT version FROM schema_migrations WHERE version = $1").
+		WithArgs(int64(1)).
+		WillReturnRows(mock.NewRows([]string{"version"}).AddRow(int64(1)))
+	mock.ExpectRollback()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SELECT pg_advisory_xact_lock($1)").WithArgs(lockID).WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectQuery("SELECT version FROM schema_migrations WHERE version = $1").
+		WithArgs(int64(2)).
+		WillReturnRows(mock.NewRows([]string{"version"}))
+	mock.ExpectExec("CREATE TABLE b ()").WillReturnResult(pgxmock.NewResult("CREATE", 0))
+	mock.ExpectExec("INSERT INTO schema_migrations (version, name) VALUES ($1, $2)").
+		WithArgs(int64(2), "b").
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+
+	applied, err := m.Up(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if applied != 1 {
+		t.Errorf("Expected 1 applied migration, got: %d", applied)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestStatusAndDown tests that Down reverts the latest applied migration.
+func TestStatusAndDown(t *testing.T) {
+	mock, err := pgxmock.NewPool(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer mock.Close()
+
+	m := &Migrator{
+		DB: mock,
+		migrations: []migration{
+			{version: 1, name: "a", up: "CREATE TABLE a ()", down: "DROP TABLE a"},
+			{version: 2, name: "b", up: "CREATE TABLE b ()", down: "DROP TABLE b"},
+		},
+	}
+
+	mock.ExpectExec(createTable).WillReturnResult(pgxmock.NewResult("CREATE", 0))
+	mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").
+		WillReturnRows(mock.NewRows([]string{"version", "applied_at"}).AddRow(int64(1), time.Now()))
+	mock.ExpectBegin()
+	mock.ExpectExec("SELECT pg_advisory_xact_lock($1)").WithArgs(lockID).WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectExec("DROP TABLE a").WillReturnResult(pgxmock.NewResult("DROP", 0))
+	mock.ExpectExec("DELETE FROM schema_migrations WHERE version = $1").
+		WithArgs(int64(1)).
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectCommit()
+
+	if err := m.Down(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
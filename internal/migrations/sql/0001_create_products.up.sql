@@ -0,0 +1,15 @@
+CREATE TABLE products (
+    id          uuid PRIMARY KEY,
+    name        text NOT NULL,
+    description text NOT NULL DEFAULT '',
+    price       double precision NOT NULL DEFAULT 0,
+    quantity    integer NOT NULL DEFAULT 0,
+    tags        text[] NOT NULL DEFAULT '{}',
+    available   boolean NOT NULL DEFAULT true,
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    updated_at  timestamptz NOT NULL DEFAULT now(),
+    deleted_at  timestamptz
+);
+
+CREATE INDEX products_created_at_id_idx ON products (created_at DESC, id DESC) WHERE deleted_at IS NULL;
+CREATE INDEX products_tags_idx ON products USING gin (tags);
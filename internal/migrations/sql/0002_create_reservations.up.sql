@@ -0,0 +1,9 @@
+CREATE TABLE reservations (
+    id         uuid PRIMARY KEY,
+    product_id uuid NOT NULL REFERENCES products (id),
+    quantity   integer NOT NULL CHECK (quantity > 0),
+    created_at timestamptz NOT NULL DEFAULT now(),
+    expires_at timestamptz NOT NULL
+);
+
+CREATE INDEX reservations_product_id_expires_at_idx ON reservations (product_id, expires_at);
@@ -0,0 +1,3 @@
+ALTER TABLE products DROP COLUMN category_id;
+
+DROP TABLE categories;
@@ -0,0 +1,13 @@
+CREATE TABLE categories (
+    id         uuid PRIMARY KEY,
+    name       text NOT NULL,
+    parent_id  uuid REFERENCES categories (id),
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX categories_parent_id_idx ON categories (parent_id);
+
+ALTER TABLE products ADD COLUMN category_id uuid REFERENCES categories (id);
+
+CREATE INDEX products_category_id_idx ON products (category_id);
@@ -0,0 +1,3 @@
+ALTER TABLE products
+    DROP COLUMN updated_by,
+    DROP COLUMN created_by;
@@ -0,0 +1,3 @@
+ALTER TABLE products
+    ADD COLUMN created_by text,
+    ADD COLUMN updated_by text;
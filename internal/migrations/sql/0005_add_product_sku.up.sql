@@ -0,0 +1,3 @@
+ALTER TABLE products ADD COLUMN sku text;
+
+CREATE UNIQUE INDEX products_sku_key ON products (sku);
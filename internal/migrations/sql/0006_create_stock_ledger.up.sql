@@ -0,0 +1,17 @@
+CREATE TABLE stock_movements (
+    id         bigserial PRIMARY KEY,
+    product_id uuid NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    delta      integer NOT NULL,
+    reason     text NOT NULL,
+    created_by text,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX stock_movements_product_id_created_at_idx ON stock_movements (product_id, created_at);
+
+CREATE TABLE stock_snapshots (
+    taken_at   timestamptz NOT NULL,
+    product_id uuid NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    quantity   integer NOT NULL,
+    PRIMARY KEY (taken_at, product_id)
+);
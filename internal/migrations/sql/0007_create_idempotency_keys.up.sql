@@ -0,0 +1,5 @@
+CREATE TABLE idempotency_keys (
+    key        text PRIMARY KEY,
+    product_id uuid NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
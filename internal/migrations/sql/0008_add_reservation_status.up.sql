@@ -0,0 +1,4 @@
+ALTER TABLE reservations
+    ADD COLUMN order_id text NOT NULL DEFAULT '',
+    ADD COLUMN status text NOT NULL DEFAULT 'active'
+        CHECK (status IN ('active', 'confirmed', 'released'));
@@ -0,0 +1,3 @@
+ALTER TABLE products ADD COLUMN barcode text;
+
+CREATE UNIQUE INDEX products_barcode_key ON products (barcode);
@@ -0,0 +1,7 @@
+ALTER TABLE products ADD COLUMN price double precision NOT NULL DEFAULT 0;
+
+UPDATE products SET price = price_cents / 100.0;
+
+ALTER TABLE products
+    DROP COLUMN price_cents,
+    DROP COLUMN currency_code;
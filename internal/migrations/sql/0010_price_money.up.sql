@@ -0,0 +1,7 @@
+ALTER TABLE products
+    ADD COLUMN price_cents bigint NOT NULL DEFAULT 0,
+    ADD COLUMN currency_code text NOT NULL DEFAULT 'RUB' CHECK (currency_code ~ '^[A-Z]{3}$');
+
+UPDATE products SET price_cents = round(price::numeric * 100);
+
+ALTER TABLE products DROP COLUMN price;
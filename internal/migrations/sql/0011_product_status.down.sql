@@ -0,0 +1,5 @@
+ALTER TABLE products ADD COLUMN available boolean NOT NULL DEFAULT true;
+
+UPDATE products SET available = status = 'active';
+
+ALTER TABLE products DROP COLUMN status;
@@ -0,0 +1,7 @@
+ALTER TABLE products
+    ADD COLUMN status text NOT NULL DEFAULT 'draft'
+        CHECK (status IN ('draft', 'active', 'archived', 'discontinued'));
+
+UPDATE products SET status = CASE WHEN available THEN 'active' ELSE 'archived' END;
+
+ALTER TABLE products DROP COLUMN available;
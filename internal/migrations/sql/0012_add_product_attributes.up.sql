@@ -0,0 +1,4 @@
+ALTER TABLE products ADD COLUMN attributes jsonb NOT NULL DEFAULT '{}'
+    CHECK (jsonb_typeof(attributes) = 'object');
+
+CREATE INDEX products_attributes_idx ON products USING gin (attributes jsonb_path_ops);
@@ -0,0 +1,7 @@
+CREATE TABLE product_translations (
+    product_id  uuid NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    locale      text NOT NULL,
+    name        text NOT NULL,
+    description text NOT NULL DEFAULT '',
+    PRIMARY KEY (product_id, locale)
+);
@@ -0,0 +1,11 @@
+CREATE TABLE product_variants (
+    id                uuid PRIMARY KEY,
+    product_id        uuid NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    sku               text NOT NULL UNIQUE,
+    options           jsonb NOT NULL DEFAULT '{}' CHECK (jsonb_typeof(options) = 'object'),
+    price_delta_cents bigint NOT NULL DEFAULT 0,
+    quantity          integer NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    created_at        timestamptz NOT NULL DEFAULT now(),
+    updated_at        timestamptz NOT NULL DEFAULT now(),
+    UNIQUE (product_id, options)
+);
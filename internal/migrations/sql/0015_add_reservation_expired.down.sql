@@ -0,0 +1,7 @@
+DROP INDEX reservations_active_expires_at_idx;
+
+UPDATE reservations SET status = 'active' WHERE status = 'expired';
+
+ALTER TABLE reservations DROP CONSTRAINT reservations_status_check;
+ALTER TABLE reservations ADD CONSTRAINT reservations_status_check
+    CHECK (status IN ('active', 'confirmed', 'released'));
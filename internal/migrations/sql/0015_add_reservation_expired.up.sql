@@ -0,0 +1,5 @@
+ALTER TABLE reservations DROP CONSTRAINT reservations_status_check;
+ALTER TABLE reservations ADD CONSTRAINT reservations_status_check
+    CHECK (status IN ('active', 'confirmed', 'released', 'expired'));
+
+CREATE INDEX reservations_active_expires_at_idx ON reservations (expires_at) WHERE status = 'active';
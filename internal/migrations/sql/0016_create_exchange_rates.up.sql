@@ -0,0 +1,5 @@
+CREATE TABLE exchange_rates (
+    currency_code text PRIMARY KEY CHECK (currency_code ~ '^[A-Z]{3}$'),
+    rate          numeric NOT NULL CHECK (rate > 0),
+    updated_at    timestamptz NOT NULL DEFAULT now()
+);
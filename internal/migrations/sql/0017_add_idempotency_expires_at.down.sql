@@ -0,0 +1,3 @@
+DROP INDEX idempotency_keys_expires_at_idx;
+
+ALTER TABLE idempotency_keys DROP COLUMN expires_at;
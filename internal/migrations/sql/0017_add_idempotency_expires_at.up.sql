@@ -0,0 +1,5 @@
+ALTER TABLE idempotency_keys ADD COLUMN expires_at timestamptz;
+UPDATE idempotency_keys SET expires_at = created_at + interval '24 hours';
+ALTER TABLE idempotency_keys ALTER COLUMN expires_at SET NOT NULL;
+
+CREATE INDEX idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
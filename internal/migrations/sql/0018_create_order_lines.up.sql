@@ -0,0 +1,11 @@
+CREATE TABLE order_lines (
+    line_id    text PRIMARY KEY,
+    order_id   text NOT NULL,
+    product_id uuid NOT NULL,
+    quantity   integer NOT NULL CHECK (quantity > 0),
+    status     text NOT NULL CHECK (status IN ('placed', 'cancelled')),
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX order_lines_order_id_idx ON order_lines (order_id);
@@ -0,0 +1,23 @@
+CREATE TABLE tags (
+    key        text PRIMARY KEY,
+    name       text NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE tag_aliases (
+    alias      text PRIMARY KEY,
+    tag        text NOT NULL REFERENCES tags (key) ON DELETE CASCADE,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX tag_aliases_tag_idx ON tag_aliases (tag);
+
+UPDATE products p
+SET tags = ARRAY(
+    SELECT lower(u.tag)
+    FROM unnest(p.tags) WITH ORDINALITY AS u(tag, n)
+    GROUP BY lower(u.tag)
+    ORDER BY min(u.n)
+)
+WHERE EXISTS (SELECT 1 FROM unnest(p.tags) AS tag WHERE tag <> lower(tag));
@@ -0,0 +1,3 @@
+DROP TRIGGER products_audit ON products;
+DROP FUNCTION audit_product_change();
+DROP TABLE audit_entries;
@@ -0,0 +1,47 @@
+CREATE TABLE audit_entries (
+    id         bigserial PRIMARY KEY,
+    product_id uuid NOT NULL,
+    operation  text NOT NULL CHECK (operation IN ('insert', 'update', 'delete')),
+    actor      text,
+    rpc        text,
+    changes    jsonb NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX audit_entries_product_id_idx ON audit_entries (product_id, id DESC);
+CREATE INDEX audit_entries_actor_idx ON audit_entries (actor, id DESC);
+CREATE INDEX audit_entries_created_at_idx ON audit_entries (created_at);
+
+CREATE FUNCTION audit_product_change() RETURNS trigger
+LANGUAGE plpgsql AS $$
+DECLARE
+    before_row jsonb := CASE WHEN TG_OP <> 'INSERT' THEN to_jsonb(OLD) END;
+    after_row  jsonb := CASE WHEN TG_OP <> 'DELETE' THEN to_jsonb(NEW) END;
+    changes    jsonb;
+BEGIN
+    SELECT jsonb_object_agg(key, jsonb_build_object('before', before_row -> key, 'after', after_row -> key))
+    INTO changes
+    FROM jsonb_object_keys(coalesce(after_row, before_row)) AS key
+    WHERE key NOT IN ('id', 'created_at', 'created_by', 'updated_at', 'updated_by')
+      AND before_row -> key IS DISTINCT FROM after_row -> key;
+
+    IF changes IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO audit_entries (product_id, operation, actor, rpc, changes)
+    VALUES (
+        coalesce(after_row, before_row) ->> 'id',
+        lower(TG_OP),
+        coalesce(nullif(current_setting('inventory.actor', true), ''), after_row ->> 'updated_by'),
+        nullif(current_setting('inventory.rpc', true), ''),
+        changes
+    );
+    RETURN NULL;
+END;
+$$;
+
+CREATE CONSTRAINT TRIGGER products_audit
+    AFTER INSERT OR UPDATE OR DELETE ON products
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION audit_product_change();
@@ -0,0 +1,3 @@
+DROP TRIGGER products_stock_movement ON products;
+DROP FUNCTION record_stock_movement();
+ALTER TABLE stock_movements DROP COLUMN reference;
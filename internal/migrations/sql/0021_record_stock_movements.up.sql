@@ -0,0 +1,32 @@
+ALTER TABLE stock_movements ADD COLUMN reference text;
+
+CREATE FUNCTION record_stock_movement() RETURNS trigger
+LANGUAGE plpgsql AS $$
+DECLARE
+    delta  integer := NEW.quantity;
+    reason text := nullif(current_setting('inventory.stock_reason', true), '');
+    actor  text := nullif(current_setting('inventory.actor', true), '');
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        delta := NEW.quantity - OLD.quantity;
+    END IF;
+    IF delta = 0 THEN
+        RETURN NULL;
+    END IF;
+
+    IF reason IS NULL THEN
+        reason := CASE TG_OP WHEN 'INSERT' THEN 'create' ELSE 'update' END;
+        actor := coalesce(actor, NEW.updated_by);
+    END IF;
+
+    INSERT INTO stock_movements (product_id, delta, reason, reference, created_by)
+    VALUES (NEW.id, delta, reason, nullif(current_setting('inventory.stock_reference', true), ''), actor);
+
+    PERFORM set_config('inventory.stock_reason', '', true), set_config('inventory.stock_reference', '', true);
+    RETURN NULL;
+END;
+$$;
+
+CREATE TRIGGER products_stock_movement
+    AFTER INSERT OR UPDATE OF quantity ON products
+    FOR EACH ROW EXECUTE FUNCTION record_stock_movement();
@@ -0,0 +1,3 @@
+DROP TABLE reorder_suggestions;
+DROP INDEX stock_movements_created_at_idx;
+ALTER TABLE products DROP COLUMN reorder_point, DROP COLUMN reorder_quantity;
@@ -0,0 +1,18 @@
+ALTER TABLE products
+    ADD COLUMN reorder_point integer NOT NULL DEFAULT 0 CHECK (reorder_point >= 0),
+    ADD COLUMN reorder_quantity integer NOT NULL DEFAULT 0 CHECK (reorder_quantity >= 0);
+
+CREATE INDEX stock_movements_created_at_idx ON stock_movements (created_at) WHERE delta < 0;
+
+CREATE TABLE reorder_suggestions (
+    product_id         uuid PRIMARY KEY REFERENCES products (id) ON DELETE CASCADE,
+    quantity           integer NOT NULL,
+    reorder_point      integer NOT NULL,
+    reorder_quantity   integer NOT NULL,
+    daily_outflow      double precision NOT NULL,
+    days_of_cover      double precision NOT NULL,
+    suggested_quantity integer NOT NULL,
+    computed_at        timestamptz NOT NULL
+);
+
+CREATE INDEX reorder_suggestions_days_of_cover_idx ON reorder_suggestions (days_of_cover, product_id);
@@ -0,0 +1,29 @@
+CREATE OR REPLACE FUNCTION record_stock_movement() RETURNS trigger
+LANGUAGE plpgsql AS $$
+DECLARE
+    delta  integer := NEW.quantity;
+    reason text := nullif(current_setting('inventory.stock_reason', true), '');
+    actor  text := nullif(current_setting('inventory.actor', true), '');
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        delta := NEW.quantity - OLD.quantity;
+    END IF;
+    IF delta = 0 THEN
+        RETURN NULL;
+    END IF;
+
+    IF reason IS NULL THEN
+        reason := CASE TG_OP WHEN 'INSERT' THEN 'create' ELSE 'update' END;
+        actor := coalesce(actor, NEW.updated_by);
+    END IF;
+
+    INSERT INTO stock_movements (product_id, delta, reason, reference, created_by)
+    VALUES (NEW.id, delta, reason, nullif(current_setting('inventory.stock_reference', true), ''), actor);
+
+    PERFORM set_config('inventory.stock_reason', '', true), set_config('inventory.stock_reference', '', true);
+    RETURN NULL;
+END;
+$$;
+
+DROP TABLE lot_movements;
+DROP TABLE product_lots;
@@ -0,0 +1,75 @@
+CREATE TABLE product_lots (
+    id         uuid PRIMARY KEY,
+    product_id uuid NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    lot_number text NOT NULL,
+    expires_on date NOT NULL,
+    quantity   integer NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    created_at timestamptz NOT NULL DEFAULT now(),
+    UNIQUE (product_id, lot_number)
+);
+
+CREATE INDEX product_lots_picking_idx ON product_lots (product_id, expires_on, lot_number) WHERE quantity > 0;
+CREATE INDEX product_lots_expires_on_idx ON product_lots (expires_on, id) WHERE quantity > 0;
+
+CREATE TABLE lot_movements (
+    id          bigserial PRIMARY KEY,
+    lot_id      uuid NOT NULL REFERENCES product_lots (id) ON DELETE CASCADE,
+    movement_id bigint NOT NULL REFERENCES stock_movements (id) ON DELETE CASCADE,
+    delta       integer NOT NULL
+);
+
+CREATE INDEX lot_movements_lot_id_idx ON lot_movements (lot_id, id);
+CREATE INDEX lot_movements_movement_id_idx ON lot_movements (movement_id);
+
+CREATE OR REPLACE FUNCTION record_stock_movement() RETURNS trigger
+LANGUAGE plpgsql AS $$
+DECLARE
+    delta    integer := NEW.quantity;
+    reason   text := nullif(current_setting('inventory.stock_reason', true), '');
+    actor    text := nullif(current_setting('inventory.actor', true), '');
+    lot      uuid := nullif(current_setting('inventory.stock_lot', true), '')::uuid;
+    movement bigint;
+    pick     record;
+    wanted   integer;
+    taken    integer;
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        delta := NEW.quantity - OLD.quantity;
+    END IF;
+    IF delta = 0 THEN
+        RETURN NULL;
+    END IF;
+
+    IF reason IS NULL THEN
+        reason := CASE TG_OP WHEN 'INSERT' THEN 'create' ELSE 'update' END;
+        actor := coalesce(actor, NEW.updated_by);
+    END IF;
+
+    INSERT INTO stock_movements (product_id, delta, reason, reference, created_by)
+    VALUES (NEW.id, delta, reason, nullif(current_setting('inventory.stock_reference', true), ''), actor)
+    RETURNING id INTO movement;
+
+    IF lot IS NOT NULL THEN
+        UPDATE product_lots SET quantity = quantity + delta WHERE id = lot;
+        INSERT INTO lot_movements (lot_id, movement_id, delta) VALUES (lot, movement, delta);
+    ELSIF delta < 0 THEN
+        wanted := -delta;
+        FOR pick IN
+            SELECT id, quantity FROM product_lots
+            WHERE product_id = NEW.id AND quantity > 0
+            ORDER BY expires_on, lot_number
+            FOR UPDATE
+        LOOP
+            taken := least(pick.quantity, wanted);
+            UPDATE product_lots SET quantity = quantity - taken WHERE id = pick.id;
+            INSERT INTO lot_movements (lot_id, movement_id, delta) VALUES (pick.id, movement, -taken);
+            wanted := wanted - taken;
+            EXIT WHEN wanted = 0;
+        END LOOP;
+    END IF;
+
+    PERFORM set_config('inventory.stock_reason', '', true), set_config('inventory.stock_reference', '', true),
+        set_config('inventory.stock_lot', '', true);
+    RETURN NULL;
+END;
+$$;
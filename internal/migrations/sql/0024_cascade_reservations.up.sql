@@ -0,0 +1,3 @@
+ALTER TABLE reservations DROP CONSTRAINT reservations_product_id_fkey;
+ALTER TABLE reservations ADD CONSTRAINT reservations_product_id_fkey
+    FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE;
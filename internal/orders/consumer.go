@@ -0,0 +1,238 @@
+// Package orders consumes the events of the order service and applies
+// them to the stock: placed orders take the stock of their lines and
+// cancelled orders give it back.
+package orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/andro-kes/inventory_service/internal/events"
+	"github.com/andro-kes/inventory_service/internal/repo"
+	pb "github.com/andro-kes/inventory_service/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Message is a Kafka record read by a Reader.
+type Message struct {
+	Topic     string
+	Partition int
+	Offset    int64
+	Key       []byte
+	Value     []byte
+	Headers   []events.Header
+}
+
+// Reader reads the records of a consumer group, e.g. the Reader of
+// kafka-go with a GroupID. Offsets are committed explicitly, once the
+// records are handled.
+type Reader interface {
+	FetchMessage(ctx context.Context) (Message, error)
+	CommitMessages(ctx context.Context, msgs ...Message) error
+}
+
+// Service applies order lines to the stock; see services.ProductService.
+type Service interface {
+	PlaceOrderLine(ctx context.Context, orderID string, line *pb.OrderLine) (bool, error)
+	CancelOrderLine(ctx context.Context, orderID string, line *pb.OrderLine) (bool, error)
+}
+
+// Topics are the topics the Consumer reads and writes.
+type Topics struct {
+	// Placed receives OrderPlaced.
+	Placed string
+	// Cancelled receives OrderCancelled.
+	Cancelled string
+	// DeadLetter receives the messages that cannot be applied.
+	DeadLetter string
+}
+
+var DefaultTopics = Topics{
+	Placed:     "orders.placed",
+	Cancelled:  "orders.cancelled",
+	DeadLetter: "inventory.orders.dlq",
+}
+
+// Headers of the dead letters, next to the headers of the message.
+const (
+	ErrorHeader     = "dlq-error"
+	TopicHeader     = "dlq-topic"
+	PartitionHeader = "dlq-partition"
+	OffsetHeader    = "dlq-offset"
+)
+
+// DefaultRetry is the Retry of NewConsumer.
+var DefaultRetry = repo.RetryPolicy{
+	BaseDelay: 100 * time.Millisecond,
+	MaxDelay:  30 * time.Second,
+}
+
+// Consumer applies the order events read by Reader through Service.
+// Every message is handled before its offset is committed, so a crash
+// redelivers it; lines are applied once per line id, so redelivered
+// events change nothing. Messages that cannot be applied, e.g. malformed
+// ones or lines of unknown products or without enough stock, are written
+// to the dead letter topic, so they block nothing and can be replayed
+// once fixed. Transient failures are retried without committing, holding
+// up the partition until the database is back.
+type Consumer struct {
+	Reader  Reader
+	Service Service
+	// DeadLetters writes to Topics.DeadLetter.
+	DeadLetters events.Writer
+	Topics      Topics
+	// Retry spaces the retries of transient failures. Its MaxAttempts is
+	// ignored: they are retried until Run returns.
+	Retry repo.RetryPolicy
+	// OnError is called with the failures of messages: dead letters,
+	// transient errors before they are retried and failed commits.
+	OnError func(msg Message, err error)
+}
+
+// NewConsumer returns a Consumer applying the events of r through s and
+// writing dead letters to dlq.
+func NewConsumer(r Reader, s Service, dlq events.Writer, topics Topics) *Consumer {
+	return &Consumer{
+		Reader:      r,
+		Service:     s,
+		DeadLetters: dlq,
+		Topics:      topics,
+		Retry:       DefaultRetry,
+	}
+}
+
+// Run handles messages until ctx is done or reading fails, and returns
+// the error it stopped with.
+func (c *Consumer) Run(ctx context.Context) error {
+	for {
+		msg, err := c.Reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("fetch order event: %w", err)
+		}
+		if err := c.handle(ctx, msg); err != nil {
+			// Only ctx ends the retries of a message; it is redelivered
+			// after a restart.
+			return err
+		}
+		if err := c.Reader.CommitMessages(ctx, msg); err != nil {
+			// The next commit covers the offset of msg too.
+			c.fail(msg, fmt.Errorf("commit order event: %w", err))
+		}
+	}
+}
+
+// handle applies msg, or writes it to the dead letter topic if it cannot
+// be applied, retrying transient failures of both until ctx is done.
+func (c *Consumer) handle(ctx context.Context, msg Message) error {
+	for attempt := 1; ; attempt++ {
+		err := c.apply(ctx, msg)
+		if err == nil {
+			return nil
+		}
+		c.fail(msg, err)
+		if !Transient(err) {
+			if err = c.deadLetter(ctx, msg, err); err == nil {
+				return nil
+			}
+			c.fail(msg, err)
+		}
+
+		timer := time.NewTimer(c.Retry.Delay(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// apply applies all lines of msg and returns the first error of a line;
+// a transient error takes precedence, so the message is retried.
+func (c *Consumer) apply(ctx context.Context, msg Message) error {
+	var orderID string
+	var lines []*pb.OrderLine
+	var applyLine func(ctx context.Context, orderID string, line *pb.OrderLine) (bool, error)
+	switch msg.Topic {
+	case c.Topics.Placed:
+		var event pb.OrderPlaced
+		if err := proto.Unmarshal(msg.Value, &event); err != nil {
+			return fmt.Errorf("decode OrderPlaced: %w", err)
+		}
+		orderID, lines, applyLine = event.GetOrderId(), event.GetLines(), c.Service.PlaceOrderLine
+	case c.Topics.Cancelled:
+		var event pb.OrderCancelled
+		if err := proto.Unmarshal(msg.Value, &event); err != nil {
+			return fmt.Errorf("decode OrderCancelled: %w", err)
+		}
+		orderID, lines, applyLine = event.GetOrderId(), event.GetLines(), c.Service.CancelOrderLine
+	default:
+		return fmt.Errorf("unexpected topic %q", msg.Topic)
+	}
+
+	// Every line is tried: applied lines are skipped when the message is
+	// retried or replayed, so the failure of one holds up no other.
+	var failed error
+	for i, line := range lines {
+		_, err := applyLine(ctx, orderID, line)
+		if err == nil {
+			continue
+		}
+		err = fmt.Errorf("order %s line %d (%s): %w", orderID, i, line.GetLineId(), err)
+		if failed == nil || Transient(err) && !Transient(failed) {
+			failed = err
+		}
+	}
+	return failed
+}
+
+// deadLetter writes msg to the dead letter topic with the reason err.
+func (c *Consumer) deadLetter(ctx context.Context, msg Message, err error) error {
+	headers := append([]events.Header{
+		{Key: ErrorHeader, Value: []byte(err.Error())},
+		{Key: TopicHeader, Value: []byte(msg.Topic)},
+		{Key: PartitionHeader, Value: []byte(strconv.Itoa(msg.Partition))},
+		{Key: OffsetHeader, Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+	}, msg.Headers...)
+	dead := events.Message{
+		Topic:   c.Topics.DeadLetter,
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	}
+	if err := c.DeadLetters.WriteMessages(ctx, dead); err != nil {
+		// Without its dead letter the message must not be committed.
+		return fmt.Errorf("write dead letter: %w", err)
+	}
+	return nil
+}
+
+func (c *Consumer) fail(msg Message, err error) {
+	if c.OnError != nil {
+		c.OnError(msg, err)
+	}
+}
+
+// Transient reports whether err is worth retrying: connection failures,
+// conflicts and overload, as opposed to events that can never apply.
+func Transient(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	if repo.IsTransient(err) {
+		return true
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.Aborted, codes.ResourceExhausted, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
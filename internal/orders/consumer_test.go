@@ -0,0 +1,167 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andro-kes/inventory_service/internal/events"
+	"github.com/andro-kes/inventory_service/internal/inverr"
+	"github.com/andro-kes/inventory_service/internal/repo"
+	pb "github.com/andro-kes/inventory_service/proto"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// fakeReader returns msgs, then calls drained and blocks until ctx is
+// done.
+type fakeReader struct {
+	msgs      []Message
+	committed []int64
+	drained   func()
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (Message, error) {
+	if len(r.msgs) == 0 {
+		if r.drained != nil {
+			r.drained()
+		}
+		<-ctx.Done()
+		return Message{}, ctx.Err()
+	}
+	msg := r.msgs[0]
+	r.msgs = r.msgs[1:]
+	return msg, nil
+}
+
+func (r *fakeReader) CommitMessages(ctx context.Context, msgs ...Message) error {
+	for _, m := range msgs {
+		r.committed = append(r.committed, m.Offset)
+	}
+	return nil
+}
+
+// fakeService applies lines once per line id, failing with the errors of
+// errs first.
+type fakeService struct {
+	stock map[string]int32
+	lines map[string]bool
+	errs  map[string][]error
+}
+
+func (s *fakeService) apply(line *pb.OrderLine, delta int32) (bool, error) {
+	if errs := s.errs[line.GetLineId()]; len(errs) > 0 {
+		s.errs[line.GetLineId()] = errs[1:]
+		return false, errs[0]
+	}
+	if s.lines[line.GetLineId()] {
+		return false, nil
+	}
+	s.lines[line.GetLineId()] = true
+	s.stock[line.GetProductId()] += delta
+	return true, nil
+}
+
+func (s *fakeService) PlaceOrderLine(ctx context.Context, orderID string, line *pb.OrderLine) (bool, error) {
+	return s.apply(line, -line.GetQuantity())
+}
+
+func (s *fakeService) CancelOrderLine(ctx context.Context, orderID string, line *pb.OrderLine) (bool, error) {
+	return s.apply(&pb.OrderLine{LineId: line.GetLineId() + "/cancel", ProductId: line.GetProductId()}, line.GetQuantity())
+}
+
+type fakeWriter struct {
+	msgs []events.Message
+}
+
+func (w *fakeWriter) WriteMessages(ctx context.Context, msgs ...events.Message) error {
+	w.msgs = append(w.msgs, msgs...)
+	return nil
+}
+
+func message(t *testing.T, topic string, offset int64, event proto.Message) Message {
+	t.Helper()
+	value, err := proto.Marshal(event)
+	require.NoError(t, err)
+	return Message{Topic: topic, Offset: offset, Key: []byte("o1"), Value: value}
+}
+
+func TestConsumer(t *testing.T) {
+	lines := []*pb.OrderLine{
+		{LineId: "l1", ProductId: "p1", Quantity: 2},
+		{LineId: "l2", ProductId: "p2", Quantity: 1},
+	}
+	placed := &pb.OrderPlaced{OrderId: "o1", Lines: lines}
+	reader := &fakeReader{msgs: []Message{
+		message(t, DefaultTopics.Placed, 1, placed),
+		// Redelivered.
+		message(t, DefaultTopics.Placed, 2, placed),
+		{Topic: DefaultTopics.Placed, Offset: 3, Value: []byte("not protobuf")},
+		message(t, DefaultTopics.Cancelled, 4, &pb.OrderCancelled{OrderId: "o1", Lines: lines[:1]}),
+	}}
+	service := &fakeService{
+		stock: map[string]int32{"p1": 10, "p2": 10},
+		lines: make(map[string]bool),
+		errs: map[string][]error{
+			// A lost connection is retried.
+			"l2": {&pgconn.PgError{Code: "08006"}},
+		},
+	}
+	dlq := &fakeWriter{}
+	c := NewConsumer(reader, service, dlq, DefaultTopics)
+	c.Retry = repo.RetryPolicy{BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	var failures int
+	c.OnError = func(msg Message, err error) { failures++ }
+
+	ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+	defer cancel()
+	reader.drained = cancel
+	err := c.Run(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	assert.Equal(t, []int64{1, 2, 3, 4}, reader.committed)
+	assert.Equal(t, map[string]int32{"p1": 10, "p2": 9}, service.stock)
+	require.Len(t, dlq.msgs, 1)
+	assert.Equal(t, DefaultTopics.DeadLetter, dlq.msgs[0].Topic)
+	assert.Equal(t, []byte("not protobuf"), dlq.msgs[0].Value)
+	assert.Equal(t, events.Header{Key: OffsetHeader, Value: []byte("3")}, dlq.msgs[0].Headers[3])
+	assert.Equal(t, 2, failures)
+}
+
+func TestConsumerDeadLetters(t *testing.T) {
+	// A line without enough stock is a dead letter; the other line of the
+	// message is applied.
+	placed := &pb.OrderPlaced{OrderId: "o1", Lines: []*pb.OrderLine{
+		{LineId: "l1", ProductId: "p1", Quantity: 20},
+		{LineId: "l2", ProductId: "p2", Quantity: 1},
+	}}
+	service := &fakeService{
+		stock: map[string]int32{"p1": 10, "p2": 10},
+		lines: make(map[string]bool),
+		errs: map[string][]error{
+			"l1": {&repo.InsufficientStockError{ID: "p1", Requested: 20, Available: 10}},
+		},
+	}
+	dlq := &fakeWriter{}
+	c := NewConsumer(&fakeReader{}, service, dlq, DefaultTopics)
+
+	require.NoError(t, c.handle(t.Context(), message(t, DefaultTopics.Placed, 1, placed)))
+	assert.Equal(t, int32(9), service.stock["p2"])
+	require.Len(t, dlq.msgs, 1)
+	assert.Contains(t, string(dlq.msgs[0].Headers[0].Value), "insufficient stock for product p1")
+}
+
+func TestTransient(t *testing.T) {
+	cases := map[error]bool{
+		&pgconn.PgError{Code: "40001"}:                                     true,
+		inverr.Throttled(0xe, inverr.ReasonDatabaseUnavailable, 0, "down"): true,
+		inverr.InvalidField("quantity", "quantity must be positive"):       false,
+		errors.New("decode OrderPlaced"):                                   false,
+	}
+	for err, want := range cases {
+		assert.Equal(t, want, Transient(err), "%v", err)
+	}
+}
@@ -0,0 +1,35 @@
+package repo
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/andro-kes/inventory_service/internal/inverr"
+	pb "github.com/andro-kes/inventory_service/proto"
+)
+
+// maxAttributes bounds the attributes of a product.
+const maxAttributes = 100
+
+// attributeKey matches the keys of Product.attributes; '.' is left out so
+// update_mask paths "attributes.<key>" are unambiguous.
+var attributeKey = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// validateAttributes checks the attribute keys of p and their number.
+func validateAttributes(p *pb.Product) error {
+	if len(p.GetAttributes()) > maxAttributes {
+		return inverr.InvalidField("attributes", "at most %d attributes are allowed", maxAttributes)
+	}
+	for key := range p.GetAttributes() {
+		if !attributeKey.MatchString(key) {
+			return inverr.InvalidField("attributes", "invalid attribute key %q", key)
+		}
+	}
+	return nil
+}
+
+// attributePath returns the key of an update_mask path "attributes.<key>".
+func attributePath(path string) (string, bool) {
+	key, ok := strings.CutPrefix(path, "attributes.")
+	return key, ok && attributeKey.MatchString(key)
+}
@@ -0,0 +1,63 @@
+package repo
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	pb "github.com/andro-kes/inventory_service/proto"
+	"github.com/pashagolub/pgxmock/v4"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func TestValidateAttributes(t *testing.T) {
+	cases := []struct {
+		attributes map[string]string
+		valid      bool
+	}{
+		{nil, true},
+		{map[string]string{"color": "red", "screen_size-in": "14"}, true},
+		{map[string]string{"": "red"}, false},
+		{map[string]string{"a.b": "red"}, false},
+		{map[string]string{strings.Repeat("k", 65): "red"}, false},
+	}
+
+	for _, tc := range cases {
+		err := validateAttributes(&pb.Product{Attributes: tc.attributes})
+		if tc.valid && err != nil {
+			t.Errorf("Expected %v to be valid, got: %v", tc.attributes, err)
+		}
+		if !tc.valid && status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument for %v, got: %v", tc.attributes, err)
+		}
+	}
+}
+
+// TestRepoUpdateAttributeKeys tests that "attributes.<key>" paths set the
+// keys present in the product and remove the others.
+func TestRepoUpdateAttributeKeys(t *testing.T) {
+	pr, mock := newMockRepo(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE products SET attributes = (attributes - $1::text[]) || $2::jsonb, "+
+		"updated_at = $3, updated_by = NULL WHERE id = $4 AND deleted_at IS NULL RETURNING "+strings.Join(productColumns, ", ")).
+		WithArgs([]string{"size"}, `{"color":"red"}`, pgxmock.AnyArg(), "1").
+		WillReturnRows(productRows(mock, "1"))
+	mock.ExpectExec(notifyChangeSQL).
+		WithArgs(ChangesChannel, `{"id":"1","op":"update"}`, "", "").
+		WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectCommit()
+
+	p := &pb.Product{Id: "1", Attributes: map[string]string{"color": "red"}}
+	mask := &fieldmaskpb.FieldMask{Paths: []string{"attributes.color", "attributes.size"}}
+	if _, err := pr.Update(context.Background(), p, mask); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	mask.Paths = append(mask.Paths, "attributes")
+	if _, err := pr.Update(context.Background(), p, mask); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for mixed paths, got: %v", err)
+	}
+}
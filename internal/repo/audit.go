@@ -0,0 +1,159 @@
+package repo
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andro-kes/inventory_service/internal/repo/builder"
+)
+
+// AuditEntry is a change of a product, recorded by the products_audit
+// trigger when the transaction of the change commits.
+type AuditEntry struct {
+	ID        int64  `db:"id"`
+	ProductID string `db:"product_id"`
+	// Operation is insert, update or delete. Soft deletes and restores are
+	// updates of deleted_at; deletes are purges.
+	Operation string `db:"operation"`
+	// Actor is the principal of the change, nil for anonymous requests
+	// and background jobs.
+	Actor *string `db:"actor"`
+	// RPC is the full gRPC method of the change, e.g.
+	// "/inventory.InventoryService/UpdateProduct", nil outside requests.
+	RPC *string `db:"rpc"`
+	// Changes are the changed columns of the product, by name. The
+	// created_* and updated_* columns are left out.
+	Changes   map[string]FieldChange `db:"changes"`
+	CreatedAt time.Time              `db:"created_at"`
+}
+
+// FieldChange is the value of a column before and after a change, as
+// JSON; null for the columns of inserted and deleted rows.
+type FieldChange struct {
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+// AuditFilter narrows the entries of AuditRepo.List. Zero-valued fields
+// do not filter.
+type AuditFilter struct {
+	ProductID string
+	Actor     string
+	// Since and Until bound the time of the entries: Since inclusive,
+	// Until exclusive.
+	Since time.Time
+	Until time.Time
+}
+
+// AuditRepo reads the audit trail of the products.
+type AuditRepo interface {
+	// List returns a page of the entries matching filter, newest first,
+	// following the entry of pageToken, and the token of the next page,
+	// empty on the last one. A non-positive pageSize means
+	// defaultPageSize, larger ones are capped at maxPageSize. Tokens it
+	// did not issue fail with ErrInvalidCursor.
+	List(ctx context.Context, filter AuditFilter, pageToken string, pageSize int32) ([]*AuditEntry, string, error)
+}
+
+type auditRepo struct {
+	DB Querier
+}
+
+// NewAuditRepo returns an AuditRepo running its queries on db.
+func NewAuditRepo(ctx context.Context, db Querier) AuditRepo {
+	return &tracingAuditRepo{
+		next: &auditRepo{
+			DB: db,
+		},
+		tracer: newTracer("AuditRepo"),
+	}
+}
+
+func (ar *auditRepo) db(ctx context.Context) Querier {
+	return conn(ctx, ar.DB)
+}
+
+func (ar *auditRepo) List(ctx context.Context, filter AuditFilter, pageToken string, pageSize int32) ([]*AuditEntry, string, error) {
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	pageSize = min(pageSize, maxPageSize)
+
+	// One extra row tells whether there is a next page.
+	b := newQuery(ctx).
+		Select(builder.StructColumns(AuditEntry{})...).
+		From("audit_entries").
+		OrderBy("id", builder.Desc).
+		Limit(int(pageSize) + 1)
+	if filter.ProductID != "" {
+		b.Where("product_id = ?", filter.ProductID)
+	}
+	if filter.Actor != "" {
+		b.Where("actor = ?", filter.Actor)
+	}
+	if !filter.Since.IsZero() {
+		b.Where("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		b.Where("created_at < ?", filter.Until)
+	}
+	if pageToken != "" {
+		before, err := decodeAuditToken(pageToken)
+		if err != nil {
+			return nil, "", err
+		}
+		b.Where("id < ?", before)
+	}
+	sql, args := b.Build()
+
+	rows, err := ar.db(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	entries := make([]*AuditEntry, 0)
+	for rows.Next() {
+		var e AuditEntry
+		if err := rows.Scan(&e.ID, &e.ProductID, &e.Operation, &e.Actor, &e.RPC, &e.Changes, &e.CreatedAt); err != nil {
+			return nil, "", err
+		}
+		entries = append(entries, &e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	if len(entries) <= int(pageSize) {
+		return entries, "", nil
+	}
+	entries = entries[:pageSize]
+	return entries, encodeAuditToken(entries[len(entries)-1].ID), nil
+}
+
+// encodeAuditToken returns an opaque page token pointing after the entry
+// id.
+func encodeAuditToken(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("audit|" + strconv.FormatInt(id, 10)))
+}
+
+// decodeAuditToken reverses encodeAuditToken.
+func decodeAuditToken(token string) (int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+	s, ok := strings.CutPrefix(string(raw), "audit|")
+	if !ok {
+		return 0, ErrInvalidCursor
+	}
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, ErrInvalidCursor
+	}
+	return id, nil
+}
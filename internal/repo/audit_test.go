@@ -0,0 +1,59 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+const selectAuditEntries = "SELECT id, product_id, operation, actor, rpc, changes, created_at FROM audit_entries "
+
+// TestAuditList tests filtering and paging through the audit trail.
+func TestAuditList(t *testing.T) {
+	_, mock := newMockRepo(t)
+	ar := &auditRepo{DB: mock}
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	actor := "alice"
+	rpc := "/inventory.InventoryService/UpdateProduct"
+	changes := map[string]FieldChange{"price_cents": {Before: []byte("100"), After: []byte("150")}}
+	columns := []string{"id", "product_id", "operation", "actor", "rpc", "changes", "created_at"}
+
+	mock.ExpectQuery(selectAuditEntries+"WHERE product_id = $1 AND actor = $2 AND created_at >= $3 ORDER BY id DESC LIMIT 3").
+		WithArgs(testProductID, actor, since).
+		WillReturnRows(mock.NewRows(columns).
+			AddRow(int64(9), testProductID, "update", &actor, &rpc, changes, since).
+			AddRow(int64(7), testProductID, "update", &actor, &rpc, changes, since).
+			AddRow(int64(4), testProductID, "update", &actor, &rpc, changes, since))
+
+	filter := AuditFilter{ProductID: testProductID, Actor: actor, Since: since}
+	entries, next, err := ar.List(context.Background(), filter, "", 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(entries) != 2 || next == "" {
+		t.Fatalf("Expected a page of 2 entries and a next page, got %d and %q", len(entries), next)
+	}
+	if string(entries[0].Changes["price_cents"].After) != "150" {
+		t.Errorf("Expected the new price, got: %+v", entries[0].Changes)
+	}
+
+	mock.ExpectQuery(selectAuditEntries+"WHERE product_id = $1 AND actor = $2 AND created_at >= $3 AND id < $4 ORDER BY id DESC LIMIT 3").
+		WithArgs(testProductID, actor, since, int64(7)).
+		WillReturnRows(mock.NewRows(columns).
+			AddRow(int64(4), testProductID, "insert", nil, nil, changes, since))
+
+	entries, next, err = ar.List(context.Background(), filter, next, 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(entries) != 1 || next != "" || entries[0].Actor != nil {
+		t.Errorf("Expected the last page with an anonymous entry, got %d entries and %q", len(entries), next)
+	}
+
+	for _, token := range []string{"!", encodeCursor(defaultPageOrder, ListFilter{}, time.Now(), "1")} {
+		if _, _, err := ar.List(context.Background(), AuditFilter{}, token, 0); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("Expected ErrInvalidCursor for %q, got: %v", token, err)
+		}
+	}
+}
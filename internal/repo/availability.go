@@ -0,0 +1,67 @@
+package repo
+
+import (
+	"context"
+
+	pb "github.com/andro-kes/inventory_service/proto"
+	"github.com/google/uuid"
+)
+
+// Stock is the stock of a product as seen by a buyer.
+type Stock struct {
+	// Available is whether the product is on sale, i.e. ACTIVE.
+	Available bool
+	// Free is the quantity less the units held by active reservations.
+	Free int32
+}
+
+// freeQuantity computes the Stock.Free of a products row.
+const freeQuantity = "quantity - COALESCE((SELECT SUM(r.quantity) FROM reservations r " +
+	"WHERE r.product_id = products.id AND r.status = '" + ReservationActive + "' AND r.expires_at > now()), 0)"
+
+// StockOf returns the stock of the products ids in one query, e.g. to
+// validate a cart. Unknown, deleted and malformed ids are left out of the
+// result.
+func (pr *productRepo) StockOf(ctx context.Context, ids []string) (map[string]Stock, error) {
+	valid := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if uuid.Validate(id) == nil {
+			valid = append(valid, id)
+		}
+	}
+	stock := make(map[string]Stock, len(valid))
+	if len(valid) == 0 {
+		return stock, nil
+	}
+
+	sql, args := productsQuery(ctx).
+		Select("id", "status = '"+statusColumn(pb.Product_ACTIVE)+"'", freeQuantity).
+		From("products").
+		Where("id = ANY(?)", valid).
+		Build()
+
+	err := pr.withStatementTimeout(ctx, func(ctx context.Context) error {
+		rows, err := pr.db(ctx).Query(ctx, sql, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var (
+				id        string
+				available bool
+				free      int64
+			)
+			if err := rows.Scan(&id, &available, &free); err != nil {
+				return err
+			}
+			stock[id] = Stock{Available: available, Free: int32(max(free, 0))}
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stock, nil
+}
@@ -0,0 +1,25 @@
+package repo
+
+import (
+	"context"
+	"testing"
+)
+
+// TestRepoStockOf tests that the stock of several products is read in one
+// query and malformed ids are skipped.
+func TestRepoStockOf(t *testing.T) {
+	pr, mock := newMockRepo(t)
+	id := "0b7f1c9e-3d2a-4c1b-9a55-2f0c8c1d6e41"
+
+	mock.ExpectQuery("SELECT id, status = 'active', " + freeQuantity + " FROM products WHERE id = ANY($1) AND deleted_at IS NULL").
+		WithArgs([]string{id}).
+		WillReturnRows(mock.NewRows([]string{"id", "available", "free"}).AddRow(id, true, int64(4)))
+
+	stock, err := pr.StockOf(context.Background(), []string{id, "not-a-uuid"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(stock) != 1 || stock[id] != (Stock{Available: true, Free: 4}) {
+		t.Errorf("Unexpected stock: %+v", stock)
+	}
+}
@@ -0,0 +1,89 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/andro-kes/inventory_service/internal/inverr"
+	pb "github.com/andro-kes/inventory_service/proto"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// validateProduct checks the fields of p the database cannot.
+func validateProduct(p *pb.Product) error {
+	if err := validatePrice(p); err != nil {
+		return err
+	}
+	if err := validateStatus(p); err != nil {
+		return err
+	}
+	if err := validateAttributes(p); err != nil {
+		return err
+	}
+	return validateBarcode(p.GetBarcode())
+}
+
+// validateBarcode checks that barcode, if set, is a GTIN: 8 (EAN-8), 12
+// (UPC-A), 13 (EAN-13) or 14 (GTIN-14) digits ending in the GS1 check
+// digit.
+func validateBarcode(barcode string) error {
+	if barcode == "" {
+		return nil
+	}
+	switch len(barcode) {
+	case 8, 12, 13, 14:
+	default:
+		return inverr.InvalidField("barcode", "barcode %s must have 8, 12, 13 or 14 digits", barcode)
+	}
+
+	// Digits are weighted 3 and 1 alternately from the right, starting
+	// with the digit left of the check digit.
+	sum := 0
+	for i := len(barcode) - 1; i >= 0; i-- {
+		c := barcode[i]
+		if c < '0' || c > '9' {
+			return inverr.InvalidField("barcode", "barcode %s must contain digits only", barcode)
+		}
+		digit := int(c - '0')
+		if i == len(barcode)-1 {
+			continue
+		}
+		if (len(barcode)-1-i)%2 == 1 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	if check := (10 - sum%10) % 10; int(barcode[len(barcode)-1]-'0') != check {
+		return inverr.InvalidField("barcode", "barcode %s has an invalid check digit", barcode)
+	}
+	return nil
+}
+
+// GetByBarcode returns the product with the given barcode, using the
+// unique index on products.barcode.
+func (pr *productRepo) GetByBarcode(ctx context.Context, barcode string) (*pb.Product, error) {
+	if barcode == "" {
+		return nil, inverr.InvalidField("barcode", "barcode is required")
+	}
+
+	sql, args := productsQuery(ctx).
+		Select(productColumns...).
+		From("products").
+		Where("barcode = ?", barcode).
+		Build()
+
+	var row productRow
+	err := pr.withStatementTimeout(ctx, func(ctx context.Context) error {
+		return row.scan(pr.db(ctx).QueryRow(ctx, sql, args...))
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, status.Errorf(codes.NotFound, "product with barcode %s not found", barcode)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return row.toProto(), nil
+}
@@ -0,0 +1,90 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pb "github.com/andro-kes/inventory_service/proto"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestValidateBarcode(t *testing.T) {
+	cases := []struct {
+		barcode string
+		valid   bool
+	}{
+		{"", true},
+		{"96385074", true},       // EAN-8
+		{"036000291452", true},   // UPC-A
+		{"4006381333931", true},  // EAN-13
+		{"10614141000415", true}, // GTIN-14
+		{"4006381333932", false}, // wrong check digit
+		{"400638133393", false},  // 12 digits, wrong check digit
+		{"40063813339", false},   // 11 digits
+		{"40063813339a", false},  // not a digit
+	}
+
+	for _, tc := range cases {
+		err := validateBarcode(tc.barcode)
+		if tc.valid && err != nil {
+			t.Errorf("Expected %q to be valid, got: %v", tc.barcode, err)
+		}
+		if !tc.valid && status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument for %q, got: %v", tc.barcode, err)
+		}
+	}
+}
+
+// TestRepoGetByBarcode tests the barcode lookup and its NotFound mapping.
+func TestRepoGetByBarcode(t *testing.T) {
+	pr, mock := newMockRepo(t)
+
+	mock.ExpectQuery(selectProduct + " WHERE barcode = $1 AND deleted_at IS NULL").
+		WithArgs("4006381333931").
+		WillReturnRows(productRows(mock, "1"))
+	mock.ExpectQuery(selectProduct + " WHERE barcode = $1 AND deleted_at IS NULL").
+		WithArgs("96385074").
+		WillReturnError(pgx.ErrNoRows)
+
+	p, err := pr.GetByBarcode(context.Background(), "4006381333931")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if p.GetId() != "1" {
+		t.Errorf("Unexpected product: %v", p)
+	}
+
+	if _, err := pr.GetByBarcode(context.Background(), "96385074"); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound, got: %v", err)
+	}
+}
+
+// TestRepoCreateInvalidBarcode tests that an invalid barcode is rejected
+// before reaching the database.
+func TestRepoCreateInvalidBarcode(t *testing.T) {
+	pr, _ := newMockRepo(t)
+
+	_, err := pr.Create(context.Background(), &pb.Product{Name: "scanner", Barcode: "4006381333932"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected InvalidArgument, got: %v", err)
+	}
+}
+
+func TestDuplicateKeyError(t *testing.T) {
+	p := &pb.Product{Sku: "SKU-1", Barcode: "96385074"}
+	for _, constraint := range []string{"products_sku_key", "products_barcode_key"} {
+		err := duplicateKeyError(&pgconn.PgError{Code: "23505", ConstraintName: constraint}, p)
+		if status.Code(err) != codes.AlreadyExists {
+			t.Errorf("Expected AlreadyExists for %s, got: %v", constraint, err)
+		}
+	}
+
+	other := errors.New("connection reset")
+	if err := duplicateKeyError(other, p); err != other {
+		t.Errorf("Expected the error as is, got: %v", err)
+	}
+}
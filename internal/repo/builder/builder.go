@@ -1,16 +1,24 @@
 package builder
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"strings"
 )
 
+// ErrPlaceholderMismatch is reported by Err when the number of ?
+// placeholders in a clause differs from the number of its arguments.
+var ErrPlaceholderMismatch = errors.New("placeholder count does not match argument count")
+
 // SQLBuilder provides a chainable API for building SQL queries.
-// It supports SELECT, INSERT, UPDATE, and DELETE operations with
+// It supports SELECT, INSERT, UPDATE, DELETE and TRUNCATE operations with
 // a fluent interface for constructing queries dynamically.
 //
-// The builder generates PostgreSQL-compatible queries with positional
-// placeholders ($1, $2, ...) for use with pgx and other PostgreSQL drivers.
+// By default the builder generates PostgreSQL-compatible queries with
+// positional placeholders ($1, $2, ...) for use with pgx and other
+// PostgreSQL drivers. Other dialects can be selected with WithDialect.
 //
 // Example usage:
 //
@@ -51,18 +59,58 @@ import (
 //		Returning("id").
 //		Build()
 //	// Result: DELETE FROM products WHERE id = $1 RETURNING id
+//
+//	// SELECT with JOIN
+//	query, args := NewSQLBuilder().
+//		Select("p.id", "c.name").
+//		From("products p").
+//		LeftJoin("categories c", "c.id = p.category_id").
+//		Where("p.price > ?", 100).
+//		Build()
+//	// Result: SELECT p.id, c.name FROM products p LEFT JOIN categories c ON c.id = p.category_id WHERE p.price > $1
 type SQLBuilder struct {
-	queryType  string   // SELECT, INSERT, UPDATE, DELETE
+	queryType  string   // SELECT, INSERT, UPDATE, DELETE, TRUNCATE
 	selectCols []string // Columns for SELECT
 	tableName  string   // Table name
 	insertCols []string // Columns for INSERT
+	values     [][]any  // Rows of values for INSERT
 	returning  []string
-	values     []any
+	distinct   bool
+	distinctOn []string
+	ctes       []cteClause
+	fromSub    *SQLBuilder
+	fromAlias  string
+	insertSub  *SQLBuilder
+	onConflict *conflictClause
 	setClauses []setClause
+	joins      []joinClause
 	whereConds []whereCondition
-	orderByCol string
+	groupBy    []string
+	orderBy    []string
 	limitVal   int
 	offsetVal  int
+	strict     bool
+	dialect    Dialect
+	softDelete string // Soft-delete column, empty when disabled
+	unscoped   bool
+	err        error // Error of the last Build
+	argErr     error // First error encoding a helper argument, e.g. JSON
+	truncate   truncateOptions
+	comments   []string // Comments appended to the query
+	lock       lockClause
+	unions     []unionClause
+}
+
+type truncateOptions struct {
+	tables          []string
+	restartIdentity bool
+	cascade         bool
+}
+
+type cteClause struct {
+	name      string
+	sub       *SQLBuilder
+	recursive bool
 }
 
 type setClause struct {
@@ -70,24 +118,98 @@ type setClause struct {
 	args   []any
 }
 
+type conflictClause struct {
+	target     string
+	doNothing  bool
+	setClauses []setClause
+}
+
+type joinClause struct {
+	kind  string // JOIN, INNER JOIN, LEFT JOIN, RIGHT JOIN
+	table string
+	on    string
+	args  []any
+}
+
 type whereCondition struct {
 	condition string
 	args      []any
 }
 
-// NewSQLBuilder creates a new SQLBuilder instance.
-func NewSQLBuilder() *SQLBuilder {
-	return &SQLBuilder{
-		selectCols: make([]string, 0),
-		insertCols: make([]string, 0),
-		values:     make([]any, 0),
-		setClauses: make([]setClause, 0),
-		whereConds: make([]whereCondition, 0),
-		limitVal:   -1,
-		offsetVal:  -1,
+// Option configures a SQLBuilder created by NewSQLBuilder.
+type Option func(*SQLBuilder)
+
+// WithDialect selects the SQL dialect of the builder. The default is Postgres.
+//
+// Example:
+//
+//	builder.NewSQLBuilder(builder.WithDialect(builder.MySQL))
+func WithDialect(d Dialect) Option {
+	return func(b *SQLBuilder) {
+		b.dialect = d
+	}
+}
+
+// WithSoftDelete enables soft deletes on the given timestamp column.
+// SELECT, UPDATE and DELETE queries get an implicit "column IS NULL"
+// condition and Delete is rendered as "UPDATE ... SET column = now()".
+// Use Unscoped to opt a single query out.
+//
+// Example:
+//
+//	builder.NewSQLBuilder(builder.WithSoftDelete("deleted_at"))
+func WithSoftDelete(column string) Option {
+	return func(b *SQLBuilder) {
+		b.softDelete = column
 	}
 }
 
+// NewSQLBuilder creates a new SQLBuilder instance. Builders are taken from
+// an internal pool; call Release once the query is built to return it.
+func NewSQLBuilder(opts ...Option) *SQLBuilder {
+	b := builderPool.Get().(*SQLBuilder)
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// With adds a common table expression (WITH clause) to the query.
+// Multiple calls add further CTEs in order. The name may include a column
+// list, e.g. "totals(product_id, qty)". Placeholders of the CTE bodies are
+// numbered before the placeholders of the main query.
+//
+// Example:
+//
+//	builder.With("recent", sub).Select("*").From("recent")
+func (b *SQLBuilder) With(name string, sub *SQLBuilder) *SQLBuilder {
+	b.ctes = append(b.ctes, cteClause{
+		name: name,
+		sub:  sub,
+	})
+	return b
+}
+
+// WithRecursive adds a recursive common table expression, rendering the
+// WITH clause as WITH RECURSIVE. The body is usually a non-recursive part
+// combined with UnionAll with a part referencing the CTE itself.
+//
+// Example:
+//
+//	tree := builder.NewSQLBuilder().
+//		Select("id").From("categories").Where("id = ?", root).
+//		UnionAll(builder.NewSQLBuilder().
+//			Select("c.id").From("categories c").Join("tree t", "c.parent_id = t.id"))
+//	builder.WithRecursive("tree", tree).Select("id").From("tree")
+func (b *SQLBuilder) WithRecursive(name string, sub *SQLBuilder) *SQLBuilder {
+	b.ctes = append(b.ctes, cteClause{
+		name:      name,
+		sub:       sub,
+		recursive: true,
+	})
+	return b
+}
+
 // Select specifies the columns to select in a SELECT query.
 // Multiple columns can be provided as separate arguments.
 //
@@ -100,6 +222,77 @@ func (b *SQLBuilder) Select(columns ...string) *SQLBuilder {
 	return b
 }
 
+// SelectCount adds a COUNT(column) expression to a SELECT query.
+//
+// Example:
+//
+//	builder.SelectCount("*").From("products")
+func (b *SQLBuilder) SelectCount(column string) *SQLBuilder {
+	return b.selectAggregate("COUNT", column)
+}
+
+// SelectSum adds a SUM(column) expression to a SELECT query.
+//
+// Example:
+//
+//	builder.SelectSum("quantity").From("products")
+func (b *SQLBuilder) SelectSum(column string) *SQLBuilder {
+	return b.selectAggregate("SUM", column)
+}
+
+// SelectMin adds a MIN(column) expression to a SELECT query.
+//
+// Example:
+//
+//	builder.SelectMin("price").From("products")
+func (b *SQLBuilder) SelectMin(column string) *SQLBuilder {
+	return b.selectAggregate("MIN", column)
+}
+
+// SelectMax adds a MAX(column) expression to a SELECT query.
+//
+// Example:
+//
+//	builder.SelectMax("price").From("products")
+func (b *SQLBuilder) SelectMax(column string) *SQLBuilder {
+	return b.selectAggregate("MAX", column)
+}
+
+// SelectAvg adds an AVG(column) expression to a SELECT query.
+//
+// Example:
+//
+//	builder.SelectAvg("price").From("products")
+func (b *SQLBuilder) SelectAvg(column string) *SQLBuilder {
+	return b.selectAggregate("AVG", column)
+}
+
+func (b *SQLBuilder) selectAggregate(fn, column string) *SQLBuilder {
+	return b.Select(fn + "(" + column + ")")
+}
+
+// Distinct turns a SELECT query into SELECT DISTINCT.
+//
+// Example:
+//
+//	builder.Select("category").Distinct()
+func (b *SQLBuilder) Distinct() *SQLBuilder {
+	b.distinct = true
+	return b
+}
+
+// DistinctOn adds the PostgreSQL-specific DISTINCT ON (...) clause to a
+// SELECT query. The leftmost ORDER BY expressions should match the columns.
+//
+// Example:
+//
+//	builder.Select("product_id", "price").DistinctOn("product_id").
+//		OrderBy("product_id, changed_at DESC")
+func (b *SQLBuilder) DistinctOn(columns ...string) *SQLBuilder {
+	b.distinctOn = append(b.distinctOn, columns...)
+	return b
+}
+
 // From specifies the table name for the query.
 //
 // Example:
@@ -110,6 +303,19 @@ func (b *SQLBuilder) From(table string) *SQLBuilder {
 	return b
 }
 
+// FromSubquery uses another builder as the source of a SELECT query.
+// The subquery is wrapped in parentheses and given the alias; its
+// placeholders are renumbered to follow the outer query's order.
+//
+// Example:
+//
+//	builder.Select("t.tag").FromSubquery(sub, "t")
+func (b *SQLBuilder) FromSubquery(sub *SQLBuilder, alias string) *SQLBuilder {
+	b.fromSub = sub
+	b.fromAlias = alias
+	return b
+}
+
 // Insert specifies the table name for an INSERT query.
 //
 // Example:
@@ -131,14 +337,44 @@ func (b *SQLBuilder) Columns(columns ...string) *SQLBuilder {
 	return b
 }
 
-// Values specifies the values for an INSERT query.
+// Values adds a row of values to an INSERT query.
 // The number of values should match the number of columns.
+// Each call adds another row, producing a multi-row INSERT.
 //
 // Example:
 //
-//	builder.Values("John Doe", "john@example.com", 30)
+//	builder.Values("John Doe", "john@example.com", 30).
+//		Values("Jane Doe", "jane@example.com", 31)
 func (b *SQLBuilder) Values(values ...any) *SQLBuilder {
-	b.values = append(b.values, values...)
+	b.values = append(b.values, values)
+	return b
+}
+
+// ValuesRows adds several rows of values to an INSERT query at once.
+// It is equivalent to calling Values for every row.
+//
+// Example:
+//
+//	builder.ValuesRows([][]any{
+//		{"Laptop", 999.99},
+//		{"Mouse", 19.99},
+//	})
+func (b *SQLBuilder) ValuesRows(rows [][]any) *SQLBuilder {
+	b.values = append(b.values, rows...)
+	return b
+}
+
+// InsertSelect inserts the rows selected by sub instead of VALUES; its
+// placeholders are renumbered to follow the outer query's order. Values
+// are ignored when a source query is set.
+//
+// Example:
+//
+//	sub := builder.NewSQLBuilder().Select("id", "quantity").From("products")
+//	builder.Insert("stock_snapshots").Columns("product_id", "quantity").InsertSelect(sub)
+//	// Result: INSERT INTO stock_snapshots (product_id, quantity) SELECT id, quantity FROM products
+func (b *SQLBuilder) InsertSelect(sub *SQLBuilder) *SQLBuilder {
+	b.insertSub = sub
 	return b
 }
 
@@ -155,18 +391,86 @@ func (b *SQLBuilder) Update(table string) *SQLBuilder {
 
 // Set adds a SET clause for an UPDATE query.
 // Multiple Set calls can be chained to set multiple columns.
+// A "col = ?" clause with a single nil argument (including a nil pointer)
+// is rendered as "col = NULL".
 //
 // Example:
 //
 //	builder.Set("name = ?", "Jane Doe").Set("age = ?", 31)
 func (b *SQLBuilder) Set(clause string, args ...any) *SQLBuilder {
+	if column, ok := strings.CutSuffix(strings.TrimSpace(clause), "= ?"); ok &&
+		len(args) == 1 && isNil(args[0]) && !strings.Contains(column, "?") {
+		return b.SetNull(strings.TrimSpace(column))
+	}
+	b.setClauses = append(b.setClauses, setClause{
+		clause: clause,
+		args:   args,
+	})
+	return b
+}
+
+// SetNull adds a "column = NULL" SET clause for an UPDATE query.
+//
+// Example:
+//
+//	builder.Update("products").SetNull("deleted_at")
+func (b *SQLBuilder) SetNull(column string) *SQLBuilder {
 	b.setClauses = append(b.setClauses, setClause{
+		clause: column + " = NULL",
+	})
+	return b
+}
+
+// OnConflict adds an ON CONFLICT clause to an INSERT query.
+// The target is the conflict target without parentheses, e.g. a column
+// list. It must be followed by DoNothing or DoUpdateSet.
+//
+// Example:
+//
+//	builder.Insert("products").OnConflict("sku").DoNothing()
+func (b *SQLBuilder) OnConflict(target string) *SQLBuilder {
+	b.onConflict = &conflictClause{target: target}
+	return b
+}
+
+// DoNothing sets the ON CONFLICT action to DO NOTHING.
+//
+// Example:
+//
+//	builder.OnConflict("sku").DoNothing()
+func (b *SQLBuilder) DoNothing() *SQLBuilder {
+	if b.onConflict == nil {
+		b.onConflict = &conflictClause{}
+	}
+	b.onConflict.doNothing = true
+	b.onConflict.setClauses = nil
+	return b
+}
+
+// DoUpdateSet adds a SET clause to the ON CONFLICT DO UPDATE action.
+// Multiple calls are joined with commas. The EXCLUDED pseudo-table can be
+// used to refer to the row proposed for insertion.
+//
+// Example:
+//
+//	builder.OnConflict("sku").DoUpdateSet("quantity = EXCLUDED.quantity")
+func (b *SQLBuilder) DoUpdateSet(clause string, args ...any) *SQLBuilder {
+	if b.onConflict == nil {
+		b.onConflict = &conflictClause{}
+	}
+	b.onConflict.doNothing = false
+	b.onConflict.setClauses = append(b.onConflict.setClauses, setClause{
 		clause: clause,
 		args:   args,
 	})
 	return b
 }
 
+// Returning adds a RETURNING clause to INSERT, UPDATE and DELETE queries.
+//
+// Example:
+//
+//	builder.Returning("id", "created_at")
 func (b *SQLBuilder) Returning(columns ...string) *SQLBuilder {
 	b.returning = append(b.returning, columns...)
 	return b
@@ -182,12 +486,102 @@ func (b *SQLBuilder) Delete() *SQLBuilder {
 	return b
 }
 
+// Join adds a JOIN clause with an ON condition to a SELECT query.
+// The ON condition may contain ? placeholders; they are numbered
+// before the placeholders of the WHERE clause.
+//
+// Example:
+//
+//	builder.From("products p").Join("categories c", "c.id = p.category_id")
+func (b *SQLBuilder) Join(table, on string, args ...any) *SQLBuilder {
+	return b.addJoin("JOIN", table, on, args)
+}
+
+// InnerJoin adds an INNER JOIN clause with an ON condition.
+//
+// Example:
+//
+//	builder.InnerJoin("categories c", "c.id = p.category_id")
+func (b *SQLBuilder) InnerJoin(table, on string, args ...any) *SQLBuilder {
+	return b.addJoin("INNER JOIN", table, on, args)
+}
+
+// LeftJoin adds a LEFT JOIN clause with an ON condition.
+//
+// Example:
+//
+//	builder.LeftJoin("categories c", "c.id = p.category_id AND c.active = ?", true)
+func (b *SQLBuilder) LeftJoin(table, on string, args ...any) *SQLBuilder {
+	return b.addJoin("LEFT JOIN", table, on, args)
+}
+
+// RightJoin adds a RIGHT JOIN clause with an ON condition.
+//
+// Example:
+//
+//	builder.RightJoin("categories c", "c.id = p.category_id")
+func (b *SQLBuilder) RightJoin(table, on string, args ...any) *SQLBuilder {
+	return b.addJoin("RIGHT JOIN", table, on, args)
+}
+
+func (b *SQLBuilder) addJoin(kind, table, on string, args []any) *SQLBuilder {
+	b.joins = append(b.joins, joinClause{
+		kind:  kind,
+		table: table,
+		on:    on,
+		args:  args,
+	})
+	return b
+}
+
+// Unscoped disables the soft-delete behavior configured with
+// WithSoftDelete for this query: deleted rows are visible and Delete
+// removes rows physically.
+//
+// Example:
+//
+//	builder.Unscoped().Update("products").SetNull("deleted_at")
+func (b *SQLBuilder) Unscoped() *SQLBuilder {
+	b.unscoped = true
+	return b
+}
+
+// Truncate starts a TRUNCATE query for one or more tables.
+//
+// Example:
+//
+//	builder.Truncate("products", "categories").RestartIdentity().Cascade()
+func (b *SQLBuilder) Truncate(tables ...string) *SQLBuilder {
+	b.queryType = "TRUNCATE"
+	b.truncate.tables = append(b.truncate.tables, tables...)
+	return b
+}
+
+// RestartIdentity adds RESTART IDENTITY to a TRUNCATE query, resetting
+// sequences owned by the truncated tables.
+func (b *SQLBuilder) RestartIdentity() *SQLBuilder {
+	b.truncate.restartIdentity = true
+	return b
+}
+
+// Cascade adds CASCADE to a TRUNCATE query, also truncating tables that
+// reference the truncated ones.
+func (b *SQLBuilder) Cascade() *SQLBuilder {
+	b.truncate.cascade = true
+	return b
+}
+
 // Where adds a WHERE condition to the query.
 // Multiple Where calls are combined with AND.
+// An argument of type *SQLBuilder is embedded as a subquery in place of
+// its ? placeholder, with its own placeholders renumbered.
+// Passing a single map[string]any binds :name parameters by name.
 //
 // Example:
 //
 //	builder.Where("age > ?", 18).Where("status = ?", "active")
+//	builder.Where("category_id IN (?)", sub)
+//	builder.Where("price > :min AND price < :max", map[string]any{"min": 1, "max": 10})
 func (b *SQLBuilder) Where(condition string, args ...any) *SQLBuilder {
 	b.whereConds = append(b.whereConds, whereCondition{
 		condition: condition,
@@ -196,13 +590,53 @@ func (b *SQLBuilder) Where(condition string, args ...any) *SQLBuilder {
 	return b
 }
 
-// OrderBy specifies the ORDER BY clause for a SELECT query.
+// Direction is the sort direction of an ORDER BY item.
+type Direction string
+
+const (
+	Asc  Direction = "ASC"
+	Desc Direction = "DESC"
+)
+
+// ParseDirection converts a user-supplied direction ("asc", "DESC", ...)
+// into a Direction. An empty string yields Asc; anything else other than
+// ASC or DESC is rejected, so the result is safe to interpolate.
+func ParseDirection(s string) (Direction, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "", "ASC":
+		return Asc, nil
+	case "DESC":
+		return Desc, nil
+	default:
+		return "", fmt.Errorf("invalid sort direction: %q", s)
+	}
+}
+
+// GroupBy adds columns to the GROUP BY clause of a SELECT query. Multiple
+// calls are joined with commas in call order.
+//
+// Example:
+//
+//	builder.Select("category_id").SelectCount("*").From("products").GroupBy("category_id")
+func (b *SQLBuilder) GroupBy(columns ...string) *SQLBuilder {
+	b.groupBy = append(b.groupBy, columns...)
+	return b
+}
+
+// OrderBy adds an item to the ORDER BY clause of a SELECT query.
+// Multiple calls are joined with commas in call order. When a direction is
+// given it is appended to the column; without one the column string is
+// used as is, so it may already contain a direction.
 //
 // Example:
 //
+//	builder.OrderBy("price", builder.Desc).OrderBy("created_at", builder.Asc)
 //	builder.OrderBy("created_at DESC")
-func (b *SQLBuilder) OrderBy(column string) *SQLBuilder {
-	b.orderByCol = column
+func (b *SQLBuilder) OrderBy(column string, dir ...Direction) *SQLBuilder {
+	if len(dir) > 0 && dir[0] != "" {
+		column += " " + string(dir[0])
+	}
+	b.orderBy = append(b.orderBy, column)
 	return b
 }
 
@@ -228,48 +662,224 @@ func (b *SQLBuilder) Offset(offset int) *SQLBuilder {
 
 // Build constructs and returns the final SQL query and its arguments.
 // Returns the query string and a slice of arguments for parameterized queries.
+// In strict mode an empty query is returned if validation fails.
+// An empty query is also returned when a clause's ? placeholders do not
+// match its arguments or an INSERT row does not match the column list;
+// Err reports the reason.
 //
 // Example:
 //
 //	query, args := builder.Build()
+//	if err := builder.Err(); err != nil {
+//		return err
+//	}
 //	// Use with database/sql: db.Query(query, args...)
 func (b *SQLBuilder) Build() (string, []any) {
+	b.err = nil
+	if b.strict {
+		if err := b.Validate(); err != nil {
+			b.err = err
+			return "", nil
+		}
+	}
+	ph := &placeholders{dialect: b.dialect}
+	query, args := b.build(ph)
+	if ph.err != nil {
+		b.err = ph.err
+		return "", nil
+	}
+	if query != "" && len(b.comments) > 0 {
+		query += " " + strings.Join(b.comments, " ")
+	}
+	return query, args
+}
+
+// Err returns the error that made the last Build return an empty query,
+// or nil if it succeeded.
+func (b *SQLBuilder) Err() error {
+	return b.err
+}
+
+// StatementName returns a stable name for the shape of the query: queries
+// that render the same SQL get the same name whatever their arguments are.
+// Comments are not part of the shape. It returns "" when Build would fail.
+// The name can be used to prepare the query once per connection with pgx.
+//
+// Example:
+//
+//	name := b.StatementName() // e.g. stmt_1f0c6a9be2d4c7a8
+//	query, args := b.Build()
+//	if _, err := conn.Prepare(ctx, name, query); err != nil {
+//		return err
+//	}
+//	rows, err := conn.Query(ctx, name, args...)
+func (b *SQLBuilder) StatementName() string {
+	if b.strict && b.Validate() != nil {
+		return ""
+	}
+	ph := &placeholders{dialect: b.dialect}
+	query, _ := b.build(ph)
+	if query == "" || ph.err != nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(query))
+	return "stmt_" + hex.EncodeToString(sum[:8])
+}
+
+// build constructs the query numbering placeholders with ph.
+// It is used directly when the builder is embedded into another query,
+// in which case the outer query's dialect and numbering apply.
+func (b *SQLBuilder) build(ph *placeholders) (string, []any) {
+	if b.argErr != nil {
+		ph.fail(b.argErr)
+	}
+
+	var buildQuery func(*placeholders) (string, []any)
 	switch b.queryType {
 	case "SELECT":
-		return b.buildSelect()
+		buildQuery = b.buildSelect
 	case "INSERT":
-		return b.buildInsert()
+		buildQuery = b.buildInsert
 	case "UPDATE":
-		return b.buildUpdate()
+		buildQuery = b.buildUpdate
 	case "DELETE":
-		return b.buildDelete()
+		buildQuery = b.buildDelete
+	case "TRUNCATE":
+		buildQuery = b.buildTruncate
 	default:
 		return "", nil
 	}
+
+	if len(b.ctes) == 0 {
+		return buildQuery(ph)
+	}
+
+	with, args := b.buildWith(ph)
+	query, queryArgs := buildQuery(ph)
+	return with + query, append(args, queryArgs...)
+}
+
+// buildWith constructs the WITH clause prefix including a trailing space.
+func (b *SQLBuilder) buildWith(ph *placeholders) (string, []any) {
+	var query strings.Builder
+	args := make([]any, 0)
+
+	query.WriteString("WITH ")
+	for _, cte := range b.ctes {
+		if cte.recursive {
+			query.WriteString("RECURSIVE ")
+			break
+		}
+	}
+	for i, cte := range b.ctes {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		subQuery, subArgs := cte.sub.build(ph)
+		query.WriteString(cte.name)
+		query.WriteString(" AS (")
+		query.WriteString(subQuery)
+		query.WriteString(")")
+		args = append(args, subArgs...)
+	}
+	query.WriteString(" ")
+
+	return query.String(), args
 }
 
-// replacePlaceholders replaces ? placeholders with PostgreSQL-style $1, $2, etc.
-// The placeholderNum is passed by reference and incremented for each placeholder found.
-func replacePlaceholders(clause string, placeholderNum *int) string {
-	result := ""
+// expandClause replaces ? placeholders with the dialect's placeholders ($1, $2, ... for PostgreSQL).
+// The counter ph is shared by the whole query and advanced for each placeholder found.
+// A *SQLBuilder argument is built in place of its placeholder and its
+// arguments are spliced into the returned argument list. A single
+// map[string]any argument binds :name parameters instead of ? ones.
+func expandClause(clause string, args []any, ph *placeholders) (string, []any) {
+	if len(args) == 1 {
+		if params, ok := args[0].(map[string]any); ok {
+			clause, args = bindNamed(clause, params)
+		}
+	}
+
+	if n := strings.Count(clause, "?"); n != len(args) {
+		ph.fail(fmt.Errorf("%w: %q has %d placeholders but %d args", ErrPlaceholderMismatch, clause, n, len(args)))
+	}
+
+	var result strings.Builder
+	out := make([]any, 0, len(args))
+	argIdx := 0
 	for i := 0; i < len(clause); i++ {
-		if clause[i] == '?' {
-			result += fmt.Sprintf("$%d", *placeholderNum)
-			*placeholderNum++
-		} else {
-			result += string(clause[i])
+		if clause[i] != '?' {
+			result.WriteByte(clause[i])
+			continue
+		}
+		if argIdx < len(args) {
+			if sub, ok := args[argIdx].(*SQLBuilder); ok {
+				subQuery, subArgs := sub.build(ph)
+				result.WriteString(subQuery)
+				out = append(out, subArgs...)
+				argIdx++
+				continue
+			}
+			out = append(out, args[argIdx])
+			argIdx++
 		}
+		result.WriteString(ph.next())
 	}
-	return result
+	if argIdx < len(args) {
+		out = append(out, args[argIdx:]...)
+	}
+	return result.String(), out
+}
+
+// conditions returns the WHERE conditions of the query including the
+// implicit soft-delete filter.
+func (b *SQLBuilder) conditions() []whereCondition {
+	if b.softDelete == "" || b.unscoped || b.queryType == "INSERT" || b.fromSub != nil {
+		return b.whereConds
+	}
+	// Qualify the column with the table alias (or name) when joins could
+	// make it ambiguous.
+	column := b.softDelete
+	if fields := strings.Fields(b.tableName); len(fields) > 0 && len(b.joins) > 0 {
+		column = fields[len(fields)-1] + "." + column
+	}
+	conds := make([]whereCondition, 0, len(b.whereConds)+1)
+	conds = append(conds, b.whereConds...)
+	return append(conds, whereCondition{condition: column + " IS NULL"})
+}
+
+// writeWhere writes the WHERE clause, if any, and returns its arguments.
+func (b *SQLBuilder) writeWhere(query *strings.Builder, ph *placeholders) []any {
+	conds := b.conditions()
+	args := make([]any, 0)
+	if len(conds) == 0 {
+		return args
+	}
+
+	query.WriteString(" WHERE ")
+	conditions := make([]string, len(conds))
+	for i, cond := range conds {
+		var condArgs []any
+		conditions[i], condArgs = expandClause(cond.condition, cond.args, ph)
+		args = append(args, condArgs...)
+	}
+	query.WriteString(strings.Join(conditions, " AND "))
+	return args
 }
 
 // buildSelect constructs a SELECT query.
-func (b *SQLBuilder) buildSelect() (string, []any) {
+func (b *SQLBuilder) buildSelect(ph *placeholders) (string, []any) {
 	var query strings.Builder
 	args := make([]any, 0)
 
 	// SELECT clause
 	query.WriteString("SELECT ")
+	if len(b.distinctOn) > 0 {
+		query.WriteString("DISTINCT ON (")
+		query.WriteString(strings.Join(b.distinctOn, ", "))
+		query.WriteString(") ")
+	} else if b.distinct {
+		query.WriteString("DISTINCT ")
+	}
 	if len(b.selectCols) == 0 {
 		query.WriteString("*")
 	} else {
@@ -277,44 +887,71 @@ func (b *SQLBuilder) buildSelect() (string, []any) {
 	}
 
 	// FROM clause
-	if b.tableName != "" {
+	if b.fromSub != nil {
+		subQuery, subArgs := b.fromSub.build(ph)
+		query.WriteString(" FROM (")
+		query.WriteString(subQuery)
+		query.WriteString(")")
+		if b.fromAlias != "" {
+			query.WriteString(" AS ")
+			query.WriteString(b.fromAlias)
+		}
+		args = append(args, subArgs...)
+	} else if b.tableName != "" {
 		query.WriteString(" FROM ")
 		query.WriteString(b.tableName)
 	}
 
-	// WHERE clause
-	if len(b.whereConds) > 0 {
-		query.WriteString(" WHERE ")
-		conditions := make([]string, len(b.whereConds))
-		placeholderNum := 1
-		for i, cond := range b.whereConds {
-			conditions[i] = replacePlaceholders(cond.condition, &placeholderNum)
-			args = append(args, cond.args...)
+	// JOIN clauses
+	for _, join := range b.joins {
+		query.WriteString(" ")
+		query.WriteString(join.kind)
+		query.WriteString(" ")
+		query.WriteString(join.table)
+		on, onArgs := expandClause(join.on, join.args, ph)
+		if on != "" {
+			query.WriteString(" ON ")
+			query.WriteString(on)
 		}
-		query.WriteString(strings.Join(conditions, " AND "))
+		args = append(args, onArgs...)
 	}
 
-	// ORDER BY clause
-	if b.orderByCol != "" {
-		query.WriteString(" ORDER BY ")
-		query.WriteString(b.orderByCol)
+	// WHERE clause
+	args = append(args, b.writeWhere(&query, ph)...)
+
+	// GROUP BY clause
+	if len(b.groupBy) > 0 {
+		query.WriteString(" GROUP BY ")
+		query.WriteString(strings.Join(b.groupBy, ", "))
 	}
 
-	// LIMIT clause
-	if b.limitVal >= 0 {
-		query.WriteString(fmt.Sprintf(" LIMIT %d", b.limitVal))
+	// UNION clauses
+	for _, union := range b.unions {
+		subQuery, subArgs := union.sub.build(ph)
+		query.WriteString(" ")
+		query.WriteString(union.kind)
+		query.WriteString(" ")
+		query.WriteString(subQuery)
+		args = append(args, subArgs...)
 	}
 
-	// OFFSET clause
-	if b.offsetVal >= 0 {
-		query.WriteString(fmt.Sprintf(" OFFSET %d", b.offsetVal))
+	// ORDER BY clause
+	if len(b.orderBy) > 0 {
+		query.WriteString(" ORDER BY ")
+		query.WriteString(strings.Join(b.orderBy, ", "))
 	}
 
+	// LIMIT / OFFSET clause
+	query.WriteString(ph.dialect.LimitOffset(b.limitVal, b.offsetVal))
+
+	// Locking clause
+	query.WriteString(b.lock.String())
+
 	return query.String(), args
 }
 
 // buildInsert constructs an INSERT query.
-func (b *SQLBuilder) buildInsert() (string, []any) {
+func (b *SQLBuilder) buildInsert(ph *placeholders) (string, []any) {
 	var query strings.Builder
 
 	query.WriteString("INSERT INTO ")
@@ -327,14 +964,57 @@ func (b *SQLBuilder) buildInsert() (string, []any) {
 		query.WriteString(")")
 	}
 
-	// Values
-	query.WriteString(" VALUES (")
-	placeholders := make([]string, len(b.values))
-	for i := range b.values {
-		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	// Source query or values
+	args := make([]any, 0)
+	if b.insertSub != nil {
+		subQuery, subArgs := b.insertSub.build(ph)
+		query.WriteString(" ")
+		query.WriteString(subQuery)
+		args = append(args, subArgs...)
+	} else {
+		query.WriteString(" VALUES ")
+		if len(b.values) == 0 {
+			query.WriteString("()")
+		}
+		for i, row := range b.values {
+			if len(b.insertCols) > 0 && len(row) != len(b.insertCols) {
+				ph.fail(fmt.Errorf("%w: row %d has %d values for %d columns", ErrPlaceholderMismatch, i+1, len(row), len(b.insertCols)))
+			}
+			if i > 0 {
+				query.WriteString(", ")
+			}
+			placeholders := make([]string, len(row))
+			for j := range row {
+				placeholders[j] = ph.next()
+			}
+			query.WriteString("(")
+			query.WriteString(strings.Join(placeholders, ", "))
+			query.WriteString(")")
+			args = append(args, row...)
+		}
+	}
+
+	// ON CONFLICT clause
+	if b.onConflict != nil {
+		query.WriteString(" ON CONFLICT")
+		if b.onConflict.target != "" {
+			query.WriteString(" (")
+			query.WriteString(b.onConflict.target)
+			query.WriteString(")")
+		}
+		if b.onConflict.doNothing || len(b.onConflict.setClauses) == 0 {
+			query.WriteString(" DO NOTHING")
+		} else {
+			query.WriteString(" DO UPDATE SET ")
+			clauses := make([]string, len(b.onConflict.setClauses))
+			for i, set := range b.onConflict.setClauses {
+				var setArgs []any
+				clauses[i], setArgs = expandClause(set.clause, set.args, ph)
+				args = append(args, setArgs...)
+			}
+			query.WriteString(strings.Join(clauses, ", "))
+		}
 	}
-	query.WriteString(strings.Join(placeholders, ", "))
-	query.WriteString(")")
 
 	// RETURNING clause
 	if len(b.returning) > 0 {
@@ -342,11 +1022,11 @@ func (b *SQLBuilder) buildInsert() (string, []any) {
 		query.WriteString(strings.Join(b.returning, ", "))
 	}
 
-	return query.String(), b.values
+	return query.String(), args
 }
 
 // buildUpdate constructs an UPDATE query.
-func (b *SQLBuilder) buildUpdate() (string, []any) {
+func (b *SQLBuilder) buildUpdate(ph *placeholders) (string, []any) {
 	var query strings.Builder
 	args := make([]any, 0)
 
@@ -354,27 +1034,19 @@ func (b *SQLBuilder) buildUpdate() (string, []any) {
 	query.WriteString(b.tableName)
 
 	// SET clause
-	placeholderNum := 1
 	if len(b.setClauses) > 0 {
 		query.WriteString(" SET ")
 		clauses := make([]string, len(b.setClauses))
 		for i, set := range b.setClauses {
-			clauses[i] = replacePlaceholders(set.clause, &placeholderNum)
-			args = append(args, set.args...)
+			var setArgs []any
+			clauses[i], setArgs = expandClause(set.clause, set.args, ph)
+			args = append(args, setArgs...)
 		}
 		query.WriteString(strings.Join(clauses, ", "))
 	}
 
 	// WHERE clause
-	if len(b.whereConds) > 0 {
-		query.WriteString(" WHERE ")
-		conditions := make([]string, len(b.whereConds))
-		for i, cond := range b.whereConds {
-			conditions[i] = replacePlaceholders(cond.condition, &placeholderNum)
-			args = append(args, cond.args...)
-		}
-		query.WriteString(strings.Join(conditions, " AND "))
-	}
+	args = append(args, b.writeWhere(&query, ph)...)
 
 	// RETURNING clause
 	if len(b.returning) > 0 {
@@ -386,24 +1058,24 @@ func (b *SQLBuilder) buildUpdate() (string, []any) {
 }
 
 // buildDelete constructs a DELETE query.
-func (b *SQLBuilder) buildDelete() (string, []any) {
+func (b *SQLBuilder) buildDelete(ph *placeholders) (string, []any) {
 	var query strings.Builder
 	args := make([]any, 0)
 
-	query.WriteString("DELETE FROM ")
-	query.WriteString(b.tableName)
+	if b.softDelete != "" && !b.unscoped {
+		// Soft delete: mark the rows instead of removing them.
+		query.WriteString("UPDATE ")
+		query.WriteString(b.tableName)
+		query.WriteString(" SET ")
+		query.WriteString(b.softDelete)
+		query.WriteString(" = now()")
+	} else {
+		query.WriteString("DELETE FROM ")
+		query.WriteString(b.tableName)
+	}
 
 	// WHERE clause
-	placeholderNum := 1
-	if len(b.whereConds) > 0 {
-		query.WriteString(" WHERE ")
-		conditions := make([]string, len(b.whereConds))
-		for i, cond := range b.whereConds {
-			conditions[i] = replacePlaceholders(cond.condition, &placeholderNum)
-			args = append(args, cond.args...)
-		}
-		query.WriteString(strings.Join(conditions, " AND "))
-	}
+	args = append(args, b.writeWhere(&query, ph)...)
 
 	// RETURNING clause
 	if len(b.returning) > 0 {
@@ -412,4 +1084,21 @@ func (b *SQLBuilder) buildDelete() (string, []any) {
 	}
 
 	return query.String(), args
-}
\ No newline at end of file
+}
+
+// buildTruncate constructs a TRUNCATE query.
+func (b *SQLBuilder) buildTruncate(*placeholders) (string, []any) {
+	var query strings.Builder
+
+	query.WriteString("TRUNCATE ")
+	query.WriteString(strings.Join(b.truncate.tables, ", "))
+
+	if b.truncate.restartIdentity {
+		query.WriteString(" RESTART IDENTITY")
+	}
+	if b.truncate.cascade {
+		query.WriteString(" CASCADE")
+	}
+
+	return query.String(), make([]any, 0)
+}
@@ -1,6 +1,8 @@
 package builder
 
 import (
+	"errors"
+	"strings"
 	"testing"
 	"time"
 )
@@ -456,3 +458,591 @@ func TestComplexRealWorldScenarios(t *testing.T) {
 		t.Errorf("Expected 1 arg, got: %d", len(args3))
 	}
 }
+
+// TestSelectWithLeftJoin tests a SELECT query with a LEFT JOIN clause.
+func TestSelectWithLeftJoin(t *testing.T) {
+	query, args := NewSQLBuilder().
+		Select("p.id", "c.name").
+		From("products p").
+		LeftJoin("categories c", "c.id = p.category_id").
+		Build()
+
+	expected := "SELECT p.id, c.name FROM products p LEFT JOIN categories c ON c.id = p.category_id"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if len(args) != 0 {
+		t.Errorf("Expected 0 args, got: %d", len(args))
+	}
+}
+
+// TestSelectWithMultipleJoins tests that join kinds are rendered in call order.
+func TestSelectWithMultipleJoins(t *testing.T) {
+	query, _ := NewSQLBuilder().
+		Select("p.id").
+		From("products p").
+		Join("stock s", "s.product_id = p.id").
+		InnerJoin("categories c", "c.id = p.category_id").
+		RightJoin("suppliers su", "su.id = p.supplier_id").
+		Build()
+
+	expected := "SELECT p.id FROM products p JOIN stock s ON s.product_id = p.id " +
+		"INNER JOIN categories c ON c.id = p.category_id " +
+		"RIGHT JOIN suppliers su ON su.id = p.supplier_id"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+}
+
+// TestJoinPlaceholderOrder tests that ON placeholders are numbered before WHERE placeholders.
+func TestJoinPlaceholderOrder(t *testing.T) {
+	query, args := NewSQLBuilder().
+		Select("p.id", "c.name").
+		From("products p").
+		LeftJoin("categories c", "c.id = p.category_id AND c.active = ?", true).
+		InnerJoin("stock s", "s.product_id = p.id AND s.warehouse = ?", "main").
+		Where("p.price > ?", 100).
+		Build()
+
+	expected := "SELECT p.id, c.name FROM products p " +
+		"LEFT JOIN categories c ON c.id = p.category_id AND c.active = $1 " +
+		"INNER JOIN stock s ON s.product_id = p.id AND s.warehouse = $2 " +
+		"WHERE p.price > $3"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if len(args) != 3 {
+		t.Fatalf("Expected 3 args, got: %d", len(args))
+	}
+	if args[0] != true || args[1] != "main" || args[2] != 100 {
+		t.Errorf("Expected args: [true, main, 100], got: %v", args)
+	}
+}
+
+// TestInsertOnConflictDoNothing tests an INSERT query with ON CONFLICT DO NOTHING.
+func TestInsertOnConflictDoNothing(t *testing.T) {
+	query, args := NewSQLBuilder().
+		Insert("products").
+		Columns("sku", "name").
+		Values("SKU-1", "Laptop").
+		OnConflict("sku").
+		DoNothing().
+		Build()
+
+	expected := "INSERT INTO products (sku, name) VALUES ($1, $2) ON CONFLICT (sku) DO NOTHING"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if len(args) != 2 {
+		t.Errorf("Expected 2 args, got: %d", len(args))
+	}
+}
+
+// TestInsertOnConflictDoUpdate tests an upsert with placeholders in the update action.
+func TestInsertOnConflictDoUpdate(t *testing.T) {
+	query, args := NewSQLBuilder().
+		Insert("products").
+		Columns("sku", "quantity").
+		Values("SKU-1", 10).
+		OnConflict("sku").
+		DoUpdateSet("quantity = EXCLUDED.quantity").
+		DoUpdateSet("updated_by = ?", "importer").
+		Returning("id").
+		Build()
+
+	expected := "INSERT INTO products (sku, quantity) VALUES ($1, $2) " +
+		"ON CONFLICT (sku) DO UPDATE SET quantity = EXCLUDED.quantity, updated_by = $3 RETURNING id"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if len(args) != 3 {
+		t.Fatalf("Expected 3 args, got: %d", len(args))
+	}
+	if args[0] != "SKU-1" || args[1] != 10 || args[2] != "importer" {
+		t.Errorf("Expected args: [SKU-1, 10, importer], got: %v", args)
+	}
+}
+
+// TestInsertOnConflictWithoutTarget tests ON CONFLICT without a conflict target.
+func TestInsertOnConflictWithoutTarget(t *testing.T) {
+	query, _ := NewSQLBuilder().
+		Insert("products").
+		Columns("sku").
+		Values("SKU-1").
+		DoNothing().
+		Build()
+
+	expected := "INSERT INTO products (sku) VALUES ($1) ON CONFLICT DO NOTHING"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+}
+
+// TestInsertMultipleRows tests a multi-row INSERT built with repeated Values calls.
+func TestInsertMultipleRows(t *testing.T) {
+	query, args := NewSQLBuilder().
+		Insert("products").
+		Columns("name", "price").
+		Values("Laptop", 999.99).
+		Values("Mouse", 19.99).
+		Values("Keyboard", 49.99).
+		Build()
+
+	expected := "INSERT INTO products (name, price) VALUES ($1, $2), ($3, $4), ($5, $6)"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if len(args) != 6 {
+		t.Fatalf("Expected 6 args, got: %d", len(args))
+	}
+	if args[0] != "Laptop" || args[2] != "Mouse" || args[4] != "Keyboard" {
+		t.Errorf("Expected args in row order, got: %v", args)
+	}
+}
+
+// TestInsertValuesRows tests a multi-row INSERT with ON CONFLICT placeholders after the rows.
+func TestInsertValuesRows(t *testing.T) {
+	query, args := NewSQLBuilder().
+		Insert("products").
+		Columns("sku", "quantity").
+		ValuesRows([][]any{
+			{"SKU-1", 1},
+			{"SKU-2", 2},
+		}).
+		OnConflict("sku").
+		DoUpdateSet("quantity = products.quantity + ?", 5).
+		Build()
+
+	expected := "INSERT INTO products (sku, quantity) VALUES ($1, $2), ($3, $4) " +
+		"ON CONFLICT (sku) DO UPDATE SET quantity = products.quantity + $5"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if len(args) != 5 || args[4] != 5 {
+		t.Errorf("Expected 5 args ending with 5, got: %v", args)
+	}
+}
+
+// TestInsertSelect tests an INSERT from a SELECT with the placeholders of
+// both queries renumbered in order.
+func TestInsertSelect(t *testing.T) {
+	sub := NewSQLBuilder().
+		Select("id", "quantity").
+		From("products").
+		Where("quantity > ?", 0)
+
+	query, args := NewSQLBuilder().
+		Insert("stock_snapshots").
+		Columns("product_id", "quantity").
+		InsertSelect(sub).
+		OnConflict("product_id").
+		DoUpdateSet("quantity = ?", 1).
+		Build()
+
+	expected := "INSERT INTO stock_snapshots (product_id, quantity) " +
+		"SELECT id, quantity FROM products WHERE quantity > $1 " +
+		"ON CONFLICT (product_id) DO UPDATE SET quantity = $2"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if len(args) != 2 || args[0] != 0 || args[1] != 1 {
+		t.Errorf("Expected args: [0 1], got: %v", args)
+	}
+}
+
+// TestWhereSubquery tests embedding a builder as a WHERE subquery with renumbered placeholders.
+func TestWhereSubquery(t *testing.T) {
+	sub := NewSQLBuilder().
+		Select("id").
+		From("categories").
+		Where("active = ?", true)
+
+	query, args := NewSQLBuilder().
+		Select("id", "name").
+		From("products").
+		Where("price > ?", 100).
+		Where("category_id IN (?)", sub).
+		Where("quantity > ?", 0).
+		Build()
+
+	expected := "SELECT id, name FROM products WHERE price > $1 " +
+		"AND category_id IN (SELECT id FROM categories WHERE active = $2) AND quantity > $3"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if len(args) != 3 {
+		t.Fatalf("Expected 3 args, got: %d", len(args))
+	}
+	if args[0] != 100 || args[1] != true || args[2] != 0 {
+		t.Errorf("Expected args: [100, true, 0], got: %v", args)
+	}
+}
+
+// TestFromSubquery tests selecting from a subquery with an alias.
+func TestFromSubquery(t *testing.T) {
+	sub := NewSQLBuilder().
+		Select("unnest(tags) AS tag").
+		From("products").
+		Where("available = ?", true)
+
+	query, args := NewSQLBuilder().
+		Select("t.tag", "count(*) AS cnt").
+		FromSubquery(sub, "t").
+		Where("t.tag <> ?", "").
+		Build()
+
+	expected := "SELECT t.tag, count(*) AS cnt FROM (SELECT unnest(tags) AS tag FROM products WHERE available = $1) AS t WHERE t.tag <> $2"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if len(args) != 2 || args[0] != true || args[1] != "" {
+		t.Errorf("Expected args: [true, ], got: %v", args)
+	}
+}
+
+// TestUpdateWithSubquery tests that subqueries are renumbered after SET placeholders.
+func TestUpdateWithSubquery(t *testing.T) {
+	sub := NewSQLBuilder().
+		Select("product_id").
+		From("reservations").
+		Where("expires_at < ?", "2024-01-01")
+
+	query, args := NewSQLBuilder().
+		Update("products").
+		Set("reserved = ?", false).
+		Where("id IN (?)", sub).
+		Build()
+
+	expected := "UPDATE products SET reserved = $1 WHERE id IN (SELECT product_id FROM reservations WHERE expires_at < $2)"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if len(args) != 2 {
+		t.Errorf("Expected 2 args, got: %d", len(args))
+	}
+}
+
+// TestSelectWithCTE tests a SELECT query with multiple CTEs and ordered placeholders.
+func TestSelectWithCTE(t *testing.T) {
+	movements := NewSQLBuilder().
+		Select("product_id", "sum(delta) AS qty").
+		From("stock_movements").
+		Where("created_at > ?", "2024-01-01")
+	low := NewSQLBuilder().
+		Select("product_id").
+		From("totals").
+		Where("qty < ?", 10)
+
+	query, args := NewSQLBuilder().
+		With("totals", movements).
+		With("low_stock", low).
+		Select("p.id", "p.name").
+		From("products p").
+		Join("low_stock l", "l.product_id = p.id").
+		Where("p.available = ?", true).
+		Build()
+
+	expected := "WITH totals AS (SELECT product_id, sum(delta) AS qty FROM stock_movements WHERE created_at > $1), " +
+		"low_stock AS (SELECT product_id FROM totals WHERE qty < $2) " +
+		"SELECT p.id, p.name FROM products p JOIN low_stock l ON l.product_id = p.id WHERE p.available = $3"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if len(args) != 3 {
+		t.Fatalf("Expected 3 args, got: %d", len(args))
+	}
+	if args[0] != "2024-01-01" || args[1] != 10 || args[2] != true {
+		t.Errorf("Expected args: [2024-01-01, 10, true], got: %v", args)
+	}
+}
+
+// TestUpdateWithCTE tests a CTE in front of an UPDATE query.
+func TestUpdateWithCTE(t *testing.T) {
+	expired := NewSQLBuilder().
+		Select("id").
+		From("reservations").
+		Where("expires_at < ?", "2024-01-01")
+
+	query, args := NewSQLBuilder().
+		With("expired", expired).
+		Update("reservations").
+		Set("status = ?", "released").
+		Where("id IN (SELECT id FROM expired)").
+		Build()
+
+	expected := "WITH expired AS (SELECT id FROM reservations WHERE expires_at < $1) " +
+		"UPDATE reservations SET status = $2 WHERE id IN (SELECT id FROM expired)"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if len(args) != 2 {
+		t.Errorf("Expected 2 args, got: %d", len(args))
+	}
+}
+
+// TestSelectDistinct tests a SELECT DISTINCT query.
+func TestSelectDistinct(t *testing.T) {
+	query, args := NewSQLBuilder().
+		Select("category").
+		Distinct().
+		From("products").
+		Build()
+
+	expected := "SELECT DISTINCT category FROM products"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if len(args) != 0 {
+		t.Errorf("Expected 0 args, got: %d", len(args))
+	}
+}
+
+// TestSelectDistinctOn tests a DISTINCT ON query for "latest row per key".
+func TestSelectDistinctOn(t *testing.T) {
+	query, args := NewSQLBuilder().
+		Select("product_id", "price", "changed_at").
+		DistinctOn("product_id").
+		From("price_history").
+		Where("changed_at <= ?", "2024-01-01").
+		OrderBy("product_id, changed_at DESC").
+		Build()
+
+	expected := "SELECT DISTINCT ON (product_id) product_id, price, changed_at FROM price_history " +
+		"WHERE changed_at <= $1 ORDER BY product_id, changed_at DESC"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if len(args) != 1 {
+		t.Errorf("Expected 1 arg, got: %d", len(args))
+	}
+}
+
+// TestDistinctOnOverridesDistinct tests that DISTINCT ON takes precedence over DISTINCT.
+func TestDistinctOnOverridesDistinct(t *testing.T) {
+	query, _ := NewSQLBuilder().
+		Select("a", "b").
+		Distinct().
+		DistinctOn("a", "b").
+		From("t").
+		Build()
+
+	expected := "SELECT DISTINCT ON (a, b) a, b FROM t"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+}
+
+// TestSelectCount tests a COUNT(*) query used for pagination totals.
+func TestSelectCount(t *testing.T) {
+	query, args := NewSQLBuilder().
+		SelectCount("*").
+		From("products").
+		Where("available = ?", true).
+		Build()
+
+	expected := "SELECT COUNT(*) FROM products WHERE available = $1"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if len(args) != 1 {
+		t.Errorf("Expected 1 arg, got: %d", len(args))
+	}
+}
+
+// TestSelectAggregates tests combining aggregate helpers with plain columns.
+func TestSelectAggregates(t *testing.T) {
+	query, _ := NewSQLBuilder().
+		Select("category_id").
+		SelectSum("quantity").
+		SelectMin("price").
+		SelectMax("price").
+		SelectAvg("price").
+		From("products").
+		Build()
+
+	expected := "SELECT category_id, SUM(quantity), MIN(price), MAX(price), AVG(price) FROM products"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+}
+
+// TestSelectGroupBy tests GROUP BY between WHERE and ORDER BY.
+func TestSelectGroupBy(t *testing.T) {
+	query, args := NewSQLBuilder().
+		Select("category_id").
+		SelectCount("*").
+		From("products").
+		Where("quantity > ?", 0).
+		GroupBy("category_id").
+		OrderBy("category_id").
+		Build()
+
+	expected := "SELECT category_id, COUNT(*) FROM products WHERE quantity > $1 GROUP BY category_id ORDER BY category_id"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if len(args) != 1 {
+		t.Errorf("Expected 1 arg, got: %d", len(args))
+	}
+}
+
+// TestOrderByMultipleColumns tests multi-column ORDER BY with directions.
+func TestOrderByMultipleColumns(t *testing.T) {
+	query, _ := NewSQLBuilder().
+		Select("id").
+		From("products").
+		OrderBy("price", Desc).
+		OrderBy("created_at", Asc).
+		OrderBy("name").
+		Build()
+
+	expected := "SELECT id FROM products ORDER BY price DESC, created_at ASC, name"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+}
+
+// TestParseDirection tests the direction whitelist.
+func TestParseDirection(t *testing.T) {
+	cases := map[string]Direction{"": Asc, "asc": Asc, "ASC": Asc, "desc": Desc, " DESC ": Desc}
+	for in, expected := range cases {
+		got, err := ParseDirection(in)
+		if err != nil || got != expected {
+			t.Errorf("ParseDirection(%q): expected %s, got: %s (%v)", in, expected, got, err)
+		}
+	}
+
+	for _, in := range []string{"DESC; DROP TABLE products", "up", "ASC NULLS"} {
+		if _, err := ParseDirection(in); err == nil {
+			t.Errorf("ParseDirection(%q): expected error", in)
+		}
+	}
+}
+
+// TestWhereNamedParameters tests :name parameters compiled to positional placeholders.
+func TestWhereNamedParameters(t *testing.T) {
+	query, args := NewSQLBuilder().
+		Select("id").
+		From("products").
+		Where("available = ?", true).
+		Where("price > :min AND price < :max", map[string]any{"min": 1, "max": 10}).
+		Where("quantity > ?", 0).
+		Build()
+
+	expected := "SELECT id FROM products WHERE available = $1 AND price > $2 AND price < $3 AND quantity > $4"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if len(args) != 4 {
+		t.Fatalf("Expected 4 args, got: %d", len(args))
+	}
+	if args[0] != true || args[1] != 1 || args[2] != 10 || args[3] != 0 {
+		t.Errorf("Expected args: [true, 1, 10, 0], got: %v", args)
+	}
+}
+
+// TestNamedParametersEdgeCases tests repeated names, casts, literals and subqueries.
+func TestNamedParametersEdgeCases(t *testing.T) {
+	sub := NewSQLBuilder().Select("id").From("categories").Where("name = ?", "books")
+
+	query, args := NewSQLBuilder().
+		Update("products").
+		Set("note = :note || ':keep'", map[string]any{"note": "x"}).
+		Where("(name = :q OR description = :q) AND tags @> ARRAY[:tag]::text[] AND category_id IN (:sub)",
+			map[string]any{"q": "laptop", "tag": "sale", "sub": sub}).
+		Build()
+
+	expected := "UPDATE products SET note = $1 || ':keep' WHERE (name = $2 OR description = $3) " +
+		"AND tags @> ARRAY[$4]::text[] AND category_id IN (SELECT id FROM categories WHERE name = $5)"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if len(args) != 5 {
+		t.Fatalf("Expected 5 args, got: %d", len(args))
+	}
+	if args[1] != "laptop" || args[2] != "laptop" || args[3] != "sale" || args[4] != "books" {
+		t.Errorf("Expected args: [x, laptop, laptop, sale, books], got: %v", args)
+	}
+}
+
+// TestPlaceholderMismatch tests that mismatched placeholders and args are reported.
+func TestPlaceholderMismatch(t *testing.T) {
+	cases := []*SQLBuilder{
+		NewSQLBuilder().Select("id").From("products").Where("price > ? AND price < ?", 1),
+		NewSQLBuilder().Select("id").From("products").Where("price > ?", 1, 2),
+		NewSQLBuilder().Update("products").Set("name = ?, price = ?", "x"),
+		NewSQLBuilder().Insert("products").Columns("name", "price").Values("x"),
+		NewSQLBuilder().Select("id").From("products").
+			Where("id IN (?)", NewSQLBuilder().Select("id").From("t").Where("a = ?")),
+	}
+	for i, b := range cases {
+		query, args := b.Build()
+		if query != "" || args != nil {
+			t.Errorf("Case %d: expected empty build, got: %s %v", i, query, args)
+		}
+		if !errors.Is(b.Err(), ErrPlaceholderMismatch) {
+			t.Errorf("Case %d: expected ErrPlaceholderMismatch, got: %v", i, b.Err())
+		}
+	}
+}
+
+// TestErrClearedOnSuccess tests that Err is nil after a successful Build.
+func TestErrClearedOnSuccess(t *testing.T) {
+	b := NewSQLBuilder().Select("id").From("products").Where("price > ?", 1)
+	if query, _ := b.Build(); query == "" {
+		t.Fatalf("Expected a query")
+	}
+	if err := b.Err(); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+}
+
+// TestTruncate tests TRUNCATE queries with options.
+func TestTruncate(t *testing.T) {
+	query, args := NewSQLBuilder().
+		Truncate("products").
+		Build()
+
+	expected := "TRUNCATE products"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if len(args) != 0 {
+		t.Errorf("Expected 0 args, got: %d", len(args))
+	}
+
+	query, _ = NewSQLBuilder().
+		Truncate("products", "categories").
+		RestartIdentity().
+		Cascade().
+		Build()
+
+	expected = "TRUNCATE products, categories RESTART IDENTITY CASCADE"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+}
+
+// TestStatementName tests that the statement name depends only on the query shape.
+func TestStatementName(t *testing.T) {
+	get := func(id any) *SQLBuilder {
+		return NewSQLBuilder().Select("id", "name").From("products").Where("id = ?", id)
+	}
+
+	name := get("a").StatementName()
+	if !strings.HasPrefix(name, "stmt_") || len(name) != len("stmt_")+16 {
+		t.Errorf("Unexpected statement name: %s", name)
+	}
+	if other := get("b").StatementName(); other != name {
+		t.Errorf("Expected same name for different args, got: %s and %s", name, other)
+	}
+	if other := get("a").Comment("route=Get").StatementName(); other != name {
+		t.Errorf("Expected comments to be ignored, got: %s and %s", name, other)
+	}
+	if other := get("a").Limit(1).StatementName(); other == name {
+		t.Errorf("Expected different name for a different shape, got: %s", other)
+	}
+	if other := NewSQLBuilder().Select("id").From("products").Where("id = ?").StatementName(); other != "" {
+		t.Errorf("Expected empty name for an invalid query, got: %s", other)
+	}
+}
@@ -0,0 +1,55 @@
+package builder
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Comment appends a /* text */ comment to the generated query, e.g. to
+// attribute it in pg_stat_statements or APM tools. A "*/" in text is
+// broken up so it cannot close the comment early.
+//
+// Example:
+//
+//	builder.Select("id").From("products").Comment("list products")
+//	// Result: SELECT id FROM products /* list products */
+func (b *SQLBuilder) Comment(text string) *SQLBuilder {
+	text = strings.ReplaceAll(text, "*/", "* /")
+	b.comments = append(b.comments, "/* "+text+" */")
+	return b
+}
+
+// CommentTags appends an sqlcommenter-style comment built from tags. Keys
+// are sorted, and keys and values are URL-encoded with values wrapped in
+// single quotes. Empty tags add nothing.
+//
+// Example:
+//
+//	builder.Select("id").From("products").
+//		CommentTags(map[string]string{"route": "/inventory.InventoryService/List"})
+//	// Result: SELECT id FROM products /*route='%2Finventory.InventoryService%2FList'*/
+func (b *SQLBuilder) CommentTags(tags map[string]string) *SQLBuilder {
+	if len(tags) == 0 {
+		return b
+	}
+
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = commentEscape(key) + "='" + commentEscape(tags[key]) + "'"
+	}
+	b.comments = append(b.comments, "/*"+strings.Join(pairs, ",")+"*/")
+	return b
+}
+
+// commentEscape URL-encodes s the way sqlcommenter does, with spaces as
+// %20. Quotes and "*/" are encoded too, so the result is safe in a comment.
+func commentEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
@@ -0,0 +1,59 @@
+package builder
+
+import "testing"
+
+// TestComment tests free-form query comments.
+func TestComment(t *testing.T) {
+	query, _ := NewSQLBuilder().
+		Select("id").
+		From("products").
+		Where("id = ?", 1).
+		Comment("list products").
+		Comment("evil */ DROP TABLE products").
+		Build()
+
+	expected := "SELECT id FROM products WHERE id = $1 /* list products */ /* evil * / DROP TABLE products */"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+}
+
+// TestCommentTags tests sqlcommenter-style comments.
+func TestCommentTags(t *testing.T) {
+	query, _ := NewSQLBuilder().
+		Update("products").
+		Set("price = ?", 10).
+		CommentTags(map[string]string{
+			"route":       "/inventory.InventoryService/Update",
+			"traceparent": "00-abc-01",
+			"app":         "it's inventory",
+		}).
+		Build()
+
+	expected := "UPDATE products SET price = $1 " +
+		"/*app='it%27s%20inventory',route='%2Finventory.InventoryService%2FUpdate',traceparent='00-abc-01'*/"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+
+	query, _ = NewSQLBuilder().Select("id").From("products").CommentTags(nil).Build()
+	if query != "SELECT id FROM products" {
+		t.Errorf("Expected no comment for empty tags, got: %s", query)
+	}
+}
+
+// TestCommentSubquery tests that only the outer query's comments are emitted.
+func TestCommentSubquery(t *testing.T) {
+	sub := NewSQLBuilder().Select("product_id").From("reservations").Comment("inner")
+	query, _ := NewSQLBuilder().
+		Select("id").
+		From("products").
+		Where("id IN (?)", sub).
+		Comment("outer").
+		Build()
+
+	expected := "SELECT id FROM products WHERE id IN (SELECT product_id FROM reservations) /* outer */"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+}
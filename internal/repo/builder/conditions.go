@@ -0,0 +1,229 @@
+package builder
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// WhereEq adds an equality condition for every column of eq, combined with
+// AND. Columns are added in sorted order so the query is deterministic.
+// A nil value produces "col IS NULL" and a slice produces "col IN (...)";
+// an empty slice matches nothing.
+//
+// Example:
+//
+//	builder.WhereEq(map[string]any{"status": "active", "available": true})
+//	// available = $1 AND status = $2
+func (b *SQLBuilder) WhereEq(eq map[string]any) *SQLBuilder {
+	columns := make([]string, 0, len(eq))
+	for column := range eq {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	for _, column := range columns {
+		value := eq[column]
+		if isNil(value) {
+			b.WhereNull(column)
+			continue
+		}
+
+		rv := reflect.ValueOf(value)
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() != reflect.Uint8 {
+			if rv.Len() == 0 {
+				b.Where("FALSE")
+				continue
+			}
+			args := make([]any, rv.Len())
+			for i := range args {
+				args[i] = rv.Index(i).Interface()
+			}
+			b.Where(column+" IN ("+strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ")+")", args...)
+			continue
+		}
+
+		b.Where(column+" = ?", value)
+	}
+	return b
+}
+
+// WhereNull adds a "column IS NULL" condition.
+//
+// Example:
+//
+//	builder.WhereNull("deleted_at")
+func (b *SQLBuilder) WhereNull(column string) *SQLBuilder {
+	return b.Where(column + " IS NULL")
+}
+
+// WhereNotNull adds a "column IS NOT NULL" condition.
+//
+// Example:
+//
+//	builder.WhereNotNull("expires_at")
+func (b *SQLBuilder) WhereNotNull(column string) *SQLBuilder {
+	return b.Where(column + " IS NOT NULL")
+}
+
+// WhereArrayContains adds a "column @> ARRAY[...]" condition matching rows
+// whose array column contains all values. The array is cast to the type of
+// the values (see arrayCast); no values add no condition.
+//
+// Example:
+//
+//	builder.WhereArrayContains("tags", "sale", "laptop")
+//	// tags @> ARRAY[$1, $2]::text[]
+func (b *SQLBuilder) WhereArrayContains(column string, values ...any) *SQLBuilder {
+	if len(values) == 0 {
+		return b
+	}
+	return b.whereArray(column, "@>", values)
+}
+
+// WhereArrayOverlaps adds a "column && ARRAY[...]" condition matching rows
+// whose array column shares at least one value. No values match nothing.
+//
+// Example:
+//
+//	builder.WhereArrayOverlaps("tags", "sale", "laptop")
+//	// tags && ARRAY[$1, $2]::text[]
+func (b *SQLBuilder) WhereArrayOverlaps(column string, values ...any) *SQLBuilder {
+	if len(values) == 0 {
+		return b.Where("FALSE")
+	}
+	return b.whereArray(column, "&&", values)
+}
+
+// WhereArrayContainedBy adds a "column <@ ARRAY[...]" condition matching
+// rows whose array column only holds the given values.
+//
+// Example:
+//
+//	builder.WhereArrayContainedBy("sizes", 38, 39, 40)
+//	// sizes <@ ARRAY[$1, $2, $3]::int[]
+func (b *SQLBuilder) WhereArrayContainedBy(column string, values ...any) *SQLBuilder {
+	return b.whereArray(column, "<@", values)
+}
+
+func (b *SQLBuilder) whereArray(column, op string, values []any) *SQLBuilder {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+	var cast string
+	if len(values) > 0 {
+		cast = arrayCast(values[0])
+	}
+	if len(values) == 0 && cast == "" {
+		cast = "::text[]"
+	}
+	return b.Where(column+" "+op+" ARRAY["+placeholders+"]"+cast, values...)
+}
+
+// arrayCast returns the PostgreSQL array cast for the Go type of v:
+// string → text[], int64 → bigint[], other integers → int[],
+// floats → float8[], bool → boolean[]. Other types are left uncast.
+func arrayCast(v any) string {
+	switch v.(type) {
+	case string:
+		return "::text[]"
+	case int64:
+		return "::bigint[]"
+	case int, int8, int16, int32, uint8, uint16, uint32:
+		return "::int[]"
+	case float32, float64:
+		return "::float8[]"
+	case bool:
+		return "::boolean[]"
+	default:
+		return ""
+	}
+}
+
+// WhereExists adds an "EXISTS (subquery)" condition. The subquery's
+// placeholders are numbered in line with the outer query.
+//
+// Example:
+//
+//	builder.WhereExists(builder.NewSQLBuilder().
+//		Select("1").From("reservations r").Where("r.product_id = p.id"))
+func (b *SQLBuilder) WhereExists(sub *SQLBuilder) *SQLBuilder {
+	return b.Where("EXISTS (?)", sub)
+}
+
+// WhereNotExists adds a "NOT EXISTS (subquery)" condition.
+//
+// Example:
+//
+//	builder.WhereNotExists(pendingReservations)
+func (b *SQLBuilder) WhereNotExists(sub *SQLBuilder) *SQLBuilder {
+	return b.Where("NOT EXISTS (?)", sub)
+}
+
+// likeEscaper escapes the LIKE wildcards and the escape character itself.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// EscapeLike escapes %, _ and \ in s so it matches literally inside a
+// LIKE/ILIKE pattern using the default backslash escape character.
+//
+// Example:
+//
+//	builder.EscapeLike("50%_off") // 50\%\_off
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// WhereLike adds a case-sensitive "column LIKE ?" condition matching
+// values that contain input. Wildcards in input are escaped.
+//
+// Example:
+//
+//	builder.WhereLike("sku", "AB_1") // sku LIKE $1 with "%AB\_1%"
+func (b *SQLBuilder) WhereLike(column, input string) *SQLBuilder {
+	return b.Where(column+" LIKE ?", "%"+EscapeLike(input)+"%")
+}
+
+// WhereILike adds a case-insensitive "column ILIKE ?" condition matching
+// values that contain input. Wildcards in input are escaped.
+//
+// Example:
+//
+//	builder.WhereILike("name", "100%") // name ILIKE $1 with "%100\%%"
+func (b *SQLBuilder) WhereILike(column, input string) *SQLBuilder {
+	return b.Where(column+" ILIKE ?", "%"+EscapeLike(input)+"%")
+}
+
+// tupleOperators are the comparison operators accepted by WhereTuple.
+var tupleOperators = map[string]bool{
+	"=": true, "<>": true, "!=": true,
+	"<": true, "<=": true, ">": true, ">=": true,
+}
+
+// WhereTuple adds a row-value comparison "(col1, col2) op (?, ?)", e.g. for
+// keyset pagination. The operator must be one of =, <>, !=, <, <=, >, >=
+// and the number of columns must match the number of values; otherwise the
+// condition fails closed as FALSE.
+//
+// Example:
+//
+//	builder.WhereTuple([]string{"created_at", "id"}, "<", []any{ts, id})
+//	// (created_at, id) < ($1, $2)
+func (b *SQLBuilder) WhereTuple(columns []string, op string, values []any) *SQLBuilder {
+	if !tupleOperators[op] || len(columns) == 0 || len(columns) != len(values) {
+		return b.Where("FALSE")
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+	return b.Where("("+strings.Join(columns, ", ")+") "+op+" ("+placeholders+")", values...)
+}
+
+// isNil reports whether v is nil or a nil pointer, map, slice or interface.
+func isNil(v any) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		return rv.IsNil()
+	default:
+		return false
+	}
+}
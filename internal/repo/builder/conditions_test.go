@@ -0,0 +1,266 @@
+package builder
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestWhereEq tests map-based equality conditions with nil and slice values.
+func TestWhereEq(t *testing.T) {
+	var deletedAt *string
+
+	query, args := NewSQLBuilder().
+		Select("id").
+		From("products").
+		Where("price > ?", 10).
+		WhereEq(map[string]any{
+			"status":     "active",
+			"available":  true,
+			"deleted_at": deletedAt,
+			"category":   []string{"books", "music"},
+		}).
+		Build()
+
+	expected := "SELECT id FROM products WHERE price > $1 AND available = $2 " +
+		"AND category IN ($3, $4) AND deleted_at IS NULL AND status = $5"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if !reflect.DeepEqual(args, []any{10, true, "books", "music", "active"}) {
+		t.Errorf("Expected args: [10 true books music active], got: %v", args)
+	}
+}
+
+// TestWhereEqEmptySlice tests that an empty slice matches nothing.
+func TestWhereEqEmptySlice(t *testing.T) {
+	query, args := NewSQLBuilder().
+		Delete().
+		From("products").
+		WhereEq(map[string]any{"id": []int{}}).
+		Build()
+
+	expected := "DELETE FROM products WHERE FALSE"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if len(args) != 0 {
+		t.Errorf("Expected 0 args, got: %d", len(args))
+	}
+}
+
+// TestWhereEqBytes tests that a byte slice is bound as a single value.
+func TestWhereEqBytes(t *testing.T) {
+	query, args := NewSQLBuilder().
+		Select("id").
+		From("files").
+		WhereEq(map[string]any{"checksum": []byte{1, 2}}).
+		Build()
+
+	expected := "SELECT id FROM files WHERE checksum = $1"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if len(args) != 1 {
+		t.Errorf("Expected 1 arg, got: %d", len(args))
+	}
+}
+
+// TestWhereNullHelpers tests IS NULL / IS NOT NULL conditions.
+func TestWhereNullHelpers(t *testing.T) {
+	query, args := NewSQLBuilder().
+		Select("id").
+		From("reservations").
+		WhereNull("released_at").
+		WhereNotNull("expires_at").
+		Where("product_id = ?", "p1").
+		Build()
+
+	expected := "SELECT id FROM reservations WHERE released_at IS NULL AND expires_at IS NOT NULL AND product_id = $1"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if len(args) != 1 {
+		t.Errorf("Expected 1 arg, got: %d", len(args))
+	}
+}
+
+// TestSetNull tests explicit and implicit NULL assignments.
+func TestSetNull(t *testing.T) {
+	var expiresAt *string
+
+	query, args := NewSQLBuilder().
+		Update("products").
+		SetNull("deleted_at").
+		Set("expires_at = ?", expiresAt).
+		Set("note = ?", nil).
+		Set("name = ?", "Laptop").
+		Set("price = coalesce(?, price)", nil).
+		Where("id = ?", 1).
+		Build()
+
+	expected := "UPDATE products SET deleted_at = NULL, expires_at = NULL, note = NULL, name = $1, price = coalesce($2, price) WHERE id = $3"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if len(args) != 3 || args[0] != "Laptop" || args[1] != nil || args[2] != 1 {
+		t.Errorf("Expected args: [Laptop <nil> 1], got: %v", args)
+	}
+}
+
+// TestWhereArrayOperators tests array containment helpers and their casts.
+func TestWhereArrayOperators(t *testing.T) {
+	query, args := NewSQLBuilder().
+		Select("id").
+		From("products").
+		WhereArrayContains("tags", "sale", "laptop").
+		WhereArrayOverlaps("sizes", 38, 39).
+		WhereArrayContainedBy("warehouses", int64(1)).
+		Build()
+
+	expected := "SELECT id FROM products WHERE tags @> ARRAY[$1, $2]::text[] " +
+		"AND sizes && ARRAY[$3, $4]::int[] AND warehouses <@ ARRAY[$5]::bigint[]"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if !reflect.DeepEqual(args, []any{"sale", "laptop", 38, 39, int64(1)}) {
+		t.Errorf("Expected args: [sale laptop 38 39 1], got: %v", args)
+	}
+}
+
+// TestWhereArrayEmptyValues tests the behavior of array helpers without values.
+func TestWhereArrayEmptyValues(t *testing.T) {
+	query, _ := NewSQLBuilder().
+		Select("id").
+		From("products").
+		WhereArrayContains("tags").
+		WhereArrayOverlaps("tags").
+		Build()
+
+	expected := "SELECT id FROM products WHERE FALSE"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+
+	query, _ = NewSQLBuilder().
+		Select("id").
+		From("products").
+		WhereArrayContainedBy("tags").
+		Build()
+
+	expected = "SELECT id FROM products WHERE tags <@ ARRAY[]::text[]"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+}
+
+// TestWhereTuple tests row-value comparisons for keyset pagination.
+func TestWhereTuple(t *testing.T) {
+	query, args := NewSQLBuilder().
+		Select("id", "created_at").
+		From("products").
+		Where("available = ?", true).
+		WhereTuple([]string{"created_at", "id"}, "<", []any{"2024-01-01", "p9"}).
+		OrderBy("created_at", Desc).
+		OrderBy("id", Desc).
+		Limit(20).
+		Build()
+
+	expected := "SELECT id, created_at FROM products WHERE available = $1 AND (created_at, id) < ($2, $3) " +
+		"ORDER BY created_at DESC, id DESC LIMIT 20"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if !reflect.DeepEqual(args, []any{true, "2024-01-01", "p9"}) {
+		t.Errorf("Expected args: [true 2024-01-01 p9], got: %v", args)
+	}
+}
+
+// TestWhereTupleInvalid tests that invalid tuple comparisons fail closed.
+func TestWhereTupleInvalid(t *testing.T) {
+	cases := []*SQLBuilder{
+		NewSQLBuilder().Select("id").From("t").WhereTuple([]string{"a", "b"}, "< 1 OR 1 =", []any{1, 2}),
+		NewSQLBuilder().Select("id").From("t").WhereTuple([]string{"a", "b"}, "<", []any{1}),
+		NewSQLBuilder().Select("id").From("t").WhereTuple(nil, "=", nil),
+	}
+	for i, b := range cases {
+		query, args := b.Build()
+		if query != "SELECT id FROM t WHERE FALSE" || len(args) != 0 {
+			t.Errorf("Case %d: expected FALSE condition, got: %s %v", i, query, args)
+		}
+	}
+}
+
+// TestWhereNotExists tests a correlated NOT EXISTS subquery with placeholder offsetting.
+func TestWhereNotExists(t *testing.T) {
+	pending := NewSQLBuilder().
+		Select("1").
+		From("reservations r").
+		Where("r.product_id = p.id").
+		Where("r.status = ?", "pending")
+
+	query, args := NewSQLBuilder().
+		Select("p.id").
+		From("products p").
+		Where("p.available = ?", true).
+		WhereNotExists(pending).
+		Where("p.quantity > ?", 0).
+		Build()
+
+	expected := "SELECT p.id FROM products p WHERE p.available = $1 " +
+		"AND NOT EXISTS (SELECT 1 FROM reservations r WHERE r.product_id = p.id AND r.status = $2) AND p.quantity > $3"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if !reflect.DeepEqual(args, []any{true, "pending", 0}) {
+		t.Errorf("Expected args: [true pending 0], got: %v", args)
+	}
+}
+
+// TestWhereExists tests an EXISTS subquery in a DELETE.
+func TestWhereExists(t *testing.T) {
+	query, args := NewSQLBuilder().
+		Delete().
+		From("products p").
+		WhereExists(NewSQLBuilder().Select("1").From("blocked b").Where("b.sku = p.sku AND b.reason = ?", "recall")).
+		Build()
+
+	expected := "DELETE FROM products p WHERE EXISTS (SELECT 1 FROM blocked b WHERE b.sku = p.sku AND b.reason = $1)"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if len(args) != 1 {
+		t.Errorf("Expected 1 arg, got: %d", len(args))
+	}
+}
+
+// TestEscapeLike tests escaping of LIKE wildcards.
+func TestEscapeLike(t *testing.T) {
+	cases := map[string]string{
+		"laptop":    "laptop",
+		"50%_off":   `50\%\_off`,
+		`back\path`: `back\\path`,
+	}
+	for in, expected := range cases {
+		if got := EscapeLike(in); got != expected {
+			t.Errorf("EscapeLike(%q): expected %s, got: %s", in, expected, got)
+		}
+	}
+}
+
+// TestWhereLikeHelpers tests substring search conditions with escaped input.
+func TestWhereLikeHelpers(t *testing.T) {
+	query, args := NewSQLBuilder().
+		Select("id").
+		From("products").
+		WhereILike("name", "100%").
+		WhereLike("sku", "AB_1").
+		Build()
+
+	expected := "SELECT id FROM products WHERE name ILIKE $1 AND sku LIKE $2"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if !reflect.DeepEqual(args, []any{`%100\%%`, `%AB\_1%`}) {
+		t.Errorf("Expected escaped patterns, got: %v", args)
+	}
+}
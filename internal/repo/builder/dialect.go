@@ -0,0 +1,102 @@
+package builder
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dialect describes the syntax differences between SQL databases that the
+// builder has to account for.
+type Dialect interface {
+	// Placeholder returns the bind placeholder for the n-th argument (1-based).
+	Placeholder(n int) string
+	// LimitOffset renders the LIMIT/OFFSET clause including its leading
+	// space. Negative values mean "not set".
+	LimitOffset(limit, offset int) string
+}
+
+var (
+	// Postgres generates $1, $2, ... placeholders.
+	Postgres Dialect = postgresDialect{}
+	// MySQL generates ? placeholders.
+	MySQL Dialect = mysqlDialect{}
+	// SQLite generates ? placeholders.
+	SQLite Dialect = sqliteDialect{}
+)
+
+type postgresDialect struct{}
+
+func (postgresDialect) Placeholder(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+func (postgresDialect) LimitOffset(limit, offset int) string {
+	var clause strings.Builder
+	if limit >= 0 {
+		clause.WriteString(fmt.Sprintf(" LIMIT %d", limit))
+	}
+	if offset >= 0 {
+		clause.WriteString(fmt.Sprintf(" OFFSET %d", offset))
+	}
+	return clause.String()
+}
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Placeholder(int) string {
+	return "?"
+}
+
+// LimitOffset uses the largest unsigned BIGINT as the limit when only an
+// offset is set, since MySQL does not accept OFFSET without LIMIT.
+func (mysqlDialect) LimitOffset(limit, offset int) string {
+	switch {
+	case limit < 0 && offset < 0:
+		return ""
+	case offset < 0:
+		return fmt.Sprintf(" LIMIT %d", limit)
+	case limit < 0:
+		return fmt.Sprintf(" LIMIT 18446744073709551615 OFFSET %d", offset)
+	default:
+		return fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
+	}
+}
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Placeholder(int) string {
+	return "?"
+}
+
+// LimitOffset uses LIMIT -1 when only an offset is set, since SQLite does
+// not accept OFFSET without LIMIT.
+func (sqliteDialect) LimitOffset(limit, offset int) string {
+	switch {
+	case limit < 0 && offset < 0:
+		return ""
+	case offset < 0:
+		return fmt.Sprintf(" LIMIT %d", limit)
+	default:
+		return fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
+	}
+}
+
+// placeholders numbers the bind placeholders of a query. One counter is
+// shared by a query and all of its subqueries, and it collects the first
+// error found while building them.
+type placeholders struct {
+	dialect Dialect
+	n       int
+	err     error
+}
+
+func (p *placeholders) next() string {
+	p.n++
+	return p.dialect.Placeholder(p.n)
+}
+
+func (p *placeholders) fail(err error) {
+	if p.err == nil {
+		p.err = err
+	}
+}
@@ -0,0 +1,90 @@
+package builder
+
+import "testing"
+
+// TestPostgresDialect tests that the Postgres dialect keeps $n placeholders.
+func TestPostgresDialect(t *testing.T) {
+	query, _ := NewSQLBuilder(WithDialect(Postgres)).
+		Select("id").
+		From("products").
+		Where("price > ?", 10).
+		Offset(5).
+		Build()
+
+	expected := "SELECT id FROM products WHERE price > $1 OFFSET 5"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+}
+
+// TestMySQLDialect tests placeholders and LIMIT syntax for MySQL.
+func TestMySQLDialect(t *testing.T) {
+	query, args := NewSQLBuilder(WithDialect(MySQL)).
+		Select("id", "name").
+		From("products").
+		Where("price > ?", 10).
+		Where("category IN (?, ?)", "a", "b").
+		Offset(20).
+		Build()
+
+	expected := "SELECT id, name FROM products WHERE price > ? AND category IN (?, ?) LIMIT 18446744073709551615 OFFSET 20"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if len(args) != 3 {
+		t.Errorf("Expected 3 args, got: %d", len(args))
+	}
+
+	query, _ = NewSQLBuilder(WithDialect(MySQL)).
+		Insert("products").
+		Columns("name", "price").
+		Values("Laptop", 999.99).
+		Values("Mouse", 19.99).
+		Build()
+
+	expected = "INSERT INTO products (name, price) VALUES (?, ?), (?, ?)"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+}
+
+// TestSQLiteDialect tests placeholders and LIMIT syntax for SQLite.
+func TestSQLiteDialect(t *testing.T) {
+	query, _ := NewSQLBuilder(WithDialect(SQLite)).
+		Update("products").
+		Set("price = ?", 1).
+		Where("id = ?", 2).
+		Build()
+
+	expected := "UPDATE products SET price = ? WHERE id = ?"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+
+	query, _ = NewSQLBuilder(WithDialect(SQLite)).
+		Select("id").
+		From("products").
+		Offset(10).
+		Build()
+
+	expected = "SELECT id FROM products LIMIT -1 OFFSET 10"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+}
+
+// TestSubqueryUsesOuterDialect tests that nested builders are rendered with the outer dialect.
+func TestSubqueryUsesOuterDialect(t *testing.T) {
+	sub := NewSQLBuilder().Select("id").From("categories").Where("active = ?", true)
+
+	query, _ := NewSQLBuilder(WithDialect(MySQL)).
+		Select("id").
+		From("products").
+		Where("category_id IN (?)", sub).
+		Build()
+
+	expected := "SELECT id FROM products WHERE category_id IN (SELECT id FROM categories WHERE active = ?)"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+}
@@ -55,6 +55,116 @@ func ExampleSQLBuilder_Limit() {
 	// [electronics]
 }
 
+// ExampleSQLBuilder_LeftJoin demonstrates a SELECT query with a LEFT JOIN.
+func ExampleSQLBuilder_LeftJoin() {
+	query, args := builder.NewSQLBuilder().
+		Select("p.id", "c.name").
+		From("products p").
+		LeftJoin("categories c", "c.id = p.category_id").
+		Where("p.price > ?", 100).
+		Build()
+
+	fmt.Println(query)
+	fmt.Printf("%v\n", args)
+	// Output:
+	// SELECT p.id, c.name FROM products p LEFT JOIN categories c ON c.id = p.category_id WHERE p.price > $1
+	// [100]
+}
+
+// ExampleSQLBuilder_FromSubquery demonstrates nested queries.
+func ExampleSQLBuilder_FromSubquery() {
+	popular := builder.NewSQLBuilder().
+		Select("tag").
+		FromSubquery(builder.NewSQLBuilder().
+			Select("unnest(tags) AS tag").
+			From("products"), "t").
+		Where("tag <> ?", "sale")
+
+	query, args := builder.NewSQLBuilder().
+		Select("id", "name").
+		From("products").
+		Where("available = ?", true).
+		Where("tags && ARRAY(?)", popular).
+		Build()
+
+	fmt.Println(query)
+	fmt.Printf("%v\n", args)
+	// Output:
+	// SELECT id, name FROM products WHERE available = $1 AND tags && ARRAY(SELECT tag FROM (SELECT unnest(tags) AS tag FROM products) AS t WHERE tag <> $2)
+	// [true sale]
+}
+
+// ExampleSQLBuilder_With demonstrates a query with a common table expression.
+func ExampleSQLBuilder_With() {
+	totals := builder.NewSQLBuilder().
+		Select("product_id", "sum(delta) AS qty").
+		From("stock_movements").
+		Where("created_at > ?", "2024-01-01")
+
+	query, args := builder.NewSQLBuilder().
+		With("totals(product_id, qty)", totals).
+		Select("product_id", "qty").
+		From("totals").
+		Where("qty < ?", 10).
+		Build()
+
+	fmt.Println(query)
+	fmt.Printf("%v\n", args)
+	// Output:
+	// WITH totals(product_id, qty) AS (SELECT product_id, sum(delta) AS qty FROM stock_movements WHERE created_at > $1) SELECT product_id, qty FROM totals WHERE qty < $2
+	// [2024-01-01 10]
+}
+
+// ExampleSQLBuilder_SelectCount demonstrates a total count for pagination.
+func ExampleSQLBuilder_SelectCount() {
+	query, args := builder.NewSQLBuilder().
+		SelectCount("*").
+		From("products").
+		Where("available = ?", true).
+		Build()
+
+	fmt.Println(query)
+	fmt.Printf("%v\n", args)
+	// Output:
+	// SELECT COUNT(*) FROM products WHERE available = $1
+	// [true]
+}
+
+// ExampleSQLBuilder_DistinctOn demonstrates a "latest price per product" query.
+func ExampleSQLBuilder_DistinctOn() {
+	query, args := builder.NewSQLBuilder().
+		Select("product_id", "price").
+		DistinctOn("product_id").
+		From("price_history").
+		OrderBy("product_id, changed_at DESC").
+		Build()
+
+	fmt.Println(query)
+	fmt.Printf("%v\n", args)
+	// Output:
+	// SELECT DISTINCT ON (product_id) product_id, price FROM price_history ORDER BY product_id, changed_at DESC
+	// []
+}
+
+// ExampleSQLBuilder_OrderBy demonstrates multi-column ordering with a whitelisted direction.
+func ExampleSQLBuilder_OrderBy() {
+	dir, err := builder.ParseDirection("desc")
+	if err != nil {
+		panic(err)
+	}
+
+	query, _ := builder.NewSQLBuilder().
+		Select("id", "name").
+		From("products").
+		OrderBy("price", dir).
+		OrderBy("created_at", builder.Asc).
+		Build()
+
+	fmt.Println(query)
+	// Output:
+	// SELECT id, name FROM products ORDER BY price DESC, created_at ASC
+}
+
 // ExampleSQLBuilder_Insert demonstrates an INSERT query.
 func ExampleSQLBuilder_Insert() {
 	query, args := builder.NewSQLBuilder().
@@ -70,6 +180,39 @@ func ExampleSQLBuilder_Insert() {
 	// [John Doe john@example.com 30]
 }
 
+// ExampleSQLBuilder_Values demonstrates a multi-row INSERT query.
+func ExampleSQLBuilder_Values() {
+	query, args := builder.NewSQLBuilder().
+		Insert("products").
+		Columns("name", "price").
+		Values("Laptop", 999.99).
+		Values("Mouse", 19.99).
+		Build()
+
+	fmt.Println(query)
+	fmt.Printf("%v\n", args)
+	// Output:
+	// INSERT INTO products (name, price) VALUES ($1, $2), ($3, $4)
+	// [Laptop 999.99 Mouse 19.99]
+}
+
+// ExampleSQLBuilder_OnConflict demonstrates an idempotent upsert.
+func ExampleSQLBuilder_OnConflict() {
+	query, args := builder.NewSQLBuilder().
+		Insert("products").
+		Columns("sku", "quantity").
+		Values("SKU-1", 10).
+		OnConflict("sku").
+		DoUpdateSet("quantity = EXCLUDED.quantity").
+		Build()
+
+	fmt.Println(query)
+	fmt.Printf("%v\n", args)
+	// Output:
+	// INSERT INTO products (sku, quantity) VALUES ($1, $2) ON CONFLICT (sku) DO UPDATE SET quantity = EXCLUDED.quantity
+	// [SKU-1 10]
+}
+
 // ExampleSQLBuilder_Update demonstrates an UPDATE query.
 func ExampleSQLBuilder_Update() {
 	query, args := builder.NewSQLBuilder().
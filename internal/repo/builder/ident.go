@@ -0,0 +1,193 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidIdentifier is returned when a table or column name contains
+// characters other than letters, digits, underscores and dots.
+var ErrInvalidIdentifier = errors.New("invalid SQL identifier")
+
+// ValidateIdentifier checks that name consists only of [a-zA-Z0-9_.] and
+// that none of its dot-separated parts is empty.
+//
+// Example:
+//
+//	builder.ValidateIdentifier("products.name") // nil
+//	builder.ValidateIdentifier("name; DROP TABLE products") // ErrInvalidIdentifier
+func ValidateIdentifier(name string) error {
+	if !isIdentifier(name) {
+		return fmt.Errorf("%w: %q", ErrInvalidIdentifier, name)
+	}
+	return nil
+}
+
+// QuoteIdentifier quotes every dot-separated part of name with double
+// quotes, doubling any embedded quote characters.
+//
+// Example:
+//
+//	builder.QuoteIdentifier("products.name") // "products"."name"
+func QuoteIdentifier(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = `"` + strings.ReplaceAll(part, `"`, `""`) + `"`
+	}
+	return strings.Join(parts, ".")
+}
+
+// Strict enables strict identifier mode. In strict mode Build returns an
+// empty query when any table, column or ORDER BY identifier fails
+// validation; use Validate to get the reason.
+//
+// Example:
+//
+//	b := builder.NewSQLBuilder().Strict().Select("id").From(table)
+//	if err := b.Validate(); err != nil {
+//		return err
+//	}
+func (b *SQLBuilder) Strict() *SQLBuilder {
+	b.strict = true
+	return b
+}
+
+// Validate checks every identifier of the query, including nested
+// subqueries and CTEs, and returns the first invalid one wrapped in
+// ErrInvalidIdentifier.
+func (b *SQLBuilder) Validate() error {
+	if b.tableName != "" {
+		if err := validateTableRef(b.tableName); err != nil {
+			return err
+		}
+	}
+	for _, col := range b.selectCols {
+		if !isSelectColumn(col) {
+			return fmt.Errorf("%w: %q", ErrInvalidIdentifier, col)
+		}
+	}
+	for _, cols := range [][]string{b.insertCols, b.returning, b.distinctOn, b.groupBy, b.truncate.tables} {
+		for _, col := range cols {
+			if err := ValidateIdentifier(col); err != nil {
+				return err
+			}
+		}
+	}
+	for _, join := range b.joins {
+		if err := validateTableRef(join.table); err != nil {
+			return err
+		}
+	}
+	for _, item := range b.orderBy {
+		if err := validateOrderBy(item); err != nil {
+			return err
+		}
+	}
+
+	subs := make([]*SQLBuilder, 0)
+	if b.fromSub != nil {
+		subs = append(subs, b.fromSub)
+	}
+	if b.insertSub != nil {
+		subs = append(subs, b.insertSub)
+	}
+	for _, cte := range b.ctes {
+		subs = append(subs, cte.sub)
+	}
+	for _, union := range b.unions {
+		subs = append(subs, union.sub)
+	}
+	for _, cond := range b.whereConds {
+		for _, arg := range cond.args {
+			if sub, ok := arg.(*SQLBuilder); ok {
+				subs = append(subs, sub)
+			}
+		}
+	}
+	for _, sub := range subs {
+		if err := sub.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, part := range strings.Split(name, ".") {
+		if part == "" {
+			return false
+		}
+		for i := 0; i < len(part); i++ {
+			c := part[i]
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isSelectColumn accepts identifiers, "*", "table.*" and the expressions
+// produced by the aggregate helpers.
+func isSelectColumn(col string) bool {
+	if col == "*" || isIdentifier(col) {
+		return true
+	}
+	if table, ok := strings.CutSuffix(col, ".*"); ok {
+		return isIdentifier(table)
+	}
+	for _, fn := range []string{"COUNT", "SUM", "MIN", "MAX", "AVG"} {
+		if inner, ok := strings.CutPrefix(col, fn+"("); ok {
+			inner, ok = strings.CutSuffix(inner, ")")
+			return ok && (inner == "*" || isIdentifier(inner))
+		}
+	}
+	return false
+}
+
+// validateTableRef accepts "table", "table alias" and "table AS alias".
+func validateTableRef(ref string) error {
+	fields := strings.Fields(ref)
+	valid := false
+	switch len(fields) {
+	case 1:
+		valid = isIdentifier(fields[0])
+	case 2:
+		valid = isIdentifier(fields[0]) && isIdentifier(fields[1])
+	case 3:
+		valid = isIdentifier(fields[0]) && strings.EqualFold(fields[1], "AS") && isIdentifier(fields[2])
+	}
+	if !valid {
+		return fmt.Errorf("%w: %q", ErrInvalidIdentifier, ref)
+	}
+	return nil
+}
+
+// validateOrderBy accepts a comma-separated list of "column [ASC|DESC]
+// [NULLS FIRST|NULLS LAST]" items.
+func validateOrderBy(orderBy string) error {
+	for _, item := range strings.Split(orderBy, ",") {
+		fields := strings.Fields(item)
+		valid := len(fields) > 0 && isIdentifier(fields[0])
+		rest := fields
+		if valid {
+			rest = fields[1:]
+		}
+		if valid && len(rest) > 0 && (strings.EqualFold(rest[0], "ASC") || strings.EqualFold(rest[0], "DESC")) {
+			rest = rest[1:]
+		}
+		if valid && len(rest) == 2 && strings.EqualFold(rest[0], "NULLS") &&
+			(strings.EqualFold(rest[1], "FIRST") || strings.EqualFold(rest[1], "LAST")) {
+			rest = rest[2:]
+		}
+		if !valid || len(rest) > 0 {
+			return fmt.Errorf("%w: %q", ErrInvalidIdentifier, strings.TrimSpace(item))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package builder
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestValidateIdentifier tests the allowed identifier character set.
+func TestValidateIdentifier(t *testing.T) {
+	valid := []string{"products", "products.name", "created_at", "t1.col_2"}
+	for _, name := range valid {
+		if err := ValidateIdentifier(name); err != nil {
+			t.Errorf("Expected %q to be valid, got: %v", name, err)
+		}
+	}
+
+	invalid := []string{"", "name; DROP TABLE products", "price DESC", "a..b", "na\"me", "(SELECT 1)"}
+	for _, name := range invalid {
+		if err := ValidateIdentifier(name); !errors.Is(err, ErrInvalidIdentifier) {
+			t.Errorf("Expected %q to be invalid, got: %v", name, err)
+		}
+	}
+}
+
+// TestQuoteIdentifier tests quoting of qualified names and embedded quotes.
+func TestQuoteIdentifier(t *testing.T) {
+	cases := map[string]string{
+		"products":      `"products"`,
+		"products.name": `"products"."name"`,
+		`we"ird`:        `"we""ird"`,
+	}
+	for in, expected := range cases {
+		if got := QuoteIdentifier(in); got != expected {
+			t.Errorf("Expected %s, got: %s", expected, got)
+		}
+	}
+}
+
+// TestStrictModeAcceptsValidQuery tests that strict mode does not change valid queries.
+func TestStrictModeAcceptsValidQuery(t *testing.T) {
+	b := NewSQLBuilder().
+		Strict().
+		Select("p.id", "p.name", "c.*").
+		SelectCount("*").
+		From("products AS p").
+		LeftJoin("categories c", "c.id = p.category_id").
+		Where("p.price > ?", 10).
+		OrderBy("p.price DESC, p.created_at ASC NULLS LAST")
+
+	if err := b.Validate(); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	query, args := b.Build()
+	expected := "SELECT p.id, p.name, c.*, COUNT(*) FROM products AS p LEFT JOIN categories c ON c.id = p.category_id " +
+		"WHERE p.price > $1 ORDER BY p.price DESC, p.created_at ASC NULLS LAST"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if len(args) != 1 {
+		t.Errorf("Expected 1 arg, got: %d", len(args))
+	}
+}
+
+// TestStrictModeRejectsInjection tests that strict mode refuses to build unsafe identifiers.
+func TestStrictModeRejectsInjection(t *testing.T) {
+	cases := []*SQLBuilder{
+		NewSQLBuilder().Strict().Select("id").From("products").OrderBy("price; DROP TABLE products"),
+		NewSQLBuilder().Strict().Select("id, (SELECT password FROM users)").From("products"),
+		NewSQLBuilder().Strict().Insert("products; --").Columns("name").Values("x"),
+		NewSQLBuilder().Strict().Update("products").Set("name = ?", "x").Returning("id; --"),
+		NewSQLBuilder().Strict().Select("id").From("products").
+			Where("id IN (?)", NewSQLBuilder().Select("id").From("t WHERE 1=1")),
+	}
+	for i, b := range cases {
+		if err := b.Validate(); !errors.Is(err, ErrInvalidIdentifier) {
+			t.Errorf("Case %d: expected ErrInvalidIdentifier, got: %v", i, err)
+		}
+		query, args := b.Build()
+		if query != "" || args != nil {
+			t.Errorf("Case %d: expected empty build, got: %s %v", i, query, args)
+		}
+	}
+}
+
+// TestNonStrictModeBuildsVerbatim tests that identifiers are interpolated verbatim without strict mode.
+func TestNonStrictModeBuildsVerbatim(t *testing.T) {
+	b := NewSQLBuilder().Select("id").From("products").OrderBy("lower(name)")
+	if err := b.Validate(); err == nil {
+		t.Errorf("Expected validation error for expression in ORDER BY")
+	}
+	query, _ := b.Build()
+	expected := "SELECT id FROM products ORDER BY lower(name)"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+}
@@ -0,0 +1,79 @@
+package builder
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// The JSONB helpers pass values as JSON text cast to jsonb, so they work
+// with any pgx query exec mode. They are PostgreSQL-only. A value that
+// cannot be encoded as JSON makes Build fail; Err reports why.
+
+// WhereJSONContains adds a "column @> ?::jsonb" condition matching rows
+// whose JSONB column contains value, e.g. an object with some of the keys.
+// A GIN index on column can serve it.
+//
+// Example:
+//
+//	builder.WhereJSONContains("attributes", map[string]string{"color": "red"})
+//	// attributes @> $1::jsonb with `{"color":"red"}`
+func (b *SQLBuilder) WhereJSONContains(column string, value any) *SQLBuilder {
+	return b.Where(column+" @> ?::jsonb", b.jsonArg(column, value))
+}
+
+// WhereJSONHasKey adds a condition matching rows whose JSONB object column
+// has key at the top level. It calls jsonb_exists, as the ? operator would
+// be taken for a placeholder.
+//
+// Example:
+//
+//	builder.WhereJSONHasKey("attributes", "color")
+//	// jsonb_exists(attributes, $1)
+func (b *SQLBuilder) WhereJSONHasKey(column, key string) *SQLBuilder {
+	return b.Where("jsonb_exists("+column+", ?)", key)
+}
+
+// WhereJSONFieldEq adds a "column->>? = ?" condition comparing the text of
+// the top-level field key of a JSONB object column with value. Unlike
+// WhereJSONContains it cannot use a GIN index on column.
+//
+// Example:
+//
+//	builder.WhereJSONFieldEq("attributes", "color", "red")
+//	// attributes->>$1 = $2
+func (b *SQLBuilder) WhereJSONFieldEq(column, key, value string) *SQLBuilder {
+	return b.Where(column+"->>? = ?", key, value)
+}
+
+// SetJSONMerge adds a SET clause for an UPDATE query that removes the
+// top-level keys remove from the JSONB object column and then merges the
+// object merge into it, overwriting keys it has. A nil merge only removes.
+//
+// Example:
+//
+//	builder.Update("products").
+//		SetJSONMerge("attributes", map[string]string{"color": "red"}, "size")
+//	// attributes = (attributes - $1::text[]) || $2::jsonb
+func (b *SQLBuilder) SetJSONMerge(column string, merge any, remove ...string) *SQLBuilder {
+	expr, args := column, []any{}
+	if len(remove) > 0 {
+		expr, args = "("+column+" - ?::text[])", append(args, remove)
+	}
+	if !isNil(merge) {
+		expr, args = expr+" || ?::jsonb", append(args, b.jsonArg(column, merge))
+	}
+	return b.Set(column+" = "+expr, args...)
+}
+
+// jsonArg returns value encoded as JSON text, recording an encoding error
+// for Build.
+func (b *SQLBuilder) jsonArg(column string, value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		if b.argErr == nil {
+			b.argErr = fmt.Errorf("encode %s as JSON: %w", column, err)
+		}
+		return ""
+	}
+	return string(data)
+}
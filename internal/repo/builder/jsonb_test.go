@@ -0,0 +1,71 @@
+package builder
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestWhereJSONHelpers tests the JSONB conditions and their arguments.
+func TestWhereJSONHelpers(t *testing.T) {
+	query, args := NewSQLBuilder().
+		Select("id").
+		From("products").
+		WhereJSONContains("attributes", map[string]string{"color": "red"}).
+		WhereJSONHasKey("attributes", "size").
+		WhereJSONFieldEq("attributes", "material", "wood").
+		Build()
+
+	expected := "SELECT id FROM products WHERE attributes @> $1::jsonb " +
+		"AND jsonb_exists(attributes, $2) AND attributes->>$3 = $4"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if !reflect.DeepEqual(args, []any{`{"color":"red"}`, "size", "material", "wood"}) {
+		t.Errorf("Unexpected args: %v", args)
+	}
+}
+
+// TestSetJSONMerge tests merging into and removing keys from a JSONB
+// column.
+func TestSetJSONMerge(t *testing.T) {
+	query, args := NewSQLBuilder().
+		Update("products").
+		SetJSONMerge("attributes", map[string]string{"color": "red"}, "size").
+		Where("id = ?", "1").
+		Build()
+
+	expected := "UPDATE products SET attributes = (attributes - $1::text[]) || $2::jsonb WHERE id = $3"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if !reflect.DeepEqual(args, []any{[]string{"size"}, `{"color":"red"}`, "1"}) {
+		t.Errorf("Unexpected args: %v", args)
+	}
+
+	query, _ = NewSQLBuilder().
+		Update("products").
+		SetJSONMerge("attributes", nil, "size").
+		Build()
+	if expected := "UPDATE products SET attributes = (attributes - $1::text[])"; query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+}
+
+// TestJSONEncodeError tests that a value that is not JSON fails Build.
+func TestJSONEncodeError(t *testing.T) {
+	b := NewSQLBuilder().
+		Select("id").
+		From("products").
+		WhereJSONContains("attributes", map[string]any{"bad": make(chan int)})
+
+	query, args := b.Build()
+	if query != "" || args != nil {
+		t.Errorf("Expected an empty query, got: %q %v", query, args)
+	}
+	var unsupported *json.UnsupportedTypeError
+	if err := b.Err(); !errors.As(err, &unsupported) {
+		t.Errorf("Expected an encoding error, got: %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+package builder
+
+// lockClause is the row-locking clause of a SELECT query.
+type lockClause struct {
+	strength string // UPDATE or SHARE, empty when not locking
+	wait     string // SKIP LOCKED or NOWAIT, empty to wait
+}
+
+// ForUpdate locks the selected rows against concurrent updates until the
+// end of the transaction.
+//
+// Example:
+//
+//	builder.Select("quantity").From("products").Where("id = ?", id).ForUpdate()
+//	// Result: SELECT quantity FROM products WHERE id = $1 FOR UPDATE
+func (b *SQLBuilder) ForUpdate() *SQLBuilder {
+	b.lock.strength = "UPDATE"
+	return b
+}
+
+// ForShare locks the selected rows against concurrent updates while
+// allowing other transactions to share the lock.
+func (b *SQLBuilder) ForShare() *SQLBuilder {
+	b.lock.strength = "SHARE"
+	return b
+}
+
+// SkipLocked makes a locking SELECT skip rows locked by other transactions
+// instead of waiting for them, e.g. for job queues.
+func (b *SQLBuilder) SkipLocked() *SQLBuilder {
+	b.lock.wait = "SKIP LOCKED"
+	return b
+}
+
+// NoWait makes a locking SELECT fail instead of waiting for rows locked by
+// other transactions.
+func (b *SQLBuilder) NoWait() *SQLBuilder {
+	b.lock.wait = "NOWAIT"
+	return b
+}
+
+// String renders the clause including its leading space.
+func (l lockClause) String() string {
+	if l.strength == "" {
+		return ""
+	}
+	clause := " FOR " + l.strength
+	if l.wait != "" {
+		clause += " " + l.wait
+	}
+	return clause
+}
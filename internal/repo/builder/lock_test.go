@@ -0,0 +1,28 @@
+package builder
+
+import "testing"
+
+// TestLocking tests the row-locking clauses of SELECT queries.
+func TestLocking(t *testing.T) {
+	cases := []struct {
+		name     string
+		lock     func(*SQLBuilder) *SQLBuilder
+		expected string
+	}{
+		{"for update", (*SQLBuilder).ForUpdate, "SELECT id FROM jobs WHERE id = $1 LIMIT 1 FOR UPDATE"},
+		{"for share", (*SQLBuilder).ForShare, "SELECT id FROM jobs WHERE id = $1 LIMIT 1 FOR SHARE"},
+		{"skip locked", func(b *SQLBuilder) *SQLBuilder { return b.ForUpdate().SkipLocked() }, "SELECT id FROM jobs WHERE id = $1 LIMIT 1 FOR UPDATE SKIP LOCKED"},
+		{"nowait", func(b *SQLBuilder) *SQLBuilder { return b.ForUpdate().NoWait() }, "SELECT id FROM jobs WHERE id = $1 LIMIT 1 FOR UPDATE NOWAIT"},
+		{"wait without lock", (*SQLBuilder).SkipLocked, "SELECT id FROM jobs WHERE id = $1 LIMIT 1"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := NewSQLBuilder().Select("id").From("jobs").Where("id = ?", 1).Limit(1)
+			query, _ := tc.lock(b).Build()
+			if query != tc.expected {
+				t.Errorf("Expected query: %s, got: %s", tc.expected, query)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package builder
+
+import "strings"
+
+// bindNamed rewrites :name parameters in clause into ? placeholders and
+// returns the matching values from params in placeholder order. A name used
+// several times is bound several times. PostgreSQL casts (::type), text in
+// single-quoted literals and names missing from params are left untouched.
+func bindNamed(clause string, params map[string]any) (string, []any) {
+	var result strings.Builder
+	args := make([]any, 0, len(params))
+	inLiteral := false
+	for i := 0; i < len(clause); i++ {
+		c := clause[i]
+		switch {
+		case c == '\'':
+			inLiteral = !inLiteral
+		case c == ':' && !inLiteral && i+1 < len(clause) && clause[i+1] == ':':
+			result.WriteString("::")
+			i++
+			continue
+		case c == ':' && !inLiteral && i+1 < len(clause) && isNameStart(clause[i+1]):
+			end := i + 1
+			for end < len(clause) && isNameChar(clause[end]) {
+				end++
+			}
+			if value, ok := params[clause[i+1:end]]; ok {
+				result.WriteByte('?')
+				args = append(args, value)
+				i = end - 1
+				continue
+			}
+		}
+		result.WriteByte(c)
+	}
+	return result.String(), args
+}
+
+func isNameStart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_'
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || c >= '0' && c <= '9'
+}
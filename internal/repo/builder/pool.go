@@ -0,0 +1,58 @@
+package builder
+
+import "sync"
+
+// builderPool recycles builders, together with the capacity of their
+// clause slices, between NewSQLBuilder and Release.
+var builderPool = sync.Pool{
+	New: func() any {
+		b := &SQLBuilder{}
+		b.reset()
+		return b
+	},
+}
+
+// Release resets the builder and returns it to the pool used by
+// NewSQLBuilder. The builder must not be used after Release, neither
+// directly nor as a subquery of another builder; the query and arguments
+// returned by Build stay valid. Subqueries are not released.
+//
+// Example:
+//
+//	b := builder.NewSQLBuilder().Select("id").From("products")
+//	query, args := b.Build()
+//	b.Release()
+func (b *SQLBuilder) Release() {
+	b.reset()
+	builderPool.Put(b)
+}
+
+// reset clears the builder to the state of a new one, keeping the
+// capacity of its slices and dropping references to arguments.
+func (b *SQLBuilder) reset() {
+	clear(b.values)
+	clear(b.setClauses)
+	clear(b.joins)
+	clear(b.whereConds)
+	clear(b.ctes)
+	clear(b.unions)
+	*b = SQLBuilder{
+		selectCols: b.selectCols[:0],
+		insertCols: b.insertCols[:0],
+		values:     b.values[:0],
+		returning:  b.returning[:0],
+		distinctOn: b.distinctOn[:0],
+		ctes:       b.ctes[:0],
+		setClauses: b.setClauses[:0],
+		joins:      b.joins[:0],
+		whereConds: b.whereConds[:0],
+		groupBy:    b.groupBy[:0],
+		orderBy:    b.orderBy[:0],
+		limitVal:   -1,
+		offsetVal:  -1,
+		dialect:    Postgres,
+		truncate:   truncateOptions{tables: b.truncate.tables[:0]},
+		comments:   b.comments[:0],
+		unions:     b.unions[:0],
+	}
+}
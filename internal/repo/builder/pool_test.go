@@ -0,0 +1,71 @@
+package builder
+
+import "testing"
+
+// TestReleaseResets tests that a released builder comes back empty.
+func TestReleaseResets(t *testing.T) {
+	for i := 0; i < 3; i++ {
+		b := NewSQLBuilder()
+		query, args := b.Build()
+		if query != "" || len(args) != 0 {
+			t.Fatalf("Expected a fresh builder, got query: %s, args: %v", query, args)
+		}
+
+		b.Select("id").
+			From("products").
+			Where("price > ?", 10).
+			OrderBy("price", Desc).
+			Limit(5).
+			Offset(10).
+			Comment("reused")
+		b.Build()
+		b.Release()
+	}
+}
+
+// TestReleaseKeepsBuiltArgs tests that Build results survive Release.
+func TestReleaseKeepsBuiltArgs(t *testing.T) {
+	b := NewSQLBuilder().Select("id").From("products").Where("id = ?", "a")
+	query, args := b.Build()
+	b.Release()
+
+	NewSQLBuilder().Select("id").From("products").Where("id = ?", "b").Build()
+
+	if query != "SELECT id FROM products WHERE id = $1" {
+		t.Errorf("Unexpected query: %s", query)
+	}
+	if len(args) != 1 || args[0] != "a" {
+		t.Errorf("Expected args [a], got: %v", args)
+	}
+}
+
+var benchColumns = []string{"id", "name", "description", "price", "quantity", "tags", "available", "created_at", "updated_at"}
+
+// listQuery builds the query of the ListProducts RPC.
+func listQuery() *SQLBuilder {
+	return NewSQLBuilder().
+		Select(benchColumns...).
+		From("products").
+		Where("quantity > ?", 0).
+		Where("available = ?", true).
+		WhereArrayContains("tags", "sale").
+		OrderBy("created_at", Desc).
+		Offset(20).
+		Limit(10)
+}
+
+func BenchmarkListQuery(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		listQuery().Build()
+	}
+}
+
+func BenchmarkListQueryRelease(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		q := listQuery()
+		q.Build()
+		q.Release()
+	}
+}
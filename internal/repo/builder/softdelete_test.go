@@ -0,0 +1,101 @@
+package builder
+
+import "testing"
+
+// TestSoftDeleteSelect tests the implicit deleted_at filter on SELECT queries.
+func TestSoftDeleteSelect(t *testing.T) {
+	query, args := NewSQLBuilder(WithSoftDelete("deleted_at")).
+		Select("id").
+		From("products").
+		Where("price > ?", 10).
+		Build()
+
+	expected := "SELECT id FROM products WHERE price > $1 AND deleted_at IS NULL"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if len(args) != 1 {
+		t.Errorf("Expected 1 arg, got: %d", len(args))
+	}
+
+	query, _ = NewSQLBuilder(WithSoftDelete("deleted_at")).
+		Select("p.id", "c.name").
+		From("products p").
+		LeftJoin("categories c", "c.id = p.category_id").
+		Build()
+
+	expected = "SELECT p.id, c.name FROM products p LEFT JOIN categories c ON c.id = p.category_id WHERE p.deleted_at IS NULL"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+}
+
+// TestSoftDeleteDelete tests that Delete is converted into an UPDATE.
+func TestSoftDeleteDelete(t *testing.T) {
+	query, args := NewSQLBuilder(WithSoftDelete("deleted_at")).
+		Delete().
+		From("products").
+		Where("id = ?", "p1").
+		Returning("id").
+		Build()
+
+	expected := "UPDATE products SET deleted_at = now() WHERE id = $1 AND deleted_at IS NULL RETURNING id"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if len(args) != 1 {
+		t.Errorf("Expected 1 arg, got: %d", len(args))
+	}
+}
+
+// TestSoftDeleteUpdateAndInsert tests UPDATE filtering and untouched INSERT queries.
+func TestSoftDeleteUpdateAndInsert(t *testing.T) {
+	query, _ := NewSQLBuilder(WithSoftDelete("deleted_at")).
+		Update("products").
+		Set("name = ?", "x").
+		Where("id = ?", "p1").
+		Build()
+
+	expected := "UPDATE products SET name = $1 WHERE id = $2 AND deleted_at IS NULL"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+
+	query, _ = NewSQLBuilder(WithSoftDelete("deleted_at")).
+		Insert("products").
+		Columns("id").
+		Values("p1").
+		Build()
+
+	expected = "INSERT INTO products (id) VALUES ($1)"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+}
+
+// TestSoftDeleteUnscoped tests opting out of soft deletes for one query.
+func TestSoftDeleteUnscoped(t *testing.T) {
+	query, _ := NewSQLBuilder(WithSoftDelete("deleted_at")).
+		Unscoped().
+		Delete().
+		From("products").
+		Where("id = ?", "p1").
+		Build()
+
+	expected := "DELETE FROM products WHERE id = $1"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+
+	query, _ = NewSQLBuilder(WithSoftDelete("deleted_at")).
+		Unscoped().
+		Update("products").
+		SetNull("deleted_at").
+		Where("id = ?", "p1").
+		Build()
+
+	expected = "UPDATE products SET deleted_at = NULL WHERE id = $1"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+}
@@ -0,0 +1,116 @@
+package builder
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// structField is a struct field mapped to a column through its db tag.
+type structField struct {
+	column string
+	value  any
+}
+
+// structFields returns the db-tagged fields of v in declaration order.
+// v must be a struct or a pointer to a struct. Fields without a db tag or
+// tagged "-" are ignored; embedded structs are flattened.
+func structFields(v any) []structField {
+	return valueFields(reflect.ValueOf(v))
+}
+
+func valueFields(rv reflect.Value) []structField {
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	fields := make([]structField, 0, rv.NumField())
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		tag, ok := f.Tag.Lookup("db")
+		if !ok && f.Anonymous {
+			fields = append(fields, valueFields(rv.Field(i))...)
+			continue
+		}
+		column, _, _ := strings.Cut(tag, ",")
+		if !f.IsExported() || column == "" || column == "-" {
+			continue
+		}
+		fields = append(fields, structField{
+			column: column,
+			value:  rv.Field(i).Interface(),
+		})
+	}
+	return fields
+}
+
+// StructColumns returns the column names of the db-tagged fields of v in
+// declaration order. It is handy for SELECT and RETURNING lists.
+//
+// Example:
+//
+//	type row struct {
+//		ID   string `db:"id"`
+//		Name string `db:"name"`
+//	}
+//	builder.StructColumns(row{}) // [id name]
+func StructColumns(v any) []string {
+	fields := structFields(v)
+	columns := make([]string, len(fields))
+	for i, f := range fields {
+		columns[i] = f.column
+	}
+	return columns
+}
+
+// InsertStruct adds the db-tagged fields of v as the columns and a row of
+// values of an INSERT query. Columns listed in skip are left out, e.g.
+// ones filled by database defaults. Calling it again adds another row;
+// the columns are taken from the first call.
+//
+// Example:
+//
+//	builder.Insert("products").InsertStruct(row, "id")
+//	// Multi-row insert
+//	for _, row := range rows {
+//		b.InsertStruct(row)
+//	}
+func (b *SQLBuilder) InsertStruct(v any, skip ...string) *SQLBuilder {
+	columns := make([]string, 0)
+	values := make([]any, 0)
+	for _, f := range structFields(v) {
+		if slices.Contains(skip, f.column) {
+			continue
+		}
+		columns = append(columns, f.column)
+		values = append(values, f.value)
+	}
+	if len(b.insertCols) == 0 {
+		b.Columns(columns...)
+	}
+	return b.Values(values...)
+}
+
+// UpdateStruct adds a SET clause for the db-tagged fields of v. When
+// columns are given only those are set (a field mask); otherwise every
+// tagged field is. Columns that v does not have are ignored.
+//
+// Example:
+//
+//	builder.Update("products").UpdateStruct(row, "name", "price")
+func (b *SQLBuilder) UpdateStruct(v any, columns ...string) *SQLBuilder {
+	for _, f := range structFields(v) {
+		if len(columns) > 0 && !slices.Contains(columns, f.column) {
+			continue
+		}
+		b.Set(f.column+" = ?", f.value)
+	}
+	return b
+}
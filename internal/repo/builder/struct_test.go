@@ -0,0 +1,106 @@
+package builder
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testAudit struct {
+	CreatedBy string `db:"created_by"`
+}
+
+type testProduct struct {
+	ID       string  `db:"id"`
+	Name     string  `db:"name"`
+	Price    float64 `db:"price"`
+	Internal string  `db:"-"`
+	Untagged string
+	secret   string `db:"secret"`
+	testAudit
+}
+
+// TestStructColumns tests column discovery from db tags.
+func TestStructColumns(t *testing.T) {
+	columns := StructColumns(&testProduct{})
+
+	expected := []string{"id", "name", "price", "created_by"}
+	if !reflect.DeepEqual(columns, expected) {
+		t.Errorf("Expected columns: %v, got: %v", expected, columns)
+	}
+}
+
+// TestInsertStruct tests an INSERT generated from a tagged struct with skipped columns.
+func TestInsertStruct(t *testing.T) {
+	p := testProduct{ID: "1", Name: "Laptop", Price: 999.99, testAudit: testAudit{CreatedBy: "admin"}, secret: "x"}
+
+	query, args := NewSQLBuilder().
+		Insert("products").
+		InsertStruct(p, "id").
+		Returning("id").
+		Build()
+
+	expected := "INSERT INTO products (name, price, created_by) VALUES ($1, $2, $3) RETURNING id"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if !reflect.DeepEqual(args, []any{"Laptop", 999.99, "admin"}) {
+		t.Errorf("Expected args: [Laptop 999.99 admin], got: %v", args)
+	}
+}
+
+// TestInsertStructRows tests a multi-row INSERT from repeated InsertStruct calls.
+func TestInsertStructRows(t *testing.T) {
+	b := NewSQLBuilder().Insert("products")
+	for _, p := range []testProduct{{ID: "1", Name: "Laptop"}, {ID: "2", Name: "Mouse", Price: 20}} {
+		b.InsertStruct(p, "created_by")
+	}
+	query, args := b.Build()
+
+	expected := "INSERT INTO products (id, name, price) VALUES ($1, $2, $3), ($4, $5, $6)"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if !reflect.DeepEqual(args, []any{"1", "Laptop", 0.0, "2", "Mouse", 20.0}) {
+		t.Errorf("Expected args for two rows, got: %v", args)
+	}
+}
+
+// TestUpdateStruct tests UPDATE generation with and without a field mask.
+func TestUpdateStruct(t *testing.T) {
+	p := &testProduct{ID: "1", Name: "Laptop", Price: 10}
+
+	query, args := NewSQLBuilder().
+		Update("products").
+		UpdateStruct(p, "price", "name", "unknown").
+		Where("id = ?", p.ID).
+		Build()
+
+	expected := "UPDATE products SET name = $1, price = $2 WHERE id = $3"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if !reflect.DeepEqual(args, []any{"Laptop", 10.0, "1"}) {
+		t.Errorf("Expected args: [Laptop 10 1], got: %v", args)
+	}
+
+	query, _ = NewSQLBuilder().
+		Update("products").
+		UpdateStruct(p).
+		Build()
+
+	expected = "UPDATE products SET id = $1, name = $2, price = $3, created_by = $4"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+}
+
+// TestStructHelpersIgnoreNonStructs tests that non-struct values add nothing.
+func TestStructHelpersIgnoreNonStructs(t *testing.T) {
+	var nilProduct *testProduct
+	if columns := StructColumns(nilProduct); len(columns) != 0 {
+		t.Errorf("Expected no columns for nil pointer, got: %v", columns)
+	}
+	if columns := StructColumns(42); len(columns) != 0 {
+		t.Errorf("Expected no columns for int, got: %v", columns)
+	}
+}
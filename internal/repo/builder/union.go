@@ -0,0 +1,26 @@
+package builder
+
+type unionClause struct {
+	kind string // UNION or UNION ALL
+	sub  *SQLBuilder
+}
+
+// Union combines a SELECT query with another one, removing duplicate rows.
+// ORDER BY, LIMIT and OFFSET of the outer query apply to the combined
+// result; placeholders are numbered in query order.
+//
+// Example:
+//
+//	builder.Select("id").From("products").Union(
+//		builder.NewSQLBuilder().Select("id").From("archived_products"))
+//	// Result: SELECT id FROM products UNION SELECT id FROM archived_products
+func (b *SQLBuilder) Union(sub *SQLBuilder) *SQLBuilder {
+	b.unions = append(b.unions, unionClause{kind: "UNION", sub: sub})
+	return b
+}
+
+// UnionAll is like Union but keeps duplicate rows.
+func (b *SQLBuilder) UnionAll(sub *SQLBuilder) *SQLBuilder {
+	b.unions = append(b.unions, unionClause{kind: "UNION ALL", sub: sub})
+	return b
+}
@@ -0,0 +1,56 @@
+package builder
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestUnion tests combining SELECT queries.
+func TestUnion(t *testing.T) {
+	query, args := NewSQLBuilder().
+		Select("id").
+		From("products").
+		Where("price > ?", 10).
+		Union(NewSQLBuilder().Select("id").From("archived_products").Where("price > ?", 20)).
+		UnionAll(NewSQLBuilder().Select("id").From("drafts")).
+		OrderBy("id").
+		Limit(5).
+		Build()
+
+	expected := "SELECT id FROM products WHERE price > $1 UNION SELECT id FROM archived_products WHERE price > $2 " +
+		"UNION ALL SELECT id FROM drafts ORDER BY id LIMIT 5"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if !reflect.DeepEqual(args, []any{10, 20}) {
+		t.Errorf("Expected args [10 20], got: %v", args)
+	}
+}
+
+// TestWithRecursive tests a recursive CTE walking a tree.
+func TestWithRecursive(t *testing.T) {
+	tree := NewSQLBuilder().
+		Select("id", "parent_id").
+		From("categories").
+		Where("id = ?", "root").
+		UnionAll(NewSQLBuilder().
+			Select("c.id", "c.parent_id").
+			From("categories c").
+			Join("tree t", "c.parent_id = t.id"))
+
+	query, args := NewSQLBuilder().
+		With("other", NewSQLBuilder().Select("id").From("x")).
+		WithRecursive("tree", tree).
+		Select("id").
+		From("tree").
+		Build()
+
+	expected := "WITH RECURSIVE other AS (SELECT id FROM x), tree AS (SELECT id, parent_id FROM categories WHERE id = $1 " +
+		"UNION ALL SELECT c.id, c.parent_id FROM categories c JOIN tree t ON c.parent_id = t.id) SELECT id FROM tree"
+	if query != expected {
+		t.Errorf("Expected query: %s, got: %s", expected, query)
+	}
+	if !reflect.DeepEqual(args, []any{"root"}) {
+		t.Errorf("Expected args [root], got: %v", args)
+	}
+}
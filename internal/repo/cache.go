@@ -0,0 +1,191 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"time"
+
+	pb "github.com/andro-kes/inventory_service/proto"
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// cacheStats counts Get cache hits, misses and Redis errors. It is
+// published through expvar as repo_cache.
+var cacheStats = expvar.NewMap("repo_cache")
+
+// cacheKeyPrefix prefixes the Redis keys of cached products.
+const cacheKeyPrefix = "inventory:product:"
+
+// cacheRepo serves Get from Redis and falls through to next on a miss.
+// Writes changing a product drop its key once they succeed. Redis errors
+// never fail a call: reads fall through and are counted in cacheStats.
+type cacheRepo struct {
+	ProductRepo
+	client redis.UniversalClient
+	ttl    time.Duration
+}
+
+// NewCachedProductRepo returns next with Get cached in client for ttl.
+// Products are cached only outside transactions, so uncommitted rows never
+// reach the cache. A read racing with a write may cache the old product
+// for up to ttl.
+func NewCachedProductRepo(next ProductRepo, client redis.UniversalClient, ttl time.Duration) ProductRepo {
+	return &cacheRepo{
+		ProductRepo: next,
+		client:      client,
+		ttl:         ttl,
+	}
+}
+
+func cacheKey(id string) string {
+	return cacheKeyPrefix + id
+}
+
+// uncachedKey is the context key of WithoutCache.
+type uncachedKey struct{}
+
+// WithoutCache returns a copy of ctx in which Get reads the database even
+// when the product is cached, e.g. to read a product just announced as
+// changed before the writer dropped its key.
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, uncachedKey{}, true)
+}
+
+// Uncached reports whether ctx was returned by WithoutCache.
+func Uncached(ctx context.Context) bool {
+	return ctx.Value(uncachedKey{}) != nil
+}
+
+func (c *cacheRepo) Get(ctx context.Context, id string) (*pb.Product, error) {
+	if ctx.Value(txKey{}) != nil || ctx.Value(uncachedKey{}) != nil {
+		return c.ProductRepo.Get(ctx, id)
+	}
+
+	data, err := c.client.Get(ctx, cacheKey(id)).Bytes()
+	if err == nil {
+		var p pb.Product
+		if err := proto.Unmarshal(data, &p); err == nil {
+			cacheStats.Add("hits", 1)
+			return maskProduct(ctx, &p), nil
+		}
+	}
+	if err != nil && !errors.Is(err, redis.Nil) {
+		cacheStats.Add("errors", 1)
+	}
+	cacheStats.Add("misses", 1)
+
+	// Only whole products are cached, whatever the read mask.
+	p, err := c.ProductRepo.Get(context.WithValue(ctx, readMaskKey{}, map[string]bool(nil)), id)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err := proto.Marshal(p); err == nil {
+		if err := c.client.Set(ctx, cacheKey(id), data, c.ttl).Err(); err != nil {
+			cacheStats.Add("errors", 1)
+		}
+	}
+	return maskProduct(ctx, p), nil
+}
+
+// invalidate drops the cached product id.
+func (c *cacheRepo) invalidate(ctx context.Context, id string) {
+	if err := c.client.Del(ctx, cacheKey(id)).Err(); err != nil {
+		cacheStats.Add("errors", 1)
+	}
+}
+
+// invalidateAll drops all cached products, scanning every master of a
+// Redis Cluster.
+func (c *cacheRepo) invalidateAll(ctx context.Context) {
+	if cluster, ok := c.client.(*redis.ClusterClient); ok {
+		_ = cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			invalidatePrefix(ctx, node)
+			return nil
+		})
+		return
+	}
+	invalidatePrefix(ctx, c.client)
+}
+
+// invalidatePrefix drops the cached products stored on client.
+func invalidatePrefix(ctx context.Context, client redis.Cmdable) {
+	iter := client.Scan(ctx, 0, cacheKeyPrefix+"*", 1000).Iterator()
+	for iter.Next(ctx) {
+		if err := client.Del(ctx, iter.Val()).Err(); err != nil {
+			cacheStats.Add("errors", 1)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		cacheStats.Add("errors", 1)
+	}
+}
+
+func (c *cacheRepo) Update(ctx context.Context, p *pb.Product, mask *fieldmaskpb.FieldMask) (*pb.Product, error) {
+	updated, err := c.ProductRepo.Update(ctx, p, mask)
+	if err != nil {
+		return nil, err
+	}
+	c.invalidate(ctx, p.GetId())
+	return updated, nil
+}
+
+func (c *cacheRepo) UpsertBySKU(ctx context.Context, p *pb.Product) (*pb.Product, error) {
+	stored, err := c.ProductRepo.UpsertBySKU(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	c.invalidate(ctx, stored.GetId())
+	return stored, nil
+}
+
+func (c *cacheRepo) Delete(ctx context.Context, id string) error {
+	if err := c.ProductRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	c.invalidate(ctx, id)
+	return nil
+}
+
+// DeleteWhere drops all cached products rather than one key per deleted
+// product, as bulk deletes may cover much of the catalog.
+func (c *cacheRepo) DeleteWhere(ctx context.Context, filter ListFilter) ([]string, error) {
+	deleted, err := c.ProductRepo.DeleteWhere(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(deleted) > 0 {
+		c.invalidateAll(ctx)
+	}
+	return deleted, nil
+}
+
+func (c *cacheRepo) Restore(ctx context.Context, id string) (*pb.Product, error) {
+	p, err := c.ProductRepo.Restore(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	c.invalidate(ctx, id)
+	return p, nil
+}
+
+func (c *cacheRepo) DecrementQuantity(ctx context.Context, id string, delta int32) (int32, error) {
+	quantity, err := c.ProductRepo.DecrementQuantity(ctx, id, delta)
+	if err != nil {
+		return 0, err
+	}
+	c.invalidate(ctx, id)
+	return quantity, nil
+}
+
+func (c *cacheRepo) ConfirmReservation(ctx context.Context, id string) (*Reservation, int32, error) {
+	r, quantity, err := c.ProductRepo.ConfirmReservation(ctx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	c.invalidate(ctx, r.ProductID)
+	return r, quantity, nil
+}
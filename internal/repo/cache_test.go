@@ -0,0 +1,151 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	pb "github.com/andro-kes/inventory_service/proto"
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// stubRepo serves Get from products and counts the calls.
+type stubRepo struct {
+	ProductRepo
+	products map[string]*pb.Product
+	gets     int
+}
+
+func (s *stubRepo) Get(ctx context.Context, id string) (*pb.Product, error) {
+	s.gets++
+	return s.products[id], nil
+}
+
+func (s *stubRepo) Update(ctx context.Context, p *pb.Product, mask *fieldmaskpb.FieldMask) (*pb.Product, error) {
+	s.products[p.GetId()] = p
+	return p, nil
+}
+
+// TestCacheRepo tests that Get is served from Redis after the first call
+// and reloaded after an Update.
+func TestCacheRepo(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	stub := &stubRepo{products: map[string]*pb.Product{"1": {Id: "1", Price: 10}}}
+	r := NewCachedProductRepo(stub, client, time.Minute)
+	ctx := context.Background()
+
+	for range 2 {
+		p, err := r.Get(ctx, "1")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if p.GetPrice() != 10 {
+			t.Errorf("Expected price 10, got: %v", p.GetPrice())
+		}
+	}
+	if stub.gets != 1 {
+		t.Errorf("Expected 1 repo Get, got: %d", stub.gets)
+	}
+	if ttl := mr.TTL(cacheKey("1")); ttl != time.Minute {
+		t.Errorf("Expected TTL %v, got: %v", time.Minute, ttl)
+	}
+
+	if _, err := r.Update(ctx, &pb.Product{Id: "1", Price: 20}, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	p, err := r.Get(ctx, "1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if p.GetPrice() != 20 || stub.gets != 2 {
+		t.Errorf("Expected a reload with price 20, got: %v after %d gets", p.GetPrice(), stub.gets)
+	}
+}
+
+// TestCacheRepoReadMask tests that a masked Get caches the whole product
+// and masks cache hits.
+func TestCacheRepoReadMask(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	stub := &stubRepo{products: map[string]*pb.Product{"1": {Id: "1", Name: "Laptop", Price: 10}}}
+	r := NewCachedProductRepo(stub, client, time.Minute)
+	ctx, err := WithReadMask(context.Background(), []string{"price"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for range 2 {
+		p, err := r.Get(ctx, "1")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if p.GetId() != "1" || p.GetPrice() != 10 || p.GetName() != "" {
+			t.Errorf("Expected id and price only, got: %v", p)
+		}
+	}
+
+	p, err := r.Get(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if p.GetName() != "Laptop" || stub.gets != 1 {
+		t.Errorf("Expected the whole product from the cache, got: %v after %d gets", p, stub.gets)
+	}
+}
+
+// TestCacheRepoRedisDown tests that Get falls through to the repo when
+// Redis is unavailable.
+func TestCacheRepoRedisDown(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	defer client.Close()
+	mr.Close()
+
+	stub := &stubRepo{products: map[string]*pb.Product{"1": {Id: "1"}}}
+	r := NewCachedProductRepo(stub, client, time.Minute)
+
+	p, err := r.Get(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if p.GetId() != "1" {
+		t.Errorf("Unexpected product: %v", p)
+	}
+}
+
+func (s *stubRepo) DeleteWhere(ctx context.Context, filter ListFilter) ([]string, error) {
+	return []string{"1"}, nil
+}
+
+// TestCacheRepoDeleteWhere tests that a bulk delete drops every cached
+// product and nothing else.
+func TestCacheRepoDeleteWhere(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	stub := &stubRepo{products: map[string]*pb.Product{"1": {Id: "1"}, "2": {Id: "2"}}}
+	r := NewCachedProductRepo(stub, client, time.Minute)
+	ctx := context.Background()
+
+	for _, id := range []string{"1", "2"} {
+		if _, err := r.Get(ctx, id); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	mr.Set("other", "kept")
+
+	if _, err := r.DeleteWhere(ctx, ListFilter{Tags: []string{"discontinued"}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if keys := mr.Keys(); len(keys) != 1 || keys[0] != "other" {
+		t.Errorf("Expected only the unrelated key, got: %v", keys)
+	}
+}
@@ -0,0 +1,259 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/andro-kes/inventory_service/internal/inverr"
+	"github.com/andro-kes/inventory_service/internal/repo/builder"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Category groups products for storefront navigation. Categories form a
+// tree through ParentID; root categories have no parent.
+type Category struct {
+	ID        string    `db:"id"`
+	Name      string    `db:"name"`
+	ParentID  *string   `db:"parent_id"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// categoryColumns lists the categories columns in Category order.
+var categoryColumns = builder.StructColumns(Category{})
+
+func (c *Category) scan(row pgx.Row) error {
+	return row.Scan(&c.ID, &c.Name, &c.ParentID, &c.CreatedAt, &c.UpdatedAt)
+}
+
+type CategoryRepo interface {
+	Create(ctx context.Context, c *Category) (*Category, error)
+	Get(ctx context.Context, id string) (*Category, error)
+	Update(ctx context.Context, c *Category) (*Category, error)
+	Delete(ctx context.Context, id string) error
+	Children(ctx context.Context, parentID string) ([]*Category, error)
+	Descendants(ctx context.Context, id string) ([]*Category, error)
+	Ancestors(ctx context.Context, id string) ([]*Category, error)
+}
+
+type categoryRepo struct {
+	DB Querier
+}
+
+// NewCategoryRepo returns a CategoryRepo running its queries on db.
+func NewCategoryRepo(ctx context.Context, db Querier) CategoryRepo {
+	return &tracingCategoryRepo{
+		next: &categoryRepo{
+			DB: db,
+		},
+		tracer: newTracer("CategoryRepo"),
+	}
+}
+
+func (cr *categoryRepo) db(ctx context.Context) Querier {
+	return conn(ctx, cr.DB)
+}
+
+// categorySubtree selects the ids of the category and all its descendants.
+func categorySubtree(id string) *builder.SQLBuilder {
+	tree := builder.NewSQLBuilder().
+		Select("id").
+		From("categories").
+		Where("id = ?", id).
+		UnionAll(builder.NewSQLBuilder().
+			Select("c.id").
+			From("categories c").
+			Join("tree t", "c.parent_id = t.id"))
+
+	return builder.NewSQLBuilder().
+		WithRecursive("tree", tree).
+		Select("id").
+		From("tree")
+}
+
+func (cr *categoryRepo) Create(ctx context.Context, c *Category) (*Category, error) {
+	row := *c
+	row.CreatedAt = time.Now()
+	row.UpdatedAt = row.CreatedAt
+
+	sql, args := newQuery(ctx).
+		Insert("categories").
+		InsertStruct(row).
+		Returning(categoryColumns...).
+		Build()
+
+	var created Category
+	if err := created.scan(cr.db(ctx).QueryRow(ctx, sql, args...)); err != nil {
+		return nil, categoryError(err, c.ID)
+	}
+
+	return &created, nil
+}
+
+func (cr *categoryRepo) Get(ctx context.Context, id string) (*Category, error) {
+	sql, args := newQuery(ctx).
+		Select(categoryColumns...).
+		From("categories").
+		Where("id = ?", id).
+		Build()
+
+	var c Category
+	if err := c.scan(cr.db(ctx).QueryRow(ctx, sql, args...)); err != nil {
+		return nil, categoryError(err, id)
+	}
+
+	return &c, nil
+}
+
+// Update renames and moves a category. Moving a category below itself or
+// one of its descendants is rejected.
+func (cr *categoryRepo) Update(ctx context.Context, c *Category) (*Category, error) {
+	if c.ParentID != nil {
+		subtree, err := cr.Descendants(ctx, c.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range append(subtree, c) {
+			if d.ID == *c.ParentID {
+				return nil, inverr.InvalidField("parent_id", "category %s cannot be moved below itself", c.ID)
+			}
+		}
+	}
+
+	sql, args := newQuery(ctx).
+		Update("categories").
+		UpdateStruct(c, "name", "parent_id").
+		Set("updated_at = ?", time.Now()).
+		Where("id = ?", c.ID).
+		Returning(categoryColumns...).
+		Build()
+
+	var updated Category
+	if err := updated.scan(cr.db(ctx).QueryRow(ctx, sql, args...)); err != nil {
+		return nil, categoryError(err, c.ID)
+	}
+
+	return &updated, nil
+}
+
+// Delete removes a category. Categories with subcategories or products
+// cannot be deleted.
+func (cr *categoryRepo) Delete(ctx context.Context, id string) error {
+	sql, args := newQuery(ctx).
+		Delete().
+		From("categories").
+		Where("id = ?", id).
+		Build()
+
+	tag, err := cr.db(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return categoryError(err, id)
+	}
+	if tag.RowsAffected() == 0 {
+		return status.Errorf(codes.NotFound, "category not found: %s", id)
+	}
+
+	return nil
+}
+
+// Children returns the direct subcategories of parentID, or the root
+// categories when parentID is empty, ordered by name.
+func (cr *categoryRepo) Children(ctx context.Context, parentID string) ([]*Category, error) {
+	b := newQuery(ctx).
+		Select(categoryColumns...).
+		From("categories").
+		OrderBy("name")
+
+	if parentID == "" {
+		b.WhereNull("parent_id")
+	} else {
+		b.Where("parent_id = ?", parentID)
+	}
+
+	sql, args := b.Build()
+	return cr.query(ctx, sql, args)
+}
+
+// Descendants returns all categories below id, parents before children.
+func (cr *categoryRepo) Descendants(ctx context.Context, id string) ([]*Category, error) {
+	tree := builder.NewSQLBuilder().
+		Select(append(categoryColumns, "1 AS depth")...).
+		From("categories").
+		Where("parent_id = ?", id).
+		UnionAll(builder.NewSQLBuilder().
+			Select("c.id", "c.name", "c.parent_id", "c.created_at", "c.updated_at", "t.depth + 1").
+			From("categories c").
+			Join("tree t", "c.parent_id = t.id"))
+
+	sql, args := newQuery(ctx).
+		WithRecursive("tree", tree).
+		Select(categoryColumns...).
+		From("tree").
+		OrderBy("depth").
+		OrderBy("name").
+		Build()
+
+	return cr.query(ctx, sql, args)
+}
+
+// Ancestors returns the categories above id, from the root down to its
+// parent, e.g. for breadcrumbs.
+func (cr *categoryRepo) Ancestors(ctx context.Context, id string) ([]*Category, error) {
+	tree := builder.NewSQLBuilder().
+		Select(append(categoryColumns, "0 AS depth")...).
+		From("categories").
+		Where("id = (?)", builder.NewSQLBuilder().Select("parent_id").From("categories").Where("id = ?", id)).
+		UnionAll(builder.NewSQLBuilder().
+			Select("c.id", "c.name", "c.parent_id", "c.created_at", "c.updated_at", "t.depth + 1").
+			From("categories c").
+			Join("tree t", "c.id = t.parent_id"))
+
+	sql, args := newQuery(ctx).
+		WithRecursive("tree", tree).
+		Select(categoryColumns...).
+		From("tree").
+		OrderBy("depth", builder.Desc).
+		Build()
+
+	return cr.query(ctx, sql, args)
+}
+
+func (cr *categoryRepo) query(ctx context.Context, sql string, args []any) ([]*Category, error) {
+	rows, err := cr.db(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := make([]*Category, 0)
+	for rows.Next() {
+		var c Category
+		if err := c.scan(rows); err != nil {
+			return nil, err
+		}
+		categories = append(categories, &c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+// categoryError maps database errors of category id to status errors.
+func categoryError(err error, id string) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return status.Errorf(codes.NotFound, "category not found: %s", id)
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+		return inverr.WithReason(codes.FailedPrecondition, inverr.ReasonCategoryInUse,
+			map[string]string{"category_id": id},
+			"category %s: %s", id, pgErr.Message)
+	}
+	return err
+}
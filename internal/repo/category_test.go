@@ -0,0 +1,55 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v4"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newMockCategoryRepo(t *testing.T) (*categoryRepo, pgxmock.PgxPoolIface) {
+	t.Helper()
+
+	_, mock := newMockRepo(t)
+	return &categoryRepo{DB: mock}, mock
+}
+
+// TestCategoryUpdateCycle tests that a category cannot be moved below its
+// own descendant.
+func TestCategoryUpdateCycle(t *testing.T) {
+	cr, mock := newMockCategoryRepo(t)
+
+	parent := "child"
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("WITH RECURSIVE tree AS (" +
+		"SELECT id, name, parent_id, created_at, updated_at, 1 AS depth FROM categories WHERE parent_id = $1 " +
+		"UNION ALL SELECT c.id, c.name, c.parent_id, c.created_at, c.updated_at, t.depth + 1 " +
+		"FROM categories c JOIN tree t ON c.parent_id = t.id) " +
+		"SELECT id, name, parent_id, created_at, updated_at FROM tree ORDER BY depth, name").
+		WithArgs("root").
+		WillReturnRows(mock.NewRows(categoryColumns).AddRow("child", "Child", new(string), now, now))
+
+	_, err := cr.Update(context.Background(), &Category{ID: "root", Name: "Root", ParentID: &parent})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument, got: %v", err)
+	}
+}
+
+// TestCategoryDeleteInUse tests that deleting a referenced category fails
+// with FailedPrecondition.
+func TestCategoryDeleteInUse(t *testing.T) {
+	cr, mock := newMockCategoryRepo(t)
+
+	mock.ExpectExec("DELETE FROM categories WHERE id = $1").
+		WithArgs("c1").
+		WillReturnError(&pgconn.PgError{Code: "23503", Message: "violates foreign key constraint"})
+
+	err := cr.Delete(context.Background(), "c1")
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition, got: %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/andro-kes/inventory_service/internal/auth"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ChangesChannel is the channel product changes are announced on with
+// NOTIFY once their transaction commits.
+const ChangesChannel = "products_changed"
+
+// Operations of a Change.
+const (
+	OpCreate  = "create"
+	OpUpdate  = "update"
+	OpDelete  = "delete"
+	OpRestore = "restore"
+	OpUpsert  = "upsert"
+	// OpReset is sent by ChangeListener after it reconnects: changes made
+	// while it was disconnected are lost, so every cached product is stale.
+	OpReset = "reset"
+)
+
+// Change is the payload of a ChangesChannel notification.
+type Change struct {
+	ID string `json:"id,omitempty"`
+	Op string `json:"op"`
+}
+
+// SQL of notifyChange and notifyChanges. Both also set the actor and RPC
+// of the transaction, which the products_audit trigger records with the
+// changes of the products when the transaction commits.
+const (
+	auditSettings    = "set_config('inventory.actor', $3, true), set_config('inventory.rpc', $4, true)"
+	notifyChangeSQL  = "SELECT pg_notify($1, $2), " + auditSettings
+	notifyChangesSQL = "SELECT pg_notify($1, payload), " + auditSettings + " FROM unnest($2::text[]) AS payload"
+)
+
+// notifyChange queues a notification of op on product id. PostgreSQL
+// delivers it when the transaction of q commits and drops it on rollback.
+func notifyChange(ctx context.Context, q Querier, id, op string) error {
+	payload, err := json.Marshal(Change{ID: id, Op: op})
+	if err != nil {
+		return err
+	}
+	_, err = q.Exec(ctx, notifyChangeSQL, ChangesChannel, string(payload), auth.Principal(ctx), route(ctx))
+	return err
+}
+
+// notifyChanges queues a notification of op on each of ids in one
+// statement, like notifyChange.
+func notifyChanges(ctx context.Context, q Querier, ids []string, op string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	payloads := make([]string, len(ids))
+	for i, id := range ids {
+		payload, err := json.Marshal(Change{ID: id, Op: op})
+		if err != nil {
+			return err
+		}
+		payloads[i] = string(payload)
+	}
+	_, err := q.Exec(ctx, notifyChangesSQL, ChangesChannel, payloads, auth.Principal(ctx), route(ctx))
+	return err
+}
+
+// listenConn is the part of pgx.Conn ChangeListener uses.
+type listenConn interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	WaitForNotification(ctx context.Context) (*pgconn.Notification, error)
+	Close(ctx context.Context) error
+}
+
+// ChangeListener delivers the product changes of all service instances,
+// e.g. to invalidate caches. It holds a dedicated connection outside any
+// pool, since LISTEN is bound to the session.
+type ChangeListener struct {
+	Config *pgx.ConnConfig
+	Handle func(Change)
+	// Retry bounds the delay between reconnects; MaxAttempts is ignored.
+	Retry RetryPolicy
+
+	connect func(ctx context.Context) (listenConn, error)
+}
+
+// NewChangeListener returns a ChangeListener connecting with config and
+// calling handle for every change.
+func NewChangeListener(config *pgx.ConnConfig, handle func(Change)) *ChangeListener {
+	return &ChangeListener{
+		Config: config,
+		Handle: handle,
+		Retry:  DefaultRetryPolicy,
+	}
+}
+
+// Run listens until ctx is done and then returns ctx.Err(). Lost
+// connections are reopened with backoff, and Handle receives an OpReset
+// change after every reconnect. Notifications with malformed payloads are
+// skipped.
+func (cl *ChangeListener) Run(ctx context.Context) error {
+	for attempt := 1; ; attempt++ {
+		err := cl.listen(ctx, attempt > 1)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !errors.Is(err, errNotListening) {
+			attempt = 1
+		}
+
+		timer := time.NewTimer(cl.Retry.Delay(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// errNotListening marks listen failures before LISTEN succeeded; they
+// back off further, while a dropped session reconnects within BaseDelay.
+var errNotListening = errors.New("not listening")
+
+// listen runs one session. It returns errNotListening, wrapped, when
+// the session could not be set up.
+func (cl *ChangeListener) listen(ctx context.Context, reconnect bool) error {
+	connect := cl.connect
+	if connect == nil {
+		connect = func(ctx context.Context) (listenConn, error) {
+			return pgx.ConnectConfig(ctx, cl.Config)
+		}
+	}
+
+	conn, err := connect(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errNotListening, err)
+	}
+	defer func() {
+		_ = conn.Close(context.Background())
+	}()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{ChangesChannel}.Sanitize()); err != nil {
+		return fmt.Errorf("%w: %w", errNotListening, err)
+	}
+	if reconnect {
+		cl.Handle(Change{Op: OpReset})
+	}
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		var change Change
+		if err := json.Unmarshal([]byte(n.Payload), &change); err != nil || change.Op == "" {
+			continue
+		}
+		cl.Handle(change)
+	}
+}
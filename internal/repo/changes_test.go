@@ -0,0 +1,80 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakeListenConn replays payloads and then fails like a dropped session.
+type fakeListenConn struct {
+	payloads []string
+	listened []string
+}
+
+func (c *fakeListenConn) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	c.listened = append(c.listened, sql)
+	return pgconn.NewCommandTag("LISTEN"), nil
+}
+
+func (c *fakeListenConn) WaitForNotification(ctx context.Context) (*pgconn.Notification, error) {
+	if len(c.payloads) == 0 {
+		return nil, errors.New("connection lost")
+	}
+	payload := c.payloads[0]
+	c.payloads = c.payloads[1:]
+	return &pgconn.Notification{Channel: ChangesChannel, Payload: payload}, nil
+}
+
+func (c *fakeListenConn) Close(ctx context.Context) error {
+	return nil
+}
+
+// TestChangeListenerReconnect tests that changes are delivered across a
+// reconnect, with a reset in between, and malformed payloads are skipped.
+func TestChangeListenerReconnect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conns := []*fakeListenConn{
+		{payloads: []string{`{"id":"1","op":"update"}`, `not json`}},
+		{payloads: []string{`{"id":"2","op":"delete"}`}},
+	}
+	dials := 0
+
+	var changes []Change
+	cl := NewChangeListener(nil, func(c Change) {
+		changes = append(changes, c)
+		if len(changes) == 3 {
+			cancel()
+		}
+	})
+	cl.Retry = RetryPolicy{BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	cl.connect = func(ctx context.Context) (listenConn, error) {
+		if dials == 1 {
+			dials++
+			return nil, errors.New("connection refused")
+		}
+		conn := conns[0]
+		conns = conns[1:]
+		dials++
+		return conn, nil
+	}
+
+	if err := cl.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got: %v", err)
+	}
+
+	expected := []Change{
+		{ID: "1", Op: OpUpdate},
+		{Op: OpReset},
+		{ID: "2", Op: OpDelete},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected changes: %v, got: %v", expected, changes)
+	}
+}
@@ -0,0 +1,130 @@
+package repo
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andro-kes/inventory_service/internal/repo/builder"
+	pb "github.com/andro-kes/inventory_service/proto"
+)
+
+// ErrInvalidCursor is returned by ListAfter for a cursor it did not issue,
+// or issued for another order or filter.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ErrInvalidOrderBy is returned by ListAfter for an order it cannot page
+// through.
+var ErrInvalidOrderBy = errors.New("invalid order_by")
+
+// pageOrder is the sort of a ListAfter listing: column, then id, both in
+// direction dir.
+type pageOrder struct {
+	column string
+	dir    builder.Direction
+}
+
+// defaultPageOrder lists the newest products first.
+var defaultPageOrder = pageOrder{column: "created_at", dir: builder.Desc}
+
+// parsePageOrder parses an orderBy of "<field> [asc|desc]" with a field
+// of listSortColumns; "" means defaultPageOrder.
+func parsePageOrder(orderBy string) (pageOrder, error) {
+	fields := strings.Fields(orderBy)
+	if len(fields) == 0 {
+		return defaultPageOrder, nil
+	}
+	column, ok := listSortColumns[fields[0]]
+	if len(fields) > 2 || !ok {
+		return pageOrder{}, ErrInvalidOrderBy
+	}
+	dir, err := builder.ParseDirection(strings.Join(fields[1:], ""))
+	if err != nil {
+		return pageOrder{}, ErrInvalidOrderBy
+	}
+	return pageOrder{column: column, dir: dir}, nil
+}
+
+func (o pageOrder) String() string {
+	return o.column + " " + string(o.dir)
+}
+
+// after adds the condition selecting the rows that follow the row with
+// the given column value and id.
+func (o pageOrder) after(b *builder.SQLBuilder, value any, id string) {
+	op := ">"
+	if o.dir == builder.Desc {
+		op = "<"
+	}
+	b.WhereTuple([]string{o.column, "id"}, op, []any{value, id})
+}
+
+// value returns the sort column value of p.
+func (o pageOrder) value(p *pb.Product) any {
+	if o.column == "price_cents" {
+		cents, _ := priceOf(p)
+		return cents
+	}
+	return p.GetCreatedAt().AsTime()
+}
+
+// formatValue and parseValue convert a sort column value for a cursor.
+func (o pageOrder) formatValue(v any) string {
+	switch v := v.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	default:
+		return ""
+	}
+}
+
+func (o pageOrder) parseValue(s string) (any, error) {
+	if o.column == "price_cents" {
+		return strconv.ParseInt(s, 10, 64)
+	}
+	return time.Parse(time.RFC3339Nano, s)
+}
+
+// fingerprint identifies filter, so a cursor is only accepted for the
+// listing it was issued for.
+func fingerprint(filter ListFilter) string {
+	available := "nil"
+	if filter.Available != nil {
+		available = strconv.FormatBool(*filter.Available)
+	}
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%v|%v|%q|%q|%s|%v|%q|%q|%q|%q", filter.MinPrice, filter.MaxPrice, filter.NameQuery,
+		filter.Tags, available, filter.Statuses, filter.CategoryID, filter.SKU, filter.Barcode, filter.Attributes)
+	return strconv.FormatUint(h.Sum64(), 36)
+}
+
+// encodeCursor returns an opaque cursor pointing after the product with
+// the given sort value and id in the listing of order and filter.
+func encodeCursor(order pageOrder, filter ListFilter, value any, id string) string {
+	raw := strings.Join([]string{order.String(), fingerprint(filter), order.formatValue(value), id}, "|")
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCursor reverses encodeCursor, checking that cursor was issued for
+// order and filter.
+func decodeCursor(cursor string, order pageOrder, filter ListFilter) (any, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, "", ErrInvalidCursor
+	}
+	parts := strings.Split(string(raw), "|")
+	if len(parts) != 4 || parts[0] != order.String() || parts[1] != fingerprint(filter) || parts[3] == "" {
+		return nil, "", ErrInvalidCursor
+	}
+	value, err := order.parseValue(parts[2])
+	if err != nil {
+		return nil, "", ErrInvalidCursor
+	}
+	return value, parts[3], nil
+}
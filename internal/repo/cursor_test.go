@@ -0,0 +1,69 @@
+package repo
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestCursorRoundTrip tests that a cursor decodes to the values it was built from.
+func TestCursorRoundTrip(t *testing.T) {
+	createdAt := time.Date(2025, 3, 1, 12, 30, 0, 123456789, time.UTC)
+	cursor := encodeCursor(defaultPageOrder, ListFilter{}, createdAt, "8c3b2a1e-0000-4000-8000-000000000001")
+
+	gotTime, gotID, err := decodeCursor(cursor, defaultPageOrder, ListFilter{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !gotTime.(time.Time).Equal(createdAt) || gotID != "8c3b2a1e-0000-4000-8000-000000000001" {
+		t.Errorf("Expected (%v, %s), got: (%v, %s)", createdAt, "8c3b2a1e-0000-4000-8000-000000000001", gotTime, gotID)
+	}
+
+	byPrice := pageOrder{column: "price_cents", dir: "ASC"}
+	price, _, err := decodeCursor(encodeCursor(byPrice, ListFilter{}, int64(1999), "1"), byPrice, ListFilter{})
+	if err != nil || price != int64(1999) {
+		t.Errorf("Expected price 1999, got: %v, %v", price, err)
+	}
+}
+
+// TestDecodeCursorInvalid tests that malformed cursors and cursors of
+// another listing are rejected.
+func TestDecodeCursorInvalid(t *testing.T) {
+	available := false
+	for _, cursor := range []string{
+		"!!!",
+		"bm9waXBl",
+		encodeCursor(defaultPageOrder, ListFilter{}, time.Now(), ""),
+		encodeCursor(pageOrder{column: "price_cents", dir: "DESC"}, ListFilter{}, int64(100), "1"),
+		encodeCursor(defaultPageOrder, ListFilter{Tags: []string{"sale"}}, time.Now(), "1"),
+		encodeCursor(defaultPageOrder, ListFilter{Available: &available}, time.Now(), "1"),
+	} {
+		if _, _, err := decodeCursor(cursor, defaultPageOrder, ListFilter{}); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("decodeCursor(%q): expected ErrInvalidCursor, got: %v", cursor, err)
+		}
+	}
+}
+
+// TestParsePageOrder tests the accepted orders.
+func TestParsePageOrder(t *testing.T) {
+	for orderBy, want := range map[string]string{
+		"":                "created_at DESC",
+		"price":           "price_cents ASC",
+		" price  desc ":   "price_cents DESC",
+		"created_at asc":  "created_at ASC",
+		"name":            "",
+		"price sideways":  "",
+		"price asc extra": "",
+	} {
+		order, err := parsePageOrder(orderBy)
+		if want == "" {
+			if !errors.Is(err, ErrInvalidOrderBy) {
+				t.Errorf("parsePageOrder(%q): expected ErrInvalidOrderBy, got: %v", orderBy, err)
+			}
+			continue
+		}
+		if err != nil || order.String() != want {
+			t.Errorf("parsePageOrder(%q) = %v, %v, want %s", orderBy, order, err, want)
+		}
+	}
+}
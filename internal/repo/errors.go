@@ -0,0 +1,32 @@
+package repo
+
+import (
+	"errors"
+
+	"github.com/andro-kes/inventory_service/internal/inverr"
+	"github.com/jackc/pgx/v5/pgconn"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TransientError converts err, if it is a transient database error left
+// after the retries of RetryPolicy, to a status error with a RetryInfo
+// detail: ResourceExhausted when the database refuses connections,
+// Unavailable otherwise. Other errors are returned as is.
+func TransientError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	retryDelay := DefaultRetryPolicy.MaxDelay
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "53300" {
+		return inverr.Throttled(codes.ResourceExhausted, inverr.ReasonDatabaseOverloaded, retryDelay,
+			"database is overloaded, retry later")
+	}
+	if _, ok := retryReason(err); ok {
+		return inverr.Throttled(codes.Unavailable, inverr.ReasonDatabaseUnavailable, retryDelay,
+			"database is temporarily unavailable, retry later")
+	}
+	return err
+}
@@ -0,0 +1,84 @@
+package repo
+
+import (
+	"github.com/andro-kes/inventory_service/internal/repo/builder"
+	pb "github.com/andro-kes/inventory_service/proto"
+)
+
+// ListFilter narrows the products returned by List, ListAfter and Count,
+// or deleted by DeleteWhere. Zero-valued fields do not filter.
+type ListFilter struct {
+	MinPrice  float64  // Lowest price, inclusive
+	MaxPrice  float64  // Highest price, inclusive
+	NameQuery string   // Case-insensitive substring of the name
+	Tags      []string // Tags a product must all have
+	Available *bool    // Whether ACTIVE; nil lists both
+	// Statuses lists products in any of these statuses.
+	Statuses []pb.Product_Status
+	// CategoryID lists products of the category and its subcategories.
+	CategoryID string
+	SKU        string // Exact SKU
+	Barcode    string // Exact barcode
+	// Attributes lists attributes a product must all have, with these
+	// values.
+	Attributes map[string]string
+}
+
+// apply adds the filter conditions to b. Only products in stock are listed.
+func (f ListFilter) apply(b *builder.SQLBuilder) {
+	b.Where("quantity > ?", 0)
+	f.where(b)
+}
+
+// isZero reports whether no field filters.
+func (f ListFilter) isZero() bool {
+	return f.MinPrice <= 0 && f.MaxPrice <= 0 && f.NameQuery == "" &&
+		len(f.Tags) == 0 && f.Available == nil && len(f.Statuses) == 0 && f.CategoryID == "" &&
+		f.SKU == "" && f.Barcode == "" && len(f.Attributes) == 0
+}
+
+// where adds the conditions of the filtering fields to b.
+func (f ListFilter) where(b *builder.SQLBuilder) {
+	if f.Available != nil {
+		op := "<>"
+		if *f.Available {
+			op = "="
+		}
+		b.Where("status "+op+" ?", statusColumn(pb.Product_ACTIVE))
+	}
+	if len(f.Statuses) > 0 {
+		statuses := make([]string, len(f.Statuses))
+		for i, s := range f.Statuses {
+			statuses[i] = statusColumn(s)
+		}
+		b.Where("status = ANY(?)", statuses)
+	}
+	if f.MinPrice > 0 {
+		b.Where("price_cents >= ?", toCents(f.MinPrice))
+	}
+	if f.MaxPrice > 0 {
+		b.Where("price_cents <= ?", toCents(f.MaxPrice))
+	}
+	if f.NameQuery != "" {
+		b.WhereILike("name", f.NameQuery)
+	}
+	if len(f.Tags) > 0 {
+		tags := make([]any, len(f.Tags))
+		for i, tag := range f.Tags {
+			tags[i] = tag
+		}
+		b.WhereArrayContains("tags", tags...)
+	}
+	if f.CategoryID != "" {
+		b.Where("category_id IN (?)", categorySubtree(f.CategoryID))
+	}
+	if f.SKU != "" {
+		b.Where("sku = ?", f.SKU)
+	}
+	if f.Barcode != "" {
+		b.Where("barcode = ?", f.Barcode)
+	}
+	if len(f.Attributes) > 0 {
+		b.WhereJSONContains("attributes", f.Attributes)
+	}
+}
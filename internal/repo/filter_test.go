@@ -0,0 +1,86 @@
+package repo
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/andro-kes/inventory_service/internal/repo/builder"
+	pb "github.com/andro-kes/inventory_service/proto"
+)
+
+// TestListFilterApply tests the conditions generated for a list filter.
+func TestListFilterApply(t *testing.T) {
+	available, unavailable := true, false
+	cases := []struct {
+		name     string
+		filter   ListFilter
+		expected string
+		args     []any
+	}{
+		{
+			name:     "empty",
+			expected: "SELECT id FROM products WHERE quantity > $1",
+			args:     []any{0},
+		},
+		{
+			name: "all",
+			filter: ListFilter{
+				MinPrice:  10,
+				MaxPrice:  100,
+				NameQuery: "lap",
+				Tags:      []string{"sale", "new"},
+				Available: &available,
+			},
+			expected: "SELECT id FROM products WHERE quantity > $1 AND status = $2 AND price_cents >= $3 AND price_cents <= $4 " +
+				"AND name ILIKE $5 AND tags @> ARRAY[$6, $7]::text[]",
+			args: []any{0, "active", int64(1000), int64(10000), "%lap%", "sale", "new"},
+		},
+		{
+			name:   "category",
+			filter: ListFilter{CategoryID: "c1"},
+			expected: "SELECT id FROM products WHERE quantity > $1 AND category_id IN (" +
+				"WITH RECURSIVE tree AS (SELECT id FROM categories WHERE id = $2 " +
+				"UNION ALL SELECT c.id FROM categories c JOIN tree t ON c.parent_id = t.id) SELECT id FROM tree)",
+			args: []any{0, "c1"},
+		},
+		{
+			name:     "keys",
+			filter:   ListFilter{SKU: "SKU-1", Barcode: "96385074"},
+			expected: "SELECT id FROM products WHERE quantity > $1 AND sku = $2 AND barcode = $3",
+			args:     []any{0, "SKU-1", "96385074"},
+		},
+		{
+			name:     "statuses",
+			filter:   ListFilter{Statuses: []pb.Product_Status{pb.Product_DRAFT, pb.Product_ARCHIVED}},
+			expected: "SELECT id FROM products WHERE quantity > $1 AND status = ANY($2)",
+			args:     []any{0, []string{"draft", "archived"}},
+		},
+		{
+			name:     "attributes",
+			filter:   ListFilter{Attributes: map[string]string{"color": "red"}},
+			expected: "SELECT id FROM products WHERE quantity > $1 AND attributes @> $2::jsonb",
+			args:     []any{0, `{"color":"red"}`},
+		},
+		{
+			name:     "unavailable",
+			filter:   ListFilter{Available: &unavailable},
+			expected: "SELECT id FROM products WHERE quantity > $1 AND status <> $2",
+			args:     []any{0, "active"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := builder.NewSQLBuilder().Select("id").From("products")
+			tc.filter.apply(b)
+			query, args := b.Build()
+
+			if query != tc.expected {
+				t.Errorf("Expected query: %s, got: %s", tc.expected, query)
+			}
+			if !reflect.DeepEqual(args, tc.args) {
+				t.Errorf("Expected args: %v, got: %v", tc.args, args)
+			}
+		})
+	}
+}
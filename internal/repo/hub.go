@@ -0,0 +1,97 @@
+package repo
+
+import "sync"
+
+// ChangeHub fans the changes of a ChangeListener out to subscribers, e.g.
+// WatchProducts streams, so they share one LISTEN connection. Use Publish
+// as the listener's Handle.
+type ChangeHub struct {
+	mu     sync.Mutex
+	subs   map[*subscription]struct{}
+	closed bool
+}
+
+// subscription is the buffered feed of one subscriber. lost is set when
+// a change did not fit in the buffer.
+type subscription struct {
+	ch     chan Change
+	lost   bool
+	closed bool
+}
+
+func NewChangeHub() *ChangeHub {
+	return &ChangeHub{subs: make(map[*subscription]struct{})}
+}
+
+// Subscribe returns a channel receiving the changes published from now on,
+// and a function ending the subscription and closing the channel. Up to
+// buffer changes are queued; a subscriber falling further behind misses
+// changes and then receives an OpReset change, as after a reconnect of
+// the listener. The channel is also closed by Close.
+func (h *ChangeHub) Subscribe(buffer int) (<-chan Change, func()) {
+	s := &subscription{ch: make(chan Change, max(buffer, 1))}
+
+	h.mu.Lock()
+	if h.closed {
+		s.close()
+	} else {
+		h.subs[s] = struct{}{}
+	}
+	h.mu.Unlock()
+
+	return s.ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.subs, s)
+		s.close()
+	}
+}
+
+// Close ends all subscriptions, present and future, e.g. so streams of
+// subscribers end before a graceful stop.
+func (h *ChangeHub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for s := range h.subs {
+		s.close()
+	}
+	clear(h.subs)
+}
+
+// Publish delivers c to every subscriber without blocking.
+func (h *ChangeHub) Publish(c Change) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for s := range h.subs {
+		if s.lost {
+			if !s.offer(Change{Op: OpReset}) {
+				continue
+			}
+			s.lost = false
+		}
+		if !s.offer(c) {
+			s.lost = true
+		}
+	}
+}
+
+// close closes the channel of s once; the hub lock must be held.
+func (s *subscription) close() {
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+	}
+}
+
+// offer queues c unless the buffer is full.
+func (s *subscription) offer(c Change) bool {
+	select {
+	case s.ch <- c:
+		return true
+	default:
+		return false
+	}
+}
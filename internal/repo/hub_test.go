@@ -0,0 +1,75 @@
+package repo
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestChangeHub tests that every subscriber receives the published
+// changes until it unsubscribes.
+func TestChangeHub(t *testing.T) {
+	h := NewChangeHub()
+	first, cancelFirst := h.Subscribe(4)
+	second, cancelSecond := h.Subscribe(4)
+	defer cancelSecond()
+
+	h.Publish(Change{ID: "1", Op: OpCreate})
+	cancelFirst()
+	h.Publish(Change{ID: "2", Op: OpDelete})
+
+	if got := drain(first); !reflect.DeepEqual(got, []Change{{ID: "1", Op: OpCreate}}) {
+		t.Errorf("Unexpected changes of first subscriber: %v", got)
+	}
+	cancelSecond()
+	expected := []Change{{ID: "1", Op: OpCreate}, {ID: "2", Op: OpDelete}}
+	if got := drain(second); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Unexpected changes of second subscriber: %v", got)
+	}
+}
+
+// TestChangeHubOverflow tests that a subscriber falling behind gets a
+// reset instead of the changes it missed.
+func TestChangeHubOverflow(t *testing.T) {
+	h := NewChangeHub()
+	ch, cancel := h.Subscribe(2)
+
+	for _, id := range []string{"1", "2", "3"} {
+		h.Publish(Change{ID: id, Op: OpUpdate})
+	}
+	for _, id := range []string{"1", "2"} {
+		if c := <-ch; c.ID != id {
+			t.Fatalf("Expected change of %s, got: %v", id, c)
+		}
+	}
+	h.Publish(Change{ID: "4", Op: OpUpdate})
+	cancel()
+
+	expected := []Change{{Op: OpReset}, {ID: "4", Op: OpUpdate}}
+	if got := drain(ch); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Unexpected changes after overflow: %v", got)
+	}
+}
+
+// TestChangeHubClose tests that Close ends present and later
+// subscriptions.
+func TestChangeHubClose(t *testing.T) {
+	h := NewChangeHub()
+	before, cancel := h.Subscribe(1)
+	h.Close()
+	cancel()
+	after, _ := h.Subscribe(1)
+	h.Publish(Change{ID: "1", Op: OpUpdate})
+
+	if got := append(drain(before), drain(after)...); len(got) != 0 {
+		t.Errorf("Expected no changes after close, got: %v", got)
+	}
+}
+
+// drain returns the changes left in the closed channel ch.
+func drain(ch <-chan Change) []Change {
+	var changes []Change
+	for c := range ch {
+		changes = append(changes, c)
+	}
+	return changes
+}
@@ -0,0 +1,129 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/andro-kes/inventory_service/internal/inverr"
+	pb "github.com/andro-kes/inventory_service/proto"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxIdempotencyKeyLen bounds the length of an idempotency key.
+const maxIdempotencyKeyLen = 255
+
+// CreateIdempotent creates p like Create and records key with the new
+// product's id for ttl. A retry with the same key within ttl returns the
+// product created by the first call instead of a duplicate, also when
+// both race: the loser's insert is rolled back. An expired key is taken
+// anew. An empty key is a plain Create.
+func (pr *productRepo) CreateIdempotent(ctx context.Context, key string, ttl time.Duration, p *pb.Product) (*pb.Product, error) {
+	if key == "" {
+		return pr.Create(ctx, p)
+	}
+	if len(key) > maxIdempotencyKeyLen {
+		return nil, inverr.InvalidField("idempotency_key", "idempotency key longer than %d bytes", maxIdempotencyKeyLen)
+	}
+
+	if created, err := pr.idempotentProduct(ctx, key); !errors.Is(err, pgx.ErrNoRows) {
+		return created, err
+	}
+
+	tx, err := pr.db(ctx).Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	created, err := pr.Create(context.WithValue(ctx, txKey{}, tx), p)
+	if err != nil {
+		return nil, err
+	}
+
+	// An expired key is released first, by the first of the retries
+	// racing for it; the others then find the key taken again.
+	now := time.Now()
+	sql, args := newQuery(ctx).
+		Delete().
+		From("idempotency_keys").
+		Where("key = ?", key).
+		Where("expires_at <= ?", now).
+		Build()
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return nil, err
+	}
+
+	// A concurrent call holding key blocks the insert until it finishes.
+	sql, args = newQuery(ctx).
+		Insert("idempotency_keys").
+		Columns("key", "product_id", "expires_at").
+		Values(key, created.GetId(), now.Add(ttl)).
+		OnConflict("key").
+		DoNothing().
+		Build()
+
+	tag, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		_ = tx.Rollback(ctx)
+		return pr.idempotentProduct(ctx, key)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// idempotentProduct returns the product recorded for key, or
+// pgx.ErrNoRows if there is none or it expired.
+func (pr *productRepo) idempotentProduct(ctx context.Context, key string) (*pb.Product, error) {
+	sql, args := newQuery(ctx).
+		Select("product_id").
+		From("idempotency_keys").
+		Where("key = ?", key).
+		Where("expires_at > ?", time.Now()).
+		Build()
+
+	var id string
+	if err := pr.db(ctx).QueryRow(ctx, sql, args...).Scan(&id); err != nil {
+		return nil, err
+	}
+
+	p, err := pr.Get(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, status.Errorf(codes.NotFound, "product %s created with this idempotency key was deleted", id)
+	}
+	return p, err
+}
+
+// PurgeIdempotencyKeys deletes up to limit expired idempotency keys and
+// returns how many it deleted. Keys locked by creates taking them anew
+// are skipped.
+func (pr *productRepo) PurgeIdempotencyKeys(ctx context.Context, limit int) (int64, error) {
+	expired := newQuery(ctx).
+		Select("key").
+		From("idempotency_keys").
+		Where("expires_at <= ?", time.Now()).
+		Limit(limit).
+		ForUpdate().
+		SkipLocked()
+	sql, args := newQuery(ctx).
+		Delete().
+		From("idempotency_keys").
+		Where("key IN (?)", expired).
+		Build()
+
+	tag, err := pr.db(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
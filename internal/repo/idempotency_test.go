@@ -0,0 +1,100 @@
+package repo
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/andro-kes/inventory_service/proto"
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+const selectIdempotencyKey = "SELECT product_id FROM idempotency_keys WHERE key = $1 AND expires_at > $2"
+
+// TestRepoCreateIdempotent tests that a retried key returns the product
+// created first.
+func TestRepoCreateIdempotent(t *testing.T) {
+	pr, mock := newMockRepo(t)
+
+	mock.ExpectQuery(selectIdempotencyKey).
+		WithArgs("req-1", pgxmock.AnyArg()).
+		WillReturnRows(mock.NewRows([]string{"product_id"}).AddRow("1"))
+	mock.ExpectQuery(selectProduct + " WHERE id = $1 AND deleted_at IS NULL").
+		WithArgs("1").
+		WillReturnRows(productRows(mock, "1"))
+
+	p, err := pr.CreateIdempotent(context.Background(), "req-1", time.Hour, &pb.Product{Id: "2", Name: "name 1"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if p.GetId() != "1" {
+		t.Errorf("Expected the product created first, got: %v", p)
+	}
+}
+
+// TestRepoCreateIdempotentRace tests that the loser of a race for a key
+// rolls back its product and returns the winner's.
+func TestRepoCreateIdempotentRace(t *testing.T) {
+	pr, mock := newMockRepo(t)
+
+	cols := strings.Join(productColumns, ", ")
+	args := make([]any, len(productColumns))
+	for i := range args {
+		args[i] = pgxmock.AnyArg()
+	}
+
+	mock.ExpectQuery(selectIdempotencyKey).
+		WithArgs("req-1", pgxmock.AnyArg()).
+		WillReturnRows(mock.NewRows([]string{"product_id"}))
+	mock.ExpectBegin()
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO products (" + cols + ") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18) " +
+		"RETURNING " + cols).
+		WithArgs(args...).
+		WillReturnRows(productRows(mock, "2"))
+	mock.ExpectExec(notifyChangeSQL).
+		WithArgs(ChangesChannel, `{"id":"2","op":"create"}`, "", "").
+		WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectCommit()
+	mock.ExpectExec("DELETE FROM idempotency_keys WHERE key = $1 AND expires_at <= $2").
+		WithArgs("req-1", pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("DELETE", 0))
+	mock.ExpectExec("INSERT INTO idempotency_keys (key, product_id, expires_at) VALUES ($1, $2, $3) ON CONFLICT (key) DO NOTHING").
+		WithArgs("req-1", "2", pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 0))
+	mock.ExpectRollback()
+	mock.ExpectQuery(selectIdempotencyKey).
+		WithArgs("req-1", pgxmock.AnyArg()).
+		WillReturnRows(mock.NewRows([]string{"product_id"}).AddRow("1"))
+	mock.ExpectQuery(selectProduct + " WHERE id = $1 AND deleted_at IS NULL").
+		WithArgs("1").
+		WillReturnRows(productRows(mock, "1"))
+
+	p, err := pr.CreateIdempotent(context.Background(), "req-1", time.Hour, &pb.Product{Id: "2", Name: "name 2"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if p.GetId() != "1" {
+		t.Errorf("Expected the winner's product, got: %v", p)
+	}
+}
+
+// TestRepoPurgeIdempotencyKeys tests that expired keys are deleted in
+// batches, skipping the locked ones.
+func TestRepoPurgeIdempotencyKeys(t *testing.T) {
+	pr, mock := newMockRepo(t)
+
+	mock.ExpectExec("DELETE FROM idempotency_keys WHERE key IN " +
+		"(SELECT key FROM idempotency_keys WHERE expires_at <= $1 LIMIT 100 FOR UPDATE SKIP LOCKED)").
+		WithArgs(pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("DELETE", 3))
+
+	n, err := pr.PurgeIdempotencyKeys(context.Background(), 100)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("Expected 3 keys purged, got: %d", n)
+	}
+}
@@ -0,0 +1,343 @@
+package repo_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andro-kes/inventory_service/internal/repo"
+	"github.com/andro-kes/inventory_service/internal/testutil"
+	pb "github.com/andro-kes/inventory_service/proto"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// TestIntegrationCreate tests that a created product reads back with its
+// tags and timestamps intact.
+func TestIntegrationCreate(t *testing.T) {
+	db := testutil.NewDB(t)
+	ctx := context.Background()
+	pr := repo.NewProductRepo(ctx, db)
+
+	p := testutil.NewProduct("Laptop")
+	p.Description = "15 inch"
+	p.Tags = []string{"electronics", "sale"}
+	p.Sku = "LAP-1"
+
+	before := time.Now().Add(-time.Second)
+	created, err := pr.Create(ctx, p)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	got, err := pr.Get(ctx, created.GetId())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got.GetName() != "Laptop" || got.GetDescription() != "15 inch" || got.GetSku() != "LAP-1" {
+		t.Errorf("Unexpected product: %v", got)
+	}
+	if !slices.Equal(got.GetTags(), []string{"electronics", "sale"}) {
+		t.Errorf("Unexpected tags: %v", got.GetTags())
+	}
+	if createdAt := got.GetCreatedAt().AsTime(); createdAt.Before(before) || !createdAt.Equal(got.GetUpdatedAt().AsTime()) {
+		t.Errorf("Unexpected timestamps: %v, %v", got.GetCreatedAt(), got.GetUpdatedAt())
+	}
+	if !created.GetCreatedAt().AsTime().Equal(got.GetCreatedAt().AsTime()) {
+		t.Errorf("Expected the returned created_at %v, got: %v", created.GetCreatedAt(), got.GetCreatedAt())
+	}
+}
+
+// TestIntegrationCreateIdempotent tests that concurrent creates with one
+// idempotency key store a single product.
+func TestIntegrationCreateIdempotent(t *testing.T) {
+	db := testutil.NewDB(t)
+	ctx := context.Background()
+	pr := repo.NewProductRepo(ctx, db)
+
+	ids := make(chan string, 4)
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p, err := pr.CreateIdempotent(ctx, "req-1", time.Hour, testutil.NewProduct("Laptop"))
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+			ids <- p.GetId()
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	first := <-ids
+	for id := range ids {
+		if id != first {
+			t.Errorf("Expected product %s, got: %s", first, id)
+		}
+	}
+	total, err := pr.Count(ctx, repo.ListFilter{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if total != 1 {
+		t.Errorf("Expected 1 product, got: %d", total)
+	}
+}
+
+// TestIntegrationList tests filtering by tags and sorting by price.
+func TestIntegrationList(t *testing.T) {
+	db := testutil.NewDB(t)
+	ctx := context.Background()
+	pr := repo.NewProductRepo(ctx, db)
+
+	cheap := testutil.NewProduct("Mouse")
+	cheap.Price = 20
+	cheap.Tags = []string{"electronics"}
+	pricey := testutil.NewProduct("Laptop")
+	pricey.Price = 999
+	pricey.Tags = []string{"electronics", "sale"}
+	other := testutil.NewProduct("Chair")
+	other.Tags = []string{"furniture"}
+	outOfStock := testutil.NewProduct("Keyboard")
+	outOfStock.Quantity = 0
+	outOfStock.Tags = []string{"electronics"}
+	testutil.SeedProducts(t, db, cheap, pricey, other, outOfStock)
+
+	products, err := pr.List(ctx, 0, 10, repo.ListFilter{Tags: []string{"electronics"}}, "price desc")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(products) != 2 || products[0].GetId() != pricey.GetId() || products[1].GetId() != cheap.GetId() {
+		t.Errorf("Expected Laptop and Mouse, got: %v", products)
+	}
+
+	products, err = pr.List(ctx, 0, 10, repo.ListFilter{Tags: []string{"electronics", "sale"}}, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(products) != 1 || products[0].GetId() != pricey.GetId() {
+		t.Errorf("Expected Laptop, got: %v", products)
+	}
+}
+
+// TestIntegrationListAfter tests that paging by price with ties visits
+// every product once.
+func TestIntegrationListAfter(t *testing.T) {
+	db := testutil.NewDB(t)
+	ctx := context.Background()
+	pr := repo.NewProductRepo(ctx, db)
+
+	var want []string
+	for i, price := range []float64{30, 10, 20, 10, 30} {
+		p := testutil.NewProduct("Product " + string(rune('A'+i)))
+		p.Price = price
+		testutil.SeedProducts(t, db, p)
+		want = append(want, p.GetId())
+	}
+
+	var got []string
+	cursor := ""
+	for page := 0; page < 5; page++ {
+		products, next, err := pr.ListAfter(ctx, cursor, 2, repo.ListFilter{}, "price desc")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for i, p := range products {
+			if i > 0 && p.GetPrice() > products[i-1].GetPrice() {
+				t.Errorf("Expected descending prices, got: %v", products)
+			}
+			got = append(got, p.GetId())
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	slices.Sort(got)
+	slices.Sort(want)
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected every product once, got: %v", got)
+	}
+}
+
+// TestIntegrationUpdate tests that Update writes the masked fields only
+// and moves updated_at.
+func TestIntegrationUpdate(t *testing.T) {
+	db := testutil.NewDB(t)
+	ctx := context.Background()
+	pr := repo.NewProductRepo(ctx, db)
+
+	p := testutil.NewProduct("Laptop")
+	p.Tags = []string{"electronics"}
+	p.CreatedAt = timestamppb.New(time.Now().Add(-time.Hour).Truncate(time.Microsecond))
+	p.UpdatedAt = p.CreatedAt
+	testutil.SeedProducts(t, db, p)
+
+	mask := &fieldmaskpb.FieldMask{Paths: []string{"price", "tags"}}
+	updated, err := pr.Update(ctx, &pb.Product{Id: p.GetId(), Name: "ignored", Price: 50, Tags: []string{"sale"}}, mask)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if updated.GetName() != "Laptop" || updated.GetPrice() != 50 || !slices.Equal(updated.GetTags(), []string{"sale"}) {
+		t.Errorf("Unexpected product: %v", updated)
+	}
+	if !updated.GetCreatedAt().AsTime().Equal(p.GetCreatedAt().AsTime()) {
+		t.Errorf("Expected created_at %v, got: %v", p.GetCreatedAt(), updated.GetCreatedAt())
+	}
+	if !updated.GetUpdatedAt().AsTime().After(p.GetUpdatedAt().AsTime()) {
+		t.Errorf("Expected updated_at after %v, got: %v", p.GetUpdatedAt(), updated.GetUpdatedAt())
+	}
+}
+
+// TestIntegrationDelete tests that a deleted product is hidden from Get
+// and List until restored.
+func TestIntegrationDelete(t *testing.T) {
+	db := testutil.NewDB(t)
+	ctx := context.Background()
+	pr := repo.NewProductRepo(ctx, db)
+
+	p := testutil.SeedProducts(t, db, testutil.NewProduct("Laptop"))[0]
+
+	if err := pr.Delete(ctx, p.GetId()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := pr.Get(ctx, p.GetId()); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("Expected no rows, got: %v", err)
+	}
+	products, err := pr.List(ctx, 0, 10, repo.ListFilter{}, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(products) != 0 {
+		t.Errorf("Expected no products, got: %v", products)
+	}
+
+	restored, err := pr.Restore(ctx, p.GetId())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if restored.GetId() != p.GetId() || restored.GetName() != p.GetName() {
+		t.Errorf("Expected the restored product, got: %v", restored)
+	}
+	if _, err := pr.Get(ctx, p.GetId()); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+// TestIntegrationDeleteWhere tests that a bulk delete by tag removes the
+// matching products, in stock or not, and keeps the others.
+func TestIntegrationDeleteWhere(t *testing.T) {
+	db := testutil.NewDB(t)
+	ctx := context.Background()
+	pr := repo.NewProductRepo(ctx, db)
+
+	inStock := testutil.NewProduct("Old laptop")
+	inStock.Tags = []string{"discontinued"}
+	outOfStock := testutil.NewProduct("Old mouse")
+	outOfStock.Tags = []string{"discontinued"}
+	outOfStock.Quantity = 0
+	kept := testutil.NewProduct("Laptop")
+	testutil.SeedProducts(t, db, inStock, outOfStock, kept)
+
+	deleted, err := pr.DeleteWhere(ctx, repo.ListFilter{Tags: []string{"discontinued"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(deleted) != 2 {
+		t.Errorf("Expected 2 deleted products, got: %v", deleted)
+	}
+	if _, err := pr.Get(ctx, kept.GetId()); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if _, err := pr.Get(ctx, outOfStock.GetId()); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("Expected no rows, got: %v", err)
+	}
+}
+
+// TestIntegrationPurge tests that purging a deleted product removes its
+// reservations with it.
+func TestIntegrationPurge(t *testing.T) {
+	db := testutil.NewDB(t)
+	ctx := context.Background()
+	pr := repo.NewProductRepo(ctx, db)
+
+	p := testutil.SeedProducts(t, db, testutil.NewProduct("Laptop"))[0]
+	r, err := pr.ReserveStock(ctx, &repo.Reservation{
+		ID:        uuid.NewString(),
+		ProductID: p.GetId(),
+		OrderID:   "order-1",
+		Quantity:  1,
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := pr.Delete(ctx, p.GetId()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	purged, err := pr.Purge(ctx, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if purged != 1 {
+		t.Errorf("Expected 1 purged product, got: %d", purged)
+	}
+	if _, err := pr.GetReservation(ctx, r.ID); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected the reservation purged, got: %v", err)
+	}
+}
+
+// TestIntegrationReservedStock tests that a purchase cannot take the
+// units of an active reservation, so confirming the reservation after
+// the rest of the stock sold out still succeeds.
+func TestIntegrationReservedStock(t *testing.T) {
+	db := testutil.NewDB(t)
+	ctx := context.Background()
+	pr := repo.NewProductRepo(ctx, db)
+
+	p := testutil.NewProduct("Laptop")
+	p.Quantity = 5
+	testutil.SeedProducts(t, db, p)
+
+	r, err := pr.ReserveStock(ctx, &repo.Reservation{
+		ID:        uuid.NewString(),
+		ProductID: p.GetId(),
+		OrderID:   "order-1",
+		Quantity:  3,
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if remaining, err := pr.DecrementQuantity(ctx, p.GetId(), 2); err != nil || remaining != 3 {
+		t.Fatalf("Expected the 2 free units sold and 3 left, got %d, %v", remaining, err)
+	}
+	var stockErr *repo.InsufficientStockError
+	if _, err := pr.DecrementQuantity(ctx, p.GetId(), 1); !errors.As(err, &stockErr) || stockErr.Available != 0 {
+		t.Fatalf("Expected InsufficientStockError without free units, got: %v", err)
+	}
+
+	if _, quantity, err := pr.ConfirmReservation(ctx, r.ID); err != nil || quantity != 0 {
+		t.Fatalf("Expected the reserved units taken, got %d, %v", quantity, err)
+	}
+	got, err := pr.Get(ctx, p.GetId())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got.GetQuantity() != 0 {
+		t.Errorf("Expected the stock sold out, got: %d", got.GetQuantity())
+	}
+}
@@ -0,0 +1,307 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/andro-kes/inventory_service/internal/auth"
+	"github.com/andro-kes/inventory_service/internal/inverr"
+	"github.com/andro-kes/inventory_service/internal/repo/builder"
+	"github.com/jackc/pgx/v5"
+)
+
+// ReasonDecrement is the movement reason recorded by DecrementQuantity.
+const ReasonDecrement = "decrement"
+
+// Movement reasons of quantities written directly: by Create, CreateMany,
+// CopyFrom and the insert of UpsertBySKU, and by Update and the update of
+// UpsertBySKU.
+const (
+	ReasonCreate = "create"
+	ReasonUpdate = "update"
+)
+
+// Movement reasons of AdjustStock.
+const (
+	ReasonReceived   = "received"
+	ReasonReturned   = "returned"
+	ReasonDamaged    = "damaged"
+	ReasonLost       = "lost"
+	ReasonSold       = "sold"
+	ReasonCorrection = "correction"
+)
+
+// adjustReasons maps the reasons AdjustStock accepts to the sign their
+// delta must have; zero allows both.
+var adjustReasons = map[string]int{
+	ReasonReceived:   1,
+	ReasonReturned:   1,
+	ReasonDamaged:    -1,
+	ReasonLost:       -1,
+	ReasonSold:       -1,
+	ReasonCorrection: 0,
+}
+
+// validateAdjustment checks that reason is one of adjustReasons and delta
+// is a change in its direction.
+func validateAdjustment(delta int32, reason string) error {
+	if delta == 0 {
+		return inverr.InvalidField("delta", "delta must not be zero")
+	}
+	sign, ok := adjustReasons[reason]
+	if !ok {
+		return inverr.InvalidField("reason", "unknown reason %q", reason)
+	}
+	switch {
+	case sign > 0 && delta < 0:
+		return inverr.InvalidField("delta", "delta of %s stock must be positive: %d", reason, delta)
+	case sign < 0 && delta > 0:
+		return inverr.InvalidField("delta", "delta of %s stock must be negative: %d", reason, delta)
+	}
+	return nil
+}
+
+// Movement is an entry of the stock ledger: a change of a product's
+// quantity by Delta units.
+type Movement struct {
+	ID        int64  `db:"id"`
+	ProductID string `db:"product_id"`
+	Delta     int32  `db:"delta"`
+	Reason    string `db:"reason"`
+	// Reference is what the movement belongs to, e.g.
+	// "reservations/<id>", or the reference given to AdjustStock.
+	Reference *string   `db:"reference"`
+	CreatedBy *string   `db:"created_by"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// Discrepancy is a product whose live quantity differs from its snapshot
+// quantity plus the ledger movements recorded since the snapshot.
+type Discrepancy struct {
+	ProductID        string
+	SnapshotQuantity int32 // quantity recorded by the snapshot
+	Movements        int32 // sum of ledger deltas since the snapshot
+	Quantity         int32 // live products.quantity
+}
+
+// Expected returns the quantity the snapshot and the ledger account for.
+func (d Discrepancy) Expected() int32 {
+	return d.SnapshotQuantity + d.Movements
+}
+
+// Diff returns the units the ledger does not explain; negative when stock
+// is missing.
+func (d Discrepancy) Diff() int32 {
+	return d.Quantity - d.Expected()
+}
+
+// StockRepo keeps the stock ledger and snapshots used to reconcile the
+// quantity column with physical stock takes.
+type StockRepo interface {
+	AdjustStock(ctx context.Context, productID string, delta int32, reason, reference string) (int32, error)
+	Movements(ctx context.Context, productID string, since time.Time) ([]*Movement, error)
+	Snapshot(ctx context.Context) (time.Time, int64, error)
+	Discrepancies(ctx context.Context, takenAt time.Time) ([]Discrepancy, error)
+}
+
+type stockRepo struct {
+	DB Querier
+}
+
+// NewStockRepo returns a StockRepo running its queries on db.
+func NewStockRepo(ctx context.Context, db Querier) StockRepo {
+	return &tracingStockRepo{
+		next: &stockRepo{
+			DB: db,
+		},
+		tracer: newTracer("StockRepo"),
+	}
+}
+
+func (sr *stockRepo) db(ctx context.Context) Querier {
+	return conn(ctx, sr.DB)
+}
+
+// labelMovementSQL sets the reason, reference and actor the
+// products_stock_movement trigger records with the next change of a
+// quantity in the transaction. Without a label the trigger records
+// ReasonCreate or ReasonUpdate and the updated_by of the product.
+const labelMovementSQL = "SELECT set_config('inventory.stock_reason', $1, true), " +
+	"set_config('inventory.stock_reference', $2, true), set_config('inventory.actor', $3, true)"
+
+// labelMovement labels the ledger entry of the next quantity change in
+// the transaction of q with reason and reference, which may be empty.
+func labelMovement(ctx context.Context, q Querier, reason, reference string) error {
+	_, err := q.Exec(ctx, labelMovementSQL, reason, reference, auth.Principal(ctx))
+	return err
+}
+
+// AdjustStock changes the product's quantity by delta, e.g. to book
+// received goods or the result of a stock take, records the movement with
+// reason, one of the Reason constants of AdjustStock, and reference, if
+// not empty, and returns the new quantity. A negative delta takes only
+// units not held by active reservations, so the quantity never goes
+// negative or below the reserved units: an *InsufficientStockError is
+// returned instead.
+func (sr *stockRepo) AdjustStock(ctx context.Context, productID string, delta int32, reason, reference string) (int32, error) {
+	if err := validateAdjustment(delta, reason); err != nil {
+		return 0, err
+	}
+
+	b := productsQuery(ctx).
+		Update("products").
+		Set("quantity = quantity + ?", delta).
+		Set("updated_at = ?", time.Now()).
+		Set("updated_by = ?", actor(ctx)).
+		Where("id = ?", productID).
+		Returning("quantity")
+	if delta < 0 {
+		b.Where(freeQuantity+" + ? >= 0", delta)
+	}
+	sql, args := b.Build()
+
+	tx, err := sr.db(ctx).Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if delta < 0 {
+		if err := lockProduct(ctx, tx, productID); err != nil {
+			return 0, err
+		}
+	}
+	if err := labelMovement(ctx, tx, reason, reference); err != nil {
+		return 0, err
+	}
+	var quantity int32
+	err = tx.QueryRow(ctx, sql, args...).Scan(&quantity)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, stockError(ctx, tx, productID, -delta)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if err := notifyChange(ctx, tx, productID, OpUpdate); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return quantity, nil
+}
+
+// Movements returns the ledger entries of the product recorded after
+// since, oldest first.
+func (sr *stockRepo) Movements(ctx context.Context, productID string, since time.Time) ([]*Movement, error) {
+	sql, args := newQuery(ctx).
+		Select(builder.StructColumns(Movement{})...).
+		From("stock_movements").
+		Where("product_id = ?", productID).
+		Where("created_at > ?", since).
+		OrderBy("id").
+		Build()
+
+	rows, err := sr.db(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	movements := make([]*Movement, 0)
+	for rows.Next() {
+		var m Movement
+		if err := rows.Scan(&m.ID, &m.ProductID, &m.Delta, &m.Reason, &m.Reference, &m.CreatedBy, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		movements = append(movements, &m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return movements, nil
+}
+
+// Snapshot records the live quantity of every product that is not
+// deleted and returns the snapshot time and the number of products.
+// Movements committing while the snapshot is taken may show up as
+// transient discrepancies.
+func (sr *stockRepo) Snapshot(ctx context.Context) (time.Time, int64, error) {
+	sql, args := newQuery(ctx).
+		Insert("stock_snapshots").
+		Columns("taken_at", "product_id", "quantity").
+		InsertSelect(productsQuery(ctx).Select("now()", "id", "quantity").From("products")).
+		Build()
+
+	tx, err := sr.db(ctx).Begin(ctx)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	// now() is the transaction start time, so it matches the stored taken_at.
+	var takenAt time.Time
+	if err := tx.QueryRow(ctx, "SELECT now()").Scan(&takenAt); err != nil {
+		return time.Time{}, 0, err
+	}
+
+	tag, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return time.Time{}, 0, err
+	}
+
+	return takenAt, tag.RowsAffected(), nil
+}
+
+// Discrepancies compares the snapshot taken at takenAt, or the latest one
+// if takenAt is zero, plus the ledger movements since then with the live
+// quantities and returns the products that do not add up, ordered by id.
+func (sr *stockRepo) Discrepancies(ctx context.Context, takenAt time.Time) ([]Discrepancy, error) {
+	b := newQuery(ctx).
+		Select("s.product_id", "s.quantity", "COALESCE(m.delta, 0)", "p.quantity").
+		From("stock_snapshots s").
+		Join("products p", "p.id = s.product_id").
+		LeftJoin("LATERAL (SELECT SUM(delta) AS delta FROM stock_movements "+
+			"WHERE product_id = s.product_id AND created_at > s.taken_at) m", "true").
+		Where("s.quantity + COALESCE(m.delta, 0) <> p.quantity").
+		OrderBy("s.product_id")
+
+	if takenAt.IsZero() {
+		b.Where("s.taken_at = (?)", builder.NewSQLBuilder().SelectMax("taken_at").From("stock_snapshots"))
+	} else {
+		b.Where("s.taken_at = ?", takenAt)
+	}
+
+	sql, args := b.Build()
+	rows, err := sr.db(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	discrepancies := make([]Discrepancy, 0)
+	for rows.Next() {
+		var d Discrepancy
+		if err := rows.Scan(&d.ProductID, &d.SnapshotQuantity, &d.Movements, &d.Quantity); err != nil {
+			return nil, err
+		}
+		discrepancies = append(discrepancies, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return discrepancies, nil
+}
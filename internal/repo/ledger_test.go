@@ -0,0 +1,198 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v4"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TestDecrementQuantityLedger tests that a decrement is labelled for the
+// stock ledger in the same transaction.
+func TestDecrementQuantityLedger(t *testing.T) {
+	pr, mock := newMockRepo(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectProductLock).
+		WithArgs("1").
+		WillReturnRows(mock.NewRows([]string{"id"}).AddRow("1"))
+	mock.ExpectExec(labelMovementSQL).
+		WithArgs(ReasonDecrement, "", "").
+		WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectQuery("UPDATE products SET quantity = quantity - $1, updated_at = $2 "+
+		"WHERE id = $3 AND "+freeQuantity+" >= $4 AND deleted_at IS NULL RETURNING quantity").
+		WithArgs(int32(2), pgxmock.AnyArg(), "1", int32(2)).
+		WillReturnRows(mock.NewRows([]string{"quantity"}).AddRow(int32(3)))
+	mock.ExpectCommit()
+
+	quantity, err := pr.DecrementQuantity(context.Background(), "1", 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if quantity != 3 {
+		t.Errorf("Expected quantity 3, got: %d", quantity)
+	}
+}
+
+// TestStockSnapshot tests that a snapshot copies the live quantities in one
+// statement at the transaction time.
+func TestStockSnapshot(t *testing.T) {
+	_, mock := newMockRepo(t)
+	sr := &stockRepo{DB: mock}
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT now()").WillReturnRows(mock.NewRows([]string{"now"}).AddRow(now))
+	mock.ExpectExec("INSERT INTO stock_snapshots (taken_at, product_id, quantity) " +
+		"SELECT now(), id, quantity FROM products WHERE deleted_at IS NULL").
+		WillReturnResult(pgxmock.NewResult("INSERT", 3))
+	mock.ExpectCommit()
+
+	takenAt, n, err := sr.Snapshot(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !takenAt.Equal(now) || n != 3 {
+		t.Errorf("Unexpected snapshot: %v, %d products", takenAt, n)
+	}
+}
+
+// TestStockDiscrepancies tests the reconciliation against the latest
+// snapshot.
+func TestStockDiscrepancies(t *testing.T) {
+	_, mock := newMockRepo(t)
+	sr := &stockRepo{DB: mock}
+
+	mock.ExpectQuery("SELECT s.product_id, s.quantity, COALESCE(m.delta, 0), p.quantity FROM stock_snapshots s " +
+		"JOIN products p ON p.id = s.product_id " +
+		"LEFT JOIN LATERAL (SELECT SUM(delta) AS delta FROM stock_movements " +
+		"WHERE product_id = s.product_id AND created_at > s.taken_at) m ON true " +
+		"WHERE s.quantity + COALESCE(m.delta, 0) <> p.quantity " +
+		"AND s.taken_at = (SELECT MAX(taken_at) FROM stock_snapshots) ORDER BY s.product_id").
+		WillReturnRows(mock.NewRows([]string{"product_id", "quantity", "delta", "quantity"}).
+			AddRow("1", int32(10), int32(-2), int32(7)))
+
+	discrepancies, err := sr.Discrepancies(context.Background(), time.Time{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(discrepancies) != 1 {
+		t.Fatalf("Expected 1 discrepancy, got: %v", discrepancies)
+	}
+	if d := discrepancies[0]; d.Expected() != 8 || d.Diff() != -1 {
+		t.Errorf("Expected 8 units expected and 1 missing, got: %+v", d)
+	}
+}
+
+// TestStockMovements tests reading the ledger of a product with the
+// references of its movements.
+func TestStockMovements(t *testing.T) {
+	_, mock := newMockRepo(t)
+	sr := &stockRepo{DB: mock}
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	reference := "reservations/r1"
+
+	mock.ExpectQuery("SELECT id, product_id, delta, reason, reference, created_by, created_at FROM stock_movements "+
+		"WHERE product_id = $1 AND created_at > $2 ORDER BY id").
+		WithArgs("1", since).
+		WillReturnRows(mock.NewRows([]string{"id", "product_id", "delta", "reason", "reference", "created_by", "created_at"}).
+			AddRow(int64(1), "1", int32(5), ReasonUpdate, nil, nil, since).
+			AddRow(int64(2), "1", int32(-2), ReasonReservation, &reference, nil, since))
+
+	movements, err := sr.Movements(context.Background(), "1", since)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(movements) != 2 || movements[0].Reference != nil || *movements[1].Reference != reference {
+		t.Errorf("Expected an update and a reservation, got: %+v", movements)
+	}
+}
+
+// TestAdjustStock tests that an adjustment is labelled for the stock
+// ledger in its transaction, before the quantity changes.
+func TestAdjustStock(t *testing.T) {
+	_, mock := newMockRepo(t)
+	sr := &stockRepo{DB: mock}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(labelMovementSQL).
+		WithArgs(ReasonReceived, "PO-42", "").
+		WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectQuery("UPDATE products SET quantity = quantity + $1, updated_at = $2, updated_by = NULL "+
+		"WHERE id = $3 AND deleted_at IS NULL RETURNING quantity").
+		WithArgs(int32(10), pgxmock.AnyArg(), "1").
+		WillReturnRows(mock.NewRows([]string{"quantity"}).AddRow(int32(15)))
+	mock.ExpectExec(notifyChangeSQL).
+		WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), "", "").
+		WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectCommit()
+
+	quantity, err := sr.AdjustStock(context.Background(), "1", 10, ReasonReceived, "PO-42")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if quantity != 15 {
+		t.Errorf("Expected quantity 15, got: %d", quantity)
+	}
+}
+
+// TestAdjustStockReserved tests that a negative adjustment leaves the
+// units of active reservations in stock.
+func TestAdjustStockReserved(t *testing.T) {
+	_, mock := newMockRepo(t)
+	sr := &stockRepo{DB: mock}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectProductLock).
+		WithArgs("1").
+		WillReturnRows(mock.NewRows([]string{"id"}).AddRow("1"))
+	mock.ExpectExec(labelMovementSQL).
+		WithArgs(ReasonDamaged, "", "").
+		WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectQuery("UPDATE products SET quantity = quantity + $1, updated_at = $2, updated_by = NULL "+
+		"WHERE id = $3 AND "+freeQuantity+" + $4 >= 0 AND deleted_at IS NULL RETURNING quantity").
+		WithArgs(int32(-3), pgxmock.AnyArg(), "1", int32(-3)).
+		WillReturnRows(mock.NewRows([]string{"quantity"}))
+	mock.ExpectQuery("SELECT " + freeQuantity + " FROM products WHERE id = $1 AND deleted_at IS NULL").
+		WithArgs("1").
+		WillReturnRows(mock.NewRows([]string{"free"}).AddRow(int64(1)))
+	mock.ExpectRollback()
+
+	_, err := sr.AdjustStock(context.Background(), "1", -3, ReasonDamaged, "")
+	var stockErr *InsufficientStockError
+	if !errors.As(err, &stockErr) || stockErr.Available != 1 {
+		t.Errorf("Expected InsufficientStockError with 1 free unit, got: %v", err)
+	}
+}
+
+func TestValidateAdjustment(t *testing.T) {
+	cases := []struct {
+		delta  int32
+		reason string
+		valid  bool
+	}{
+		{5, ReasonReceived, true},
+		{-2, ReasonDamaged, true},
+		{-1, ReasonCorrection, true},
+		{3, ReasonCorrection, true},
+		{0, ReasonCorrection, false},
+		{-5, ReasonReceived, false},
+		{2, ReasonSold, false},
+		{1, "", false},
+		{1, ReasonDecrement, false},
+	}
+
+	for _, tc := range cases {
+		err := validateAdjustment(tc.delta, tc.reason)
+		if tc.valid && err != nil {
+			t.Errorf("Expected %d %q to be valid, got: %v", tc.delta, tc.reason, err)
+		}
+		if !tc.valid && status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument for %d %q, got: %v", tc.delta, tc.reason, err)
+		}
+	}
+}
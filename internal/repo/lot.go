@@ -0,0 +1,310 @@
+package repo
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/andro-kes/inventory_service/internal/inverr"
+	"github.com/andro-kes/inventory_service/internal/repo/builder"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxLotNumberLength bounds the lot numbers, in characters.
+const maxLotNumberLength = 64
+
+// dateLayout formats the expiry dates of lots.
+const dateLayout = "2006-01-02"
+
+// Lot is a batch of a product received together. The units of the lots
+// of a product are part of its quantity: receipts add to both, and every
+// decrement of the quantity takes units from the lots first expiring
+// first. Units added without a lot, e.g. by AdjustStock, are not traced.
+type Lot struct {
+	ID        string `db:"id"`
+	ProductID string `db:"product_id"`
+	Number    string `db:"lot_number"`
+	// ExpiresOn is the last day the lot is fit for sale, at midnight UTC.
+	ExpiresOn time.Time `db:"expires_on"`
+	// Quantity is the units of the lot left in stock.
+	Quantity  int32     `db:"quantity"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// lotColumns lists the product_lots columns in Lot order.
+var lotColumns = builder.StructColumns(Lot{})
+
+func (l *Lot) scan(row pgx.Row) error {
+	return row.Scan(&l.ID, &l.ProductID, &l.Number, &l.ExpiresOn, &l.Quantity, &l.CreatedAt)
+}
+
+// LotMovement is the part of a stock ledger entry booked to a lot.
+type LotMovement struct {
+	ID     int64
+	LotID  string
+	Delta  int32
+	Reason string
+	// Reference is the reference of the ledger entry, e.g.
+	// "orders/<id>/lines/<id>" for the units of an order line.
+	Reference *string
+	CreatedAt time.Time
+}
+
+// validateLot checks the lot number and the received quantity of l.
+func validateLot(l *Lot) error {
+	switch {
+	case l.Number == "":
+		return inverr.InvalidField("lot_number", "lot_number is required")
+	case utf8.RuneCountInString(l.Number) > maxLotNumberLength:
+		return inverr.InvalidField("lot_number", "lot_number must be at most %d characters", maxLotNumberLength)
+	case l.ExpiresOn.IsZero():
+		return inverr.InvalidField("expiry_date", "expiry_date is required")
+	case l.Quantity <= 0:
+		return inverr.InvalidField("quantity", "quantity must be positive: %d", l.Quantity)
+	}
+	return nil
+}
+
+// LotRepo keeps the lots of the products and the ledger entries booked
+// to them.
+type LotRepo interface {
+	// Receive books l.Quantity received units of l.ProductID to the lot
+	// l.Number, created with l.ID and l.ExpiresOn unless the product has
+	// it already, and records the movement with ReasonReceived and
+	// reference. It returns the lot and the quantity of the product. A
+	// lot number received again with another expiry date fails with
+	// FailedPrecondition.
+	Receive(ctx context.Context, l *Lot, reference string) (*Lot, int32, error)
+	// Expiring returns a page of the lots with units left expiring on or
+	// before the day of before, of productID unless it is empty, the
+	// soonest first, and the token of the next page, empty on the last
+	// one. A non-positive pageSize means defaultPageSize, larger ones are
+	// capped at maxPageSize. Tokens it did not issue fail with
+	// ErrInvalidCursor.
+	Expiring(ctx context.Context, before time.Time, productID, pageToken string, pageSize int32) ([]*Lot, string, error)
+	// Movements returns the ledger entries booked to the lot, oldest
+	// first, which trace its units to their receipts and orders.
+	Movements(ctx context.Context, lotID string) ([]*LotMovement, error)
+}
+
+type lotRepo struct {
+	DB Querier
+}
+
+// NewLotRepo returns a LotRepo running its queries on db.
+func NewLotRepo(ctx context.Context, db Querier) LotRepo {
+	return &tracingLotRepo{
+		next: &lotRepo{
+			DB: db,
+		},
+		tracer: newTracer("LotRepo"),
+	}
+}
+
+func (lr *lotRepo) db(ctx context.Context) Querier {
+	return conn(ctx, lr.DB)
+}
+
+// labelLotSQL books the next quantity change in the transaction to a lot
+// instead of picking lots; the products_stock_movement trigger consumes it
+// with the label of labelMovement.
+const labelLotSQL = "SELECT set_config('inventory.stock_lot', $1, true)"
+
+func (lr *lotRepo) Receive(ctx context.Context, l *Lot, reference string) (*Lot, int32, error) {
+	if err := validateLot(l); err != nil {
+		return nil, 0, err
+	}
+
+	tx, err := lr.db(ctx).Begin(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	// Locking the product serializes the receipts of its lots.
+	if err := lockProduct(ctx, tx, l.ProductID); err != nil {
+		return nil, 0, err
+	}
+
+	sql, args := newQuery(ctx).
+		Select("id", "expires_on").
+		From("product_lots").
+		Where("product_id = ?", l.ProductID).
+		Where("lot_number = ?", l.Number).
+		Build()
+	lotID := l.ID
+	var expiresOn time.Time
+	err = tx.QueryRow(ctx, sql, args...).Scan(&lotID, &expiresOn)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		sql, args = newQuery(ctx).
+			Insert("product_lots").
+			Columns("id", "product_id", "lot_number", "expires_on").
+			Values(l.ID, l.ProductID, l.Number, l.ExpiresOn).
+			Build()
+		if _, err := tx.Exec(ctx, sql, args...); err != nil {
+			return nil, 0, err
+		}
+	case err != nil:
+		return nil, 0, err
+	case !expiresOn.Equal(l.ExpiresOn):
+		return nil, 0, status.Errorf(codes.FailedPrecondition, "lot %s expires on %s, not %s",
+			l.Number, expiresOn.Format(dateLayout), l.ExpiresOn.Format(dateLayout))
+	}
+
+	if err := labelMovement(ctx, tx, ReasonReceived, reference); err != nil {
+		return nil, 0, err
+	}
+	if _, err := tx.Exec(ctx, labelLotSQL, lotID); err != nil {
+		return nil, 0, err
+	}
+	sql, args = productsQuery(ctx).
+		Update("products").
+		Set("quantity = quantity + ?", l.Quantity).
+		Set("updated_at = ?", time.Now()).
+		Set("updated_by = ?", actor(ctx)).
+		Where("id = ?", l.ProductID).
+		Returning("quantity").
+		Build()
+	var quantity int32
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&quantity); err != nil {
+		return nil, 0, err
+	}
+
+	sql, args = newQuery(ctx).
+		Select(lotColumns...).
+		From("product_lots").
+		Where("id = ?", lotID).
+		Build()
+	var lot Lot
+	if err := lot.scan(tx.QueryRow(ctx, sql, args...)); err != nil {
+		return nil, 0, err
+	}
+
+	if err := notifyChange(ctx, tx, l.ProductID, OpUpdate); err != nil {
+		return nil, 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, 0, err
+	}
+
+	return &lot, quantity, nil
+}
+
+func (lr *lotRepo) Expiring(ctx context.Context, before time.Time, productID, pageToken string, pageSize int32) ([]*Lot, string, error) {
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	pageSize = min(pageSize, maxPageSize)
+
+	columns := make([]string, len(lotColumns))
+	for i, col := range lotColumns {
+		columns[i] = "l." + col
+	}
+	// One extra row tells whether there is a next page.
+	b := productsQuery(ctx).
+		Select(columns...).
+		From("products").
+		Join("product_lots l", "l.product_id = products.id").
+		Where("l.quantity > 0").
+		Where("l.expires_on <= ?", before.Format(dateLayout)).
+		OrderBy("l.expires_on").
+		OrderBy("l.id").
+		Limit(int(pageSize) + 1)
+	if productID != "" {
+		b.Where("l.product_id = ?", productID)
+	}
+	if pageToken != "" {
+		expiresOn, id, err := decodeLotToken(pageToken)
+		if err != nil {
+			return nil, "", err
+		}
+		b.Where("(l.expires_on, l.id) > (?, ?)", expiresOn, id)
+	}
+	sql, args := b.Build()
+
+	rows, err := lr.db(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	lots := make([]*Lot, 0)
+	for rows.Next() {
+		var l Lot
+		if err := l.scan(rows); err != nil {
+			return nil, "", err
+		}
+		lots = append(lots, &l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	if len(lots) <= int(pageSize) {
+		return lots, "", nil
+	}
+	lots = lots[:pageSize]
+	last := lots[len(lots)-1]
+	return lots, encodeLotToken(last.ExpiresOn, last.ID), nil
+}
+
+func (lr *lotRepo) Movements(ctx context.Context, lotID string) ([]*LotMovement, error) {
+	sql, args := newQuery(ctx).
+		Select("lm.id", "lm.lot_id", "lm.delta", "m.reason", "m.reference", "m.created_at").
+		From("lot_movements lm").
+		Join("stock_movements m", "m.id = lm.movement_id").
+		Where("lm.lot_id = ?", lotID).
+		OrderBy("lm.id").
+		Build()
+
+	rows, err := lr.db(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	movements := make([]*LotMovement, 0)
+	for rows.Next() {
+		var m LotMovement
+		if err := rows.Scan(&m.ID, &m.LotID, &m.Delta, &m.Reason, &m.Reference, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		movements = append(movements, &m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return movements, nil
+}
+
+// encodeLotToken returns an opaque page token pointing after the lot id
+// expiring on expiresOn.
+func encodeLotToken(expiresOn time.Time, id string) string {
+	raw := "lots|" + expiresOn.Format(dateLayout) + "|" + id
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeLotToken reverses encodeLotToken.
+func decodeLotToken(token string) (string, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", "", ErrInvalidCursor
+	}
+	parts := strings.Split(string(raw), "|")
+	if len(parts) != 3 || parts[0] != "lots" || parts[2] == "" {
+		return "", "", ErrInvalidCursor
+	}
+	if _, err := time.Parse(dateLayout, parts[1]); err != nil {
+		return "", "", ErrInvalidCursor
+	}
+	return parts[1], parts[2], nil
+}
@@ -0,0 +1,177 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v4"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const selectProductLock = "SELECT id FROM products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE"
+
+const selectLotNumber = "SELECT id, expires_on FROM product_lots WHERE product_id = $1 AND lot_number = $2"
+
+// TestLotReceive tests that the first receipt of a lot creates it and
+// books the units to it through the stock ledger.
+func TestLotReceive(t *testing.T) {
+	_, mock := newMockRepo(t)
+	lr := &lotRepo{DB: mock}
+	expiresOn := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+	l := &Lot{ID: "l1", ProductID: testProductID, Number: "B-7", ExpiresOn: expiresOn, Quantity: 12}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectProductLock).
+		WithArgs(testProductID).
+		WillReturnRows(mock.NewRows([]string{"id"}).AddRow(testProductID))
+	mock.ExpectQuery(selectLotNumber).
+		WithArgs(testProductID, "B-7").
+		WillReturnRows(mock.NewRows([]string{"id", "expires_on"}))
+	mock.ExpectExec("INSERT INTO product_lots (id, product_id, lot_number, expires_on) VALUES ($1, $2, $3, $4)").
+		WithArgs("l1", testProductID, "B-7", expiresOn).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectExec(labelMovementSQL).
+		WithArgs(ReasonReceived, "PO-9", "").
+		WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectExec(labelLotSQL).
+		WithArgs("l1").
+		WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectQuery("UPDATE products SET quantity = quantity + $1, updated_at = $2, updated_by = NULL "+
+		"WHERE id = $3 AND deleted_at IS NULL RETURNING quantity").
+		WithArgs(int32(12), pgxmock.AnyArg(), testProductID).
+		WillReturnRows(mock.NewRows([]string{"quantity"}).AddRow(int32(15)))
+	mock.ExpectQuery("SELECT id, product_id, lot_number, expires_on, quantity, created_at FROM product_lots WHERE id = $1").
+		WithArgs("l1").
+		WillReturnRows(mock.NewRows([]string{"id", "product_id", "lot_number", "expires_on", "quantity", "created_at"}).
+			AddRow("l1", testProductID, "B-7", expiresOn, int32(12), time.Now()))
+	mock.ExpectExec(notifyChangeSQL).
+		WithArgs(pgxmock.AnyArg(), pgxmock.AnyArg(), "", "").
+		WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectCommit()
+
+	lot, quantity, err := lr.Receive(context.Background(), l, "PO-9")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if lot.ID != "l1" || lot.Quantity != 12 || quantity != 15 {
+		t.Errorf("Expected 12 units in lot l1 and 15 in stock, got %+v and %d", lot, quantity)
+	}
+}
+
+// TestLotReceiveExpiry tests that a lot cannot be received again with
+// another expiry date.
+func TestLotReceiveExpiry(t *testing.T) {
+	_, mock := newMockRepo(t)
+	lr := &lotRepo{DB: mock}
+	l := &Lot{ID: "l2", ProductID: testProductID, Number: "B-7", ExpiresOn: time.Date(2026, 4, 30, 0, 0, 0, 0, time.UTC), Quantity: 1}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectProductLock).
+		WithArgs(testProductID).
+		WillReturnRows(mock.NewRows([]string{"id"}).AddRow(testProductID))
+	mock.ExpectQuery(selectLotNumber).
+		WithArgs(testProductID, "B-7").
+		WillReturnRows(mock.NewRows([]string{"id", "expires_on"}).
+			AddRow("l1", time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)))
+	mock.ExpectRollback()
+
+	if _, _, err := lr.Receive(context.Background(), l, ""); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition, got: %v", err)
+	}
+}
+
+func TestValidateLot(t *testing.T) {
+	expiresOn := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		lot   Lot
+		field string
+	}{
+		{Lot{Number: "B-7", ExpiresOn: expiresOn, Quantity: 1}, ""},
+		{Lot{ExpiresOn: expiresOn, Quantity: 1}, "lot_number"},
+		{Lot{Number: string(make([]byte, 65)), ExpiresOn: expiresOn, Quantity: 1}, "lot_number"},
+		{Lot{Number: "B-7", Quantity: 1}, "expiry_date"},
+		{Lot{Number: "B-7", ExpiresOn: expiresOn}, "quantity"},
+	}
+
+	for _, tc := range cases {
+		err := validateLot(&tc.lot)
+		if tc.field == "" && err != nil {
+			t.Errorf("Expected %+v to be valid, got: %v", tc.lot, err)
+		}
+		if tc.field != "" && status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument for the %s of %+v, got: %v", tc.field, tc.lot, err)
+		}
+	}
+}
+
+// TestLotExpiring tests paging through the expiring lots, the soonest
+// first.
+func TestLotExpiring(t *testing.T) {
+	_, mock := newMockRepo(t)
+	lr := &lotRepo{DB: mock}
+	before := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+	columns := []string{"id", "product_id", "lot_number", "expires_on", "quantity", "created_at"}
+	const selectLots = "SELECT l.id, l.product_id, l.lot_number, l.expires_on, l.quantity, l.created_at FROM products " +
+		"JOIN product_lots l ON l.product_id = products.id WHERE l.quantity > 0 AND l.expires_on <= $1 "
+
+	mock.ExpectQuery(selectLots+"AND l.product_id = $2 AND products.deleted_at IS NULL ORDER BY l.expires_on, l.id LIMIT 2").
+		WithArgs("2026-03-31", testProductID).
+		WillReturnRows(mock.NewRows(columns).
+			AddRow("l1", testProductID, "A-1", day(20), int32(3), day(1)).
+			AddRow("l2", testProductID, "B-7", day(31), int32(12), day(1)))
+
+	lots, next, err := lr.Expiring(context.Background(), before, testProductID, "", 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(lots) != 1 || lots[0].Number != "A-1" || next == "" {
+		t.Fatalf("Expected lot A-1 and a next page, got %v and %q", lots, next)
+	}
+
+	mock.ExpectQuery(selectLots+"AND (l.expires_on, l.id) > ($2, $3) AND products.deleted_at IS NULL ORDER BY l.expires_on, l.id LIMIT 2").
+		WithArgs("2026-03-31", "2026-03-20", "l1").
+		WillReturnRows(mock.NewRows(columns).
+			AddRow("l2", testProductID, "B-7", day(31), int32(12), day(1)))
+
+	lots, next, err = lr.Expiring(context.Background(), before, "", next, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(lots) != 1 || lots[0].Number != "B-7" || next != "" {
+		t.Errorf("Expected the last lot B-7, got %v and %q", lots, next)
+	}
+
+	for _, token := range []string{"!", encodeReorderToken(1, "l1")} {
+		if _, _, err := lr.Expiring(context.Background(), before, "", token, 0); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("Expected ErrInvalidCursor for %q, got: %v", token, err)
+		}
+	}
+}
+
+// TestLotMovements tests tracing the units of a lot to their ledger
+// entries.
+func TestLotMovements(t *testing.T) {
+	_, mock := newMockRepo(t)
+	lr := &lotRepo{DB: mock}
+	now := time.Now()
+	po, order := "PO-9", "orders/o1/lines/1"
+
+	mock.ExpectQuery("SELECT lm.id, lm.lot_id, lm.delta, m.reason, m.reference, m.created_at FROM lot_movements lm " +
+		"JOIN stock_movements m ON m.id = lm.movement_id WHERE lm.lot_id = $1 ORDER BY lm.id").
+		WithArgs("l1").
+		WillReturnRows(mock.NewRows([]string{"id", "lot_id", "delta", "reason", "reference", "created_at"}).
+			AddRow(int64(1), "l1", int32(12), ReasonReceived, &po, now).
+			AddRow(int64(4), "l1", int32(-2), ReasonSold, &order, now))
+
+	movements, err := lr.Movements(context.Background(), "l1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(movements) != 2 || *movements[0].Reference != po || movements[1].Delta != -2 {
+		t.Errorf("Expected the receipt and the sale of the lot, got: %+v", movements)
+	}
+}
@@ -0,0 +1,101 @@
+package repo
+
+import (
+	"math"
+	"regexp"
+
+	"github.com/andro-kes/inventory_service/internal/inverr"
+	pb "github.com/andro-kes/inventory_service/proto"
+)
+
+// DefaultCurrency is the currency of prices given without one, e.g. only
+// as the deprecated Product.price.
+const DefaultCurrency = "RUB"
+
+// nanosPerCent converts between Money.nanos and cents; prices are stored
+// in cents, so finer nanos are rejected.
+const nanosPerCent = 10_000_000
+
+// currencyCode matches ISO 4217 codes.
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// fieldColumns maps the Product fields stored in other columns than their
+// name to those columns, for update and read masks.
+var fieldColumns = map[string][]string{
+	"price":       {"price_cents", "currency_code"},
+	"price_money": {"price_cents", "currency_code"},
+	"available":   {"status"},
+	// translations are kept in product_translations.
+	"translations": nil,
+	// variants are kept in product_variants.
+	"variants": nil,
+}
+
+// columnsOf returns the columns storing the Product field path.
+func columnsOf(path string) []string {
+	if columns, ok := fieldColumns[path]; ok {
+		return columns
+	}
+	return []string{path}
+}
+
+// validatePrice checks that the price_money of p, if set, is a valid
+// amount in whole cents.
+func validatePrice(p *pb.Product) error {
+	m := p.GetPriceMoney()
+	if m == nil {
+		if math.IsNaN(p.GetPrice()) || math.IsInf(p.GetPrice(), 0) {
+			return inverr.InvalidField("price", "price must be finite")
+		}
+		return nil
+	}
+	return validateMoney("price_money", m)
+}
+
+// validateMoney checks that m, the field name, is a valid amount in whole
+// cents.
+func validateMoney(name string, m *pb.Money) error {
+	if m.GetCurrencyCode() != "" && !currencyCode.MatchString(m.GetCurrencyCode()) {
+		return inverr.InvalidField(name+".currency_code", "invalid currency code %q", m.GetCurrencyCode())
+	}
+	if m.GetNanos() <= -1e9 || m.GetNanos() >= 1e9 || m.GetNanos()%nanosPerCent != 0 {
+		return inverr.InvalidField(name+".nanos", "%s nanos must be whole cents: %d", name, m.GetNanos())
+	}
+	if m.GetUnits() > 0 && m.GetNanos() < 0 || m.GetUnits() < 0 && m.GetNanos() > 0 {
+		return inverr.InvalidField(name, "%s units and nanos must have the same sign", name)
+	}
+	return nil
+}
+
+// priceOf returns the price of p in cents and its currency: price_money
+// if set, otherwise the deprecated price in DefaultCurrency.
+func priceOf(p *pb.Product) (int64, string) {
+	m := p.GetPriceMoney()
+	if m == nil {
+		return toCents(p.GetPrice()), DefaultCurrency
+	}
+	currency := m.GetCurrencyCode()
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	return centsOf(m), currency
+}
+
+// centsOf returns the amount of m in cents.
+func centsOf(m *pb.Money) int64 {
+	return m.GetUnits()*100 + int64(m.GetNanos()/nanosPerCent)
+}
+
+// toCents rounds amount to cents.
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// money returns the Money of cents in currency.
+func money(cents int64, currency string) *pb.Money {
+	return &pb.Money{
+		CurrencyCode: currency,
+		Units:        cents / 100,
+		Nanos:        int32(cents%100) * nanosPerCent,
+	}
+}
@@ -0,0 +1,74 @@
+package repo
+
+import (
+	"math"
+	"testing"
+
+	pb "github.com/andro-kes/inventory_service/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// TestPriceOf tests that price_money takes precedence over the deprecated
+// price, which is read in DefaultCurrency.
+func TestPriceOf(t *testing.T) {
+	cases := []struct {
+		product  *pb.Product
+		cents    int64
+		currency string
+	}{
+		{&pb.Product{}, 0, DefaultCurrency},
+		{&pb.Product{Price: 19.99}, 1999, DefaultCurrency},
+		{&pb.Product{Price: 5, PriceMoney: &pb.Money{CurrencyCode: "USD", Units: 12, Nanos: 340_000_000}}, 1234, "USD"},
+		{&pb.Product{PriceMoney: &pb.Money{Units: 3}}, 300, DefaultCurrency},
+		{&pb.Product{PriceMoney: &pb.Money{Units: -1, Nanos: -500_000_000}}, -150, DefaultCurrency},
+	}
+
+	for _, tc := range cases {
+		cents, currency := priceOf(tc.product)
+		if cents != tc.cents || currency != tc.currency {
+			t.Errorf("priceOf(%v) = %d %s, want %d %s", tc.product, cents, currency, tc.cents, tc.currency)
+		}
+	}
+}
+
+func TestValidatePrice(t *testing.T) {
+	cases := []struct {
+		product *pb.Product
+		valid   bool
+	}{
+		{&pb.Product{Price: 10.5}, true},
+		{&pb.Product{PriceMoney: &pb.Money{CurrencyCode: "EUR", Units: 1, Nanos: 990_000_000}}, true},
+		{&pb.Product{Price: math.NaN()}, false},
+		{&pb.Product{PriceMoney: &pb.Money{CurrencyCode: "eur", Units: 1}}, false},
+		{&pb.Product{PriceMoney: &pb.Money{Units: 1, Nanos: 1}}, false},
+		{&pb.Product{PriceMoney: &pb.Money{Units: 1, Nanos: -10_000_000}}, false},
+		{&pb.Product{PriceMoney: &pb.Money{Nanos: 1_000_000_000}}, false},
+	}
+
+	for _, tc := range cases {
+		err := validatePrice(tc.product)
+		if tc.valid && err != nil {
+			t.Errorf("Expected %v to be valid, got: %v", tc.product, err)
+		}
+		if !tc.valid && status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument for %v, got: %v", tc.product, err)
+		}
+	}
+}
+
+// TestProductRowPrice tests that a row fills both the deprecated price
+// and price_money.
+func TestProductRowPrice(t *testing.T) {
+	row := newProductRow(&pb.Product{PriceMoney: &pb.Money{CurrencyCode: "USD", Units: 12, Nanos: 340_000_000}})
+	p := row.toProto()
+
+	if p.GetPrice() != 12.34 {
+		t.Errorf("Expected price 12.34, got: %v", p.GetPrice())
+	}
+	expected := &pb.Money{CurrencyCode: "USD", Units: 12, Nanos: 340_000_000}
+	if !proto.Equal(p.GetPriceMoney(), expected) {
+		t.Errorf("Expected price_money %v, got: %v", expected, p.GetPriceMoney())
+	}
+}
@@ -0,0 +1,99 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	pb "github.com/andro-kes/inventory_service/proto"
+	"github.com/jackc/pgx/v5"
+)
+
+// Statuses of the order_lines table.
+const (
+	OrderLinePlaced    = "placed"
+	OrderLineCancelled = "cancelled"
+)
+
+// OrderLineRepo records the order lines whose stock was taken or given
+// back, so that redelivered order events are applied once. Call its
+// methods in the transaction changing the stock.
+type OrderLineRepo interface {
+	// Place records the placement of line of the order orderID and
+	// reports whether the line is new: false if it was placed or
+	// cancelled before.
+	Place(ctx context.Context, orderID string, line *pb.OrderLine) (bool, error)
+	// Cancel records the cancellation of line and returns the line as
+	// placed, to give back its stock, or nil if it was not placed: it was
+	// cancelled before, or is not placed yet, and then its placement is
+	// ignored.
+	Cancel(ctx context.Context, orderID string, line *pb.OrderLine) (*pb.OrderLine, error)
+}
+
+type orderLineRepo struct {
+	DB Querier
+}
+
+// NewOrderLineRepo returns an OrderLineRepo running its queries on db.
+func NewOrderLineRepo(ctx context.Context, db Querier) OrderLineRepo {
+	return &tracingOrderLineRepo{
+		next: &orderLineRepo{
+			DB: db,
+		},
+		tracer: newTracer("OrderLineRepo"),
+	}
+}
+
+func (lr *orderLineRepo) db(ctx context.Context) Querier {
+	return conn(ctx, lr.DB)
+}
+
+// insert inserts line with status unless it exists, and reports whether
+// it did. A concurrent insert of the line blocks it until that commits.
+func (lr *orderLineRepo) insert(ctx context.Context, orderID string, line *pb.OrderLine, status string) (bool, error) {
+	sql, args := newQuery(ctx).
+		Insert("order_lines").
+		Columns("line_id", "order_id", "product_id", "quantity", "status").
+		Values(line.GetLineId(), orderID, line.GetProductId(), line.GetQuantity(), status).
+		OnConflict("line_id").
+		DoNothing().
+		Build()
+
+	tag, err := lr.db(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (lr *orderLineRepo) Place(ctx context.Context, orderID string, line *pb.OrderLine) (bool, error) {
+	return lr.insert(ctx, orderID, line, OrderLinePlaced)
+}
+
+func (lr *orderLineRepo) Cancel(ctx context.Context, orderID string, line *pb.OrderLine) (*pb.OrderLine, error) {
+	// Inserting first waits for a concurrent Place, whose line the update
+	// then sees.
+	inserted, err := lr.insert(ctx, orderID, line, OrderLineCancelled)
+	if err != nil || inserted {
+		return nil, err
+	}
+
+	sql, args := newQuery(ctx).
+		Update("order_lines").
+		Set("status = ?", OrderLineCancelled).
+		Set("updated_at = ?", time.Now()).
+		Where("line_id = ?", line.GetLineId()).
+		Where("status = ?", OrderLinePlaced).
+		Returning("product_id", "quantity").
+		Build()
+
+	placed := &pb.OrderLine{LineId: line.GetLineId()}
+	err = lr.db(ctx).QueryRow(ctx, sql, args...).Scan(&placed.ProductId, &placed.Quantity)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return placed, nil
+}
@@ -0,0 +1,71 @@
+package repo
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/andro-kes/inventory_service/proto"
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+const insertOrderLine = "INSERT INTO order_lines (line_id, order_id, product_id, quantity, status) " +
+	"VALUES ($1, $2, $3, $4, $5) ON CONFLICT (line_id) DO NOTHING"
+
+// TestOrderLinePlace tests that a line is placed once.
+func TestOrderLinePlace(t *testing.T) {
+	_, mock := newMockRepo(t)
+	lr := &orderLineRepo{DB: mock}
+	line := &pb.OrderLine{LineId: "l1", ProductId: testProductID, Quantity: 2}
+
+	mock.ExpectExec(insertOrderLine).
+		WithArgs("l1", "o1", testProductID, int32(2), OrderLinePlaced).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectExec(insertOrderLine).
+		WithArgs("l1", "o1", testProductID, int32(2), OrderLinePlaced).
+		WillReturnResult(pgxmock.NewResult("INSERT", 0))
+
+	for _, want := range []bool{true, false} {
+		placed, err := lr.Place(context.Background(), "o1", line)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if placed != want {
+			t.Errorf("Expected placed %t, got: %t", want, placed)
+		}
+	}
+}
+
+// TestOrderLineCancel tests that cancelling a placed line returns it as
+// placed, and cancelling an unknown line records it.
+func TestOrderLineCancel(t *testing.T) {
+	_, mock := newMockRepo(t)
+	lr := &orderLineRepo{DB: mock}
+	line := &pb.OrderLine{LineId: "l1", ProductId: testProductID, Quantity: 5}
+
+	mock.ExpectExec(insertOrderLine).
+		WithArgs("l1", "o1", testProductID, int32(5), OrderLineCancelled).
+		WillReturnResult(pgxmock.NewResult("INSERT", 0))
+	mock.ExpectQuery("UPDATE order_lines SET status = $1, updated_at = $2 WHERE line_id = $3 AND status = $4 "+
+		"RETURNING product_id, quantity").
+		WithArgs(OrderLineCancelled, pgxmock.AnyArg(), "l1", OrderLinePlaced).
+		WillReturnRows(mock.NewRows([]string{"product_id", "quantity"}).AddRow(testProductID, int32(2)))
+	mock.ExpectExec(insertOrderLine).
+		WithArgs("l2", "o1", testProductID, int32(1), OrderLineCancelled).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	placed, err := lr.Cancel(context.Background(), "o1", line)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if placed.GetQuantity() != 2 || placed.GetProductId() != testProductID {
+		t.Errorf("Expected the line as placed, got: %v", placed)
+	}
+
+	placed, err = lr.Cancel(context.Background(), "o1", &pb.OrderLine{LineId: "l2", ProductId: testProductID, Quantity: 1})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if placed != nil {
+		t.Errorf("Expected no line for a cancellation before placement, got: %v", placed)
+	}
+}
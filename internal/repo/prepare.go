@@ -0,0 +1,60 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/andro-kes/inventory_service/internal/repo/builder"
+	pb "github.com/andro-kes/inventory_service/proto"
+	"github.com/jackc/pgx/v5"
+)
+
+// Hot queries are prepared untagged, for calls outside an RPC, and tagged
+// with the RPC serving them.
+var (
+	getRoutes  = []string{"", pb.InventoryService_GetProduct_FullMethodName}
+	listRoutes = []string{"", pb.InventoryService_ListProducts_FullMethodName}
+)
+
+// hotQueries returns the SQL of the hottest repo queries: Get, and the
+// pages and count ListProducts runs with its default filter and page size.
+func hotQueries() []string {
+	available := true
+	filter := ListFilter{Available: &available}
+
+	var queries []string
+	for _, route := range getRoutes {
+		queries = append(queries, buildSQL(getQuery(route, productColumns, "")))
+	}
+	for _, route := range listRoutes {
+		queries = append(queries,
+			buildSQL(listAfterQuery(route, productColumns, defaultPageOrder, defaultPageSize, filter, nil)),
+			buildSQL(listAfterQuery(route, productColumns, defaultPageOrder, defaultPageSize, filter, []any{time.Time{}, ""})),
+			buildSQL(countQuery(route, filter)),
+		)
+	}
+	return queries
+}
+
+// buildSQL builds and releases b, dropping the arguments.
+func buildSQL(b *builder.SQLBuilder) string {
+	sql, _ := b.Build()
+	b.Release()
+	return sql
+}
+
+// PrepareStatements prepares the hot repo queries on conn. Use it as
+// pgxpool.Config.AfterConnect.
+//
+// Statements are named by their SQL, so pgx runs the prepared statement
+// whenever a repo method sends the same text and falls back to sending the
+// text on connections without it. Statements that fail to prepare, e.g.
+// before migrations are applied, are skipped.
+func PrepareStatements(ctx context.Context, conn *pgx.Conn) error {
+	for _, sql := range hotQueries() {
+		if _, err := conn.Prepare(ctx, sql, sql); err != nil && ctx.Err() != nil {
+			return err
+		}
+	}
+	return nil
+}
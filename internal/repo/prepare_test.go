@@ -0,0 +1,83 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// TestHotQueries tests that the prepared SQL is the text the repo sends, so
+// the prepared statements are actually used.
+func TestHotQueries(t *testing.T) {
+	pr, mock := newMockRepo(t)
+	hot := hotQueries()
+
+	mock.ExpectQuery(hot[0]).WithArgs("1").WillReturnRows(productRows(mock, "1"))
+	mock.ExpectQuery(hot[2]).WithArgs(0, "active").WillReturnRows(productRows(mock))
+	mock.ExpectQuery(hot[4]).WithArgs(0, "active").WillReturnRows(mock.NewRows([]string{"count"}).AddRow(int64(0)))
+
+	ctx := context.Background()
+	available := true
+	filter := ListFilter{Available: &available}
+
+	if _, err := pr.Get(ctx, "1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, _, err := pr.ListAfter(ctx, "", 0, filter, ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := pr.Count(ctx, filter); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+// BenchmarkGet compares Get sending its SQL text on every call with Get
+// running the statement prepared on connect. It needs a migrated database
+// in TEST_DB_URL.
+func BenchmarkGet(b *testing.B) {
+	dbURL := os.Getenv("TEST_DB_URL")
+	if dbURL == "" {
+		b.Skip("TEST_DB_URL is not set")
+	}
+
+	for _, tc := range []struct {
+		name    string
+		prepare bool
+	}{
+		{name: "text"},
+		{name: "prepared", prepare: true},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			ctx := context.Background()
+			cfg, err := pgx.ParseConfig(dbURL)
+			if err != nil {
+				b.Fatal(err)
+			}
+			// Without statement caching every text query is parsed and
+			// planned by the server.
+			cfg.DefaultQueryExecMode = pgx.QueryExecModeExec
+
+			conn, err := pgx.ConnectConfig(ctx, cfg)
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer conn.Close(ctx)
+
+			if tc.prepare {
+				if err := PrepareStatements(ctx, conn); err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			pr := &productRepo{DB: conn}
+			for b.Loop() {
+				if _, err := pr.Get(ctx, "00000000-0000-0000-0000-000000000000"); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/andro-kes/inventory_service/internal/repo/builder"
+)
+
+// RateRepo reads the exchange_rates table, the rates of the currencies
+// against the base currency of the service. The table is maintained
+// outside the service, e.g. by a job loading the rates of a central bank.
+type RateRepo interface {
+	// Rates returns how many units of each currency one unit of the base
+	// currency buys.
+	Rates(ctx context.Context) (map[string]*big.Rat, error)
+}
+
+type rateRepo struct {
+	DB Querier
+}
+
+// NewRateRepo returns a RateRepo running its queries on db.
+func NewRateRepo(ctx context.Context, db Querier) RateRepo {
+	return &tracingRateRepo{
+		next: &rateRepo{
+			DB: db,
+		},
+		tracer: newTracer("RateRepo"),
+	}
+}
+
+func (rr *rateRepo) Rates(ctx context.Context) (map[string]*big.Rat, error) {
+	// numeric is read as text to keep the rates exact.
+	sql, args := builder.NewSQLBuilder().
+		Select("currency_code", "rate::text").
+		From("exchange_rates").
+		Build()
+
+	rows, err := conn(ctx, rr.DB).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := make(map[string]*big.Rat)
+	for rows.Next() {
+		var currency, text string
+		if err := rows.Scan(&currency, &text); err != nil {
+			return nil, err
+		}
+		rate, ok := new(big.Rat).SetString(text)
+		if !ok {
+			return nil, fmt.Errorf("invalid exchange rate of %s: %q", currency, text)
+		}
+		rates[currency] = rate
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
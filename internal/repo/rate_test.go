@@ -0,0 +1,28 @@
+package repo
+
+import (
+	"context"
+	"testing"
+)
+
+// TestRateRates tests that exchange rates are read exactly.
+func TestRateRates(t *testing.T) {
+	_, mock := newMockRepo(t)
+	rr := &rateRepo{DB: mock}
+
+	mock.ExpectQuery("SELECT currency_code, rate::text FROM exchange_rates").
+		WillReturnRows(mock.NewRows([]string{"currency_code", "rate"}).
+			AddRow("USD", "0.0108").
+			AddRow("EUR", "0.0100"))
+
+	rates, err := rr.Rates(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(rates) != 2 {
+		t.Fatalf("Expected two rates, got: %v", rates)
+	}
+	if got := rates["USD"].RatString(); got != "27/2500" {
+		t.Errorf("Expected USD rate 27/2500, got: %s", got)
+	}
+}
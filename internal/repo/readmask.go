@@ -0,0 +1,117 @@
+package repo
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/andro-kes/inventory_service/internal/inverr"
+	pb "github.com/andro-kes/inventory_service/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// readMaskKey is the context key of the product fields to read.
+type readMaskKey struct{}
+
+// WithReadMask returns a copy of ctx in which Get, List and ListAfter
+// select only the id and the product fields in paths, e.g. name and price;
+// the other fields of the returned products are unset. Field names are
+// those of Product. Empty paths read every field; an unknown field is an
+// InvalidArgument error.
+func WithReadMask(ctx context.Context, paths []string) (context.Context, error) {
+	if len(paths) == 0 {
+		return ctx, nil
+	}
+	mask := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		if !readableField(path) {
+			return nil, inverr.InvalidField("read_mask", "unknown field in read_mask: %s", path)
+		}
+		mask[path] = true
+	}
+	return context.WithValue(ctx, readMaskKey{}, mask), nil
+}
+
+// readableField reports whether path is a Product field stored in the
+// products columns.
+func readableField(path string) bool {
+	fields := (&pb.Product{}).ProtoReflect().Descriptor().Fields()
+	if fields.ByName(protoreflect.Name(path)) == nil {
+		return false
+	}
+	for _, column := range columnsOf(path) {
+		if !slices.Contains(productColumns, column) {
+			return false
+		}
+	}
+	return true
+}
+
+// readMask returns the fields to read in ctx, or nil for all of them.
+func readMask(ctx context.Context) map[string]bool {
+	mask, _ := ctx.Value(readMaskKey{}).(map[string]bool)
+	return mask
+}
+
+// readColumns returns the columns to select for the read mask of ctx, in
+// productColumns order. The id and the required columns, e.g. the keyset
+// of a page, are always selected.
+func readColumns(ctx context.Context, required ...string) []string {
+	mask := readMask(ctx)
+	if mask == nil {
+		return productColumns
+	}
+	masked := make(map[string]bool, len(mask))
+	for path := range mask {
+		for _, column := range columnsOf(path) {
+			masked[column] = true
+		}
+	}
+	columns := make([]string, 0, len(masked)+len(required)+1)
+	for _, column := range productColumns {
+		if masked[column] || column == "id" || slices.Contains(required, column) {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// maskProduct clears the fields of p outside the read mask of ctx, such as
+// keyset columns selected for paging only.
+func maskProduct(ctx context.Context, p *pb.Product) *pb.Product {
+	mask := readMask(ctx)
+	if mask == nil {
+		return p
+	}
+	m := p.ProtoReflect()
+	var unread []protoreflect.FieldDescriptor
+	m.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		if name := string(fd.Name()); !mask[name] && name != "id" {
+			unread = append(unread, fd)
+		}
+		return true
+	})
+	for _, fd := range unread {
+		m.Clear(fd)
+	}
+	return p
+}
+
+// ReadsField reports whether the read mask of ctx includes the Product
+// field, e.g. for fields filled outside the products columns.
+func ReadsField(ctx context.Context, field string) bool {
+	mask := readMask(ctx)
+	return mask == nil || mask[field]
+}
+
+// ReadMaskPaths returns the sorted fields of the read mask of ctx, or nil
+// when every field is read.
+func ReadMaskPaths(ctx context.Context) []string {
+	mask := readMask(ctx)
+	if mask == nil {
+		return nil
+	}
+	paths := slices.Collect(maps.Keys(mask))
+	slices.Sort(paths)
+	return paths
+}
@@ -0,0 +1,252 @@
+package repo
+
+import (
+	"context"
+	"encoding/base64"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andro-kes/inventory_service/internal/repo/builder"
+	pb "github.com/andro-kes/inventory_service/proto"
+)
+
+// outflowReasons are the movement reasons counted as demand by the
+// reorder suggestions: sales, not losses or corrections.
+var outflowReasons = []string{ReasonDecrement, ReasonSold, ReasonReservation}
+
+// ReorderPolicy sizes the reorder suggestions.
+type ReorderPolicy struct {
+	// Window is how far back the outflow of a product is averaged.
+	Window time.Duration
+	// Cover is how long the suggested order should last at that outflow.
+	Cover time.Duration
+}
+
+// DefaultReorderPolicy averages four weeks of outflow and orders for two.
+var DefaultReorderPolicy = ReorderPolicy{Window: 28 * 24 * time.Hour, Cover: 14 * 24 * time.Hour}
+
+// ReorderSuggestion is a product to replenish, as computed by
+// ReorderRepo.Refresh.
+type ReorderSuggestion struct {
+	ProductID       string `db:"product_id"`
+	Quantity        int32  `db:"quantity"`
+	ReorderPoint    int32  `db:"reorder_point"`
+	ReorderQuantity int32  `db:"reorder_quantity"`
+	// DailyOutflow is the average of the units sold per day over the
+	// window of the policy.
+	DailyOutflow float64 `db:"daily_outflow"`
+	// DaysOfCover is how many days Quantity lasts at DailyOutflow, +Inf
+	// without outflow.
+	DaysOfCover       float64   `db:"days_of_cover"`
+	SuggestedQuantity int32     `db:"suggested_quantity"`
+	ComputedAt        time.Time `db:"computed_at"`
+}
+
+// suggestReorder sizes the order of a product with quantity units left
+// that sold outflow units over the window of policy: enough to cover the
+// outflow for policy.Cover on top of the reorder point, and at least the
+// reorder quantity.
+func suggestReorder(s *ReorderSuggestion, outflow int64, policy ReorderPolicy) {
+	s.DailyOutflow = float64(outflow) / policy.Window.Hours() * 24
+	s.DaysOfCover = math.Inf(1)
+	if s.DailyOutflow > 0 {
+		s.DaysOfCover = float64(s.Quantity) / s.DailyOutflow
+	}
+	demand := int64(math.Ceil(s.DailyOutflow * policy.Cover.Hours() / 24))
+	need := min(demand+int64(s.ReorderPoint)-int64(s.Quantity), math.MaxInt32)
+	s.SuggestedQuantity = max(s.ReorderQuantity, int32(need))
+}
+
+// ReorderRepo keeps the reorder suggestions: the active products at or
+// below their reorder point, with the quantity to order.
+type ReorderRepo interface {
+	// Refresh recomputes the suggestions with policy from the stock
+	// ledger and returns their number. Products with a reorder_quantity
+	// of 0 are left out.
+	Refresh(ctx context.Context, policy ReorderPolicy) (int64, error)
+	// List returns a page of the suggestions, the lowest DaysOfCover
+	// first, following the suggestion of pageToken, and the token of the
+	// next page, empty on the last one. A non-positive pageSize means
+	// defaultPageSize, larger ones are capped at maxPageSize. Tokens it
+	// did not issue fail with ErrInvalidCursor.
+	List(ctx context.Context, pageToken string, pageSize int32) ([]*ReorderSuggestion, string, error)
+}
+
+type reorderRepo struct {
+	DB Querier
+}
+
+// NewReorderRepo returns a ReorderRepo running its queries on db.
+func NewReorderRepo(ctx context.Context, db Querier) ReorderRepo {
+	return &tracingReorderRepo{
+		next: &reorderRepo{
+			DB: db,
+		},
+		tracer: newTracer("ReorderRepo"),
+	}
+}
+
+func (rr *reorderRepo) db(ctx context.Context) Querier {
+	return conn(ctx, rr.DB)
+}
+
+// reorderColumns lists the reorder_suggestions columns in
+// ReorderSuggestion order.
+var reorderColumns = builder.StructColumns(ReorderSuggestion{})
+
+func (rr *reorderRepo) Refresh(ctx context.Context, policy ReorderPolicy) (int64, error) {
+	now := time.Now()
+	outflow := newQuery(ctx).
+		Select("product_id", "-SUM(delta) AS units").
+		From("stock_movements").
+		Where("delta < 0").
+		Where("reason = ANY(?)", outflowReasons).
+		Where("created_at > ?", now.Add(-policy.Window)).
+		GroupBy("product_id")
+	sql, args := productsQuery(ctx).
+		With("outflow", outflow).
+		Select("products.id", "products.quantity", "products.reorder_point", "products.reorder_quantity", "COALESCE(o.units, 0)").
+		From("products").
+		LeftJoin("outflow o", "o.product_id = products.id").
+		Where("products.status = ?", statusColumn(pb.Product_ACTIVE)).
+		Where("products.reorder_quantity > 0").
+		Where("products.quantity <= products.reorder_point").
+		Build()
+
+	tx, err := rr.db(ctx).Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		return 0, err
+	}
+	var suggestions [][]any
+	for rows.Next() {
+		s := ReorderSuggestion{ComputedAt: now}
+		var units int64
+		if err := rows.Scan(&s.ProductID, &s.Quantity, &s.ReorderPoint, &s.ReorderQuantity, &units); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		suggestReorder(&s, units, policy)
+		suggestions = append(suggestions, []any{
+			s.ProductID, s.Quantity, s.ReorderPoint, s.ReorderQuantity,
+			s.DailyOutflow, s.DaysOfCover, s.SuggestedQuantity, s.ComputedAt,
+		})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	// Upserting and then dropping what this run did not compute lets
+	// replicas refresh concurrently.
+	for batch := range slices.Chunk(suggestions, createBatchSize) {
+		b := newQuery(ctx).
+			Insert("reorder_suggestions").
+			Columns(reorderColumns...).
+			ValuesRows(batch).
+			OnConflict("product_id")
+		for _, col := range reorderColumns[1:] {
+			b.DoUpdateSet(col + " = EXCLUDED." + col)
+		}
+		sql, args := b.Build()
+		if _, err := tx.Exec(ctx, sql, args...); err != nil {
+			return 0, err
+		}
+	}
+	sql, args = newQuery(ctx).
+		Delete().
+		From("reorder_suggestions").
+		Where("computed_at < ?", now).
+		Build()
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return int64(len(suggestions)), nil
+}
+
+func (rr *reorderRepo) List(ctx context.Context, pageToken string, pageSize int32) ([]*ReorderSuggestion, string, error) {
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	pageSize = min(pageSize, maxPageSize)
+
+	// One extra row tells whether there is a next page.
+	b := newQuery(ctx).
+		Select(reorderColumns...).
+		From("reorder_suggestions").
+		OrderBy("days_of_cover").
+		OrderBy("product_id").
+		Limit(int(pageSize) + 1)
+	if pageToken != "" {
+		days, id, err := decodeReorderToken(pageToken)
+		if err != nil {
+			return nil, "", err
+		}
+		b.Where("(days_of_cover, product_id) > (?, ?)", days, id)
+	}
+	sql, args := b.Build()
+
+	rows, err := rr.db(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	suggestions := make([]*ReorderSuggestion, 0)
+	for rows.Next() {
+		var s ReorderSuggestion
+		if err := rows.Scan(&s.ProductID, &s.Quantity, &s.ReorderPoint, &s.ReorderQuantity,
+			&s.DailyOutflow, &s.DaysOfCover, &s.SuggestedQuantity, &s.ComputedAt); err != nil {
+			return nil, "", err
+		}
+		suggestions = append(suggestions, &s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	if len(suggestions) <= int(pageSize) {
+		return suggestions, "", nil
+	}
+	suggestions = suggestions[:pageSize]
+	last := suggestions[len(suggestions)-1]
+	return suggestions, encodeReorderToken(last.DaysOfCover, last.ProductID), nil
+}
+
+// encodeReorderToken returns an opaque page token pointing after the
+// suggestion of product id with days of cover.
+func encodeReorderToken(days float64, id string) string {
+	raw := "reorder|" + strconv.FormatFloat(days, 'g', -1, 64) + "|" + id
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeReorderToken reverses encodeReorderToken.
+func decodeReorderToken(token string) (float64, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, "", ErrInvalidCursor
+	}
+	parts := strings.Split(string(raw), "|")
+	if len(parts) != 3 || parts[0] != "reorder" || parts[2] == "" {
+		return 0, "", ErrInvalidCursor
+	}
+	days, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil || math.IsNaN(days) {
+		return 0, "", ErrInvalidCursor
+	}
+	return days, parts[2], nil
+}
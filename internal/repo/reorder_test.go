@@ -0,0 +1,120 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+func TestSuggestReorder(t *testing.T) {
+	policy := ReorderPolicy{Window: 10 * 24 * time.Hour, Cover: 7 * 24 * time.Hour}
+	cases := []struct {
+		name                 string
+		quantity, point, min int32
+		outflow              int64
+		daily, days          float64
+		suggested            int32
+	}{
+		// 3 a day for a week on top of the reorder point of 5.
+		{"selling", 2, 5, 10, 30, 3, 2.0 / 3, 24},
+		// The reorder quantity is the least order.
+		{"slow", 4, 5, 10, 5, 0.5, 8, 10},
+		{"no outflow", 0, 0, 6, 0, 0, math.Inf(1), 6},
+	}
+	for _, tc := range cases {
+		s := ReorderSuggestion{Quantity: tc.quantity, ReorderPoint: tc.point, ReorderQuantity: tc.min}
+		suggestReorder(&s, tc.outflow, policy)
+		if s.DailyOutflow != tc.daily || s.DaysOfCover != tc.days || s.SuggestedQuantity != tc.suggested {
+			t.Errorf("%s: expected %v a day, %v days and %d to order, got: %+v", tc.name, tc.daily, tc.days, tc.suggested, s)
+		}
+	}
+}
+
+// TestReorderRefresh tests that the suggestions are upserted and the
+// stale ones dropped in one transaction.
+func TestReorderRefresh(t *testing.T) {
+	_, mock := newMockRepo(t)
+	rr := &reorderRepo{DB: mock}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("WITH outflow AS (SELECT product_id, -SUM(delta) AS units FROM stock_movements "+
+		"WHERE delta < 0 AND reason = ANY($1) AND created_at > $2 GROUP BY product_id) "+
+		"SELECT products.id, products.quantity, products.reorder_point, products.reorder_quantity, COALESCE(o.units, 0) "+
+		"FROM products LEFT JOIN outflow o ON o.product_id = products.id "+
+		"WHERE products.status = $3 AND products.reorder_quantity > 0 AND products.quantity <= products.reorder_point "+
+		"AND products.deleted_at IS NULL").
+		WithArgs(outflowReasons, pgxmock.AnyArg(), "active").
+		WillReturnRows(mock.NewRows([]string{"id", "quantity", "reorder_point", "reorder_quantity", "units"}).
+			AddRow(testProductID, int32(2), int32(5), int32(10), int64(56)))
+	mock.ExpectExec("INSERT INTO reorder_suggestions (product_id, quantity, reorder_point, reorder_quantity, "+
+		"daily_outflow, days_of_cover, suggested_quantity, computed_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) "+
+		"ON CONFLICT (product_id) DO UPDATE SET quantity = EXCLUDED.quantity, reorder_point = EXCLUDED.reorder_point, "+
+		"reorder_quantity = EXCLUDED.reorder_quantity, daily_outflow = EXCLUDED.daily_outflow, "+
+		"days_of_cover = EXCLUDED.days_of_cover, suggested_quantity = EXCLUDED.suggested_quantity, "+
+		"computed_at = EXCLUDED.computed_at").
+		WithArgs(testProductID, int32(2), int32(5), int32(10), 2.0, 1.0, int32(31), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectExec("DELETE FROM reorder_suggestions WHERE computed_at < $1").
+		WithArgs(pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("DELETE", 3))
+	mock.ExpectCommit()
+
+	n, err := rr.Refresh(context.Background(), DefaultReorderPolicy)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("Expected 1 suggestion, got: %d", n)
+	}
+}
+
+// TestReorderList tests paging through the suggestions, the soonest to
+// run out first.
+func TestReorderList(t *testing.T) {
+	_, mock := newMockRepo(t)
+	rr := &reorderRepo{DB: mock}
+	now := time.Now()
+	columns := []string{"product_id", "quantity", "reorder_point", "reorder_quantity",
+		"daily_outflow", "days_of_cover", "suggested_quantity", "computed_at"}
+	const selectSuggestions = "SELECT product_id, quantity, reorder_point, reorder_quantity, daily_outflow, " +
+		"days_of_cover, suggested_quantity, computed_at FROM reorder_suggestions "
+
+	mock.ExpectQuery(selectSuggestions + "ORDER BY days_of_cover, product_id LIMIT 2").
+		WillReturnRows(mock.NewRows(columns).
+			AddRow("a", int32(1), int32(5), int32(10), 2.0, 0.5, int32(33), now).
+			AddRow("b", int32(0), int32(5), int32(10), 0.0, math.Inf(1), int32(10), now))
+
+	suggestions, next, err := rr.List(context.Background(), "", 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(suggestions) != 1 || suggestions[0].ProductID != "a" || next == "" {
+		t.Fatalf("Expected the first suggestion and a next page, got %v and %q", suggestions, next)
+	}
+
+	mock.ExpectQuery(selectSuggestions+"WHERE (days_of_cover, product_id) > ($1, $2) ORDER BY days_of_cover, product_id LIMIT 2").
+		WithArgs(0.5, "a").
+		WillReturnRows(mock.NewRows(columns).
+			AddRow("b", int32(0), int32(5), int32(10), 0.0, math.Inf(1), int32(10), now))
+
+	suggestions, next, err = rr.List(context.Background(), next, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(suggestions) != 1 || next != "" || !math.IsInf(suggestions[0].DaysOfCover, 1) {
+		t.Errorf("Expected the last suggestion without outflow, got %v and %q", suggestions, next)
+	}
+
+	if days, id, err := decodeReorderToken(encodeReorderToken(math.Inf(1), "b")); err != nil || !math.IsInf(days, 1) || id != "b" {
+		t.Errorf("Expected the token of b to round-trip, got %v, %q, %v", days, id, err)
+	}
+	for _, token := range []string{"!", encodeCursor(defaultPageOrder, ListFilter{}, time.Now(), "1")} {
+		if _, _, err := rr.List(context.Background(), token, 0); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("Expected ErrInvalidCursor for %q, got: %v", token, err)
+		}
+	}
+}
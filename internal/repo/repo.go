@@ -2,44 +2,218 @@ package repo
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/andro-kes/inventory_service/internal/auth"
+	"github.com/andro-kes/inventory_service/internal/inverr"
 	"github.com/andro-kes/inventory_service/internal/repo/builder"
 	pb "github.com/andro-kes/inventory_service/proto"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/fieldmaskpb"
-	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type ProductRepo interface {
 	Create(ctx context.Context, p *pb.Product) (*pb.Product, error)
+	CreateIdempotent(ctx context.Context, key string, ttl time.Duration, p *pb.Product) (*pb.Product, error)
+	CreateMany(ctx context.Context, ps []*pb.Product) ([]*pb.Product, error)
+	CopyFrom(ctx context.Context, ps []*pb.Product, progress func(copied int64)) (int64, error)
 	Delete(ctx context.Context, id string) error
-	List(ctx context.Context, prevSize, pageSize int32, filter, orderBy string) ([]*pb.Product, error)
+	DeleteWhere(ctx context.Context, filter ListFilter) ([]string, error)
+	Restore(ctx context.Context, id string) (*pb.Product, error)
+	Purge(ctx context.Context, olderThan time.Duration) (int64, error)
+	List(ctx context.Context, prevSize, pageSize int32, filter ListFilter, orderBy string) ([]*pb.Product, error)
+	ListAfter(ctx context.Context, cursor string, pageSize int32, filter ListFilter, orderBy string) ([]*pb.Product, string, error)
+	Count(ctx context.Context, filter ListFilter) (int64, error)
+	ListAll(ctx context.Context, filter ListFilter, fn func(*pb.Product) error) error
 	Update(ctx context.Context, p *pb.Product, mask *fieldmaskpb.FieldMask) (*pb.Product, error)
 	Get(ctx context.Context, id string) (*pb.Product, error)
+	GetBySKU(ctx context.Context, sku string) (*pb.Product, error)
+	GetByBarcode(ctx context.Context, barcode string) (*pb.Product, error)
+	UpsertBySKU(ctx context.Context, p *pb.Product) (*pb.Product, error)
+	DecrementQuantity(ctx context.Context, id string, delta int32) (int32, error)
+	ReserveStock(ctx context.Context, r *Reservation) (*Reservation, error)
+	GetReservation(ctx context.Context, id string) (*Reservation, error)
+	ConfirmReservation(ctx context.Context, id string) (*Reservation, int32, error)
+	ReleaseReservation(ctx context.Context, id string) (*Reservation, error)
+	ReleaseExpired(ctx context.Context, limit int) (int64, error)
+	PurgeIdempotencyKeys(ctx context.Context, limit int) (int64, error)
+	StockOf(ctx context.Context, ids []string) (map[string]Stock, error)
+	Translations(ctx context.Context, ids []string) (map[string][]*pb.LocalizedText, error)
+}
+
+// updatableFields are the Product fields Update accepts in the field mask;
+// see columnsOf for their columns.
+var updatableFields = map[string]bool{
+	"name":         true,
+	"description":  true,
+	"price":        true,
+	"price_money":  true,
+	"quantity":     true,
+	"tags":         true,
+	"available":    true,
+	"status":       true,
+	"attributes":   true,
+	"translations": true,
+	"category_id":  true,
+	"sku":          true,
+	"barcode":      true,
+
+	"reorder_point":    true,
+	"reorder_quantity": true,
+}
+
+// listSortColumns maps the fields List and ListAfter accept in orderBy to
+// their columns. Prices sort by amount, whatever the currency.
+var listSortColumns = map[string]string{
+	"price":      "price_cents",
+	"created_at": "created_at",
+}
+
+// newQuery starts a query tagged with the calling RPC method, so that
+// pg_stat_statements and APM tools can attribute it.
+func newQuery(ctx context.Context, opts ...builder.Option) *builder.SQLBuilder {
+	return routeQuery(route(ctx), opts...)
+}
+
+// route returns the RPC method of ctx, if any.
+func route(ctx context.Context) string {
+	method, _ := grpc.Method(ctx)
+	return method
+}
+
+// routeQuery starts a query tagged with route, or untagged if it is empty.
+func routeQuery(route string, opts ...builder.Option) *builder.SQLBuilder {
+	b := builder.NewSQLBuilder(opts...)
+	if route != "" {
+		b.CommentTags(map[string]string{"route": route})
+	}
+	return b
+}
+
+// actor returns the authenticated principal of ctx, or nil for anonymous
+// requests, for the created_by and updated_by columns.
+func actor(ctx context.Context) *string {
+	return nullable(auth.Principal(ctx))
+}
+
+// createBatchSize bounds the rows of one INSERT in CreateMany, keeping its
+// parameters well under the PostgreSQL limit of 65535.
+const createBatchSize = 1000
+
+// copyChunkSize is the number of rows CopyFrom sends per COPY.
+const copyChunkSize = 10000
+
+// defaultPageSize is the page size of ListAfter when none is requested.
+const defaultPageSize = 50
+
+// maxPageSize caps the page size of ListAfter.
+const maxPageSize = 1000
+
+// listAllFetchSize is the number of rows ListAll fetches from its cursor
+// at a time.
+const listAllFetchSize = 1000
+
+// productsQuery starts a query on the products table. Deleted products
+// have deleted_at set and are hidden unless the query is Unscoped.
+func productsQuery(ctx context.Context) *builder.SQLBuilder {
+	return productsRouteQuery(route(ctx))
+}
+
+// productsRouteQuery is productsQuery for an explicit route.
+func productsRouteQuery(route string) *builder.SQLBuilder {
+	return routeQuery(route, builder.WithSoftDelete("deleted_at"))
 }
 
 type productRepo struct {
-	Pool *pgxpool.Pool
+	DB Querier
+	// StatementTimeout bounds the read queries; zero means no bound.
+	StatementTimeout time.Duration
+}
+
+// Option configures the repo returned by NewProductRepo.
+type Option func(*productRepo)
+
+// WithStatementTimeout bounds Get, List, ListAfter and Count to d, so a
+// pathological filter cannot hold a pooled connection for minutes. A query
+// running longer is cancelled on the server and fails with
+// codes.DeadlineExceeded. Zero means no bound.
+func WithStatementTimeout(d time.Duration) Option {
+	return func(pr *productRepo) {
+		pr.StatementTimeout = d
+	}
 }
 
-func NewProductRepo(ctx context.Context, pool *pgxpool.Pool) ProductRepo {
-	return &productRepo{
-		Pool: pool,
+// NewProductRepo returns a ProductRepo running its queries on db: a
+// *pgxpool.Pool, a *pgx.Conn or a pgx.Tx. Operations failing with
+// transient errors are retried with DefaultRetryPolicy, and every call is
+// traced with an OpenTelemetry span.
+func NewProductRepo(ctx context.Context, db Querier, opts ...Option) ProductRepo {
+	pr := &productRepo{DB: db}
+	for _, opt := range opts {
+		opt(pr)
+	}
+
+	return &tracingRepo{
+		next: &retryRepo{
+			next:   pr,
+			policy: DefaultRetryPolicy,
+		},
+		tracer: newTracer("ProductRepo"),
 	}
 }
 
+// db returns the transaction of ctx, if any, or pr.DB.
+func (pr *productRepo) db(ctx context.Context) Querier {
+	return conn(ctx, pr.DB)
+}
+
+// withStatementTimeout runs query with ctx bounded by StatementTimeout.
+// pgx cancels the running statement when the bound expires, and the error
+// is then reported as codes.DeadlineExceeded.
+func (pr *productRepo) withStatementTimeout(ctx context.Context, query func(ctx context.Context) error) error {
+	if pr.StatementTimeout <= 0 {
+		return query(ctx)
+	}
+
+	qctx, cancel := context.WithTimeout(ctx, pr.StatementTimeout)
+	defer cancel()
+
+	err := query(qctx)
+	if err != nil && ctx.Err() == nil && errors.Is(qctx.Err(), context.DeadlineExceeded) {
+		return status.Errorf(codes.DeadlineExceeded, "query exceeded statement timeout of %s", pr.StatementTimeout)
+	}
+	return err
+}
+
 func (pr *productRepo) Create(ctx context.Context, p *pb.Product) (*pb.Product, error) {
-	sql, args := builder.NewSQLBuilder().
-	Insert("products").
-	Columns("id", "name", "description", "price", "quantity", "tags", "available", "created_at", "updated_at").
-	Values(p.Id, p.Name, p.Description, p.Price, p.Quantity, p.Tags, p.Available, time.Now(), time.Now()).
-	Returning("id", "name", "description", "price", "quantity", "tags", "available", "created_at", "updated_at").
-	Build()
-
-	tx, err := pr.Pool.Begin(ctx)
+	if err := validateProduct(p); err != nil {
+		return nil, err
+	}
+	if err := validateTranslations(p); err != nil {
+		return nil, err
+	}
+
+	row := newProductRow(p)
+	row.CreatedAt = time.Now()
+	row.UpdatedAt = row.CreatedAt
+	row.CreatedBy = actor(ctx)
+	row.UpdatedBy = row.CreatedBy
+
+	sql, args := productsQuery(ctx).
+		Insert("products").
+		InsertStruct(row).
+		Returning(productColumns...).
+		Build()
+
+	tx, err := pr.db(ctx).Begin(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -48,41 +222,140 @@ func (pr *productRepo) Create(ctx context.Context, p *pb.Product) (*pb.Product,
 		_ = tx.Rollback(ctx)
 	}()
 
-	var id, name, description string
-	var price float64
-	var quantity int32
-	var tags []string
-	var available bool
-	var createdAt, updatedAt time.Time
+	var created productRow
+	err = created.scan(tx.QueryRow(ctx, sql, args...))
+	if err != nil {
+		return nil, duplicateKeyError(err, p)
+	}
+	if len(p.GetTranslations()) > 0 {
+		if err = saveTranslations(ctx, tx, created.ID, p.GetTranslations()); err != nil {
+			return nil, err
+		}
+	}
+	if err = notifyChange(ctx, tx, created.ID, OpCreate); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	product := created.toProto()
+	product.Translations = p.GetTranslations()
+	return product, nil
+}
+
+// CreateMany inserts products in one transaction with multi-row INSERTs of
+// up to createBatchSize rows and returns the created rows.
+func (pr *productRepo) CreateMany(ctx context.Context, ps []*pb.Product) ([]*pb.Product, error) {
+	created := make([]*pb.Product, 0, len(ps))
+	if len(ps) == 0 {
+		return created, nil
+	}
+	for _, p := range ps {
+		if err := validateProduct(p); err != nil {
+			return nil, err
+		}
+	}
 
-	row := tx.QueryRow(ctx, sql, args...)
-	err = row.Scan(&id, &name, &description, &price, &quantity, &tags, &available, &createdAt, &updatedAt)
+	tx, err := pr.db(ctx).Begin(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	now, by := time.Now(), actor(ctx)
+	for batch := range slices.Chunk(ps, createBatchSize) {
+		b := productsQuery(ctx).Insert("products").Returning(productColumns...)
+		for _, p := range batch {
+			row := newProductRow(p)
+			row.CreatedAt = now
+			row.UpdatedAt = now
+			row.CreatedBy = by
+			row.UpdatedBy = by
+			b.InsertStruct(row)
+		}
+		sql, args := b.Build()
+		b.Release()
+
+		rows, err := tx.Query(ctx, sql, args...)
+		if err != nil {
+			return nil, err
+		}
+		products, err := collectProducts(rows, len(batch), productColumns)
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, products...)
+	}
+
 	if err = tx.Commit(ctx); err != nil {
 		return nil, err
 	}
 
-	return &pb.Product{
-		Id:          id,
-		Name:        name,
-		Description: description,
-		Price:       price,
-		Quantity:    quantity,
-		Tags:        tags,
-		Available:   available,
-		CreatedAt:   timestamppb.New(createdAt),
-		UpdatedAt:   timestamppb.New(updatedAt),
-	}, nil
+	return created, nil
+}
+
+// CopyFrom bulk-loads products with the COPY protocol in chunks of
+// copyChunkSize rows, all in one transaction. progress, if not nil, is
+// called with the number of rows copied so far after every chunk. It
+// returns the number of rows copied.
+func (pr *productRepo) CopyFrom(ctx context.Context, ps []*pb.Product, progress func(copied int64)) (int64, error) {
+	for _, p := range ps {
+		if err := validateProduct(p); err != nil {
+			return 0, err
+		}
+	}
+
+	tx, err := pr.db(ctx).Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	now, by := time.Now(), actor(ctx)
+	var copied int64
+	for chunk := range slices.Chunk(ps, copyChunkSize) {
+		rows := make([][]any, len(chunk))
+		for i, p := range chunk {
+			row := newProductRow(p)
+			row.CreatedAt = now
+			row.UpdatedAt = now
+			row.CreatedBy = by
+			row.UpdatedBy = by
+			rows[i] = row.values()
+		}
+
+		n, err := tx.CopyFrom(ctx, pgx.Identifier{"products"}, productColumns, pgx.CopyFromRows(rows))
+		if err != nil {
+			return copied, err
+		}
+		copied += n
+		if progress != nil {
+			progress(copied)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return copied, nil
 }
 
+// Delete marks the product as deleted; Restore brings it back until Purge
+// removes it.
 func (pr *productRepo) Delete(ctx context.Context, id string) error {
-	sql, args := builder.NewSQLBuilder().
+	sql, args := productsQuery(ctx).
 		Delete().From("products").Where("id = ?", id).Build()
 
-	tx, err := pr.Pool.Begin(ctx)
+	tx, err := pr.db(ctx).Begin(ctx)
 	if err != nil {
 		return err
 	}
@@ -90,10 +363,16 @@ func (pr *productRepo) Delete(ctx context.Context, id string) error {
 		_ = tx.Rollback(ctx)
 	}()
 
-	_, err = tx.Exec(ctx, sql, args...)
+	tag, err := tx.Exec(ctx, sql, args...)
 	if err != nil {
 		return err
 	}
+	if tag.RowsAffected() == 0 {
+		return status.Errorf(codes.NotFound, "product not found: %s", id)
+	}
+	if err = notifyChange(ctx, tx, id, OpDelete); err != nil {
+		return err
+	}
 
 	if err = tx.Commit(ctx); err != nil {
 		return err
@@ -102,62 +381,321 @@ func (pr *productRepo) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-func (pr *productRepo) List(ctx context.Context, prevSize, pageSize int32, filter, orderBy string) ([]*pb.Product, error) {
-	ob := "created_at DESC"
-	switch orderBy {
-	case "price", "price DESC", "price ASC",
-		"created_at", "created_at DESC", "created_at ASC":
-		ob = orderBy
+// DeleteWhere soft-deletes all products matching filter in one statement
+// and returns the ids of the deleted ones. Unlike List, products out of
+// stock match too. An empty filter is rejected rather than deleting the
+// whole catalog.
+func (pr *productRepo) DeleteWhere(ctx context.Context, filter ListFilter) ([]string, error) {
+	if filter.isZero() {
+		return nil, status.Error(codes.InvalidArgument, "filter must not be empty")
 	}
 
-	b := builder.NewSQLBuilder().
-		Select("id", "name", "description", "price", "quantity", "tags", "available", "created_at", "updated_at").
+	b := productsQuery(ctx).
+		Delete().
 		From("products").
-		Where("quantity > ?", 0).
-		Where("available = ?", true).
-		OrderBy(ob).
+		Returning("id")
+	filter.where(b)
+	sql, args := b.Build()
+
+	tx, err := pr.db(ctx).Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, err
+	}
+	if err = notifyChanges(ctx, tx, ids, OpDelete); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// Restore undeletes a product removed with Delete and returns it.
+func (pr *productRepo) Restore(ctx context.Context, id string) (*pb.Product, error) {
+	sql, args := productsQuery(ctx).
+		Unscoped().
+		Update("products").
+		SetNull("deleted_at").
+		Set("updated_at = ?", time.Now()).
+		Where("id = ?", id).
+		WhereNotNull("deleted_at").
+		Returning(productColumns...).
+		Build()
+
+	tx, err := pr.db(ctx).Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var restored productRow
+	if err := restored.scan(tx.QueryRow(ctx, sql, args...)); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "deleted product not found: %s", id)
+		}
+		return nil, err
+	}
+	if err = notifyChange(ctx, tx, id, OpRestore); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return restored.toProto(), nil
+}
+
+// Purge physically removes products deleted more than olderThan ago and
+// returns how many were removed.
+func (pr *productRepo) Purge(ctx context.Context, olderThan time.Duration) (int64, error) {
+	sql, args := productsQuery(ctx).
+		Unscoped().
+		Delete().
+		From("products").
+		Where("deleted_at < ?", time.Now().Add(-olderThan)).
+		Build()
+
+	tag, err := pr.db(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+func (pr *productRepo) List(ctx context.Context, prevSize, pageSize int32, filter ListFilter, orderBy string) ([]*pb.Product, error) {
+	col, dir := "created_at", builder.Desc
+	if fields := strings.Fields(orderBy); len(fields) == 1 || len(fields) == 2 {
+		d, err := builder.ParseDirection(strings.Join(fields[1:], ""))
+		if column, ok := listSortColumns[fields[0]]; err == nil && ok {
+			col, dir = column, d
+		}
+	}
+
+	columns := readColumns(ctx)
+	b := productsQuery(ctx).
+		Select(columns...).
+		From("products").
+		OrderBy(col, dir).
 		Offset(int(prevSize)).
 		Limit(int(pageSize))
+	filter.apply(b)
+
+	sql, args := b.Build()
+	b.Release()
 
-	if filter != "" {
-		b.Where("tags @> ARRAY[?]::text[]", filter)
+	var products []*pb.Product
+	err := pr.withStatementTimeout(ctx, func(ctx context.Context) error {
+		var err error
+		products, err = pr.queryProducts(ctx, int(pageSize), columns, sql, args)
+		return err
+	})
+	return products, err
+}
+
+// ListAfter returns the products following cursor in orderBy, "<column>
+// [asc|desc]" with a column of listSortColumns, newest first if empty.
+// Pages are keyed on the sort column and id, so deep pages cost as much as
+// the first one. An empty cursor starts at the first product; the returned
+// cursor is empty on the last page and only valid for the same orderBy and
+// filter. A non-positive pageSize means defaultPageSize, larger ones are
+// capped at maxPageSize.
+func (pr *productRepo) ListAfter(ctx context.Context, cursor string, pageSize int32, filter ListFilter, orderBy string) ([]*pb.Product, string, error) {
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	pageSize = min(pageSize, maxPageSize)
+
+	order, err := parsePageOrder(orderBy)
+	if err != nil {
+		return nil, "", err
 	}
 
+	var after []any
+	if cursor != "" {
+		value, id, err := decodeCursor(cursor, order, filter)
+		if err != nil {
+			return nil, "", err
+		}
+		after = []any{value, id}
+	}
+
+	columns := readColumns(ctx, order.column)
+	b := listAfterQuery(route(ctx), columns, order, pageSize, filter, after)
 	sql, args := b.Build()
+	b.Release()
 
-	rows, err := pr.Pool.Query(ctx, sql, args...)
+	// One extra row tells whether there is a next page.
+	var products []*pb.Product
+	err = pr.withStatementTimeout(ctx, func(ctx context.Context) error {
+		var err error
+		products, err = pr.queryProducts(ctx, int(pageSize)+1, columns, sql, args)
+		return err
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	var next string
+	if len(products) > int(pageSize) {
+		products = products[:pageSize]
+		last := products[len(products)-1]
+		next = encodeCursor(order, filter, order.value(last), last.GetId())
+	}
+	// The keyset is read for the cursor even outside the mask.
+	for _, p := range products {
+		maskProduct(ctx, p)
+	}
+	return products, next, nil
+}
+
+// listAfterQuery selects columns of the page of ListAfter in order
+// following the (sort value, id) position after, or the first page if
+// after is nil.
+func listAfterQuery(route string, columns []string, order pageOrder, pageSize int32, filter ListFilter, after []any) *builder.SQLBuilder {
+	b := productsRouteQuery(route).
+		Select(columns...).
+		From("products").
+		OrderBy(order.column, order.dir).
+		OrderBy("id", order.dir).
+		Limit(int(pageSize) + 1)
+	filter.apply(b)
+
+	if after != nil {
+		order.after(b, after[0], after[1].(string))
+	}
+	return b
+}
+
+// Count returns the number of products List and ListAfter page through
+// for filter.
+func (pr *productRepo) Count(ctx context.Context, filter ListFilter) (int64, error) {
+	b := countQuery(route(ctx), filter)
+	sql, args := b.Build()
+	b.Release()
+
+	var total int64
+	err := pr.withStatementTimeout(ctx, func(ctx context.Context) error {
+		return pr.db(ctx).QueryRow(ctx, sql, args...).Scan(&total)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// ListAll calls fn for every product matching filter, ordered by id. Rows
+// are read through a server-side cursor listAllFetchSize at a time, so
+// memory use does not grow with the catalog. It stops at the first error
+// of fn and returns it. ListAll is not retried, since fn would see rows
+// twice.
+func (pr *productRepo) ListAll(ctx context.Context, filter ListFilter, fn func(*pb.Product) error) error {
+	b := productsQuery(ctx).
+		Select(productColumns...).
+		From("products").
+		OrderBy("id")
+	filter.apply(b)
+
+	sql, args := b.Build()
+	b.Release()
+
+	tx, err := pr.db(ctx).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if _, err := tx.Exec(ctx, "DECLARE products_all NO SCROLL CURSOR FOR "+sql, args...); err != nil {
+		return err
+	}
+
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM products_all", listAllFetchSize)
+	for {
+		rows, err := tx.Query(ctx, fetch)
+		if err != nil {
+			return err
+		}
+
+		fetched := 0
+		for rows.Next() {
+			var row productRow
+			if err := row.scan(rows); err != nil {
+				rows.Close()
+				return err
+			}
+			fetched++
+			if err := fn(row.toProto()); err != nil {
+				rows.Close()
+				return err
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if fetched < listAllFetchSize {
+			break
+		}
+	}
+
+	// Closing lets a later ListAll in the same transaction reuse the name.
+	if _, err := tx.Exec(ctx, "CLOSE products_all"); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// countQuery counts the products matching filter.
+func countQuery(route string, filter ListFilter) *builder.SQLBuilder {
+	b := productsRouteQuery(route).SelectCount("*").From("products")
+	filter.apply(b)
+	return b
+}
+
+// queryProducts runs a query selecting columns, a subset of
+// productColumns.
+func (pr *productRepo) queryProducts(ctx context.Context, size int, columns []string, sql string, args []any) ([]*pb.Product, error) {
+	rows, err := pr.db(ctx).Query(ctx, sql, args...)
 	if err != nil {
 		return nil, err
 	}
+
+	return collectProducts(rows, size, columns)
+}
+
+// collectProducts scans and closes rows selected with columns, a subset
+// of productColumns.
+func collectProducts(rows pgx.Rows, size int, columns []string) ([]*pb.Product, error) {
 	defer rows.Close()
 
-	products := make([]*pb.Product, 0, pageSize)
+	products := make([]*pb.Product, 0, size)
 	for rows.Next() {
-		var id, name, description string
-		var price float64
-		var quantity int32
-		var tags []string
-		var available bool
-		var createdAt, updatedAt time.Time
-
-		if err := rows.Scan(
-			&id, &name, &description, &price, &quantity,
-			&tags, &available, &createdAt, &updatedAt,
-		); err != nil {
+		var row productRow
+		if err := row.scanColumns(rows, columns); err != nil {
 			return nil, err
 		}
 
-		products = append(products, &pb.Product{
-			Id:          id,
-			Name:        name,
-			Description: description,
-			Price:       price,
-			Quantity:    quantity,
-			Tags:        tags,
-			Available:   available,
-			CreatedAt:   timestamppb.New(createdAt),
-			UpdatedAt:   timestamppb.New(updatedAt),
-		})
+		products = append(products, row.toProto())
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
@@ -167,91 +705,245 @@ func (pr *productRepo) List(ctx context.Context, prevSize, pageSize int32, filte
 }
 
 func (pr *productRepo) Update(ctx context.Context, p *pb.Product, mask *fieldmaskpb.FieldMask) (*pb.Product, error) {
-    b := builder.NewSQLBuilder().
-        Update("products").
-        Where("id = ?", p.GetId()).
-        Returning("id", "name", "description", "price", "quantity", "tags", "available", "created_at", "updated_at")
-
-    for _, path := range mask.GetPaths() {
-        switch path {
-        case "name":
-            b.Set("name = ?", p.GetName())
-        case "description":
-            b.Set("description = ?", p.GetDescription())
-        case "price":
-            b.Set("price = ?", p.GetPrice())
-        case "quantity":
-            b.Set("quantity = ?", p.GetQuantity())
-        case "tags":
-            b.Set("tags = ?", p.GetTags())
-        case "available":
-            b.Set("available = ?", p.GetAvailable())
-        default:
-            return nil, status.Errorf(codes.InvalidArgument, "unknown field in update_mask: %s", path)
-        }
-    }
+	var (
+		columns []string
+		// merge and remove are the attributes set and removed by
+		// "attributes.<key>" paths.
+		merge  map[string]string
+		remove []string
+	)
+	for _, path := range mask.GetPaths() {
+		if key, ok := attributePath(path); ok {
+			if value, set := p.GetAttributes()[key]; set {
+				if merge == nil {
+					merge = make(map[string]string)
+				}
+				merge[key] = value
+			} else {
+				remove = append(remove, key)
+			}
+			continue
+		}
+		if !updatableFields[path] {
+			return nil, inverr.InvalidField("update_mask", "unknown field in update_mask: %s", path)
+		}
+		for _, column := range columnsOf(path) {
+			if !slices.Contains(columns, column) {
+				columns = append(columns, column)
+			}
+		}
+	}
+	if slices.Contains(columns, "barcode") {
+		if err := validateBarcode(p.GetBarcode()); err != nil {
+			return nil, err
+		}
+	}
+	if slices.Contains(columns, "price_cents") {
+		if err := validatePrice(p); err != nil {
+			return nil, err
+		}
+	}
+	if slices.Contains(columns, "status") {
+		if err := validateStatus(p); err != nil {
+			return nil, err
+		}
+	}
+	translations := slices.Contains(mask.GetPaths(), "translations")
+	if translations {
+		if err := validateTranslations(p); err != nil {
+			return nil, err
+		}
+	}
+	if slices.Contains(columns, "attributes") {
+		if merge != nil || remove != nil {
+			return nil, inverr.InvalidField("update_mask", "update_mask cannot combine attributes with attributes.<key>")
+		}
+		if err := validateAttributes(p); err != nil {
+			return nil, err
+		}
+	}
+
+	b := productsQuery(ctx).
+		Update("products").
+		Where("id = ?", p.GetId()).
+		Returning(productColumns...)
+
+	if len(columns) > 0 {
+		b.UpdateStruct(newProductRow(p), columns...)
+	}
+	if merge != nil || remove != nil {
+		b.SetJSONMerge("attributes", merge, remove...)
+	}
 
 	b.Set("updated_at = ?", time.Now())
-    sql, args := b.Build()
-    row := pr.Pool.QueryRow(ctx, sql, args...)
-
-    var id, name, description string
-    var price float64
-    var quantity int32
-    var tags []string
-    var available bool
-    var createdAt, updatedAt time.Time
-
-    if err := row.Scan(
-        &id, &name, &description, &price, &quantity,
-        &tags, &available, &createdAt, &updatedAt,
-    ); err != nil {
-        return nil, status.Errorf(codes.Internal, "update failed: %v", err)
-    }
-
-    return &pb.Product{
-        Id:          id,
-        Name:        name,
-        Description: description,
-        Price:       price,
-        Quantity:    quantity,
-        Tags:        tags,
-        Available:   available,
-        CreatedAt:   timestamppb.New(createdAt),
-        UpdatedAt:   timestamppb.New(updatedAt),
-    }, nil
+	b.Set("updated_by = ?", actor(ctx))
+	sql, args := b.Build()
+
+	tx, err := pr.db(ctx).Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var row productRow
+	if err := row.scan(tx.QueryRow(ctx, sql, args...)); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "product not found: %s", p.GetId())
+		}
+		return nil, duplicateKeyError(err, p)
+	}
+	if translations {
+		if err = saveTranslations(ctx, tx, row.ID, p.GetTranslations()); err != nil {
+			return nil, err
+		}
+	}
+	if err = notifyChange(ctx, tx, row.ID, OpUpdate); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	product := row.toProto()
+	if translations {
+		product.Translations = p.GetTranslations()
+	}
+	return product, nil
 }
 
 func (pr *productRepo) Get(ctx context.Context, id string) (*pb.Product, error) {
-	sql, args := builder.NewSQLBuilder().
-	Select("id", "name", "description", "price", "quantity", "tags", "available", "created_at", "updated_at").
-	From("products").
-	Where("id = ?", id).Build()
-
-	var pid, name, description string
-	var price float64
-	var quantity int32
-	var tags []string
-	var available bool
-	var createdAt, updatedAt time.Time
-
-	err := pr.Pool.QueryRow(ctx, sql, args...).Scan(
-		&pid, &name, &description, &price, &quantity,
-		&tags, &available, &createdAt, &updatedAt,
-	)
+	columns := readColumns(ctx)
+	b := getQuery(route(ctx), columns, id)
+	if ctx.Value(rowLockKey{}) != nil {
+		b.ForUpdate()
+	}
+	sql, args := b.Build()
+
+	var row productRow
+	err := pr.withStatementTimeout(ctx, func(ctx context.Context) error {
+		return row.scanColumns(pr.db(ctx).QueryRow(ctx, sql, args...), columns)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return maskProduct(ctx, row.toProto()), nil
+}
+
+// GetBySKU returns the product with the given SKU, using the unique index
+// on products.sku.
+func (pr *productRepo) GetBySKU(ctx context.Context, sku string) (*pb.Product, error) {
+	if sku == "" {
+		return nil, inverr.InvalidField("sku", "sku is required")
+	}
+
+	sql, args := productsQuery(ctx).
+		Select(productColumns...).
+		From("products").
+		Where("sku = ?", sku).
+		Build()
+
+	var row productRow
+	err := pr.withStatementTimeout(ctx, func(ctx context.Context) error {
+		return row.scan(pr.db(ctx).QueryRow(ctx, sql, args...))
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, status.Errorf(codes.NotFound, "product with sku %s not found", sku)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return row.toProto(), nil
+}
+
+// upsertColumns are the columns UpsertBySKU overwrites on an existing
+// product: the fields a supplier feed owns. The status is not; it only
+// sets that of new products.
+var upsertColumns = []string{
+	"name", "description", "price_cents", "currency_code", "quantity", "tags", "attributes", "category_id",
+	"updated_at", "updated_by",
+}
+
+// UpsertBySKU inserts p, or overwrites the feed-owned fields of the
+// product with the same SKU in a single statement, and returns the stored
+// product. An existing product keeps its id, creation fields and deletion
+// mark.
+func (pr *productRepo) UpsertBySKU(ctx context.Context, p *pb.Product) (*pb.Product, error) {
+	if p.GetSku() == "" {
+		return nil, inverr.InvalidField("sku", "sku is required")
+	}
+	if err := validateProduct(p); err != nil {
+		return nil, err
+	}
+
+	row := newProductRow(p)
+	row.CreatedAt = time.Now()
+	row.UpdatedAt = row.CreatedAt
+	row.CreatedBy = actor(ctx)
+	row.UpdatedBy = row.CreatedBy
+
+	b := productsQuery(ctx).
+		Insert("products").
+		InsertStruct(row).
+		OnConflict("sku").
+		Returning(productColumns...)
+	for _, col := range upsertColumns {
+		b.DoUpdateSet(col + " = EXCLUDED." + col)
+	}
+	sql, args := b.Build()
+
+	tx, err := pr.db(ctx).Begin(ctx)
 	if err != nil {
 		return nil, err
 	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var stored productRow
+	if err := stored.scan(tx.QueryRow(ctx, sql, args...)); err != nil {
+		return nil, duplicateKeyError(err, p)
+	}
+	if err = notifyChange(ctx, tx, stored.ID, OpUpsert); err != nil {
+		return nil, err
+	}
 
-	return &pb.Product{
-		Id:          pid,
-		Name:        name,
-		Description: description,
-		Price:       price,
-		Quantity:    quantity,
-		Tags:        tags,
-		Available:   available,
-		CreatedAt:   timestamppb.New(createdAt),
-		UpdatedAt:   timestamppb.New(updatedAt),
-	}, nil
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return stored.toProto(), nil
+}
+
+// duplicateKeyError maps a unique violation of products.sku or
+// products.barcode by p to codes.AlreadyExists and returns other errors as
+// is.
+func duplicateKeyError(err error, p *pb.Product) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != "23505" {
+		return err
+	}
+	switch pgErr.ConstraintName {
+	case "products_sku_key":
+		return inverr.WithReason(codes.AlreadyExists, inverr.ReasonDuplicateSKU,
+			map[string]string{"sku": p.GetSku()},
+			"product with sku %s already exists", p.GetSku())
+	case "products_barcode_key":
+		return inverr.WithReason(codes.AlreadyExists, inverr.ReasonDuplicateBarcode,
+			map[string]string{"barcode": p.GetBarcode()},
+			"product with barcode %s already exists", p.GetBarcode())
+	}
+	return err
+}
+
+// getQuery selects columns of the product id.
+func getQuery(route string, columns []string, id string) *builder.SQLBuilder {
+	return productsRouteQuery(route).
+		Select(columns...).
+		From("products").
+		Where("id = ?", id)
 }
@@ -0,0 +1,523 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andro-kes/inventory_service/internal/auth"
+	pb "github.com/andro-kes/inventory_service/proto"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v4"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+const selectProduct = "SELECT id, name, description, price_cents, currency_code, quantity, tags, status, created_at, updated_at, category_id, created_by, updated_by, sku, barcode, attributes, reorder_point, reorder_quantity FROM products"
+
+func newMockRepo(t *testing.T) (*productRepo, pgxmock.PgxPoolIface) {
+	t.Helper()
+
+	mock, err := pgxmock.NewPool(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		mock.Close()
+	})
+
+	return &productRepo{DB: mock}, mock
+}
+
+func productRows(mock pgxmock.PgxPoolIface, ids ...string) *pgxmock.Rows {
+	rows := mock.NewRows(productColumns)
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, id := range ids {
+		createdAt := created.Add(-time.Duration(i) * time.Hour)
+		rows.AddRow(id, "name "+id, "", int64(1000), "RUB", int32(1), []string{"tag"}, "active", createdAt, createdAt, (*string)(nil), (*string)(nil), (*string)(nil), (*string)(nil), (*string)(nil), map[string]string{}, int32(0), int32(0))
+	}
+	return rows
+}
+
+// TestRepoGet tests that Get hides deleted products.
+func TestRepoGet(t *testing.T) {
+	pr, mock := newMockRepo(t)
+
+	mock.ExpectQuery(selectProduct + " WHERE id = $1 AND deleted_at IS NULL").
+		WithArgs("1").
+		WillReturnRows(productRows(mock, "1"))
+
+	p, err := pr.Get(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if p.GetId() != "1" || p.GetName() != "name 1" {
+		t.Errorf("Unexpected product: %v", p)
+	}
+}
+
+// TestRepoGetRowLock tests that Get locks the product under WithRowLock.
+func TestRepoGetRowLock(t *testing.T) {
+	pr, mock := newMockRepo(t)
+
+	mock.ExpectQuery(selectProduct + " WHERE id = $1 AND deleted_at IS NULL FOR UPDATE").
+		WithArgs("1").
+		WillReturnRows(productRows(mock, "1"))
+
+	if _, err := pr.Get(WithRowLock(context.Background()), "1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+// TestRepoDelete tests that Delete marks the product as deleted and
+// announces the change.
+func TestRepoDelete(t *testing.T) {
+	pr, mock := newMockRepo(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE products SET deleted_at = now() WHERE id = $1 AND deleted_at IS NULL").
+		WithArgs("1").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec(notifyChangeSQL).
+		WithArgs(ChangesChannel, `{"id":"1","op":"delete"}`, "", "").
+		WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectCommit()
+
+	if err := pr.Delete(context.Background(), "1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+// TestRepoDeleteNotFound tests that deleting a missing product fails
+// with NotFound and announces nothing.
+func TestRepoDeleteNotFound(t *testing.T) {
+	pr, mock := newMockRepo(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE products SET deleted_at = now() WHERE id = $1 AND deleted_at IS NULL").
+		WithArgs("1").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	mock.ExpectRollback()
+
+	if err := pr.Delete(context.Background(), "1"); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound, got: %v", err)
+	}
+}
+
+// TestRepoDeleteWhere tests a bulk delete by tag notifying each deleted
+// product in one statement.
+func TestRepoDeleteWhere(t *testing.T) {
+	pr, mock := newMockRepo(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE products SET deleted_at = now() WHERE tags @> ARRAY[$1]::text[] AND deleted_at IS NULL RETURNING id").
+		WithArgs("discontinued").
+		WillReturnRows(mock.NewRows([]string{"id"}).AddRow("1").AddRow("2"))
+	mock.ExpectExec(notifyChangesSQL).
+		WithArgs(ChangesChannel, []string{`{"id":"1","op":"delete"}`, `{"id":"2","op":"delete"}`}, "", "").
+		WillReturnResult(pgxmock.NewResult("SELECT", 2))
+	mock.ExpectCommit()
+
+	deleted, err := pr.DeleteWhere(context.Background(), ListFilter{Tags: []string{"discontinued"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !slices.Equal(deleted, []string{"1", "2"}) {
+		t.Errorf("Expected products 1 and 2 deleted, got: %v", deleted)
+	}
+}
+
+// TestRepoDeleteWhereEmptyFilter tests that an empty filter is rejected.
+func TestRepoDeleteWhereEmptyFilter(t *testing.T) {
+	pr, _ := newMockRepo(t)
+
+	_, err := pr.DeleteWhere(context.Background(), ListFilter{})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument, got: %v", err)
+	}
+}
+
+// TestRepoRestoreNotFound tests Restore of a product that is not deleted.
+func TestRepoRestoreNotFound(t *testing.T) {
+	pr, mock := newMockRepo(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE products SET deleted_at = NULL, updated_at = $1 WHERE id = $2 AND deleted_at IS NOT NULL RETURNING "+strings.Join(productColumns, ", ")).
+		WithArgs(pgxmock.AnyArg(), "1").
+		WillReturnRows(mock.NewRows(productColumns))
+	mock.ExpectRollback()
+
+	_, err := pr.Restore(context.Background(), "1")
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound, got: %v", err)
+	}
+}
+
+// TestRepoListAfter tests that ListAfter returns a cursor when more
+// products follow and continues from it.
+func TestRepoListAfter(t *testing.T) {
+	pr, mock := newMockRepo(t)
+
+	mock.ExpectQuery(selectProduct + " WHERE quantity > $1 AND deleted_at IS NULL ORDER BY created_at DESC, id DESC LIMIT 2").
+		WithArgs(0).
+		WillReturnRows(productRows(mock, "b", "a"))
+
+	products, cursor, err := pr.ListAfter(context.Background(), "", 1, ListFilter{}, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(products) != 1 || products[0].GetId() != "b" || cursor == "" {
+		t.Fatalf("Expected product b and a cursor, got: %v, %q", products, cursor)
+	}
+
+	mock.ExpectQuery(selectProduct+" WHERE quantity > $1 AND (created_at, id) < ($2, $3) AND deleted_at IS NULL "+
+		"ORDER BY created_at DESC, id DESC LIMIT 2").
+		WithArgs(0, products[0].GetCreatedAt().AsTime(), "b").
+		WillReturnRows(productRows(mock, "a"))
+
+	products, cursor, err = pr.ListAfter(context.Background(), cursor, 1, ListFilter{}, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(products) != 1 || products[0].GetId() != "a" || cursor != "" {
+		t.Errorf("Expected the last page with product a, got: %v, %q", products, cursor)
+	}
+}
+
+// TestRepoListAfterOrderBy tests keyset pagination by price and that the
+// cursor is rejected for another order.
+func TestRepoListAfterOrderBy(t *testing.T) {
+	pr, mock := newMockRepo(t)
+
+	mock.ExpectQuery(selectProduct + " WHERE quantity > $1 AND deleted_at IS NULL ORDER BY price_cents ASC, id ASC LIMIT 2").
+		WithArgs(0).
+		WillReturnRows(productRows(mock, "a", "b"))
+
+	_, cursor, err := pr.ListAfter(context.Background(), "", 1, ListFilter{}, "price asc")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	mock.ExpectQuery(selectProduct+" WHERE quantity > $1 AND (price_cents, id) > ($2, $3) AND deleted_at IS NULL "+
+		"ORDER BY price_cents ASC, id ASC LIMIT 2").
+		WithArgs(0, int64(1000), "a").
+		WillReturnRows(productRows(mock, "b"))
+
+	if _, _, err := pr.ListAfter(context.Background(), cursor, 1, ListFilter{}, "price asc"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, _, err := pr.ListAfter(context.Background(), cursor, 1, ListFilter{}, "price desc"); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("Expected ErrInvalidCursor, got: %v", err)
+	}
+	if _, _, err := pr.ListAfter(context.Background(), "", 1, ListFilter{}, "name"); !errors.Is(err, ErrInvalidOrderBy) {
+		t.Errorf("Expected ErrInvalidOrderBy, got: %v", err)
+	}
+}
+
+// TestRepoReadMask tests that Get and ListAfter select the masked
+// columns, the id and the keyset only, and that ListAfter clears the
+// keyset outside the mask on every page, the last one included.
+func TestRepoReadMask(t *testing.T) {
+	pr, mock := newMockRepo(t)
+	ctx, err := WithReadMask(context.Background(), []string{"name", "price"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	mock.ExpectQuery("SELECT id, name, price_cents, currency_code FROM products WHERE id = $1 AND deleted_at IS NULL").
+		WithArgs("1").
+		WillReturnRows(mock.NewRows([]string{"id", "name", "price_cents", "currency_code"}).AddRow("1", "Laptop", int64(1000), "RUB"))
+
+	p, err := pr.Get(ctx, "1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if p.GetName() != "Laptop" || p.GetPrice() != 10 || p.GetPriceMoney() != nil || p.GetCreatedAt() != nil {
+		t.Errorf("Expected name and price only, got: %v", p)
+	}
+
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT id, name, price_cents, currency_code, created_at FROM products WHERE quantity > $1 AND deleted_at IS NULL " +
+		"ORDER BY created_at DESC, id DESC LIMIT 2").
+		WithArgs(0).
+		WillReturnRows(mock.NewRows([]string{"id", "name", "price_cents", "currency_code", "created_at"}).
+			AddRow("b", "Mouse", int64(2000), "RUB", created).
+			AddRow("a", "Laptop", int64(1000), "RUB", created.Add(-time.Hour)))
+
+	products, cursor, err := pr.ListAfter(ctx, "", 1, ListFilter{}, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(products) != 1 || products[0].GetCreatedAt() != nil || cursor == "" {
+		t.Errorf("Expected product b without created_at and a cursor, got: %v, %q", products, cursor)
+	}
+
+	mock.ExpectQuery("SELECT id, name, price_cents, currency_code, created_at FROM products WHERE quantity > $1 AND deleted_at IS NULL " +
+		"ORDER BY created_at DESC, id DESC LIMIT 3").
+		WithArgs(0).
+		WillReturnRows(mock.NewRows([]string{"id", "name", "price_cents", "currency_code", "created_at"}).
+			AddRow("a", "Laptop", int64(1000), "RUB", created))
+
+	products, cursor, err = pr.ListAfter(ctx, "", 2, ListFilter{}, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(products) != 1 || products[0].GetCreatedAt() != nil || cursor != "" {
+		t.Errorf("Expected product a without created_at on the last page, got: %v, %q", products, cursor)
+	}
+
+	if _, err := WithReadMask(context.Background(), []string{"created_at.seconds"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument, got: %v", err)
+	}
+}
+
+// TestRepoDecrementQuantityInsufficient tests the error returned when the
+// stock does not cover the decrement.
+func TestRepoDecrementQuantityInsufficient(t *testing.T) {
+	pr, mock := newMockRepo(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectProductLock).
+		WithArgs("1").
+		WillReturnRows(mock.NewRows([]string{"id"}).AddRow("1"))
+	mock.ExpectExec(labelMovementSQL).
+		WithArgs(ReasonDecrement, "", "").
+		WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectQuery("UPDATE products SET quantity = quantity - $1, updated_at = $2 "+
+		"WHERE id = $3 AND "+freeQuantity+" >= $4 AND deleted_at IS NULL RETURNING quantity").
+		WithArgs(int32(5), pgxmock.AnyArg(), "1", int32(5)).
+		WillReturnRows(mock.NewRows([]string{"quantity"}))
+	mock.ExpectQuery("SELECT " + freeQuantity + " FROM products WHERE id = $1 AND deleted_at IS NULL").
+		WithArgs("1").
+		WillReturnRows(mock.NewRows([]string{"free"}).AddRow(int64(2)))
+	mock.ExpectRollback()
+
+	_, err := pr.DecrementQuantity(context.Background(), "1", 5)
+	var stockErr *InsufficientStockError
+	if !errors.As(err, &stockErr) {
+		t.Fatalf("Expected InsufficientStockError, got: %v", err)
+	}
+	if stockErr.Available != 2 || stockErr.Requested != 5 {
+		t.Errorf("Unexpected error details: %+v", stockErr)
+	}
+
+	st := status.Convert(err)
+	if st.Code() != codes.FailedPrecondition {
+		t.Fatalf("Expected FailedPrecondition, got: %v", st.Code())
+	}
+	var info *errdetails.ErrorInfo
+	for _, detail := range st.Details() {
+		if d, ok := detail.(*errdetails.ErrorInfo); ok {
+			info = d
+		}
+	}
+	if info.GetReason() != StockErrorReason || info.GetMetadata()["available"] != "2" {
+		t.Errorf("Unexpected error info: %v", info)
+	}
+}
+
+// TestRepoCopyFrom tests chunked COPY with progress reporting.
+func TestRepoCopyFrom(t *testing.T) {
+	pr, mock := newMockRepo(t)
+
+	ps := make([]*pb.Product, copyChunkSize+1)
+	for i := range ps {
+		ps[i] = &pb.Product{Id: fmt.Sprint(i), Name: "p"}
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectCopyFrom(pgx.Identifier{"products"}, productColumns).WillReturnResult(copyChunkSize)
+	mock.ExpectCopyFrom(pgx.Identifier{"products"}, productColumns).WillReturnResult(1)
+	mock.ExpectCommit()
+
+	var reported []int64
+	copied, err := pr.CopyFrom(context.Background(), ps, func(n int64) {
+		reported = append(reported, n)
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if copied != copyChunkSize+1 {
+		t.Errorf("Expected %d copied rows, got: %d", copyChunkSize+1, copied)
+	}
+	if len(reported) != 2 || reported[0] != copyChunkSize || reported[1] != copyChunkSize+1 {
+		t.Errorf("Unexpected progress: %v", reported)
+	}
+}
+
+// TestRepoUpdateActor tests that Update records the principal of the
+// request, also for the audit trail.
+func TestRepoUpdateActor(t *testing.T) {
+	pr, mock := newMockRepo(t)
+
+	operator := "alice"
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE products SET price_cents = $1, currency_code = $2, updated_at = $3, updated_by = $4 "+
+		"WHERE id = $5 AND deleted_at IS NULL RETURNING "+strings.Join(productColumns, ", ")).
+		WithArgs(int64(1250), "RUB", pgxmock.AnyArg(), &operator, "1").
+		WillReturnRows(productRows(mock, "1"))
+	mock.ExpectExec(notifyChangeSQL).
+		WithArgs(ChangesChannel, `{"id":"1","op":"update"}`, operator, "").
+		WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectCommit()
+
+	ctx := auth.NewContext(context.Background(), operator)
+	p := &pb.Product{Id: "1", Price: 12.5}
+	if _, err := pr.Update(ctx, p, &fieldmaskpb.FieldMask{Paths: []string{"price"}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+// TestRepoUpdateNotFound tests that updating a missing product fails with
+// NotFound rather than Internal.
+func TestRepoUpdateNotFound(t *testing.T) {
+	pr, mock := newMockRepo(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE products SET name = $1, updated_at = $2, updated_by = NULL "+
+		"WHERE id = $3 AND deleted_at IS NULL RETURNING "+strings.Join(productColumns, ", ")).
+		WithArgs("tv", pgxmock.AnyArg(), "1").
+		WillReturnError(pgx.ErrNoRows)
+	mock.ExpectRollback()
+
+	p := &pb.Product{Id: "1", Name: "tv"}
+	if _, err := pr.Update(context.Background(), p, &fieldmaskpb.FieldMask{Paths: []string{"name"}}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound, got: %v", err)
+	}
+}
+
+// TestRepoStatementTimeout tests that a read query running past the
+// statement timeout is cancelled and reported as DeadlineExceeded.
+func TestRepoStatementTimeout(t *testing.T) {
+	pr, mock := newMockRepo(t)
+	pr.StatementTimeout = 10 * time.Millisecond
+
+	mock.ExpectQuery("SELECT COUNT(*) FROM products WHERE quantity > $1 AND deleted_at IS NULL").
+		WithArgs(0).
+		WillReturnRows(mock.NewRows([]string{"count"}).AddRow(int64(1))).
+		WillDelayFor(time.Second)
+
+	start := time.Now()
+	_, err := pr.Count(context.Background(), ListFilter{})
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("Expected DeadlineExceeded, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected the query to be cancelled, took: %v", elapsed)
+	}
+}
+
+// TestRepoGetBySKU tests the SKU lookup and its NotFound mapping.
+func TestRepoGetBySKU(t *testing.T) {
+	pr, mock := newMockRepo(t)
+
+	mock.ExpectQuery(selectProduct + " WHERE sku = $1 AND deleted_at IS NULL").
+		WithArgs("SKU-1").
+		WillReturnRows(productRows(mock, "1"))
+	mock.ExpectQuery(selectProduct + " WHERE sku = $1 AND deleted_at IS NULL").
+		WithArgs("SKU-2").
+		WillReturnError(pgx.ErrNoRows)
+
+	p, err := pr.GetBySKU(context.Background(), "SKU-1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if p.GetId() != "1" {
+		t.Errorf("Unexpected product: %v", p)
+	}
+
+	if _, err := pr.GetBySKU(context.Background(), "SKU-2"); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound, got: %v", err)
+	}
+}
+
+// TestRepoUpsertBySKU tests that UpsertBySKU overwrites only the
+// feed-owned fields of an existing product.
+func TestRepoUpsertBySKU(t *testing.T) {
+	pr, mock := newMockRepo(t)
+
+	cols := strings.Join(productColumns, ", ")
+	args := make([]any, len(productColumns))
+	for i := range args {
+		args[i] = pgxmock.AnyArg()
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO products (" + cols + ") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18) " +
+		"ON CONFLICT (sku) DO UPDATE SET name = EXCLUDED.name, description = EXCLUDED.description, " +
+		"price_cents = EXCLUDED.price_cents, currency_code = EXCLUDED.currency_code, quantity = EXCLUDED.quantity, tags = EXCLUDED.tags, attributes = EXCLUDED.attributes, " +
+		"category_id = EXCLUDED.category_id, updated_at = EXCLUDED.updated_at, updated_by = EXCLUDED.updated_by " +
+		"RETURNING " + cols).
+		WithArgs(args...).
+		WillReturnRows(productRows(mock, "1"))
+	mock.ExpectExec(notifyChangeSQL).
+		WithArgs(ChangesChannel, `{"id":"1","op":"upsert"}`, "", "").
+		WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectCommit()
+
+	p, err := pr.UpsertBySKU(context.Background(), &pb.Product{Id: "2", Sku: "SKU-1", Price: 5})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if p.GetId() != "1" {
+		t.Errorf("Expected the existing product, got: %v", p)
+	}
+}
+
+// TestRepoListAll tests that ListAll fetches from a cursor until a short
+// batch and stops at the first error of fn.
+func TestRepoListAll(t *testing.T) {
+	declare := "DECLARE products_all NO SCROLL CURSOR FOR " + selectProduct +
+		" WHERE quantity > $1 AND deleted_at IS NULL ORDER BY id"
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM products_all", listAllFetchSize)
+
+	t.Run("all", func(t *testing.T) {
+		pr, mock := newMockRepo(t)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(declare).WithArgs(0).WillReturnResult(pgxmock.NewResult("DECLARE CURSOR", 0))
+		mock.ExpectQuery(fetch).WillReturnRows(productRows(mock, "1", "2"))
+		mock.ExpectExec("CLOSE products_all").WillReturnResult(pgxmock.NewResult("CLOSE CURSOR", 0))
+		mock.ExpectCommit()
+
+		var ids []string
+		err := pr.ListAll(context.Background(), ListFilter{}, func(p *pb.Product) error {
+			ids = append(ids, p.GetId())
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(ids) != 2 || ids[0] != "1" || ids[1] != "2" {
+			t.Errorf("Unexpected products: %v", ids)
+		}
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		pr, mock := newMockRepo(t)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(declare).WithArgs(0).WillReturnResult(pgxmock.NewResult("DECLARE CURSOR", 0))
+		mock.ExpectQuery(fetch).WillReturnRows(productRows(mock, "1", "2"))
+		mock.ExpectRollback()
+
+		stop := errors.New("stop")
+		calls := 0
+		err := pr.ListAll(context.Background(), ListFilter{}, func(p *pb.Product) error {
+			calls++
+			return stop
+		})
+		if !errors.Is(err, stop) || calls != 1 {
+			t.Errorf("Expected to stop after 1 call, got: %v after %d calls", err, calls)
+		}
+	})
+}
@@ -0,0 +1,273 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/andro-kes/inventory_service/internal/inverr"
+	"github.com/andro-kes/inventory_service/internal/repo/builder"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Reservation statuses. An active reservation past ExpiresAt is expired:
+// it no longer holds stock and cannot be confirmed. ReleaseExpired stores
+// that as ReservationExpired.
+const (
+	ReservationActive    = "active"
+	ReservationConfirmed = "confirmed"
+	ReservationReleased  = "released"
+	ReservationExpired   = "expired"
+)
+
+// ReasonReservation is the movement reason recorded by ConfirmReservation.
+const ReasonReservation = "reservation"
+
+// Reservation holds units of a product for a checkout until ExpiresAt.
+// Reserved units are not free to reserve again while the reservation is
+// active.
+type Reservation struct {
+	ID        string    `db:"id"`
+	ProductID string    `db:"product_id"`
+	OrderID   string    `db:"order_id"`
+	Quantity  int32     `db:"quantity"`
+	Status    string    `db:"status"`
+	CreatedAt time.Time `db:"created_at"`
+	ExpiresAt time.Time `db:"expires_at"`
+}
+
+// Expired reports whether r is marked expired, or active but past
+// ExpiresAt at now.
+func (r *Reservation) Expired(now time.Time) bool {
+	return r.Status == ReservationExpired || r.Status == ReservationActive && !now.Before(r.ExpiresAt)
+}
+
+// reservationColumns lists the reservations columns in Reservation order.
+var reservationColumns = builder.StructColumns(Reservation{})
+
+func (r *Reservation) scan(row pgx.Row) error {
+	return row.Scan(&r.ID, &r.ProductID, &r.OrderID, &r.Quantity, &r.Status, &r.CreatedAt, &r.ExpiresAt)
+}
+
+// ReserveStock reserves r.Quantity units of r.ProductID. The product row
+// is locked while the free quantity (stock minus active reservations) is
+// checked and the reservation is inserted, so concurrent reservations
+// cannot oversell. An *InsufficientStockError reports the free quantity.
+func (pr *productRepo) ReserveStock(ctx context.Context, r *Reservation) (*Reservation, error) {
+	if r.Quantity <= 0 {
+		return nil, inverr.InvalidField("quantity", "quantity must be positive: %d", r.Quantity)
+	}
+
+	tx, err := pr.db(ctx).Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	sql, args := productsQuery(ctx).
+		Select("quantity").
+		From("products").
+		Where("id = ?", r.ProductID).
+		ForUpdate().
+		Build()
+
+	var quantity int32
+	err = tx.QueryRow(ctx, sql, args...).Scan(&quantity)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, status.Errorf(codes.NotFound, "product not found: %s", r.ProductID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	sql, args = newQuery(ctx).
+		Select("COALESCE(SUM(quantity), 0)").
+		From("reservations").
+		Where("product_id = ?", r.ProductID).
+		Where("status = ?", ReservationActive).
+		Where("expires_at > ?", now).
+		Build()
+
+	var reserved int64
+	if err = tx.QueryRow(ctx, sql, args...).Scan(&reserved); err != nil {
+		return nil, err
+	}
+
+	if free := int64(quantity) - reserved; free < int64(r.Quantity) {
+		return nil, &InsufficientStockError{ID: r.ProductID, Requested: r.Quantity, Available: int32(max(free, 0))}
+	}
+
+	row := *r
+	row.Status = ReservationActive
+	row.CreatedAt = now
+	sql, args = newQuery(ctx).
+		Insert("reservations").
+		InsertStruct(row).
+		Returning(reservationColumns...).
+		Build()
+
+	var created Reservation
+	if err = created.scan(tx.QueryRow(ctx, sql, args...)); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// GetReservation returns the reservation id.
+func (pr *productRepo) GetReservation(ctx context.Context, id string) (*Reservation, error) {
+	return getReservation(ctx, pr.db(ctx), id, false)
+}
+
+// getReservation reads the reservation id, locking it if forUpdate.
+func getReservation(ctx context.Context, q Querier, id string, forUpdate bool) (*Reservation, error) {
+	b := newQuery(ctx).
+		Select(reservationColumns...).
+		From("reservations").
+		Where("id = ?", id)
+	if forUpdate {
+		b.ForUpdate()
+	}
+	sql, args := b.Build()
+
+	var r Reservation
+	err := r.scan(q.QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, status.Errorf(codes.NotFound, "reservation not found: %s", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// ConfirmReservation turns the reservation id into a purchase: its units
+// are taken out of stock and recorded in the ledger with
+// ReasonReservation and the reference "reservations/<id>". The
+// reservation is confirmed first, so that its units are free to take.
+// It returns the reservation and the quantity of the product left.
+// Confirming a confirmed reservation returns it unchanged with quantity
+// -1, as no units are taken; released and expired reservations are
+// FailedPrecondition.
+func (pr *productRepo) ConfirmReservation(ctx context.Context, id string) (*Reservation, int32, error) {
+	tx, err := pr.db(ctx).Begin(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	r, err := getReservation(ctx, tx, id, true)
+	if err != nil {
+		return nil, 0, err
+	}
+	switch {
+	case r.Status == ReservationConfirmed:
+		return r, -1, nil
+	case r.Status == ReservationReleased:
+		return nil, 0, inverr.WithReason(codes.FailedPrecondition, inverr.ReasonReservationReleased,
+			map[string]string{"reservation_id": id},
+			"reservation %s was released", id)
+	case r.Expired(time.Now()):
+		return nil, 0, inverr.WithReason(codes.FailedPrecondition, inverr.ReasonReservationExpired,
+			map[string]string{"reservation_id": id, "expires_at": r.ExpiresAt.Format(time.RFC3339)},
+			"reservation %s expired at %s", id, r.ExpiresAt.Format(time.RFC3339))
+	}
+
+	sql, args := newQuery(ctx).
+		Update("reservations").
+		Set("status = ?", ReservationConfirmed).
+		Where("id = ?", id).
+		Build()
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return nil, 0, err
+	}
+	quantity, err := takeStock(ctx, tx, r.ProductID, r.Quantity, ReasonReservation, "reservations/"+id)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := notifyChange(ctx, tx, r.ProductID, OpUpdate); err != nil {
+		return nil, 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, 0, err
+	}
+
+	r.Status = ReservationConfirmed
+	return r, quantity, nil
+}
+
+// ReleaseReservation frees the units held by the reservation id, e.g. for
+// a cancelled order. Releasing a released or expired reservation is a
+// no-op; a confirmed one is FailedPrecondition.
+func (pr *productRepo) ReleaseReservation(ctx context.Context, id string) (*Reservation, error) {
+	sql, args := newQuery(ctx).
+		Update("reservations").
+		Set("status = ?", ReservationReleased).
+		Where("id = ?", id).
+		Where("status = ?", ReservationActive).
+		Returning(reservationColumns...).
+		Build()
+
+	var r Reservation
+	err := r.scan(pr.db(ctx).QueryRow(ctx, sql, args...))
+	if err == nil {
+		return &r, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	current, err := pr.GetReservation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if current.Status == ReservationConfirmed {
+		return nil, inverr.WithReason(codes.FailedPrecondition, inverr.ReasonReservationConfirmed,
+			map[string]string{"reservation_id": id},
+			"reservation %s was confirmed", id)
+	}
+	return current, nil
+}
+
+// ReleaseExpired marks up to limit active reservations past their expiry
+// as ReservationExpired and returns how many it marked. Their units are
+// free again from expires_at on; marking them keeps the active
+// reservations few for ReserveStock and CheckAvailability. Reservations
+// locked by another transaction, e.g. a ConfirmReservation or the
+// ReleaseExpired of another replica, are skipped and left for a later
+// call, so replicas can run it concurrently.
+func (pr *productRepo) ReleaseExpired(ctx context.Context, limit int) (int64, error) {
+	expired := newQuery(ctx).
+		Select("id").
+		From("reservations").
+		Where("status = ?", ReservationActive).
+		Where("expires_at <= ?", time.Now()).
+		OrderBy("expires_at").
+		Limit(limit).
+		ForUpdate().
+		SkipLocked()
+	sql, args := newQuery(ctx).
+		Update("reservations").
+		Set("status = ?", ReservationExpired).
+		Where("id IN (?)", expired).
+		Build()
+
+	tag, err := pr.db(ctx).Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
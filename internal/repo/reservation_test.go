@@ -0,0 +1,148 @@
+package repo
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v4"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var reservationCols = strings.Join(reservationColumns, ", ")
+
+func reservationRows(mock pgxmock.PgxPoolIface, state string, expiresAt time.Time) *pgxmock.Rows {
+	return mock.NewRows(reservationColumns).
+		AddRow("r1", "1", "order-1", int32(2), state, expiresAt.Add(-time.Minute), expiresAt)
+}
+
+// TestRepoConfirmReservation tests that confirming takes the reserved
+// units out of stock once.
+func TestRepoConfirmReservation(t *testing.T) {
+	pr, mock := newMockRepo(t)
+	expiresAt := time.Now().Add(time.Minute)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT " + reservationCols + " FROM reservations WHERE id = $1 FOR UPDATE").
+		WithArgs("r1").
+		WillReturnRows(reservationRows(mock, ReservationActive, expiresAt))
+	mock.ExpectExec("UPDATE reservations SET status = $1 WHERE id = $2").
+		WithArgs(ReservationConfirmed, "r1").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectQuery(selectProductLock).
+		WithArgs("1").
+		WillReturnRows(mock.NewRows([]string{"id"}).AddRow("1"))
+	mock.ExpectExec(labelMovementSQL).
+		WithArgs(ReasonReservation, "reservations/r1", "").
+		WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectQuery("UPDATE products SET quantity = quantity - $1, updated_at = $2 "+
+		"WHERE id = $3 AND "+freeQuantity+" >= $4 AND deleted_at IS NULL RETURNING quantity").
+		WithArgs(int32(2), pgxmock.AnyArg(), "1", int32(2)).
+		WillReturnRows(mock.NewRows([]string{"quantity"}).AddRow(int32(3)))
+	mock.ExpectExec(notifyChangeSQL).
+		WithArgs(ChangesChannel, `{"id":"1","op":"update"}`, "", "").
+		WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectCommit()
+
+	r, quantity, err := pr.ConfirmReservation(context.Background(), "r1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if r.Status != ReservationConfirmed || quantity != 3 {
+		t.Errorf("Expected status %s and 3 left, got: %s, %d", ReservationConfirmed, r.Status, quantity)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT " + reservationCols + " FROM reservations WHERE id = $1 FOR UPDATE").
+		WithArgs("r1").
+		WillReturnRows(reservationRows(mock, ReservationConfirmed, expiresAt))
+	mock.ExpectRollback()
+
+	if _, quantity, err := pr.ConfirmReservation(context.Background(), "r1"); err != nil || quantity != -1 {
+		t.Fatalf("Expected nothing taken confirming twice, got: %d, %v", quantity, err)
+	}
+}
+
+// TestRepoConfirmReservationExpired tests that an expired reservation is
+// not confirmed.
+func TestRepoConfirmReservationExpired(t *testing.T) {
+	pr, mock := newMockRepo(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT " + reservationCols + " FROM reservations WHERE id = $1 FOR UPDATE").
+		WithArgs("r1").
+		WillReturnRows(reservationRows(mock, ReservationActive, time.Now().Add(-time.Second)))
+	mock.ExpectRollback()
+
+	_, _, err := pr.ConfirmReservation(context.Background(), "r1")
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("Expected FailedPrecondition, got: %v", err)
+	}
+}
+
+// TestRepoReleaseReservation tests that only active reservations are
+// released, and that releasing twice is a no-op.
+func TestRepoReleaseReservation(t *testing.T) {
+	pr, mock := newMockRepo(t)
+	expiresAt := time.Now().Add(time.Minute)
+	release := "UPDATE reservations SET status = $1 WHERE id = $2 AND status = $3 RETURNING " + reservationCols
+
+	mock.ExpectQuery(release).
+		WithArgs(ReservationReleased, "r1", ReservationActive).
+		WillReturnRows(reservationRows(mock, ReservationReleased, expiresAt))
+
+	r, err := pr.ReleaseReservation(context.Background(), "r1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if r.Status != ReservationReleased {
+		t.Errorf("Expected status %s, got: %s", ReservationReleased, r.Status)
+	}
+
+	mock.ExpectQuery(release).
+		WithArgs(ReservationReleased, "r1", ReservationActive).
+		WillReturnRows(mock.NewRows(reservationColumns))
+	mock.ExpectQuery("SELECT " + reservationCols + " FROM reservations WHERE id = $1").
+		WithArgs("r1").
+		WillReturnRows(reservationRows(mock, ReservationReleased, expiresAt))
+
+	if _, err := pr.ReleaseReservation(context.Background(), "r1"); err != nil {
+		t.Fatalf("Unexpected error releasing twice: %v", err)
+	}
+
+	mock.ExpectQuery(release).
+		WithArgs(ReservationReleased, "r2", ReservationActive).
+		WillReturnRows(mock.NewRows(reservationColumns))
+	mock.ExpectQuery("SELECT " + reservationCols + " FROM reservations WHERE id = $1").
+		WithArgs("r2").
+		WillReturnRows(reservationRows(mock, ReservationConfirmed, expiresAt))
+
+	_, err = pr.ReleaseReservation(context.Background(), "r2")
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("Expected FailedPrecondition, got: %v", err)
+	}
+}
+
+// TestRepoReleaseExpired tests that expired reservations are marked in
+// batches, skipping rows locked by other replicas.
+func TestRepoReleaseExpired(t *testing.T) {
+	pr, mock := newMockRepo(t)
+
+	mock.ExpectExec("UPDATE reservations SET status = $1 WHERE id IN (SELECT id FROM reservations "+
+		"WHERE status = $2 AND expires_at <= $3 ORDER BY expires_at LIMIT 100 FOR UPDATE SKIP LOCKED)").
+		WithArgs(ReservationExpired, ReservationActive, pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 7))
+
+	n, err := pr.ReleaseExpired(context.Background(), 100)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if n != 7 {
+		t.Errorf("Expected 7 released reservations, got: %d", n)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
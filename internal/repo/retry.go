@@ -0,0 +1,330 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"math/rand/v2"
+	"time"
+
+	pb "github.com/andro-kes/inventory_service/proto"
+	"github.com/jackc/pgx/v5/pgconn"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// retries counts retried operations by reason. It is published through
+// expvar as repo_retries.
+var retries = expvar.NewMap("repo_retries")
+
+// RetryPolicy retries operations failing with transient PostgreSQL errors
+// using exponential backoff with full jitter.
+type RetryPolicy struct {
+	MaxAttempts int           // Attempts including the first one
+	BaseDelay   time.Duration // Delay cap of the first retry
+	MaxDelay    time.Duration // Delay cap of any retry
+}
+
+// DefaultRetryPolicy is the policy of the repos returned by NewProductRepo.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   50 * time.Millisecond,
+	MaxDelay:    time.Second,
+}
+
+// Do calls fn until it succeeds, fails with a non-transient error or the
+// attempts run out. It gives up early, returning the last error, when ctx
+// is done or its deadline leaves no room for the next delay. Inside a
+// transaction started by TxManager fn is called once, since a failed
+// statement aborts the whole transaction. Conflicts are not retried in a
+// ctx returned by WithoutConflictRetry.
+func (p RetryPolicy) Do(ctx context.Context, fn func() error) error {
+	transient := retryReason
+	if ctx.Value(noConflictRetryKey{}) != nil {
+		transient = func(err error) (string, bool) {
+			if IsConflict(err) {
+				return "", false
+			}
+			return retryReason(err)
+		}
+	}
+	return p.Retry(ctx, retries, transient, fn)
+}
+
+// Retry is Do for the errors retryable names a reason for, counting the
+// retries by reason in counts, e.g. for retries of whole operations above
+// the repos.
+func (p RetryPolicy) Retry(ctx context.Context, counts *expvar.Map, retryable func(error) (string, bool), fn func() error) error {
+	if ctx.Value(txKey{}) != nil {
+		return fn()
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		reason, ok := retryable(err)
+		if !ok || attempt >= p.MaxAttempts {
+			return err
+		}
+
+		delay := p.Delay(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		counts.Add(reason, 1)
+	}
+}
+
+// noConflictRetryKey is the context key of WithoutConflictRetry.
+type noConflictRetryKey struct{}
+
+// WithoutConflictRetry returns a copy of ctx in which the repos do not
+// retry conflicts, see IsConflict, so that a caller retrying the whole
+// operation does not multiply the attempts.
+func WithoutConflictRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noConflictRetryKey{}, true)
+}
+
+// IsTransient reports whether err is a database error worth retrying,
+// as RetryPolicy.Do does: a conflict or a lost connection.
+func IsTransient(err error) bool {
+	_, ok := retryReason(err)
+	return ok
+}
+
+// IsConflict reports whether err is a conflict with a concurrent
+// transaction: a serialization failure or a deadlock. Rerunning the
+// operation, with fresh reads, usually succeeds.
+func IsConflict(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && (pgErr.Code == "40001" || pgErr.Code == "40P01")
+}
+
+// Delay returns a random delay before the retry after attempt, of up to
+// BaseDelay doubled per attempt, capped at MaxDelay.
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	ceiling := p.BaseDelay << (attempt - 1)
+	if ceiling <= 0 || ceiling > p.MaxDelay {
+		ceiling = p.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling)
+}
+
+// retryReason reports whether err is transient and names the reason.
+func retryReason(err error) (string, bool) {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case pgErr.Code == "40001":
+			return "serialization_failure", true
+		case pgErr.Code == "40P01":
+			return "deadlock", true
+		case pgErr.Code == "57P01" || len(pgErr.Code) == 5 && pgErr.Code[:2] == "08":
+			return "connection", true
+		}
+		return "", false
+	}
+	if pgconn.SafeToRetry(err) {
+		return "connection", true
+	}
+	return "", false
+}
+
+// retryRepo retries the operations of a ProductRepo with a RetryPolicy.
+type retryRepo struct {
+	next   ProductRepo
+	policy RetryPolicy
+}
+
+// retryValue is RetryPolicy.Do for operations returning a value.
+func retryValue[T any](ctx context.Context, p RetryPolicy, fn func() (T, error)) (T, error) {
+	var v T
+	err := p.Do(ctx, func() error {
+		var err error
+		v, err = fn()
+		return err
+	})
+	return v, err
+}
+
+func (r *retryRepo) Create(ctx context.Context, p *pb.Product) (*pb.Product, error) {
+	return retryValue(ctx, r.policy, func() (*pb.Product, error) {
+		return r.next.Create(ctx, p)
+	})
+}
+
+func (r *retryRepo) CreateIdempotent(ctx context.Context, key string, ttl time.Duration, p *pb.Product) (*pb.Product, error) {
+	return retryValue(ctx, r.policy, func() (*pb.Product, error) {
+		return r.next.CreateIdempotent(ctx, key, ttl, p)
+	})
+}
+
+func (r *retryRepo) CreateMany(ctx context.Context, ps []*pb.Product) ([]*pb.Product, error) {
+	return retryValue(ctx, r.policy, func() ([]*pb.Product, error) {
+		return r.next.CreateMany(ctx, ps)
+	})
+}
+
+func (r *retryRepo) CopyFrom(ctx context.Context, ps []*pb.Product, progress func(copied int64)) (int64, error) {
+	return retryValue(ctx, r.policy, func() (int64, error) {
+		return r.next.CopyFrom(ctx, ps, progress)
+	})
+}
+
+func (r *retryRepo) Delete(ctx context.Context, id string) error {
+	return r.policy.Do(ctx, func() error {
+		return r.next.Delete(ctx, id)
+	})
+}
+
+func (r *retryRepo) DeleteWhere(ctx context.Context, filter ListFilter) ([]string, error) {
+	return retryValue(ctx, r.policy, func() ([]string, error) {
+		return r.next.DeleteWhere(ctx, filter)
+	})
+}
+
+func (r *retryRepo) Restore(ctx context.Context, id string) (*pb.Product, error) {
+	return retryValue(ctx, r.policy, func() (*pb.Product, error) {
+		return r.next.Restore(ctx, id)
+	})
+}
+
+func (r *retryRepo) Purge(ctx context.Context, olderThan time.Duration) (int64, error) {
+	return retryValue(ctx, r.policy, func() (int64, error) {
+		return r.next.Purge(ctx, olderThan)
+	})
+}
+
+func (r *retryRepo) List(ctx context.Context, prevSize, pageSize int32, filter ListFilter, orderBy string) ([]*pb.Product, error) {
+	return retryValue(ctx, r.policy, func() ([]*pb.Product, error) {
+		return r.next.List(ctx, prevSize, pageSize, filter, orderBy)
+	})
+}
+
+func (r *retryRepo) ListAfter(ctx context.Context, cursor string, pageSize int32, filter ListFilter, orderBy string) ([]*pb.Product, string, error) {
+	var next string
+	products, err := retryValue(ctx, r.policy, func() ([]*pb.Product, error) {
+		var (
+			products []*pb.Product
+			err      error
+		)
+		products, next, err = r.next.ListAfter(ctx, cursor, pageSize, filter, orderBy)
+		return products, err
+	})
+	return products, next, err
+}
+
+func (r *retryRepo) Count(ctx context.Context, filter ListFilter) (int64, error) {
+	return retryValue(ctx, r.policy, func() (int64, error) {
+		return r.next.Count(ctx, filter)
+	})
+}
+
+// ListAll is not retried: fn has already seen the rows read before a
+// failure.
+func (r *retryRepo) ListAll(ctx context.Context, filter ListFilter, fn func(*pb.Product) error) error {
+	return r.next.ListAll(ctx, filter, fn)
+}
+
+func (r *retryRepo) Update(ctx context.Context, p *pb.Product, mask *fieldmaskpb.FieldMask) (*pb.Product, error) {
+	return retryValue(ctx, r.policy, func() (*pb.Product, error) {
+		return r.next.Update(ctx, p, mask)
+	})
+}
+
+func (r *retryRepo) Get(ctx context.Context, id string) (*pb.Product, error) {
+	return retryValue(ctx, r.policy, func() (*pb.Product, error) {
+		return r.next.Get(ctx, id)
+	})
+}
+
+func (r *retryRepo) GetBySKU(ctx context.Context, sku string) (*pb.Product, error) {
+	return retryValue(ctx, r.policy, func() (*pb.Product, error) {
+		return r.next.GetBySKU(ctx, sku)
+	})
+}
+
+func (r *retryRepo) GetByBarcode(ctx context.Context, barcode string) (*pb.Product, error) {
+	return retryValue(ctx, r.policy, func() (*pb.Product, error) {
+		return r.next.GetByBarcode(ctx, barcode)
+	})
+}
+
+func (r *retryRepo) UpsertBySKU(ctx context.Context, p *pb.Product) (*pb.Product, error) {
+	return retryValue(ctx, r.policy, func() (*pb.Product, error) {
+		return r.next.UpsertBySKU(ctx, p)
+	})
+}
+
+func (r *retryRepo) DecrementQuantity(ctx context.Context, id string, delta int32) (int32, error) {
+	return retryValue(ctx, r.policy, func() (int32, error) {
+		return r.next.DecrementQuantity(ctx, id, delta)
+	})
+}
+
+func (r *retryRepo) ReserveStock(ctx context.Context, res *Reservation) (*Reservation, error) {
+	return retryValue(ctx, r.policy, func() (*Reservation, error) {
+		return r.next.ReserveStock(ctx, res)
+	})
+}
+
+func (r *retryRepo) GetReservation(ctx context.Context, id string) (*Reservation, error) {
+	return retryValue(ctx, r.policy, func() (*Reservation, error) {
+		return r.next.GetReservation(ctx, id)
+	})
+}
+
+func (r *retryRepo) ConfirmReservation(ctx context.Context, id string) (*Reservation, int32, error) {
+	var quantity int32
+	res, err := retryValue(ctx, r.policy, func() (*Reservation, error) {
+		var (
+			res *Reservation
+			err error
+		)
+		res, quantity, err = r.next.ConfirmReservation(ctx, id)
+		return res, err
+	})
+	return res, quantity, err
+}
+
+func (r *retryRepo) ReleaseExpired(ctx context.Context, limit int) (int64, error) {
+	return retryValue(ctx, r.policy, func() (int64, error) {
+		return r.next.ReleaseExpired(ctx, limit)
+	})
+}
+
+func (r *retryRepo) PurgeIdempotencyKeys(ctx context.Context, limit int) (int64, error) {
+	return retryValue(ctx, r.policy, func() (int64, error) {
+		return r.next.PurgeIdempotencyKeys(ctx, limit)
+	})
+}
+
+func (r *retryRepo) ReleaseReservation(ctx context.Context, id string) (*Reservation, error) {
+	return retryValue(ctx, r.policy, func() (*Reservation, error) {
+		return r.next.ReleaseReservation(ctx, id)
+	})
+}
+
+func (r *retryRepo) StockOf(ctx context.Context, ids []string) (map[string]Stock, error) {
+	return retryValue(ctx, r.policy, func() (map[string]Stock, error) {
+		return r.next.StockOf(ctx, ids)
+	})
+}
+
+func (r *retryRepo) Translations(ctx context.Context, ids []string) (map[string][]*pb.LocalizedText, error) {
+	return retryValue(ctx, r.policy, func() (map[string][]*pb.LocalizedText, error) {
+		return r.next.Translations(ctx, ids)
+	})
+}
@@ -0,0 +1,158 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var testRetryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}
+
+// TestRetryReason tests the classification of transient errors.
+func TestRetryReason(t *testing.T) {
+	cases := []struct {
+		err    error
+		reason string
+	}{
+		{&pgconn.PgError{Code: "40001"}, "serialization_failure"},
+		{&pgconn.PgError{Code: "40P01"}, "deadlock"},
+		{&pgconn.PgError{Code: "57P01"}, "connection"},
+		{&pgconn.PgError{Code: "08006"}, "connection"},
+		{&pgconn.PgError{Code: "23505"}, ""},
+		{pgx.ErrNoRows, ""},
+		{errors.New("boom"), ""},
+	}
+
+	for _, tc := range cases {
+		reason, ok := retryReason(tc.err)
+		if reason != tc.reason || ok != (tc.reason != "") {
+			t.Errorf("retryReason(%v): expected %q, got: %q, %v", tc.err, tc.reason, reason, ok)
+		}
+	}
+}
+
+// TestRetryDo tests retrying until success or until the attempts run out.
+func TestRetryDo(t *testing.T) {
+	transient := &pgconn.PgError{Code: "40001"}
+
+	calls := 0
+	err := testRetryPolicy.Do(context.Background(), func() error {
+		calls++
+		if calls < 3 {
+			return transient
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("Expected success after 3 calls, got: %v after %d", err, calls)
+	}
+
+	calls = 0
+	err = testRetryPolicy.Do(context.Background(), func() error {
+		calls++
+		return transient
+	})
+	if !errors.Is(err, transient) || calls != 3 {
+		t.Errorf("Expected the transient error after 3 calls, got: %v after %d", err, calls)
+	}
+
+	calls = 0
+	permanent := errors.New("permanent")
+	err = testRetryPolicy.Do(context.Background(), func() error {
+		calls++
+		return permanent
+	})
+	if !errors.Is(err, permanent) || calls != 1 {
+		t.Errorf("Expected no retry of a permanent error, got: %v after %d", err, calls)
+	}
+}
+
+// TestRetryDoStops tests that Do does not retry inside a transaction or
+// after the context is done.
+func TestRetryDoStops(t *testing.T) {
+	transient := &pgconn.PgError{Code: "40P01"}
+
+	calls := 0
+	inTx := context.WithValue(context.Background(), txKey{}, pgx.Tx(&fakeTx{}))
+	_ = testRetryPolicy.Do(inTx, func() error {
+		calls++
+		return transient
+	})
+	if calls != 1 {
+		t.Errorf("Expected 1 call inside a transaction, got: %d", calls)
+	}
+
+	calls = 0
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	slow := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Hour, MaxDelay: time.Hour}
+	_ = slow.Do(ctx, func() error {
+		calls++
+		return transient
+	})
+	if calls != 1 {
+		t.Errorf("Expected 1 call with a done context, got: %d", calls)
+	}
+}
+
+// TestRetryWithoutConflictRetry tests that conflicts are left to the
+// caller in a WithoutConflictRetry context, while connection errors are
+// still retried.
+func TestRetryWithoutConflictRetry(t *testing.T) {
+	ctx := WithoutConflictRetry(context.Background())
+
+	calls := 0
+	_ = testRetryPolicy.Do(ctx, func() error {
+		calls++
+		return &pgconn.PgError{Code: "40001"}
+	})
+	if calls != 1 {
+		t.Errorf("Expected 1 call for a conflict, got: %d", calls)
+	}
+
+	calls = 0
+	_ = testRetryPolicy.Do(ctx, func() error {
+		calls++
+		return &pgconn.PgError{Code: "57P01"}
+	})
+	if calls != 3 {
+		t.Errorf("Expected 3 calls for a connection error, got: %d", calls)
+	}
+}
+
+// TestTransientError tests that transient errors become retryable status
+// errors with a RetryInfo detail and other errors are kept.
+func TestTransientError(t *testing.T) {
+	cases := []struct {
+		err  error
+		code codes.Code
+	}{
+		{&pgconn.PgError{Code: "53300"}, codes.ResourceExhausted},
+		{&pgconn.PgError{Code: "40001"}, codes.Unavailable},
+		{&pgconn.PgError{Code: "23505"}, codes.Unknown},
+		{status.Error(codes.NotFound, "missing"), codes.NotFound},
+	}
+
+	for _, tc := range cases {
+		err := TransientError(tc.err)
+		if status.Code(err) != tc.code {
+			t.Errorf("Expected %s for %v, got: %v", tc.code, tc.err, err)
+		}
+		hasRetryInfo := false
+		for _, detail := range status.Convert(err).Details() {
+			if _, ok := detail.(*errdetails.RetryInfo); ok {
+				hasRetryInfo = true
+			}
+		}
+		if retryable := tc.code == codes.ResourceExhausted || tc.code == codes.Unavailable; hasRetryInfo != retryable {
+			t.Errorf("Expected RetryInfo %t for %v, got: %v", retryable, tc.err, err)
+		}
+	}
+}
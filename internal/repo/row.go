@@ -0,0 +1,149 @@
+package repo
+
+import (
+	"slices"
+	"time"
+
+	"github.com/andro-kes/inventory_service/internal/repo/builder"
+	pb "github.com/andro-kes/inventory_service/proto"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// productRow mirrors a row of the products table. The db tags drive the
+// column lists of every products query.
+type productRow struct {
+	ID          string            `db:"id"`
+	Name        string            `db:"name"`
+	Description string            `db:"description"`
+	PriceCents  int64             `db:"price_cents"`
+	Currency    string            `db:"currency_code"`
+	Quantity    int32             `db:"quantity"`
+	Tags        []string          `db:"tags"`
+	Status      string            `db:"status"`
+	CreatedAt   time.Time         `db:"created_at"`
+	UpdatedAt   time.Time         `db:"updated_at"`
+	CategoryID  *string           `db:"category_id"`
+	CreatedBy   *string           `db:"created_by"`
+	UpdatedBy   *string           `db:"updated_by"`
+	SKU         *string           `db:"sku"`
+	Barcode     *string           `db:"barcode"`
+	Attributes  map[string]string `db:"attributes"`
+	// ReorderPoint and ReorderQuantity drive the reorder suggestions; see
+	// ReorderRepo.
+	ReorderPoint    int32 `db:"reorder_point"`
+	ReorderQuantity int32 `db:"reorder_quantity"`
+}
+
+// productColumns lists the products columns in productRow order.
+var productColumns = builder.StructColumns(productRow{})
+
+func newProductRow(p *pb.Product) productRow {
+	cents, currency := priceOf(p)
+	// A nil map would be stored as JSON null.
+	attributes := p.GetAttributes()
+	if attributes == nil {
+		attributes = map[string]string{}
+	}
+	return productRow{
+		ID:          p.GetId(),
+		Name:        p.GetName(),
+		Description: p.GetDescription(),
+		PriceCents:  cents,
+		Currency:    currency,
+		Quantity:    p.GetQuantity(),
+		Tags:        p.GetTags(),
+		Status:      statusColumn(StatusOf(p)),
+		CreatedAt:   p.GetCreatedAt().AsTime(),
+		UpdatedAt:   p.GetUpdatedAt().AsTime(),
+		CategoryID:  nullable(p.GetCategoryId()),
+		SKU:         nullable(p.GetSku()),
+		Barcode:     nullable(p.GetBarcode()),
+		Attributes:  attributes,
+
+		ReorderPoint:    p.GetReorderPoint(),
+		ReorderQuantity: p.GetReorderQuantity(),
+	}
+}
+
+// scan reads a row selected with productColumns.
+func (r *productRow) scan(row pgx.Row) error {
+	return row.Scan(
+		&r.ID, &r.Name, &r.Description, &r.PriceCents, &r.Currency, &r.Quantity,
+		&r.Tags, &r.Status, &r.CreatedAt, &r.UpdatedAt, &r.CategoryID,
+		&r.CreatedBy, &r.UpdatedBy, &r.SKU, &r.Barcode, &r.Attributes,
+		&r.ReorderPoint, &r.ReorderQuantity,
+	)
+}
+
+// scanColumns reads a row selected with columns, a subset of
+// productColumns in the same order.
+func (r *productRow) scanColumns(row pgx.Row, columns []string) error {
+	if len(columns) == len(productColumns) {
+		return r.scan(row)
+	}
+	all := []any{
+		&r.ID, &r.Name, &r.Description, &r.PriceCents, &r.Currency, &r.Quantity,
+		&r.Tags, &r.Status, &r.CreatedAt, &r.UpdatedAt, &r.CategoryID,
+		&r.CreatedBy, &r.UpdatedBy, &r.SKU, &r.Barcode, &r.Attributes,
+		&r.ReorderPoint, &r.ReorderQuantity,
+	}
+	dest := make([]any, 0, len(columns))
+	for i, column := range productColumns {
+		if slices.Contains(columns, column) {
+			dest = append(dest, all[i])
+		}
+	}
+	return row.Scan(dest...)
+}
+
+// values returns the fields in productColumns order.
+func (r *productRow) values() []any {
+	return []any{
+		r.ID, r.Name, r.Description, r.PriceCents, r.Currency, r.Quantity,
+		r.Tags, r.Status, r.CreatedAt, r.UpdatedAt, r.CategoryID,
+		r.CreatedBy, r.UpdatedBy, r.SKU, r.Barcode, r.Attributes,
+		r.ReorderPoint, r.ReorderQuantity,
+	}
+}
+
+func (r *productRow) toProto() *pb.Product {
+	return &pb.Product{
+		Id:          r.ID,
+		Name:        r.Name,
+		Description: r.Description,
+		Price:       float64(r.PriceCents) / 100,
+		PriceMoney:  money(r.PriceCents, r.Currency),
+		Quantity:    r.Quantity,
+		Tags:        r.Tags,
+		Available:   r.Status == statusColumn(pb.Product_ACTIVE),
+		Status:      parseStatus(r.Status),
+		CreatedAt:   timestamppb.New(r.CreatedAt),
+		UpdatedAt:   timestamppb.New(r.UpdatedAt),
+		CategoryId:  valueOrEmpty(r.CategoryID),
+		CreatedBy:   valueOrEmpty(r.CreatedBy),
+		UpdatedBy:   valueOrEmpty(r.UpdatedBy),
+		Sku:         valueOrEmpty(r.SKU),
+		Barcode:     valueOrEmpty(r.Barcode),
+		Attributes:  r.Attributes,
+
+		ReorderPoint:    r.ReorderPoint,
+		ReorderQuantity: r.ReorderQuantity,
+	}
+}
+
+// nullable maps an empty string to NULL.
+func nullable(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// valueOrEmpty maps NULL to an empty string.
+func valueOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
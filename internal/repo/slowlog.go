@@ -0,0 +1,157 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/andro-kes/inventory_service/internal/auth"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// SlowQueryTracer is a pgx.QueryTracer logging every statement running
+// for Threshold or longer, with the repo operation that sent it, e.g.
+// ProductRepo.List, the duration and the number of arguments. Argument
+// values are never logged.
+type SlowQueryTracer struct {
+	Logger    *zap.Logger
+	Threshold time.Duration
+	// Explain adds the EXPLAIN (ANALYZE, BUFFERS) plan of slow SELECT,
+	// INSERT, UPDATE and DELETE statements to the log. ANALYZE runs the
+	// statement once more, in a transaction or savepoint that is rolled
+	// back, so it doubles the load of slow queries and may wait on locks:
+	// enable it for debugging only.
+	Explain bool
+}
+
+// NewSlowQueryTracer returns a tracer logging statements slower than
+// threshold to logger. Install it as pgx.ConnConfig.Tracer.
+func NewSlowQueryTracer(logger *zap.Logger, threshold time.Duration) *SlowQueryTracer {
+	return &SlowQueryTracer{
+		Logger:    logger,
+		Threshold: threshold,
+	}
+}
+
+// slowQueryKey is the context key of the statement being timed.
+type slowQueryKey struct{}
+
+// explainKey marks the context of the statements sent by explain, which
+// are not traced.
+type explainKey struct{}
+
+type slowQuery struct {
+	start time.Time
+	sql   string
+	args  []any
+}
+
+// TraceQueryStart implements pgx.QueryTracer.
+func (t *SlowQueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if ctx.Value(explainKey{}) != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, slowQueryKey{}, slowQuery{
+		start: time.Now(),
+		sql:   data.SQL,
+		args:  data.Args,
+	})
+}
+
+// TraceQueryEnd implements pgx.QueryTracer.
+func (t *SlowQueryTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	q, ok := ctx.Value(slowQueryKey{}).(slowQuery)
+	if !ok {
+		return
+	}
+	elapsed := time.Since(q.start)
+	if elapsed < t.Threshold {
+		return
+	}
+
+	fields := []zap.Field{
+		zap.String("statement", statementName(ctx, q.sql)),
+		zap.Duration("duration", elapsed),
+		zap.Int("args", len(q.args)),
+		zap.String("sql", q.sql),
+	}
+	if r := route(ctx); r != "" {
+		fields = append(fields, zap.String("route", r))
+	}
+	if principal := auth.Principal(ctx); principal != "" {
+		fields = append(fields, zap.String("principal", principal))
+	}
+	if data.Err != nil {
+		fields = append(fields, zap.Error(data.Err))
+	} else if t.Explain && conn != nil && explainable(q.sql) {
+		plan, err := explain(ctx, conn, q)
+		if err != nil {
+			fields = append(fields, zap.NamedError("explain_error", err))
+		} else {
+			fields = append(fields, zap.String("plan", plan))
+		}
+	}
+	t.Logger.Warn("slow query", fields...)
+}
+
+// explainable reports whether EXPLAIN accepts the statement sql.
+func explainable(sql string) bool {
+	command, _, _ := strings.Cut(strings.TrimSpace(sql), " ")
+	switch strings.ToUpper(command) {
+	case "SELECT", "INSERT", "UPDATE", "DELETE", "WITH":
+		return true
+	}
+	return false
+}
+
+// explain runs q again under EXPLAIN (ANALYZE, BUFFERS) on conn and
+// returns the plan. The run is rolled back: in its own transaction, or in
+// a savepoint if conn is in a transaction. It is skipped in a failed
+// transaction, which rejects any statement.
+func explain(ctx context.Context, conn *pgx.Conn, q slowQuery) (plan string, err error) {
+	ctx = context.WithValue(ctx, explainKey{}, true)
+
+	begin, rollback := "BEGIN", []string{"ROLLBACK"}
+	switch conn.PgConn().TxStatus() {
+	case 'I':
+	case 'T':
+		begin = "SAVEPOINT explain"
+		rollback = []string{"ROLLBACK TO SAVEPOINT explain", "RELEASE SAVEPOINT explain"}
+	default:
+		return "", errors.New("transaction is aborted")
+	}
+
+	if _, err := conn.Exec(ctx, begin); err != nil {
+		return "", err
+	}
+	defer func() {
+		for _, sql := range rollback {
+			if _, rbErr := conn.Exec(ctx, sql); rbErr != nil && err == nil {
+				err = rbErr
+			}
+		}
+	}()
+
+	args := append([]any{pgx.QueryExecModeExec}, q.args...)
+	rows, err := conn.Query(ctx, "EXPLAIN (ANALYZE, BUFFERS) "+q.sql, args...)
+	if err != nil {
+		return "", err
+	}
+	lines, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// statementName names the statement after the repo operation of ctx or,
+// for statements sent outside the repo, after its SQL command.
+func statementName(ctx context.Context, sql string) string {
+	if op := operation(ctx); op != "" {
+		return op
+	}
+	command, _, _ := strings.Cut(strings.TrimSpace(sql), " ")
+	return strings.ToUpper(command)
+}
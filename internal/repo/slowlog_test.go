@@ -0,0 +1,59 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andro-kes/inventory_service/internal/auth"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// TestSlowQueryTracer tests that only statements reaching the threshold
+// are logged, named after the repo operation.
+func TestSlowQueryTracer(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	ctx := context.WithValue(context.Background(), opKey{}, "ProductRepo.List")
+	ctx = auth.NewContext(ctx, "apikey:nightly-import")
+	start := pgx.TraceQueryStartData{SQL: "SELECT id FROM products WHERE id = $1", Args: []any{"1"}}
+
+	fast := NewSlowQueryTracer(zap.New(core), time.Hour)
+	fast.TraceQueryEnd(fast.TraceQueryStart(ctx, nil, start), nil, pgx.TraceQueryEndData{})
+	if logs.Len() != 0 {
+		t.Fatalf("Expected no logs, got: %d", logs.Len())
+	}
+
+	slow := NewSlowQueryTracer(zap.New(core), 0)
+	slow.TraceQueryEnd(slow.TraceQueryStart(ctx, nil, start), nil, pgx.TraceQueryEndData{})
+	slow.TraceQueryEnd(slow.TraceQueryStart(context.Background(), nil, start), nil, pgx.TraceQueryEndData{})
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 logs, got: %d", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields["statement"] != "ProductRepo.List" || fields["args"] != int64(1) || fields["principal"] != "apikey:nightly-import" {
+		t.Errorf("Unexpected fields: %v", fields)
+	}
+	if name := entries[1].ContextMap()["statement"]; name != "SELECT" {
+		t.Errorf("Expected statement SELECT outside the repo, got: %v", name)
+	}
+}
+
+// TestExplainable tests which statements are explained.
+func TestExplainable(t *testing.T) {
+	for sql, want := range map[string]bool{
+		"SELECT id FROM products":               true,
+		"  update products SET quantity = 1":    true,
+		"WITH t AS (SELECT 1) SELECT * FROM t":  true,
+		"FETCH FORWARD 1000 FROM products_all":  false,
+		"DECLARE products_all NO SCROLL CURSOR": false,
+		"BEGIN":                                 false,
+	} {
+		if got := explainable(sql); got != want {
+			t.Errorf("explainable(%q) = %v, want %v", sql, got, want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package repo
+
+import (
+	"github.com/andro-kes/inventory_service/internal/inverr"
+	pb "github.com/andro-kes/inventory_service/proto"
+	"google.golang.org/grpc/codes"
+)
+
+// productStatuses maps the Product statuses to the products.status column.
+var productStatuses = map[pb.Product_Status]string{
+	pb.Product_DRAFT:        "draft",
+	pb.Product_ACTIVE:       "active",
+	pb.Product_ARCHIVED:     "archived",
+	pb.Product_DISCONTINUED: "discontinued",
+}
+
+// statusTransitions lists the statuses a product may move to from each
+// status.
+var statusTransitions = map[pb.Product_Status][]pb.Product_Status{
+	pb.Product_DRAFT:    {pb.Product_ACTIVE, pb.Product_DISCONTINUED},
+	pb.Product_ACTIVE:   {pb.Product_ARCHIVED, pb.Product_DISCONTINUED},
+	pb.Product_ARCHIVED: {pb.Product_ACTIVE, pb.Product_DISCONTINUED},
+}
+
+// StatusOf returns the status of p: its status if set, otherwise ACTIVE
+// for the deprecated available and DRAFT without it.
+func StatusOf(p *pb.Product) pb.Product_Status {
+	switch {
+	case p.GetStatus() != pb.Product_STATUS_UNSPECIFIED:
+		return p.GetStatus()
+	case p.GetAvailable():
+		return pb.Product_ACTIVE
+	default:
+		return pb.Product_DRAFT
+	}
+}
+
+// CheckTransition returns a FailedPrecondition error unless a product may
+// move from status from to status to. Staying in a status is allowed.
+func CheckTransition(from, to pb.Product_Status) error {
+	if from == to {
+		return nil
+	}
+	for _, next := range statusTransitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return inverr.WithReason(codes.FailedPrecondition, inverr.ReasonInvalidStatusTransition,
+		map[string]string{"from": from.String(), "to": to.String()},
+		"product cannot move from %s to %s", from, to)
+}
+
+// validateStatus checks that the status of p, if set, is a known one.
+func validateStatus(p *pb.Product) error {
+	if _, ok := productStatuses[StatusOf(p)]; !ok {
+		return inverr.InvalidField("status", "invalid status %d", p.GetStatus())
+	}
+	return nil
+}
+
+// statusColumn returns the products.status value of s, "" if unknown.
+func statusColumn(s pb.Product_Status) string {
+	return productStatuses[s]
+}
+
+// parseStatus reverses statusColumn.
+func parseStatus(column string) pb.Product_Status {
+	for s, c := range productStatuses {
+		if c == column {
+			return s
+		}
+	}
+	return pb.Product_STATUS_UNSPECIFIED
+}
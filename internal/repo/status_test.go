@@ -0,0 +1,58 @@
+package repo
+
+import (
+	"testing"
+
+	pb "github.com/andro-kes/inventory_service/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCheckTransition(t *testing.T) {
+	cases := []struct {
+		from, to pb.Product_Status
+		allowed  bool
+	}{
+		{pb.Product_DRAFT, pb.Product_ACTIVE, true},
+		{pb.Product_DRAFT, pb.Product_DISCONTINUED, true},
+		{pb.Product_DRAFT, pb.Product_ARCHIVED, false},
+		{pb.Product_ACTIVE, pb.Product_ARCHIVED, true},
+		{pb.Product_ACTIVE, pb.Product_DRAFT, false},
+		{pb.Product_ARCHIVED, pb.Product_ACTIVE, true},
+		{pb.Product_ARCHIVED, pb.Product_DISCONTINUED, true},
+		{pb.Product_DISCONTINUED, pb.Product_ACTIVE, false},
+		{pb.Product_DISCONTINUED, pb.Product_DISCONTINUED, true},
+	}
+
+	for _, tc := range cases {
+		err := CheckTransition(tc.from, tc.to)
+		if tc.allowed && err != nil {
+			t.Errorf("Expected %s -> %s to be allowed, got: %v", tc.from, tc.to, err)
+		}
+		if !tc.allowed && status.Code(err) != codes.FailedPrecondition {
+			t.Errorf("Expected FailedPrecondition for %s -> %s, got: %v", tc.from, tc.to, err)
+		}
+	}
+}
+
+// TestStatusOf tests that status takes precedence over the deprecated
+// available.
+func TestStatusOf(t *testing.T) {
+	cases := []struct {
+		product  *pb.Product
+		expected pb.Product_Status
+	}{
+		{&pb.Product{}, pb.Product_DRAFT},
+		{&pb.Product{Available: true}, pb.Product_ACTIVE},
+		{&pb.Product{Available: true, Status: pb.Product_ARCHIVED}, pb.Product_ARCHIVED},
+	}
+
+	for _, tc := range cases {
+		if got := StatusOf(tc.product); got != tc.expected {
+			t.Errorf("StatusOf(%v) = %s, want %s", tc.product, got, tc.expected)
+		}
+	}
+	if err := validateStatus(&pb.Product{Status: 42}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an unknown status, got: %v", err)
+	}
+}
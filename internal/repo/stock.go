@@ -0,0 +1,163 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/andro-kes/inventory_service/internal/inverr"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// InsufficientStockError is returned when a product has fewer units in
+// stock than requested.
+type InsufficientStockError struct {
+	ID        string
+	Requested int32
+	Available int32
+}
+
+func (e *InsufficientStockError) Error() string {
+	return fmt.Sprintf("insufficient stock for product %s: requested %d, available %d", e.ID, e.Requested, e.Available)
+}
+
+// StockErrorReason is the ErrorInfo reason of an InsufficientStockError
+// status.
+const StockErrorReason = inverr.ReasonInsufficientStock
+
+// GRPCStatus returns a FailedPrecondition status carrying the requested and
+// available quantities as ErrorInfo metadata, so clients need not parse
+// the message.
+func (e *InsufficientStockError) GRPCStatus() *status.Status {
+	st := status.New(codes.FailedPrecondition, e.Error())
+	detailed, err := st.WithDetails(
+		&errdetails.ErrorInfo{
+			Reason: StockErrorReason,
+			Domain: inverr.Domain,
+			Metadata: map[string]string{
+				"product_id": e.ID,
+				"requested":  strconv.Itoa(int(e.Requested)),
+				"available":  strconv.Itoa(int(e.Available)),
+			},
+		},
+		&errdetails.PreconditionFailure{
+			Violations: []*errdetails.PreconditionFailure_Violation{{
+				Type:        "STOCK",
+				Subject:     "products/" + e.ID,
+				Description: fmt.Sprintf("requested %d, available %d", e.Requested, e.Available),
+			}},
+		},
+	)
+	if err != nil {
+		return st
+	}
+	return detailed
+}
+
+// DecrementQuantity atomically takes delta units of the product out of
+// stock, records the movement in the stock ledger and returns the
+// remaining quantity. Units held by active reservations are not taken:
+// if fewer than delta units are free an *InsufficientStockError is
+// returned and nothing changes.
+func (pr *productRepo) DecrementQuantity(ctx context.Context, id string, delta int32) (int32, error) {
+	if delta <= 0 {
+		return 0, inverr.InvalidField("quantity", "delta must be positive: %d", delta)
+	}
+
+	tx, err := pr.db(ctx).Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	quantity, err := takeStock(ctx, tx, id, delta, ReasonDecrement, "")
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return quantity, nil
+}
+
+// takeStock takes delta free units of the product out of stock in the
+// transaction of q, records the movement with reason and reference and
+// returns the remaining quantity, or an *InsufficientStockError.
+func takeStock(ctx context.Context, q Querier, id string, delta int32, reason, reference string) (int32, error) {
+	sql, args := productsQuery(ctx).
+		Update("products").
+		Set("quantity = quantity - ?", delta).
+		Set("updated_at = ?", time.Now()).
+		Where("id = ?", id).
+		Where(freeQuantity+" >= ?", delta).
+		Returning("quantity").
+		Build()
+
+	if err := lockProduct(ctx, q, id); err != nil {
+		return 0, err
+	}
+	if err := labelMovement(ctx, q, reason, reference); err != nil {
+		return 0, err
+	}
+	var quantity int32
+	err := q.QueryRow(ctx, sql, args...).Scan(&quantity)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, stockError(ctx, q, id, delta)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return quantity, nil
+}
+
+// lockProduct locks the product row until the transaction of q ends, or
+// fails with NotFound. ReserveStock holds the same lock while it inserts a
+// reservation, so the statements after lockProduct see every reservation
+// of the product; a conditional UPDATE rechecked after waiting for the
+// row would not.
+func lockProduct(ctx context.Context, q Querier, id string) error {
+	sql, args := productsQuery(ctx).
+		Select("id").
+		From("products").
+		Where("id = ?", id).
+		ForUpdate().
+		Build()
+
+	var locked string
+	err := q.QueryRow(ctx, sql, args...).Scan(&locked)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return status.Errorf(codes.NotFound, "product not found: %s", id)
+	}
+	return err
+}
+
+// stockError explains why taking delta units of the product failed,
+// reporting the units not held by active reservations as available.
+func stockError(ctx context.Context, q Querier, id string, delta int32) error {
+	sql, args := productsQuery(ctx).
+		Select(freeQuantity).
+		From("products").
+		Where("id = ?", id).
+		Build()
+
+	var free int64
+	err := q.QueryRow(ctx, sql, args...).Scan(&free)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return status.Errorf(codes.NotFound, "product not found: %s", id)
+	}
+	if err != nil {
+		return err
+	}
+
+	return &InsufficientStockError{ID: id, Requested: delta, Available: int32(max(free, 0))}
+}
@@ -0,0 +1,308 @@
+package repo
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/andro-kes/inventory_service/internal/repo/builder"
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Tag is a tag of products. Tags are matched by their TagKey, so that
+// "Electronics" and "ELECTRONICS" are the same tag. Registered tags are
+// stored on products as their Name; other tags in lower case.
+type Tag struct {
+	// Name is the tag as stored on products.
+	Name string
+	// Registered reports whether the tag is in the registry rather than
+	// only on products.
+	Registered bool
+	// Aliases are the keys of the tags stored as this tag instead, in
+	// order.
+	Aliases []string
+	// Products is the number of products with the tag, deleted ones
+	// aside.
+	Products int64
+}
+
+// TagKey returns the key tag is matched by: the tag in lower case.
+func TagKey(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// TagRepo stores the registry of tags: their canonical names and the
+// aliases merged into them.
+type TagRepo interface {
+	// Resolve returns the names of the registered tags and aliases among
+	// keys, by key. Other keys are left out.
+	Resolve(ctx context.Context, keys []string) (map[string]string, error)
+	// Get returns the registered tag with the key of tag.
+	Get(ctx context.Context, tag string) (*Tag, error)
+	// List returns the registered tags and the tags of products, by name.
+	List(ctx context.Context) ([]*Tag, error)
+	// Merge registers name, makes the tags of from its aliases, and
+	// retags the products with any of them, or with name in another
+	// casing, as name. It returns the number of products retagged,
+	// deleted ones included.
+	Merge(ctx context.Context, name string, from []string) (int64, error)
+}
+
+type tagRepo struct {
+	DB Querier
+}
+
+// NewTagRepo returns a TagRepo running its queries on db.
+func NewTagRepo(ctx context.Context, db Querier) TagRepo {
+	return &tracingTagRepo{
+		next: &tagRepo{
+			DB: db,
+		},
+		tracer: newTracer("TagRepo"),
+	}
+}
+
+func (tr *tagRepo) db(ctx context.Context) Querier {
+	return conn(ctx, tr.DB)
+}
+
+// tagAliases selects the aliases of the tags row, in order.
+const tagAliases = "ARRAY(SELECT alias FROM tag_aliases a WHERE a.tag = tags.key ORDER BY alias)"
+
+func (tr *tagRepo) Resolve(ctx context.Context, keys []string) (map[string]string, error) {
+	names := make(map[string]string)
+	if len(keys) == 0 {
+		return names, nil
+	}
+
+	sql, args := newQuery(ctx).
+		Select("key", "name").
+		From("tags").
+		Where("key = ANY(?)", keys).
+		UnionAll(builder.NewSQLBuilder().
+			Select("a.alias", "t.name").
+			From("tag_aliases a").
+			Join("tags t", "t.key = a.tag").
+			Where("a.alias = ANY(?)", keys)).
+		Build()
+
+	rows, err := tr.db(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key, name string
+		if err := rows.Scan(&key, &name); err != nil {
+			return nil, err
+		}
+		names[key] = name
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return names, nil
+}
+
+func (tr *tagRepo) Get(ctx context.Context, tag string) (*Tag, error) {
+	sql, args := newQuery(ctx).
+		Select("name", tagAliases).
+		From("tags").
+		Where("key = ?", TagKey(tag)).
+		Build()
+
+	t := &Tag{Registered: true}
+	err := tr.db(ctx).QueryRow(ctx, sql, args...).Scan(&t.Name, &t.Aliases)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, status.Errorf(codes.NotFound, "tag not found: %s", tag)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sql, args = productsQuery(ctx).
+		SelectCount("*").
+		From("products").
+		WhereArrayContains("tags", t.Name).
+		Build()
+
+	if err := tr.db(ctx).QueryRow(ctx, sql, args...).Scan(&t.Products); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (tr *tagRepo) List(ctx context.Context) ([]*Tag, error) {
+	sql, args := newQuery(ctx).
+		Select("key", "name", tagAliases).
+		From("tags").
+		Build()
+
+	rows, err := tr.db(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := make(map[string]*Tag)
+	aliases := make(map[string]*Tag)
+	for rows.Next() {
+		var key string
+		t := &Tag{Registered: true}
+		if err := rows.Scan(&key, &t.Name, &t.Aliases); err != nil {
+			return nil, err
+		}
+		tags[key] = t
+		for _, alias := range t.Aliases {
+			aliases[alias] = t
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sql, args = productsQuery(ctx).
+		Select("t.tag").
+		SelectCount("*").
+		From("products").
+		Join("unnest(products.tags) AS t(tag)", "true").
+		GroupBy("t.tag").
+		Build()
+
+	rows, err = tr.db(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tag string
+		var n int64
+		if err := rows.Scan(&tag, &n); err != nil {
+			return nil, err
+		}
+		// Products tagged before the registry, or in another casing,
+		// count for the tag they will be stored as.
+		key := TagKey(tag)
+		t, ok := tags[key]
+		if !ok {
+			t, ok = aliases[key]
+		}
+		if !ok {
+			t = &Tag{Name: key}
+			tags[key] = t
+		}
+		t.Products += n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	list := slices.Collect(maps.Values(tags))
+	slices.SortFunc(list, func(a, b *Tag) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return list, nil
+}
+
+func (tr *tagRepo) Merge(ctx context.Context, name string, from []string) (int64, error) {
+	name = strings.TrimSpace(name)
+	key := TagKey(name)
+	var aliases []string
+	for _, tag := range from {
+		if alias := TagKey(tag); alias != key && alias != "" && !slices.Contains(aliases, alias) {
+			aliases = append(aliases, alias)
+		}
+	}
+	now := time.Now()
+
+	tx, err := tr.db(ctx).Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	statements := []*builder.SQLBuilder{
+		newQuery(ctx).
+			Insert("tags").
+			Columns("key", "name", "created_at", "updated_at").
+			Values(key, name, now, now).
+			OnConflict("key").
+			DoUpdateSet("name = EXCLUDED.name, updated_at = EXCLUDED.updated_at"),
+		// name is a tag now rather than an alias of another one.
+		newQuery(ctx).
+			Delete().
+			From("tag_aliases").
+			Where("alias = ?", key),
+	}
+	if len(aliases) > 0 {
+		rows := make([][]any, len(aliases))
+		for i, alias := range aliases {
+			rows[i] = []any{alias, key, now}
+		}
+		statements = append(statements,
+			newQuery(ctx).
+				Update("tag_aliases").
+				Set("tag = ?", key).
+				Where("tag = ANY(?)", aliases),
+			newQuery(ctx).
+				Delete().
+				From("tags").
+				Where("key = ANY(?)", aliases),
+			newQuery(ctx).
+				Insert("tag_aliases").
+				Columns("alias", "tag", "created_at").
+				ValuesRows(rows).
+				OnConflict("alias").
+				DoUpdateSet("tag = EXCLUDED.tag"),
+		)
+	}
+	for _, b := range statements {
+		sql, args := b.Build()
+		if _, err := tx.Exec(ctx, sql, args...); err != nil {
+			return 0, err
+		}
+	}
+
+	// Tags are rewritten in place, duplicates dropped at their first
+	// position.
+	keys := append([]string{key}, aliases...)
+	sql, args := newQuery(ctx).
+		Update("products").
+		Set("tags = ARRAY(SELECT CASE WHEN lower(u.tag) = ANY(?) THEN ? ELSE u.tag END "+
+			"FROM unnest(tags) WITH ORDINALITY AS u(tag, n) GROUP BY 1 ORDER BY min(u.n))", keys, name).
+		Set("updated_at = ?", now).
+		Set("updated_by = ?", actor(ctx)).
+		Where("EXISTS (SELECT 1 FROM unnest(tags) AS tag WHERE lower(tag) = ANY(?) AND tag <> ?)", keys, name).
+		Returning("id").
+		Build()
+
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		return 0, err
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return 0, err
+	}
+	if err = notifyChanges(ctx, tx, ids, OpUpdate); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return int64(len(ids)), nil
+}
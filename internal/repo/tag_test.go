@@ -0,0 +1,106 @@
+package repo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+// TestTagResolve tests that tags and aliases resolve to their names.
+func TestTagResolve(t *testing.T) {
+	_, mock := newMockRepo(t)
+	tr := &tagRepo{DB: mock}
+	keys := []string{"electronics", "electronic", "sale"}
+
+	mock.ExpectQuery("SELECT key, name FROM tags WHERE key = ANY($1) "+
+		"UNION ALL SELECT a.alias, t.name FROM tag_aliases a JOIN tags t ON t.key = a.tag WHERE a.alias = ANY($2)").
+		WithArgs(keys, keys).
+		WillReturnRows(mock.NewRows([]string{"key", "name"}).
+			AddRow("electronics", "Electronics").
+			AddRow("electronic", "Electronics"))
+
+	names, err := tr.Resolve(context.Background(), keys)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(names) != 2 || names["electronic"] != "Electronics" {
+		t.Errorf("Expected both keys of Electronics, got: %v", names)
+	}
+}
+
+// TestTagList tests that product counts of tags in other casings and of
+// aliases add up to their tag.
+func TestTagList(t *testing.T) {
+	_, mock := newMockRepo(t)
+	tr := &tagRepo{DB: mock}
+
+	mock.ExpectQuery("SELECT key, name, " + tagAliases + " FROM tags").
+		WillReturnRows(mock.NewRows([]string{"key", "name", "aliases"}).
+			AddRow("electronics", "Electronics", []string{"electronic"}))
+	mock.ExpectQuery("SELECT t.tag, COUNT(*) FROM products JOIN unnest(products.tags) AS t(tag) ON true " +
+		"WHERE products.deleted_at IS NULL GROUP BY t.tag").
+		WillReturnRows(mock.NewRows([]string{"tag", "count"}).
+			AddRow("Electronics", int64(3)).
+			AddRow("electronic", int64(1)).
+			AddRow("Sale", int64(2)))
+
+	tags, err := tr.List(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(tags) != 2 {
+		t.Fatalf("Expected 2 tags, got: %d", len(tags))
+	}
+	if tags[0].Name != "Electronics" || !tags[0].Registered || tags[0].Products != 4 {
+		t.Errorf("Expected Electronics with 4 products, got: %+v", tags[0])
+	}
+	if tags[1].Name != "sale" || tags[1].Registered || tags[1].Products != 2 {
+		t.Errorf("Expected sale with 2 products, got: %+v", tags[1])
+	}
+}
+
+// TestTagMerge tests that merging registers the tag, moves the aliases
+// and retags the products.
+func TestTagMerge(t *testing.T) {
+	_, mock := newMockRepo(t)
+	tr := &tagRepo{DB: mock}
+	aliases := []string{"electronic", "gadgets"}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO tags (key, name, created_at, updated_at) VALUES ($1, $2, $3, $4) "+
+		"ON CONFLICT (key) DO UPDATE SET name = EXCLUDED.name, updated_at = EXCLUDED.updated_at").
+		WithArgs("electronics", "Electronics", pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectExec("DELETE FROM tag_aliases WHERE alias = $1").
+		WithArgs("electronics").
+		WillReturnResult(pgxmock.NewResult("DELETE", 0))
+	mock.ExpectExec("UPDATE tag_aliases SET tag = $1 WHERE tag = ANY($2)").
+		WithArgs("electronics", aliases).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	mock.ExpectExec("DELETE FROM tags WHERE key = ANY($1)").
+		WithArgs(aliases).
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectExec("INSERT INTO tag_aliases (alias, tag, created_at) VALUES ($1, $2, $3), ($4, $5, $6) "+
+		"ON CONFLICT (alias) DO UPDATE SET tag = EXCLUDED.tag").
+		WithArgs("electronic", "electronics", pgxmock.AnyArg(), "gadgets", "electronics", pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 2))
+	keys := []string{"electronics", "electronic", "gadgets"}
+	mock.ExpectQuery("UPDATE products SET tags = ARRAY(SELECT CASE WHEN lower(u.tag) = ANY($1) THEN $2 ELSE u.tag END "+
+		"FROM unnest(tags) WITH ORDINALITY AS u(tag, n) GROUP BY 1 ORDER BY min(u.n)), updated_at = $3, updated_by = NULL "+
+		"WHERE EXISTS (SELECT 1 FROM unnest(tags) AS tag WHERE lower(tag) = ANY($4) AND tag <> $5) RETURNING id").
+		WithArgs(keys, "Electronics", pgxmock.AnyArg(), keys, "Electronics").
+		WillReturnRows(mock.NewRows([]string{"id"}).AddRow(testProductID))
+	mock.ExpectExec(notifyChangesSQL).
+		WithArgs(ChangesChannel, pgxmock.AnyArg(), "", "").
+		WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectCommit()
+
+	retagged, err := tr.Merge(context.Background(), " Electronics", []string{"Electronic", "GADGETS", "electronics", "gadgets"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if retagged != 1 {
+		t.Errorf("Expected 1 product retagged, got: %d", retagged)
+	}
+}
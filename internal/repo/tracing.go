@@ -0,0 +1,535 @@
+package repo
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	pb "github.com/andro-kes/inventory_service/proto"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// tracerName is the instrumentation scope of the repo spans.
+const tracerName = "github.com/andro-kes/inventory_service/internal/repo"
+
+// opKey is the context key of the repo operation being traced.
+type opKey struct{}
+
+// operation returns the repo operation of ctx, e.g. ProductRepo.Get, or
+// "" outside a repo call.
+func operation(ctx context.Context) string {
+	op, _ := ctx.Value(opKey{}).(string)
+	return op
+}
+
+// tracer starts a client span per repo call named after the repo and the
+// method, e.g. ProductRepo.Get. Spans are children of the span in ctx, so
+// with a traced gRPC server they nest under the RPC span. The span context
+// also carries the operation name for SlowQueryTracer.
+type tracer struct {
+	tracer trace.Tracer
+	repo   string
+}
+
+func newTracer(repo string) tracer {
+	return tracer{
+		tracer: otel.Tracer(tracerName),
+		repo:   repo,
+	}
+}
+
+func (t tracer) start(ctx context.Context, op string) (context.Context, trace.Span) {
+	name := t.repo + "." + op
+	ctx = context.WithValue(ctx, opKey{}, name)
+	return t.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNamePostgreSQL,
+			semconv.DBOperationName(op),
+		),
+	)
+}
+
+// end records the returned rows, unless rows is negative, and err on span
+// and ends it.
+func end(span trace.Span, rows int, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else if rows >= 0 {
+		span.SetAttributes(semconv.DBResponseReturnedRows(rows))
+	}
+	span.End()
+}
+
+// traceValue runs fn in a span; rows counts the rows of its result.
+func traceValue[T any](ctx context.Context, t tracer, op string, rows func(T) int, fn func(ctx context.Context) (T, error)) (T, error) {
+	ctx, span := t.start(ctx, op)
+	v, err := fn(ctx)
+	end(span, rows(v), err)
+	return v, err
+}
+
+// traceErr runs fn, which affects a single row, in a span.
+func traceErr(ctx context.Context, t tracer, op string, fn func(ctx context.Context) error) error {
+	ctx, span := t.start(ctx, op)
+	err := fn(ctx)
+	end(span, 1, err)
+	return err
+}
+
+// Row counters for traceValue.
+func rowsOne[T any](T) int                        { return 1 }
+func rowsLen[T any](v []T) int                    { return len(v) }
+func rowsKeys[K comparable, V any](m map[K]V) int { return len(m) }
+func rowsCount[T int32 | int64](n T) int          { return int(n) }
+func rowsUnknown[T any](T) int                    { return -1 }
+
+// tracingRepo traces the operations of a ProductRepo.
+type tracingRepo struct {
+	next   ProductRepo
+	tracer tracer
+}
+
+func (r *tracingRepo) Create(ctx context.Context, p *pb.Product) (*pb.Product, error) {
+	return traceValue(ctx, r.tracer, "Create", rowsOne, func(ctx context.Context) (*pb.Product, error) {
+		return r.next.Create(ctx, p)
+	})
+}
+
+func (r *tracingRepo) CreateIdempotent(ctx context.Context, key string, ttl time.Duration, p *pb.Product) (*pb.Product, error) {
+	return traceValue(ctx, r.tracer, "CreateIdempotent", rowsOne, func(ctx context.Context) (*pb.Product, error) {
+		return r.next.CreateIdempotent(ctx, key, ttl, p)
+	})
+}
+
+func (r *tracingRepo) CreateMany(ctx context.Context, ps []*pb.Product) ([]*pb.Product, error) {
+	return traceValue(ctx, r.tracer, "CreateMany", rowsLen, func(ctx context.Context) ([]*pb.Product, error) {
+		return r.next.CreateMany(ctx, ps)
+	})
+}
+
+func (r *tracingRepo) CopyFrom(ctx context.Context, ps []*pb.Product, progress func(copied int64)) (int64, error) {
+	return traceValue(ctx, r.tracer, "CopyFrom", rowsCount, func(ctx context.Context) (int64, error) {
+		return r.next.CopyFrom(ctx, ps, progress)
+	})
+}
+
+func (r *tracingRepo) Delete(ctx context.Context, id string) error {
+	return traceErr(ctx, r.tracer, "Delete", func(ctx context.Context) error {
+		return r.next.Delete(ctx, id)
+	})
+}
+
+func (r *tracingRepo) DeleteWhere(ctx context.Context, filter ListFilter) ([]string, error) {
+	return traceValue(ctx, r.tracer, "DeleteWhere", rowsLen, func(ctx context.Context) ([]string, error) {
+		return r.next.DeleteWhere(ctx, filter)
+	})
+}
+
+func (r *tracingRepo) Restore(ctx context.Context, id string) (*pb.Product, error) {
+	return traceValue(ctx, r.tracer, "Restore", rowsOne, func(ctx context.Context) (*pb.Product, error) {
+		return r.next.Restore(ctx, id)
+	})
+}
+
+func (r *tracingRepo) Purge(ctx context.Context, olderThan time.Duration) (int64, error) {
+	return traceValue(ctx, r.tracer, "Purge", rowsCount, func(ctx context.Context) (int64, error) {
+		return r.next.Purge(ctx, olderThan)
+	})
+}
+
+func (r *tracingRepo) List(ctx context.Context, prevSize, pageSize int32, filter ListFilter, orderBy string) ([]*pb.Product, error) {
+	return traceValue(ctx, r.tracer, "List", rowsLen, func(ctx context.Context) ([]*pb.Product, error) {
+		return r.next.List(ctx, prevSize, pageSize, filter, orderBy)
+	})
+}
+
+func (r *tracingRepo) ListAfter(ctx context.Context, cursor string, pageSize int32, filter ListFilter, orderBy string) ([]*pb.Product, string, error) {
+	var next string
+	products, err := traceValue(ctx, r.tracer, "ListAfter", rowsLen, func(ctx context.Context) ([]*pb.Product, error) {
+		var (
+			products []*pb.Product
+			err      error
+		)
+		products, next, err = r.next.ListAfter(ctx, cursor, pageSize, filter, orderBy)
+		return products, err
+	})
+	return products, next, err
+}
+
+func (r *tracingRepo) Count(ctx context.Context, filter ListFilter) (int64, error) {
+	return traceValue(ctx, r.tracer, "Count", rowsUnknown, func(ctx context.Context) (int64, error) {
+		return r.next.Count(ctx, filter)
+	})
+}
+
+func (r *tracingRepo) ListAll(ctx context.Context, filter ListFilter, fn func(*pb.Product) error) error {
+	ctx, span := r.tracer.start(ctx, "ListAll")
+	rows := 0
+	err := r.next.ListAll(ctx, filter, func(p *pb.Product) error {
+		rows++
+		return fn(p)
+	})
+	end(span, rows, err)
+	return err
+}
+
+func (r *tracingRepo) Update(ctx context.Context, p *pb.Product, mask *fieldmaskpb.FieldMask) (*pb.Product, error) {
+	return traceValue(ctx, r.tracer, "Update", rowsOne, func(ctx context.Context) (*pb.Product, error) {
+		return r.next.Update(ctx, p, mask)
+	})
+}
+
+func (r *tracingRepo) Get(ctx context.Context, id string) (*pb.Product, error) {
+	return traceValue(ctx, r.tracer, "Get", rowsOne, func(ctx context.Context) (*pb.Product, error) {
+		return r.next.Get(ctx, id)
+	})
+}
+
+func (r *tracingRepo) GetBySKU(ctx context.Context, sku string) (*pb.Product, error) {
+	return traceValue(ctx, r.tracer, "GetBySKU", rowsOne, func(ctx context.Context) (*pb.Product, error) {
+		return r.next.GetBySKU(ctx, sku)
+	})
+}
+
+func (r *tracingRepo) GetByBarcode(ctx context.Context, barcode string) (*pb.Product, error) {
+	return traceValue(ctx, r.tracer, "GetByBarcode", rowsOne, func(ctx context.Context) (*pb.Product, error) {
+		return r.next.GetByBarcode(ctx, barcode)
+	})
+}
+
+func (r *tracingRepo) UpsertBySKU(ctx context.Context, p *pb.Product) (*pb.Product, error) {
+	return traceValue(ctx, r.tracer, "UpsertBySKU", rowsOne, func(ctx context.Context) (*pb.Product, error) {
+		return r.next.UpsertBySKU(ctx, p)
+	})
+}
+
+func (r *tracingRepo) DecrementQuantity(ctx context.Context, id string, delta int32) (int32, error) {
+	return traceValue(ctx, r.tracer, "DecrementQuantity", rowsOne, func(ctx context.Context) (int32, error) {
+		return r.next.DecrementQuantity(ctx, id, delta)
+	})
+}
+
+func (r *tracingRepo) ReserveStock(ctx context.Context, res *Reservation) (*Reservation, error) {
+	return traceValue(ctx, r.tracer, "ReserveStock", rowsOne, func(ctx context.Context) (*Reservation, error) {
+		return r.next.ReserveStock(ctx, res)
+	})
+}
+
+func (r *tracingRepo) GetReservation(ctx context.Context, id string) (*Reservation, error) {
+	return traceValue(ctx, r.tracer, "GetReservation", rowsOne, func(ctx context.Context) (*Reservation, error) {
+		return r.next.GetReservation(ctx, id)
+	})
+}
+
+func (r *tracingRepo) ConfirmReservation(ctx context.Context, id string) (*Reservation, int32, error) {
+	var quantity int32
+	res, err := traceValue(ctx, r.tracer, "ConfirmReservation", rowsOne, func(ctx context.Context) (*Reservation, error) {
+		var (
+			res *Reservation
+			err error
+		)
+		res, quantity, err = r.next.ConfirmReservation(ctx, id)
+		return res, err
+	})
+	return res, quantity, err
+}
+
+func (r *tracingRepo) ReleaseReservation(ctx context.Context, id string) (*Reservation, error) {
+	return traceValue(ctx, r.tracer, "ReleaseReservation", rowsOne, func(ctx context.Context) (*Reservation, error) {
+		return r.next.ReleaseReservation(ctx, id)
+	})
+}
+
+func (r *tracingRepo) ReleaseExpired(ctx context.Context, limit int) (int64, error) {
+	return traceValue(ctx, r.tracer, "ReleaseExpired", rowsCount, func(ctx context.Context) (int64, error) {
+		return r.next.ReleaseExpired(ctx, limit)
+	})
+}
+
+func (r *tracingRepo) PurgeIdempotencyKeys(ctx context.Context, limit int) (int64, error) {
+	return traceValue(ctx, r.tracer, "PurgeIdempotencyKeys", rowsCount, func(ctx context.Context) (int64, error) {
+		return r.next.PurgeIdempotencyKeys(ctx, limit)
+	})
+}
+
+func (r *tracingRepo) StockOf(ctx context.Context, ids []string) (map[string]Stock, error) {
+	return traceValue(ctx, r.tracer, "StockOf", rowsKeys, func(ctx context.Context) (map[string]Stock, error) {
+		return r.next.StockOf(ctx, ids)
+	})
+}
+
+func (r *tracingRepo) Translations(ctx context.Context, ids []string) (map[string][]*pb.LocalizedText, error) {
+	return traceValue(ctx, r.tracer, "Translations", rowsKeys, func(ctx context.Context) (map[string][]*pb.LocalizedText, error) {
+		return r.next.Translations(ctx, ids)
+	})
+}
+
+// tracingCategoryRepo traces the operations of a CategoryRepo.
+type tracingCategoryRepo struct {
+	next   CategoryRepo
+	tracer tracer
+}
+
+func (r *tracingCategoryRepo) Create(ctx context.Context, c *Category) (*Category, error) {
+	return traceValue(ctx, r.tracer, "Create", rowsOne, func(ctx context.Context) (*Category, error) {
+		return r.next.Create(ctx, c)
+	})
+}
+
+func (r *tracingCategoryRepo) Get(ctx context.Context, id string) (*Category, error) {
+	return traceValue(ctx, r.tracer, "Get", rowsOne, func(ctx context.Context) (*Category, error) {
+		return r.next.Get(ctx, id)
+	})
+}
+
+func (r *tracingCategoryRepo) Update(ctx context.Context, c *Category) (*Category, error) {
+	return traceValue(ctx, r.tracer, "Update", rowsOne, func(ctx context.Context) (*Category, error) {
+		return r.next.Update(ctx, c)
+	})
+}
+
+func (r *tracingCategoryRepo) Delete(ctx context.Context, id string) error {
+	return traceErr(ctx, r.tracer, "Delete", func(ctx context.Context) error {
+		return r.next.Delete(ctx, id)
+	})
+}
+
+func (r *tracingCategoryRepo) Children(ctx context.Context, parentID string) ([]*Category, error) {
+	return traceValue(ctx, r.tracer, "Children", rowsLen, func(ctx context.Context) ([]*Category, error) {
+		return r.next.Children(ctx, parentID)
+	})
+}
+
+func (r *tracingCategoryRepo) Descendants(ctx context.Context, id string) ([]*Category, error) {
+	return traceValue(ctx, r.tracer, "Descendants", rowsLen, func(ctx context.Context) ([]*Category, error) {
+		return r.next.Descendants(ctx, id)
+	})
+}
+
+func (r *tracingCategoryRepo) Ancestors(ctx context.Context, id string) ([]*Category, error) {
+	return traceValue(ctx, r.tracer, "Ancestors", rowsLen, func(ctx context.Context) ([]*Category, error) {
+		return r.next.Ancestors(ctx, id)
+	})
+}
+
+// tracingStockRepo traces the operations of a StockRepo.
+type tracingStockRepo struct {
+	next   StockRepo
+	tracer tracer
+}
+
+func (r *tracingStockRepo) AdjustStock(ctx context.Context, productID string, delta int32, reason, reference string) (int32, error) {
+	return traceValue(ctx, r.tracer, "AdjustStock", rowsOne, func(ctx context.Context) (int32, error) {
+		return r.next.AdjustStock(ctx, productID, delta, reason, reference)
+	})
+}
+
+func (r *tracingStockRepo) Movements(ctx context.Context, productID string, since time.Time) ([]*Movement, error) {
+	return traceValue(ctx, r.tracer, "Movements", rowsLen, func(ctx context.Context) ([]*Movement, error) {
+		return r.next.Movements(ctx, productID, since)
+	})
+}
+
+func (r *tracingStockRepo) Snapshot(ctx context.Context) (time.Time, int64, error) {
+	var products int64
+	takenAt, err := traceValue(ctx, r.tracer, "Snapshot", func(time.Time) int { return int(products) },
+		func(ctx context.Context) (time.Time, error) {
+			var (
+				takenAt time.Time
+				err     error
+			)
+			takenAt, products, err = r.next.Snapshot(ctx)
+			return takenAt, err
+		})
+	return takenAt, products, err
+}
+
+func (r *tracingStockRepo) Discrepancies(ctx context.Context, takenAt time.Time) ([]Discrepancy, error) {
+	return traceValue(ctx, r.tracer, "Discrepancies", rowsLen, func(ctx context.Context) ([]Discrepancy, error) {
+		return r.next.Discrepancies(ctx, takenAt)
+	})
+}
+
+// tracingVariantRepo traces the operations of a VariantRepo.
+type tracingVariantRepo struct {
+	next   VariantRepo
+	tracer tracer
+}
+
+func (r *tracingVariantRepo) Create(ctx context.Context, v *pb.Variant) (*pb.Variant, error) {
+	return traceValue(ctx, r.tracer, "Create", rowsOne, func(ctx context.Context) (*pb.Variant, error) {
+		return r.next.Create(ctx, v)
+	})
+}
+
+func (r *tracingVariantRepo) Get(ctx context.Context, id string) (*pb.Variant, error) {
+	return traceValue(ctx, r.tracer, "Get", rowsOne, func(ctx context.Context) (*pb.Variant, error) {
+		return r.next.Get(ctx, id)
+	})
+}
+
+func (r *tracingVariantRepo) Update(ctx context.Context, v *pb.Variant, mask *fieldmaskpb.FieldMask) (*pb.Variant, error) {
+	return traceValue(ctx, r.tracer, "Update", rowsOne, func(ctx context.Context) (*pb.Variant, error) {
+		return r.next.Update(ctx, v, mask)
+	})
+}
+
+func (r *tracingVariantRepo) Delete(ctx context.Context, id string) error {
+	return traceErr(ctx, r.tracer, "Delete", func(ctx context.Context) error {
+		return r.next.Delete(ctx, id)
+	})
+}
+
+func (r *tracingVariantRepo) ListByProducts(ctx context.Context, ids []string) (map[string][]*pb.Variant, error) {
+	return traceValue(ctx, r.tracer, "ListByProducts", rowsKeys, func(ctx context.Context) (map[string][]*pb.Variant, error) {
+		return r.next.ListByProducts(ctx, ids)
+	})
+}
+
+// tracingRateRepo traces the operations of a RateRepo.
+type tracingRateRepo struct {
+	next   RateRepo
+	tracer tracer
+}
+
+func (r *tracingRateRepo) Rates(ctx context.Context) (map[string]*big.Rat, error) {
+	return traceValue(ctx, r.tracer, "Rates", rowsKeys, func(ctx context.Context) (map[string]*big.Rat, error) {
+		return r.next.Rates(ctx)
+	})
+}
+
+// tracingOrderLineRepo traces the operations of an OrderLineRepo.
+type tracingOrderLineRepo struct {
+	next   OrderLineRepo
+	tracer tracer
+}
+
+func (r *tracingOrderLineRepo) Place(ctx context.Context, orderID string, line *pb.OrderLine) (bool, error) {
+	return traceValue(ctx, r.tracer, "Place", rowsUnknown, func(ctx context.Context) (bool, error) {
+		return r.next.Place(ctx, orderID, line)
+	})
+}
+
+func (r *tracingOrderLineRepo) Cancel(ctx context.Context, orderID string, line *pb.OrderLine) (*pb.OrderLine, error) {
+	return traceValue(ctx, r.tracer, "Cancel", rowsUnknown, func(ctx context.Context) (*pb.OrderLine, error) {
+		return r.next.Cancel(ctx, orderID, line)
+	})
+}
+
+// tracingTagRepo traces the operations of a TagRepo.
+type tracingTagRepo struct {
+	next   TagRepo
+	tracer tracer
+}
+
+func (r *tracingTagRepo) Resolve(ctx context.Context, keys []string) (map[string]string, error) {
+	return traceValue(ctx, r.tracer, "Resolve", rowsKeys, func(ctx context.Context) (map[string]string, error) {
+		return r.next.Resolve(ctx, keys)
+	})
+}
+
+func (r *tracingTagRepo) Get(ctx context.Context, tag string) (*Tag, error) {
+	return traceValue(ctx, r.tracer, "Get", rowsOne, func(ctx context.Context) (*Tag, error) {
+		return r.next.Get(ctx, tag)
+	})
+}
+
+func (r *tracingTagRepo) List(ctx context.Context) ([]*Tag, error) {
+	return traceValue(ctx, r.tracer, "List", rowsLen, func(ctx context.Context) ([]*Tag, error) {
+		return r.next.List(ctx)
+	})
+}
+
+func (r *tracingTagRepo) Merge(ctx context.Context, name string, from []string) (int64, error) {
+	return traceValue(ctx, r.tracer, "Merge", rowsCount, func(ctx context.Context) (int64, error) {
+		return r.next.Merge(ctx, name, from)
+	})
+}
+
+// tracingAuditRepo traces the operations of an AuditRepo.
+type tracingAuditRepo struct {
+	next   AuditRepo
+	tracer tracer
+}
+
+func (r *tracingAuditRepo) List(ctx context.Context, filter AuditFilter, pageToken string, pageSize int32) ([]*AuditEntry, string, error) {
+	var next string
+	entries, err := traceValue(ctx, r.tracer, "List", rowsLen, func(ctx context.Context) ([]*AuditEntry, error) {
+		var (
+			entries []*AuditEntry
+			err     error
+		)
+		entries, next, err = r.next.List(ctx, filter, pageToken, pageSize)
+		return entries, err
+	})
+	return entries, next, err
+}
+
+// tracingReorderRepo traces the operations of a ReorderRepo.
+type tracingReorderRepo struct {
+	next   ReorderRepo
+	tracer tracer
+}
+
+func (r *tracingReorderRepo) Refresh(ctx context.Context, policy ReorderPolicy) (int64, error) {
+	return traceValue(ctx, r.tracer, "Refresh", rowsCount, func(ctx context.Context) (int64, error) {
+		return r.next.Refresh(ctx, policy)
+	})
+}
+
+func (r *tracingReorderRepo) List(ctx context.Context, pageToken string, pageSize int32) ([]*ReorderSuggestion, string, error) {
+	var next string
+	suggestions, err := traceValue(ctx, r.tracer, "List", rowsLen, func(ctx context.Context) ([]*ReorderSuggestion, error) {
+		var (
+			suggestions []*ReorderSuggestion
+			err         error
+		)
+		suggestions, next, err = r.next.List(ctx, pageToken, pageSize)
+		return suggestions, err
+	})
+	return suggestions, next, err
+}
+
+// tracingLotRepo traces the operations of a LotRepo.
+type tracingLotRepo struct {
+	next   LotRepo
+	tracer tracer
+}
+
+func (r *tracingLotRepo) Receive(ctx context.Context, l *Lot, reference string) (*Lot, int32, error) {
+	var quantity int32
+	lot, err := traceValue(ctx, r.tracer, "Receive", rowsOne, func(ctx context.Context) (*Lot, error) {
+		var (
+			lot *Lot
+			err error
+		)
+		lot, quantity, err = r.next.Receive(ctx, l, reference)
+		return lot, err
+	})
+	return lot, quantity, err
+}
+
+func (r *tracingLotRepo) Expiring(ctx context.Context, before time.Time, productID, pageToken string, pageSize int32) ([]*Lot, string, error) {
+	var next string
+	lots, err := traceValue(ctx, r.tracer, "Expiring", rowsLen, func(ctx context.Context) ([]*Lot, error) {
+		var (
+			lots []*Lot
+			err  error
+		)
+		lots, next, err = r.next.Expiring(ctx, before, productID, pageToken, pageSize)
+		return lots, err
+	})
+	return lots, next, err
+}
+
+func (r *tracingLotRepo) Movements(ctx context.Context, lotID string) ([]*LotMovement, error) {
+	return traceValue(ctx, r.tracer, "Movements", rowsLen, func(ctx context.Context) ([]*LotMovement, error) {
+		return r.next.Movements(ctx, lotID)
+	})
+}
@@ -0,0 +1,60 @@
+package repo
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// TestTracingRepo tests the span names, row counts and error status of
+// traced calls.
+func TestTracingRepo(t *testing.T) {
+	pr, mock := newMockRepo(t)
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	r := &tracingRepo{
+		next:   pr,
+		tracer: tracer{tracer: tp.Tracer(tracerName), repo: "ProductRepo"},
+	}
+
+	mock.ExpectQuery(selectProduct + " WHERE quantity > $1 AND deleted_at IS NULL ORDER BY created_at DESC LIMIT 2 OFFSET 0").
+		WithArgs(0).
+		WillReturnRows(productRows(mock, "1", "2"))
+	mock.ExpectQuery(selectProduct + " WHERE id = $1 AND deleted_at IS NULL").
+		WithArgs("3").
+		WillReturnRows(productRows(mock))
+
+	ctx := context.Background()
+	if _, err := r.List(ctx, 0, 2, ListFilter{}, ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := r.Get(ctx, "3"); err == nil {
+		t.Fatal("Expected an error")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got: %d", len(spans))
+	}
+
+	list := spans[0]
+	if list.Name() != "ProductRepo.List" {
+		t.Errorf("Unexpected span name: %s", list.Name())
+	}
+	attrs := attribute.NewSet(list.Attributes()...)
+	if v, _ := attrs.Value("db.operation.name"); v.AsString() != "List" {
+		t.Errorf("Unexpected db.operation.name: %v", v.AsString())
+	}
+	if v, _ := attrs.Value("db.response.returned_rows"); v.AsInt64() != 2 {
+		t.Errorf("Expected 2 returned rows, got: %v", v.AsInt64())
+	}
+
+	get := spans[1]
+	if get.Name() != "ProductRepo.Get" || get.Status().Code != codes.Error {
+		t.Errorf("Expected a failed ProductRepo.Get span, got: %s %v", get.Name(), get.Status())
+	}
+}
@@ -0,0 +1,105 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/andro-kes/inventory_service/internal/inverr"
+	pb "github.com/andro-kes/inventory_service/proto"
+	"github.com/google/uuid"
+	"golang.org/x/text/language"
+)
+
+// validateTranslations checks that the translations of p have a name and
+// distinct, valid locales, and canonicalizes the locales, e.g. "pt_br" to
+// "pt-BR".
+func validateTranslations(p *pb.Product) error {
+	seen := make(map[string]bool, len(p.GetTranslations()))
+	for i, t := range p.GetTranslations() {
+		tag, err := language.Parse(t.GetLocale())
+		if err != nil {
+			return inverr.InvalidField(fmt.Sprintf("translations[%d].locale", i), "invalid translation locale %q", t.GetLocale())
+		}
+		t.Locale = tag.String()
+		if seen[t.Locale] {
+			return inverr.InvalidField(fmt.Sprintf("translations[%d].locale", i), "duplicate translation locale %s", t.Locale)
+		}
+		seen[t.Locale] = true
+		if t.GetName() == "" {
+			return inverr.InvalidField(fmt.Sprintf("translations[%d].name", i), "translation %s has no name", t.Locale)
+		}
+	}
+	return nil
+}
+
+// saveTranslations replaces the translations of the product id with
+// texts, in q's transaction.
+func saveTranslations(ctx context.Context, q Querier, id string, texts []*pb.LocalizedText) error {
+	sql, args := newQuery(ctx).
+		Delete().
+		From("product_translations").
+		Where("product_id = ?", id).
+		Build()
+	if _, err := q.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+	if len(texts) == 0 {
+		return nil
+	}
+
+	b := newQuery(ctx).
+		Insert("product_translations").
+		Columns("product_id", "locale", "name", "description")
+	for _, t := range texts {
+		b.Values(id, t.GetLocale(), t.GetName(), t.GetDescription())
+	}
+	sql, args = b.Build()
+	_, err := q.Exec(ctx, sql, args...)
+	return err
+}
+
+// Translations returns the translations of the products ids by product
+// id, in locale order, with one query for a whole page of products.
+// Products without translations and malformed ids are left out.
+func (pr *productRepo) Translations(ctx context.Context, ids []string) (map[string][]*pb.LocalizedText, error) {
+	valid := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if uuid.Validate(id) == nil {
+			valid = append(valid, id)
+		}
+	}
+	translations := make(map[string][]*pb.LocalizedText)
+	if len(valid) == 0 {
+		return translations, nil
+	}
+
+	sql, args := newQuery(ctx).
+		Select("product_id", "locale", "name", "description").
+		From("product_translations").
+		Where("product_id = ANY(?)", valid).
+		OrderBy("product_id").
+		OrderBy("locale").
+		Build()
+
+	err := pr.withStatementTimeout(ctx, func(ctx context.Context) error {
+		rows, err := pr.db(ctx).Query(ctx, sql, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var id string
+			var t pb.LocalizedText
+			if err := rows.Scan(&id, &t.Locale, &t.Name, &t.Description); err != nil {
+				return err
+			}
+			translations[id] = append(translations[id], &t)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return translations, nil
+}
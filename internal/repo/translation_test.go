@@ -0,0 +1,103 @@
+package repo
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	pb "github.com/andro-kes/inventory_service/proto"
+	"github.com/pashagolub/pgxmock/v4"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func TestValidateTranslations(t *testing.T) {
+	cases := []struct {
+		texts []*pb.LocalizedText
+		valid bool
+	}{
+		{nil, true},
+		{[]*pb.LocalizedText{{Locale: "de", Name: "Stuhl"}, {Locale: "pt-BR", Name: "Cadeira"}}, true},
+		{[]*pb.LocalizedText{{Locale: "", Name: "Stuhl"}}, false},
+		{[]*pb.LocalizedText{{Locale: "not a locale", Name: "Stuhl"}}, false},
+		{[]*pb.LocalizedText{{Locale: "de"}}, false},
+		{[]*pb.LocalizedText{{Locale: "pt-br", Name: "Cadeira"}, {Locale: "pt-BR", Name: "Cadeira"}}, false},
+	}
+
+	for _, tc := range cases {
+		err := validateTranslations(&pb.Product{Translations: tc.texts})
+		if tc.valid && err != nil {
+			t.Errorf("Expected %v to be valid, got: %v", tc.texts, err)
+		}
+		if !tc.valid && status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument for %v, got: %v", tc.texts, err)
+		}
+	}
+
+	p := &pb.Product{Translations: []*pb.LocalizedText{{Locale: "pt_br", Name: "Cadeira"}}}
+	if err := validateTranslations(p); err != nil || p.Translations[0].Locale != "pt-BR" {
+		t.Errorf("Expected the locale canonicalized to pt-BR, got: %v %v", p.Translations[0].Locale, err)
+	}
+}
+
+// TestRepoTranslations tests that the translations of several products
+// are loaded with one query, skipping malformed ids.
+func TestRepoTranslations(t *testing.T) {
+	pr, mock := newMockRepo(t)
+
+	id1 := "6f1c1c3e-2a4b-4c55-9d4e-0b8e0f1a2b3c"
+	id2 := "7a2d2d4f-3b5c-4d66-8e5f-1c9f1a2b3c4d"
+	mock.ExpectQuery("SELECT product_id, locale, name, description FROM product_translations " +
+		"WHERE product_id = ANY($1) ORDER BY product_id, locale").
+		WithArgs([]string{id1, id2}).
+		WillReturnRows(mock.NewRows([]string{"product_id", "locale", "name", "description"}).
+			AddRow(id1, "de", "Stuhl", "Holzstuhl").
+			AddRow(id1, "fr", "Chaise", ""))
+
+	translations, err := pr.Translations(context.Background(), []string{id1, "bad", id2})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(translations) != 1 || len(translations[id1]) != 2 {
+		t.Fatalf("Expected two translations of %s, got: %v", id1, translations)
+	}
+	expected := &pb.LocalizedText{Locale: "fr", Name: "Chaise"}
+	if !proto.Equal(translations[id1][1], expected) {
+		t.Errorf("Expected %v, got: %v", expected, translations[id1][1])
+	}
+}
+
+// TestRepoUpdateTranslations tests that the "translations" path replaces
+// the translations of the product in the update's transaction.
+func TestRepoUpdateTranslations(t *testing.T) {
+	pr, mock := newMockRepo(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE products SET updated_at = $1, updated_by = NULL WHERE id = $2 AND deleted_at IS NULL RETURNING "+strings.Join(productColumns, ", ")).
+		WithArgs(pgxmock.AnyArg(), "1").
+		WillReturnRows(productRows(mock, "1"))
+	mock.ExpectExec("DELETE FROM product_translations WHERE product_id = $1").
+		WithArgs("1").
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectExec("INSERT INTO product_translations (product_id, locale, name, description) VALUES ($1, $2, $3, $4), ($5, $6, $7, $8)").
+		WithArgs("1", "de", "Stuhl", "", "1", "pt-BR", "Cadeira", "").
+		WillReturnResult(pgxmock.NewResult("INSERT", 2))
+	mock.ExpectExec(notifyChangeSQL).
+		WithArgs(ChangesChannel, `{"id":"1","op":"update"}`, "", "").
+		WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectCommit()
+
+	p := &pb.Product{Id: "1", Translations: []*pb.LocalizedText{
+		{Locale: "de", Name: "Stuhl"},
+		{Locale: "pt_br", Name: "Cadeira"},
+	}}
+	updated, err := pr.Update(context.Background(), p, &fieldmaskpb.FieldMask{Paths: []string{"translations"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(updated.GetTranslations()) != 2 {
+		t.Errorf("Expected the new translations, got: %v", updated.GetTranslations())
+	}
+}
@@ -0,0 +1,92 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Querier is the part of pgxpool.Pool, pgx.Conn and pgx.Tx the repos use.
+// Begin on a pgx.Tx starts a savepoint, so repo methods that need their own
+// transaction nest inside an existing one.
+type Querier interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	CopyFrom(ctx context.Context, table pgx.Identifier, columns []string, src pgx.CopyFromSource) (int64, error)
+}
+
+type txKey struct{}
+
+// TxManager runs functions in a transaction that repo methods called with
+// the function's context take part in.
+type TxManager struct {
+	DB Querier
+	// Retry reruns the whole transaction on transient errors.
+	Retry RetryPolicy
+}
+
+func NewTxManager(db Querier) *TxManager {
+	return &TxManager{
+		DB:    db,
+		Retry: DefaultRetryPolicy,
+	}
+}
+
+// WithinTx begins a transaction, calls fn with a context carrying it and
+// commits if fn returns nil; otherwise, or if fn panics, it rolls back.
+// Called inside another WithinTx it uses a savepoint of the outer
+// transaction. An outermost transaction failing with a transient error is
+// retried with tm.Retry, so fn may run more than once.
+//
+// Example:
+//
+//	err := tm.WithinTx(ctx, func(ctx context.Context) error {
+//		p, err := products.Create(ctx, p)
+//		if err != nil {
+//			return err
+//		}
+//		_, err = products.ReserveStock(ctx, r)
+//		return err
+//	})
+func (tm *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return tm.Retry.Do(ctx, func() error {
+		return tm.withinTx(ctx, fn)
+	})
+}
+
+func (tm *TxManager) withinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := conn(ctx, tm.DB).Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+type rowLockKey struct{}
+
+// WithRowLock returns a copy of ctx in which Get locks the product it reads
+// until the end of the transaction, e.g. to check a change against the
+// current product before making it. Use it inside WithinTx.
+func WithRowLock(ctx context.Context) context.Context {
+	return context.WithValue(ctx, rowLockKey{}, true)
+}
+
+// conn returns the transaction carried by ctx, or db outside WithinTx.
+func conn(ctx context.Context, db Querier) Querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return db
+}
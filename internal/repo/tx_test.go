@@ -0,0 +1,70 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// fakeTx records how a transaction was finished. Begin starts a nested one.
+type fakeTx struct {
+	pgx.Tx
+	nested     *fakeTx
+	committed  bool
+	rolledBack bool
+}
+
+func (tx *fakeTx) Begin(context.Context) (pgx.Tx, error) {
+	tx.nested = &fakeTx{}
+	return tx.nested, nil
+}
+
+func (tx *fakeTx) Commit(context.Context) error {
+	tx.committed = true
+	return nil
+}
+
+func (tx *fakeTx) Rollback(context.Context) error {
+	if !tx.committed {
+		tx.rolledBack = true
+	}
+	return nil
+}
+
+// TestWithinTx tests that WithinTx exposes its transaction through the
+// context and commits or rolls back depending on the result.
+func TestWithinTx(t *testing.T) {
+	tm := NewTxManager(nil)
+
+	outer := &fakeTx{}
+	ctx := context.WithValue(context.Background(), txKey{}, pgx.Tx(outer))
+
+	err := tm.WithinTx(ctx, func(ctx context.Context) error {
+		if conn(ctx, nil) != outer.nested {
+			t.Error("Expected the context to carry the nested transaction")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !outer.nested.committed || outer.nested.rolledBack {
+		t.Errorf("Expected commit, got committed=%v rolledBack=%v", outer.nested.committed, outer.nested.rolledBack)
+	}
+
+	errFailed := errors.New("failed")
+	err = tm.WithinTx(ctx, func(context.Context) error {
+		return errFailed
+	})
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("Expected the function error, got: %v", err)
+	}
+	if outer.nested.committed || !outer.nested.rolledBack {
+		t.Errorf("Expected rollback, got committed=%v rolledBack=%v", outer.nested.committed, outer.nested.rolledBack)
+	}
+	if outer.committed || outer.rolledBack {
+		t.Error("Expected the outer transaction to be left alone")
+	}
+}
@@ -0,0 +1,410 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/andro-kes/inventory_service/internal/inverr"
+	"github.com/andro-kes/inventory_service/internal/repo/builder"
+	pb "github.com/andro-kes/inventory_service/proto"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// variantRow mirrors a row of the product_variants table.
+type variantRow struct {
+	ID              string            `db:"id"`
+	ProductID       string            `db:"product_id"`
+	SKU             string            `db:"sku"`
+	Options         map[string]string `db:"options"`
+	PriceDeltaCents int64             `db:"price_delta_cents"`
+	Quantity        int32             `db:"quantity"`
+	CreatedAt       time.Time         `db:"created_at"`
+	UpdatedAt       time.Time         `db:"updated_at"`
+}
+
+// variantColumns lists the product_variants columns in variantRow order.
+var variantColumns = builder.StructColumns(variantRow{})
+
+// variantFields maps the Variant fields of update masks to their columns.
+var variantFields = map[string]string{
+	"sku":         "sku",
+	"options":     "options",
+	"price_delta": "price_delta_cents",
+	"quantity":    "quantity",
+}
+
+func newVariantRow(v *pb.Variant) variantRow {
+	return variantRow{
+		ID:              v.GetId(),
+		ProductID:       v.GetProductId(),
+		SKU:             v.GetSku(),
+		Options:         v.GetOptions(),
+		PriceDeltaCents: centsOf(v.GetPriceDelta()),
+		Quantity:        v.GetQuantity(),
+	}
+}
+
+func (r *variantRow) fields() []any {
+	return []any{
+		&r.ID, &r.ProductID, &r.SKU, &r.Options, &r.PriceDeltaCents, &r.Quantity,
+		&r.CreatedAt, &r.UpdatedAt,
+	}
+}
+
+// toProto returns the variant of a product priced price cents in currency.
+func (r *variantRow) toProto(price int64, currency string) *pb.Variant {
+	return &pb.Variant{
+		Id:         r.ID,
+		ProductId:  r.ProductID,
+		Sku:        r.SKU,
+		Options:    r.Options,
+		PriceDelta: money(r.PriceDeltaCents, currency),
+		Quantity:   r.Quantity,
+		Price:      money(price+r.PriceDeltaCents, currency),
+		CreatedAt:  timestamppb.New(r.CreatedAt),
+		UpdatedAt:  timestamppb.New(r.UpdatedAt),
+	}
+}
+
+// VariantRepo stores the variants of products, e.g. the sizes and colors
+// of a shirt. Changes to variants are published as updates of their
+// product on ChangesChannel.
+type VariantRepo interface {
+	Create(ctx context.Context, v *pb.Variant) (*pb.Variant, error)
+	Get(ctx context.Context, id string) (*pb.Variant, error)
+	// Update sets the fields of v in mask: sku, options, price_delta or
+	// quantity.
+	Update(ctx context.Context, v *pb.Variant, mask *fieldmaskpb.FieldMask) (*pb.Variant, error)
+	Delete(ctx context.Context, id string) error
+	// ListByProducts returns the variants of the products ids by product
+	// id, ordered by SKU, with one query for a whole page of products.
+	ListByProducts(ctx context.Context, ids []string) (map[string][]*pb.Variant, error)
+}
+
+type variantRepo struct {
+	DB Querier
+}
+
+// NewVariantRepo returns a VariantRepo running its queries on db.
+func NewVariantRepo(ctx context.Context, db Querier) VariantRepo {
+	return &tracingVariantRepo{
+		next: &variantRepo{
+			DB: db,
+		},
+		tracer: newTracer("VariantRepo"),
+	}
+}
+
+func (vr *variantRepo) db(ctx context.Context) Querier {
+	return conn(ctx, vr.DB)
+}
+
+// variantsQuery selects the variants, with the price and currency of
+// their product, of products that are not deleted.
+func variantsQuery(ctx context.Context) *builder.SQLBuilder {
+	columns := make([]string, 0, len(variantColumns)+2)
+	for _, column := range variantColumns {
+		columns = append(columns, "v."+column)
+	}
+	return newQuery(ctx).
+		Select(append(columns, "p.price_cents", "p.currency_code")...).
+		From("product_variants v").
+		Join("products p", "p.id = v.product_id").
+		WhereNull("p.deleted_at")
+}
+
+// scanVariant reads a row of variantsQuery.
+func scanVariant(row pgx.Row) (*pb.Variant, error) {
+	var r variantRow
+	var price int64
+	var currency string
+	if err := row.Scan(append(r.fields(), &price, &currency)...); err != nil {
+		return nil, err
+	}
+	return r.toProto(price, currency), nil
+}
+
+// productPrice returns the price and currency of the product id, locking
+// it against deletion until the end of the transaction of q.
+func productPrice(ctx context.Context, q Querier, id string) (int64, string, error) {
+	sql, args := productsQuery(ctx).
+		Select("price_cents", "currency_code").
+		From("products").
+		Where("id = ?", id).
+		ForShare().
+		Build()
+
+	var price int64
+	var currency string
+	err := q.QueryRow(ctx, sql, args...).Scan(&price, &currency)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, "", status.Errorf(codes.NotFound, "product not found: %s", id)
+	}
+	return price, currency, err
+}
+
+func (vr *variantRepo) Create(ctx context.Context, v *pb.Variant) (*pb.Variant, error) {
+	if err := uuid.Validate(v.GetProductId()); err != nil {
+		return nil, inverr.InvalidField("product_id", "invalid product id %q", v.GetProductId())
+	}
+	if err := validateVariant(v, nil); err != nil {
+		return nil, err
+	}
+
+	row := newVariantRow(v)
+	row.CreatedAt = time.Now()
+	row.UpdatedAt = row.CreatedAt
+
+	tx, err := vr.db(ctx).Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	price, currency, err := productPrice(ctx, tx, v.GetProductId())
+	if err != nil {
+		return nil, err
+	}
+	if err := checkDeltaCurrency(v, currency); err != nil {
+		return nil, err
+	}
+
+	sql, args := newQuery(ctx).
+		Insert("product_variants").
+		InsertStruct(row).
+		Returning(variantColumns...).
+		Build()
+
+	var created variantRow
+	if err := tx.QueryRow(ctx, sql, args...).Scan(created.fields()...); err != nil {
+		return nil, variantError(err, v)
+	}
+	if err := notifyChange(ctx, tx, created.ProductID, OpUpdate); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return created.toProto(price, currency), nil
+}
+
+func (vr *variantRepo) Get(ctx context.Context, id string) (*pb.Variant, error) {
+	if uuid.Validate(id) != nil {
+		return nil, status.Errorf(codes.NotFound, "variant not found: %s", id)
+	}
+
+	sql, args := variantsQuery(ctx).
+		Where("v.id = ?", id).
+		Build()
+
+	v, err := scanVariant(vr.db(ctx).QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, status.Errorf(codes.NotFound, "variant not found: %s", id)
+	}
+	return v, err
+}
+
+func (vr *variantRepo) Update(ctx context.Context, v *pb.Variant, mask *fieldmaskpb.FieldMask) (*pb.Variant, error) {
+	if len(mask.GetPaths()) == 0 {
+		return nil, inverr.InvalidField("update_mask", "update_mask is required")
+	}
+	columns := make([]string, 0, len(mask.GetPaths()))
+	for _, path := range mask.GetPaths() {
+		column, ok := variantFields[path]
+		if !ok {
+			return nil, inverr.InvalidField("update_mask", "field %s cannot be updated", path)
+		}
+		columns = append(columns, column)
+	}
+	if err := validateVariant(v, mask.GetPaths()); err != nil {
+		return nil, err
+	}
+	if uuid.Validate(v.GetId()) != nil {
+		return nil, status.Errorf(codes.NotFound, "variant not found: %s", v.GetId())
+	}
+
+	sql, args := newQuery(ctx).
+		Update("product_variants").
+		UpdateStruct(newVariantRow(v), columns...).
+		Set("updated_at = ?", time.Now()).
+		Where("id = ?", v.GetId()).
+		Returning(variantColumns...).
+		Build()
+
+	tx, err := vr.db(ctx).Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var row variantRow
+	if err := tx.QueryRow(ctx, sql, args...).Scan(row.fields()...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "variant not found: %s", v.GetId())
+		}
+		return nil, variantError(err, v)
+	}
+	price, currency, err := productPrice(ctx, tx, row.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(mask.GetPaths(), "price_delta") {
+		if err := checkDeltaCurrency(v, currency); err != nil {
+			return nil, err
+		}
+	}
+	if err := notifyChange(ctx, tx, row.ProductID, OpUpdate); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return row.toProto(price, currency), nil
+}
+
+func (vr *variantRepo) Delete(ctx context.Context, id string) error {
+	if uuid.Validate(id) != nil {
+		return status.Errorf(codes.NotFound, "variant not found: %s", id)
+	}
+
+	sql, args := newQuery(ctx).
+		Delete().
+		From("product_variants").
+		Where("id = ?", id).
+		Returning("product_id").
+		Build()
+
+	tx, err := vr.db(ctx).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var productID string
+	if err := tx.QueryRow(ctx, sql, args...).Scan(&productID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return status.Errorf(codes.NotFound, "variant not found: %s", id)
+		}
+		return err
+	}
+	if err := notifyChange(ctx, tx, productID, OpUpdate); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (vr *variantRepo) ListByProducts(ctx context.Context, ids []string) (map[string][]*pb.Variant, error) {
+	valid := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if uuid.Validate(id) == nil {
+			valid = append(valid, id)
+		}
+	}
+	variants := make(map[string][]*pb.Variant)
+	if len(valid) == 0 {
+		return variants, nil
+	}
+
+	sql, args := variantsQuery(ctx).
+		Where("v.product_id = ANY(?)", valid).
+		OrderBy("v.product_id").
+		OrderBy("v.sku").
+		Build()
+
+	rows, err := vr.db(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		v, err := scanVariant(rows)
+		if err != nil {
+			return nil, err
+		}
+		variants[v.ProductId] = append(variants[v.ProductId], v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return variants, nil
+}
+
+// validateVariant checks the fields of v in paths, or all of them if
+// paths is nil.
+func validateVariant(v *pb.Variant, paths []string) error {
+	checks := func(field string) bool {
+		return paths == nil || slices.Contains(paths, field)
+	}
+
+	if checks("sku") && v.GetSku() == "" {
+		return inverr.InvalidField("sku", "variant sku is required")
+	}
+	if checks("options") {
+		if len(v.GetOptions()) == 0 {
+			return inverr.InvalidField("options", "variant options are required")
+		}
+		if len(v.GetOptions()) > maxAttributes {
+			return inverr.InvalidField("options", "at most %d options are allowed", maxAttributes)
+		}
+		for key := range v.GetOptions() {
+			if !attributeKey.MatchString(key) {
+				return inverr.InvalidField("options", "invalid option key %q", key)
+			}
+		}
+	}
+	if checks("price_delta") {
+		if err := validateMoney("price_delta", v.GetPriceDelta()); err != nil {
+			return err
+		}
+	}
+	if checks("quantity") && v.GetQuantity() < 0 {
+		return inverr.InvalidField("quantity", "quantity must not be negative: %d", v.GetQuantity())
+	}
+	return nil
+}
+
+// checkDeltaCurrency checks that the price_delta of v, if it names a
+// currency, is in currency, the currency of its product.
+func checkDeltaCurrency(v *pb.Variant, currency string) error {
+	if c := v.GetPriceDelta().GetCurrencyCode(); c != "" && c != currency {
+		return inverr.InvalidField("price_delta.currency_code", "price_delta currency %s differs from the product currency %s", c, currency)
+	}
+	return nil
+}
+
+// variantError maps database errors of writing v to status errors.
+func variantError(err error, v *pb.Variant) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != "23505" {
+		return err
+	}
+	switch pgErr.ConstraintName {
+	case "product_variants_sku_key":
+		return inverr.WithReason(codes.AlreadyExists, inverr.ReasonDuplicateSKU,
+			map[string]string{"sku": v.GetSku()},
+			"variant with sku %s already exists", v.GetSku())
+	case "product_variants_product_id_options_key":
+		return inverr.WithReason(codes.AlreadyExists, inverr.ReasonDuplicateVariant,
+			map[string]string{"product_id": v.GetProductId()},
+			"product already has a variant with options %v", v.GetOptions())
+	}
+	return err
+}
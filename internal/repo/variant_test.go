@@ -0,0 +1,172 @@
+package repo
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/andro-kes/inventory_service/proto"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v4"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+const (
+	testProductID = "6f1c1c3e-2a4b-4c55-9d4e-0b8e0f1a2b3c"
+	testVariantID = "7a2d2d4f-3b5c-4d66-8e5f-1c9f1a2b3c4d"
+)
+
+func newMockVariantRepo(t *testing.T) (*variantRepo, pgxmock.PgxPoolIface) {
+	t.Helper()
+
+	_, mock := newMockRepo(t)
+	return &variantRepo{DB: mock}, mock
+}
+
+func TestValidateVariant(t *testing.T) {
+	valid := func() *pb.Variant {
+		return &pb.Variant{Sku: "TS-M-RED", Options: map[string]string{"size": "M", "color": "red"}, Quantity: 3}
+	}
+	cases := []struct {
+		name   string
+		modify func(v *pb.Variant)
+		paths  []string
+		valid  bool
+	}{
+		{"valid", func(v *pb.Variant) {}, nil, true},
+		{"no sku", func(v *pb.Variant) { v.Sku = "" }, nil, false},
+		{"no options", func(v *pb.Variant) { v.Options = nil }, nil, false},
+		{"bad option key", func(v *pb.Variant) { v.Options["a.b"] = "x" }, nil, false},
+		{"negative quantity", func(v *pb.Variant) { v.Quantity = -1 }, nil, false},
+		{"fractional cents", func(v *pb.Variant) { v.PriceDelta = &pb.Money{Nanos: 1} }, nil, false},
+		{"negative delta", func(v *pb.Variant) { v.PriceDelta = &pb.Money{Units: -5, Nanos: -500_000_000} }, nil, true},
+		{"unmasked sku", func(v *pb.Variant) { v.Sku = "" }, []string{"quantity"}, true},
+	}
+
+	for _, tc := range cases {
+		v := valid()
+		tc.modify(v)
+		err := validateVariant(v, tc.paths)
+		if tc.valid && err != nil {
+			t.Errorf("%s: expected valid, got: %v", tc.name, err)
+		}
+		if !tc.valid && status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%s: expected InvalidArgument, got: %v", tc.name, err)
+		}
+	}
+}
+
+// TestVariantCreate tests that a variant is priced from its product and
+// published as an update of the product.
+func TestVariantCreate(t *testing.T) {
+	vr, mock := newMockVariantRepo(t)
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	options := map[string]string{"size": "M"}
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT price_cents, currency_code FROM products WHERE id = $1 AND deleted_at IS NULL FOR SHARE").
+		WithArgs(testProductID).
+		WillReturnRows(mock.NewRows([]string{"price_cents", "currency_code"}).AddRow(int64(1000), "USD"))
+	mock.ExpectQuery("INSERT INTO product_variants ("+strings.Join(variantColumns, ", ")+") "+
+		"VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING "+strings.Join(variantColumns, ", ")).
+		WithArgs(testVariantID, testProductID, "TS-M", options, int64(250), int32(3), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(mock.NewRows(variantColumns).AddRow(testVariantID, testProductID, "TS-M", options, int64(250), int32(3), now, now))
+	mock.ExpectExec(notifyChangeSQL).
+		WithArgs(ChangesChannel, `{"id":"`+testProductID+`","op":"update"}`, "", "").
+		WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectCommit()
+
+	v, err := vr.Create(context.Background(), &pb.Variant{
+		Id:         testVariantID,
+		ProductId:  testProductID,
+		Sku:        "TS-M",
+		Options:    options,
+		PriceDelta: &pb.Money{Units: 2, Nanos: 500_000_000},
+		Quantity:   3,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := &pb.Money{CurrencyCode: "USD", Units: 12, Nanos: 500_000_000}
+	if !proto.Equal(v.GetPrice(), expected) {
+		t.Errorf("Expected price %v, got: %v", expected, v.GetPrice())
+	}
+}
+
+// TestVariantCreateCurrency tests that a price delta in another currency
+// than the product's is rejected.
+func TestVariantCreateCurrency(t *testing.T) {
+	vr, mock := newMockVariantRepo(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT price_cents, currency_code FROM products WHERE id = $1 AND deleted_at IS NULL FOR SHARE").
+		WithArgs(testProductID).
+		WillReturnRows(mock.NewRows([]string{"price_cents", "currency_code"}).AddRow(int64(1000), "USD"))
+	mock.ExpectRollback()
+
+	_, err := vr.Create(context.Background(), &pb.Variant{
+		ProductId:  testProductID,
+		Sku:        "TS-M",
+		Options:    map[string]string{"size": "M"},
+		PriceDelta: &pb.Money{CurrencyCode: "EUR", Units: 1},
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument, got: %v", err)
+	}
+}
+
+// TestVariantUpdateDuplicate tests that taking the options of another
+// variant of the product fails with AlreadyExists.
+func TestVariantUpdateDuplicate(t *testing.T) {
+	vr, mock := newMockVariantRepo(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE product_variants SET options = $1, updated_at = $2 WHERE id = $3 RETURNING "+strings.Join(variantColumns, ", ")).
+		WithArgs(map[string]string{"size": "L"}, pgxmock.AnyArg(), testVariantID).
+		WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "product_variants_product_id_options_key"})
+	mock.ExpectRollback()
+
+	v := &pb.Variant{Id: testVariantID, Options: map[string]string{"size": "L"}}
+	_, err := vr.Update(context.Background(), v, &fieldmaskpb.FieldMask{Paths: []string{"options"}})
+	if status.Code(err) != codes.AlreadyExists {
+		t.Errorf("Expected AlreadyExists, got: %v", err)
+	}
+
+	_, err = vr.Update(context.Background(), v, &fieldmaskpb.FieldMask{Paths: []string{"product_id"}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for product_id, got: %v", err)
+	}
+}
+
+// TestVariantListByProducts tests that the variants of a page of products
+// are loaded with one query.
+func TestVariantListByProducts(t *testing.T) {
+	vr, mock := newMockVariantRepo(t)
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	columns := make([]string, 0, len(variantColumns)+2)
+	for _, column := range variantColumns {
+		columns = append(columns, "v."+column)
+	}
+	mock.ExpectQuery("SELECT " + strings.Join(columns, ", ") + ", p.price_cents, p.currency_code " +
+		"FROM product_variants v JOIN products p ON p.id = v.product_id " +
+		"WHERE p.deleted_at IS NULL AND v.product_id = ANY($1) ORDER BY v.product_id, v.sku").
+		WithArgs([]string{testProductID}).
+		WillReturnRows(mock.NewRows(append(variantColumns, "price_cents", "currency_code")).
+			AddRow(testVariantID, testProductID, "TS-L", map[string]string{"size": "L"}, int64(-100), int32(1), now, now, int64(1000), "RUB"))
+
+	variants, err := vr.ListByProducts(context.Background(), []string{testProductID, "bad"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(variants[testProductID]) != 1 {
+		t.Fatalf("Expected one variant, got: %v", variants)
+	}
+	if price := variants[testProductID][0].GetPrice(); price.GetUnits() != 9 {
+		t.Errorf("Expected price 9, got: %v", price)
+	}
+}
@@ -0,0 +1,133 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/gzip"
+)
+
+// Zstd is the name of the zstd compressor registered by
+// RegisterCompressors; gzip.Name is that of gzip.
+const Zstd = "zstd"
+
+// RegisterCompressors registers the gzip and zstd compressors, compressing
+// at level from 1 (fastest) to 9 (smallest); zero keeps their defaults.
+// Requests compressed with either are accepted and answered the same way.
+// It must be called before serving, like encoding.RegisterCompressor.
+func RegisterCompressors(level int) error {
+	if level < 0 || level > 9 {
+		return fmt.Errorf("compression level must be from 1 to 9: %d", level)
+	}
+	zstdLevel := zstd.SpeedDefault
+	if level > 0 {
+		if err := gzip.SetLevel(level); err != nil {
+			return err
+		}
+		zstdLevel = zstd.EncoderLevelFromZstd(level)
+	}
+	encoding.RegisterCompressor(&zstdCompressor{level: zstdLevel})
+	return nil
+}
+
+// UnaryCompressionInterceptor compresses the responses with the registered
+// compressor name, e.g. gzip, for clients accepting it, even if their
+// requests are not compressed.
+func UnaryCompressionInterceptor(name string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		useCompressor(ctx, name)
+		return handler(ctx, req)
+	}
+}
+
+// StreamCompressionInterceptor is UnaryCompressionInterceptor for
+// streaming RPCs.
+func StreamCompressionInterceptor(name string) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		useCompressor(ss.Context(), name)
+		return handler(srv, ss)
+	}
+}
+
+// useCompressor sends the response of the RPC of ctx compressed with name
+// if the client accepts it.
+func useCompressor(ctx context.Context, name string) {
+	accepted, err := grpc.ClientSupportedCompressors(ctx)
+	if err == nil && slices.Contains(accepted, name) {
+		_ = grpc.SetSendCompressor(ctx, name)
+	}
+}
+
+// zstdCompressor implements encoding.Compressor with zstd, reusing
+// encoders and decoders across messages.
+type zstdCompressor struct {
+	level    zstd.EncoderLevel
+	encoders sync.Pool
+	decoders sync.Pool
+}
+
+func (c *zstdCompressor) Name() string {
+	return Zstd
+}
+
+func (c *zstdCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	if enc, ok := c.encoders.Get().(*zstd.Encoder); ok {
+		enc.Reset(w)
+		return &zstdWriter{Encoder: enc, pool: &c.encoders}, nil
+	}
+	enc, err := zstd.NewWriter(w, zstd.WithEncoderLevel(c.level), zstd.WithEncoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	return &zstdWriter{Encoder: enc, pool: &c.encoders}, nil
+}
+
+func (c *zstdCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	if dec, ok := c.decoders.Get().(*zstd.Decoder); ok {
+		if err := dec.Reset(r); err != nil {
+			return nil, err
+		}
+		return &zstdReader{dec: dec, pool: &c.decoders}, nil
+	}
+	dec, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	return &zstdReader{dec: dec, pool: &c.decoders}, nil
+}
+
+// zstdWriter returns its encoder to the pool once closed.
+type zstdWriter struct {
+	*zstd.Encoder
+	pool *sync.Pool
+}
+
+func (w *zstdWriter) Close() error {
+	err := w.Encoder.Close()
+	w.pool.Put(w.Encoder)
+	return err
+}
+
+// zstdReader returns its decoder to the pool once the message is read.
+type zstdReader struct {
+	dec  *zstd.Decoder
+	pool *sync.Pool
+}
+
+func (r *zstdReader) Read(p []byte) (int, error) {
+	if r.dec == nil {
+		return 0, io.EOF
+	}
+	n, err := r.dec.Read(p)
+	if err == io.EOF {
+		r.pool.Put(r.dec)
+		r.dec = nil
+	}
+	return n, err
+}
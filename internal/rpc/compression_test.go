@@ -0,0 +1,59 @@
+package rpc
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/encoding"
+)
+
+// TestZstdCompressor tests that messages survive a round trip through the
+// zstd compressor, including with pooled encoders and decoders.
+func TestZstdCompressor(t *testing.T) {
+	if err := RegisterCompressors(3); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	c := encoding.GetCompressor(Zstd)
+	if c == nil {
+		t.Fatal("Expected zstd to be registered")
+	}
+
+	for _, msg := range []string{strings.Repeat("long description ", 1000), "short", ""} {
+		var buf bytes.Buffer
+		w, err := c.Compress(&buf)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := w.Write([]byte(msg)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(msg) > 1000 && buf.Len() >= len(msg)/10 {
+			t.Errorf("Expected %d bytes to compress well, got: %d", len(msg), buf.Len())
+		}
+
+		r, err := c.Decompress(&buf)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		got, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if string(got) != msg {
+			t.Errorf("Expected %d bytes back, got: %d", len(msg), len(got))
+		}
+	}
+}
+
+func TestRegisterCompressorsLevel(t *testing.T) {
+	for _, level := range []int{-1, 10} {
+		if err := RegisterCompressors(level); err == nil {
+			t.Errorf("Expected an error for level %d", level)
+		}
+	}
+}
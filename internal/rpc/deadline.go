@@ -0,0 +1,70 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// Timeouts are the default deadlines of unary RPCs by method name, e.g.
+// "GetProduct", for requests arriving without a deadline. The "*" entry,
+// if any, applies to the other methods. Streaming RPCs such as
+// WatchProducts run for as long as the client wants.
+type Timeouts map[string]time.Duration
+
+// DefaultTimeouts bound the RPCs when no timeouts are configured.
+var DefaultTimeouts = Timeouts{"*": 30 * time.Second}
+
+// ParseTimeouts parses comma-separated method=duration pairs, e.g.
+// "GetProduct=2s,BatchUpdateProducts=10s,*=5s".
+func ParseTimeouts(s string) (Timeouts, error) {
+	timeouts := make(Timeouts)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		method, value, ok := strings.Cut(pair, "=")
+		if !ok || method == "" {
+			return nil, fmt.Errorf("invalid timeout %q: want method=duration", pair)
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout of %s: %w", method, err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("timeout of %s must be positive: %s", method, d)
+		}
+		timeouts[strings.TrimSpace(method)] = d
+	}
+	return timeouts, nil
+}
+
+// timeout returns the default deadline of the RPC fullMethod, e.g.
+// "/inventory.InventoryService/GetProduct", or zero for none.
+func (t Timeouts) timeout(fullMethod string) time.Duration {
+	if d, ok := t[path.Base(fullMethod)]; ok {
+		return d
+	}
+	return t["*"]
+}
+
+// UnaryInterceptor runs requests without a deadline with the default
+// deadline of their method, so that the handlers and the repo queries
+// they run give up instead of holding connections forever.
+func (t Timeouts) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			if d := t.timeout(info.FullMethod); d > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, d)
+				defer cancel()
+			}
+		}
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,66 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+func TestParseTimeouts(t *testing.T) {
+	timeouts, err := ParseTimeouts("GetProduct=2s, BatchUpdateProducts=10s,*=5s")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	cases := map[string]time.Duration{
+		"/inventory.InventoryService/GetProduct":          2 * time.Second,
+		"/inventory.InventoryService/BatchUpdateProducts": 10 * time.Second,
+		"/inventory.InventoryService/ListProducts":        5 * time.Second,
+	}
+	for method, want := range cases {
+		if got := timeouts.timeout(method); got != want {
+			t.Errorf("Expected %s for %s, got: %s", want, method, got)
+		}
+	}
+
+	for _, invalid := range []string{"GetProduct", "=2s", "GetProduct=soon", "GetProduct=0s"} {
+		if _, err := ParseTimeouts(invalid); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
+
+// TestTimeoutsUnaryInterceptor tests that requests without a deadline get
+// the default of their method and client deadlines are kept.
+func TestTimeoutsUnaryInterceptor(t *testing.T) {
+	interceptor := Timeouts{"GetProduct": time.Minute}.UnaryInterceptor()
+	deadline := func(ctx context.Context, method string) (time.Time, bool) {
+		var got time.Time
+		var ok bool
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req any) (any, error) {
+			got, ok = ctx.Deadline()
+			return nil, nil
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return got, ok
+	}
+
+	got, ok := deadline(context.Background(), "/inventory.InventoryService/GetProduct")
+	if !ok || time.Until(got) > time.Minute || time.Until(got) < 50*time.Second {
+		t.Errorf("Expected a deadline in a minute, got: %v %t", got, ok)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	want, _ := ctx.Deadline()
+	if got, _ := deadline(ctx, "/inventory.InventoryService/GetProduct"); !got.Equal(want) {
+		t.Errorf("Expected the client deadline %v, got: %v", want, got)
+	}
+
+	if _, ok := deadline(context.Background(), "/inventory.InventoryService/ListProducts"); ok {
+		t.Error("Expected no deadline for a method without a timeout")
+	}
+}
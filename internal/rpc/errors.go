@@ -0,0 +1,30 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/andro-kes/inventory_service/internal/repo"
+	"google.golang.org/grpc"
+)
+
+// UnaryErrorInterceptor converts transient database errors of the
+// handlers to status errors clients can retry on; see repo.TransientError.
+func UnaryErrorInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return nil, repo.TransientError(err)
+		}
+		return resp, nil
+	}
+}
+
+// StreamErrorInterceptor is UnaryErrorInterceptor for streaming RPCs.
+func StreamErrorInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, ss); err != nil {
+			return repo.TransientError(err)
+		}
+		return nil
+	}
+}
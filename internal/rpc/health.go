@@ -0,0 +1,71 @@
+package rpc
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/andro-kes/inventory_service/proto"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Pinger is the part of pgxpool.Pool the health check uses.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Health implements grpc.health.v1.Health for the overall server ("")
+// and InventoryService. Both are NOT_SERVING until a database ping
+// succeeds, follow the ping results of Run and stay NOT_SERVING once
+// Shutdown is called.
+type Health struct {
+	*health.Server
+	db Pinger
+}
+
+// healthServices are the services whose status Health reports.
+var healthServices = []string{"", pb.InventoryService_ServiceDesc.ServiceName}
+
+// NewHealth returns a Health checking db, NOT_SERVING until the first
+// successful ping.
+func NewHealth(db Pinger) *Health {
+	h := &Health{
+		Server: health.NewServer(),
+		db:     db,
+	}
+	h.set(healthpb.HealthCheckResponse_NOT_SERVING)
+	return h
+}
+
+func (h *Health) set(status healthpb.HealthCheckResponse_ServingStatus) {
+	for _, service := range healthServices {
+		h.SetServingStatus(service, status)
+	}
+}
+
+// Probe pings the database once and updates the status.
+func (h *Health) Probe(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := h.db.Ping(ctx); err != nil {
+		h.set(healthpb.HealthCheckResponse_NOT_SERVING)
+		return
+	}
+	h.set(healthpb.HealthCheckResponse_SERVING)
+}
+
+// Run checks the database every interval until ctx is done.
+func (h *Health) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		h.Probe(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
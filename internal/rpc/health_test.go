@@ -0,0 +1,51 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pb "github.com/andro-kes/inventory_service/proto"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type stubPinger struct {
+	err error
+}
+
+func (p *stubPinger) Ping(ctx context.Context) error {
+	return p.err
+}
+
+// TestHealth tests that the status follows the database pings until
+// Shutdown.
+func TestHealth(t *testing.T) {
+	db := &stubPinger{err: errors.New("connection refused")}
+	h := NewHealth(db)
+	ctx := context.Background()
+
+	check := func(want healthpb.HealthCheckResponse_ServingStatus) {
+		t.Helper()
+		for _, service := range []string{"", pb.InventoryService_ServiceDesc.ServiceName} {
+			resp, err := h.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if resp.GetStatus() != want {
+				t.Errorf("Expected %q to be %v, got: %v", service, want, resp.GetStatus())
+			}
+		}
+	}
+
+	check(healthpb.HealthCheckResponse_NOT_SERVING)
+	h.Probe(ctx)
+	check(healthpb.HealthCheckResponse_NOT_SERVING)
+
+	db.err = nil
+	h.Probe(ctx)
+	check(healthpb.HealthCheckResponse_SERVING)
+
+	h.Shutdown()
+	h.Probe(ctx)
+	check(healthpb.HealthCheckResponse_NOT_SERVING)
+}
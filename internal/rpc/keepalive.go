@@ -0,0 +1,82 @@
+package rpc
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+// Keepalive configures how the server manages client connections. Zero
+// MaxConnectionIdle, MaxConnectionAge and MaxConnectionAgeGrace mean
+// forever, as in keepalive.ServerParameters.
+type Keepalive struct {
+	// MaxConnectionIdle closes connections without RPCs for that long.
+	MaxConnectionIdle time.Duration
+	// MaxConnectionAge closes connections after that long, give or take
+	// 10%, so that clients reconnect and load balancers can spread them.
+	MaxConnectionAge time.Duration
+	// MaxConnectionAgeGrace lets the RPCs of an aged connection finish
+	// for that long before it is closed forcibly.
+	MaxConnectionAgeGrace time.Duration
+	// Time is how long the server waits on an idle connection before
+	// pinging the client, and Timeout how long it waits for the ack.
+	Time    time.Duration
+	Timeout time.Duration
+	// MinTime is the minimum interval of client pings; clients pinging
+	// more often are disconnected. PermitWithoutStream allows pings on
+	// connections without RPCs.
+	MinTime             time.Duration
+	PermitWithoutStream bool
+}
+
+// DefaultKeepalive are the grpc defaults: connections live for as long as
+// the clients keep them.
+var DefaultKeepalive = Keepalive{
+	Time:    2 * time.Hour,
+	Timeout: 20 * time.Second,
+	MinTime: 5 * time.Minute,
+}
+
+// Validate checks that no duration is negative and that the ping
+// intervals are positive.
+func (k Keepalive) Validate() error {
+	durations := []struct {
+		name string
+		d    time.Duration
+	}{
+		{"max connection idle", k.MaxConnectionIdle},
+		{"max connection age", k.MaxConnectionAge},
+		{"max connection age grace", k.MaxConnectionAgeGrace},
+		{"keepalive time", k.Time},
+		{"keepalive timeout", k.Timeout},
+		{"keepalive min time", k.MinTime},
+	}
+	for _, d := range durations {
+		if d.d < 0 {
+			return fmt.Errorf("%s must not be negative: %s", d.name, d.d)
+		}
+	}
+	if k.Time == 0 || k.Timeout == 0 {
+		return fmt.Errorf("keepalive time and timeout must be positive")
+	}
+	return nil
+}
+
+// ServerOptions returns the grpc server options applying k.
+func (k Keepalive) ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			MaxConnectionIdle:     k.MaxConnectionIdle,
+			MaxConnectionAge:      k.MaxConnectionAge,
+			MaxConnectionAgeGrace: k.MaxConnectionAgeGrace,
+			Time:                  k.Time,
+			Timeout:               k.Timeout,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             k.MinTime,
+			PermitWithoutStream: k.PermitWithoutStream,
+		}),
+	}
+}
@@ -0,0 +1,33 @@
+package rpc
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKeepaliveValidate(t *testing.T) {
+	if err := DefaultKeepalive.Validate(); err != nil {
+		t.Errorf("Expected the defaults to be valid, got: %v", err)
+	}
+
+	aged := DefaultKeepalive
+	aged.MaxConnectionAge = 30 * time.Minute
+	aged.MaxConnectionAgeGrace = time.Minute
+	if err := aged.Validate(); err != nil {
+		t.Errorf("Expected %+v to be valid, got: %v", aged, err)
+	}
+
+	invalid := []func(k *Keepalive){
+		func(k *Keepalive) { k.MaxConnectionAge = -time.Second },
+		func(k *Keepalive) { k.MinTime = -time.Second },
+		func(k *Keepalive) { k.Time = 0 },
+		func(k *Keepalive) { k.Timeout = 0 },
+	}
+	for i, change := range invalid {
+		k := DefaultKeepalive
+		change(&k)
+		if err := k.Validate(); err == nil {
+			t.Errorf("Expected an error for case %d: %+v", i, k)
+		}
+	}
+}
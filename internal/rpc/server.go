@@ -1,83 +1,896 @@
 package rpc
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
 
+	"github.com/andro-kes/inventory_service/internal/buildinfo"
 	"github.com/andro-kes/inventory_service/internal/inverr"
+	"github.com/andro-kes/inventory_service/internal/repo"
 	"github.com/andro-kes/inventory_service/internal/services"
 	pb "github.com/andro-kes/inventory_service/proto"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/text/language"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type InventoryService struct {
 	pb.UnimplementedInventoryServiceServer
-	ProductService *services.ProductService
+	ProductService ProductService
+	// Changes feeds WatchProducts; nil disables it.
+	Changes *repo.ChangeHub
 }
 
-func NewInventoryService(ctx context.Context, pool *pgxpool.Pool) *InventoryService {
-	return &InventoryService{
-		ProductService: services.NewProductService(ctx, pool),
+// NewInventoryService returns the gRPC server of ps.
+func NewInventoryService(ps ProductService) *InventoryService {
+	return &InventoryService{ProductService: ps}
+}
+
+// hideError returns err if it is a status error, e.g. a transient
+// database error clients may retry on, and fallback otherwise, so that
+// clients do not see database internals. Errors of a request that ran
+// out of time or was cancelled keep their code.
+func hideError(err, fallback error) error {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return status.FromContextError(err).Err()
+	}
+	err = repo.TransientError(err)
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return fallback
+}
+
+// productError is hideError for the RPCs of the product id, reporting a
+// missing product as NotFound.
+func productError(err error, id string, fallback error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return status.Errorf(codes.NotFound, "product %s not found", id)
+	}
+	return hideError(err, fallback)
+}
+
+// validateID checks that the id of the request field, e.g. of a product
+// or a reservation, is a UUID, so that malformed ids are InvalidArgument
+// rather than database errors.
+func validateID(field, id string) error {
+	if id == "" {
+		return inverr.InvalidField(field, "%s is required", field)
 	}
+	if uuid.Validate(id) != nil {
+		return inverr.InvalidField(field, "invalid %s %q", field, id)
+	}
+	return nil
 }
 
+// idempotencyKeyHeader is the metadata key of the idempotency key of
+// CreateProduct when the request field is unset.
+const idempotencyKeyHeader = "idempotency-key"
+
 func (is *InventoryService) CreateProduct(ctx context.Context, req *pb.CreateRequest) (*pb.CreateResponse, error) {
-	product, err := is.ProductService.Create(ctx, req.Product)
+	key := req.GetIdempotencyKey()
+	if key == "" {
+		if values := metadata.ValueFromIncomingContext(ctx, idempotencyKeyHeader); len(values) > 0 {
+			key = values[0]
+		}
+	}
+
+	if req.GetProduct() == nil {
+		return nil, inverr.InvalidField("product", "product is required")
+	}
+
+	product, err := is.ProductService.CreateIdempotent(ctx, key, req.GetProduct())
 	if err != nil {
-		return nil, inverr.CreateProductError
+		return nil, hideError(err, inverr.CreateProductError)
 	}
+	return &pb.CreateResponse{Product: product}, nil
+}
 
-	var resp pb.CreateResponse
-	resp.Product = product
+func (is *InventoryService) DeleteProduct(ctx context.Context, req *pb.DeleteRequest) (*pb.DeleteResponse, error) {
+	if err := validateID("id", req.GetId()); err != nil {
+		return nil, err
+	}
+	if err := is.ProductService.Delete(ctx, req.GetId()); err != nil {
+		return nil, hideError(err, inverr.DeleteProductError)
+	}
+	return &pb.DeleteResponse{Success: true}, nil
+}
+
+func (is *InventoryService) BatchDeleteProducts(ctx context.Context, req *pb.BatchDeleteRequest) (*pb.BatchDeleteResponse, error) {
+	var resp pb.BatchDeleteResponse
+
+	deleted, err := is.ProductService.DeleteWhere(ctx, repo.ListFilter{
+		MinPrice:   req.GetMinPrice(),
+		MaxPrice:   req.GetMaxPrice(),
+		NameQuery:  req.GetNameQuery(),
+		Tags:       req.GetTags(),
+		Available:  req.Available,
+		CategoryID: req.GetCategoryId(),
+	})
+	if err != nil {
+		return nil, hideError(err, inverr.DeleteProductError)
+	}
+
+	resp.DeletedCount = deleted
 	return &resp, nil
 }
 
-func (is *InventoryService) DeleteProduct(ctx context.Context, req *pb.DeleteRequest) (*pb.DeleteResponse, error) {
-	var resp pb.DeleteResponse
+// maxBatchUpdateItems bounds the updates of a BatchUpdateProducts request.
+const maxBatchUpdateItems = 1000
+
+// BatchUpdateProducts applies bulk updates, e.g. price lists, atomically.
+// Failed updates are reported in the results rather than as the RPC error,
+// which is left for failures of the transaction itself.
+func (is *InventoryService) BatchUpdateProducts(ctx context.Context, req *pb.BatchUpdateProductsRequest) (*pb.BatchUpdateProductsResponse, error) {
+	requests := req.GetRequests()
+	if len(requests) > maxBatchUpdateItems {
+		return nil, inverr.InvalidField("requests", "at most %d updates allowed, got %d", maxBatchUpdateItems, len(requests))
+	}
+	updates := make([]services.ProductUpdate, len(requests))
+	for i, r := range requests {
+		if err := validateID(fmt.Sprintf("requests[%d].product.id", i), r.GetProduct().GetId()); err != nil {
+			return nil, err
+		}
+		updates[i] = services.ProductUpdate{Product: r.GetProduct(), Mask: r.GetUpdateMask()}
+	}
+
+	results, applied, err := is.ProductService.BatchUpdate(ctx, updates)
+	if err != nil {
+		return nil, hideError(err, inverr.UpdateProductError)
+	}
+
+	resp := pb.BatchUpdateProductsResponse{
+		Applied: applied,
+		Results: make([]*pb.BatchUpdateResult, len(results)),
+	}
+	for i, r := range results {
+		var st *status.Status
+		if r.Err != nil {
+			st = status.Convert(productError(r.Err, requests[i].GetProduct().GetId(), inverr.UpdateProductError))
+		}
+		resp.Results[i] = &pb.BatchUpdateResult{
+			Product: r.Product,
+			Code:    int32(st.Code()),
+			Message: st.Message(),
+		}
+	}
+	return &resp, nil
+}
+
+// ImportProductsCsv upserts the products of a CSV file by SKU. Skipped
+// rows are reported in the response; the RPC error is for invalid files
+// and failed batches.
+func (is *InventoryService) ImportProductsCsv(ctx context.Context, req *pb.ImportProductsCsvRequest) (*pb.ImportProductsCsvResponse, error) {
+	report, err := is.ProductService.ImportCSV(ctx, bytes.NewReader(req.GetCsv()), req.GetDryRun())
+	if err != nil {
+		return nil, hideError(err, inverr.ImportProductsError)
+	}
+
+	resp := pb.ImportProductsCsvResponse{
+		Rows:     int32(report.Rows),
+		Imported: int32(report.Imported),
+		Errors:   make([]*pb.ImportRowError, len(report.Errors)),
+	}
+	for i, e := range report.Errors {
+		st := status.Convert(hideError(e.Err, inverr.ImportProductsError))
+		resp.Errors[i] = &pb.ImportRowError{
+			Line:    int32(e.Line),
+			Sku:     e.SKU,
+			Code:    int32(st.Code()),
+			Message: st.Message(),
+		}
+	}
+	return &resp, nil
+}
+
+// exportChunkSize is the size of the chunks of ExportProducts.
+const exportChunkSize = 64 << 10
+
+// exportFormats maps the formats of ExportProducts to the service.
+var exportFormats = map[pb.ExportProductsRequest_Format]services.ExportFormat{
+	pb.ExportProductsRequest_FORMAT_UNSPECIFIED: services.ExportCSV,
+	pb.ExportProductsRequest_CSV:                services.ExportCSV,
+	pb.ExportProductsRequest_NDJSON:             services.ExportNDJSON,
+}
+
+// ExportProducts streams the filtered catalog in chunks of
+// exportChunkSize, sending each as it fills, so neither side holds the
+// file. Slow clients hold the export transaction open.
+func (is *InventoryService) ExportProducts(req *pb.ExportProductsRequest, stream pb.InventoryService_ExportProductsServer) error {
+	format, ok := exportFormats[req.GetFormat()]
+	if !ok {
+		return inverr.InvalidField("format", "unknown format %d", req.GetFormat())
+	}
+
+	w := bufio.NewWriterSize(chunkWriter{stream}, exportChunkSize)
+	if _, err := is.ProductService.Export(stream.Context(), listFilter(req.GetFilter()), format, w); err != nil {
+		return hideError(err, inverr.ListProductsError)
+	}
+	return w.Flush()
+}
+
+// chunkWriter sends every write as an ExportProductsChunk.
+type chunkWriter struct {
+	stream pb.InventoryService_ExportProductsServer
+}
+
+func (w chunkWriter) Write(p []byte) (int, error) {
+	// Send marshals the chunk before returning, so p may be reused.
+	if err := w.stream.Send(&pb.ExportProductsChunk{Data: p}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (is *InventoryService) ListTags(ctx context.Context, req *pb.ListTagsRequest) (*pb.ListTagsResponse, error) {
+	tags, err := is.ProductService.ListTags(ctx)
+	if err != nil {
+		return nil, hideError(err, inverr.ListTagsError)
+	}
+
+	resp := pb.ListTagsResponse{Tags: make([]*pb.Tag, len(tags))}
+	for i, t := range tags {
+		resp.Tags[i] = tagToProto(t)
+	}
+	return &resp, nil
+}
+
+// MergeTags merges tags into the tag of the request and reports how many
+// products were retagged.
+func (is *InventoryService) MergeTags(ctx context.Context, req *pb.MergeTagsRequest) (*pb.MergeTagsResponse, error) {
+	tag, retagged, err := is.ProductService.MergeTags(ctx, req.GetName(), req.GetFrom())
+	if err != nil {
+		return nil, hideError(err, inverr.MergeTagsError)
+	}
+	return &pb.MergeTagsResponse{Tag: tagToProto(tag), RetaggedProducts: retagged}, nil
+}
+
+func tagToProto(t *repo.Tag) *pb.Tag {
+	return &pb.Tag{
+		Name:         t.Name,
+		Registered:   t.Registered,
+		Aliases:      t.Aliases,
+		ProductCount: t.Products,
+	}
+}
+
+// auditOperations maps the operations of the audit trail to the API.
+var auditOperations = map[string]pb.AuditEntry_Operation{
+	"insert": pb.AuditEntry_INSERT,
+	"update": pb.AuditEntry_UPDATE,
+	"delete": pb.AuditEntry_DELETE,
+}
+
+func (is *InventoryService) ListAuditEntries(ctx context.Context, req *pb.ListAuditEntriesRequest) (*pb.ListAuditEntriesResponse, error) {
+	if req.GetPageSize() < 0 {
+		return nil, inverr.InvalidPageSize
+	}
+	filter := repo.AuditFilter{ProductID: req.GetProductId(), Actor: req.GetActor()}
+	if filter.ProductID != "" {
+		if err := validateID("product_id", filter.ProductID); err != nil {
+			return nil, err
+		}
+	}
+	if req.StartTime != nil {
+		filter.Since = req.GetStartTime().AsTime()
+	}
+	if req.EndTime != nil {
+		filter.Until = req.GetEndTime().AsTime()
+	}
+	if !filter.Since.IsZero() && !filter.Until.IsZero() && !filter.Since.Before(filter.Until) {
+		return nil, inverr.InvalidField("end_time", "end_time must be after start_time")
+	}
+
+	entries, next, err := is.ProductService.ListAuditEntries(ctx, filter, req.GetPageToken(), req.GetPageSize())
+	switch {
+	case errors.Is(err, repo.ErrInvalidCursor):
+		return nil, inverr.InvalidPageToken
+	case err != nil:
+		return nil, hideError(err, inverr.ListAuditError)
+	}
+
+	resp := pb.ListAuditEntriesResponse{
+		Entries:       make([]*pb.AuditEntry, len(entries)),
+		NextPageToken: next,
+	}
+	for i, e := range entries {
+		resp.Entries[i] = auditEntryToProto(e)
+	}
+	return &resp, nil
+}
+
+// auditEntryToProto converts e, ordering its changes by field.
+func auditEntryToProto(e *repo.AuditEntry) *pb.AuditEntry {
+	entry := &pb.AuditEntry{
+		Id:        e.ID,
+		ProductId: e.ProductID,
+		Operation: auditOperations[e.Operation],
+		Changes:   make([]*pb.FieldChange, 0, len(e.Changes)),
+		CreatedAt: timestamppb.New(e.CreatedAt),
+	}
+	if e.Actor != nil {
+		entry.Actor = *e.Actor
+	}
+	if e.RPC != nil {
+		entry.Rpc = *e.RPC
+	}
+	for _, field := range slices.Sorted(maps.Keys(e.Changes)) {
+		change := e.Changes[field]
+		entry.Changes = append(entry.Changes, &pb.FieldChange{
+			Field:  field,
+			Before: string(change.Before),
+			After:  string(change.After),
+		})
+	}
+	return entry
+}
+
+// PurchaseProduct takes the purchased units out of stock with a single
+// conditional update, so concurrent purchases cannot oversell. Insufficient
+// stock is reported as FailedPrecondition; see repo.InsufficientStockError.
+func (is *InventoryService) PurchaseProduct(ctx context.Context, req *pb.PurchaseRequest) (*pb.PurchaseResponse, error) {
+	var resp pb.PurchaseResponse
+	if err := validateID("product_id", req.GetProductId()); err != nil {
+		return nil, err
+	}
+
+	remaining, err := is.ProductService.DecrementQuantity(ctx, req.GetProductId(), req.GetQuantity())
+	if err != nil {
+		return nil, productError(err, req.GetProductId(), inverr.PurchaseError)
+	}
+
+	resp.RemainingQuantity = remaining
+	return &resp, nil
+}
+
+// adjustReasons maps the AdjustStock reasons to the stock ledger.
+var adjustReasons = map[pb.AdjustStockRequest_Reason]string{
+	pb.AdjustStockRequest_RECEIVED:   repo.ReasonReceived,
+	pb.AdjustStockRequest_RETURNED:   repo.ReasonReturned,
+	pb.AdjustStockRequest_DAMAGED:    repo.ReasonDamaged,
+	pb.AdjustStockRequest_LOST:       repo.ReasonLost,
+	pb.AdjustStockRequest_SOLD:       repo.ReasonSold,
+	pb.AdjustStockRequest_CORRECTION: repo.ReasonCorrection,
+}
+
+// AdjustStock books a stock change with its reason and reference in the
+// ledger, where an UpdateProduct of quantity is booked as a plain update.
+func (is *InventoryService) AdjustStock(ctx context.Context, req *pb.AdjustStockRequest) (*pb.AdjustStockResponse, error) {
+	if err := validateID("product_id", req.GetProductId()); err != nil {
+		return nil, err
+	}
+	reason, ok := adjustReasons[req.GetReason()]
+	if !ok {
+		return nil, inverr.InvalidField("reason", "reason is required")
+	}
+
+	quantity, err := is.ProductService.AdjustStock(ctx, req.GetProductId(), req.GetDelta(), reason, req.GetReference())
+	if err != nil {
+		return nil, productError(err, req.GetProductId(), inverr.AdjustStockError)
+	}
+	return &pb.AdjustStockResponse{Quantity: quantity}, nil
+}
+
+func (is *InventoryService) ListReorderSuggestions(ctx context.Context, req *pb.ListReorderSuggestionsRequest) (*pb.ListReorderSuggestionsResponse, error) {
+	if req.GetPageSize() < 0 {
+		return nil, inverr.InvalidPageSize
+	}
+
+	suggestions, next, err := is.ProductService.ListReorderSuggestions(ctx, req.GetPageToken(), req.GetPageSize())
+	switch {
+	case errors.Is(err, repo.ErrInvalidCursor):
+		return nil, inverr.InvalidPageToken
+	case err != nil:
+		return nil, hideError(err, inverr.ListReorderError)
+	}
+
+	resp := pb.ListReorderSuggestionsResponse{
+		Suggestions:   make([]*pb.ReorderSuggestion, len(suggestions)),
+		NextPageToken: next,
+	}
+	for i, s := range suggestions {
+		resp.Suggestions[i] = &pb.ReorderSuggestion{
+			ProductId:         s.ProductID,
+			Quantity:          s.Quantity,
+			ReorderPoint:      s.ReorderPoint,
+			ReorderQuantity:   s.ReorderQuantity,
+			DailyOutflow:      s.DailyOutflow,
+			DaysOfCover:       s.DaysOfCover,
+			SuggestedQuantity: s.SuggestedQuantity,
+			ComputedAt:        timestamppb.New(s.ComputedAt),
+		}
+	}
+	return &resp, nil
+}
+
+// ReceiveLot books received units to a lot; see
+// ProductService.ReceiveLot. The expiry date is parsed here, the lot
+// number and quantity are checked by the repo.
+func (is *InventoryService) ReceiveLot(ctx context.Context, req *pb.ReceiveLotRequest) (*pb.ReceiveLotResponse, error) {
+	if err := validateID("product_id", req.GetProductId()); err != nil {
+		return nil, err
+	}
+	expiresOn, err := time.Parse(lotDateLayout, req.GetExpiryDate())
+	if err != nil {
+		return nil, inverr.InvalidField("expiry_date", "expiry_date must be a date as YYYY-MM-DD: %q", req.GetExpiryDate())
+	}
+
+	l := &repo.Lot{
+		ProductID: req.GetProductId(),
+		Number:    req.GetLotNumber(),
+		ExpiresOn: expiresOn,
+		Quantity:  req.GetQuantity(),
+	}
+	lot, quantity, err := is.ProductService.ReceiveLot(ctx, l, req.GetReference())
+	if err != nil {
+		return nil, productError(err, req.GetProductId(), inverr.ReceiveLotError)
+	}
+	return &pb.ReceiveLotResponse{Lot: lotProto(lot), Quantity: quantity}, nil
+}
+
+func (is *InventoryService) ListExpiringLots(ctx context.Context, req *pb.ListExpiringLotsRequest) (*pb.ListExpiringLotsResponse, error) {
+	if req.GetPageSize() < 0 {
+		return nil, inverr.InvalidPageSize
+	}
+	if req.GetDays() < 0 {
+		return nil, inverr.InvalidField("days", "days must not be negative: %d", req.GetDays())
+	}
+	if req.GetProductId() != "" {
+		if err := validateID("product_id", req.GetProductId()); err != nil {
+			return nil, err
+		}
+	}
+
+	lots, next, err := is.ProductService.ListExpiringLots(ctx, req.GetDays(), req.GetProductId(), req.GetPageToken(), req.GetPageSize())
+	switch {
+	case errors.Is(err, repo.ErrInvalidCursor):
+		return nil, inverr.InvalidPageToken
+	case err != nil:
+		return nil, hideError(err, inverr.ListLotsError)
+	}
+
+	resp := pb.ListExpiringLotsResponse{
+		Lots:          make([]*pb.Lot, len(lots)),
+		NextPageToken: next,
+	}
+	for i, l := range lots {
+		resp.Lots[i] = lotProto(l)
+	}
+	return &resp, nil
+}
+
+// lotDateLayout is the layout of the expiry dates of lots.
+const lotDateLayout = "2006-01-02"
+
+func lotProto(l *repo.Lot) *pb.Lot {
+	return &pb.Lot{
+		Id:         l.ID,
+		ProductId:  l.ProductID,
+		LotNumber:  l.Number,
+		ExpiryDate: l.ExpiresOn.Format(lotDateLayout),
+		Quantity:   l.Quantity,
+		CreatedAt:  timestamppb.New(l.CreatedAt),
+	}
+}
+
+// defaultReservationTTL holds reserved units when ReserveStock sets no ttl.
+const defaultReservationTTL = 15 * time.Minute
+
+func (is *InventoryService) ReserveStock(ctx context.Context, req *pb.ReserveStockRequest) (*pb.ReservationResponse, error) {
+	ttl := defaultReservationTTL
+	if req.Ttl != nil {
+		ttl = req.GetTtl().AsDuration()
+	}
+	if ttl <= 0 {
+		return nil, inverr.InvalidTTL
+	}
+	if err := validateID("product_id", req.GetProductId()); err != nil {
+		return nil, err
+	}
+
+	r, err := is.ProductService.ReserveStock(ctx, req.GetProductId(), req.GetOrderId(), req.GetQuantity(), ttl)
+	if err != nil {
+		return nil, productError(err, req.GetProductId(), inverr.ReserveStockError)
+	}
+	return &pb.ReservationResponse{Reservation: reservationProto(r)}, nil
+}
+
+func (is *InventoryService) ConfirmReservation(ctx context.Context, req *pb.ReservationRequest) (*pb.ReservationResponse, error) {
+	if err := validateID("id", req.GetId()); err != nil {
+		return nil, err
+	}
+
+	r, err := is.ProductService.ConfirmReservation(ctx, req.GetId())
+	if err != nil {
+		return nil, hideError(err, inverr.UpdateReservationError)
+	}
+	return &pb.ReservationResponse{Reservation: reservationProto(r)}, nil
+}
+
+func (is *InventoryService) ReleaseReservation(ctx context.Context, req *pb.ReservationRequest) (*pb.ReservationResponse, error) {
+	if err := validateID("id", req.GetId()); err != nil {
+		return nil, err
+	}
+
+	r, err := is.ProductService.ReleaseReservation(ctx, req.GetId())
+	if err != nil {
+		return nil, hideError(err, inverr.UpdateReservationError)
+	}
+	return &pb.ReservationResponse{Reservation: reservationProto(r)}, nil
+}
+
+func (is *InventoryService) GetReservation(ctx context.Context, req *pb.ReservationRequest) (*pb.ReservationResponse, error) {
+	if err := validateID("id", req.GetId()); err != nil {
+		return nil, err
+	}
 
-	err := is.ProductService.Delete(ctx, req.Id)
+	r, err := is.ProductService.GetReservation(ctx, req.GetId())
 	if err != nil {
-		resp.Success = false
-		return nil, inverr.DeleteProductError
+		return nil, hideError(err, inverr.GetReservationError)
+	}
+	return &pb.ReservationResponse{Reservation: reservationProto(r)}, nil
+}
+
+// reservationStatuses maps the stored reservation statuses to the API.
+var reservationStatuses = map[string]pb.Reservation_Status{
+	repo.ReservationActive:    pb.Reservation_ACTIVE,
+	repo.ReservationConfirmed: pb.Reservation_CONFIRMED,
+	repo.ReservationReleased:  pb.Reservation_RELEASED,
+	repo.ReservationExpired:   pb.Reservation_EXPIRED,
+}
+
+// reservationProto converts r for the API, reporting active reservations
+// past their expiry as EXPIRED.
+func reservationProto(r *repo.Reservation) *pb.Reservation {
+	state := reservationStatuses[r.Status]
+	if r.Expired(time.Now()) {
+		state = pb.Reservation_EXPIRED
+	}
+	return &pb.Reservation{
+		Id:        r.ID,
+		ProductId: r.ProductID,
+		OrderId:   r.OrderID,
+		Quantity:  r.Quantity,
+		Status:    state,
+		CreatedAt: timestamppb.New(r.CreatedAt),
+		ExpiresAt: timestamppb.New(r.ExpiresAt),
+	}
+}
+
+// maxAvailabilityItems bounds the items of a CheckAvailability request.
+const maxAvailabilityItems = 1000
+
+// CheckAvailability validates a cart in one round trip instead of one
+// GetProduct per line.
+func (is *InventoryService) CheckAvailability(ctx context.Context, req *pb.CheckAvailabilityRequest) (*pb.CheckAvailabilityResponse, error) {
+	items := req.GetItems()
+	if len(items) > maxAvailabilityItems {
+		return nil, inverr.InvalidField("items", "at most %d items allowed, got %d", maxAvailabilityItems, len(items))
+	}
+	ids := make([]string, len(items))
+	for i, item := range items {
+		if err := validateID(fmt.Sprintf("items[%d].product_id", i), item.GetProductId()); err != nil {
+			return nil, err
+		}
+		if item.GetQuantity() <= 0 {
+			return nil, inverr.InvalidField(fmt.Sprintf("items[%d].quantity", i), "quantity must be positive: %d", item.GetQuantity())
+		}
+		ids[i] = item.GetProductId()
+	}
+
+	stock, err := is.ProductService.StockOf(ctx, ids)
+	if err != nil {
+		return nil, hideError(err, inverr.CheckAvailabilityError)
 	}
 
-	resp.Success = true
+	resp := pb.CheckAvailabilityResponse{Items: make([]*pb.ItemAvailability, len(items))}
+	for i, item := range items {
+		s, found := stock[item.GetProductId()]
+		resp.Items[i] = &pb.ItemAvailability{
+			ProductId:         item.GetProductId(),
+			RequestedQuantity: item.GetQuantity(),
+			Found:             found,
+			AvailableQuantity: s.Free,
+			Available:         found && s.Available && s.Free >= item.GetQuantity(),
+		}
+	}
 	return &resp, nil
 }
 
+// watchBuffer is the number of changes queued for a WatchProducts stream
+// before it misses changes and gets a RESET.
+const watchBuffer = 256
+
+// WatchProducts streams the changes published on is.Changes until the
+// client goes away. Closing is.Changes ends the streams with Unavailable,
+// so clients reconnect to another instance.
+func (is *InventoryService) WatchProducts(req *pb.WatchProductsRequest, stream pb.InventoryService_WatchProductsServer) error {
+	if is.Changes == nil {
+		return status.Error(codes.Unavailable, "product changes are not available")
+	}
+	changes, cancel := is.Changes.Subscribe(watchBuffer)
+	defer cancel()
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case c, ok := <-changes:
+			if !ok {
+				return status.Error(codes.Unavailable, "server is shutting down")
+			}
+			change, err := is.productChange(ctx, c)
+			if err != nil {
+				return err
+			}
+			if change == nil {
+				continue
+			}
+			if err := stream.Send(change); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// changeTypes maps the operations of repo.Change to the API.
+var changeTypes = map[string]pb.ProductChange_Type{
+	repo.OpCreate:  pb.ProductChange_CREATED,
+	repo.OpUpdate:  pb.ProductChange_UPDATED,
+	repo.OpUpsert:  pb.ProductChange_UPDATED,
+	repo.OpRestore: pb.ProductChange_UPDATED,
+	repo.OpDelete:  pb.ProductChange_DELETED,
+	repo.OpReset:   pb.ProductChange_RESET,
+}
+
+// productChange converts c for WatchProducts, reading the changed product
+// past the cache. It returns nil for changes not to send: unknown
+// operations, and products deleted since, whose deletion follows.
+func (is *InventoryService) productChange(ctx context.Context, c repo.Change) (*pb.ProductChange, error) {
+	change := &pb.ProductChange{Type: changeTypes[c.Op]}
+	switch change.Type {
+	case pb.ProductChange_TYPE_UNSPECIFIED:
+		return nil, nil
+	case pb.ProductChange_RESET:
+		return change, nil
+	case pb.ProductChange_DELETED:
+		change.Product = &pb.Product{Id: c.ID}
+		return change, nil
+	}
+
+	p, err := is.ProductService.Get(repo.WithoutCache(ctx), c.ID)
+	if errors.Is(err, pgx.ErrNoRows) || status.Code(err) == codes.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	change.Product = p
+	return change, nil
+}
+
 func (is *InventoryService) ListProducts(ctx context.Context, req *pb.ListRequest) (*pb.ListResponse, error) {
 	var resp pb.ListResponse
+	if req.GetPageSize() < 0 {
+		return nil, inverr.InvalidPageSize
+	}
+	ctx, err := repo.WithReadMask(ctx, req.GetReadMask().GetPaths())
+	if err != nil {
+		return nil, err
+	}
+	prefs, err := locales(ctx, req.GetLocale())
+	if err != nil {
+		return nil, err
+	}
 
-	products, err := is.ProductService.List(ctx, req.PrevSize, req.PageSize, req.Filter, req.OrderBy)
+	filter := listFilter(req)
+	total, err := is.ProductService.Count(ctx, filter)
 	if err != nil {
-		return nil, inverr.ListProductsError
+		return nil, hideError(err, inverr.ListProductsError)
+	}
+	resp.TotalSize = int32(total)
+
+	products, next, err := is.ProductService.ListAfter(ctx, req.GetPageToken(), req.GetPageSize(), filter, req.GetOrderBy())
+	switch {
+	case errors.Is(err, repo.ErrInvalidCursor):
+		return nil, inverr.InvalidPageToken
+	case errors.Is(err, repo.ErrInvalidOrderBy):
+		return nil, inverr.InvalidOrderBy
+	case err != nil:
+		return nil, hideError(err, inverr.ListProductsError)
+	}
+
+	if err := is.ProductService.Localize(ctx, prefs, products...); err != nil {
+		return nil, hideError(err, inverr.ListProductsError)
+	}
+	if req.GetExpandVariants() {
+		if err := is.ProductService.ExpandVariants(ctx, products...); err != nil {
+			return nil, hideError(err, inverr.ListProductsError)
+		}
+	}
+	if err := is.ProductService.ConvertPrices(ctx, req.GetCurrency(), products...); err != nil {
+		return nil, hideError(err, inverr.ListProductsError)
 	}
 
 	resp.Products = products
+	resp.NextPageToken = next
 	return &resp, nil
 }
 
+// listFilter translates the filter fields of a ListRequest. The legacy
+// filter field is a single tag. Without statuses only ACTIVE products are
+// listed by default.
+func listFilter(req *pb.ListRequest) repo.ListFilter {
+	available := req.Available
+	if available == nil && len(req.GetStatuses()) == 0 {
+		active := true
+		available = &active
+	}
+
+	tags := req.GetTags()
+	if req.GetFilter() != "" {
+		tags = append([]string{req.GetFilter()}, tags...)
+	}
+
+	return repo.ListFilter{
+		MinPrice:   req.GetMinPrice(),
+		MaxPrice:   req.GetMaxPrice(),
+		NameQuery:  req.GetNameQuery(),
+		Tags:       tags,
+		Available:  available,
+		Statuses:   req.GetStatuses(),
+		Attributes: req.GetAttributes(),
+		CategoryID: req.GetCategoryId(),
+		SKU:        req.GetSku(),
+		Barcode:    req.GetBarcode(),
+	}
+}
+
 func (is *InventoryService) UpdateProduct(ctx context.Context, req *pb.UpdateRequest) (*pb.UpdateResponse, error) {
-	var resp pb.UpdateResponse
+	if err := validateID("product.id", req.GetProduct().GetId()); err != nil {
+		return nil, err
+	}
 
 	product, err := is.ProductService.Update(ctx, req.GetProduct(), req.GetUpdateMask())
 	if err != nil {
+		return nil, productError(err, req.GetProduct().GetId(), inverr.UpdateProductError)
+	}
+	return &pb.UpdateResponse{Product: product}, nil
+}
+
+func (is *InventoryService) GetProduct(ctx context.Context, req *pb.GetRequest) (*pb.GetResponse, error) {
+	if err := validateID("id", req.GetId()); err != nil {
 		return nil, err
 	}
+	ctx, err := repo.WithReadMask(ctx, req.GetReadMask().GetPaths())
+	if err != nil {
+		return nil, err
+	}
+	prefs, err := locales(ctx, req.GetLocale())
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := is.ProductService.Get(ctx, req.GetId())
+	if err != nil {
+		return nil, productError(err, req.GetId(), inverr.GetProductError)
+	}
+	if err := is.ProductService.Localize(ctx, prefs, product); err != nil {
+		return nil, hideError(err, inverr.GetProductError)
+	}
+	if req.GetExpandVariants() {
+		if err := is.ProductService.ExpandVariants(ctx, product); err != nil {
+			return nil, hideError(err, inverr.GetProductError)
+		}
+	}
+	if err := is.ProductService.ConvertPrices(ctx, req.GetCurrency(), product); err != nil {
+		return nil, hideError(err, inverr.GetProductError)
+	}
+	return &pb.GetResponse{Product: product}, nil
+}
+
+// localeHeader is the metadata key of the preferred locales of GetProduct
+// and ListProducts when the request locale is unset.
+const localeHeader = "accept-language"
+
+// locales parses the preferred locales of a request, in Accept-Language
+// syntax, e.g. "de-AT, de;q=0.9, en;q=0.5". A malformed locale field is an
+// InvalidArgument error; a malformed header is ignored.
+func locales(ctx context.Context, locale string) ([]language.Tag, error) {
+	if locale != "" {
+		tags, _, err := language.ParseAcceptLanguage(locale)
+		if err != nil {
+			return nil, inverr.InvalidField("locale", "invalid locale %q", locale)
+		}
+		return tags, nil
+	}
+	if values := metadata.ValueFromIncomingContext(ctx, localeHeader); len(values) > 0 {
+		tags, _, _ := language.ParseAcceptLanguage(values[0])
+		return tags, nil
+	}
+	return nil, nil
+}
+
+func (is *InventoryService) GetProductBySku(ctx context.Context, req *pb.GetBySkuRequest) (*pb.GetResponse, error) {
+	var resp pb.GetResponse
+
+	product, err := is.ProductService.GetBySKU(ctx, req.GetSku())
+	if err != nil {
+		return nil, hideError(err, inverr.GetProductError)
+	}
 
 	resp.Product = product
 
 	return &resp, nil
 }
 
-func (is *InventoryService) GetProduct(ctx context.Context, req *pb.GetRequest) (*pb.GetResponse, error) {
+func (is *InventoryService) GetProductByBarcode(ctx context.Context, req *pb.GetByBarcodeRequest) (*pb.GetResponse, error) {
 	var resp pb.GetResponse
 
-	product, err := is.ProductService.Get(ctx, req.Id)
+	product, err := is.ProductService.GetByBarcode(ctx, req.GetBarcode())
 	if err != nil {
-		return nil, err
+		return nil, hideError(err, inverr.GetProductError)
 	}
 
 	resp.Product = product
 
 	return &resp, nil
 }
+
+func (is *InventoryService) CreateVariant(ctx context.Context, req *pb.CreateVariantRequest) (*pb.VariantResponse, error) {
+	if req.GetVariant() == nil {
+		return nil, inverr.InvalidField("variant", "variant is required")
+	}
+
+	v, err := is.ProductService.CreateVariant(ctx, req.GetVariant())
+	if err != nil {
+		return nil, hideError(err, inverr.CreateVariantError)
+	}
+	return &pb.VariantResponse{Variant: v}, nil
+}
+
+func (is *InventoryService) GetVariant(ctx context.Context, req *pb.GetVariantRequest) (*pb.VariantResponse, error) {
+	v, err := is.ProductService.GetVariant(ctx, req.GetId())
+	if err != nil {
+		return nil, hideError(err, inverr.GetVariantError)
+	}
+	return &pb.VariantResponse{Variant: v}, nil
+}
+
+func (is *InventoryService) UpdateVariant(ctx context.Context, req *pb.UpdateVariantRequest) (*pb.VariantResponse, error) {
+	v, err := is.ProductService.UpdateVariant(ctx, req.GetVariant(), req.GetUpdateMask())
+	if err != nil {
+		return nil, hideError(err, inverr.UpdateVariantError)
+	}
+	return &pb.VariantResponse{Variant: v}, nil
+}
+
+func (is *InventoryService) DeleteVariant(ctx context.Context, req *pb.DeleteVariantRequest) (*pb.DeleteVariantResponse, error) {
+	if err := is.ProductService.DeleteVariant(ctx, req.GetId()); err != nil {
+		return nil, hideError(err, inverr.DeleteVariantError)
+	}
+	return &pb.DeleteVariantResponse{Success: true}, nil
+}
+
+func (is *InventoryService) ListVariants(ctx context.Context, req *pb.ListVariantsRequest) (*pb.ListVariantsResponse, error) {
+	variants, err := is.ProductService.ListVariants(ctx, req.GetProductId())
+	if err != nil {
+		return nil, hideError(err, inverr.ListVariantsError)
+	}
+	return &pb.ListVariantsResponse{Variants: variants}, nil
+}
+
+func (is *InventoryService) GetServerInfo(ctx context.Context, req *pb.GetServerInfoRequest) (*pb.ServerInfo, error) {
+	return buildinfo.Get(), nil
+}
@@ -0,0 +1,984 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/andro-kes/inventory_service/internal/repo"
+	"github.com/andro-kes/inventory_service/internal/services"
+	pb "github.com/andro-kes/inventory_service/proto"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/text/language"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// fakeService stores products in memory for the handlers under test.
+// err, if set, fails every call; the other ProductService methods are not
+// used.
+type fakeService struct {
+	ProductService
+	products map[string]*pb.Product
+	stock    map[string]repo.Stock
+	err      error
+
+	// adjustments are the reasons and references of the AdjustStock
+	// calls.
+	adjustments [][2]string
+	// cursor and pageSize are the arguments of the last ListAfter.
+	cursor   string
+	pageSize int32
+	// filter is the filter of the last Export.
+	filter repo.ListFilter
+}
+
+func newFakeService(products ...*pb.Product) *fakeService {
+	s := &fakeService{products: make(map[string]*pb.Product)}
+	for _, p := range products {
+		s.products[p.GetId()] = p
+	}
+	return s
+}
+
+func (s *fakeService) CreateIdempotent(ctx context.Context, key string, p *pb.Product) (*pb.Product, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	created := proto.Clone(p).(*pb.Product)
+	created.Id = uuid.NewString()
+	created.Status = pb.Product_DRAFT
+	s.products[created.GetId()] = created
+	return created, nil
+}
+
+func (s *fakeService) Get(ctx context.Context, id string) (*pb.Product, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	p, ok := s.products[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return p, nil
+}
+
+func (s *fakeService) Update(ctx context.Context, p *pb.Product, mask *fieldmaskpb.FieldMask) (*pb.Product, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if _, ok := s.products[p.GetId()]; !ok {
+		return nil, status.Errorf(codes.NotFound, "product not found: %s", p.GetId())
+	}
+	s.products[p.GetId()] = p
+	return p, nil
+}
+
+func (s *fakeService) Delete(ctx context.Context, id string) error {
+	if s.err != nil {
+		return s.err
+	}
+	if _, ok := s.products[id]; !ok {
+		return status.Errorf(codes.NotFound, "product not found: %s", id)
+	}
+	delete(s.products, id)
+	return nil
+}
+
+func (s *fakeService) Count(ctx context.Context, filter repo.ListFilter) (int64, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	return int64(len(s.products)), nil
+}
+
+func (s *fakeService) ListAfter(ctx context.Context, cursor string, pageSize int32, filter repo.ListFilter, orderBy string) ([]*pb.Product, string, error) {
+	if s.err != nil {
+		return nil, "", s.err
+	}
+	if cursor == "bad" {
+		return nil, "", repo.ErrInvalidCursor
+	}
+	s.cursor, s.pageSize = cursor, pageSize
+	products := make([]*pb.Product, 0, len(s.products))
+	for _, p := range s.products {
+		products = append(products, p)
+	}
+	return products, "next", nil
+}
+
+func (s *fakeService) Localize(ctx context.Context, prefs []language.Tag, products ...*pb.Product) error {
+	return s.err
+}
+
+// ConvertPrices marks the prices as converted to currency.
+func (s *fakeService) ConvertPrices(ctx context.Context, currency string, products ...*pb.Product) error {
+	if currency == "" {
+		return nil
+	}
+	for _, p := range products {
+		p.PriceMoney = &pb.Money{CurrencyCode: currency, Units: p.GetPriceMoney().GetUnits()}
+	}
+	return s.err
+}
+
+func (s *fakeService) AdjustStock(ctx context.Context, id string, delta int32, reason, reference string) (int32, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	s.adjustments = append(s.adjustments, [2]string{reason, reference})
+	return 10 + delta, nil
+}
+
+func (s *fakeService) StockOf(ctx context.Context, ids []string) (map[string]repo.Stock, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.stock, nil
+}
+
+func TestCreateProduct(t *testing.T) {
+	fake := newFakeService()
+	is := NewInventoryService(fake)
+
+	resp, err := is.CreateProduct(context.Background(), &pb.CreateRequest{Product: &pb.Product{Name: "laptop"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.GetProduct().GetId() == "" || resp.GetProduct().GetName() != "laptop" || resp.GetProduct().GetStatus() != pb.Product_DRAFT {
+		t.Errorf("Expected the created product, got: %v", resp.GetProduct())
+	}
+
+	if _, err := is.CreateProduct(context.Background(), &pb.CreateRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument without a product, got: %v", err)
+	}
+
+	fake.err = errors.New("relation products does not exist")
+	_, err = is.CreateProduct(context.Background(), &pb.CreateRequest{Product: &pb.Product{Name: "laptop"}})
+	if st := status.Convert(err); st.Code() != codes.Internal || st.Message() != "failed to create product" {
+		t.Errorf("Expected a generic Internal error, got: %v", err)
+	}
+}
+
+func TestGetProduct(t *testing.T) {
+	id := uuid.NewString()
+	is := NewInventoryService(newFakeService(&pb.Product{Id: id, Name: "laptop"}))
+
+	resp, err := is.GetProduct(context.Background(), &pb.GetRequest{Id: id})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.GetProduct().GetName() != "laptop" {
+		t.Errorf("Expected laptop, got: %v", resp.GetProduct())
+	}
+
+	resp, err = is.GetProduct(context.Background(), &pb.GetRequest{Id: id, Currency: "USD"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.GetProduct().GetPriceMoney().GetCurrencyCode() != "USD" {
+		t.Errorf("Expected the price in USD, got: %v", resp.GetProduct())
+	}
+
+	cases := map[string]codes.Code{
+		"":               codes.InvalidArgument,
+		"1":              codes.InvalidArgument,
+		uuid.NewString(): codes.NotFound,
+	}
+	for id, code := range cases {
+		if _, err := is.GetProduct(context.Background(), &pb.GetRequest{Id: id}); status.Code(err) != code {
+			t.Errorf("Expected %s for id %q, got: %v", code, id, err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	is = NewInventoryService(&fakeService{err: ctx.Err()})
+	if _, err := is.GetProduct(ctx, &pb.GetRequest{Id: id}); status.Code(err) != codes.Canceled {
+		t.Errorf("Expected Canceled, got: %v", err)
+	}
+}
+
+func TestUpdateProduct(t *testing.T) {
+	id := uuid.NewString()
+	is := NewInventoryService(newFakeService(&pb.Product{Id: id, Name: "laptop"}))
+	mask := &fieldmaskpb.FieldMask{Paths: []string{"name"}}
+
+	resp, err := is.UpdateProduct(context.Background(), &pb.UpdateRequest{Product: &pb.Product{Id: id, Name: "notebook"}, UpdateMask: mask})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.GetProduct().GetName() != "notebook" {
+		t.Errorf("Expected the updated product, got: %v", resp.GetProduct())
+	}
+
+	_, err = is.UpdateProduct(context.Background(), &pb.UpdateRequest{Product: &pb.Product{Id: uuid.NewString()}, UpdateMask: mask})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound, got: %v", err)
+	}
+	if _, err := is.UpdateProduct(context.Background(), &pb.UpdateRequest{UpdateMask: mask}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument without a product, got: %v", err)
+	}
+}
+
+func TestDeleteProduct(t *testing.T) {
+	id := uuid.NewString()
+	fake := newFakeService(&pb.Product{Id: id})
+	is := NewInventoryService(fake)
+
+	resp, err := is.DeleteProduct(context.Background(), &pb.DeleteRequest{Id: id})
+	if err != nil || !resp.GetSuccess() {
+		t.Fatalf("Expected success, got: %v, %v", resp, err)
+	}
+	if _, ok := fake.products[id]; ok {
+		t.Error("Expected the product to be deleted")
+	}
+	if _, err := is.DeleteProduct(context.Background(), &pb.DeleteRequest{Id: id}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound for a deleted product, got: %v", err)
+	}
+
+	fake.err = errors.New("connection refused")
+	if _, err := is.DeleteProduct(context.Background(), &pb.DeleteRequest{Id: id}); status.Code(err) != codes.Internal {
+		t.Errorf("Expected Internal, got: %v", err)
+	}
+}
+
+func (s *fakeService) DeleteWhere(ctx context.Context, filter repo.ListFilter) (int64, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	deleted := int64(len(s.products))
+	clear(s.products)
+	return deleted, nil
+}
+
+func TestBatchDeleteProducts(t *testing.T) {
+	fake := newFakeService(&pb.Product{Id: uuid.NewString()}, &pb.Product{Id: uuid.NewString()})
+	is := NewInventoryService(fake)
+
+	resp, err := is.BatchDeleteProducts(context.Background(), &pb.BatchDeleteRequest{NameQuery: "tv"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.GetDeletedCount() != 2 {
+		t.Errorf("Expected 2 deleted products, got: %d", resp.GetDeletedCount())
+	}
+
+	fake.err = errors.New("connection refused")
+	_, err = is.BatchDeleteProducts(context.Background(), &pb.BatchDeleteRequest{})
+	if st := status.Convert(err); st.Code() != codes.Internal || st.Message() != "failed to delete product" {
+		t.Errorf("Expected the database error hidden, got: %v", err)
+	}
+}
+
+func (s *fakeService) BatchUpdate(ctx context.Context, updates []services.ProductUpdate) ([]services.UpdateResult, bool, error) {
+	if s.err != nil {
+		return nil, false, s.err
+	}
+	results := make([]services.UpdateResult, len(updates))
+	for i, u := range updates {
+		if _, ok := s.products[u.Product.GetId()]; !ok {
+			results[i].Err = pgx.ErrNoRows
+		}
+	}
+	return results, false, nil
+}
+
+func TestBatchUpdateProducts(t *testing.T) {
+	id := uuid.NewString()
+	fake := newFakeService(&pb.Product{Id: id})
+	is := NewInventoryService(fake)
+
+	resp, err := is.BatchUpdateProducts(context.Background(), &pb.BatchUpdateProductsRequest{Requests: []*pb.UpdateRequest{
+		{Product: &pb.Product{Id: id}},
+		{Product: &pb.Product{Id: uuid.NewString()}},
+	}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if codes.Code(resp.GetResults()[0].GetCode()) != codes.OK || codes.Code(resp.GetResults()[1].GetCode()) != codes.NotFound {
+		t.Errorf("Expected OK and NotFound, got: %v", resp.GetResults())
+	}
+
+	_, err = is.BatchUpdateProducts(context.Background(), &pb.BatchUpdateProductsRequest{Requests: []*pb.UpdateRequest{
+		{Product: &pb.Product{Id: id}},
+		{Product: &pb.Product{Id: "1"}},
+	}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for a malformed id, got: %v", err)
+	}
+
+	fake.err = errors.New("connection refused")
+	_, err = is.BatchUpdateProducts(context.Background(), &pb.BatchUpdateProductsRequest{Requests: []*pb.UpdateRequest{{Product: &pb.Product{Id: id}}}})
+	if st := status.Convert(err); st.Code() != codes.Internal || st.Message() != "failed to update product" {
+		t.Errorf("Expected the database error hidden, got: %v", err)
+	}
+}
+
+func (s *fakeService) ImportCSV(ctx context.Context, r io.Reader, dryRun bool) (*services.ImportReport, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &services.ImportReport{Rows: 2, Imported: 1, Errors: []services.ImportRowError{
+		{Line: 3, SKU: "TV-1", Err: errors.New(`duplicate key value violates unique constraint "products_barcode_key"`)},
+	}}, nil
+}
+
+func TestImportProductsCsv(t *testing.T) {
+	fake := newFakeService()
+	is := NewInventoryService(fake)
+
+	resp, err := is.ImportProductsCsv(context.Background(), &pb.ImportProductsCsvRequest{Csv: []byte("sku,name\n")})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(resp.GetErrors()) != 1 || codes.Code(resp.GetErrors()[0].GetCode()) != codes.Internal || resp.GetErrors()[0].GetMessage() != "failed to import products" {
+		t.Errorf("Expected the database error of line 3 hidden, got: %v", resp.GetErrors())
+	}
+
+	fake.err = errors.New("connection refused")
+	_, err = is.ImportProductsCsv(context.Background(), &pb.ImportProductsCsvRequest{})
+	if st := status.Convert(err); st.Code() != codes.Internal || st.Message() != "failed to import products" {
+		t.Errorf("Expected the database error hidden, got: %v", err)
+	}
+	fake.err = status.Error(codes.InvalidArgument, "the file is empty")
+	if _, err := is.ImportProductsCsv(context.Background(), &pb.ImportProductsCsvRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument, got: %v", err)
+	}
+}
+
+func TestListProducts(t *testing.T) {
+	fake := newFakeService(&pb.Product{Id: uuid.NewString()}, &pb.Product{Id: uuid.NewString()})
+	is := NewInventoryService(fake)
+
+	resp, err := is.ListProducts(context.Background(), &pb.ListRequest{PageSize: 10, PageToken: "token"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(resp.GetProducts()) != 2 || resp.GetTotalSize() != 2 || resp.GetNextPageToken() != "next" {
+		t.Errorf("Expected 2 products of 2 and the next page token, got: %v", resp)
+	}
+	if fake.cursor != "token" || fake.pageSize != 10 {
+		t.Errorf("Expected page_token and page_size to be passed through, got: %q %d", fake.cursor, fake.pageSize)
+	}
+
+	for _, req := range []*pb.ListRequest{{PageSize: -1}, {PageToken: "bad"}} {
+		if _, err := is.ListProducts(context.Background(), req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument for %v, got: %v", req, err)
+		}
+	}
+}
+
+func TestCheckAvailability(t *testing.T) {
+	a, b, c := uuid.NewString(), uuid.NewString(), uuid.NewString()
+	fake := newFakeService()
+	fake.stock = map[string]repo.Stock{
+		a: {Available: true, Free: 5},
+		b: {Available: false, Free: 5},
+	}
+	is := NewInventoryService(fake)
+
+	resp, err := is.CheckAvailability(context.Background(), &pb.CheckAvailabilityRequest{Items: []*pb.CheckAvailabilityRequest_Item{
+		{ProductId: a, Quantity: 5},
+		{ProductId: a, Quantity: 6},
+		{ProductId: b, Quantity: 1},
+		{ProductId: c, Quantity: 1},
+	}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []bool{true, false, false, false}
+	for i, item := range resp.GetItems() {
+		if item.GetAvailable() != expected[i] {
+			t.Errorf("Expected available %t for item %d, got: %v", expected[i], i, item)
+		}
+	}
+	if resp.GetItems()[3].GetFound() {
+		t.Error("Expected an unknown product not to be found")
+	}
+
+	for name, item := range map[string]*pb.CheckAvailabilityRequest_Item{
+		"zero quantity": {ProductId: a},
+		"malformed id":  {ProductId: "a", Quantity: 1},
+	} {
+		_, err = is.CheckAvailability(context.Background(), &pb.CheckAvailabilityRequest{Items: []*pb.CheckAvailabilityRequest_Item{item}})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument for a %s, got: %v", name, err)
+		}
+	}
+
+	fake.err = errors.New("connection refused")
+	_, err = is.CheckAvailability(context.Background(), &pb.CheckAvailabilityRequest{Items: []*pb.CheckAvailabilityRequest_Item{{ProductId: a, Quantity: 1}}})
+	if st := status.Convert(err); st.Code() != codes.Internal || st.Message() != "failed to check availability" {
+		t.Errorf("Expected the database error hidden, got: %v", err)
+	}
+}
+
+func (s *fakeService) ReserveStock(ctx context.Context, productID, orderID string, quantity int32, ttl time.Duration) (*repo.Reservation, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if _, ok := s.products[productID]; !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return &repo.Reservation{ID: uuid.NewString(), ProductID: productID, OrderID: orderID, Quantity: quantity,
+		Status: repo.ReservationActive, ExpiresAt: time.Now().Add(ttl)}, nil
+}
+
+func (s *fakeService) GetReservation(ctx context.Context, id string) (*repo.Reservation, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return nil, status.Errorf(codes.NotFound, "reservation not found: %s", id)
+}
+
+func TestReservations(t *testing.T) {
+	id := uuid.NewString()
+	fake := newFakeService(&pb.Product{Id: id})
+	is := NewInventoryService(fake)
+
+	resp, err := is.ReserveStock(context.Background(), &pb.ReserveStockRequest{ProductId: id, OrderId: "o1", Quantity: 2})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if r := resp.GetReservation(); r.GetStatus() != pb.Reservation_ACTIVE || r.GetQuantity() != 2 {
+		t.Errorf("Expected an active reservation of 2, got: %v", r)
+	}
+	if _, err := is.ReserveStock(context.Background(), &pb.ReserveStockRequest{ProductId: uuid.NewString(), Quantity: 2}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound for an unknown product, got: %v", err)
+	}
+	if _, err := is.ReserveStock(context.Background(), &pb.ReserveStockRequest{ProductId: "1", Quantity: 2}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for a malformed product id, got: %v", err)
+	}
+	if _, err := is.GetReservation(context.Background(), &pb.ReservationRequest{Id: uuid.NewString()}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound for an unknown reservation, got: %v", err)
+	}
+	for name, call := range map[string]func(context.Context, *pb.ReservationRequest) (*pb.ReservationResponse, error){
+		"ConfirmReservation": is.ConfirmReservation,
+		"ReleaseReservation": is.ReleaseReservation,
+		"GetReservation":     is.GetReservation,
+	} {
+		if _, err := call(context.Background(), &pb.ReservationRequest{Id: "r1"}); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument for a malformed id of %s, got: %v", name, err)
+		}
+	}
+
+	fake.err = errors.New("connection refused")
+	_, err = is.GetReservation(context.Background(), &pb.ReservationRequest{Id: uuid.NewString()})
+	if st := status.Convert(err); st.Code() != codes.Internal || st.Message() != "failed to get reservation" {
+		t.Errorf("Expected the database error hidden, got: %v", err)
+	}
+}
+
+func TestGetServerInfo(t *testing.T) {
+	info, err := NewInventoryService(newFakeService()).GetServerInfo(context.Background(), &pb.GetServerInfoRequest{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if info.GetVersion() == "" || info.GetSchemaVersion() == "" {
+		t.Errorf("Expected a version and schema version, got: %v", info)
+	}
+}
+
+func TestAdjustStock(t *testing.T) {
+	fake := newFakeService()
+	is := NewInventoryService(fake)
+	id := uuid.NewString()
+
+	resp, err := is.AdjustStock(context.Background(), &pb.AdjustStockRequest{ProductId: id, Delta: -2, Reason: pb.AdjustStockRequest_DAMAGED, Reference: "RMA-17"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.GetQuantity() != 8 {
+		t.Errorf("Expected quantity 8, got: %d", resp.GetQuantity())
+	}
+	if len(fake.adjustments) != 1 || fake.adjustments[0] != [2]string{repo.ReasonDamaged, "RMA-17"} {
+		t.Errorf("Expected a damaged movement, got: %v", fake.adjustments)
+	}
+
+	for _, req := range []*pb.AdjustStockRequest{
+		{ProductId: id, Delta: 1},
+		{ProductId: "1", Delta: 1, Reason: pb.AdjustStockRequest_RECEIVED},
+	} {
+		if _, err := is.AdjustStock(context.Background(), req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument for %v, got: %v", req, err)
+		}
+	}
+
+	fake.err = errors.New("connection refused")
+	_, err = is.AdjustStock(context.Background(), &pb.AdjustStockRequest{ProductId: id, Delta: 1, Reason: pb.AdjustStockRequest_RECEIVED})
+	if st := status.Convert(err); st.Code() != codes.Internal || st.Message() != "failed to adjust stock" {
+		t.Errorf("Expected the database error hidden, got: %v", err)
+	}
+}
+
+func (s *fakeService) DecrementQuantity(ctx context.Context, id string, delta int32) (int32, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	if _, ok := s.products[id]; !ok {
+		return 0, pgx.ErrNoRows
+	}
+	return 10 - delta, nil
+}
+
+func TestPurchaseProduct(t *testing.T) {
+	id := uuid.NewString()
+	fake := newFakeService(&pb.Product{Id: id})
+	is := NewInventoryService(fake)
+
+	resp, err := is.PurchaseProduct(context.Background(), &pb.PurchaseRequest{ProductId: id, Quantity: 3})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.GetRemainingQuantity() != 7 {
+		t.Errorf("Expected 7 remaining, got: %d", resp.GetRemainingQuantity())
+	}
+
+	if _, err := is.PurchaseProduct(context.Background(), &pb.PurchaseRequest{ProductId: "1", Quantity: 3}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for a malformed id, got: %v", err)
+	}
+	if _, err := is.PurchaseProduct(context.Background(), &pb.PurchaseRequest{ProductId: uuid.NewString(), Quantity: 3}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound for an unknown product, got: %v", err)
+	}
+
+	fake.err = errors.New("connection refused")
+	_, err = is.PurchaseProduct(context.Background(), &pb.PurchaseRequest{ProductId: id, Quantity: 3})
+	if st := status.Convert(err); st.Code() != codes.Internal || st.Message() != "failed to purchase product" {
+		t.Errorf("Expected the database error hidden, got: %v", err)
+	}
+}
+
+// exportLine is what the fake Export writes per product: long enough
+// for the export to take several chunks.
+var exportLine = bytes.Repeat([]byte("x"), 40000)
+
+func (s *fakeService) Export(ctx context.Context, filter repo.ListFilter, format services.ExportFormat, w io.Writer) (int64, error) {
+	s.filter = filter
+	for range s.products {
+		if _, err := w.Write(exportLine); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(s.products)), s.err
+}
+
+// exportStream collects the chunks of ExportProducts.
+type exportStream struct {
+	grpc.ServerStream
+	chunks [][]byte
+}
+
+func (s *exportStream) Context() context.Context { return context.Background() }
+
+func (s *exportStream) Send(chunk *pb.ExportProductsChunk) error {
+	s.chunks = append(s.chunks, bytes.Clone(chunk.GetData()))
+	return nil
+}
+
+func TestExportProducts(t *testing.T) {
+	fake := newFakeService(&pb.Product{Id: "1"}, &pb.Product{Id: "2"}, &pb.Product{Id: "3"})
+	is := NewInventoryService(fake)
+
+	stream := &exportStream{}
+	err := is.ExportProducts(&pb.ExportProductsRequest{Filter: &pb.ListRequest{Tags: []string{"sale"}}}, stream)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(stream.chunks) != 2 || len(stream.chunks[0]) != exportChunkSize {
+		t.Errorf("Expected a full chunk and the rest, got %d chunks", len(stream.chunks))
+	}
+	if got := bytes.Join(stream.chunks, nil); !bytes.Equal(got, bytes.Repeat(exportLine, 3)) {
+		t.Errorf("Expected the exported file, got %d bytes", len(got))
+	}
+	if fake.filter.Available == nil || !*fake.filter.Available || len(fake.filter.Tags) != 1 {
+		t.Errorf("Expected the filter of ListProducts, got: %+v", fake.filter)
+	}
+
+	err = is.ExportProducts(&pb.ExportProductsRequest{Format: 7}, &exportStream{})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an unknown format, got: %v", err)
+	}
+}
+
+func (s *fakeService) MergeTags(ctx context.Context, name string, from []string) (*repo.Tag, int64, error) {
+	if s.err != nil {
+		return nil, 0, s.err
+	}
+	return &repo.Tag{Name: name, Registered: true, Aliases: from, Products: 3}, 2, nil
+}
+
+func TestMergeTags(t *testing.T) {
+	fake := newFakeService()
+	is := NewInventoryService(fake)
+
+	resp, err := is.MergeTags(context.Background(), &pb.MergeTagsRequest{Name: "Electronics", From: []string{"electronic"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	tag := resp.GetTag()
+	if tag.GetName() != "Electronics" || !tag.GetRegistered() || len(tag.GetAliases()) != 1 || tag.GetProductCount() != 3 {
+		t.Errorf("Expected the merged tag, got: %v", tag)
+	}
+	if resp.GetRetaggedProducts() != 2 {
+		t.Errorf("Expected 2 retagged products, got: %d", resp.GetRetaggedProducts())
+	}
+
+	fake.err = status.Error(codes.InvalidArgument, "invalid tag")
+	if _, err := is.MergeTags(context.Background(), &pb.MergeTagsRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument, got: %v", err)
+	}
+
+	fake.err = errors.New("deadlock detected")
+	_, err = is.MergeTags(context.Background(), &pb.MergeTagsRequest{Name: "Electronics"})
+	if st := status.Convert(err); st.Code() != codes.Internal || st.Message() != "failed to merge tags" {
+		t.Errorf("Expected the database error hidden, got: %v", err)
+	}
+}
+
+func (s *fakeService) ListTags(ctx context.Context) ([]*repo.Tag, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return []*repo.Tag{{Name: "sale", Products: 4}}, nil
+}
+
+func TestListTags(t *testing.T) {
+	fake := newFakeService()
+	is := NewInventoryService(fake)
+
+	resp, err := is.ListTags(context.Background(), &pb.ListTagsRequest{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(resp.GetTags()) != 1 || resp.GetTags()[0].GetName() != "sale" || resp.GetTags()[0].GetProductCount() != 4 {
+		t.Errorf("Expected tag sale of 4 products, got: %v", resp.GetTags())
+	}
+
+	fake.err = errors.New("connection refused")
+	_, err = is.ListTags(context.Background(), &pb.ListTagsRequest{})
+	if st := status.Convert(err); st.Code() != codes.Internal || st.Message() != "failed to list tags" {
+		t.Errorf("Expected the database error hidden, got: %v", err)
+	}
+}
+
+func (s *fakeService) ListAuditEntries(ctx context.Context, filter repo.AuditFilter, pageToken string, pageSize int32) ([]*repo.AuditEntry, string, error) {
+	if pageToken == "bad" {
+		return nil, "", repo.ErrInvalidCursor
+	}
+	actor := "alice"
+	return []*repo.AuditEntry{{
+		ID:        2,
+		ProductID: filter.ProductID,
+		Operation: "update",
+		Actor:     &actor,
+		Changes: map[string]repo.FieldChange{
+			"quantity": {Before: []byte("1"), After: []byte("2")},
+			"name":     {Before: []byte(`"tv"`), After: []byte(`"TV"`)},
+		},
+	}}, "next", nil
+}
+
+func (s *fakeService) ListReorderSuggestions(ctx context.Context, pageToken string, pageSize int32) ([]*repo.ReorderSuggestion, string, error) {
+	if pageToken == "bad" {
+		return nil, "", repo.ErrInvalidCursor
+	}
+	return []*repo.ReorderSuggestion{{
+		ProductID:         "p1",
+		Quantity:          2,
+		ReorderPoint:      5,
+		ReorderQuantity:   10,
+		DailyOutflow:      1.5,
+		DaysOfCover:       4.0 / 3,
+		SuggestedQuantity: 24,
+		ComputedAt:        time.Unix(1, 0),
+	}}, "next", nil
+}
+
+func TestListReorderSuggestions(t *testing.T) {
+	is := NewInventoryService(newFakeService())
+
+	resp, err := is.ListReorderSuggestions(context.Background(), &pb.ListReorderSuggestionsRequest{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(resp.GetSuggestions()) != 1 || resp.GetNextPageToken() != "next" {
+		t.Fatalf("Expected one suggestion and a next page, got: %v", resp)
+	}
+	if s := resp.GetSuggestions()[0]; s.GetProductId() != "p1" || s.GetSuggestedQuantity() != 24 || s.GetComputedAt().AsTime() != time.Unix(1, 0).UTC() {
+		t.Errorf("Expected the suggestion of p1, got: %v", s)
+	}
+
+	for name, req := range map[string]*pb.ListReorderSuggestionsRequest{
+		"page size":  {PageSize: -1},
+		"page token": {PageToken: "bad"},
+	} {
+		if _, err := is.ListReorderSuggestions(context.Background(), req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument for the %s, got: %v", name, err)
+		}
+	}
+}
+
+func (s *fakeService) ReceiveLot(ctx context.Context, l *repo.Lot, reference string) (*repo.Lot, int32, error) {
+	if s.err != nil {
+		return nil, 0, s.err
+	}
+	lot := *l
+	lot.ID = "l1"
+	return &lot, l.Quantity + 3, nil
+}
+
+func (s *fakeService) ListExpiringLots(ctx context.Context, days int32, productID, pageToken string, pageSize int32) ([]*repo.Lot, string, error) {
+	if pageToken == "bad" {
+		return nil, "", repo.ErrInvalidCursor
+	}
+	return []*repo.Lot{{
+		ID:        "l1",
+		ProductID: productID,
+		Number:    "B-7",
+		ExpiresOn: time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC),
+		Quantity:  12,
+	}}, "", nil
+}
+
+func TestReceiveLot(t *testing.T) {
+	fake := newFakeService()
+	is := NewInventoryService(fake)
+	id := uuid.NewString()
+
+	resp, err := is.ReceiveLot(context.Background(), &pb.ReceiveLotRequest{
+		ProductId: id, LotNumber: "B-7", ExpiryDate: "2026-03-31", Quantity: 12,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if lot := resp.GetLot(); lot.GetId() != "l1" || lot.GetExpiryDate() != "2026-03-31" || resp.GetQuantity() != 15 {
+		t.Errorf("Expected lot l1 expiring on 2026-03-31 and 15 in stock, got: %v", resp)
+	}
+
+	for name, req := range map[string]*pb.ReceiveLotRequest{
+		"product id":  {ProductId: "not-a-uuid", ExpiryDate: "2026-03-31"},
+		"expiry date": {ProductId: id, ExpiryDate: "31.03.2026"},
+	} {
+		if _, err := is.ReceiveLot(context.Background(), req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument for the %s, got: %v", name, err)
+		}
+	}
+
+	fake.err = errors.New("connection refused")
+	_, err = is.ReceiveLot(context.Background(), &pb.ReceiveLotRequest{ProductId: id, LotNumber: "B-7", ExpiryDate: "2026-03-31", Quantity: 12})
+	if st := status.Convert(err); st.Code() != codes.Internal || st.Message() != "failed to receive lot" {
+		t.Errorf("Expected the database error hidden, got: %v", err)
+	}
+}
+
+func TestListExpiringLots(t *testing.T) {
+	is := NewInventoryService(newFakeService())
+	id := uuid.NewString()
+
+	resp, err := is.ListExpiringLots(context.Background(), &pb.ListExpiringLotsRequest{Days: 30, ProductId: id})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(resp.GetLots()) != 1 || resp.GetLots()[0].GetProductId() != id || resp.GetLots()[0].GetExpiryDate() != "2026-03-31" {
+		t.Errorf("Expected the lot of the product, got: %v", resp)
+	}
+
+	for name, req := range map[string]*pb.ListExpiringLotsRequest{
+		"days":       {Days: -1},
+		"page size":  {PageSize: -1},
+		"product id": {ProductId: "not-a-uuid"},
+		"page token": {PageToken: "bad"},
+	} {
+		if _, err := is.ListExpiringLots(context.Background(), req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument for the %s, got: %v", name, err)
+		}
+	}
+}
+
+func TestListAuditEntries(t *testing.T) {
+	is := NewInventoryService(newFakeService())
+	id := uuid.NewString()
+
+	resp, err := is.ListAuditEntries(context.Background(), &pb.ListAuditEntriesRequest{ProductId: id})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(resp.GetEntries()) != 1 || resp.GetNextPageToken() != "next" {
+		t.Fatalf("Expected one entry and a next page, got: %v", resp)
+	}
+	entry := resp.GetEntries()[0]
+	if entry.GetOperation() != pb.AuditEntry_UPDATE || entry.GetActor() != "alice" || entry.GetRpc() != "" {
+		t.Errorf("Expected an update by alice, got: %v", entry)
+	}
+	changes := entry.GetChanges()
+	if len(changes) != 2 || changes[0].GetField() != "name" || changes[0].GetAfter() != `"TV"` {
+		t.Errorf("Expected changes ordered by field, got: %v", changes)
+	}
+
+	for name, req := range map[string]*pb.ListAuditEntriesRequest{
+		"page size":  {PageSize: -1},
+		"product id": {ProductId: "not-a-uuid"},
+		"page token": {PageToken: "bad"},
+		"time range": {StartTime: timestamppb.New(time.Unix(2, 0)), EndTime: timestamppb.New(time.Unix(1, 0))},
+	} {
+		if _, err := is.ListAuditEntries(context.Background(), req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument for the %s, got: %v", name, err)
+		}
+	}
+}
+
+func (s *fakeService) GetBySKU(ctx context.Context, sku string) (*pb.Product, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	for _, p := range s.products {
+		if p.GetSku() == sku {
+			return p, nil
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "product not found: %s", sku)
+}
+
+func TestGetProductBySku(t *testing.T) {
+	fake := newFakeService(&pb.Product{Id: uuid.NewString(), Sku: "LAP-1"})
+	is := NewInventoryService(fake)
+
+	resp, err := is.GetProductBySku(context.Background(), &pb.GetBySkuRequest{Sku: "LAP-1"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.GetProduct().GetSku() != "LAP-1" {
+		t.Errorf("Expected product LAP-1, got: %v", resp.GetProduct())
+	}
+
+	if _, err := is.GetProductBySku(context.Background(), &pb.GetBySkuRequest{Sku: "missing"}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound, got: %v", err)
+	}
+
+	fake.err = errors.New("connection refused")
+	_, err = is.GetProductBySku(context.Background(), &pb.GetBySkuRequest{Sku: "LAP-1"})
+	if st := status.Convert(err); st.Code() != codes.Internal || st.Message() != "failed to get product" {
+		t.Errorf("Expected the database error hidden, got: %v", err)
+	}
+}
+
+func (s *fakeService) GetByBarcode(ctx context.Context, barcode string) (*pb.Product, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	for _, p := range s.products {
+		if p.GetBarcode() == barcode {
+			return p, nil
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "product not found: %s", barcode)
+}
+
+func TestGetProductByBarcode(t *testing.T) {
+	fake := newFakeService(&pb.Product{Id: uuid.NewString(), Barcode: "4006381333931"})
+	is := NewInventoryService(fake)
+
+	resp, err := is.GetProductByBarcode(context.Background(), &pb.GetByBarcodeRequest{Barcode: "4006381333931"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.GetProduct().GetBarcode() != "4006381333931" {
+		t.Errorf("Expected product 4006381333931, got: %v", resp.GetProduct())
+	}
+
+	if _, err := is.GetProductByBarcode(context.Background(), &pb.GetByBarcodeRequest{Barcode: "0000000000000"}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound, got: %v", err)
+	}
+
+	fake.err = errors.New("connection refused")
+	_, err = is.GetProductByBarcode(context.Background(), &pb.GetByBarcodeRequest{Barcode: "4006381333931"})
+	if st := status.Convert(err); st.Code() != codes.Internal || st.Message() != "failed to get product" {
+		t.Errorf("Expected the database error hidden, got: %v", err)
+	}
+}
+
+func (s *fakeService) CreateVariant(ctx context.Context, v *pb.Variant) (*pb.Variant, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	created := proto.Clone(v).(*pb.Variant)
+	created.Id = uuid.NewString()
+	return created, nil
+}
+
+func (s *fakeService) GetVariant(ctx context.Context, id string) (*pb.Variant, error) {
+	return nil, s.err
+}
+
+func (s *fakeService) UpdateVariant(ctx context.Context, v *pb.Variant, mask *fieldmaskpb.FieldMask) (*pb.Variant, error) {
+	return nil, s.err
+}
+
+func (s *fakeService) DeleteVariant(ctx context.Context, id string) error {
+	return s.err
+}
+
+func (s *fakeService) ListVariants(ctx context.Context, id string) ([]*pb.Variant, error) {
+	return nil, s.err
+}
+
+func TestVariants(t *testing.T) {
+	fake := newFakeService()
+	is := NewInventoryService(fake)
+	ctx := context.Background()
+
+	resp, err := is.CreateVariant(ctx, &pb.CreateVariantRequest{Variant: &pb.Variant{Sku: "TS-M"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.GetVariant().GetId() == "" || resp.GetVariant().GetSku() != "TS-M" {
+		t.Errorf("Expected the created variant, got: %v", resp.GetVariant())
+	}
+
+	_, err = is.CreateVariant(ctx, &pb.CreateVariantRequest{})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for a request without a variant, got: %v", err)
+	}
+
+	fake.err = status.Error(codes.NotFound, "variant not found")
+	if _, err := is.GetVariant(ctx, &pb.GetVariantRequest{Id: uuid.NewString()}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound, got: %v", err)
+	}
+
+	fake.err = errors.New("connection refused")
+	for name, call := range map[string]func() error{
+		"failed to create variant": func() error {
+			_, err := is.CreateVariant(ctx, &pb.CreateVariantRequest{Variant: &pb.Variant{Sku: "TS-M"}})
+			return err
+		},
+		"failed to get variant": func() error {
+			_, err := is.GetVariant(ctx, &pb.GetVariantRequest{Id: uuid.NewString()})
+			return err
+		},
+		"failed to update variant": func() error {
+			_, err := is.UpdateVariant(ctx, &pb.UpdateVariantRequest{Variant: &pb.Variant{Id: uuid.NewString()}})
+			return err
+		},
+		"failed to delete variant": func() error {
+			_, err := is.DeleteVariant(ctx, &pb.DeleteVariantRequest{Id: uuid.NewString()})
+			return err
+		},
+		"failed to list variants": func() error {
+			_, err := is.ListVariants(ctx, &pb.ListVariantsRequest{ProductId: uuid.NewString()})
+			return err
+		},
+	} {
+		if st := status.Convert(call()); st.Code() != codes.Internal || st.Message() != name {
+			t.Errorf("Expected %q, got: %v", name, st.Err())
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package rpc
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/andro-kes/inventory_service/internal/repo"
+	"github.com/andro-kes/inventory_service/internal/services"
+	pb "github.com/andro-kes/inventory_service/proto"
+	"golang.org/x/text/language"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// ProductService is the business logic the handlers call, implemented by
+// *services.ProductService and its cached decorator,
+// *services.CachedService. Tests pass a fake instead.
+type ProductService interface {
+	CreateIdempotent(ctx context.Context, key string, p *pb.Product) (*pb.Product, error)
+	Get(ctx context.Context, id string) (*pb.Product, error)
+	GetBySKU(ctx context.Context, sku string) (*pb.Product, error)
+	GetByBarcode(ctx context.Context, barcode string) (*pb.Product, error)
+	Update(ctx context.Context, p *pb.Product, mask *fieldmaskpb.FieldMask) (*pb.Product, error)
+	BatchUpdate(ctx context.Context, updates []services.ProductUpdate) ([]services.UpdateResult, bool, error)
+	ImportCSV(ctx context.Context, r io.Reader, dryRun bool) (*services.ImportReport, error)
+	Export(ctx context.Context, filter repo.ListFilter, format services.ExportFormat, w io.Writer) (int64, error)
+	Delete(ctx context.Context, id string) error
+	DeleteWhere(ctx context.Context, filter repo.ListFilter) (int64, error)
+	ListAfter(ctx context.Context, cursor string, pageSize int32, filter repo.ListFilter, orderBy string) ([]*pb.Product, string, error)
+	Count(ctx context.Context, filter repo.ListFilter) (int64, error)
+	Localize(ctx context.Context, prefs []language.Tag, products ...*pb.Product) error
+	ListTags(ctx context.Context) ([]*repo.Tag, error)
+	MergeTags(ctx context.Context, name string, from []string) (*repo.Tag, int64, error)
+	ListAuditEntries(ctx context.Context, filter repo.AuditFilter, pageToken string, pageSize int32) ([]*repo.AuditEntry, string, error)
+
+	DecrementQuantity(ctx context.Context, id string, delta int32) (int32, error)
+	AdjustStock(ctx context.Context, id string, delta int32, reason, reference string) (int32, error)
+	ListReorderSuggestions(ctx context.Context, pageToken string, pageSize int32) ([]*repo.ReorderSuggestion, string, error)
+	ReceiveLot(ctx context.Context, l *repo.Lot, reference string) (*repo.Lot, int32, error)
+	ListExpiringLots(ctx context.Context, days int32, productID, pageToken string, pageSize int32) ([]*repo.Lot, string, error)
+	StockOf(ctx context.Context, ids []string) (map[string]repo.Stock, error)
+	ReserveStock(ctx context.Context, productID, orderID string, quantity int32, ttl time.Duration) (*repo.Reservation, error)
+	ConfirmReservation(ctx context.Context, id string) (*repo.Reservation, error)
+	ReleaseReservation(ctx context.Context, id string) (*repo.Reservation, error)
+	GetReservation(ctx context.Context, id string) (*repo.Reservation, error)
+
+	CreateVariant(ctx context.Context, v *pb.Variant) (*pb.Variant, error)
+	GetVariant(ctx context.Context, id string) (*pb.Variant, error)
+	UpdateVariant(ctx context.Context, v *pb.Variant, mask *fieldmaskpb.FieldMask) (*pb.Variant, error)
+	DeleteVariant(ctx context.Context, id string) error
+	ListVariants(ctx context.Context, id string) ([]*pb.Variant, error)
+	ExpandVariants(ctx context.Context, products ...*pb.Product) error
+	ConvertPrices(ctx context.Context, currency string, products ...*pb.Product) error
+}
+
+var (
+	_ ProductService = (*services.ProductService)(nil)
+	_ ProductService = (*services.CachedService)(nil)
+)
@@ -0,0 +1,68 @@
+package rpc
+
+import (
+	"sync"
+	"time"
+)
+
+// GracefulStopper is the part of grpc.Server Drain stops.
+type GracefulStopper interface {
+	GracefulStop()
+	Stop()
+}
+
+// Drain stops s accepting connections and RPCs and waits up to timeout for
+// the in-flight RPCs to finish, then cancels the rest with Stop. It
+// reports whether all RPCs finished in time.
+func Drain(s GracefulStopper, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(done)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		s.Stop()
+		<-done
+		return false
+	}
+}
+
+// Workers tracks background goroutines, e.g. the change listener, so that
+// shutdown can wait for them after cancelling their context.
+type Workers struct {
+	wg sync.WaitGroup
+}
+
+// Go runs f in a new goroutine tracked by w.
+func (w *Workers) Go(f func()) {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		f()
+	}()
+}
+
+// Wait waits up to timeout for the goroutines of w to return and reports
+// whether they all did.
+func (w *Workers) Wait(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
@@ -0,0 +1,62 @@
+package rpc
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stopper is a server whose GracefulStop waits for release or Stop.
+type stopper struct {
+	release chan struct{}
+	stopped atomic.Bool
+}
+
+func newStopper() *stopper {
+	return &stopper{release: make(chan struct{})}
+}
+
+func (s *stopper) GracefulStop() {
+	<-s.release
+}
+
+func (s *stopper) Stop() {
+	s.stopped.Store(true)
+	close(s.release)
+}
+
+func TestDrain(t *testing.T) {
+	s := newStopper()
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(s.release)
+	}()
+	if !Drain(s, time.Minute) {
+		t.Error("Expected the in-flight RPCs to finish")
+	}
+	if s.stopped.Load() {
+		t.Error("Expected no forced stop")
+	}
+
+	s = newStopper()
+	if Drain(s, 10*time.Millisecond) {
+		t.Error("Expected the drain to time out")
+	}
+	if !s.stopped.Load() {
+		t.Error("Expected a forced stop after the timeout")
+	}
+}
+
+func TestWorkersWait(t *testing.T) {
+	var w Workers
+	release := make(chan struct{})
+	w.Go(func() { <-release })
+
+	if w.Wait(10 * time.Millisecond) {
+		t.Error("Expected the wait to time out with a running worker")
+	}
+	close(release)
+	if !w.Wait(time.Minute) {
+		t.Error("Expected the workers to finish")
+	}
+}
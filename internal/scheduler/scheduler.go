@@ -0,0 +1,205 @@
+// Package scheduler runs the periodic maintenance jobs of the service,
+// such as purging deleted products or expiring reservations, and exports
+// their runs to Prometheus.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Results of the runs, the result label of scheduler_job_runs_total.
+const (
+	ResultSuccess = "success"
+	ResultError   = "error"
+	// ResultSkipped counts the runs that were due while the previous run
+	// of the job was still going.
+	ResultSkipped = "skipped"
+)
+
+// Job is a function run every Interval.
+type Job struct {
+	// Name identifies the job in errors and metrics.
+	Name     string
+	Interval time.Duration
+	// Jitter delays every run by a random duration up to Jitter, so that
+	// the jobs of replicas started together do not hit the database at
+	// once.
+	Jitter time.Duration
+	// Timeout cancels the context of a run after Timeout; zero runs it
+	// until the scheduler stops.
+	Timeout time.Duration
+	Run     func(ctx context.Context) error
+}
+
+type entry struct {
+	Job
+	busy atomic.Bool
+}
+
+// Scheduler runs jobs at their intervals, each run in its own goroutine.
+// A run due while the previous run of its job is still going is skipped,
+// so slow jobs never overlap themselves. Scheduler is a
+// prometheus.Collector of the runs of its jobs.
+type Scheduler struct {
+	// OnError is called with the failed runs; errors of runs cancelled
+	// because the scheduler stopped are not reported.
+	OnError func(job string, err error)
+
+	jobs        []*entry
+	runs        *prometheus.CounterVec
+	durations   *prometheus.HistogramVec
+	running     *prometheus.GaugeVec
+	lastSuccess *prometheus.GaugeVec
+}
+
+// New returns a Scheduler without jobs.
+func New() *Scheduler {
+	return &Scheduler{
+		runs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "scheduler_job_runs_total",
+			Help: "Runs of the scheduled jobs by result: success, error or skipped.",
+		}, []string{"job", "result"}),
+		durations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "scheduler_job_duration_seconds",
+			Help:    "Duration of the runs of the scheduled jobs.",
+			Buckets: prometheus.ExponentialBuckets(0.01, 4, 8),
+		}, []string{"job"}),
+		running: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "scheduler_job_running",
+			Help: "Whether a run of the scheduled job is going.",
+		}, []string{"job"}),
+		lastSuccess: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "scheduler_job_last_success_timestamp_seconds",
+			Help: "Unix time of the last successful run of the scheduled job.",
+		}, []string{"job"}),
+	}
+}
+
+// Add registers job. Jobs are added before Run; names must be unique.
+func (s *Scheduler) Add(job Job) error {
+	if job.Name == "" || job.Run == nil {
+		return errors.New("scheduler: job needs a name and a run function")
+	}
+	if job.Interval <= 0 {
+		return fmt.Errorf("scheduler: job %s: interval must be positive", job.Name)
+	}
+	if job.Jitter < 0 || job.Timeout < 0 {
+		return fmt.Errorf("scheduler: job %s: jitter and timeout must not be negative", job.Name)
+	}
+	for _, e := range s.jobs {
+		if e.Name == job.Name {
+			return fmt.Errorf("scheduler: job %s added twice", job.Name)
+		}
+	}
+	s.jobs = append(s.jobs, &entry{Job: job})
+
+	// Export the job before its first run.
+	for _, result := range []string{ResultSuccess, ResultError, ResultSkipped} {
+		s.runs.WithLabelValues(job.Name, result)
+	}
+	s.running.WithLabelValues(job.Name)
+	return nil
+}
+
+// Run runs the jobs until ctx is done, then waits for the runs going,
+// which see ctx cancelled, to return. The first run of a job is due one
+// interval after Run is called.
+func (s *Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, e := range s.jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.loop(ctx, e, &wg)
+		}()
+	}
+	wg.Wait()
+}
+
+// loop starts the runs of e until ctx is done, tracking them in wg.
+func (s *Scheduler) loop(ctx context.Context, e *entry, wg *sync.WaitGroup) {
+	timer := time.NewTimer(e.next())
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		timer.Reset(e.next())
+
+		if !e.busy.CompareAndSwap(false, true) {
+			s.runs.WithLabelValues(e.Name, ResultSkipped).Inc()
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer e.busy.Store(false)
+			s.run(ctx, e)
+		}()
+	}
+}
+
+// next returns the delay before the next run of e.
+func (e *entry) next() time.Duration {
+	if e.Jitter <= 0 {
+		return e.Interval
+	}
+	return e.Interval + rand.N(e.Jitter)
+}
+
+// run runs e once and records the result.
+func (s *Scheduler) run(ctx context.Context, e *entry) {
+	runCtx := ctx
+	if e.Timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, e.Timeout)
+		defer cancel()
+	}
+
+	running := s.running.WithLabelValues(e.Name)
+	running.Set(1)
+	defer running.Set(0)
+
+	start := time.Now()
+	err := e.Run(runCtx)
+	s.durations.WithLabelValues(e.Name).Observe(time.Since(start).Seconds())
+	switch {
+	case err == nil:
+		s.runs.WithLabelValues(e.Name, ResultSuccess).Inc()
+		s.lastSuccess.WithLabelValues(e.Name).SetToCurrentTime()
+	case ctx.Err() != nil:
+		// The scheduler stopped; the run is not a failure of the job.
+	default:
+		s.runs.WithLabelValues(e.Name, ResultError).Inc()
+		if s.OnError != nil {
+			s.OnError(e.Name, err)
+		}
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (s *Scheduler) Describe(ch chan<- *prometheus.Desc) {
+	s.runs.Describe(ch)
+	s.durations.Describe(ch)
+	s.running.Describe(ch)
+	s.lastSuccess.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (s *Scheduler) Collect(ch chan<- prometheus.Metric) {
+	s.runs.Collect(ch)
+	s.durations.Collect(ch)
+	s.running.Collect(ch)
+	s.lastSuccess.Collect(ch)
+}
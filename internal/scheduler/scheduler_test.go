@@ -0,0 +1,77 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduler(t *testing.T) {
+	s := New()
+	var mu sync.Mutex
+	var failed []string
+	s.OnError = func(job string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		failed = append(failed, job)
+	}
+
+	var quick atomic.Int32
+	require.NoError(t, s.Add(Job{Name: "quick", Interval: time.Millisecond, Jitter: time.Millisecond, Run: func(ctx context.Context) error {
+		quick.Add(1)
+		return nil
+	}}))
+	// slow outlives its interval: the runs due meanwhile are skipped.
+	var slow, slowRunning atomic.Int32
+	require.NoError(t, s.Add(Job{Name: "slow", Interval: time.Millisecond, Run: func(ctx context.Context) error {
+		defer slowRunning.Add(-1)
+		assert.Equal(t, int32(1), slowRunning.Add(1))
+		slow.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	}}))
+	require.NoError(t, s.Add(Job{Name: "failing", Interval: time.Millisecond, Timeout: time.Millisecond, Run: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}))
+	// Runs cancelled by the stop are neither failures nor successes.
+	require.NoError(t, s.Add(Job{Name: "blocked", Interval: time.Millisecond, Run: func(ctx context.Context) error {
+		<-ctx.Done()
+		return errors.New("stopped")
+	}}))
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+	s.Run(ctx)
+
+	assert.Greater(t, quick.Load(), int32(5))
+	assert.Equal(t, float64(quick.Load()), testutil.ToFloat64(s.runs.WithLabelValues("quick", ResultSuccess)))
+	assert.Equal(t, float64(slow.Load()), testutil.ToFloat64(s.runs.WithLabelValues("slow", ResultSuccess)))
+	assert.Greater(t, testutil.ToFloat64(s.runs.WithLabelValues("slow", ResultSkipped)), 0.0)
+	assert.Greater(t, testutil.ToFloat64(s.runs.WithLabelValues("failing", ResultError)), 0.0)
+	assert.Zero(t, testutil.ToFloat64(s.runs.WithLabelValues("blocked", ResultError)))
+	assert.Zero(t, testutil.ToFloat64(s.runs.WithLabelValues("blocked", ResultSuccess)))
+	assert.Zero(t, testutil.ToFloat64(s.running.WithLabelValues("blocked")))
+	assert.NotContains(t, failed, "blocked")
+	assert.Contains(t, failed, "failing")
+}
+
+func TestAdd(t *testing.T) {
+	s := New()
+	run := func(ctx context.Context) error { return nil }
+	require.NoError(t, s.Add(Job{Name: "purge", Interval: time.Hour, Run: run}))
+	assert.Error(t, s.Add(Job{Name: "purge", Interval: time.Hour, Run: run}))
+	assert.Error(t, s.Add(Job{Name: "snapshot", Run: run}))
+	assert.Error(t, s.Add(Job{Name: "snapshot", Interval: time.Hour, Jitter: -time.Second, Run: run}))
+	assert.Error(t, s.Add(Job{Interval: time.Hour, Run: run}))
+
+	// Jobs are exported before they run.
+	assert.Equal(t, 4, testutil.CollectAndCount(s, "scheduler_job_runs_total", "scheduler_job_running"))
+}
@@ -0,0 +1,536 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andro-kes/inventory_service/internal/repo"
+	pb "github.com/andro-kes/inventory_service/proto"
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// cacheStats counts the hits, misses and store errors of CachedService.
+// It is published through expvar as service_cache.
+var cacheStats = expvar.NewMap("service_cache")
+
+// ErrCacheMiss is returned by CacheStore.Get for absent keys.
+var ErrCacheMiss = errors.New("cache miss")
+
+// CacheStore is the backend of CachedService: a MemoryStore per replica
+// or a RedisStore shared by them.
+type CacheStore interface {
+	// Get returns the value of key, or ErrCacheMiss.
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	// DeletePrefix deletes the keys starting with prefix.
+	DeletePrefix(ctx context.Context, prefix string) error
+	// Incr increments the counter key, starting at zero, and returns its
+	// new value. Counters do not expire.
+	Incr(ctx context.Context, key string) (int64, error)
+}
+
+// Cache keys. Lists are keyed by the list generation, bumped by every
+// change, so that one increment drops all cached pages.
+const (
+	productKeyPrefix  = "product:"
+	listKeyPrefix     = "list:"
+	listGenerationKey = "list-generation"
+)
+
+// CachedService is a ProductService serving Get, ListAfter and Count from
+// a CacheStore. Its writes drop what they change once they succeed; the
+// writes of other replicas reach it through Invalidate. Any change of a
+// product drops all cached lists, so lists are cached briefly, for the
+// bursts of identical queries of catalog pages.
+//
+// Store errors never fail a call: reads fall through to the
+// ProductService and are counted in cacheStats. A read racing with a
+// write may cache the old version for up to the TTL.
+type CachedService struct {
+	*ProductService
+	Store CacheStore
+	// TTL bounds the age of cached products, ListTTL that of lists and
+	// counts.
+	TTL     time.Duration
+	ListTTL time.Duration
+}
+
+// NewCachedService returns ps cached in store, products for ttl and lists
+// for listTTL.
+func NewCachedService(ps *ProductService, store CacheStore, ttl, listTTL time.Duration) *CachedService {
+	return &CachedService{
+		ProductService: ps,
+		Store:          store,
+		TTL:            ttl,
+		ListTTL:        listTTL,
+	}
+}
+
+// Get caches whole products; reads with a read mask, or through
+// repo.WithoutCache, skip the cache.
+func (c *CachedService) Get(ctx context.Context, id string) (*pb.Product, error) {
+	if repo.Uncached(ctx) || repo.ReadMaskPaths(ctx) != nil {
+		return c.ProductService.Get(ctx, id)
+	}
+
+	key := productKeyPrefix + id
+	var p pb.Product
+	if c.load(ctx, key, &p) {
+		return &p, nil
+	}
+	loaded, err := c.ProductService.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	c.store(ctx, key, loaded, c.TTL)
+	return loaded, nil
+}
+
+// ListAfter caches pages by their query and the read mask of ctx.
+func (c *CachedService) ListAfter(ctx context.Context, cursor string, pageSize int32, filter repo.ListFilter, orderBy string) ([]*pb.Product, string, error) {
+	if repo.Uncached(ctx) {
+		return c.ProductService.ListAfter(ctx, cursor, pageSize, filter, orderBy)
+	}
+
+	key, ok := c.listKey(ctx, "list", cursor, pageSize, filter, orderBy, repo.ReadMaskPaths(ctx))
+	var page pb.ListResponse
+	if ok && c.load(ctx, key, &page) {
+		return page.GetProducts(), page.GetNextPageToken(), nil
+	}
+	products, next, err := c.ProductService.ListAfter(ctx, cursor, pageSize, filter, orderBy)
+	if err != nil {
+		return nil, "", err
+	}
+	if ok {
+		c.store(ctx, key, &pb.ListResponse{Products: products, NextPageToken: next}, c.ListTTL)
+	}
+	return products, next, nil
+}
+
+func (c *CachedService) Count(ctx context.Context, filter repo.ListFilter) (int64, error) {
+	if repo.Uncached(ctx) {
+		return c.ProductService.Count(ctx, filter)
+	}
+
+	key, ok := c.listKey(ctx, "count", filter)
+	if ok {
+		data, err := c.Store.Get(ctx, key)
+		if n, perr := strconv.ParseInt(string(data), 10, 64); err == nil && perr == nil {
+			cacheStats.Add("hits", 1)
+			return n, nil
+		}
+		c.miss(err)
+	}
+	n, err := c.ProductService.Count(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	if ok {
+		c.set(ctx, key, []byte(strconv.FormatInt(n, 10)), c.ListTTL)
+	}
+	return n, nil
+}
+
+// Invalidate drops what change makes stale, e.g. for the changes of a
+// repo.ChangeListener. An OpReset drops everything.
+func (c *CachedService) Invalidate(ctx context.Context, change repo.Change) {
+	switch {
+	case change.Op == repo.OpReset:
+		c.invalidateAll(ctx)
+	case change.ID != "":
+		c.invalidate(ctx, change.ID)
+	default:
+		c.invalidateLists(ctx)
+	}
+}
+
+func (c *CachedService) Create(ctx context.Context, p *pb.Product) (*pb.Product, error) {
+	created, err := c.ProductService.Create(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	c.invalidateLists(ctx)
+	return created, nil
+}
+
+func (c *CachedService) CreateIdempotent(ctx context.Context, key string, p *pb.Product) (*pb.Product, error) {
+	created, err := c.ProductService.CreateIdempotent(ctx, key, p)
+	if err != nil {
+		return nil, err
+	}
+	c.invalidateLists(ctx)
+	return created, nil
+}
+
+func (c *CachedService) CreateMany(ctx context.Context, products []*pb.Product) ([]*pb.Product, error) {
+	created, err := c.ProductService.CreateMany(ctx, products)
+	if err != nil {
+		return nil, err
+	}
+	c.invalidateLists(ctx)
+	return created, nil
+}
+
+func (c *CachedService) Import(ctx context.Context, products []*pb.Product, progress func(copied int64)) (int64, error) {
+	copied, err := c.ProductService.Import(ctx, products, progress)
+	if copied > 0 {
+		c.invalidateLists(ctx)
+	}
+	return copied, err
+}
+
+func (c *CachedService) ImportCSV(ctx context.Context, r io.Reader, dryRun bool) (*ImportReport, error) {
+	report, err := c.ProductService.ImportCSV(ctx, r, dryRun)
+	if report != nil && report.Imported > 0 {
+		// Existing products may be overwritten, so entries go too.
+		c.invalidateAll(ctx)
+	}
+	return report, err
+}
+
+func (c *CachedService) Update(ctx context.Context, p *pb.Product, mask *fieldmaskpb.FieldMask) (*pb.Product, error) {
+	updated, err := c.ProductService.Update(ctx, p, mask)
+	if err != nil {
+		return nil, err
+	}
+	c.invalidate(ctx, p.GetId())
+	return updated, nil
+}
+
+func (c *CachedService) BatchUpdate(ctx context.Context, updates []ProductUpdate) ([]UpdateResult, bool, error) {
+	results, applied, err := c.ProductService.BatchUpdate(ctx, updates)
+	if applied {
+		for _, u := range updates {
+			c.invalidate(ctx, u.Product.GetId())
+		}
+	}
+	return results, applied, err
+}
+
+func (c *CachedService) UpsertBySKU(ctx context.Context, p *pb.Product) (*pb.Product, error) {
+	stored, err := c.ProductService.UpsertBySKU(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	c.invalidate(ctx, stored.GetId())
+	return stored, nil
+}
+
+func (c *CachedService) Delete(ctx context.Context, id string) error {
+	if err := c.ProductService.Delete(ctx, id); err != nil {
+		return err
+	}
+	c.invalidate(ctx, id)
+	return nil
+}
+
+// DeleteWhere drops everything, since the deleted ids are not known here.
+func (c *CachedService) DeleteWhere(ctx context.Context, filter repo.ListFilter) (int64, error) {
+	deleted, err := c.ProductService.DeleteWhere(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	if deleted > 0 {
+		c.invalidateAll(ctx)
+	}
+	return deleted, nil
+}
+
+// MergeTags drops everything, since the retagged ids are not known here.
+func (c *CachedService) MergeTags(ctx context.Context, name string, from []string) (*repo.Tag, int64, error) {
+	tag, retagged, err := c.ProductService.MergeTags(ctx, name, from)
+	if retagged > 0 {
+		c.invalidateAll(ctx)
+	}
+	return tag, retagged, err
+}
+
+func (c *CachedService) Restore(ctx context.Context, id string) error {
+	if err := c.ProductService.Restore(ctx, id); err != nil {
+		return err
+	}
+	c.invalidate(ctx, id)
+	return nil
+}
+
+func (c *CachedService) DecrementQuantity(ctx context.Context, id string, delta int32) (int32, error) {
+	remaining, err := c.ProductService.DecrementQuantity(ctx, id, delta)
+	if err != nil {
+		return 0, err
+	}
+	c.invalidate(ctx, id)
+	return remaining, nil
+}
+
+func (c *CachedService) AdjustStock(ctx context.Context, id string, delta int32, reason, reference string) (int32, error) {
+	quantity, err := c.ProductService.AdjustStock(ctx, id, delta, reason, reference)
+	if err != nil {
+		return 0, err
+	}
+	c.invalidate(ctx, id)
+	return quantity, nil
+}
+
+func (c *CachedService) ReceiveLot(ctx context.Context, l *repo.Lot, reference string) (*repo.Lot, int32, error) {
+	lot, quantity, err := c.ProductService.ReceiveLot(ctx, l, reference)
+	if err != nil {
+		return nil, 0, err
+	}
+	c.invalidate(ctx, l.ProductID)
+	return lot, quantity, nil
+}
+
+func (c *CachedService) ConfirmReservation(ctx context.Context, id string) (*repo.Reservation, error) {
+	r, err := c.ProductService.ConfirmReservation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	c.invalidate(ctx, r.ProductID)
+	return r, nil
+}
+
+// listKey returns the key of a list query with parts in the current list
+// generation; ok is false if the generation could not be read.
+func (c *CachedService) listKey(ctx context.Context, parts ...any) (key string, ok bool) {
+	generation := "0"
+	data, err := c.Store.Get(ctx, listGenerationKey)
+	switch {
+	case err == nil:
+		generation = string(data)
+	case !errors.Is(err, ErrCacheMiss):
+		cacheStats.Add("errors", 1)
+		return "", false
+	}
+	query, err := json.Marshal(parts)
+	if err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256(query)
+	return listKeyPrefix + generation + ":" + hex.EncodeToString(sum[:]), true
+}
+
+// load reads key into m and reports whether it was cached.
+func (c *CachedService) load(ctx context.Context, key string, m proto.Message) bool {
+	data, err := c.Store.Get(ctx, key)
+	if err == nil {
+		if err := proto.Unmarshal(data, m); err == nil {
+			cacheStats.Add("hits", 1)
+			return true
+		}
+	}
+	c.miss(err)
+	return false
+}
+
+// miss counts a miss, and a store error unless err is ErrCacheMiss.
+func (c *CachedService) miss(err error) {
+	if err != nil && !errors.Is(err, ErrCacheMiss) {
+		cacheStats.Add("errors", 1)
+	}
+	cacheStats.Add("misses", 1)
+}
+
+func (c *CachedService) store(ctx context.Context, key string, m proto.Message, ttl time.Duration) {
+	if data, err := proto.Marshal(m); err == nil {
+		c.set(ctx, key, data, ttl)
+	}
+}
+
+func (c *CachedService) set(ctx context.Context, key string, data []byte, ttl time.Duration) {
+	if err := c.Store.Set(ctx, key, data, ttl); err != nil {
+		cacheStats.Add("errors", 1)
+	}
+}
+
+// invalidate drops the cached product id and all lists.
+func (c *CachedService) invalidate(ctx context.Context, id string) {
+	if err := c.Store.Delete(ctx, productKeyPrefix+id); err != nil {
+		cacheStats.Add("errors", 1)
+	}
+	c.invalidateLists(ctx)
+}
+
+// invalidateLists drops all cached lists by moving to a new generation;
+// the pages of older ones expire with ListTTL.
+func (c *CachedService) invalidateLists(ctx context.Context) {
+	if _, err := c.Store.Incr(ctx, listGenerationKey); err != nil {
+		cacheStats.Add("errors", 1)
+	}
+}
+
+func (c *CachedService) invalidateAll(ctx context.Context) {
+	if err := c.Store.DeletePrefix(ctx, productKeyPrefix); err != nil {
+		cacheStats.Add("errors", 1)
+	}
+	c.invalidateLists(ctx)
+}
+
+// MemoryStore is a CacheStore in the memory of one replica, holding up to
+// MaxEntries keys.
+type MemoryStore struct {
+	MaxEntries int
+
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	now     func() time.Time
+}
+
+// memoryEntry is a value of MemoryStore; a zero expires never expires.
+type memoryEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	return &MemoryStore{
+		MaxEntries: maxEntries,
+		entries:    make(map[string]memoryEntry),
+		now:        time.Now,
+	}
+}
+
+func (s *MemoryStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok || s.expired(e) {
+		return nil, ErrCacheMiss
+	}
+	return e.value, nil
+}
+
+// Set evicts the expired keys when the store is full, and an arbitrary
+// key if none has expired.
+func (s *MemoryStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entries[key]; !ok && len(s.entries) >= s.MaxEntries {
+		s.evict()
+	}
+	s.entries[key] = memoryEntry{value: value, expires: s.now().Add(ttl)}
+	return nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		delete(s.entries, key)
+	}
+	return nil
+}
+
+func (s *MemoryStore) DeletePrefix(ctx context.Context, prefix string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key := range s.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(s.entries, key)
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) Incr(ctx context.Context, key string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n, _ := strconv.ParseInt(string(s.entries[key].value), 10, 64)
+	n++
+	s.entries[key] = memoryEntry{value: []byte(strconv.FormatInt(n, 10))}
+	return n, nil
+}
+
+// evict makes room for a key; the lock must be held.
+func (s *MemoryStore) evict() {
+	for key, e := range s.entries {
+		if s.expired(e) {
+			delete(s.entries, key)
+		}
+	}
+	for key, e := range s.entries {
+		if len(s.entries) < s.MaxEntries {
+			return
+		}
+		if !e.expires.IsZero() {
+			delete(s.entries, key)
+		}
+	}
+}
+
+func (s *MemoryStore) expired(e memoryEntry) bool {
+	return !e.expires.IsZero() && !s.now().Before(e.expires)
+}
+
+// redisKeyPrefix prefixes the Redis keys of RedisStore.
+const redisKeyPrefix = "inventory:cache:"
+
+// RedisStore is a CacheStore in Redis, shared by the replicas.
+type RedisStore struct {
+	client redis.UniversalClient
+}
+
+func NewRedisStore(client redis.UniversalClient) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+func (s *RedisStore) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := s.client.Get(ctx, redisKeyPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrCacheMiss
+	}
+	return data, err
+}
+
+func (s *RedisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.client.Set(ctx, redisKeyPrefix+key, value, ttl).Err()
+}
+
+func (s *RedisStore) Delete(ctx context.Context, keys ...string) error {
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = redisKeyPrefix + key
+	}
+	return s.client.Del(ctx, prefixed...).Err()
+}
+
+// DeletePrefix scans every master of a Redis Cluster.
+func (s *RedisStore) DeletePrefix(ctx context.Context, prefix string) error {
+	if cluster, ok := s.client.(*redis.ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			return deletePrefix(ctx, node, redisKeyPrefix+prefix)
+		})
+	}
+	return deletePrefix(ctx, s.client, redisKeyPrefix+prefix)
+}
+
+func (s *RedisStore) Incr(ctx context.Context, key string) (int64, error) {
+	return s.client.Incr(ctx, redisKeyPrefix+key).Result()
+}
+
+// deletePrefix deletes the keys starting with prefix stored on client.
+func deletePrefix(ctx context.Context, client redis.Cmdable, prefix string) error {
+	iter := client.Scan(ctx, 0, prefix+"*", 1000).Iterator()
+	for iter.Next(ctx) {
+		if err := client.Del(ctx, iter.Val()).Err(); err != nil {
+			return err
+		}
+	}
+	return iter.Err()
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/andro-kes/inventory_service/internal/repo"
+	pb "github.com/andro-kes/inventory_service/proto"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// countingRepo counts the reads CachedService should save.
+type countingRepo struct {
+	repo.ProductRepo
+	gets, lists, counts int
+}
+
+func (r *countingRepo) Get(ctx context.Context, id string) (*pb.Product, error) {
+	r.gets++
+	return r.ProductRepo.Get(ctx, id)
+}
+
+func (r *countingRepo) ListAfter(ctx context.Context, cursor string, pageSize int32, filter repo.ListFilter, orderBy string) ([]*pb.Product, string, error) {
+	r.lists++
+	return r.ProductRepo.ListAfter(ctx, cursor, pageSize, filter, orderBy)
+}
+
+func (r *countingRepo) Count(ctx context.Context, filter repo.ListFilter) (int64, error) {
+	r.counts++
+	return r.ProductRepo.Count(ctx, filter)
+}
+
+func newCachedTestService(store CacheStore) (*CachedService, *countingRepo) {
+	ps := NewTestService(nil)
+	counting := &countingRepo{ProductRepo: ps.Repo}
+	ps.Repo = counting
+	return NewCachedService(ps, store, time.Minute, time.Minute), counting
+}
+
+func TestCachedServiceGet(t *testing.T) {
+	c, counting := newCachedTestService(NewMemoryStore(100))
+	ctx := t.Context()
+
+	p, err := c.Create(ctx, &pb.Product{Name: "old"})
+	require.NoError(t, err)
+	for range 2 {
+		got, err := c.Get(ctx, p.Id)
+		require.NoError(t, err)
+		assert.Equal(t, "old", got.GetName())
+	}
+	assert.Equal(t, 1, counting.gets)
+
+	_, err = c.Update(ctx, &pb.Product{Id: p.Id, Name: "new"}, &fieldmaskpb.FieldMask{Paths: []string{"name"}})
+	require.NoError(t, err)
+	got, err := c.Get(ctx, p.Id)
+	require.NoError(t, err)
+	assert.Equal(t, "new", got.GetName())
+	assert.Equal(t, 2, counting.gets)
+
+	// A change made by another replica.
+	c.Invalidate(ctx, repo.Change{ID: p.Id, Op: repo.OpUpdate})
+	_, err = c.Get(ctx, p.Id)
+	require.NoError(t, err)
+	assert.Equal(t, 3, counting.gets)
+
+	// Masked reads and WithoutCache skip the cache.
+	masked, err := repo.WithReadMask(ctx, []string{"name"})
+	require.NoError(t, err)
+	_, err = c.Get(masked, p.Id)
+	require.NoError(t, err)
+	_, err = c.Get(repo.WithoutCache(ctx), p.Id)
+	require.NoError(t, err)
+	assert.Equal(t, 5, counting.gets)
+
+	// Errors are not cached.
+	_, err = c.Get(ctx, "missing")
+	assert.Error(t, err)
+	_, err = c.Get(ctx, "missing")
+	assert.Error(t, err)
+	assert.Equal(t, 7, counting.gets)
+}
+
+func TestCachedServiceList(t *testing.T) {
+	c, counting := newCachedTestService(NewMemoryStore(100))
+	ctx := t.Context()
+
+	_, err := c.Create(ctx, &pb.Product{Name: "first"})
+	require.NoError(t, err)
+	filter := repo.ListFilter{Tags: []string{"sale"}}
+	for range 2 {
+		products, _, err := c.ListAfter(ctx, "", 10, filter, "name")
+		require.NoError(t, err)
+		assert.Len(t, products, 1)
+		n, err := c.Count(ctx, filter)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+	}
+	assert.Equal(t, 1, counting.lists)
+	assert.Equal(t, 1, counting.counts)
+
+	// Another query is cached apart.
+	_, _, err = c.ListAfter(ctx, "", 20, filter, "name")
+	require.NoError(t, err)
+	assert.Equal(t, 2, counting.lists)
+
+	_, err = c.Create(ctx, &pb.Product{Name: "second"})
+	require.NoError(t, err)
+	products, _, err := c.ListAfter(ctx, "", 10, filter, "name")
+	require.NoError(t, err)
+	assert.Len(t, products, 2)
+	n, err := c.Count(ctx, filter)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	assert.Equal(t, 3, counting.lists)
+	assert.Equal(t, 2, counting.counts)
+}
+
+func TestCachedServiceRedis(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	defer client.Close()
+	c, counting := newCachedTestService(NewRedisStore(client))
+	ctx := t.Context()
+
+	p, err := c.Create(ctx, &pb.Product{Name: "test"})
+	require.NoError(t, err)
+	_, err = c.Get(ctx, p.Id)
+	require.NoError(t, err)
+	assert.True(t, mr.Exists("inventory:cache:product:"+p.Id))
+	mr.Set("other", "kept")
+
+	c.Invalidate(ctx, repo.Change{Op: repo.OpReset})
+	assert.False(t, mr.Exists("inventory:cache:product:"+p.Id))
+	assert.True(t, mr.Exists("other"))
+	generation, err := mr.Get("inventory:cache:list-generation")
+	require.NoError(t, err)
+	assert.Equal(t, "2", generation)
+
+	// Without Redis reads fall through.
+	mr.Close()
+	got, err := c.Get(ctx, p.Id)
+	require.NoError(t, err)
+	assert.Equal(t, p.Id, got.GetId())
+	_, _, err = c.ListAfter(ctx, "", 10, repo.ListFilter{}, "")
+	require.NoError(t, err)
+	assert.Equal(t, 2, counting.gets)
+	assert.Equal(t, 1, counting.lists)
+}
+
+func TestMemoryStore(t *testing.T) {
+	s := NewMemoryStore(2)
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	ctx := t.Context()
+
+	require.NoError(t, s.Set(ctx, "a", []byte("1"), time.Second))
+	require.NoError(t, s.Set(ctx, "b", []byte("2"), time.Minute))
+	n, err := s.Incr(ctx, "counter")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	now = now.Add(2 * time.Second)
+	_, err = s.Get(ctx, "a")
+	assert.ErrorIs(t, err, ErrCacheMiss)
+
+	// Full, the store evicts the expired a first, then b, but never the
+	// counter.
+	require.NoError(t, s.Set(ctx, "c", []byte("3"), time.Minute))
+	require.NoError(t, s.Set(ctx, "d", []byte("4"), time.Minute))
+	assert.Len(t, s.entries, 2)
+	n, err = s.Incr(ctx, "counter")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	require.NoError(t, s.DeletePrefix(ctx, "d"))
+	_, err = s.Get(ctx, "d")
+	assert.ErrorIs(t, err, ErrCacheMiss)
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"context"
+	"expvar"
+	"time"
+
+	"github.com/andro-kes/inventory_service/internal/inverr"
+	"github.com/andro-kes/inventory_service/internal/repo"
+	"google.golang.org/grpc/codes"
+)
+
+// stockRetries counts the retries of stock mutations after conflicts by
+// operation, and under "aborted" the mutations given up. It is published
+// through expvar as stock_conflict_retries.
+var stockRetries = expvar.NewMap("stock_conflict_retries")
+
+// DefaultConflictRetry is the ConflictRetry of NewProductService: stock
+// mutations are short, so conflicts are retried soon and often.
+var DefaultConflictRetry = repo.RetryPolicy{
+	MaxAttempts: 5,
+	BaseDelay:   10 * time.Millisecond,
+	MaxDelay:    200 * time.Millisecond,
+}
+
+// retryConflicts runs the stock mutation op until it does not fail with a
+// conflict, see repo.IsConflict, within ps.ConflictRetry. Every attempt
+// reads the stock anew. A conflict left after the retries
+// is returned as Aborted, with a RetryInfo detail.
+func (ps *ProductService) retryConflicts(ctx context.Context, op string, fn func(ctx context.Context) error) error {
+	ctx = repo.WithoutConflictRetry(ctx)
+	err := ps.ConflictRetry.Retry(ctx, stockRetries, func(err error) (string, bool) {
+		return op, repo.IsConflict(err)
+	}, func() error {
+		return fn(ctx)
+	})
+	if repo.IsConflict(err) {
+		stockRetries.Add("aborted", 1)
+		return inverr.Throttled(codes.Aborted, inverr.ReasonStockConflict, ps.ConflictRetry.MaxDelay,
+			"%s conflicted with concurrent stock changes, retry", op)
+	}
+	return err
+}
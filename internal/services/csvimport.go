@@ -0,0 +1,295 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/andro-kes/inventory_service/internal/inverr"
+	pb "github.com/andro-kes/inventory_service/proto"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/status"
+)
+
+// CSVColumns are the columns ImportCSV reads:
+//
+//   - sku, required: the product to create or overwrite
+//   - name, required
+//   - description
+//   - price: a decimal amount with at most two digits after the point,
+//     e.g. 1499.90; 0 if empty
+//   - currency: the ISO 4217 code of price; the default currency if empty
+//   - quantity: a non-negative integer; 0 if empty
+//   - status: DRAFT, ACTIVE, ARCHIVED or DISCONTINUED; only set on new
+//     products, as with UpsertBySKU
+//   - category_id
+//   - tags: separated by "|", e.g. sale|new
+//   - attributes: key=value pairs separated by "|", e.g. color=red|size=M
+//
+// The header row names the columns of the file, in any order; columns
+// left out are empty for every row.
+var CSVColumns = []string{
+	"sku", "name", "description", "price", "currency", "quantity", "status", "category_id", "tags", "attributes",
+}
+
+// importBatchSize is the number of rows ImportCSV upserts per
+// transaction.
+const importBatchSize = 500
+
+// csvPrice matches the price column.
+var csvPrice = regexp.MustCompile(`^(\d+)(?:\.(\d{1,2}))?$`)
+
+// ImportRowError is a row ImportCSV skipped.
+type ImportRowError struct {
+	// Line is the line of the row in the file; the header is line 1.
+	Line int
+	SKU  string
+	Err  error
+}
+
+// ImportReport is the outcome of ImportCSV.
+type ImportReport struct {
+	// Rows is the number of rows read, Imported the number of rows
+	// upserted.
+	Rows     int
+	Imported int
+	// Errors are the skipped rows, by line.
+	Errors []ImportRowError
+}
+
+// csvRow is a parsed row of an import.
+type csvRow struct {
+	line    int
+	product *pb.Product
+}
+
+// ImportCSV upserts the products of the CSV file r by SKU, see
+// CSVColumns, and reports the rows it skipped. Rows are parsed, run
+// through the before hooks of OpCreate and validated first; invalid rows
+// are skipped. The rest is upserted in transactions of importBatchSize
+// rows, each row in its own savepoint, so a row the database rejects,
+// e.g. for a duplicate barcode, skips only that row. Later rows of a SKU
+// overwrite earlier ones. With dryRun nothing is written.
+//
+// The error is for files without a valid header and failed
+// transactions; the batches committed before are kept and counted in the
+// report.
+func (ps *ProductService) ImportCSV(ctx context.Context, r io.Reader, dryRun bool) (*ImportReport, error) {
+	rows, report, err := readCSV(r)
+	if err != nil {
+		return nil, err
+	}
+
+	valid := rows[:0]
+	for _, row := range rows {
+		p, err := ps.beforeCreate(ctx, row.product)
+		if err != nil {
+			report.fail(row, err)
+			continue
+		}
+		p.Id = uuid.NewString()
+		row.product = p
+		valid = append(valid, row)
+	}
+
+	if !dryRun {
+		for batch := range slices.Chunk(valid, importBatchSize) {
+			if err := ps.importBatch(ctx, batch, report); err != nil {
+				report.sort()
+				return report, err
+			}
+		}
+	}
+	report.sort()
+	return report, nil
+}
+
+// importBatch upserts batch in one transaction and adds the outcome to
+// report once it is committed.
+func (ps *ProductService) importBatch(ctx context.Context, batch []csvRow, report *ImportReport) error {
+	// stored are the upserted products and newIDs the fresh ids they
+	// were given.
+	var stored []*pb.Product
+	var newIDs []string
+	var failed ImportReport
+	err := ps.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		stored, newIDs, failed.Errors = nil, nil, nil
+		for _, row := range batch {
+			p, err := ps.Repo.UpsertBySKU(ctx, row.product)
+			if err != nil {
+				failed.fail(row, err)
+				continue
+			}
+			stored = append(stored, p)
+			newIDs = append(newIDs, row.product.GetId())
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	report.Imported += len(stored)
+	report.Errors = append(report.Errors, failed.Errors...)
+	for i, p := range stored {
+		ps.upserted(ctx, newIDs[i], p)
+	}
+	return nil
+}
+
+func (r *ImportReport) fail(row csvRow, err error) {
+	r.Errors = append(r.Errors, ImportRowError{Line: row.line, SKU: row.product.GetSku(), Err: err})
+}
+
+func (r *ImportReport) sort() {
+	slices.SortStableFunc(r.Errors, func(a, b ImportRowError) int {
+		return cmp.Compare(a.Line, b.Line)
+	})
+}
+
+// readCSV parses the rows of r. Rows that do not parse are added to the
+// errors of the report.
+func readCSV(r io.Reader) ([]csvRow, *ImportReport, error) {
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, inverr.InvalidField("csv", "the file is empty")
+		}
+		return nil, nil, inverr.InvalidField("csv", "invalid header: %v", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\uFEFF")))
+		if !slices.Contains(CSVColumns, name) {
+			return nil, nil, inverr.InvalidField("csv", "unknown column %q, want some of %s", name, strings.Join(CSVColumns, ", "))
+		}
+		if _, ok := columns[name]; ok {
+			return nil, nil, inverr.InvalidField("csv", "duplicate column %q", name)
+		}
+		columns[name] = i
+	}
+	for _, name := range []string{"sku", "name"} {
+		if _, ok := columns[name]; !ok {
+			return nil, nil, inverr.InvalidField("csv", "missing column %q", name)
+		}
+	}
+
+	var rows []csvRow
+	report := &ImportReport{}
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			report.Rows++
+			report.fail(csvRow{line: parseErr.StartLine, product: &pb.Product{}}, inverr.InvalidField("csv", "%v", parseErr.Err))
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		report.Rows++
+		line, _ := cr.FieldPos(0)
+		field := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		row := csvRow{line: line}
+		row.product, err = parseCSVProduct(field)
+		if err != nil {
+			report.fail(row, err)
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows, report, nil
+}
+
+// parseCSVProduct returns the product of a row whose columns field
+// returns.
+func parseCSVProduct(field func(name string) string) (*pb.Product, error) {
+	p := &pb.Product{
+		Sku:         field("sku"),
+		Name:        field("name"),
+		Description: field("description"),
+		CategoryId:  field("category_id"),
+	}
+	if p.Sku == "" {
+		return p, inverr.InvalidField("sku", "sku is required")
+	}
+
+	p.PriceMoney = &pb.Money{CurrencyCode: field("currency")}
+	if v := field("price"); v != "" {
+		m := csvPrice.FindStringSubmatch(v)
+		if m == nil {
+			return p, inverr.InvalidField("price", "price must be a non-negative amount like 1499.90: %q", v)
+		}
+		units, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil {
+			return p, inverr.InvalidField("price", "price is too large: %q", v)
+		}
+		cents, _ := strconv.Atoi((m[2] + "00")[:2])
+		p.PriceMoney.Units = units
+		p.PriceMoney.Nanos = int32(cents) * 10_000_000
+	}
+
+	if v := field("quantity"); v != "" {
+		q, err := strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			return p, inverr.InvalidField("quantity", "quantity must be an integer: %q", v)
+		}
+		p.Quantity = int32(q)
+	}
+
+	if v := field("status"); v != "" {
+		st, ok := pb.Product_Status_value[strings.ToUpper(v)]
+		if !ok || st == int32(pb.Product_STATUS_UNSPECIFIED) {
+			return p, inverr.InvalidField("status", "status must be DRAFT, ACTIVE, ARCHIVED or DISCONTINUED: %q", v)
+		}
+		p.Status = pb.Product_Status(st)
+	}
+
+	for tag := range strings.SplitSeq(field("tags"), "|") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			p.Tags = append(p.Tags, tag)
+		}
+	}
+
+	for pair := range strings.SplitSeq(field("attributes"), "|") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return p, inverr.InvalidField("attributes", "attributes must be key=value pairs: %q", pair)
+		}
+		if p.Attributes == nil {
+			p.Attributes = make(map[string]string)
+		}
+		p.Attributes[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return p, nil
+}
+
+// String formats e as "line 3 (sku TS-M): name is required".
+func (e ImportRowError) String() string {
+	msg := status.Convert(e.Err).Message()
+	if e.SKU == "" {
+		return fmt.Sprintf("line %d: %s", e.Line, msg)
+	}
+	return fmt.Sprintf("line %d (sku %s): %s", e.Line, e.SKU, msg)
+}
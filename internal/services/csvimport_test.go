@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/andro-kes/inventory_service/internal/inverr"
+	"github.com/andro-kes/inventory_service/internal/repo"
+	pb "github.com/andro-kes/inventory_service/proto"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// rejectingRepo fails the upserts of sku like a unique violation.
+type rejectingRepo struct {
+	*TestRepo
+	sku string
+}
+
+func (r *rejectingRepo) UpsertBySKU(ctx context.Context, p *pb.Product) (*pb.Product, error) {
+	if p.GetSku() == r.sku {
+		return nil, inverr.WithReason(codes.AlreadyExists, inverr.ReasonDuplicateSKU, nil, "duplicate barcode")
+	}
+	return r.TestRepo.UpsertBySKU(ctx, p)
+}
+
+func TestImportCSV(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	s := NewTestService(nil)
+	s.Tx = repo.NewTxManager(mock)
+	s.Repo = &rejectingRepo{TestRepo: s.Repo.(*TestRepo), sku: "TAKEN"}
+	var after []string
+	s.Hooks.After(OpCreate, func(ctx context.Context, w *Write) {
+		after = append(after, w.Product.GetSku())
+	})
+
+	file := "\uFEFFSKU,name,price,currency,quantity,tags,attributes,status\n" +
+		"TS-M,Shirt,1499.9,USD,3,sale|new,color=red|size=M,active\n" +
+		",No sku,1,,1,,,\n" +
+		"TS-L,\"Shirt, large\",12,,1,,,\n" +
+		"BAD-PRICE,Shirt,12.345,,1,,,\n" +
+		"BAD-QTY,Shirt,1,,-1,,,\n" +
+		"TAKEN,Shirt,1,,1,,,\n" +
+		"SHORT,Shirt\n"
+
+	// Dry runs only validate.
+	report, err := s.ImportCSV(t.Context(), strings.NewReader(file), true)
+	require.NoError(t, err)
+	assert.Equal(t, 7, report.Rows)
+	assert.Zero(t, report.Imported)
+	require.Len(t, report.Errors, 4)
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	report, err = s.ImportCSV(t.Context(), strings.NewReader(file), false)
+	require.NoError(t, err)
+	assert.Equal(t, 7, report.Rows)
+	assert.Equal(t, 2, report.Imported)
+	assert.Equal(t, []string{"TS-M", "TS-L"}, after)
+
+	var lines []int
+	for _, e := range report.Errors {
+		lines = append(lines, e.Line)
+	}
+	assert.Equal(t, []int{3, 5, 6, 7, 8}, lines)
+	assert.Equal(t, "line 5 (sku BAD-PRICE): price must be a non-negative amount like 1499.90: \"12.345\"", report.Errors[1].String())
+	assert.Equal(t, codes.AlreadyExists, status.Code(report.Errors[3].Err))
+
+	p, err := s.GetBySKU(t.Context(), "TS-M")
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(&pb.Money{CurrencyCode: "USD", Units: 1499, Nanos: 900_000_000}, p.GetPriceMoney()))
+	assert.Equal(t, []string{"sale", "new"}, p.GetTags())
+	assert.Equal(t, map[string]string{"color": "red", "size": "M"}, p.GetAttributes())
+	assert.Equal(t, pb.Product_ACTIVE, p.GetStatus())
+
+	for _, file := range []string{"", "sku,name,weight\n", "name,price\n"} {
+		_, err := s.ImportCSV(t.Context(), strings.NewReader(file), false)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), file)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
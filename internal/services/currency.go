@@ -0,0 +1,287 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/andro-kes/inventory_service/internal/inverr"
+	"github.com/andro-kes/inventory_service/internal/repo"
+	pb "github.com/andro-kes/inventory_service/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RateProvider supplies exchange rates, e.g. repo.RateRepo or HTTPRates.
+type RateProvider interface {
+	// Rates returns how many units of each currency one unit of the base
+	// currency buys, e.g. {"USD": 0.0108} for RUB.
+	Rates(ctx context.Context) (map[string]*big.Rat, error)
+}
+
+// ErrUnknownCurrency is returned for currencies without an exchange rate.
+var ErrUnknownCurrency = errors.New("unknown currency")
+
+// Converter converts prices between currencies through the base currency
+// the rates of Provider are quoted against.
+type Converter struct {
+	// Base is the base currency; its rate is 1 unless Provider has one.
+	Base     string
+	Provider RateProvider
+}
+
+func NewConverter(base string, provider RateProvider) *Converter {
+	return &Converter{Base: base, Provider: provider}
+}
+
+// rates returns the rates of Provider with the one of the base currency.
+func (c *Converter) rates(ctx context.Context) (map[string]*big.Rat, error) {
+	rates, err := c.Provider.Rates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := rates[c.Base]; !ok {
+		// Providers may share their map between calls.
+		rates = maps.Clone(rates)
+		if rates == nil {
+			rates = make(map[string]*big.Rat)
+		}
+		rates[c.Base] = big.NewRat(1, 1)
+	}
+	return rates, nil
+}
+
+// Convert returns m in the currency to, rounded half away from zero to
+// cents. m without a currency is in repo.DefaultCurrency.
+func (c *Converter) Convert(ctx context.Context, m *pb.Money, to string) (*pb.Money, error) {
+	rates, err := c.rates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return convert(rates, m, to)
+}
+
+func convert(rates map[string]*big.Rat, m *pb.Money, to string) (*pb.Money, error) {
+	if m == nil {
+		return nil, nil
+	}
+	from := m.GetCurrencyCode()
+	if from == "" {
+		from = repo.DefaultCurrency
+	}
+	if from == to {
+		return m, nil
+	}
+	fromRate, ok := rates[from]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCurrency, from)
+	}
+	toRate, ok := rates[to]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCurrency, to)
+	}
+
+	// The amount in nanos, converted and then rounded to cents.
+	nanos := new(big.Int).Mul(big.NewInt(m.GetUnits()), big.NewInt(1e9))
+	nanos.Add(nanos, big.NewInt(int64(m.GetNanos())))
+	amount := new(big.Rat).SetFrac(nanos, big.NewInt(1e7))
+	amount.Mul(amount, toRate).Quo(amount, fromRate)
+	cents := roundHalfAway(amount)
+	if !cents.IsInt64() {
+		return nil, fmt.Errorf("%s %d.%09d in %s out of range", from, m.GetUnits(), m.GetNanos(), to)
+	}
+	n := cents.Int64()
+	return &pb.Money{
+		CurrencyCode: to,
+		Units:        n / 100,
+		Nanos:        int32(n%100) * 1e7,
+	}, nil
+}
+
+// roundHalfAway rounds r to the nearest integer, halves away from zero.
+func roundHalfAway(r *big.Rat) *big.Int {
+	num := new(big.Int).Abs(r.Num())
+	q, rem := new(big.Int).QuoRem(num, r.Denom(), new(big.Int))
+	if rem.Lsh(rem, 1).Cmp(r.Denom()) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if r.Sign() < 0 {
+		q.Neg(q)
+	}
+	return q
+}
+
+// ConvertPrices converts the prices of products, and of their variants,
+// to currency at the current exchange rates; see Converter. An empty
+// currency leaves them as stored. Prices outside the read mask of ctx are
+// left unset.
+func (ps *ProductService) ConvertPrices(ctx context.Context, currency string, products ...*pb.Product) error {
+	if currency == "" || len(products) == 0 {
+		return nil
+	}
+	if ps.Currency == nil {
+		return status.Error(codes.Unimplemented, "currency conversion is not configured")
+	}
+	rates, err := ps.Currency.rates(ctx)
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "exchange rates unavailable: %v", err)
+	}
+	if _, ok := rates[currency]; !ok {
+		return inverr.InvalidField("currency", "unsupported currency %q", currency)
+	}
+
+	for _, p := range products {
+		err := convertProduct(ctx, rates, p, currency)
+		if errors.Is(err, ErrUnknownCurrency) {
+			// The product is stored in a currency without a rate.
+			return status.Errorf(codes.FailedPrecondition, "cannot convert the prices of product %s: %v", p.GetId(), err)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// convertProduct converts the prices of p and its variants to currency.
+func convertProduct(ctx context.Context, rates map[string]*big.Rat, p *pb.Product, currency string) error {
+	if p.PriceMoney == nil {
+		return nil
+	}
+	price, err := convert(rates, p.PriceMoney, currency)
+	if err != nil {
+		return err
+	}
+	p.PriceMoney = price
+	if repo.ReadsField(ctx, "price") {
+		p.Price = float64(price.GetUnits()) + float64(price.GetNanos())/1e9
+	}
+	for _, v := range p.Variants {
+		if v.PriceDelta, err = convert(rates, v.PriceDelta, currency); err != nil {
+			return err
+		}
+		if v.Price, err = convert(rates, v.Price, currency); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HTTPRates fetches exchange rates from an HTTP API answering GET URL
+// with a JSON object like
+//
+//	{"base": "RUB", "rates": {"USD": 0.0108, "EUR": 0.0100}}
+//
+// as many public rate APIs do. A base other than Base is rejected; a
+// response without one is taken to be in Base.
+type HTTPRates struct {
+	URL    string
+	Base   string
+	Client *http.Client
+}
+
+func (h *HTTPRates) Rates(ctx context.Context) (map[string]*big.Rat, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := h.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("exchange rates: %s", resp.Status)
+	}
+
+	var body struct {
+		Base  string                 `json:"base"`
+		Rates map[string]json.Number `json:"rates"`
+	}
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&body); err != nil {
+		return nil, fmt.Errorf("exchange rates: %w", err)
+	}
+	if body.Base != "" && body.Base != h.Base {
+		return nil, fmt.Errorf("exchange rates: base %s, want %s", body.Base, h.Base)
+	}
+
+	rates := make(map[string]*big.Rat, len(body.Rates))
+	for currency, n := range body.Rates {
+		rate, ok := new(big.Rat).SetString(n.String())
+		if !ok || rate.Sign() <= 0 {
+			return nil, fmt.Errorf("exchange rates: invalid rate of %s: %s", currency, n)
+		}
+		rates[currency] = rate
+	}
+	return rates, nil
+}
+
+// CachedRates keeps the rates of Provider for TTL, so that reads do not
+// query it every time. When a refresh fails the last rates are kept for
+// another TTL, and the failure is reported to OnError; without rates it
+// is returned.
+type CachedRates struct {
+	Provider RateProvider
+	TTL      time.Duration
+	// OnError is called with the failed refreshes served stale rates.
+	OnError func(err error)
+
+	mu      sync.Mutex
+	rates   map[string]*big.Rat
+	fetched time.Time
+	now     func() time.Time
+}
+
+func NewCachedRates(provider RateProvider, ttl time.Duration) *CachedRates {
+	return &CachedRates{Provider: provider, TTL: ttl, now: time.Now}
+}
+
+// Rates returns the cached rates, refreshing them once they are older
+// than TTL. Concurrent callers wait for one refresh.
+func (c *CachedRates) Rates(ctx context.Context) (map[string]*big.Rat, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.rates != nil && c.now().Sub(c.fetched) < c.TTL {
+		return c.rates, nil
+	}
+	rates, err := c.Provider.Rates(ctx)
+	if err != nil {
+		if c.rates == nil {
+			return nil, err
+		}
+		if c.OnError != nil {
+			c.OnError(err)
+		}
+		c.fetched = c.now()
+		return c.rates, nil
+	}
+	c.rates, c.fetched = rates, c.now()
+	return rates, nil
+}
+
+// Refresh fetches the rates of Provider now, e.g. from a periodic job so
+// that reads never wait for the provider. On failure the cached rates are
+// kept until they expire as usual.
+func (c *CachedRates) Refresh(ctx context.Context) error {
+	rates, err := c.Provider.Rates(ctx)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rates, c.fetched = rates, c.now()
+	return nil
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/andro-kes/inventory_service/internal/repo"
+	pb "github.com/andro-kes/inventory_service/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// staticRates is a RateProvider of fixed rates.
+type staticRates struct {
+	rates map[string]*big.Rat
+	err   error
+	calls int
+}
+
+func (s *staticRates) Rates(ctx context.Context) (map[string]*big.Rat, error) {
+	s.calls++
+	return s.rates, s.err
+}
+
+func testRates() *staticRates {
+	return &staticRates{rates: map[string]*big.Rat{
+		"USD": big.NewRat(1, 80),
+		"EUR": big.NewRat(1, 100),
+	}}
+}
+
+func TestConverterConvert(t *testing.T) {
+	c := NewConverter("RUB", testRates())
+	ctx := t.Context()
+
+	cases := []struct {
+		in   *pb.Money
+		to   string
+		want *pb.Money
+	}{
+		{&pb.Money{CurrencyCode: "RUB", Units: 800}, "USD", &pb.Money{CurrencyCode: "USD", Units: 10}},
+		// 1.01 RUB is 0.012625 USD.
+		{&pb.Money{CurrencyCode: "RUB", Units: 1, Nanos: 10_000_000}, "USD", &pb.Money{CurrencyCode: "USD", Nanos: 10_000_000}},
+		// 0.40 RUB is 0.005 USD, rounded away from zero.
+		{&pb.Money{Nanos: 400_000_000}, "USD", &pb.Money{CurrencyCode: "USD", Nanos: 10_000_000}},
+		{&pb.Money{CurrencyCode: "RUB", Nanos: -400_000_000}, "USD", &pb.Money{CurrencyCode: "USD", Nanos: -10_000_000}},
+		// Through the base currency.
+		{&pb.Money{CurrencyCode: "USD", Units: 5}, "EUR", &pb.Money{CurrencyCode: "EUR", Units: 4}},
+		{&pb.Money{CurrencyCode: "EUR", Units: 3}, "EUR", &pb.Money{CurrencyCode: "EUR", Units: 3}},
+	}
+	for _, tc := range cases {
+		got, err := c.Convert(ctx, tc.in, tc.to)
+		require.NoError(t, err)
+		assert.Equal(t, tc.want.String(), got.String(), "%v to %s", tc.in, tc.to)
+	}
+
+	_, err := c.Convert(ctx, &pb.Money{CurrencyCode: "RUB", Units: 1}, "GBP")
+	assert.ErrorIs(t, err, ErrUnknownCurrency)
+}
+
+func TestConvertPrices(t *testing.T) {
+	ps := NewTestService(nil)
+	ctx := t.Context()
+	product := func() *pb.Product {
+		return &pb.Product{
+			Id:         "p1",
+			Price:      800,
+			PriceMoney: &pb.Money{CurrencyCode: "RUB", Units: 800},
+			Variants: []*pb.Variant{{
+				PriceDelta: &pb.Money{CurrencyCode: "RUB", Units: -80},
+				Price:      &pb.Money{CurrencyCode: "RUB", Units: 720},
+			}},
+		}
+	}
+
+	// Without a converter only unconverted reads succeed.
+	require.NoError(t, ps.ConvertPrices(ctx, "", product()))
+	assert.Equal(t, codes.Unimplemented, status.Code(ps.ConvertPrices(ctx, "USD", product())))
+
+	rates := testRates()
+	ps.Currency = NewConverter("RUB", rates)
+	p := product()
+	require.NoError(t, ps.ConvertPrices(ctx, "USD", p))
+	assert.Equal(t, &pb.Money{CurrencyCode: "USD", Units: 10}, p.GetPriceMoney())
+	assert.Equal(t, 10.0, p.GetPrice())
+	assert.Equal(t, int64(-1), p.GetVariants()[0].GetPriceDelta().GetUnits())
+	assert.Equal(t, int64(9), p.GetVariants()[0].GetPrice().GetUnits())
+
+	// The deprecated price is left out as the read mask says.
+	masked, err := repo.WithReadMask(ctx, []string{"price_money"})
+	require.NoError(t, err)
+	p = product()
+	p.Price = 0
+	require.NoError(t, ps.ConvertPrices(masked, "USD", p))
+	assert.Zero(t, p.GetPrice())
+
+	err = ps.ConvertPrices(ctx, "GBP", product())
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	p = product()
+	p.PriceMoney.CurrencyCode = "JPY"
+	assert.Equal(t, codes.FailedPrecondition, status.Code(ps.ConvertPrices(ctx, "USD", p)))
+	rates.err = errors.New("connection refused")
+	assert.Equal(t, codes.Unavailable, status.Code(ps.ConvertPrices(ctx, "USD", product())))
+}
+
+func TestHTTPRates(t *testing.T) {
+	body := `{"base": "RUB", "rates": {"USD": 0.0108, "EUR": 1e-2}}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	h := &HTTPRates{URL: srv.URL, Base: "RUB"}
+	rates, err := h.Rates(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "27/2500", rates["USD"].RatString())
+	assert.Equal(t, "1/100", rates["EUR"].RatString())
+
+	for _, bad := range []string{
+		`{"base": "USD", "rates": {"RUB": 92.5}}`,
+		`{"rates": {"USD": 0}}`,
+		`not json`,
+	} {
+		body = bad
+		_, err := h.Rates(t.Context())
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestCachedRates(t *testing.T) {
+	provider := testRates()
+	c := NewCachedRates(provider, time.Minute)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	var errs []error
+	c.OnError = func(err error) { errs = append(errs, err) }
+	ctx := t.Context()
+
+	for range 2 {
+		_, err := c.Rates(ctx)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, provider.calls)
+
+	// A failed refresh keeps the rates for another TTL.
+	now = now.Add(2 * time.Minute)
+	provider.err = errors.New("connection refused")
+	for range 2 {
+		rates, err := c.Rates(ctx)
+		require.NoError(t, err)
+		assert.Len(t, rates, 2)
+	}
+	assert.Equal(t, 2, provider.calls)
+	assert.Len(t, errs, 1)
+
+	// Without rates the failure is returned.
+	c = NewCachedRates(provider, time.Minute)
+	_, err := c.Rates(ctx)
+	assert.Error(t, err)
+
+	// Refreshed rates are served without calling the provider.
+	assert.Error(t, c.Refresh(ctx))
+	provider.err = nil
+	require.NoError(t, c.Refresh(ctx))
+	calls := provider.calls
+	_, err = c.Rates(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, calls, provider.calls)
+}
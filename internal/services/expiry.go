@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultIdempotencyTTL is the IdempotencyTTL of NewProductService: long
+// enough for clients to retry a create across outages and deploys.
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// ReleaseExpired marks the expired reservations, batchSize at a time,
+// and returns how many it marked. Reservations locked elsewhere are left
+// for a later call; see repo.ProductRepo.ReleaseExpired.
+func (ps *ProductService) ReleaseExpired(ctx context.Context, batchSize int) (int64, error) {
+	return sweep(ctx, batchSize, ps.Repo.ReleaseExpired)
+}
+
+// PurgeIdempotencyKeys deletes the expired idempotency keys, batchSize at
+// a time, and returns how many it deleted; see
+// repo.ProductRepo.PurgeIdempotencyKeys.
+func (ps *ProductService) PurgeIdempotencyKeys(ctx context.Context, batchSize int) (int64, error) {
+	return sweep(ctx, batchSize, ps.Repo.PurgeIdempotencyKeys)
+}
+
+// sweep calls batch until it handles fewer than batchSize rows, and
+// returns how many rows the batches handled.
+func sweep(ctx context.Context, batchSize int, batch func(ctx context.Context, limit int) (int64, error)) (int64, error) {
+	var total int64
+	for {
+		n, err := batch(ctx, batchSize)
+		total += n
+		if err != nil || n < int64(batchSize) {
+			return total, err
+		}
+	}
+}
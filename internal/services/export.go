@@ -0,0 +1,112 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/andro-kes/inventory_service/internal/repo"
+	pb "github.com/andro-kes/inventory_service/proto"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// ExportFormat is a file format of Export.
+type ExportFormat int
+
+const (
+	// ExportCSV writes the CSVColumns of the products, so that the file
+	// can be imported again with ImportCSV.
+	ExportCSV ExportFormat = iota
+	// ExportNDJSON writes every product as a line of protojson, with all
+	// stored fields.
+	ExportNDJSON
+)
+
+// Export writes the products matching filter to w in format and returns
+// how many it wrote. Products are read with ListAll, one at a time, so
+// memory does not grow with the catalog; the file is a consistent
+// snapshot. w is written to as the products are read and should buffer.
+func (ps *ProductService) Export(ctx context.Context, filter repo.ListFilter, format ExportFormat, w io.Writer) (int64, error) {
+	var n int64
+	switch format {
+	case ExportCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(CSVColumns); err != nil {
+			return 0, err
+		}
+		record := make([]string, len(CSVColumns))
+		err := ps.ListAll(ctx, filter, func(p *pb.Product) error {
+			n++
+			return cw.Write(csvRecord(p, record))
+		})
+		if err != nil {
+			return n, err
+		}
+		cw.Flush()
+		return n, cw.Error()
+	case ExportNDJSON:
+		bw := bufio.NewWriter(w)
+		var opts protojson.MarshalOptions
+		var line []byte
+		err := ps.ListAll(ctx, filter, func(p *pb.Product) error {
+			var err error
+			if line, err = opts.MarshalAppend(line[:0], p); err != nil {
+				return err
+			}
+			n++
+			_, err = bw.Write(append(line, '\n'))
+			return err
+		})
+		if err != nil {
+			return n, err
+		}
+		return n, bw.Flush()
+	default:
+		return 0, fmt.Errorf("unknown export format %d", format)
+	}
+}
+
+// csvRecord fills record with the CSVColumns of p and returns it.
+func csvRecord(p *pb.Product, record []string) []string {
+	price := p.GetPriceMoney()
+	cents := price.GetUnits()*100 + int64(price.GetNanos()/10_000_000)
+	attributes := make([]string, 0, len(p.GetAttributes()))
+	for _, key := range slices.Sorted(maps.Keys(p.GetAttributes())) {
+		attributes = append(attributes, key+"="+p.GetAttributes()[key])
+	}
+	for i, column := range CSVColumns {
+		var v string
+		switch column {
+		case "sku":
+			v = p.GetSku()
+		case "name":
+			v = p.GetName()
+		case "description":
+			v = p.GetDescription()
+		case "price":
+			v = fmt.Sprintf("%d.%02d", cents/100, cents%100)
+		case "currency":
+			v = price.GetCurrencyCode()
+		case "quantity":
+			v = strconv.Itoa(int(p.GetQuantity()))
+		case "status":
+			if p.GetStatus() != pb.Product_STATUS_UNSPECIFIED {
+				v = p.GetStatus().String()
+			}
+		case "category_id":
+			v = p.GetCategoryId()
+		case "tags":
+			v = strings.Join(p.GetTags(), "|")
+		case "attributes":
+			v = strings.Join(attributes, "|")
+		}
+		record[i] = v
+	}
+	return record
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/andro-kes/inventory_service/internal/repo"
+	pb "github.com/andro-kes/inventory_service/proto"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestExport(t *testing.T) {
+	s := NewTestService(nil)
+	p := &pb.Product{
+		Id:         "1",
+		Sku:        "TS-M",
+		Name:       "Shirt, red",
+		PriceMoney: &pb.Money{CurrencyCode: "USD", Units: 1499, Nanos: 900_000_000},
+		Quantity:   3,
+		Status:     pb.Product_ACTIVE,
+		Tags:       []string{"sale", "new"},
+		Attributes: map[string]string{"size": "M", "color": "red"},
+	}
+	s.Repo.(*TestRepo).Storage[p.Id] = p
+
+	var csv bytes.Buffer
+	n, err := s.Export(t.Context(), repo.ListFilter{}, ExportCSV, &csv)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	assert.Equal(t, "sku,name,description,price,currency,quantity,status,category_id,tags,attributes\n"+
+		"TS-M,\"Shirt, red\",,1499.90,USD,3,ACTIVE,,sale|new,color=red|size=M\n", csv.String())
+
+	// Exported files import as they are.
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	imported := NewTestService(nil)
+	imported.Tx = repo.NewTxManager(mock)
+	report, err := imported.ImportCSV(t.Context(), &csv, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Imported)
+	assert.Empty(t, report.Errors)
+
+	var ndjson bytes.Buffer
+	_, err = s.Export(t.Context(), repo.ListFilter{}, ExportNDJSON, &ndjson)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(ndjson.String(), "\n"), "\n")
+	require.Len(t, lines, 1)
+	var exported pb.Product
+	require.NoError(t, protojson.Unmarshal([]byte(lines[0]), &exported))
+	assert.True(t, proto.Equal(p, &exported))
+}
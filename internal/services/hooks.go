@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+
+	pb "github.com/andro-kes/inventory_service/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// Op is a kind of product write hooks are registered for.
+type Op string
+
+const (
+	// OpCreate covers Create, CreateIdempotent, CreateMany, Import,
+	// ImportCSV and UpsertBySKU.
+	OpCreate Op = "create"
+	// OpUpdate covers Update and every update of BatchUpdate.
+	OpUpdate Op = "update"
+	// OpDelete covers Delete and every product DeleteWhere deletes.
+	// Restore and Purge run no hooks: a restore undoes a delete rather
+	// than writing a product, and Purge removes products whose delete ran
+	// the hooks already.
+	OpDelete Op = "delete"
+)
+
+// Write is a product write as hooks see it.
+type Write struct {
+	Op Op
+	// Product is the product as given to the before hooks, which may
+	// change it, and as stored to the after hooks. For deletes only its
+	// id is set.
+	Product *pb.Product
+	// Mask is the update mask of updates, nil if all fields are written.
+	// Before hooks changing fields outside it must add their paths for the
+	// change to be stored.
+	Mask *fieldmaskpb.FieldMask
+}
+
+// BeforeHook runs before a write is validated and stored, e.g. to add
+// tags or enforce naming conventions. An error rejects the write and is
+// returned to the caller as is, so hooks should return status errors,
+// e.g. inverr.InvalidField; other errors reach clients as Internal.
+type BeforeHook func(ctx context.Context, w *Write) error
+
+// AfterHook runs once a write is committed, e.g. to notify other systems.
+// The write cannot be undone anymore, so after hooks handle their own
+// failures. They run within the request; slow work should be handed off.
+type AfterHook func(ctx context.Context, w *Write)
+
+// Hooks are the hooks of the writes of a ProductService, run in the order
+// they were registered. Register them before serving: registration is not
+// safe concurrently with writes.
+type Hooks struct {
+	before map[Op][]BeforeHook
+	after  map[Op][]AfterHook
+}
+
+// Before registers hook to run before the writes op.
+func (h *Hooks) Before(op Op, hook BeforeHook) {
+	if h.before == nil {
+		h.before = make(map[Op][]BeforeHook)
+	}
+	h.before[op] = append(h.before[op], hook)
+}
+
+// After registers hook to run after the writes op.
+func (h *Hooks) After(op Op, hook AfterHook) {
+	if h.after == nil {
+		h.after = make(map[Op][]AfterHook)
+	}
+	h.after[op] = append(h.after[op], hook)
+}
+
+// hasBefore reports whether before hooks are registered for op.
+func (h *Hooks) hasBefore(op Op) bool {
+	return len(h.before[op]) > 0
+}
+
+// runBefore runs the before hooks of w.Op on w until one fails.
+func (h *Hooks) runBefore(ctx context.Context, w *Write) error {
+	for _, hook := range h.before[w.Op] {
+		if err := hook(ctx, w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runAfter runs the after hooks of op on p.
+func (h *Hooks) runAfter(ctx context.Context, op Op, p *pb.Product, mask *fieldmaskpb.FieldMask) {
+	w := &Write{Op: op, Product: p, Mask: mask}
+	for _, hook := range h.after[op] {
+		hook(ctx, w)
+	}
+}
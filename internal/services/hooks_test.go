@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andro-kes/inventory_service/internal/inverr"
+	"github.com/andro-kes/inventory_service/internal/repo"
+	pb "github.com/andro-kes/inventory_service/proto"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func TestHooks(t *testing.T) {
+	service := NewTestService(nil)
+	ctx := t.Context()
+
+	// Names are title case and products are tagged with their writer.
+	service.Hooks.Before(OpCreate, func(ctx context.Context, w *Write) error {
+		if strings.ToUpper(w.Product.GetName()[:1]) != w.Product.GetName()[:1] {
+			return inverr.InvalidField("name", "name must start with a capital letter")
+		}
+		w.Product.Tags = append(w.Product.Tags, "catalog")
+		return nil
+	})
+	service.Hooks.Before(OpUpdate, func(ctx context.Context, w *Write) error {
+		w.Product.Tags = []string{"edited"}
+		w.Mask.Paths = append(w.Mask.Paths, "tags")
+		return nil
+	})
+	service.Hooks.Before(OpDelete, func(ctx context.Context, w *Write) error {
+		return status.Error(codes.PermissionDenied, "products are archived, not deleted")
+	})
+	var after []*Write
+	for _, op := range []Op{OpCreate, OpUpdate, OpDelete} {
+		service.Hooks.After(op, func(ctx context.Context, w *Write) {
+			after = append(after, w)
+		})
+	}
+
+	_, err := service.Create(ctx, &pb.Product{Name: "chair"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	p, err := service.Create(ctx, &pb.Product{Name: "Chair"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"catalog"}, p.GetTags())
+
+	updated, err := service.Update(ctx, &pb.Product{Id: p.Id, Name: "Table"}, &fieldmaskpb.FieldMask{Paths: []string{"name"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"edited"}, updated.GetTags())
+
+	err = service.Delete(ctx, p.Id)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// Only the committed writes reach the after hooks.
+	require.Len(t, after, 2)
+	assert.Equal(t, OpCreate, after[0].Op)
+	assert.Equal(t, p.Id, after[0].Product.GetId())
+	assert.Equal(t, OpUpdate, after[1].Op)
+	assert.Equal(t, []string{"name", "tags"}, after[1].Mask.GetPaths())
+}
+
+func TestDeleteWhereHooks(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	service := NewTestService(nil)
+	service.Tx = repo.NewTxManager(mock)
+	ctx := t.Context()
+
+	var vetoed string
+	var before, after []string
+	service.Hooks.Before(OpDelete, func(ctx context.Context, w *Write) error {
+		before = append(before, w.Product.GetId())
+		if w.Product.GetId() == vetoed {
+			return status.Error(codes.PermissionDenied, "product is archived, not deleted")
+		}
+		return nil
+	})
+	service.Hooks.After(OpDelete, func(ctx context.Context, w *Write) {
+		after = append(after, w.Product.GetId())
+	})
+
+	// One failing before hook rolls back the whole delete.
+	p, err := service.Create(ctx, &pb.Product{Name: "Chair", Tags: []string{"old"}})
+	require.NoError(t, err)
+	vetoed = p.Id
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	_, err = service.DeleteWhere(ctx, repo.ListFilter{Tags: []string{"old"}})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Empty(t, after)
+
+	vetoed, before = "", nil
+	var ids []string
+	for _, name := range []string{"Table", "Lamp"} {
+		p, err := service.Create(ctx, &pb.Product{Name: name, Tags: []string{"old"}})
+		require.NoError(t, err)
+		ids = append(ids, p.Id)
+	}
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	deleted, err := service.DeleteWhere(ctx, repo.ListFilter{Tags: []string{"old"}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+	assert.ElementsMatch(t, ids, before)
+	assert.ElementsMatch(t, ids, after)
+
+	// Restore and Purge run no hooks.
+	before, after = nil, nil
+	require.NoError(t, service.Restore(ctx, ids[0]))
+	_, err = service.Purge(ctx, time.Hour)
+	require.NoError(t, err)
+	assert.Empty(t, before)
+	assert.Empty(t, after)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+
+	"github.com/andro-kes/inventory_service/internal/inverr"
+	"github.com/andro-kes/inventory_service/internal/repo"
+	pb "github.com/andro-kes/inventory_service/proto"
+	"github.com/google/uuid"
+)
+
+// PlaceOrderLine takes the quantity of line of the order orderID out of
+// stock as sold, once per line id, and reports whether it did: lines
+// placed or cancelled before change nothing. Without enough stock it
+// fails with an *repo.InsufficientStockError and records nothing.
+func (ps *ProductService) PlaceOrderLine(ctx context.Context, orderID string, line *pb.OrderLine) (bool, error) {
+	if err := validateOrderLine(line); err != nil {
+		return false, err
+	}
+
+	var quantity int32
+	var applied bool
+	err := ps.retryConflicts(ctx, "PlaceOrderLine", func(ctx context.Context) error {
+		applied = false
+		return ps.Tx.WithinTx(ctx, func(ctx context.Context) error {
+			placed, err := ps.OrderLines.Place(ctx, orderID, line)
+			if err != nil || !placed {
+				return err
+			}
+			quantity, err = ps.Stock.AdjustStock(ctx, line.GetProductId(), -line.GetQuantity(), repo.ReasonSold, orderLineReference(orderID, line))
+			applied = err == nil
+			return err
+		})
+	})
+	if err != nil {
+		return false, err
+	}
+	if applied {
+		ps.Events.StockChanged(line.GetProductId(), -line.GetQuantity(), quantity, repo.ReasonSold)
+	}
+	return applied, nil
+}
+
+// CancelOrderLine gives back the stock PlaceOrderLine took for line as
+// returned, once per line id, and reports whether it did. A line
+// cancelled before it is placed changes nothing, and its placement is
+// then ignored.
+func (ps *ProductService) CancelOrderLine(ctx context.Context, orderID string, line *pb.OrderLine) (bool, error) {
+	if err := validateOrderLine(line); err != nil {
+		return false, err
+	}
+
+	var placed *pb.OrderLine
+	var quantity int32
+	err := ps.retryConflicts(ctx, "CancelOrderLine", func(ctx context.Context) error {
+		return ps.Tx.WithinTx(ctx, func(ctx context.Context) error {
+			var err error
+			placed, err = ps.OrderLines.Cancel(ctx, orderID, line)
+			if err != nil || placed == nil {
+				return err
+			}
+			quantity, err = ps.Stock.AdjustStock(ctx, placed.GetProductId(), placed.GetQuantity(), repo.ReasonReturned, orderLineReference(orderID, placed))
+			return err
+		})
+	})
+	if err != nil || placed == nil {
+		return false, err
+	}
+	ps.Events.StockChanged(placed.GetProductId(), placed.GetQuantity(), quantity, repo.ReasonReturned)
+	return true, nil
+}
+
+// orderLineReference is the stock ledger reference of the movements of
+// line of the order orderID.
+func orderLineReference(orderID string, line *pb.OrderLine) string {
+	return "orders/" + orderID + "/lines/" + line.GetLineId()
+}
+
+func validateOrderLine(line *pb.OrderLine) error {
+	if line.GetLineId() == "" {
+		return inverr.InvalidField("line_id", "line_id is required")
+	}
+	if uuid.Validate(line.GetProductId()) != nil {
+		return inverr.InvalidField("product_id", "invalid product id: %q", line.GetProductId())
+	}
+	if line.GetQuantity() <= 0 {
+		return inverr.InvalidField("quantity", "quantity must be positive: %d", line.GetQuantity())
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/andro-kes/inventory_service/internal/repo"
+	pb "github.com/andro-kes/inventory_service/proto"
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// testOrderLines records order lines like the order_lines table.
+type testOrderLines struct {
+	lines  map[string]*pb.OrderLine
+	status map[string]string
+}
+
+func (r *testOrderLines) Place(ctx context.Context, orderID string, line *pb.OrderLine) (bool, error) {
+	if _, ok := r.status[line.GetLineId()]; ok {
+		return false, nil
+	}
+	r.lines[line.GetLineId()] = line
+	r.status[line.GetLineId()] = repo.OrderLinePlaced
+	return true, nil
+}
+
+func (r *testOrderLines) Cancel(ctx context.Context, orderID string, line *pb.OrderLine) (*pb.OrderLine, error) {
+	st, ok := r.status[line.GetLineId()]
+	r.status[line.GetLineId()] = repo.OrderLineCancelled
+	if !ok || st != repo.OrderLinePlaced {
+		return nil, nil
+	}
+	return r.lines[line.GetLineId()], nil
+}
+
+// testStock keeps quantities and refuses to go below zero.
+type testStock struct {
+	repo.StockRepo
+	quantity map[string]int32
+	// references are the references of the movements.
+	references []string
+}
+
+func (s *testStock) AdjustStock(ctx context.Context, productID string, delta int32, reason, reference string) (int32, error) {
+	q := s.quantity[productID] + delta
+	if q < 0 {
+		return 0, &repo.InsufficientStockError{ID: productID, Requested: -delta, Available: s.quantity[productID]}
+	}
+	s.quantity[productID] = q
+	s.references = append(s.references, reference)
+	return q, nil
+}
+
+func TestOrderLines(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	id := uuid.NewString()
+	stock := &testStock{quantity: map[string]int32{id: 5}}
+	s := NewTestService(nil)
+	s.Tx = repo.NewTxManager(mock)
+	s.Stock = stock
+	s.OrderLines = &testOrderLines{lines: make(map[string]*pb.OrderLine), status: make(map[string]string)}
+	ctx := t.Context()
+
+	_, err = s.PlaceOrderLine(ctx, "o1", &pb.OrderLine{LineId: "l1", ProductId: id})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// Lines are placed and cancelled once, whatever the redeliveries.
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	applied, err := s.PlaceOrderLine(ctx, "o1", &pb.OrderLine{LineId: "l1", ProductId: id, Quantity: 2})
+	require.NoError(t, err)
+	assert.True(t, applied)
+	assert.Equal(t, int32(3), stock.quantity[id])
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	applied, err = s.PlaceOrderLine(ctx, "o1", &pb.OrderLine{LineId: "l1", ProductId: id, Quantity: 2})
+	require.NoError(t, err)
+	assert.False(t, applied)
+	assert.Equal(t, int32(3), stock.quantity[id])
+
+	// Without enough stock nothing is recorded.
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	_, err = s.PlaceOrderLine(ctx, "o1", &pb.OrderLine{LineId: "l2", ProductId: id, Quantity: 4})
+	var insufficient *repo.InsufficientStockError
+	assert.ErrorAs(t, err, &insufficient)
+
+	for _, want := range []bool{true, false} {
+		mock.ExpectBegin()
+		mock.ExpectCommit()
+		applied, err = s.CancelOrderLine(ctx, "o1", &pb.OrderLine{LineId: "l1", ProductId: id, Quantity: 2})
+		require.NoError(t, err)
+		assert.Equal(t, want, applied)
+		assert.Equal(t, int32(5), stock.quantity[id])
+	}
+
+	// A line cancelled before it is placed is never placed.
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	applied, err = s.CancelOrderLine(ctx, "o2", &pb.OrderLine{LineId: "l3", ProductId: id, Quantity: 1})
+	require.NoError(t, err)
+	assert.False(t, applied)
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	applied, err = s.PlaceOrderLine(ctx, "o2", &pb.OrderLine{LineId: "l3", ProductId: id, Quantity: 1})
+	require.NoError(t, err)
+	assert.False(t, applied)
+	assert.Equal(t, int32(5), stock.quantity[id])
+	assert.Equal(t, []string{"orders/o1/lines/l1", "orders/o1/lines/l1"}, stock.references)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
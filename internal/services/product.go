@@ -2,43 +2,728 @@ package services
 
 import (
 	"context"
+	"errors"
+	"slices"
+	"time"
 
+	"github.com/andro-kes/inventory_service/internal/events"
+	"github.com/andro-kes/inventory_service/internal/inverr"
 	"github.com/andro-kes/inventory_service/internal/repo"
 	pb "github.com/andro-kes/inventory_service/proto"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/text/language"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
 type ProductService struct {
 	Repo repo.ProductRepo
+	// Tx composes several repo calls into one transaction.
+	Tx *repo.TxManager
+	// Variants stores the variants of the products.
+	Variants repo.VariantRepo
+	// Stock adjusts quantities through the stock ledger.
+	Stock repo.StockRepo
+	// OrderLines records the order lines applied to the stock.
+	OrderLines repo.OrderLineRepo
+	// Tags resolves the tags of writes and filters to their registered
+	// names; nil only lowers them.
+	Tags repo.TagRepo
+	// Audit reads the audit trail of the products.
+	Audit repo.AuditRepo
+	// Reorder keeps the reorder suggestions.
+	Reorder repo.ReorderRepo
+	// Lots keeps the lots of the products.
+	Lots repo.LotRepo
+	// Events publishes the changes once they are committed; nil publishes
+	// nothing.
+	Events *events.Publisher
+	// ConflictRetry bounds the retries of stock mutations failing with
+	// conflicts of concurrent transactions.
+	ConflictRetry repo.RetryPolicy
+	// Currency converts prices to the currencies requested by the reads;
+	// nil rejects such reads.
+	Currency *Converter
+	// Hooks extend the creates, updates and deletes of products.
+	Hooks Hooks
+	// IdempotencyTTL is how long CreateIdempotent remembers a key;
+	// DefaultIdempotencyTTL if zero.
+	IdempotencyTTL time.Duration
 }
 
-func NewProductService(ctx context.Context, pool *pgxpool.Pool) *ProductService {
+func NewProductService(ctx context.Context, pool *pgxpool.Pool, opts ...repo.Option) *ProductService {
 	return &ProductService{
-		Repo: repo.NewProductRepo(ctx, pool),
+		Repo:     repo.NewProductRepo(ctx, pool, opts...),
+		Tx:       repo.NewTxManager(pool),
+		Variants: repo.NewVariantRepo(ctx, pool),
+		Stock:    repo.NewStockRepo(ctx, pool),
+
+		OrderLines:     repo.NewOrderLineRepo(ctx, pool),
+		Tags:           repo.NewTagRepo(ctx, pool),
+		Audit:          repo.NewAuditRepo(ctx, pool),
+		Reorder:        repo.NewReorderRepo(ctx, pool),
+		Lots:           repo.NewLotRepo(ctx, pool),
+		ConflictRetry:  DefaultConflictRetry,
+		IdempotencyTTL: DefaultIdempotencyTTL,
 	}
 }
 
 func (ps *ProductService) Create(ctx context.Context, p *pb.Product) (*pb.Product, error) {
+	p, err := ps.beforeCreate(ctx, p)
+	if err != nil {
+		return nil, err
+	}
 	id := uuid.NewString()
 	p.Id = id
 
-	return ps.Repo.Create(ctx, p)
+	created, err := ps.Repo.Create(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	ps.created(ctx, created)
+	return created, nil
+}
+
+// beforeCreate runs the before hooks of OpCreate on p, normalizes the
+// tags and validates the product they leave.
+func (ps *ProductService) beforeCreate(ctx context.Context, p *pb.Product) (*pb.Product, error) {
+	w := &Write{Op: OpCreate, Product: p}
+	if err := ps.Hooks.runBefore(ctx, w); err != nil {
+		return nil, err
+	}
+	tags, err := ps.normalizeTags(ctx, w.Product.GetTags())
+	if err != nil {
+		return nil, err
+	}
+	w.Product.Tags = tags
+	if err := validateProduct(w.Product); err != nil {
+		return nil, err
+	}
+	return w.Product, nil
+}
+
+// created publishes the creation of p and runs the after hooks.
+func (ps *ProductService) created(ctx context.Context, p *pb.Product) {
+	ps.Events.ProductCreated(p)
+	ps.Hooks.runAfter(ctx, OpCreate, p, nil)
+}
+
+// CreateIdempotent creates p once per key; retries with the same key
+// within IdempotencyTTL return the product created first. See
+// repo.ProductRepo.CreateIdempotent.
+func (ps *ProductService) CreateIdempotent(ctx context.Context, key string, p *pb.Product) (*pb.Product, error) {
+	p, err := ps.beforeCreate(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	p.Id = uuid.NewString()
+
+	ttl := ps.IdempotencyTTL
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+	created, err := ps.Repo.CreateIdempotent(ctx, key, ttl, p)
+	if err != nil {
+		return nil, err
+	}
+	// A retry publishes the event and runs the after hooks again;
+	// consumers see it twice, as with any redelivery.
+	ps.created(ctx, created)
+	return created, nil
+}
+
+func (ps *ProductService) CreateMany(ctx context.Context, products []*pb.Product) ([]*pb.Product, error) {
+	for i, p := range products {
+		p, err := ps.beforeCreate(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		p.Id = uuid.NewString()
+		products[i] = p
+	}
+
+	created, err := ps.Repo.CreateMany(ctx, products)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range created {
+		ps.created(ctx, p)
+	}
+	return created, nil
+}
+
+// Import bulk-loads products for catalog re-imports; see repo.ProductRepo.CopyFrom.
+// The events and after hooks see the products as given, once all are
+// copied.
+func (ps *ProductService) Import(ctx context.Context, products []*pb.Product, progress func(copied int64)) (int64, error) {
+	for i, p := range products {
+		p, err := ps.beforeCreate(ctx, p)
+		if err != nil {
+			return 0, err
+		}
+		p.Id = uuid.NewString()
+		products[i] = p
+	}
+
+	n, err := ps.Repo.CopyFrom(ctx, products, progress)
+	if err != nil {
+		return 0, err
+	}
+	for _, p := range products {
+		ps.created(ctx, p)
+	}
+	return n, nil
 }
 
 func (ps *ProductService) Delete(ctx context.Context, id string) error {
-	return ps.Repo.Delete(ctx, id)
+	if err := ps.Hooks.runBefore(ctx, &Write{Op: OpDelete, Product: &pb.Product{Id: id}}); err != nil {
+		return err
+	}
+	if err := ps.Repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	ps.Events.ProductDeleted(id)
+	ps.Hooks.runAfter(ctx, OpDelete, &pb.Product{Id: id}, nil)
+	return nil
+}
+
+// DeleteWhere deletes the products matching filter, publishing a
+// ProductDeleted and running the after hooks of OpDelete per product, and
+// returns how many were deleted. The products are only known once
+// deleted, so the before hooks run per product within the transaction of
+// the delete, after the statement: one failing rolls back the whole
+// delete.
+func (ps *ProductService) DeleteWhere(ctx context.Context, filter repo.ListFilter) (int64, error) {
+	filter, err := ps.normalizeFilter(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	var ids []string
+	if ps.Hooks.hasBefore(OpDelete) {
+		err = ps.Tx.WithinTx(ctx, func(ctx context.Context) error {
+			ids, err = ps.Repo.DeleteWhere(ctx, filter)
+			if err != nil {
+				return err
+			}
+			for _, id := range ids {
+				if err := ps.Hooks.runBefore(ctx, &Write{Op: OpDelete, Product: &pb.Product{Id: id}}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	} else {
+		ids, err = ps.Repo.DeleteWhere(ctx, filter)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	for _, id := range ids {
+		ps.Events.ProductDeleted(id)
+		ps.Hooks.runAfter(ctx, OpDelete, &pb.Product{Id: id}, nil)
+	}
+	return int64(len(ids)), nil
+}
+
+// Restore undeletes the product id. Consumers of the events dropped it on
+// ProductDeleted, so it is published as created again. It runs no hooks;
+// see OpDelete.
+func (ps *ProductService) Restore(ctx context.Context, id string) error {
+	p, err := ps.Repo.Restore(ctx, id)
+	if err != nil {
+		return err
+	}
+	ps.Events.ProductCreated(p)
+	return nil
 }
 
-func (ps *ProductService) List(ctx context.Context, prevSize, pageSize int32, filter, orderBy string) ([]*pb.Product, error) {
+// Purge physically removes the products deleted more than olderThan ago.
+// It runs no hooks; see OpDelete.
+func (ps *ProductService) Purge(ctx context.Context, olderThan time.Duration) (int64, error) {
+	return ps.Repo.Purge(ctx, olderThan)
+}
+
+func (ps *ProductService) List(ctx context.Context, prevSize, pageSize int32, filter repo.ListFilter, orderBy string) ([]*pb.Product, error) {
+	filter, err := ps.normalizeFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
 	return ps.Repo.List(ctx, prevSize, pageSize, filter, orderBy)
 }
 
+func (ps *ProductService) ListAfter(ctx context.Context, cursor string, pageSize int32, filter repo.ListFilter, orderBy string) ([]*pb.Product, string, error) {
+	filter, err := ps.normalizeFilter(ctx, filter)
+	if err != nil {
+		return nil, "", err
+	}
+	return ps.Repo.ListAfter(ctx, cursor, pageSize, filter, orderBy)
+}
+
+func (ps *ProductService) Count(ctx context.Context, filter repo.ListFilter) (int64, error) {
+	filter, err := ps.normalizeFilter(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return ps.Repo.Count(ctx, filter)
+}
+
+// Update applies the fields of p in mask. A change of status, also
+// through the deprecated available, is checked against the current status
+// of the product, locked until the update; see repo.CheckTransition.
 func (ps *ProductService) Update(ctx context.Context, p *pb.Product, mask *fieldmaskpb.FieldMask) (*pb.Product, error) {
-	return ps.Repo.Update(ctx, p, mask)
+	updated, mask, err := ps.update(ctx, p, mask)
+	if err != nil {
+		return nil, err
+	}
+	ps.updated(ctx, updated, mask)
+	return updated, nil
+}
+
+// updated publishes the update of the fields in mask of p and runs the
+// after hooks.
+func (ps *ProductService) updated(ctx context.Context, p *pb.Product, mask *fieldmaskpb.FieldMask) {
+	ps.Events.ProductUpdated(p, mask.GetPaths())
+	ps.Hooks.runAfter(ctx, OpUpdate, p, mask)
+}
+
+// update is Update without the event and the after hooks, for updates in
+// a transaction that has yet to commit. It returns the mask as left by
+// the before hooks.
+func (ps *ProductService) update(ctx context.Context, p *pb.Product, mask *fieldmaskpb.FieldMask) (*pb.Product, *fieldmaskpb.FieldMask, error) {
+	w := &Write{Op: OpUpdate, Product: p, Mask: mask}
+	if err := ps.Hooks.runBefore(ctx, w); err != nil {
+		return nil, nil, err
+	}
+	if len(w.Mask.GetPaths()) == 0 || slices.Contains(w.Mask.GetPaths(), "tags") {
+		tags, err := ps.normalizeTags(ctx, w.Product.GetTags())
+		if err != nil {
+			return nil, nil, err
+		}
+		w.Product.Tags = tags
+	}
+	updated, err := ps.updateProduct(ctx, w.Product, w.Mask)
+	if err != nil {
+		return nil, nil, err
+	}
+	return updated, w.Mask, nil
+}
+
+// updateProduct validates and stores the update of the fields of p in
+// mask.
+func (ps *ProductService) updateProduct(ctx context.Context, p *pb.Product, mask *fieldmaskpb.FieldMask) (*pb.Product, error) {
+	if len(mask.GetPaths()) > 0 {
+		if err := validateProduct(p, mask.GetPaths()...); err != nil {
+			return nil, err
+		}
+	}
+	if !slices.ContainsFunc(mask.GetPaths(), isStatusPath) {
+		return ps.Repo.Update(ctx, p, mask)
+	}
+	if slices.Contains(mask.GetPaths(), "status") && p.GetStatus() == pb.Product_STATUS_UNSPECIFIED {
+		return nil, inverr.InvalidField("status", "status is required")
+	}
+
+	var updated *pb.Product
+	err := ps.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		current, err := ps.Repo.Get(repo.WithRowLock(ctx), p.GetId())
+		if errors.Is(err, pgx.ErrNoRows) {
+			return status.Errorf(codes.NotFound, "product not found: %s", p.GetId())
+		}
+		if err != nil {
+			return err
+		}
+
+		next := proto.Clone(p).(*pb.Product)
+		next.Status = repo.StatusOf(p)
+		if p.GetStatus() == pb.Product_STATUS_UNSPECIFIED && !p.GetAvailable() {
+			// available=false takes an active product off sale and leaves
+			// other products as they are.
+			next.Status = current.GetStatus()
+			if next.Status == pb.Product_ACTIVE {
+				next.Status = pb.Product_ARCHIVED
+			}
+		}
+		if err := repo.CheckTransition(current.GetStatus(), next.Status); err != nil {
+			return err
+		}
+
+		updated, err = ps.Repo.Update(ctx, next, mask)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// isStatusPath reports whether an update_mask path sets the status.
+func isStatusPath(path string) bool {
+	return path == "status" || path == "available"
+}
+
+// ProductUpdate is one update of BatchUpdate: the fields of Product in
+// Mask, as for Update.
+type ProductUpdate struct {
+	Product *pb.Product
+	Mask    *fieldmaskpb.FieldMask
+}
+
+// UpdateResult is the outcome of one update of BatchUpdate: the updated
+// product, or the error the update failed with.
+type UpdateResult struct {
+	Product *pb.Product
+	Err     error
+}
+
+// errBatchFailed rolls back a BatchUpdate with failed updates.
+var errBatchFailed = errors.New("batch update failed")
+
+// BatchUpdate applies updates in one transaction and reports whether they
+// were committed. Every update is tried, each in its own savepoint, so the
+// results name all failing updates; if any fails none is applied and the
+// products of the results are unset. The error is for failures of the
+// transaction itself.
+func (ps *ProductService) BatchUpdate(ctx context.Context, updates []ProductUpdate) ([]UpdateResult, bool, error) {
+	var results []UpdateResult
+	var masks []*fieldmaskpb.FieldMask
+	err := ps.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		results = make([]UpdateResult, len(updates))
+		masks = make([]*fieldmaskpb.FieldMask, len(updates))
+		failed := false
+		for i, u := range updates {
+			results[i].Product, masks[i], results[i].Err = ps.update(ctx, u.Product, u.Mask)
+			failed = failed || results[i].Err != nil
+		}
+		if failed {
+			return errBatchFailed
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, errBatchFailed):
+		for i := range results {
+			results[i].Product = nil
+		}
+		return results, false, nil
+	case err != nil:
+		return nil, false, err
+	}
+	for i, r := range results {
+		ps.updated(ctx, r.Product, masks[i])
+	}
+	return results, true, nil
 }
 
 func (ps *ProductService) Get(ctx context.Context, id string) (*pb.Product, error) {
 	return ps.Repo.Get(ctx, id)
 }
+
+func (ps *ProductService) GetBySKU(ctx context.Context, sku string) (*pb.Product, error) {
+	return ps.Repo.GetBySKU(ctx, sku)
+}
+
+func (ps *ProductService) GetByBarcode(ctx context.Context, barcode string) (*pb.Product, error) {
+	return ps.Repo.GetByBarcode(ctx, barcode)
+}
+
+// UpsertBySKU creates or updates the product with p's SKU, e.g. from a
+// supplier feed. New products get a fresh id. It runs the hooks of
+// OpCreate either way.
+func (ps *ProductService) UpsertBySKU(ctx context.Context, p *pb.Product) (*pb.Product, error) {
+	p, err := ps.beforeCreate(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	p.Id = uuid.NewString()
+
+	upserted, err := ps.Repo.UpsertBySKU(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	ps.upserted(ctx, p.Id, upserted)
+	return upserted, nil
+}
+
+// upsertPaths are the Product fields an upsert overwrites on an existing
+// product; see repo.ProductRepo.UpsertBySKU.
+var upsertPaths = []string{
+	"name", "description", "price", "price_money", "quantity", "tags", "attributes", "category_id",
+}
+
+// upserted publishes p, stored by an upsert given the fresh id newID, as
+// created if it kept newID and as updated otherwise, and runs the after
+// hooks of OpCreate.
+func (ps *ProductService) upserted(ctx context.Context, newID string, p *pb.Product) {
+	if p.GetId() == newID {
+		ps.Events.ProductCreated(p)
+	} else {
+		ps.Events.ProductUpdated(p, upsertPaths)
+	}
+	ps.Hooks.runAfter(ctx, OpCreate, p, nil)
+}
+
+// ListAll calls fn for every product matching filter without loading
+// them all into memory, e.g. for exports.
+func (ps *ProductService) ListAll(ctx context.Context, filter repo.ListFilter, fn func(*pb.Product) error) error {
+	filter, err := ps.normalizeFilter(ctx, filter)
+	if err != nil {
+		return err
+	}
+	return ps.Repo.ListAll(ctx, filter, fn)
+}
+
+// ListAuditEntries returns a page of the audit trail of the products,
+// newest first; see repo.AuditRepo.List.
+func (ps *ProductService) ListAuditEntries(ctx context.Context, filter repo.AuditFilter, pageToken string, pageSize int32) ([]*repo.AuditEntry, string, error) {
+	return ps.Audit.List(ctx, filter, pageToken, pageSize)
+}
+
+func (ps *ProductService) DecrementQuantity(ctx context.Context, id string, delta int32) (int32, error) {
+	var remaining int32
+	err := ps.retryConflicts(ctx, "DecrementQuantity", func(ctx context.Context) error {
+		var err error
+		remaining, err = ps.Repo.DecrementQuantity(ctx, id, delta)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	ps.Events.StockChanged(id, -delta, remaining, repo.ReasonDecrement)
+	return remaining, nil
+}
+
+// AdjustStock changes the quantity of the product by delta for reason and
+// records the movement with reference; see repo.StockRepo.
+func (ps *ProductService) AdjustStock(ctx context.Context, id string, delta int32, reason, reference string) (int32, error) {
+	var quantity int32
+	err := ps.retryConflicts(ctx, "AdjustStock", func(ctx context.Context) error {
+		var err error
+		quantity, err = ps.Stock.AdjustStock(ctx, id, delta, reason, reference)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	ps.Events.StockChanged(id, delta, quantity, reason)
+	return quantity, nil
+}
+
+// RefreshReorderSuggestions recomputes the reorder suggestions with
+// policy and returns their number; see repo.ReorderRepo.Refresh.
+func (ps *ProductService) RefreshReorderSuggestions(ctx context.Context, policy repo.ReorderPolicy) (int64, error) {
+	return ps.Reorder.Refresh(ctx, policy)
+}
+
+// ListReorderSuggestions returns a page of the reorder suggestions, the
+// soonest to run out first; see repo.ReorderRepo.List.
+func (ps *ProductService) ListReorderSuggestions(ctx context.Context, pageToken string, pageSize int32) ([]*repo.ReorderSuggestion, string, error) {
+	return ps.Reorder.List(ctx, pageToken, pageSize)
+}
+
+// ReceiveLot books l.Quantity received units of l.ProductID to its lot
+// l.Number and returns the lot and the quantity of the product; see
+// repo.LotRepo.Receive.
+func (ps *ProductService) ReceiveLot(ctx context.Context, l *repo.Lot, reference string) (*repo.Lot, int32, error) {
+	l.ID = uuid.NewString()
+	var (
+		lot      *repo.Lot
+		quantity int32
+	)
+	err := ps.retryConflicts(ctx, "ReceiveLot", func(ctx context.Context) error {
+		var err error
+		lot, quantity, err = ps.Lots.Receive(ctx, l, reference)
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	ps.Events.StockChanged(l.ProductID, l.Quantity, quantity, repo.ReasonReceived)
+	return lot, quantity, nil
+}
+
+// ListExpiringLots returns a page of the lots with units left expiring
+// within days from today (UTC), of productID unless it is empty, the
+// soonest first; see repo.LotRepo.Expiring.
+func (ps *ProductService) ListExpiringLots(ctx context.Context, days int32, productID, pageToken string, pageSize int32) ([]*repo.Lot, string, error) {
+	before := time.Now().UTC().AddDate(0, 0, int(days))
+	return ps.Lots.Expiring(ctx, before, productID, pageToken, pageSize)
+}
+
+// SnapshotStock records the quantity of every product for later
+// reconciliation; see repo.StockRepo.Snapshot.
+func (ps *ProductService) SnapshotStock(ctx context.Context) (time.Time, int64, error) {
+	return ps.Stock.Snapshot(ctx)
+}
+
+// ReserveStock holds quantity units of a product for orderID for ttl.
+func (ps *ProductService) ReserveStock(ctx context.Context, productID, orderID string, quantity int32, ttl time.Duration) (*repo.Reservation, error) {
+	r := &repo.Reservation{
+		ID:        uuid.NewString(),
+		ProductID: productID,
+		OrderID:   orderID,
+		Quantity:  quantity,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	var reserved *repo.Reservation
+	err := ps.retryConflicts(ctx, "ReserveStock", func(ctx context.Context) error {
+		var err error
+		reserved, err = ps.Repo.ReserveStock(ctx, r)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reserved, nil
+}
+
+// StockOf returns the stock of the products ids; see repo.ProductRepo.
+func (ps *ProductService) StockOf(ctx context.Context, ids []string) (map[string]repo.Stock, error) {
+	return ps.Repo.StockOf(ctx, ids)
+}
+
+// Localize fills the translations of products and, for the preferred
+// locales prefs, e.g. from Accept-Language, replaces their name and
+// description with the best matching translation. Products without a
+// matching translation keep their own texts. Fields outside the read
+// mask of ctx are left unset.
+func (ps *ProductService) Localize(ctx context.Context, prefs []language.Tag, products ...*pb.Product) error {
+	if len(products) == 0 || (len(prefs) == 0 && !repo.ReadsField(ctx, "translations")) {
+		return nil
+	}
+
+	ids := make([]string, len(products))
+	for i, p := range products {
+		ids[i] = p.GetId()
+	}
+	translations, err := ps.Repo.Translations(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range products {
+		texts := translations[p.GetId()]
+		if repo.ReadsField(ctx, "translations") {
+			p.Translations = texts
+		}
+		if t := bestTranslation(texts, prefs); t != nil {
+			if repo.ReadsField(ctx, "name") {
+				p.Name = t.GetName()
+			}
+			if repo.ReadsField(ctx, "description") {
+				p.Description = t.GetDescription()
+			}
+		}
+	}
+	return nil
+}
+
+// bestTranslation returns the translation in texts that best matches
+// prefs, e.g. "de" for "de-AT", or nil if none does.
+func bestTranslation(texts []*pb.LocalizedText, prefs []language.Tag) *pb.LocalizedText {
+	if len(texts) == 0 || len(prefs) == 0 {
+		return nil
+	}
+	// The product's own texts come first, as the fallback of the matcher.
+	tags := []language.Tag{language.Und}
+	for _, t := range texts {
+		tags = append(tags, language.Make(t.GetLocale()))
+	}
+	_, i, confidence := language.NewMatcher(tags).Match(prefs...)
+	if i == 0 || confidence == language.No {
+		return nil
+	}
+	return texts[i-1]
+}
+
+// CreateVariant adds v to its product with a fresh id.
+func (ps *ProductService) CreateVariant(ctx context.Context, v *pb.Variant) (*pb.Variant, error) {
+	if v == nil {
+		return nil, inverr.InvalidField("variant", "variant is required")
+	}
+	v.Id = uuid.NewString()
+
+	return ps.Variants.Create(ctx, v)
+}
+
+func (ps *ProductService) GetVariant(ctx context.Context, id string) (*pb.Variant, error) {
+	return ps.Variants.Get(ctx, id)
+}
+
+func (ps *ProductService) UpdateVariant(ctx context.Context, v *pb.Variant, mask *fieldmaskpb.FieldMask) (*pb.Variant, error) {
+	return ps.Variants.Update(ctx, v, mask)
+}
+
+func (ps *ProductService) DeleteVariant(ctx context.Context, id string) error {
+	return ps.Variants.Delete(ctx, id)
+}
+
+// ListVariants returns the variants of the product id, NotFound if the
+// product does not exist.
+func (ps *ProductService) ListVariants(ctx context.Context, id string) ([]*pb.Variant, error) {
+	if _, err := ps.Repo.Get(ctx, id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "product not found: %s", id)
+		}
+		return nil, err
+	}
+	variants, err := ps.Variants.ListByProducts(ctx, []string{id})
+	if err != nil {
+		return nil, err
+	}
+	return variants[id], nil
+}
+
+// ExpandVariants fills the variants of products, loading them in one
+// call, unless the read mask of ctx leaves them out.
+func (ps *ProductService) ExpandVariants(ctx context.Context, products ...*pb.Product) error {
+	if len(products) == 0 || !repo.ReadsField(ctx, "variants") {
+		return nil
+	}
+
+	ids := make([]string, len(products))
+	for i, p := range products {
+		ids[i] = p.GetId()
+	}
+	variants, err := ps.Variants.ListByProducts(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, p := range products {
+		p.Variants = variants[p.GetId()]
+	}
+	return nil
+}
+
+func (ps *ProductService) GetReservation(ctx context.Context, id string) (*repo.Reservation, error) {
+	return ps.Repo.GetReservation(ctx, id)
+}
+
+// ConfirmReservation takes the units of the reservation id out of stock;
+// conflicts are retried as for the other stock mutations. Confirming a
+// confirmed reservation publishes no StockChanged again.
+func (ps *ProductService) ConfirmReservation(ctx context.Context, id string) (*repo.Reservation, error) {
+	var confirmed *repo.Reservation
+	var quantity int32
+	err := ps.retryConflicts(ctx, "ConfirmReservation", func(ctx context.Context) error {
+		var err error
+		confirmed, quantity, err = ps.Repo.ConfirmReservation(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if quantity >= 0 {
+		ps.Events.StockChanged(confirmed.ProductID, -confirmed.Quantity, quantity, repo.ReasonReservation)
+	}
+	return confirmed, nil
+}
+
+func (ps *ProductService) ReleaseReservation(ctx context.Context, id string) (*repo.Reservation, error) {
+	return ps.Repo.ReleaseReservation(ctx, id)
+}
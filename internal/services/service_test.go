@@ -2,18 +2,36 @@ package services
 
 import (
 	"context"
+	"maps"
+	"slices"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/andro-kes/inventory_service/internal/events"
+	"github.com/andro-kes/inventory_service/internal/repo"
 	pb "github.com/andro-kes/inventory_service/proto"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v4"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/fieldmaskpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type TestRepo struct {
 	Storage map[string]any
+	// Keys maps idempotency keys to product ids.
+	Keys map[string]string
+	// KeyExpiry maps idempotency keys to when they expire.
+	KeyExpiry map[string]time.Time
+	// Reservations maps reservation ids to reservations.
+	Reservations map[string]*repo.Reservation
 	Err error
 }
 
@@ -26,6 +44,65 @@ func (r *TestRepo) Create(ctx context.Context, p *pb.Product) (*pb.Product, erro
 	return p, nil
 }
 
+func (r *TestRepo) CreateIdempotent(ctx context.Context, key string, ttl time.Duration, p *pb.Product) (*pb.Product, error) {
+	if key == "" {
+		return r.Create(ctx, p)
+	}
+	if id, ok := r.Keys[key]; ok && time.Now().Before(r.KeyExpiry[key]) {
+		return r.Get(ctx, id)
+	}
+
+	created, err := r.Create(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	if r.Keys == nil {
+		r.Keys = make(map[string]string)
+		r.KeyExpiry = make(map[string]time.Time)
+	}
+	r.Keys[key] = created.GetId()
+	r.KeyExpiry[key] = time.Now().Add(ttl)
+	return created, nil
+}
+
+func (r *TestRepo) PurgeIdempotencyKeys(ctx context.Context, limit int) (int64, error) {
+	if r.Err != nil {
+		return 0, r.Err
+	}
+
+	var n int64
+	for key, expiry := range r.KeyExpiry {
+		if n < int64(limit) && !time.Now().Before(expiry) {
+			delete(r.Keys, key)
+			delete(r.KeyExpiry, key)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *TestRepo) CreateMany(ctx context.Context, ps []*pb.Product) ([]*pb.Product, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+
+	for _, p := range ps {
+		r.Storage[p.Id] = p
+	}
+	return ps, nil
+}
+
+func (r *TestRepo) CopyFrom(ctx context.Context, ps []*pb.Product, progress func(copied int64)) (int64, error) {
+	created, err := r.CreateMany(ctx, ps)
+	if err != nil {
+		return 0, err
+	}
+	if progress != nil {
+		progress(int64(len(created)))
+	}
+	return int64(len(created)), nil
+}
+
 func (r *TestRepo) Delete(ctx context.Context, id string) error {
 	if r.Err != nil {
 		return r.Err
@@ -39,6 +116,42 @@ func (r *TestRepo) Delete(ctx context.Context, id string) error {
 	}
 }
 
+// DeleteWhere deletes the products having all filter.Tags.
+func (r *TestRepo) DeleteWhere(ctx context.Context, filter repo.ListFilter) ([]string, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+
+	var deleted []string
+	for id, v := range r.Storage {
+		if p := v.(*pb.Product); hasTags(p, filter.Tags) {
+			delete(r.Storage, id)
+			deleted = append(deleted, id)
+		}
+	}
+	return deleted, nil
+}
+
+func hasTags(p *pb.Product, tags []string) bool {
+	for _, tag := range tags {
+		if !slices.Contains(p.GetTags(), tag) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *TestRepo) Restore(ctx context.Context, id string) (*pb.Product, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	return &pb.Product{Id: id}, nil
+}
+
+func (r *TestRepo) Purge(ctx context.Context, olderThan time.Duration) (int64, error) {
+	return 0, r.Err
+}
+
 func (r *TestRepo) Get(ctx context.Context, id string) (*pb.Product, error) {
 	if r.Err != nil {
 		return nil, r.Err
@@ -51,8 +164,62 @@ func (r *TestRepo) Get(ctx context.Context, id string) (*pb.Product, error) {
 	return r.Storage[id].(*pb.Product), nil
 }
 
+func (r *TestRepo) UpsertBySKU(ctx context.Context, p *pb.Product) (*pb.Product, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+
+	if existing, err := r.GetBySKU(ctx, p.GetSku()); err == nil {
+		existing.Name = p.Name
+		existing.Price = p.Price
+		existing.Quantity = p.Quantity
+		return existing, nil
+	}
+	r.Storage[p.Id] = p
+	return p, nil
+}
+
+func (r *TestRepo) ListAll(ctx context.Context, filter repo.ListFilter, fn func(*pb.Product) error) error {
+	if r.Err != nil {
+		return r.Err
+	}
+
+	for _, v := range r.Storage {
+		if err := fn(v.(*pb.Product)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *TestRepo) GetBySKU(ctx context.Context, sku string) (*pb.Product, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+
+	for _, v := range r.Storage {
+		if p := v.(*pb.Product); p.GetSku() == sku {
+			return p, nil
+		}
+	}
+	return nil, assert.AnError
+}
+
+func (r *TestRepo) GetByBarcode(ctx context.Context, barcode string) (*pb.Product, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+
+	for _, v := range r.Storage {
+		if p := v.(*pb.Product); p.GetBarcode() == barcode {
+			return p, nil
+		}
+	}
+	return nil, assert.AnError
+}
+
 // Пока не тестируем фильтры
-func (r *TestRepo) List(ctx context.Context, prevSize, pageSize int32, filter, orderBy string) ([]*pb.Product, error) {
+func (r *TestRepo) List(ctx context.Context, prevSize, pageSize int32, filter repo.ListFilter, orderBy string) ([]*pb.Product, error) {
 	if r.Err != nil {
 		return nil, r.Err
 	}
@@ -69,6 +236,19 @@ func (r *TestRepo) List(ctx context.Context, prevSize, pageSize int32, filter, o
 	return p, nil
 }
 
+func (r *TestRepo) ListAfter(ctx context.Context, cursor string, pageSize int32, filter repo.ListFilter, orderBy string) ([]*pb.Product, string, error) {
+	p, err := r.List(ctx, 0, pageSize, filter, "")
+	return p, "", err
+}
+
+func (r *TestRepo) Count(ctx context.Context, filter repo.ListFilter) (int64, error) {
+	if r.Err != nil {
+		return 0, r.Err
+	}
+
+	return int64(len(r.Storage)), nil
+}
+
 func (r *TestRepo) Update(ctx context.Context, p *pb.Product, mask *fieldmaskpb.FieldMask) (*pb.Product, error) {
 	if r.Err != nil {
 		return nil, r.Err
@@ -80,6 +260,10 @@ func (r *TestRepo) Update(ctx context.Context, p *pb.Product, mask *fieldmaskpb.
 			r.Storage[p.Id] = p
 		case mask.Paths[0] == "name":
 			r.Storage[p.Id].(*pb.Product).Name = p.Name
+		case mask.Paths[0] == "status" || mask.Paths[0] == "available":
+			r.Storage[p.Id].(*pb.Product).Status = p.Status
+		case mask.Paths[0] == "tags":
+			r.Storage[p.Id].(*pb.Product).Tags = p.Tags
 		}
 		return p, nil
 	}
@@ -87,6 +271,178 @@ func (r *TestRepo) Update(ctx context.Context, p *pb.Product, mask *fieldmaskpb.
 	return nil, assert.AnError
 }
 
+func (r *TestRepo) DecrementQuantity(ctx context.Context, id string, delta int32) (int32, error) {
+	if r.Err != nil {
+		return 0, r.Err
+	}
+
+	p, ok := r.Storage[id].(*pb.Product)
+	if !ok {
+		return 0, assert.AnError
+	}
+	if p.Quantity < delta {
+		return 0, &repo.InsufficientStockError{ID: id, Requested: delta, Available: p.Quantity}
+	}
+
+	p.Quantity -= delta
+	return p.Quantity, nil
+}
+
+func (r *TestRepo) ReserveStock(ctx context.Context, res *repo.Reservation) (*repo.Reservation, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+
+	p, ok := r.Storage[res.ProductID].(*pb.Product)
+	if !ok {
+		return nil, assert.AnError
+	}
+	if p.Quantity < res.Quantity {
+		return nil, &repo.InsufficientStockError{ID: res.ProductID, Requested: res.Quantity, Available: p.Quantity}
+	}
+
+	if r.Reservations == nil {
+		r.Reservations = make(map[string]*repo.Reservation)
+	}
+	res.Status = repo.ReservationActive
+	r.Reservations[res.ID] = res
+	return res, nil
+}
+
+func (r *TestRepo) GetReservation(ctx context.Context, id string) (*repo.Reservation, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+
+	res, ok := r.Reservations[id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "reservation not found: %s", id)
+	}
+	return res, nil
+}
+
+func (r *TestRepo) ConfirmReservation(ctx context.Context, id string) (*repo.Reservation, int32, error) {
+	res, err := r.GetReservation(ctx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	switch {
+	case res.Status == repo.ReservationConfirmed:
+		return res, -1, nil
+	case res.Status == repo.ReservationReleased, res.Expired(time.Now()):
+		return nil, 0, status.Errorf(codes.FailedPrecondition, "reservation %s is not active", id)
+	}
+
+	quantity, err := r.DecrementQuantity(ctx, res.ProductID, res.Quantity)
+	if err != nil {
+		return nil, 0, err
+	}
+	res.Status = repo.ReservationConfirmed
+	return res, quantity, nil
+}
+
+func (r *TestRepo) ReleaseReservation(ctx context.Context, id string) (*repo.Reservation, error) {
+	res, err := r.GetReservation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	switch res.Status {
+	case repo.ReservationConfirmed:
+		return nil, status.Errorf(codes.FailedPrecondition, "reservation %s was confirmed", id)
+	case repo.ReservationActive:
+		res.Status = repo.ReservationReleased
+	}
+	return res, nil
+}
+
+func (r *TestRepo) ReleaseExpired(ctx context.Context, limit int) (int64, error) {
+	if r.Err != nil {
+		return 0, r.Err
+	}
+
+	var n int64
+	for _, res := range r.Reservations {
+		if n < int64(limit) && res.Status == repo.ReservationActive && res.Expired(time.Now()) {
+			res.Status = repo.ReservationExpired
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *TestRepo) StockOf(ctx context.Context, ids []string) (map[string]repo.Stock, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+
+	stock := make(map[string]repo.Stock)
+	for _, id := range ids {
+		p, ok := r.Storage[id].(*pb.Product)
+		if !ok {
+			continue
+		}
+		free := p.Quantity
+		for _, res := range r.Reservations {
+			if res.ProductID == id && res.Status == repo.ReservationActive && !res.Expired(time.Now()) {
+				free -= res.Quantity
+			}
+		}
+		stock[id] = repo.Stock{Available: p.Available, Free: free}
+	}
+	return stock, nil
+}
+
+func (r *TestRepo) Translations(ctx context.Context, ids []string) (map[string][]*pb.LocalizedText, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+
+	translations := make(map[string][]*pb.LocalizedText)
+	for _, id := range ids {
+		if p, ok := r.Storage[id].(*pb.Product); ok && len(p.Translations) > 0 {
+			translations[id] = p.Translations
+		}
+	}
+	return translations, nil
+}
+
+// TestVariantRepo keeps variants in memory by id.
+type TestVariantRepo struct {
+	Storage map[string]*pb.Variant
+}
+
+func (r *TestVariantRepo) Create(ctx context.Context, v *pb.Variant) (*pb.Variant, error) {
+	r.Storage[v.Id] = v
+	return v, nil
+}
+
+func (r *TestVariantRepo) Get(ctx context.Context, id string) (*pb.Variant, error) {
+	if v, ok := r.Storage[id]; ok {
+		return v, nil
+	}
+	return nil, status.Errorf(codes.NotFound, "variant not found: %s", id)
+}
+
+func (r *TestVariantRepo) Update(ctx context.Context, v *pb.Variant, mask *fieldmaskpb.FieldMask) (*pb.Variant, error) {
+	r.Storage[v.Id] = v
+	return v, nil
+}
+
+func (r *TestVariantRepo) Delete(ctx context.Context, id string) error {
+	delete(r.Storage, id)
+	return nil
+}
+
+func (r *TestVariantRepo) ListByProducts(ctx context.Context, ids []string) (map[string][]*pb.Variant, error) {
+	variants := make(map[string][]*pb.Variant)
+	for _, v := range r.Storage {
+		if slices.Contains(ids, v.ProductId) {
+			variants[v.ProductId] = append(variants[v.ProductId], v)
+		}
+	}
+	return variants, nil
+}
+
 func NewTestService(err error) *ProductService {
 	repo := &TestRepo{
 		Storage: make(map[string]any),
@@ -94,7 +450,8 @@ func NewTestService(err error) *ProductService {
 	}
 
 	return &ProductService{
-		Repo: repo,
+		Repo:     repo,
+		Variants: &TestVariantRepo{Storage: make(map[string]*pb.Variant)},
 	}
 }
 
@@ -123,6 +480,42 @@ func TestCreate(t *testing.T) {
 	assert.Equal(t, p.Quantity, testProduct.Quantity)
 }
 
+func TestCreateIdempotent(t *testing.T) {
+	service := NewTestService(nil)
+
+	first, err := service.CreateIdempotent(t.Context(), "req-1", &pb.Product{Name: "Laptop"})
+	assert.NoError(t, err)
+	retried, err := service.CreateIdempotent(t.Context(), "req-1", &pb.Product{Name: "Laptop"})
+	assert.NoError(t, err)
+	other, err := service.CreateIdempotent(t.Context(), "req-2", &pb.Product{Name: "Laptop"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, first.Id, retried.Id)
+	assert.NotEqual(t, first.Id, other.Id)
+}
+
+func TestPurgeIdempotencyKeys(t *testing.T) {
+	service := NewTestService(nil)
+	testRepo := service.Repo.(*TestRepo)
+
+	first, err := service.CreateIdempotent(t.Context(), "req-1", &pb.Product{Name: "Laptop"})
+	assert.NoError(t, err)
+	testRepo.KeyExpiry["req-1"] = time.Now().Add(-time.Second)
+	_, err = service.CreateIdempotent(t.Context(), "req-2", &pb.Product{Name: "Laptop"})
+	assert.NoError(t, err)
+
+	// An expired key is taken anew.
+	retried, err := service.CreateIdempotent(t.Context(), "req-1", &pb.Product{Name: "Laptop"})
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.Id, retried.Id)
+
+	testRepo.KeyExpiry["req-1"] = time.Now().Add(-time.Second)
+	n, err := service.PurgeIdempotencyKeys(t.Context(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	assert.Equal(t, []string{"req-2"}, slices.Collect(maps.Keys(testRepo.Keys)))
+}
+
 func TestCreateDelete(t *testing.T) {
 	service := NewTestService(nil)
 
@@ -133,6 +526,24 @@ func TestCreateDelete(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestDeleteWhere(t *testing.T) {
+	service := NewTestService(nil)
+
+	_, err := service.CreateMany(t.Context(), []*pb.Product{
+		{Name: "a", Tags: []string{"discontinued"}},
+		{Name: "b"},
+	})
+	assert.NoError(t, err)
+
+	deleted, err := service.DeleteWhere(t.Context(), repo.ListFilter{Tags: []string{"discontinued"}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	total, err := service.Count(t.Context(), repo.ListFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+}
+
 func TestCreateGet(t *testing.T) {
 	service := NewTestService(nil) 
 
@@ -149,6 +560,49 @@ func TestCreateGet(t *testing.T) {
 	assert.Equal(t, p.Quantity, testProduct.Quantity)
 }
 
+func TestGetBySKU(t *testing.T) {
+	service := NewTestService(nil)
+
+	p, err := service.Create(t.Context(), &pb.Product{Name: "test", Sku: "SKU-1"})
+	assert.NoError(t, err)
+
+	got, err := service.GetBySKU(t.Context(), "SKU-1")
+	assert.NoError(t, err)
+	assert.Equal(t, p.Id, got.Id)
+
+	_, err = service.GetBySKU(t.Context(), "SKU-2")
+	assert.Error(t, err)
+}
+
+func TestUpsertBySKU(t *testing.T) {
+	service := NewTestService(nil)
+
+	created, err := service.UpsertBySKU(t.Context(), &pb.Product{Name: "feed", Sku: "SKU-1", Price: 10})
+	assert.NoError(t, err)
+
+	updated, err := service.UpsertBySKU(t.Context(), &pb.Product{Name: "feed", Sku: "SKU-1", Price: 12})
+	assert.NoError(t, err)
+	assert.Equal(t, created.Id, updated.Id)
+	assert.Equal(t, 12.0, updated.Price)
+}
+
+func TestListAll(t *testing.T) {
+	service := NewTestService(nil)
+
+	for range 3 {
+		_, err := service.Create(t.Context(), &pb.Product{Name: "test"})
+		assert.NoError(t, err)
+	}
+
+	count := 0
+	err := service.ListAll(t.Context(), repo.ListFilter{}, func(p *pb.Product) error {
+		count++
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+}
+
 func TestCreateUpdate(t *testing.T) {
 	service := NewTestService(nil) 
 
@@ -172,7 +626,508 @@ func TestList(t *testing.T) {
 		assert.NoError(t, err)
 	}
 	
-	ps, err := s.List(t.Context(), 0, 0, "", "")
+	ps, err := s.List(t.Context(), 0, 0, repo.ListFilter{}, "")
 	assert.NoError(t, err)
 	assert.Equal(t, 4, len(ps))
-}
\ No newline at end of file
+}
+func TestListAfter(t *testing.T) {
+	s := NewTestService(nil)
+
+	for i := 1; i < 3; i++ {
+		testProduct.Id = strconv.Itoa(i)
+		_, err := s.Create(t.Context(), &testProduct)
+		assert.NoError(t, err)
+	}
+
+	ps, next, err := s.ListAfter(t.Context(), "", 10, repo.ListFilter{}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(ps))
+	assert.Empty(t, next)
+}
+
+func TestCount(t *testing.T) {
+	s := NewTestService(nil)
+
+	for i := 1; i < 4; i++ {
+		testProduct.Id = strconv.Itoa(i)
+		_, err := s.Create(t.Context(), &testProduct)
+		assert.NoError(t, err)
+	}
+
+	total, err := s.Count(t.Context(), repo.ListFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+}
+
+func TestCreateMany(t *testing.T) {
+	s := NewTestService(nil)
+
+	ps, err := s.CreateMany(t.Context(), []*pb.Product{{Name: "a"}, {Name: "b"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(ps))
+	assert.NotEqual(t, ps[0].Id, ps[1].Id)
+
+	total, err := s.Count(t.Context(), repo.ListFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+}
+
+func TestDecrementQuantity(t *testing.T) {
+	s := NewTestService(nil)
+
+	p, err := s.Create(t.Context(), &pb.Product{Name: "stock", Quantity: 3})
+	assert.NoError(t, err)
+
+	left, err := s.DecrementQuantity(t.Context(), p.Id, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), left)
+
+	_, err = s.DecrementQuantity(t.Context(), p.Id, 2)
+	var stockErr *repo.InsufficientStockError
+	assert.ErrorAs(t, err, &stockErr)
+	assert.Equal(t, int32(1), stockErr.Available)
+}
+
+func TestReserveStock(t *testing.T) {
+	s := NewTestService(nil)
+
+	p, err := s.Create(t.Context(), &pb.Product{Name: "stock", Quantity: 3})
+	assert.NoError(t, err)
+
+	r, err := s.ReserveStock(t.Context(), p.Id, "order-1", 2, time.Minute)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, r.ID)
+	assert.Equal(t, p.Id, r.ProductID)
+	assert.Equal(t, "order-1", r.OrderID)
+	assert.True(t, r.ExpiresAt.After(time.Now()))
+
+	_, err = s.ReserveStock(t.Context(), p.Id, "order-2", 5, time.Minute)
+	var stockErr *repo.InsufficientStockError
+	assert.ErrorAs(t, err, &stockErr)
+}
+
+func TestConfirmReservation(t *testing.T) {
+	s := NewTestService(nil)
+
+	p, err := s.Create(t.Context(), &pb.Product{Name: "stock", Quantity: 3})
+	assert.NoError(t, err)
+	r, err := s.ReserveStock(t.Context(), p.Id, "order-1", 2, time.Minute)
+	assert.NoError(t, err)
+
+	confirmed, err := s.ConfirmReservation(t.Context(), r.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, repo.ReservationConfirmed, confirmed.Status)
+	assert.Equal(t, int32(1), p.Quantity)
+
+	// Confirming twice does not take the stock again.
+	_, err = s.ConfirmReservation(t.Context(), r.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), p.Quantity)
+
+	_, err = s.ReleaseReservation(t.Context(), r.ID)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestReleaseReservation(t *testing.T) {
+	s := NewTestService(nil)
+
+	p, err := s.Create(t.Context(), &pb.Product{Name: "stock", Quantity: 3})
+	assert.NoError(t, err)
+	r, err := s.ReserveStock(t.Context(), p.Id, "order-1", 2, time.Minute)
+	assert.NoError(t, err)
+
+	released, err := s.ReleaseReservation(t.Context(), r.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, repo.ReservationReleased, released.Status)
+	assert.Equal(t, int32(3), p.Quantity)
+
+	_, err = s.ConfirmReservation(t.Context(), r.ID)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = s.GetReservation(t.Context(), "missing")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestStockOf(t *testing.T) {
+	s := NewTestService(nil)
+
+	p, err := s.Create(t.Context(), &pb.Product{Name: "stock", Quantity: 3, Available: true})
+	assert.NoError(t, err)
+	_, err = s.ReserveStock(t.Context(), p.Id, "order-1", 2, time.Minute)
+	assert.NoError(t, err)
+
+	stock, err := s.StockOf(t.Context(), []string{p.Id, "missing"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]repo.Stock{p.Id: {Available: true, Free: 1}}, stock)
+}
+
+// TestLocalize tests that the best matching translation replaces the
+// name and description, falling back to the product's own texts.
+func TestLocalize(t *testing.T) {
+	s := NewTestService(nil)
+
+	p, err := s.Create(t.Context(), &pb.Product{
+		Name:        "Chair",
+		Description: "Wooden chair",
+		Translations: []*pb.LocalizedText{
+			{Locale: "de", Name: "Stuhl", Description: "Holzstuhl"},
+			{Locale: "fr", Name: "Chaise"},
+		},
+	})
+	assert.NoError(t, err)
+
+	cases := []struct {
+		prefs       []language.Tag
+		name        string
+		description string
+	}{
+		{nil, "Chair", "Wooden chair"},
+		{[]language.Tag{language.MustParse("de-AT")}, "Stuhl", "Holzstuhl"},
+		{[]language.Tag{language.Japanese, language.French}, "Chaise", ""},
+		{[]language.Tag{language.Japanese}, "Chair", "Wooden chair"},
+	}
+	for _, tc := range cases {
+		localized := &pb.Product{Id: p.Id, Name: "Chair", Description: "Wooden chair"}
+		assert.NoError(t, s.Localize(t.Context(), tc.prefs, localized))
+		assert.Equal(t, tc.name, localized.GetName(), "prefs %v", tc.prefs)
+		assert.Equal(t, tc.description, localized.GetDescription(), "prefs %v", tc.prefs)
+		assert.Len(t, localized.GetTranslations(), 2)
+	}
+
+	// Fields outside the read mask stay unset.
+	ctx, err := repo.WithReadMask(t.Context(), []string{"name"})
+	assert.NoError(t, err)
+	localized := &pb.Product{Id: p.Id, Name: "Chair"}
+	assert.NoError(t, s.Localize(ctx, []language.Tag{language.German}, localized))
+	assert.Equal(t, "Stuhl", localized.GetName())
+	assert.Empty(t, localized.GetDescription())
+	assert.Empty(t, localized.GetTranslations())
+}
+
+func TestVariants(t *testing.T) {
+	s := NewTestService(nil)
+
+	p, err := s.Create(t.Context(), &pb.Product{Name: "shirt"})
+	assert.NoError(t, err)
+	v, err := s.CreateVariant(t.Context(), &pb.Variant{ProductId: p.Id, Sku: "TS-M", Options: map[string]string{"size": "M"}})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, v.Id)
+
+	_, err = s.CreateVariant(t.Context(), nil)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	variants, err := s.ListVariants(t.Context(), p.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, []*pb.Variant{v}, variants)
+
+	_, err = s.ListVariants(t.Context(), "missing")
+	assert.Error(t, err)
+
+	// Products are collapsed unless expanded, and the read mask may leave
+	// the variants out.
+	assert.Empty(t, p.Variants)
+	assert.NoError(t, s.ExpandVariants(t.Context(), p))
+	assert.Equal(t, []*pb.Variant{v}, p.Variants)
+
+	ctx, err := repo.WithReadMask(t.Context(), []string{"name"})
+	assert.NoError(t, err)
+	masked := &pb.Product{Id: p.Id}
+	assert.NoError(t, s.ExpandVariants(ctx, masked))
+	assert.Empty(t, masked.Variants)
+}
+
+func TestBatchUpdate(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	s := NewTestService(nil)
+	s.Tx = repo.NewTxManager(mock)
+
+	p, err := s.Create(t.Context(), &pb.Product{Name: "old"})
+	assert.NoError(t, err)
+	mask := &fieldmaskpb.FieldMask{Paths: []string{"name"}}
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	results, applied, err := s.BatchUpdate(t.Context(), []ProductUpdate{
+		{Product: &pb.Product{Id: p.Id, Name: "new"}, Mask: mask},
+	})
+	assert.NoError(t, err)
+	assert.True(t, applied)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "new", results[0].Product.GetName())
+
+	// One failed update rolls back the batch and is reported on its own.
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	results, applied, err = s.BatchUpdate(t.Context(), []ProductUpdate{
+		{Product: &pb.Product{Id: p.Id, Name: "newer"}, Mask: mask},
+		{Product: &pb.Product{Id: "missing", Name: "newer"}, Mask: mask},
+	})
+	assert.NoError(t, err)
+	assert.False(t, applied)
+	assert.NoError(t, results[0].Err)
+	assert.Nil(t, results[0].Product)
+	assert.Error(t, results[1].Err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateStatus(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	assert.NoError(t, err)
+	defer mock.Close()
+
+	s := NewTestService(nil)
+	s.Tx = repo.NewTxManager(mock)
+
+	p, err := s.Create(t.Context(), &pb.Product{Name: "draft", Status: pb.Product_DRAFT})
+	assert.NoError(t, err)
+	statusMask := &fieldmaskpb.FieldMask{Paths: []string{"status"}}
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	updated, err := s.Update(t.Context(), &pb.Product{Id: p.Id, Status: pb.Product_ACTIVE}, statusMask)
+	assert.NoError(t, err)
+	assert.Equal(t, pb.Product_ACTIVE, updated.GetStatus())
+
+	// The deprecated available=false archives an active product.
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	updated, err = s.Update(t.Context(), &pb.Product{Id: p.Id}, &fieldmaskpb.FieldMask{Paths: []string{"available"}})
+	assert.NoError(t, err)
+	assert.Equal(t, pb.Product_ARCHIVED, updated.GetStatus())
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	_, err = s.Update(t.Context(), &pb.Product{Id: p.Id, Status: pb.Product_DRAFT}, statusMask)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = s.Update(t.Context(), &pb.Product{Id: p.Id}, statusMask)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestValidateProduct(t *testing.T) {
+	manyTags := make([]string, maxTags+1)
+	for i := range manyTags {
+		manyTags[i] = "tag"
+	}
+	cases := []struct {
+		product *pb.Product
+		paths   []string
+		field   string // the field of the violation, "" if valid
+	}{
+		{&pb.Product{Name: strings.Repeat("я", maxNameLength), Tags: []string{"sale", "новинка"}, Sku: "NB-14-001"}, nil, ""},
+		{&pb.Product{Name: " "}, nil, "name"},
+		{&pb.Product{Name: strings.Repeat("a", maxNameLength+1)}, nil, "name"},
+		{&pb.Product{Name: "a", Description: strings.Repeat("a", maxDescriptionLength+1)}, nil, "description"},
+		{&pb.Product{Name: "a", Price: -1}, nil, "price"},
+		{&pb.Product{Name: "a", PriceMoney: &pb.Money{Units: -5}}, nil, "price_money"},
+		{&pb.Product{Name: "a", Quantity: -1}, nil, "quantity"},
+		{&pb.Product{Name: "a", ReorderPoint: -1}, nil, "reorder_point"},
+		{&pb.Product{Name: "a", ReorderQuantity: -1}, nil, "reorder_quantity"},
+		{&pb.Product{Name: "a", Tags: manyTags}, nil, "tags"},
+		{&pb.Product{Name: "a", Tags: []string{strings.Repeat("a", maxTagLength+1)}}, nil, "tags[0]"},
+		{&pb.Product{Name: "a", Tags: []string{"ok", "two words"}}, nil, "tags[1]"},
+		{&pb.Product{Name: "a", Sku: "sku with spaces"}, nil, "sku"},
+		{&pb.Product{Name: "a", Translations: []*pb.LocalizedText{{Locale: "de", Name: strings.Repeat("a", maxNameLength+1)}}}, nil, "translations[0].name"},
+		// Updates only check the fields of their mask.
+		{&pb.Product{Quantity: -1}, []string{"name"}, "name"},
+		{&pb.Product{Name: "", Quantity: 3}, []string{"quantity"}, ""},
+		{&pb.Product{Price: -1}, []string{"price_money"}, "price"},
+	}
+
+	for _, tc := range cases {
+		err := validateProduct(tc.product, tc.paths...)
+		if tc.field == "" {
+			assert.NoError(t, err, "product %v", tc.product)
+			continue
+		}
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), "product %v", tc.product)
+		assert.Equal(t, tc.field, violationField(err), "product %v", tc.product)
+	}
+}
+
+// violationField returns the field of the BadRequest detail of err.
+func violationField(err error) string {
+	for _, d := range status.Convert(err).Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok && len(br.GetFieldViolations()) > 0 {
+			return br.GetFieldViolations()[0].GetField()
+		}
+	}
+	return ""
+}
+
+// TestCreateValidates tests that Create and Update reject invalid products
+// before they reach the repo.
+func TestCreateValidates(t *testing.T) {
+	service := NewTestService(nil)
+
+	_, err := service.Create(t.Context(), &pb.Product{Name: "test", Quantity: -1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Empty(t, service.Repo.(*TestRepo).Storage)
+
+	p, err := service.Create(t.Context(), &pb.Product{Name: "test"})
+	assert.NoError(t, err)
+	_, err = service.Update(t.Context(), &pb.Product{Id: p.Id, Tags: []string{"bad tag"}}, &fieldmaskpb.FieldMask{Paths: []string{"tags"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+type eventWriter struct {
+	msgs []events.Message
+}
+
+func (w *eventWriter) WriteMessages(ctx context.Context, msgs ...events.Message) error {
+	w.msgs = append(w.msgs, msgs...)
+	return nil
+}
+
+func TestEvents(t *testing.T) {
+	w := &eventWriter{}
+	service := NewTestService(nil)
+	service.Events = events.NewPublisher(w, events.DefaultTopics, 10)
+
+	p, err := service.Create(t.Context(), &pb.Product{Name: "test", Quantity: 5})
+	assert.NoError(t, err)
+	_, err = service.Update(t.Context(), &pb.Product{Id: p.Id, Name: "new"}, &fieldmaskpb.FieldMask{Paths: []string{"name"}})
+	assert.NoError(t, err)
+	_, err = service.DecrementQuantity(t.Context(), p.Id, 2)
+	assert.NoError(t, err)
+	// Failed changes publish nothing.
+	_, err = service.Update(t.Context(), &pb.Product{Id: "missing", Name: "new"}, &fieldmaskpb.FieldMask{Paths: []string{"name"}})
+	assert.Error(t, err)
+	assert.NoError(t, service.Delete(t.Context(), p.Id))
+
+	service.Events.Close()
+	service.Events.Run(t.Context())
+
+	var types []string
+	for _, m := range w.msgs {
+		assert.Equal(t, p.Id, string(m.Key))
+		types = append(types, string(m.Headers[0].Value))
+	}
+	assert.Equal(t, []string{"inventory.ProductCreated", "inventory.ProductUpdated", "inventory.StockChanged", "inventory.ProductDeleted"}, types)
+}
+
+// TestBulkEvents tests the events of the writes besides the single
+// product CRUD: confirmations, upserts, imports, bulk deletes and
+// restores.
+func TestBulkEvents(t *testing.T) {
+	w := &eventWriter{}
+	service := NewTestService(nil)
+	service.Events = events.NewPublisher(w, events.DefaultTopics, 20)
+
+	p, err := service.Create(t.Context(), &pb.Product{Name: "stock", Sku: "SKU-1", Quantity: 5, Tags: []string{"old"}})
+	assert.NoError(t, err)
+	r, err := service.ReserveStock(t.Context(), p.Id, "order-1", 2, time.Minute)
+	assert.NoError(t, err)
+	_, err = service.ConfirmReservation(t.Context(), r.ID)
+	assert.NoError(t, err)
+	// A second confirmation takes nothing and publishes nothing.
+	_, err = service.ConfirmReservation(t.Context(), r.ID)
+	assert.NoError(t, err)
+
+	_, err = service.UpsertBySKU(t.Context(), &pb.Product{Name: "stock", Sku: "SKU-1", Quantity: 7})
+	assert.NoError(t, err)
+	created, err := service.UpsertBySKU(t.Context(), &pb.Product{Name: "new", Sku: "SKU-2"})
+	assert.NoError(t, err)
+	imported := &pb.Product{Name: "imported"}
+	_, err = service.Import(t.Context(), []*pb.Product{imported}, nil)
+	assert.NoError(t, err)
+
+	_, err = service.DeleteWhere(t.Context(), repo.ListFilter{Tags: []string{"old"}})
+	assert.NoError(t, err)
+	assert.NoError(t, service.Restore(t.Context(), p.Id))
+
+	service.Events.Close()
+	service.Events.Run(t.Context())
+
+	var got [][2]string
+	for _, m := range w.msgs {
+		got = append(got, [2]string{string(m.Key), string(m.Headers[0].Value)})
+	}
+	assert.Equal(t, [][2]string{
+		{p.Id, "inventory.ProductCreated"},
+		{p.Id, "inventory.StockChanged"},
+		{p.Id, "inventory.ProductUpdated"},
+		{created.Id, "inventory.ProductCreated"},
+		{imported.Id, "inventory.ProductCreated"},
+		{p.Id, "inventory.ProductDeleted"},
+		{p.Id, "inventory.ProductCreated"},
+	}, got)
+
+	var changed pb.StockChanged
+	assert.NoError(t, proto.Unmarshal(w.msgs[1].Value, &changed))
+	assert.Equal(t, int32(-2), changed.GetDelta())
+	assert.Equal(t, int32(3), changed.GetQuantity())
+	assert.Equal(t, pb.AdjustStockRequest_SOLD, changed.GetReason())
+}
+
+func TestReleaseExpired(t *testing.T) {
+	service := NewTestService(nil)
+	p, err := service.Create(t.Context(), &pb.Product{Name: "test", Quantity: 10})
+	assert.NoError(t, err)
+
+	var expired []*repo.Reservation
+	for range 3 {
+		r, err := service.ReserveStock(t.Context(), p.Id, "order", 1, -time.Second)
+		assert.NoError(t, err)
+		expired = append(expired, r)
+	}
+	active, err := service.ReserveStock(t.Context(), p.Id, "order", 1, time.Minute)
+	assert.NoError(t, err)
+
+	// Batches of two take two sweeps of the repo.
+	n, err := service.ReleaseExpired(t.Context(), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	for _, r := range expired {
+		assert.Equal(t, repo.ReservationExpired, r.Status)
+	}
+	assert.Equal(t, repo.ReservationActive, active.Status)
+
+	_, err = service.ConfirmReservation(t.Context(), expired[0].ID)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+// conflictingStock fails AdjustStock with a serialization failure the
+// first conflicts times.
+type conflictingStock struct {
+	repo.StockRepo
+	conflicts int
+	calls     int
+}
+
+func (s *conflictingStock) AdjustStock(ctx context.Context, productID string, delta int32, reason, reference string) (int32, error) {
+	s.calls++
+	if s.calls <= s.conflicts {
+		return 0, &pgconn.PgError{Code: "40001"}
+	}
+	return 10 + delta, nil
+}
+
+func TestAdjustStockRetriesConflicts(t *testing.T) {
+	service := NewTestService(nil)
+	service.ConflictRetry = repo.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+
+	stock := &conflictingStock{conflicts: 2}
+	service.Stock = stock
+	quantity, err := service.AdjustStock(t.Context(), "1", 5, repo.ReasonReceived, "")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(15), quantity)
+	assert.Equal(t, 3, stock.calls)
+
+	stock = &conflictingStock{conflicts: 3}
+	service.Stock = stock
+	_, err = service.AdjustStock(t.Context(), "1", 5, repo.ReasonReceived, "")
+	assert.Equal(t, codes.Aborted, status.Code(err))
+	assert.Equal(t, 3, stock.calls)
+	for _, d := range status.Convert(err).Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			assert.Equal(t, "STOCK_CONFLICT", info.GetReason())
+		}
+	}
+}
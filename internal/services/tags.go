@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/andro-kes/inventory_service/internal/repo"
+)
+
+// normalizeTags returns tags as they are stored: registered tags and
+// their aliases as the name of the tag, other tags in lower case, each
+// once in the order given. Without a TagRepo all tags are lowered.
+func (ps *ProductService) normalizeTags(ctx context.Context, tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return tags, nil
+	}
+	keys := make([]string, len(tags))
+	for i, tag := range tags {
+		keys[i] = repo.TagKey(tag)
+	}
+	var names map[string]string
+	if ps.Tags != nil {
+		var err error
+		if names, err = ps.Tags.Resolve(ctx, keys); err != nil {
+			return nil, err
+		}
+	}
+
+	normalized := make([]string, 0, len(keys))
+	for _, key := range keys {
+		name, ok := names[key]
+		if !ok {
+			name = key
+		}
+		if !slices.Contains(normalized, name) {
+			normalized = append(normalized, name)
+		}
+	}
+	return normalized, nil
+}
+
+// normalizeFilter returns filter with its tags normalized, so that they
+// match the tags of the products in any casing.
+func (ps *ProductService) normalizeFilter(ctx context.Context, filter repo.ListFilter) (repo.ListFilter, error) {
+	tags, err := ps.normalizeTags(ctx, filter.Tags)
+	if err != nil {
+		return filter, err
+	}
+	filter.Tags = tags
+	return filter, nil
+}
+
+// ListTags returns the registered tags and the tags of products, with
+// the number of products of each, by name.
+func (ps *ProductService) ListTags(ctx context.Context) ([]*repo.Tag, error) {
+	return ps.Tags.List(ctx)
+}
+
+// MergeTags registers the tag name with its casing, makes the tags of
+// from its aliases and retags the products with any of them as name.
+// Products written later with an alias get name instead. With no from it
+// only registers name, or changes its casing. It returns the merged tag
+// and the number of products retagged.
+func (ps *ProductService) MergeTags(ctx context.Context, name string, from []string) (*repo.Tag, int64, error) {
+	if err := validateTag("name", strings.TrimSpace(name)); err != nil {
+		return nil, 0, err
+	}
+	for i, tag := range from {
+		if err := validateTag(fmt.Sprintf("from[%d]", i), strings.TrimSpace(tag)); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	retagged, err := ps.Tags.Merge(ctx, name, from)
+	if err != nil {
+		return nil, 0, err
+	}
+	tag, err := ps.Tags.Get(ctx, name)
+	if err != nil {
+		return nil, retagged, err
+	}
+	return tag, retagged, nil
+}
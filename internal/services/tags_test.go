@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/andro-kes/inventory_service/internal/repo"
+	pb "github.com/andro-kes/inventory_service/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// testTags is a registry of tag names by key, aliases included.
+type testTags struct {
+	repo.TagRepo
+	names map[string]string
+}
+
+func (r *testTags) Resolve(ctx context.Context, keys []string) (map[string]string, error) {
+	names := make(map[string]string)
+	for _, key := range keys {
+		if name, ok := r.names[key]; ok {
+			names[key] = name
+		}
+	}
+	return names, nil
+}
+
+func TestNormalizeTags(t *testing.T) {
+	s := NewTestService(nil)
+	s.Tags = &testTags{names: map[string]string{"electronics": "Electronics", "electronic": "Electronics"}}
+
+	p, err := s.Create(t.Context(), &pb.Product{Name: "tv", Tags: []string{"ELECTRONICS", "Sale", "electronic", "sale"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Electronics", "sale"}, p.GetTags())
+
+	p, err = s.Update(t.Context(), &pb.Product{Id: p.GetId(), Tags: []string{"New", "electronics"}}, &fieldmaskpb.FieldMask{Paths: []string{"tags"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"new", "Electronics"}, p.GetTags())
+
+	// Filters match in any casing.
+	deleted, err := s.DeleteWhere(t.Context(), repo.ListFilter{Tags: []string{"NEW", "electronic"}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	// Without a registry tags are only lowered.
+	s.Tags = nil
+	p, err = s.Create(t.Context(), &pb.Product{Name: "tv", Tags: []string{"Electronics"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"electronics"}, p.GetTags())
+}
+
+func TestMergeTagsValidates(t *testing.T) {
+	s := NewTestService(nil)
+	s.Tags = &testTags{}
+
+	for _, from := range [][]string{{"ok", "two words"}, {""}} {
+		_, _, err := s.MergeTags(t.Context(), "Electronics", from)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), from)
+	}
+	_, _, err := s.MergeTags(t.Context(), "-bad", nil)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/andro-kes/inventory_service/internal/inverr"
+	pb "github.com/andro-kes/inventory_service/proto"
+)
+
+// Limits of the product fields, in characters, so that a single request
+// cannot store arbitrarily large products.
+const (
+	maxNameLength        = 255
+	maxDescriptionLength = 10_000
+	maxTags              = 50
+	maxTagLength         = 64
+	maxTranslations      = 50
+)
+
+var (
+	// tagFormat matches tags: letters of any script, digits, '_' and '-',
+	// starting with a letter or digit.
+	tagFormat = regexp.MustCompile(`^[\p{L}\p{N}][\p{L}\p{N}_-]*$`)
+	// skuFormat matches SKUs such as "NB-14-001".
+	skuFormat = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+)
+
+// validateProduct checks the business rules of the fields of p: all of
+// them for a new product, or only those of the update_mask paths of an
+// update. Violations are InvalidArgument errors naming the field. The
+// repo checks what the database needs on top, e.g. price formats and
+// status values.
+func validateProduct(p *pb.Product, paths ...string) error {
+	checks := func(fields ...string) bool {
+		if len(paths) == 0 {
+			return true
+		}
+		for _, f := range fields {
+			if slices.Contains(paths, f) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if checks("name") {
+		if strings.TrimSpace(p.GetName()) == "" {
+			return inverr.InvalidField("name", "name is required")
+		}
+		if err := validateLength("name", p.GetName(), maxNameLength); err != nil {
+			return err
+		}
+	}
+	if checks("description") {
+		if err := validateLength("description", p.GetDescription(), maxDescriptionLength); err != nil {
+			return err
+		}
+	}
+	if checks("price", "price_money") {
+		if m := p.GetPriceMoney(); m != nil && (m.GetUnits() < 0 || m.GetNanos() < 0) {
+			return inverr.InvalidField("price_money", "price must not be negative")
+		}
+		if p.GetPrice() < 0 {
+			return inverr.InvalidField("price", "price must not be negative: %v", p.GetPrice())
+		}
+	}
+	if checks("quantity") && p.GetQuantity() < 0 {
+		return inverr.InvalidField("quantity", "quantity must not be negative: %d", p.GetQuantity())
+	}
+	if checks("reorder_point") && p.GetReorderPoint() < 0 {
+		return inverr.InvalidField("reorder_point", "reorder_point must not be negative: %d", p.GetReorderPoint())
+	}
+	if checks("reorder_quantity") && p.GetReorderQuantity() < 0 {
+		return inverr.InvalidField("reorder_quantity", "reorder_quantity must not be negative: %d", p.GetReorderQuantity())
+	}
+	if checks("tags") {
+		if err := validateTags(p.GetTags()); err != nil {
+			return err
+		}
+	}
+	if checks("sku") && p.GetSku() != "" && !skuFormat.MatchString(p.GetSku()) {
+		return inverr.InvalidField("sku", "invalid sku %q: want up to 64 letters, digits, '.', '_' and '-'", p.GetSku())
+	}
+	if checks("translations") {
+		if err := validateTranslationLimits(p.GetTranslations()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateTags checks the number, length and format of tags.
+func validateTags(tags []string) error {
+	if len(tags) > maxTags {
+		return inverr.InvalidField("tags", "at most %d tags are allowed, got %d", maxTags, len(tags))
+	}
+	for i, tag := range tags {
+		if err := validateTag(fmt.Sprintf("tags[%d]", i), tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateTag checks the length and format of the tag in field.
+func validateTag(field, tag string) error {
+	if err := validateLength(field, tag, maxTagLength); err != nil {
+		return err
+	}
+	if !tagFormat.MatchString(tag) {
+		return inverr.InvalidField(field, "invalid tag %q: want letters, digits, '_' and '-'", tag)
+	}
+	return nil
+}
+
+// validateTranslationLimits checks the number of translations and the
+// length of their texts.
+func validateTranslationLimits(translations []*pb.LocalizedText) error {
+	if len(translations) > maxTranslations {
+		return inverr.InvalidField("translations", "at most %d translations are allowed, got %d", maxTranslations, len(translations))
+	}
+	for i, t := range translations {
+		if err := validateLength(fmt.Sprintf("translations[%d].name", i), t.GetName(), maxNameLength); err != nil {
+			return err
+		}
+		if err := validateLength(fmt.Sprintf("translations[%d].description", i), t.GetDescription(), maxDescriptionLength); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateLength checks that the field value has at most max characters.
+func validateLength(field, value string, max int) error {
+	if n := utf8.RuneCountInString(value); n > max {
+		return inverr.InvalidField(field, "%s must have at most %d characters, got %d", field, max, n)
+	}
+	return nil
+}
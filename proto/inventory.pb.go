@@ -9,6 +9,7 @@ package proto
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
 	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
 	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
@@ -23,19 +24,408 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Status is the publishing state of a product. Updates may move a
+// product from DRAFT to ACTIVE, between ACTIVE and ARCHIVED, and from
+// any of them to DISCONTINUED, which is final.
+type Product_Status int32
+
+const (
+	Product_STATUS_UNSPECIFIED Product_Status = 0
+	// DRAFT products are being prepared and not on sale yet.
+	Product_DRAFT Product_Status = 1
+	// ACTIVE products are on sale.
+	Product_ACTIVE Product_Status = 2
+	// ARCHIVED products are off sale for now.
+	Product_ARCHIVED Product_Status = 3
+	// DISCONTINUED products are off sale for good.
+	Product_DISCONTINUED Product_Status = 4
+)
+
+// Enum value maps for Product_Status.
+var (
+	Product_Status_name = map[int32]string{
+		0: "STATUS_UNSPECIFIED",
+		1: "DRAFT",
+		2: "ACTIVE",
+		3: "ARCHIVED",
+		4: "DISCONTINUED",
+	}
+	Product_Status_value = map[string]int32{
+		"STATUS_UNSPECIFIED": 0,
+		"DRAFT":              1,
+		"ACTIVE":             2,
+		"ARCHIVED":           3,
+		"DISCONTINUED":       4,
+	}
+)
+
+func (x Product_Status) Enum() *Product_Status {
+	p := new(Product_Status)
+	*p = x
+	return p
+}
+
+func (x Product_Status) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Product_Status) Descriptor() protoreflect.EnumDescriptor {
+	return file_inventory_proto_enumTypes[0].Descriptor()
+}
+
+func (Product_Status) Type() protoreflect.EnumType {
+	return &file_inventory_proto_enumTypes[0]
+}
+
+func (x Product_Status) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Product_Status.Descriptor instead.
+func (Product_Status) EnumDescriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{0, 0}
+}
+
+type ExportProductsRequest_Format int32
+
+const (
+	// FORMAT_UNSPECIFIED is CSV.
+	ExportProductsRequest_FORMAT_UNSPECIFIED ExportProductsRequest_Format = 0
+	// CSV has the columns of ImportProductsCsv, so the file can be
+	// imported again.
+	ExportProductsRequest_CSV ExportProductsRequest_Format = 1
+	// NDJSON has one Product per line in the JSON mapping of protobuf,
+	// with all stored fields.
+	ExportProductsRequest_NDJSON ExportProductsRequest_Format = 2
+)
+
+// Enum value maps for ExportProductsRequest_Format.
+var (
+	ExportProductsRequest_Format_name = map[int32]string{
+		0: "FORMAT_UNSPECIFIED",
+		1: "CSV",
+		2: "NDJSON",
+	}
+	ExportProductsRequest_Format_value = map[string]int32{
+		"FORMAT_UNSPECIFIED": 0,
+		"CSV":                1,
+		"NDJSON":             2,
+	}
+)
+
+func (x ExportProductsRequest_Format) Enum() *ExportProductsRequest_Format {
+	p := new(ExportProductsRequest_Format)
+	*p = x
+	return p
+}
+
+func (x ExportProductsRequest_Format) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ExportProductsRequest_Format) Descriptor() protoreflect.EnumDescriptor {
+	return file_inventory_proto_enumTypes[1].Descriptor()
+}
+
+func (ExportProductsRequest_Format) Type() protoreflect.EnumType {
+	return &file_inventory_proto_enumTypes[1]
+}
+
+func (x ExportProductsRequest_Format) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ExportProductsRequest_Format.Descriptor instead.
+func (ExportProductsRequest_Format) EnumDescriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{24, 0}
+}
+
+type AuditEntry_Operation int32
+
+const (
+	AuditEntry_OPERATION_UNSPECIFIED AuditEntry_Operation = 0
+	AuditEntry_INSERT                AuditEntry_Operation = 1
+	// UPDATE also covers deletes and restores, as changes of
+	// deleted_at.
+	AuditEntry_UPDATE AuditEntry_Operation = 2
+	// DELETE is a purge of a deleted product.
+	AuditEntry_DELETE AuditEntry_Operation = 3
+)
+
+// Enum value maps for AuditEntry_Operation.
+var (
+	AuditEntry_Operation_name = map[int32]string{
+		0: "OPERATION_UNSPECIFIED",
+		1: "INSERT",
+		2: "UPDATE",
+		3: "DELETE",
+	}
+	AuditEntry_Operation_value = map[string]int32{
+		"OPERATION_UNSPECIFIED": 0,
+		"INSERT":                1,
+		"UPDATE":                2,
+		"DELETE":                3,
+	}
+)
+
+func (x AuditEntry_Operation) Enum() *AuditEntry_Operation {
+	p := new(AuditEntry_Operation)
+	*p = x
+	return p
+}
+
+func (x AuditEntry_Operation) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AuditEntry_Operation) Descriptor() protoreflect.EnumDescriptor {
+	return file_inventory_proto_enumTypes[2].Descriptor()
+}
+
+func (AuditEntry_Operation) Type() protoreflect.EnumType {
+	return &file_inventory_proto_enumTypes[2]
+}
+
+func (x AuditEntry_Operation) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AuditEntry_Operation.Descriptor instead.
+func (AuditEntry_Operation) EnumDescriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{32, 0}
+}
+
+// Reason is why the stock changes. RECEIVED and RETURNED add units,
+// DAMAGED, LOST and SOLD take them out, CORRECTION does either.
+type AdjustStockRequest_Reason int32
+
+const (
+	AdjustStockRequest_REASON_UNSPECIFIED AdjustStockRequest_Reason = 0
+	AdjustStockRequest_RECEIVED           AdjustStockRequest_Reason = 1
+	AdjustStockRequest_DAMAGED            AdjustStockRequest_Reason = 2
+	AdjustStockRequest_CORRECTION         AdjustStockRequest_Reason = 3
+	AdjustStockRequest_SOLD               AdjustStockRequest_Reason = 4
+	AdjustStockRequest_RETURNED           AdjustStockRequest_Reason = 5
+	AdjustStockRequest_LOST               AdjustStockRequest_Reason = 6
+)
+
+// Enum value maps for AdjustStockRequest_Reason.
+var (
+	AdjustStockRequest_Reason_name = map[int32]string{
+		0: "REASON_UNSPECIFIED",
+		1: "RECEIVED",
+		2: "DAMAGED",
+		3: "CORRECTION",
+		4: "SOLD",
+		5: "RETURNED",
+		6: "LOST",
+	}
+	AdjustStockRequest_Reason_value = map[string]int32{
+		"REASON_UNSPECIFIED": 0,
+		"RECEIVED":           1,
+		"DAMAGED":            2,
+		"CORRECTION":         3,
+		"SOLD":               4,
+		"RETURNED":           5,
+		"LOST":               6,
+	}
+)
+
+func (x AdjustStockRequest_Reason) Enum() *AdjustStockRequest_Reason {
+	p := new(AdjustStockRequest_Reason)
+	*p = x
+	return p
+}
+
+func (x AdjustStockRequest_Reason) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AdjustStockRequest_Reason) Descriptor() protoreflect.EnumDescriptor {
+	return file_inventory_proto_enumTypes[3].Descriptor()
+}
+
+func (AdjustStockRequest_Reason) Type() protoreflect.EnumType {
+	return &file_inventory_proto_enumTypes[3]
+}
+
+func (x AdjustStockRequest_Reason) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AdjustStockRequest_Reason.Descriptor instead.
+func (AdjustStockRequest_Reason) EnumDescriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{37, 0}
+}
+
+type Reservation_Status int32
+
+const (
+	Reservation_STATUS_UNSPECIFIED Reservation_Status = 0
+	Reservation_ACTIVE             Reservation_Status = 1
+	Reservation_CONFIRMED          Reservation_Status = 2
+	Reservation_RELEASED           Reservation_Status = 3
+	// EXPIRED reservations were neither confirmed nor released before
+	// expires_at; they no longer hold stock.
+	Reservation_EXPIRED Reservation_Status = 4
+)
+
+// Enum value maps for Reservation_Status.
+var (
+	Reservation_Status_name = map[int32]string{
+		0: "STATUS_UNSPECIFIED",
+		1: "ACTIVE",
+		2: "CONFIRMED",
+		3: "RELEASED",
+		4: "EXPIRED",
+	}
+	Reservation_Status_value = map[string]int32{
+		"STATUS_UNSPECIFIED": 0,
+		"ACTIVE":             1,
+		"CONFIRMED":          2,
+		"RELEASED":           3,
+		"EXPIRED":            4,
+	}
+)
+
+func (x Reservation_Status) Enum() *Reservation_Status {
+	p := new(Reservation_Status)
+	*p = x
+	return p
+}
+
+func (x Reservation_Status) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Reservation_Status) Descriptor() protoreflect.EnumDescriptor {
+	return file_inventory_proto_enumTypes[4].Descriptor()
+}
+
+func (Reservation_Status) Type() protoreflect.EnumType {
+	return &file_inventory_proto_enumTypes[4]
+}
+
+func (x Reservation_Status) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Reservation_Status.Descriptor instead.
+func (Reservation_Status) EnumDescriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{47, 0}
+}
+
+type ProductChange_Type int32
+
+const (
+	ProductChange_TYPE_UNSPECIFIED ProductChange_Type = 0
+	ProductChange_CREATED          ProductChange_Type = 1
+	// UPDATED is also sent for upserts and restored products.
+	ProductChange_UPDATED ProductChange_Type = 2
+	ProductChange_DELETED ProductChange_Type = 3
+	ProductChange_RESET   ProductChange_Type = 4
+)
+
+// Enum value maps for ProductChange_Type.
+var (
+	ProductChange_Type_name = map[int32]string{
+		0: "TYPE_UNSPECIFIED",
+		1: "CREATED",
+		2: "UPDATED",
+		3: "DELETED",
+		4: "RESET",
+	}
+	ProductChange_Type_value = map[string]int32{
+		"TYPE_UNSPECIFIED": 0,
+		"CREATED":          1,
+		"UPDATED":          2,
+		"DELETED":          3,
+		"RESET":            4,
+	}
+)
+
+func (x ProductChange_Type) Enum() *ProductChange_Type {
+	p := new(ProductChange_Type)
+	*p = x
+	return p
+}
+
+func (x ProductChange_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ProductChange_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_inventory_proto_enumTypes[5].Descriptor()
+}
+
+func (ProductChange_Type) Type() protoreflect.EnumType {
+	return &file_inventory_proto_enumTypes[5]
+}
+
+func (x ProductChange_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ProductChange_Type.Descriptor instead.
+func (ProductChange_Type) EnumDescriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{55, 0}
+}
+
 type Product struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
-	Price         float64                `protobuf:"fixed64,4,opt,name=price,proto3" json:"price,omitempty"`
-	Quantity      int32                  `protobuf:"varint,5,opt,name=quantity,proto3" json:"quantity,omitempty"`
-	Tags          []string               `protobuf:"bytes,6,rep,name=tags,proto3" json:"tags,omitempty"`
-	Available     bool                   `protobuf:"varint,7,opt,name=available,proto3" json:"available,omitempty"`
-	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name        string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	// price is price_money as a double, kept for clients that do not read
+	// price_money yet. It is ignored on writes when price_money is set, and
+	// otherwise taken in the default currency (RUB).
+	//
+	// Deprecated: Marked as deprecated in inventory.proto.
+	Price    float64  `protobuf:"fixed64,4,opt,name=price,proto3" json:"price,omitempty"`
+	Quantity int32    `protobuf:"varint,5,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	Tags     []string `protobuf:"bytes,6,rep,name=tags,proto3" json:"tags,omitempty"`
+	// available is true for ACTIVE products, kept for clients that do not
+	// read status yet. On writes without status, true means ACTIVE; false
+	// creates DRAFT products and takes ACTIVE products to ARCHIVED.
+	//
+	// Deprecated: Marked as deprecated in inventory.proto.
+	Available bool                   `protobuf:"varint,7,opt,name=available,proto3" json:"available,omitempty"`
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	// category_id is empty for uncategorized products.
+	CategoryId string `protobuf:"bytes,10,opt,name=category_id,json=categoryId,proto3" json:"category_id,omitempty"`
+	// created_by and updated_by name the principals of the requests that
+	// created and last updated the product; empty for anonymous requests.
+	CreatedBy string `protobuf:"bytes,11,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"`
+	UpdatedBy string `protobuf:"bytes,12,opt,name=updated_by,json=updatedBy,proto3" json:"updated_by,omitempty"`
+	// sku is the stock keeping unit external systems identify the product
+	// by. It is unique; empty means none.
+	Sku string `protobuf:"bytes,13,opt,name=sku,proto3" json:"sku,omitempty"`
+	// barcode is the EAN-8, UPC-A, EAN-13 or GTIN-14 of the product, with a
+	// valid check digit; unique like sku.
+	Barcode string `protobuf:"bytes,14,opt,name=barcode,proto3" json:"barcode,omitempty"`
+	// price_money is the price, in whole cents.
+	PriceMoney *Money         `protobuf:"bytes,15,opt,name=price_money,json=priceMoney,proto3" json:"price_money,omitempty"`
+	Status     Product_Status `protobuf:"varint,16,opt,name=status,proto3,enum=inventory.Product_Status" json:"status,omitempty"`
+	// attributes holds merchant-specific properties, e.g. color or size.
+	// Keys are up to 64 letters, digits, '_' or '-'. An update_mask path
+	// "attributes.<key>" sets or, if absent, removes a single key.
+	Attributes map[string]string `protobuf:"bytes,17,rep,name=attributes,proto3" json:"attributes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// translations are the name and description of the product in other
+	// locales, one per locale. Create stores them and Update replaces all
+	// of them for the update_mask path "translations".
+	Translations []*LocalizedText `protobuf:"bytes,18,rep,name=translations,proto3" json:"translations,omitempty"`
+	// variants are the variants of the product, returned only when
+	// expand_variants is set on Get and List requests.
+	Variants []*Variant `protobuf:"bytes,19,rep,name=variants,proto3" json:"variants,omitempty"`
+	// reorder_point is the quantity at or below which the product is
+	// suggested for reordering, and reorder_quantity the least quantity to
+	// order. A reorder_quantity of 0 leaves the product out of the
+	// suggestions; see ListReorderSuggestions.
+	ReorderPoint    int32 `protobuf:"varint,20,opt,name=reorder_point,json=reorderPoint,proto3" json:"reorder_point,omitempty"`
+	ReorderQuantity int32 `protobuf:"varint,21,opt,name=reorder_quantity,json=reorderQuantity,proto3" json:"reorder_quantity,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *Product) Reset() {
@@ -89,6 +479,7 @@ func (x *Product) GetDescription() string {
 	return ""
 }
 
+// Deprecated: Marked as deprecated in inventory.proto.
 func (x *Product) GetPrice() float64 {
 	if x != nil {
 		return x.Price
@@ -110,6 +501,7 @@ func (x *Product) GetTags() []string {
 	return nil
 }
 
+// Deprecated: Marked as deprecated in inventory.proto.
 func (x *Product) GetAvailable() bool {
 	if x != nil {
 		return x.Available
@@ -131,30 +523,128 @@ func (x *Product) GetUpdatedAt() *timestamppb.Timestamp {
 	return nil
 }
 
-type ListRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	PageSize      int32                  `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
-	PrevSize      int32                  `protobuf:"varint,2,opt,name=prev_size,json=prevSize,proto3" json:"prev_size,omitempty"`
-	Filter        string                 `protobuf:"bytes,3,opt,name=filter,proto3" json:"filter,omitempty"`
-	OrderBy       string                 `protobuf:"bytes,4,opt,name=order_by,json=orderBy,proto3" json:"order_by,omitempty"`
+func (x *Product) GetCategoryId() string {
+	if x != nil {
+		return x.CategoryId
+	}
+	return ""
+}
+
+func (x *Product) GetCreatedBy() string {
+	if x != nil {
+		return x.CreatedBy
+	}
+	return ""
+}
+
+func (x *Product) GetUpdatedBy() string {
+	if x != nil {
+		return x.UpdatedBy
+	}
+	return ""
+}
+
+func (x *Product) GetSku() string {
+	if x != nil {
+		return x.Sku
+	}
+	return ""
+}
+
+func (x *Product) GetBarcode() string {
+	if x != nil {
+		return x.Barcode
+	}
+	return ""
+}
+
+func (x *Product) GetPriceMoney() *Money {
+	if x != nil {
+		return x.PriceMoney
+	}
+	return nil
+}
+
+func (x *Product) GetStatus() Product_Status {
+	if x != nil {
+		return x.Status
+	}
+	return Product_STATUS_UNSPECIFIED
+}
+
+func (x *Product) GetAttributes() map[string]string {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+func (x *Product) GetTranslations() []*LocalizedText {
+	if x != nil {
+		return x.Translations
+	}
+	return nil
+}
+
+func (x *Product) GetVariants() []*Variant {
+	if x != nil {
+		return x.Variants
+	}
+	return nil
+}
+
+func (x *Product) GetReorderPoint() int32 {
+	if x != nil {
+		return x.ReorderPoint
+	}
+	return 0
+}
+
+func (x *Product) GetReorderQuantity() int32 {
+	if x != nil {
+		return x.ReorderQuantity
+	}
+	return 0
+}
+
+// Variant is a sellable option of a product, e.g. size M in red, with its
+// own SKU and stock. Its price is the product price plus price_delta.
+type Variant struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ProductId string                 `protobuf:"bytes,2,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	// sku is required and unique among variants.
+	Sku string `protobuf:"bytes,3,opt,name=sku,proto3" json:"sku,omitempty"`
+	// options tell the variants of a product apart, e.g. {"size": "M",
+	// "color": "red"}. Keys are like Product.attributes keys; at least one
+	// option is required.
+	Options map[string]string `protobuf:"bytes,4,rep,name=options,proto3" json:"options,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// price_delta is added to the product price, in the product's
+	// currency; negative for cheaper variants.
+	PriceDelta *Money `protobuf:"bytes,5,opt,name=price_delta,json=priceDelta,proto3" json:"price_delta,omitempty"`
+	Quantity   int32  `protobuf:"varint,6,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	// price is the product price plus price_delta; output only.
+	Price         *Money                 `protobuf:"bytes,7,opt,name=price,proto3" json:"price,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListRequest) Reset() {
-	*x = ListRequest{}
+func (x *Variant) Reset() {
+	*x = Variant{}
 	mi := &file_inventory_proto_msgTypes[1]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListRequest) String() string {
+func (x *Variant) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListRequest) ProtoMessage() {}
+func (*Variant) ProtoMessage() {}
 
-func (x *ListRequest) ProtoReflect() protoreflect.Message {
+func (x *Variant) ProtoReflect() protoreflect.Message {
 	mi := &file_inventory_proto_msgTypes[1]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -166,61 +656,99 @@ func (x *ListRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListRequest.ProtoReflect.Descriptor instead.
-func (*ListRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use Variant.ProtoReflect.Descriptor instead.
+func (*Variant) Descriptor() ([]byte, []int) {
 	return file_inventory_proto_rawDescGZIP(), []int{1}
 }
 
-func (x *ListRequest) GetPageSize() int32 {
+func (x *Variant) GetId() string {
 	if x != nil {
-		return x.PageSize
+		return x.Id
 	}
-	return 0
+	return ""
 }
 
-func (x *ListRequest) GetPrevSize() int32 {
+func (x *Variant) GetProductId() string {
 	if x != nil {
-		return x.PrevSize
+		return x.ProductId
 	}
-	return 0
+	return ""
 }
 
-func (x *ListRequest) GetFilter() string {
+func (x *Variant) GetSku() string {
 	if x != nil {
-		return x.Filter
+		return x.Sku
 	}
 	return ""
 }
 
-func (x *ListRequest) GetOrderBy() string {
+func (x *Variant) GetOptions() map[string]string {
 	if x != nil {
-		return x.OrderBy
+		return x.Options
 	}
-	return ""
+	return nil
 }
 
-type ListResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Products      []*Product             `protobuf:"bytes,1,rep,name=products,proto3" json:"products,omitempty"`
-	TotalSize     int32                  `protobuf:"varint,3,opt,name=total_size,json=totalSize,proto3" json:"total_size,omitempty"`
+func (x *Variant) GetPriceDelta() *Money {
+	if x != nil {
+		return x.PriceDelta
+	}
+	return nil
+}
+
+func (x *Variant) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *Variant) GetPrice() *Money {
+	if x != nil {
+		return x.Price
+	}
+	return nil
+}
+
+func (x *Variant) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Variant) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+// LocalizedText is the name and description of a product in a locale.
+type LocalizedText struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// locale is a BCP 47 language tag, e.g. "de" or "pt-BR".
+	Locale        string `protobuf:"bytes,1,opt,name=locale,proto3" json:"locale,omitempty"`
+	Name          string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListResponse) Reset() {
-	*x = ListResponse{}
+func (x *LocalizedText) Reset() {
+	*x = LocalizedText{}
 	mi := &file_inventory_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListResponse) String() string {
+func (x *LocalizedText) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListResponse) ProtoMessage() {}
+func (*LocalizedText) ProtoMessage() {}
 
-func (x *ListResponse) ProtoReflect() protoreflect.Message {
+func (x *LocalizedText) ProtoReflect() protoreflect.Message {
 	mi := &file_inventory_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -232,18 +760,354 @@ func (x *ListResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListResponse.ProtoReflect.Descriptor instead.
-func (*ListResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use LocalizedText.ProtoReflect.Descriptor instead.
+func (*LocalizedText) Descriptor() ([]byte, []int) {
 	return file_inventory_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *ListResponse) GetProducts() []*Product {
+func (x *LocalizedText) GetLocale() string {
 	if x != nil {
-		return x.Products
+		return x.Locale
+	}
+	return ""
+}
+
+func (x *LocalizedText) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *LocalizedText) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+// Money is an amount of a currency, like google.type.Money.
+type Money struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// currency_code is the ISO 4217 code, e.g. RUB; the default currency
+	// if empty.
+	CurrencyCode string `protobuf:"bytes,1,opt,name=currency_code,json=currencyCode,proto3" json:"currency_code,omitempty"`
+	// units is the whole part of the amount.
+	Units int64 `protobuf:"varint,2,opt,name=units,proto3" json:"units,omitempty"`
+	// nanos is the fraction of the amount in 10^-9 units, with the sign of
+	// units. Prices are kept in cents, so it must be a multiple of
+	// 10,000,000.
+	Nanos         int32 `protobuf:"varint,3,opt,name=nanos,proto3" json:"nanos,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Money) Reset() {
+	*x = Money{}
+	mi := &file_inventory_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Money) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Money) ProtoMessage() {}
+
+func (x *Money) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Money.ProtoReflect.Descriptor instead.
+func (*Money) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Money) GetCurrencyCode() string {
+	if x != nil {
+		return x.CurrencyCode
+	}
+	return ""
+}
+
+func (x *Money) GetUnits() int64 {
+	if x != nil {
+		return x.Units
+	}
+	return 0
+}
+
+func (x *Money) GetNanos() int32 {
+	if x != nil {
+		return x.Nanos
+	}
+	return 0
+}
+
+type ListRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// page_size is the maximum number of products to return: 50 if unset,
+	// at most 1000.
+	PageSize int32  `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	Filter   string `protobuf:"bytes,3,opt,name=filter,proto3" json:"filter,omitempty"`
+	// order_by is "<field> [asc|desc]" with field price or created_at;
+	// newest first if unset.
+	OrderBy string `protobuf:"bytes,4,opt,name=order_by,json=orderBy,proto3" json:"order_by,omitempty"`
+	// page_token is the next_page_token of the previous page. All other
+	// fields must match the request that returned it.
+	PageToken string  `protobuf:"bytes,5,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	MinPrice  float64 `protobuf:"fixed64,6,opt,name=min_price,json=minPrice,proto3" json:"min_price,omitempty"`
+	MaxPrice  float64 `protobuf:"fixed64,7,opt,name=max_price,json=maxPrice,proto3" json:"max_price,omitempty"`
+	// name_query matches a case-insensitive substring of the name.
+	NameQuery string `protobuf:"bytes,8,opt,name=name_query,json=nameQuery,proto3" json:"name_query,omitempty"`
+	// tags lists tags a product must all have, in addition to filter.
+	Tags []string `protobuf:"bytes,9,rep,name=tags,proto3" json:"tags,omitempty"`
+	// available defaults to true unless statuses is set; set it to false to
+	// list products that are not ACTIVE.
+	Available *bool `protobuf:"varint,10,opt,name=available,proto3,oneof" json:"available,omitempty"`
+	// category_id lists products of the category and its subcategories.
+	CategoryId string `protobuf:"bytes,11,opt,name=category_id,json=categoryId,proto3" json:"category_id,omitempty"`
+	// read_mask lists the product fields to return; see GetRequest.
+	ReadMask *fieldmaskpb.FieldMask `protobuf:"bytes,12,opt,name=read_mask,json=readMask,proto3" json:"read_mask,omitempty"`
+	// sku and barcode list the product with exactly that SKU or barcode.
+	Sku     string `protobuf:"bytes,13,opt,name=sku,proto3" json:"sku,omitempty"`
+	Barcode string `protobuf:"bytes,14,opt,name=barcode,proto3" json:"barcode,omitempty"`
+	// statuses lists products in any of these statuses.
+	Statuses []Product_Status `protobuf:"varint,15,rep,packed,name=statuses,proto3,enum=inventory.Product_Status" json:"statuses,omitempty"`
+	// attributes lists products having all these attributes with these
+	// values, e.g. {"color": "red"} for attributes.color = "red".
+	Attributes map[string]string `protobuf:"bytes,16,rep,name=attributes,proto3" json:"attributes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// locale picks the translation of each product; see GetRequest.
+	Locale string `protobuf:"bytes,17,opt,name=locale,proto3" json:"locale,omitempty"`
+	// expand_variants returns the variants of each product; see GetRequest.
+	ExpandVariants bool `protobuf:"varint,18,opt,name=expand_variants,json=expandVariants,proto3" json:"expand_variants,omitempty"`
+	// currency converts the prices of each product; see GetRequest. The
+	// price filters still apply to the stored prices.
+	Currency      string `protobuf:"bytes,19,opt,name=currency,proto3" json:"currency,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRequest) Reset() {
+	*x = ListRequest{}
+	mi := &file_inventory_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRequest) ProtoMessage() {}
+
+func (x *ListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRequest.ProtoReflect.Descriptor instead.
+func (*ListRequest) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListRequest) GetFilter() string {
+	if x != nil {
+		return x.Filter
+	}
+	return ""
+}
+
+func (x *ListRequest) GetOrderBy() string {
+	if x != nil {
+		return x.OrderBy
+	}
+	return ""
+}
+
+func (x *ListRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListRequest) GetMinPrice() float64 {
+	if x != nil {
+		return x.MinPrice
+	}
+	return 0
+}
+
+func (x *ListRequest) GetMaxPrice() float64 {
+	if x != nil {
+		return x.MaxPrice
+	}
+	return 0
+}
+
+func (x *ListRequest) GetNameQuery() string {
+	if x != nil {
+		return x.NameQuery
+	}
+	return ""
+}
+
+func (x *ListRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *ListRequest) GetAvailable() bool {
+	if x != nil && x.Available != nil {
+		return *x.Available
+	}
+	return false
+}
+
+func (x *ListRequest) GetCategoryId() string {
+	if x != nil {
+		return x.CategoryId
+	}
+	return ""
+}
+
+func (x *ListRequest) GetReadMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.ReadMask
+	}
+	return nil
+}
+
+func (x *ListRequest) GetSku() string {
+	if x != nil {
+		return x.Sku
+	}
+	return ""
+}
+
+func (x *ListRequest) GetBarcode() string {
+	if x != nil {
+		return x.Barcode
+	}
+	return ""
+}
+
+func (x *ListRequest) GetStatuses() []Product_Status {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+func (x *ListRequest) GetAttributes() map[string]string {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+func (x *ListRequest) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
+func (x *ListRequest) GetExpandVariants() bool {
+	if x != nil {
+		return x.ExpandVariants
+	}
+	return false
+}
+
+func (x *ListRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+type ListResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Products []*Product             `protobuf:"bytes,1,rep,name=products,proto3" json:"products,omitempty"`
+	// next_page_token is an opaque token to pass as page_token to get the
+	// next page; empty on the last page.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	TotalSize     int32  `protobuf:"varint,3,opt,name=total_size,json=totalSize,proto3" json:"total_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListResponse) Reset() {
+	*x = ListResponse{}
+	mi := &file_inventory_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListResponse) ProtoMessage() {}
+
+func (x *ListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListResponse.ProtoReflect.Descriptor instead.
+func (*ListResponse) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListResponse) GetProducts() []*Product {
+	if x != nil {
+		return x.Products
 	}
 	return nil
 }
 
+func (x *ListResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
 func (x *ListResponse) GetTotalSize() int32 {
 	if x != nil {
 		return x.TotalSize
@@ -252,27 +1116,3458 @@ func (x *ListResponse) GetTotalSize() int32 {
 }
 
 type GetRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// read_mask lists the product fields to return, e.g. "name,price"; id is
+	// always returned. All fields if unset.
+	ReadMask *fieldmaskpb.FieldMask `protobuf:"bytes,2,opt,name=read_mask,json=readMask,proto3" json:"read_mask,omitempty"`
+	// locale lists the preferred locales, as in an Accept-Language header,
+	// e.g. "de-AT, de;q=0.9". The name and description of the product are
+	// those of its best matching translation, or its own if none matches.
+	// The accept-language metadata is used when unset.
+	Locale string `protobuf:"bytes,3,opt,name=locale,proto3" json:"locale,omitempty"`
+	// expand_variants returns the variants of the product in its variants
+	// field; products are returned without them otherwise.
+	ExpandVariants bool `protobuf:"varint,4,opt,name=expand_variants,json=expandVariants,proto3" json:"expand_variants,omitempty"`
+	// currency is the ISO 4217 code, e.g. USD, to return the prices in,
+	// converted at the current exchange rates and rounded to cents. Prices
+	// are returned in the currency they are stored in when unset.
+	Currency      string `protobuf:"bytes,5,opt,name=currency,proto3" json:"currency,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRequest) Reset() {
+	*x = GetRequest{}
+	mi := &file_inventory_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRequest) ProtoMessage() {}
+
+func (x *GetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRequest.ProtoReflect.Descriptor instead.
+func (*GetRequest) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GetRequest) GetReadMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.ReadMask
+	}
+	return nil
+}
+
+func (x *GetRequest) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
+func (x *GetRequest) GetExpandVariants() bool {
+	if x != nil {
+		return x.ExpandVariants
+	}
+	return false
+}
+
+func (x *GetRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+type GetBySkuRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sku           string                 `protobuf:"bytes,1,opt,name=sku,proto3" json:"sku,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBySkuRequest) Reset() {
+	*x = GetBySkuRequest{}
+	mi := &file_inventory_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBySkuRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBySkuRequest) ProtoMessage() {}
+
+func (x *GetBySkuRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBySkuRequest.ProtoReflect.Descriptor instead.
+func (*GetBySkuRequest) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetBySkuRequest) GetSku() string {
+	if x != nil {
+		return x.Sku
+	}
+	return ""
+}
+
+type GetByBarcodeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Barcode       string                 `protobuf:"bytes,1,opt,name=barcode,proto3" json:"barcode,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetByBarcodeRequest) Reset() {
+	*x = GetByBarcodeRequest{}
+	mi := &file_inventory_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetByBarcodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetByBarcodeRequest) ProtoMessage() {}
+
+func (x *GetByBarcodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetByBarcodeRequest.ProtoReflect.Descriptor instead.
+func (*GetByBarcodeRequest) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetByBarcodeRequest) GetBarcode() string {
+	if x != nil {
+		return x.Barcode
+	}
+	return ""
+}
+
+type GetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Product       *Product               `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetResponse) Reset() {
+	*x = GetResponse{}
+	mi := &file_inventory_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetResponse) ProtoMessage() {}
+
+func (x *GetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetResponse.ProtoReflect.Descriptor instead.
+func (*GetResponse) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetResponse) GetProduct() *Product {
+	if x != nil {
+		return x.Product
+	}
+	return nil
+}
+
+type CreateRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Product *Product               `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+	// idempotency_key makes retries of the request return the product
+	// created first instead of a duplicate, e.g. a UUID per form submit.
+	// It may also be sent as idempotency-key metadata.
+	IdempotencyKey string `protobuf:"bytes,2,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CreateRequest) Reset() {
+	*x = CreateRequest{}
+	mi := &file_inventory_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateRequest) ProtoMessage() {}
+
+func (x *CreateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateRequest.ProtoReflect.Descriptor instead.
+func (*CreateRequest) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *CreateRequest) GetProduct() *Product {
+	if x != nil {
+		return x.Product
+	}
+	return nil
+}
+
+func (x *CreateRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+type CreateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Product       *Product               `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateResponse) Reset() {
+	*x = CreateResponse{}
+	mi := &file_inventory_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateResponse) ProtoMessage() {}
+
+func (x *CreateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateResponse.ProtoReflect.Descriptor instead.
+func (*CreateResponse) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CreateResponse) GetProduct() *Product {
+	if x != nil {
+		return x.Product
+	}
+	return nil
+}
+
+type UpdateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Product       *Product               `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+	UpdateMask    *fieldmaskpb.FieldMask `protobuf:"bytes,2,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateRequest) Reset() {
+	*x = UpdateRequest{}
+	mi := &file_inventory_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateRequest) ProtoMessage() {}
+
+func (x *UpdateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateRequest.ProtoReflect.Descriptor instead.
+func (*UpdateRequest) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *UpdateRequest) GetProduct() *Product {
+	if x != nil {
+		return x.Product
+	}
+	return nil
+}
+
+func (x *UpdateRequest) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+type UpdateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Product       *Product               `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateResponse) Reset() {
+	*x = UpdateResponse{}
+	mi := &file_inventory_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateResponse) ProtoMessage() {}
+
+func (x *UpdateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateResponse.ProtoReflect.Descriptor instead.
+func (*UpdateResponse) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *UpdateResponse) GetProduct() *Product {
+	if x != nil {
+		return x.Product
+	}
+	return nil
+}
+
+type DeleteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRequest) Reset() {
+	*x = DeleteRequest{}
+	mi := &file_inventory_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRequest) ProtoMessage() {}
+
+func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRequest.ProtoReflect.Descriptor instead.
+func (*DeleteRequest) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *DeleteRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DeleteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteResponse) Reset() {
+	*x = DeleteResponse{}
+	mi := &file_inventory_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteResponse) ProtoMessage() {}
+
+func (x *DeleteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteResponse.ProtoReflect.Descriptor instead.
+func (*DeleteResponse) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *DeleteResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+// BatchDeleteRequest deletes all products matching every set field,
+// in stock or not. At least one field must be set.
+type BatchDeleteRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	MinPrice float64                `protobuf:"fixed64,1,opt,name=min_price,json=minPrice,proto3" json:"min_price,omitempty"`
+	MaxPrice float64                `protobuf:"fixed64,2,opt,name=max_price,json=maxPrice,proto3" json:"max_price,omitempty"`
+	// name_query matches a case-insensitive substring of the name.
+	NameQuery string `protobuf:"bytes,3,opt,name=name_query,json=nameQuery,proto3" json:"name_query,omitempty"`
+	// tags lists tags a product must all have.
+	Tags []string `protobuf:"bytes,4,rep,name=tags,proto3" json:"tags,omitempty"`
+	// available matches products with this availability; unset matches both.
+	Available *bool `protobuf:"varint,5,opt,name=available,proto3,oneof" json:"available,omitempty"`
+	// category_id matches products of the category and its subcategories.
+	CategoryId    string `protobuf:"bytes,6,opt,name=category_id,json=categoryId,proto3" json:"category_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchDeleteRequest) Reset() {
+	*x = BatchDeleteRequest{}
+	mi := &file_inventory_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchDeleteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchDeleteRequest) ProtoMessage() {}
+
+func (x *BatchDeleteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchDeleteRequest.ProtoReflect.Descriptor instead.
+func (*BatchDeleteRequest) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *BatchDeleteRequest) GetMinPrice() float64 {
+	if x != nil {
+		return x.MinPrice
+	}
+	return 0
+}
+
+func (x *BatchDeleteRequest) GetMaxPrice() float64 {
+	if x != nil {
+		return x.MaxPrice
+	}
+	return 0
+}
+
+func (x *BatchDeleteRequest) GetNameQuery() string {
+	if x != nil {
+		return x.NameQuery
+	}
+	return ""
+}
+
+func (x *BatchDeleteRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *BatchDeleteRequest) GetAvailable() bool {
+	if x != nil && x.Available != nil {
+		return *x.Available
+	}
+	return false
+}
+
+func (x *BatchDeleteRequest) GetCategoryId() string {
+	if x != nil {
+		return x.CategoryId
+	}
+	return ""
+}
+
+type BatchDeleteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeletedCount  int64                  `protobuf:"varint,1,opt,name=deleted_count,json=deletedCount,proto3" json:"deleted_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchDeleteResponse) Reset() {
+	*x = BatchDeleteResponse{}
+	mi := &file_inventory_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchDeleteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchDeleteResponse) ProtoMessage() {}
+
+func (x *BatchDeleteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchDeleteResponse.ProtoReflect.Descriptor instead.
+func (*BatchDeleteResponse) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *BatchDeleteResponse) GetDeletedCount() int64 {
+	if x != nil {
+		return x.DeletedCount
+	}
+	return 0
+}
+
+type BatchUpdateProductsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// requests holds at most 1000 updates, each as for UpdateProduct.
+	Requests      []*UpdateRequest `protobuf:"bytes,1,rep,name=requests,proto3" json:"requests,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchUpdateProductsRequest) Reset() {
+	*x = BatchUpdateProductsRequest{}
+	mi := &file_inventory_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchUpdateProductsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchUpdateProductsRequest) ProtoMessage() {}
+
+func (x *BatchUpdateProductsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchUpdateProductsRequest.ProtoReflect.Descriptor instead.
+func (*BatchUpdateProductsRequest) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *BatchUpdateProductsRequest) GetRequests() []*UpdateRequest {
+	if x != nil {
+		return x.Requests
+	}
+	return nil
+}
+
+type BatchUpdateResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// product is the updated product; unset unless the batch was applied.
+	Product *Product `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+	// code is the google.rpc.Code of the update, OK (0) when it succeeded,
+	// and message describes a failure.
+	Code          int32  `protobuf:"varint,2,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchUpdateResult) Reset() {
+	*x = BatchUpdateResult{}
+	mi := &file_inventory_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchUpdateResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchUpdateResult) ProtoMessage() {}
+
+func (x *BatchUpdateResult) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchUpdateResult.ProtoReflect.Descriptor instead.
+func (*BatchUpdateResult) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *BatchUpdateResult) GetProduct() *Product {
+	if x != nil {
+		return x.Product
+	}
+	return nil
+}
+
+func (x *BatchUpdateResult) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *BatchUpdateResult) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type BatchUpdateProductsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// applied is false when any update failed; nothing was changed then.
+	Applied       bool                 `protobuf:"varint,1,opt,name=applied,proto3" json:"applied,omitempty"`
+	Results       []*BatchUpdateResult `protobuf:"bytes,2,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchUpdateProductsResponse) Reset() {
+	*x = BatchUpdateProductsResponse{}
+	mi := &file_inventory_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchUpdateProductsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchUpdateProductsResponse) ProtoMessage() {}
+
+func (x *BatchUpdateProductsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchUpdateProductsResponse.ProtoReflect.Descriptor instead.
+func (*BatchUpdateProductsResponse) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *BatchUpdateProductsResponse) GetApplied() bool {
+	if x != nil {
+		return x.Applied
+	}
+	return false
+}
+
+func (x *BatchUpdateProductsResponse) GetResults() []*BatchUpdateResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type ImportProductsCsvRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// csv is the UTF-8 file with a header row. Its size is bounded by the
+	// maximum message size of the server.
+	Csv []byte `protobuf:"bytes,1,opt,name=csv,proto3" json:"csv,omitempty"`
+	// dry_run validates the rows without writing them.
+	DryRun        bool `protobuf:"varint,2,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportProductsCsvRequest) Reset() {
+	*x = ImportProductsCsvRequest{}
+	mi := &file_inventory_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportProductsCsvRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportProductsCsvRequest) ProtoMessage() {}
+
+func (x *ImportProductsCsvRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportProductsCsvRequest.ProtoReflect.Descriptor instead.
+func (*ImportProductsCsvRequest) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ImportProductsCsvRequest) GetCsv() []byte {
+	if x != nil {
+		return x.Csv
+	}
+	return nil
+}
+
+func (x *ImportProductsCsvRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+// ImportRowError is a row ImportProductsCsv skipped.
+type ImportRowError struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// line is the line of the row in the file; the header is line 1.
+	Line int32  `protobuf:"varint,1,opt,name=line,proto3" json:"line,omitempty"`
+	Sku  string `protobuf:"bytes,2,opt,name=sku,proto3" json:"sku,omitempty"`
+	// code is the google.rpc.Code of the failure and message describes it.
+	Code          int32  `protobuf:"varint,3,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportRowError) Reset() {
+	*x = ImportRowError{}
+	mi := &file_inventory_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportRowError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportRowError) ProtoMessage() {}
+
+func (x *ImportRowError) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportRowError.ProtoReflect.Descriptor instead.
+func (*ImportRowError) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ImportRowError) GetLine() int32 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+func (x *ImportRowError) GetSku() string {
+	if x != nil {
+		return x.Sku
+	}
+	return ""
+}
+
+func (x *ImportRowError) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *ImportRowError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type ImportProductsCsvResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// rows is the number of rows read, imported the number of rows
+	// written; zero for dry runs.
+	Rows     int32 `protobuf:"varint,1,opt,name=rows,proto3" json:"rows,omitempty"`
+	Imported int32 `protobuf:"varint,2,opt,name=imported,proto3" json:"imported,omitempty"`
+	// errors are the skipped rows, by line.
+	Errors        []*ImportRowError `protobuf:"bytes,3,rep,name=errors,proto3" json:"errors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportProductsCsvResponse) Reset() {
+	*x = ImportProductsCsvResponse{}
+	mi := &file_inventory_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportProductsCsvResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportProductsCsvResponse) ProtoMessage() {}
+
+func (x *ImportProductsCsvResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportProductsCsvResponse.ProtoReflect.Descriptor instead.
+func (*ImportProductsCsvResponse) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ImportProductsCsvResponse) GetRows() int32 {
+	if x != nil {
+		return x.Rows
+	}
+	return 0
+}
+
+func (x *ImportProductsCsvResponse) GetImported() int32 {
+	if x != nil {
+		return x.Imported
+	}
+	return 0
+}
+
+func (x *ImportProductsCsvResponse) GetErrors() []*ImportRowError {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+type ExportProductsRequest struct {
+	state  protoimpl.MessageState       `protogen:"open.v1"`
+	Format ExportProductsRequest_Format `protobuf:"varint,1,opt,name=format,proto3,enum=inventory.ExportProductsRequest_Format" json:"format,omitempty"`
+	// filter selects the products like the filter fields of ListProducts,
+	// in stock and ACTIVE by default. Its paging, order, read mask, locale,
+	// variants and currency are ignored: products are exported by id, as
+	// stored.
+	Filter        *ListRequest `protobuf:"bytes,2,opt,name=filter,proto3" json:"filter,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportProductsRequest) Reset() {
+	*x = ExportProductsRequest{}
+	mi := &file_inventory_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportProductsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportProductsRequest) ProtoMessage() {}
+
+func (x *ExportProductsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportProductsRequest.ProtoReflect.Descriptor instead.
+func (*ExportProductsRequest) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ExportProductsRequest) GetFormat() ExportProductsRequest_Format {
+	if x != nil {
+		return x.Format
+	}
+	return ExportProductsRequest_FORMAT_UNSPECIFIED
+}
+
+func (x *ExportProductsRequest) GetFilter() *ListRequest {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+type ExportProductsChunk struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// data is the next part of the file; the chunks concatenated are the
+	// file. Chunks end anywhere, not only at the end of a row.
+	Data          []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportProductsChunk) Reset() {
+	*x = ExportProductsChunk{}
+	mi := &file_inventory_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportProductsChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportProductsChunk) ProtoMessage() {}
+
+func (x *ExportProductsChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportProductsChunk.ProtoReflect.Descriptor instead.
+func (*ExportProductsChunk) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ExportProductsChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type Tag struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// name is the tag as stored on products.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// registered is whether the tag is in the registry rather than only
+	// on products.
+	Registered bool `protobuf:"varint,2,opt,name=registered,proto3" json:"registered,omitempty"`
+	// aliases are the tags written as this tag, in lower case.
+	Aliases []string `protobuf:"bytes,3,rep,name=aliases,proto3" json:"aliases,omitempty"`
+	// product_count is the number of products with the tag.
+	ProductCount  int64 `protobuf:"varint,4,opt,name=product_count,json=productCount,proto3" json:"product_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Tag) Reset() {
+	*x = Tag{}
+	mi := &file_inventory_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Tag) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Tag) ProtoMessage() {}
+
+func (x *Tag) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Tag.ProtoReflect.Descriptor instead.
+func (*Tag) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *Tag) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Tag) GetRegistered() bool {
+	if x != nil {
+		return x.Registered
+	}
+	return false
+}
+
+func (x *Tag) GetAliases() []string {
+	if x != nil {
+		return x.Aliases
+	}
+	return nil
+}
+
+func (x *Tag) GetProductCount() int64 {
+	if x != nil {
+		return x.ProductCount
+	}
+	return 0
+}
+
+type ListTagsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTagsRequest) Reset() {
+	*x = ListTagsRequest{}
+	mi := &file_inventory_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTagsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTagsRequest) ProtoMessage() {}
+
+func (x *ListTagsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTagsRequest.ProtoReflect.Descriptor instead.
+func (*ListTagsRequest) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{27}
+}
+
+type ListTagsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tags          []*Tag                 `protobuf:"bytes,1,rep,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTagsResponse) Reset() {
+	*x = ListTagsResponse{}
+	mi := &file_inventory_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTagsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTagsResponse) ProtoMessage() {}
+
+func (x *ListTagsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTagsResponse.ProtoReflect.Descriptor instead.
+func (*ListTagsResponse) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ListTagsResponse) GetTags() []*Tag {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type MergeTagsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// name is the tag to keep, in its canonical casing.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// from are the tags merged into name. Without them name is only
+	// registered, or its casing changed.
+	From          []string `protobuf:"bytes,2,rep,name=from,proto3" json:"from,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MergeTagsRequest) Reset() {
+	*x = MergeTagsRequest{}
+	mi := &file_inventory_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MergeTagsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MergeTagsRequest) ProtoMessage() {}
+
+func (x *MergeTagsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MergeTagsRequest.ProtoReflect.Descriptor instead.
+func (*MergeTagsRequest) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *MergeTagsRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *MergeTagsRequest) GetFrom() []string {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+type MergeTagsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Tag   *Tag                   `protobuf:"bytes,1,opt,name=tag,proto3" json:"tag,omitempty"`
+	// retagged_products is the number of products whose tags were
+	// rewritten, deleted ones included.
+	RetaggedProducts int64 `protobuf:"varint,2,opt,name=retagged_products,json=retaggedProducts,proto3" json:"retagged_products,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *MergeTagsResponse) Reset() {
+	*x = MergeTagsResponse{}
+	mi := &file_inventory_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MergeTagsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MergeTagsResponse) ProtoMessage() {}
+
+func (x *MergeTagsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MergeTagsResponse.ProtoReflect.Descriptor instead.
+func (*MergeTagsResponse) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *MergeTagsResponse) GetTag() *Tag {
+	if x != nil {
+		return x.Tag
+	}
+	return nil
+}
+
+func (x *MergeTagsResponse) GetRetaggedProducts() int64 {
+	if x != nil {
+		return x.RetaggedProducts
+	}
+	return 0
+}
+
+type FieldChange struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// field is the changed column of the product, e.g. price_cents.
+	Field string `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	// before and after are the values of the column as JSON, e.g. 149990
+	// or ["sale","new"]; null for inserted and deleted products.
+	Before        string `protobuf:"bytes,2,opt,name=before,proto3" json:"before,omitempty"`
+	After         string `protobuf:"bytes,3,opt,name=after,proto3" json:"after,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FieldChange) Reset() {
+	*x = FieldChange{}
+	mi := &file_inventory_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FieldChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FieldChange) ProtoMessage() {}
+
+func (x *FieldChange) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FieldChange.ProtoReflect.Descriptor instead.
+func (*FieldChange) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *FieldChange) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *FieldChange) GetBefore() string {
+	if x != nil {
+		return x.Before
+	}
+	return ""
+}
+
+func (x *FieldChange) GetAfter() string {
+	if x != nil {
+		return x.After
+	}
+	return ""
+}
+
+type AuditEntry struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	ProductId string                 `protobuf:"bytes,2,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Operation AuditEntry_Operation   `protobuf:"varint,3,opt,name=operation,proto3,enum=inventory.AuditEntry_Operation" json:"operation,omitempty"`
+	// actor is the principal of the change; empty for anonymous requests
+	// and background jobs.
+	Actor string `protobuf:"bytes,4,opt,name=actor,proto3" json:"actor,omitempty"`
+	// rpc is the full method of the change, e.g.
+	// "/inventory.InventoryService/UpdateProduct"; empty outside requests.
+	Rpc string `protobuf:"bytes,5,opt,name=rpc,proto3" json:"rpc,omitempty"`
+	// changes are the changed fields, by name. created_* and updated_*
+	// are left out.
+	Changes       []*FieldChange         `protobuf:"bytes,6,rep,name=changes,proto3" json:"changes,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuditEntry) Reset() {
+	*x = AuditEntry{}
+	mi := &file_inventory_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuditEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditEntry) ProtoMessage() {}
+
+func (x *AuditEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditEntry.ProtoReflect.Descriptor instead.
+func (*AuditEntry) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *AuditEntry) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *AuditEntry) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetOperation() AuditEntry_Operation {
+	if x != nil {
+		return x.Operation
+	}
+	return AuditEntry_OPERATION_UNSPECIFIED
+}
+
+func (x *AuditEntry) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetRpc() string {
+	if x != nil {
+		return x.Rpc
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetChanges() []*FieldChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+func (x *AuditEntry) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type ListAuditEntriesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// page_size is the maximum number of entries to return: 50 if unset,
+	// at most 1000.
+	PageSize int32 `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// page_token is the next_page_token of the previous page.
+	PageToken string `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	// product_id, actor, start_time (inclusive) and end_time (exclusive)
+	// narrow the entries when set.
+	ProductId     string                 `protobuf:"bytes,3,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Actor         string                 `protobuf:"bytes,4,opt,name=actor,proto3" json:"actor,omitempty"`
+	StartTime     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime       *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAuditEntriesRequest) Reset() {
+	*x = ListAuditEntriesRequest{}
+	mi := &file_inventory_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAuditEntriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAuditEntriesRequest) ProtoMessage() {}
+
+func (x *ListAuditEntriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAuditEntriesRequest.ProtoReflect.Descriptor instead.
+func (*ListAuditEntriesRequest) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ListAuditEntriesRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListAuditEntriesRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListAuditEntriesRequest) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *ListAuditEntriesRequest) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *ListAuditEntriesRequest) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *ListAuditEntriesRequest) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+type ListAuditEntriesResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Entries []*AuditEntry          `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	// next_page_token is an opaque token to pass as page_token to get the
+	// next page; empty on the last page.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAuditEntriesResponse) Reset() {
+	*x = ListAuditEntriesResponse{}
+	mi := &file_inventory_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAuditEntriesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAuditEntriesResponse) ProtoMessage() {}
+
+func (x *ListAuditEntriesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAuditEntriesResponse.ProtoReflect.Descriptor instead.
+func (*ListAuditEntriesResponse) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *ListAuditEntriesResponse) GetEntries() []*AuditEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *ListAuditEntriesResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type PurchaseRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ProductId string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	// quantity is the number of units to take out of stock; must be positive.
+	Quantity      int32 `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PurchaseRequest) Reset() {
+	*x = PurchaseRequest{}
+	mi := &file_inventory_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PurchaseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PurchaseRequest) ProtoMessage() {}
+
+func (x *PurchaseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PurchaseRequest.ProtoReflect.Descriptor instead.
+func (*PurchaseRequest) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *PurchaseRequest) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *PurchaseRequest) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+type PurchaseResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// remaining_quantity is the stock left after the purchase.
+	RemainingQuantity int32 `protobuf:"varint,1,opt,name=remaining_quantity,json=remainingQuantity,proto3" json:"remaining_quantity,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *PurchaseResponse) Reset() {
+	*x = PurchaseResponse{}
+	mi := &file_inventory_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PurchaseResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PurchaseResponse) ProtoMessage() {}
+
+func (x *PurchaseResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PurchaseResponse.ProtoReflect.Descriptor instead.
+func (*PurchaseResponse) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *PurchaseResponse) GetRemainingQuantity() int32 {
+	if x != nil {
+		return x.RemainingQuantity
+	}
+	return 0
+}
+
+type AdjustStockRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ProductId string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	// delta is the change of quantity; must not be zero.
+	Delta int32 `protobuf:"varint,2,opt,name=delta,proto3" json:"delta,omitempty"`
+	// reason is required.
+	Reason AdjustStockRequest_Reason `protobuf:"varint,3,opt,name=reason,proto3,enum=inventory.AdjustStockRequest_Reason" json:"reason,omitempty"`
+	// reference is recorded with the movement in the stock ledger, e.g.
+	// the number of the purchase order of received goods. Optional.
+	Reference     string `protobuf:"bytes,4,opt,name=reference,proto3" json:"reference,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdjustStockRequest) Reset() {
+	*x = AdjustStockRequest{}
+	mi := &file_inventory_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdjustStockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdjustStockRequest) ProtoMessage() {}
+
+func (x *AdjustStockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdjustStockRequest.ProtoReflect.Descriptor instead.
+func (*AdjustStockRequest) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *AdjustStockRequest) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *AdjustStockRequest) GetDelta() int32 {
+	if x != nil {
+		return x.Delta
+	}
+	return 0
+}
+
+func (x *AdjustStockRequest) GetReason() AdjustStockRequest_Reason {
+	if x != nil {
+		return x.Reason
+	}
+	return AdjustStockRequest_REASON_UNSPECIFIED
+}
+
+func (x *AdjustStockRequest) GetReference() string {
+	if x != nil {
+		return x.Reference
+	}
+	return ""
+}
+
+type AdjustStockResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// quantity is the stock after the adjustment.
+	Quantity      int32 `protobuf:"varint,1,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdjustStockResponse) Reset() {
+	*x = AdjustStockResponse{}
+	mi := &file_inventory_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdjustStockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdjustStockResponse) ProtoMessage() {}
+
+func (x *AdjustStockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdjustStockResponse.ProtoReflect.Descriptor instead.
+func (*AdjustStockResponse) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *AdjustStockResponse) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+// ReorderSuggestion is a product to replenish, as of computed_at.
+type ReorderSuggestion struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ProductId string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	// quantity, reorder_point and reorder_quantity are those of the
+	// product when the suggestion was computed.
+	Quantity        int32 `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	ReorderPoint    int32 `protobuf:"varint,3,opt,name=reorder_point,json=reorderPoint,proto3" json:"reorder_point,omitempty"`
+	ReorderQuantity int32 `protobuf:"varint,4,opt,name=reorder_quantity,json=reorderQuantity,proto3" json:"reorder_quantity,omitempty"`
+	// daily_outflow is the average of the units sold per day over the
+	// outflow window, e.g. the last 28 days.
+	DailyOutflow float64 `protobuf:"fixed64,5,opt,name=daily_outflow,json=dailyOutflow,proto3" json:"daily_outflow,omitempty"`
+	// days_of_cover is how many days quantity lasts at daily_outflow;
+	// infinity without outflow.
+	DaysOfCover float64 `protobuf:"fixed64,6,opt,name=days_of_cover,json=daysOfCover,proto3" json:"days_of_cover,omitempty"`
+	// suggested_quantity is the quantity to order: enough to cover the
+	// daily_outflow for the cover period on top of the reorder point, and
+	// at least reorder_quantity.
+	SuggestedQuantity int32                  `protobuf:"varint,7,opt,name=suggested_quantity,json=suggestedQuantity,proto3" json:"suggested_quantity,omitempty"`
+	ComputedAt        *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=computed_at,json=computedAt,proto3" json:"computed_at,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *ReorderSuggestion) Reset() {
+	*x = ReorderSuggestion{}
+	mi := &file_inventory_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReorderSuggestion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReorderSuggestion) ProtoMessage() {}
+
+func (x *ReorderSuggestion) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReorderSuggestion.ProtoReflect.Descriptor instead.
+func (*ReorderSuggestion) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *ReorderSuggestion) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *ReorderSuggestion) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *ReorderSuggestion) GetReorderPoint() int32 {
+	if x != nil {
+		return x.ReorderPoint
+	}
+	return 0
+}
+
+func (x *ReorderSuggestion) GetReorderQuantity() int32 {
+	if x != nil {
+		return x.ReorderQuantity
+	}
+	return 0
+}
+
+func (x *ReorderSuggestion) GetDailyOutflow() float64 {
+	if x != nil {
+		return x.DailyOutflow
+	}
+	return 0
+}
+
+func (x *ReorderSuggestion) GetDaysOfCover() float64 {
+	if x != nil {
+		return x.DaysOfCover
+	}
+	return 0
+}
+
+func (x *ReorderSuggestion) GetSuggestedQuantity() int32 {
+	if x != nil {
+		return x.SuggestedQuantity
+	}
+	return 0
+}
+
+func (x *ReorderSuggestion) GetComputedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ComputedAt
+	}
+	return nil
+}
+
+type ListReorderSuggestionsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// page_size is the maximum number of suggestions to return: 50 if
+	// unset, at most 1000.
+	PageSize int32 `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// page_token is the next_page_token of the previous page.
+	PageToken     string `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListReorderSuggestionsRequest) Reset() {
+	*x = ListReorderSuggestionsRequest{}
+	mi := &file_inventory_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListReorderSuggestionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListReorderSuggestionsRequest) ProtoMessage() {}
+
+func (x *ListReorderSuggestionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListReorderSuggestionsRequest.ProtoReflect.Descriptor instead.
+func (*ListReorderSuggestionsRequest) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *ListReorderSuggestionsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListReorderSuggestionsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListReorderSuggestionsResponse struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Suggestions []*ReorderSuggestion   `protobuf:"bytes,1,rep,name=suggestions,proto3" json:"suggestions,omitempty"`
+	// next_page_token is an opaque token to pass as page_token to get the
+	// next page; empty on the last page.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListReorderSuggestionsResponse) Reset() {
+	*x = ListReorderSuggestionsResponse{}
+	mi := &file_inventory_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListReorderSuggestionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListReorderSuggestionsResponse) ProtoMessage() {}
+
+func (x *ListReorderSuggestionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListReorderSuggestionsResponse.ProtoReflect.Descriptor instead.
+func (*ListReorderSuggestionsResponse) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *ListReorderSuggestionsResponse) GetSuggestions() []*ReorderSuggestion {
+	if x != nil {
+		return x.Suggestions
+	}
+	return nil
+}
+
+func (x *ListReorderSuggestionsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+// Lot is a batch of a product received together, traced by its number.
+type Lot struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ProductId string                 `protobuf:"bytes,2,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	// lot_number is the number of the lot on its packaging, unique per
+	// product.
+	LotNumber string `protobuf:"bytes,3,opt,name=lot_number,json=lotNumber,proto3" json:"lot_number,omitempty"`
+	// expiry_date is the last day the lot is fit for sale, as YYYY-MM-DD.
+	ExpiryDate string `protobuf:"bytes,4,opt,name=expiry_date,json=expiryDate,proto3" json:"expiry_date,omitempty"`
+	// quantity is the units of the lot left in stock.
+	Quantity      int32                  `protobuf:"varint,5,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Lot) Reset() {
+	*x = Lot{}
+	mi := &file_inventory_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Lot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Lot) ProtoMessage() {}
+
+func (x *Lot) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Lot.ProtoReflect.Descriptor instead.
+func (*Lot) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *Lot) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Lot) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *Lot) GetLotNumber() string {
+	if x != nil {
+		return x.LotNumber
+	}
+	return ""
+}
+
+func (x *Lot) GetExpiryDate() string {
+	if x != nil {
+		return x.ExpiryDate
+	}
+	return ""
+}
+
+func (x *Lot) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *Lot) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type ReceiveLotRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ProductId string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	// lot_number is required, up to 64 characters.
+	LotNumber string `protobuf:"bytes,2,opt,name=lot_number,json=lotNumber,proto3" json:"lot_number,omitempty"`
+	// expiry_date is required, as YYYY-MM-DD.
+	ExpiryDate string `protobuf:"bytes,3,opt,name=expiry_date,json=expiryDate,proto3" json:"expiry_date,omitempty"`
+	// quantity is the units received; must be positive.
+	Quantity int32 `protobuf:"varint,4,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	// reference is recorded with the movement in the stock ledger, e.g.
+	// the number of the purchase order. Optional.
+	Reference     string `protobuf:"bytes,5,opt,name=reference,proto3" json:"reference,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReceiveLotRequest) Reset() {
+	*x = ReceiveLotRequest{}
+	mi := &file_inventory_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReceiveLotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReceiveLotRequest) ProtoMessage() {}
+
+func (x *ReceiveLotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReceiveLotRequest.ProtoReflect.Descriptor instead.
+func (*ReceiveLotRequest) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *ReceiveLotRequest) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *ReceiveLotRequest) GetLotNumber() string {
+	if x != nil {
+		return x.LotNumber
+	}
+	return ""
+}
+
+func (x *ReceiveLotRequest) GetExpiryDate() string {
+	if x != nil {
+		return x.ExpiryDate
+	}
+	return ""
+}
+
+func (x *ReceiveLotRequest) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *ReceiveLotRequest) GetReference() string {
+	if x != nil {
+		return x.Reference
+	}
+	return ""
+}
+
+type ReceiveLotResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Lot   *Lot                   `protobuf:"bytes,1,opt,name=lot,proto3" json:"lot,omitempty"`
+	// quantity is the stock of the product after the receipt.
+	Quantity      int32 `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReceiveLotResponse) Reset() {
+	*x = ReceiveLotResponse{}
+	mi := &file_inventory_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReceiveLotResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReceiveLotResponse) ProtoMessage() {}
+
+func (x *ReceiveLotResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReceiveLotResponse.ProtoReflect.Descriptor instead.
+func (*ReceiveLotResponse) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *ReceiveLotResponse) GetLot() *Lot {
+	if x != nil {
+		return x.Lot
+	}
+	return nil
+}
+
+func (x *ReceiveLotResponse) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+type ListExpiringLotsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// days selects the lots expiring on or before today (UTC) plus days;
+	// 0 lists the lots expiring today or earlier. Must not be negative.
+	Days int32 `protobuf:"varint,1,opt,name=days,proto3" json:"days,omitempty"`
+	// product_id restricts the lots to one product. Optional.
+	ProductId string `protobuf:"bytes,2,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	// page_size is the maximum number of lots to return: 50 if unset, at
+	// most 1000.
+	PageSize int32 `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// page_token is the next_page_token of the previous page.
+	PageToken     string `protobuf:"bytes,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListExpiringLotsRequest) Reset() {
+	*x = ListExpiringLotsRequest{}
+	mi := &file_inventory_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListExpiringLotsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListExpiringLotsRequest) ProtoMessage() {}
+
+func (x *ListExpiringLotsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListExpiringLotsRequest.ProtoReflect.Descriptor instead.
+func (*ListExpiringLotsRequest) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *ListExpiringLotsRequest) GetDays() int32 {
+	if x != nil {
+		return x.Days
+	}
+	return 0
+}
+
+func (x *ListExpiringLotsRequest) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *ListExpiringLotsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListExpiringLotsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListExpiringLotsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Lots  []*Lot                 `protobuf:"bytes,1,rep,name=lots,proto3" json:"lots,omitempty"`
+	// next_page_token is an opaque token to pass as page_token to get the
+	// next page; empty on the last page.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListExpiringLotsResponse) Reset() {
+	*x = ListExpiringLotsResponse{}
+	mi := &file_inventory_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListExpiringLotsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListExpiringLotsResponse) ProtoMessage() {}
+
+func (x *ListExpiringLotsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListExpiringLotsResponse.ProtoReflect.Descriptor instead.
+func (*ListExpiringLotsResponse) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *ListExpiringLotsResponse) GetLots() []*Lot {
+	if x != nil {
+		return x.Lots
+	}
+	return nil
+}
+
+func (x *ListExpiringLotsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type Reservation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ProductId     string                 `protobuf:"bytes,2,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	OrderId       string                 `protobuf:"bytes,3,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Quantity      int32                  `protobuf:"varint,4,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	Status        Reservation_Status     `protobuf:"varint,5,opt,name=status,proto3,enum=inventory.Reservation_Status" json:"status,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Reservation) Reset() {
+	*x = Reservation{}
+	mi := &file_inventory_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Reservation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Reservation) ProtoMessage() {}
+
+func (x *Reservation) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Reservation.ProtoReflect.Descriptor instead.
+func (*Reservation) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *Reservation) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Reservation) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *Reservation) GetOrderId() string {
+	if x != nil {
+		return x.OrderId
+	}
+	return ""
+}
+
+func (x *Reservation) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *Reservation) GetStatus() Reservation_Status {
+	if x != nil {
+		return x.Status
+	}
+	return Reservation_STATUS_UNSPECIFIED
+}
+
+func (x *Reservation) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Reservation) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type ReserveStockRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ProductId string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	// quantity is the number of units to hold; must be positive.
+	Quantity int32 `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	// order_id identifies the order the units are held for.
+	OrderId string `protobuf:"bytes,3,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	// ttl is how long the units are held; 15 minutes when unset.
+	Ttl           *durationpb.Duration `protobuf:"bytes,4,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReserveStockRequest) Reset() {
+	*x = ReserveStockRequest{}
+	mi := &file_inventory_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReserveStockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReserveStockRequest) ProtoMessage() {}
+
+func (x *ReserveStockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReserveStockRequest.ProtoReflect.Descriptor instead.
+func (*ReserveStockRequest) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *ReserveStockRequest) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *ReserveStockRequest) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *ReserveStockRequest) GetOrderId() string {
+	if x != nil {
+		return x.OrderId
+	}
+	return ""
+}
+
+func (x *ReserveStockRequest) GetTtl() *durationpb.Duration {
+	if x != nil {
+		return x.Ttl
+	}
+	return nil
+}
+
+type ReservationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReservationRequest) Reset() {
+	*x = ReservationRequest{}
+	mi := &file_inventory_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReservationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReservationRequest) ProtoMessage() {}
+
+func (x *ReservationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReservationRequest.ProtoReflect.Descriptor instead.
+func (*ReservationRequest) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *ReservationRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ReservationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reservation   *Reservation           `protobuf:"bytes,1,opt,name=reservation,proto3" json:"reservation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReservationResponse) Reset() {
+	*x = ReservationResponse{}
+	mi := &file_inventory_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReservationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReservationResponse) ProtoMessage() {}
+
+func (x *ReservationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReservationResponse.ProtoReflect.Descriptor instead.
+func (*ReservationResponse) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *ReservationResponse) GetReservation() *Reservation {
+	if x != nil {
+		return x.Reservation
+	}
+	return nil
+}
+
+type CheckAvailabilityRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// items holds at most 1000 items.
+	Items         []*CheckAvailabilityRequest_Item `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckAvailabilityRequest) Reset() {
+	*x = CheckAvailabilityRequest{}
+	mi := &file_inventory_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckAvailabilityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckAvailabilityRequest) ProtoMessage() {}
+
+func (x *CheckAvailabilityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckAvailabilityRequest.ProtoReflect.Descriptor instead.
+func (*CheckAvailabilityRequest) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *CheckAvailabilityRequest) GetItems() []*CheckAvailabilityRequest_Item {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type ItemAvailability struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	ProductId         string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	RequestedQuantity int32                  `protobuf:"varint,2,opt,name=requested_quantity,json=requestedQuantity,proto3" json:"requested_quantity,omitempty"`
+	// found is false for unknown and deleted products.
+	Found bool `protobuf:"varint,3,opt,name=found,proto3" json:"found,omitempty"`
+	// available_quantity is the stock less the units held by active
+	// reservations.
+	AvailableQuantity int32 `protobuf:"varint,4,opt,name=available_quantity,json=availableQuantity,proto3" json:"available_quantity,omitempty"`
+	// available is true when the product is found, on sale and has at
+	// least requested_quantity units available.
+	Available     bool `protobuf:"varint,5,opt,name=available,proto3" json:"available,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ItemAvailability) Reset() {
+	*x = ItemAvailability{}
+	mi := &file_inventory_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ItemAvailability) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ItemAvailability) ProtoMessage() {}
+
+func (x *ItemAvailability) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ItemAvailability.ProtoReflect.Descriptor instead.
+func (*ItemAvailability) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *ItemAvailability) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *ItemAvailability) GetRequestedQuantity() int32 {
+	if x != nil {
+		return x.RequestedQuantity
+	}
+	return 0
+}
+
+func (x *ItemAvailability) GetFound() bool {
+	if x != nil {
+		return x.Found
+	}
+	return false
+}
+
+func (x *ItemAvailability) GetAvailableQuantity() int32 {
+	if x != nil {
+		return x.AvailableQuantity
+	}
+	return 0
+}
+
+func (x *ItemAvailability) GetAvailable() bool {
+	if x != nil {
+		return x.Available
+	}
+	return false
+}
+
+type CheckAvailabilityResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*ItemAvailability    `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckAvailabilityResponse) Reset() {
+	*x = CheckAvailabilityResponse{}
+	mi := &file_inventory_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckAvailabilityResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckAvailabilityResponse) ProtoMessage() {}
+
+func (x *CheckAvailabilityResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckAvailabilityResponse.ProtoReflect.Descriptor instead.
+func (*CheckAvailabilityResponse) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *CheckAvailabilityResponse) GetItems() []*ItemAvailability {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type WatchProductsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchProductsRequest) Reset() {
+	*x = WatchProductsRequest{}
+	mi := &file_inventory_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchProductsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchProductsRequest) ProtoMessage() {}
+
+func (x *WatchProductsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchProductsRequest.ProtoReflect.Descriptor instead.
+func (*WatchProductsRequest) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{54}
+}
+
+type ProductChange struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Type  ProductChange_Type     `protobuf:"varint,1,opt,name=type,proto3,enum=inventory.ProductChange_Type" json:"type,omitempty"`
+	// product is the product after the change. DELETED changes carry only
+	// the id, and RESET changes none.
+	Product       *Product `protobuf:"bytes,2,opt,name=product,proto3" json:"product,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProductChange) Reset() {
+	*x = ProductChange{}
+	mi := &file_inventory_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProductChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProductChange) ProtoMessage() {}
+
+func (x *ProductChange) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProductChange.ProtoReflect.Descriptor instead.
+func (*ProductChange) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *ProductChange) GetType() ProductChange_Type {
+	if x != nil {
+		return x.Type
+	}
+	return ProductChange_TYPE_UNSPECIFIED
+}
+
+func (x *ProductChange) GetProduct() *Product {
+	if x != nil {
+		return x.Product
+	}
+	return nil
+}
+
+type CreateVariantRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Variant       *Variant               `protobuf:"bytes,1,opt,name=variant,proto3" json:"variant,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateVariantRequest) Reset() {
+	*x = CreateVariantRequest{}
+	mi := &file_inventory_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateVariantRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateVariantRequest) ProtoMessage() {}
+
+func (x *CreateVariantRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateVariantRequest.ProtoReflect.Descriptor instead.
+func (*CreateVariantRequest) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{56}
+}
+
+func (x *CreateVariantRequest) GetVariant() *Variant {
+	if x != nil {
+		return x.Variant
+	}
+	return nil
+}
+
+type GetVariantRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetVariantRequest) Reset() {
+	*x = GetVariantRequest{}
+	mi := &file_inventory_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetVariantRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetVariantRequest) ProtoMessage() {}
+
+func (x *GetVariantRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[57]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetVariantRequest.ProtoReflect.Descriptor instead.
+func (*GetVariantRequest) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{57}
+}
+
+func (x *GetVariantRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type UpdateVariantRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Variant *Variant               `protobuf:"bytes,1,opt,name=variant,proto3" json:"variant,omitempty"`
+	// update_mask lists the fields to update: sku, options, price_delta or
+	// quantity.
+	UpdateMask    *fieldmaskpb.FieldMask `protobuf:"bytes,2,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateVariantRequest) Reset() {
+	*x = UpdateVariantRequest{}
+	mi := &file_inventory_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateVariantRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateVariantRequest) ProtoMessage() {}
+
+func (x *UpdateVariantRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateVariantRequest.ProtoReflect.Descriptor instead.
+func (*UpdateVariantRequest) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{58}
+}
+
+func (x *UpdateVariantRequest) GetVariant() *Variant {
+	if x != nil {
+		return x.Variant
+	}
+	return nil
+}
+
+func (x *UpdateVariantRequest) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+type DeleteVariantRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteVariantRequest) Reset() {
+	*x = DeleteVariantRequest{}
+	mi := &file_inventory_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteVariantRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteVariantRequest) ProtoMessage() {}
+
+func (x *DeleteVariantRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteVariantRequest.ProtoReflect.Descriptor instead.
+func (*DeleteVariantRequest) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{59}
+}
+
+func (x *DeleteVariantRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DeleteVariantResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteVariantResponse) Reset() {
+	*x = DeleteVariantResponse{}
+	mi := &file_inventory_proto_msgTypes[60]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteVariantResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteVariantResponse) ProtoMessage() {}
+
+func (x *DeleteVariantResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[60]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteVariantResponse.ProtoReflect.Descriptor instead.
+func (*DeleteVariantResponse) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{60}
+}
+
+func (x *DeleteVariantResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type ListVariantsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListVariantsRequest) Reset() {
+	*x = ListVariantsRequest{}
+	mi := &file_inventory_proto_msgTypes[61]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListVariantsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListVariantsRequest) ProtoMessage() {}
+
+func (x *ListVariantsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[61]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListVariantsRequest.ProtoReflect.Descriptor instead.
+func (*ListVariantsRequest) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{61}
+}
+
+func (x *ListVariantsRequest) GetProductId() string {
+	if x != nil {
+		return x.ProductId
+	}
+	return ""
+}
+
+type ListVariantsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Variants      []*Variant             `protobuf:"bytes,1,rep,name=variants,proto3" json:"variants,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListVariantsResponse) Reset() {
+	*x = ListVariantsResponse{}
+	mi := &file_inventory_proto_msgTypes[62]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListVariantsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListVariantsResponse) ProtoMessage() {}
+
+func (x *ListVariantsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[62]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListVariantsResponse.ProtoReflect.Descriptor instead.
+func (*ListVariantsResponse) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{62}
+}
+
+func (x *ListVariantsResponse) GetVariants() []*Variant {
+	if x != nil {
+		return x.Variants
+	}
+	return nil
+}
+
+type VariantResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Variant       *Variant               `protobuf:"bytes,1,opt,name=variant,proto3" json:"variant,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VariantResponse) Reset() {
+	*x = VariantResponse{}
+	mi := &file_inventory_proto_msgTypes[63]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VariantResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VariantResponse) ProtoMessage() {}
+
+func (x *VariantResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[63]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VariantResponse.ProtoReflect.Descriptor instead.
+func (*VariantResponse) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{63}
+}
+
+func (x *VariantResponse) GetVariant() *Variant {
+	if x != nil {
+		return x.Variant
+	}
+	return nil
+}
+
+type GetServerInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetServerInfoRequest) Reset() {
+	*x = GetServerInfoRequest{}
+	mi := &file_inventory_proto_msgTypes[64]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetServerInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetServerInfoRequest) ProtoMessage() {}
+
+func (x *GetServerInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[64]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetServerInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetServerInfoRequest) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{64}
+}
+
+type ServerInfo struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// version is the release, e.g. "v1.4.0", or "dev" for local builds.
+	Version string `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	// commit is the git commit the server was built from.
+	Commit string `protobuf:"bytes,2,opt,name=commit,proto3" json:"commit,omitempty"`
+	// build_date is when the server was built, in RFC 3339.
+	BuildDate string `protobuf:"bytes,3,opt,name=build_date,json=buildDate,proto3" json:"build_date,omitempty"`
+	// schema_version is the version of this proto schema the server
+	// implements.
+	SchemaVersion string `protobuf:"bytes,4,opt,name=schema_version,json=schemaVersion,proto3" json:"schema_version,omitempty"`
+	// go_version is the Go release the server was built with.
+	GoVersion     string `protobuf:"bytes,5,opt,name=go_version,json=goVersion,proto3" json:"go_version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServerInfo) Reset() {
+	*x = ServerInfo{}
+	mi := &file_inventory_proto_msgTypes[65]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerInfo) ProtoMessage() {}
+
+func (x *ServerInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[65]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerInfo.ProtoReflect.Descriptor instead.
+func (*ServerInfo) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{65}
+}
+
+func (x *ServerInfo) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *ServerInfo) GetCommit() string {
+	if x != nil {
+		return x.Commit
+	}
+	return ""
+}
+
+func (x *ServerInfo) GetBuildDate() string {
+	if x != nil {
+		return x.BuildDate
+	}
+	return ""
+}
+
+func (x *ServerInfo) GetSchemaVersion() string {
+	if x != nil {
+		return x.SchemaVersion
+	}
+	return ""
+}
+
+func (x *ServerInfo) GetGoVersion() string {
+	if x != nil {
+		return x.GoVersion
+	}
+	return ""
+}
+
+type ProductCreated struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Product       *Product               `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+	OccurredAt    *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetRequest) Reset() {
-	*x = GetRequest{}
-	mi := &file_inventory_proto_msgTypes[3]
+func (x *ProductCreated) Reset() {
+	*x = ProductCreated{}
+	mi := &file_inventory_proto_msgTypes[66]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetRequest) String() string {
+func (x *ProductCreated) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetRequest) ProtoMessage() {}
+func (*ProductCreated) ProtoMessage() {}
 
-func (x *GetRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_inventory_proto_msgTypes[3]
+func (x *ProductCreated) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[66]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -283,40 +4578,51 @@ func (x *GetRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetRequest.ProtoReflect.Descriptor instead.
-func (*GetRequest) Descriptor() ([]byte, []int) {
-	return file_inventory_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use ProductCreated.ProtoReflect.Descriptor instead.
+func (*ProductCreated) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{66}
 }
 
-func (x *GetRequest) GetId() string {
+func (x *ProductCreated) GetProduct() *Product {
 	if x != nil {
-		return x.Id
+		return x.Product
 	}
-	return ""
+	return nil
 }
 
-type GetResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Product       *Product               `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+func (x *ProductCreated) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return nil
+}
+
+type ProductUpdated struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// product is the product after the update.
+	Product *Product `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+	// changed_fields are the paths of the update_mask.
+	ChangedFields []string               `protobuf:"bytes,2,rep,name=changed_fields,json=changedFields,proto3" json:"changed_fields,omitempty"`
+	OccurredAt    *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetResponse) Reset() {
-	*x = GetResponse{}
-	mi := &file_inventory_proto_msgTypes[4]
+func (x *ProductUpdated) Reset() {
+	*x = ProductUpdated{}
+	mi := &file_inventory_proto_msgTypes[67]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetResponse) String() string {
+func (x *ProductUpdated) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetResponse) ProtoMessage() {}
+func (*ProductUpdated) ProtoMessage() {}
 
-func (x *GetResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_inventory_proto_msgTypes[4]
+func (x *ProductUpdated) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[67]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -327,40 +4633,55 @@ func (x *GetResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetResponse.ProtoReflect.Descriptor instead.
-func (*GetResponse) Descriptor() ([]byte, []int) {
-	return file_inventory_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use ProductUpdated.ProtoReflect.Descriptor instead.
+func (*ProductUpdated) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{67}
 }
 
-func (x *GetResponse) GetProduct() *Product {
+func (x *ProductUpdated) GetProduct() *Product {
 	if x != nil {
 		return x.Product
 	}
 	return nil
 }
 
-type CreateRequest struct {
+func (x *ProductUpdated) GetChangedFields() []string {
+	if x != nil {
+		return x.ChangedFields
+	}
+	return nil
+}
+
+func (x *ProductUpdated) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return nil
+}
+
+type ProductDeleted struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Product       *Product               `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	OccurredAt    *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CreateRequest) Reset() {
-	*x = CreateRequest{}
-	mi := &file_inventory_proto_msgTypes[5]
+func (x *ProductDeleted) Reset() {
+	*x = ProductDeleted{}
+	mi := &file_inventory_proto_msgTypes[68]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CreateRequest) String() string {
+func (x *ProductDeleted) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateRequest) ProtoMessage() {}
+func (*ProductDeleted) ProtoMessage() {}
 
-func (x *CreateRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_inventory_proto_msgTypes[5]
+func (x *ProductDeleted) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[68]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -371,40 +4692,53 @@ func (x *CreateRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateRequest.ProtoReflect.Descriptor instead.
-func (*CreateRequest) Descriptor() ([]byte, []int) {
-	return file_inventory_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use ProductDeleted.ProtoReflect.Descriptor instead.
+func (*ProductDeleted) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{68}
 }
 
-func (x *CreateRequest) GetProduct() *Product {
+func (x *ProductDeleted) GetId() string {
 	if x != nil {
-		return x.Product
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ProductDeleted) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
 	}
 	return nil
 }
 
-type CreateResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Product       *Product               `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+type StockChanged struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ProductId string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	// delta is the change of quantity.
+	Delta int32 `protobuf:"varint,2,opt,name=delta,proto3" json:"delta,omitempty"`
+	// quantity is the stock after the change.
+	Quantity      int32                     `protobuf:"varint,3,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	Reason        AdjustStockRequest_Reason `protobuf:"varint,4,opt,name=reason,proto3,enum=inventory.AdjustStockRequest_Reason" json:"reason,omitempty"`
+	OccurredAt    *timestamppb.Timestamp    `protobuf:"bytes,5,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CreateResponse) Reset() {
-	*x = CreateResponse{}
-	mi := &file_inventory_proto_msgTypes[6]
+func (x *StockChanged) Reset() {
+	*x = StockChanged{}
+	mi := &file_inventory_proto_msgTypes[69]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CreateResponse) String() string {
+func (x *StockChanged) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateResponse) ProtoMessage() {}
+func (*StockChanged) ProtoMessage() {}
 
-func (x *CreateResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_inventory_proto_msgTypes[6]
+func (x *StockChanged) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[69]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -415,41 +4749,71 @@ func (x *CreateResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateResponse.ProtoReflect.Descriptor instead.
-func (*CreateResponse) Descriptor() ([]byte, []int) {
-	return file_inventory_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use StockChanged.ProtoReflect.Descriptor instead.
+func (*StockChanged) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{69}
 }
 
-func (x *CreateResponse) GetProduct() *Product {
+func (x *StockChanged) GetProductId() string {
 	if x != nil {
-		return x.Product
+		return x.ProductId
+	}
+	return ""
+}
+
+func (x *StockChanged) GetDelta() int32 {
+	if x != nil {
+		return x.Delta
+	}
+	return 0
+}
+
+func (x *StockChanged) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *StockChanged) GetReason() AdjustStockRequest_Reason {
+	if x != nil {
+		return x.Reason
+	}
+	return AdjustStockRequest_REASON_UNSPECIFIED
+}
+
+func (x *StockChanged) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
 	}
 	return nil
 }
 
-type UpdateRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Product       *Product               `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
-	UpdateMask    *fieldmaskpb.FieldMask `protobuf:"bytes,2,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"`
+type OrderLine struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// line_id identifies the line across the events of its order.
+	LineId        string `protobuf:"bytes,1,opt,name=line_id,json=lineId,proto3" json:"line_id,omitempty"`
+	ProductId     string `protobuf:"bytes,2,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Quantity      int32  `protobuf:"varint,3,opt,name=quantity,proto3" json:"quantity,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateRequest) Reset() {
-	*x = UpdateRequest{}
-	mi := &file_inventory_proto_msgTypes[7]
+func (x *OrderLine) Reset() {
+	*x = OrderLine{}
+	mi := &file_inventory_proto_msgTypes[70]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateRequest) String() string {
+func (x *OrderLine) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateRequest) ProtoMessage() {}
+func (*OrderLine) ProtoMessage() {}
 
-func (x *UpdateRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_inventory_proto_msgTypes[7]
+func (x *OrderLine) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[70]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -460,47 +4824,56 @@ func (x *UpdateRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateRequest.ProtoReflect.Descriptor instead.
-func (*UpdateRequest) Descriptor() ([]byte, []int) {
-	return file_inventory_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use OrderLine.ProtoReflect.Descriptor instead.
+func (*OrderLine) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{70}
 }
 
-func (x *UpdateRequest) GetProduct() *Product {
+func (x *OrderLine) GetLineId() string {
 	if x != nil {
-		return x.Product
+		return x.LineId
 	}
-	return nil
+	return ""
 }
 
-func (x *UpdateRequest) GetUpdateMask() *fieldmaskpb.FieldMask {
+func (x *OrderLine) GetProductId() string {
 	if x != nil {
-		return x.UpdateMask
+		return x.ProductId
 	}
-	return nil
+	return ""
 }
 
-type UpdateResponse struct {
+func (x *OrderLine) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+type OrderPlaced struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Product       *Product               `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+	OrderId       string                 `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Lines         []*OrderLine           `protobuf:"bytes,2,rep,name=lines,proto3" json:"lines,omitempty"`
+	OccurredAt    *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateResponse) Reset() {
-	*x = UpdateResponse{}
-	mi := &file_inventory_proto_msgTypes[8]
+func (x *OrderPlaced) Reset() {
+	*x = OrderPlaced{}
+	mi := &file_inventory_proto_msgTypes[71]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateResponse) String() string {
+func (x *OrderPlaced) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateResponse) ProtoMessage() {}
+func (*OrderPlaced) ProtoMessage() {}
 
-func (x *UpdateResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_inventory_proto_msgTypes[8]
+func (x *OrderPlaced) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[71]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -511,40 +4884,58 @@ func (x *UpdateResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateResponse.ProtoReflect.Descriptor instead.
-func (*UpdateResponse) Descriptor() ([]byte, []int) {
-	return file_inventory_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use OrderPlaced.ProtoReflect.Descriptor instead.
+func (*OrderPlaced) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{71}
 }
 
-func (x *UpdateResponse) GetProduct() *Product {
+func (x *OrderPlaced) GetOrderId() string {
 	if x != nil {
-		return x.Product
+		return x.OrderId
+	}
+	return ""
+}
+
+func (x *OrderPlaced) GetLines() []*OrderLine {
+	if x != nil {
+		return x.Lines
 	}
 	return nil
 }
 
-type DeleteRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+func (x *OrderPlaced) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return nil
+}
+
+type OrderCancelled struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	OrderId string                 `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	// lines are the lines of the order; their stock is given back as it
+	// was taken, whatever their quantity here.
+	Lines         []*OrderLine           `protobuf:"bytes,2,rep,name=lines,proto3" json:"lines,omitempty"`
+	OccurredAt    *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteRequest) Reset() {
-	*x = DeleteRequest{}
-	mi := &file_inventory_proto_msgTypes[9]
+func (x *OrderCancelled) Reset() {
+	*x = OrderCancelled{}
+	mi := &file_inventory_proto_msgTypes[72]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteRequest) String() string {
+func (x *OrderCancelled) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteRequest) ProtoMessage() {}
+func (*OrderCancelled) ProtoMessage() {}
 
-func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_inventory_proto_msgTypes[9]
+func (x *OrderCancelled) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[72]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -555,40 +4946,56 @@ func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteRequest.ProtoReflect.Descriptor instead.
-func (*DeleteRequest) Descriptor() ([]byte, []int) {
-	return file_inventory_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use OrderCancelled.ProtoReflect.Descriptor instead.
+func (*OrderCancelled) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{72}
 }
 
-func (x *DeleteRequest) GetId() string {
+func (x *OrderCancelled) GetOrderId() string {
 	if x != nil {
-		return x.Id
+		return x.OrderId
 	}
 	return ""
 }
 
-type DeleteResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+func (x *OrderCancelled) GetLines() []*OrderLine {
+	if x != nil {
+		return x.Lines
+	}
+	return nil
+}
+
+func (x *OrderCancelled) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return nil
+}
+
+type CheckAvailabilityRequest_Item struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ProductId string                 `protobuf:"bytes,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	// quantity must be positive.
+	Quantity      int32 `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteResponse) Reset() {
-	*x = DeleteResponse{}
-	mi := &file_inventory_proto_msgTypes[10]
+func (x *CheckAvailabilityRequest_Item) Reset() {
+	*x = CheckAvailabilityRequest_Item{}
+	mi := &file_inventory_proto_msgTypes[76]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteResponse) String() string {
+func (x *CheckAvailabilityRequest_Item) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteResponse) ProtoMessage() {}
+func (*CheckAvailabilityRequest_Item) ProtoMessage() {}
 
-func (x *DeleteResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_inventory_proto_msgTypes[10]
+func (x *CheckAvailabilityRequest_Item) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_proto_msgTypes[76]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -599,51 +5006,147 @@ func (x *DeleteResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteResponse.ProtoReflect.Descriptor instead.
-func (*DeleteResponse) Descriptor() ([]byte, []int) {
-	return file_inventory_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use CheckAvailabilityRequest_Item.ProtoReflect.Descriptor instead.
+func (*CheckAvailabilityRequest_Item) Descriptor() ([]byte, []int) {
+	return file_inventory_proto_rawDescGZIP(), []int{51, 0}
 }
 
-func (x *DeleteResponse) GetSuccess() bool {
+func (x *CheckAvailabilityRequest_Item) GetProductId() string {
 	if x != nil {
-		return x.Success
+		return x.ProductId
 	}
-	return false
+	return ""
+}
+
+func (x *CheckAvailabilityRequest_Item) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
 }
 
 var File_inventory_proto protoreflect.FileDescriptor
 
 const file_inventory_proto_rawDesc = "" +
 	"\n" +
-	"\x0finventory.proto\x12\tinventory\x1a\x1fgoogle/protobuf/timestamp.proto\x1a google/protobuf/field_mask.proto\"\xa9\x02\n" +
+	"\x0finventory.proto\x12\tinventory\x1a\x1fgoogle/protobuf/timestamp.proto\x1a google/protobuf/field_mask.proto\x1a\x1egoogle/protobuf/duration.proto\"\xbc\a\n" +
 	"\aProduct\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
-	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x14\n" +
-	"\x05price\x18\x04 \x01(\x01R\x05price\x12\x1a\n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x18\n" +
+	"\x05price\x18\x04 \x01(\x01B\x02\x18\x01R\x05price\x12\x1a\n" +
 	"\bquantity\x18\x05 \x01(\x05R\bquantity\x12\x12\n" +
-	"\x04tags\x18\x06 \x03(\tR\x04tags\x12\x1c\n" +
-	"\tavailable\x18\a \x01(\bR\tavailable\x129\n" +
+	"\x04tags\x18\x06 \x03(\tR\x04tags\x12 \n" +
+	"\tavailable\x18\a \x01(\bB\x02\x18\x01R\tavailable\x129\n" +
+	"\n" +
+	"created_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x1f\n" +
+	"\vcategory_id\x18\n" +
+	" \x01(\tR\n" +
+	"categoryId\x12\x1d\n" +
+	"\n" +
+	"created_by\x18\v \x01(\tR\tcreatedBy\x12\x1d\n" +
+	"\n" +
+	"updated_by\x18\f \x01(\tR\tupdatedBy\x12\x10\n" +
+	"\x03sku\x18\r \x01(\tR\x03sku\x12\x18\n" +
+	"\abarcode\x18\x0e \x01(\tR\abarcode\x121\n" +
+	"\vprice_money\x18\x0f \x01(\v2\x10.inventory.MoneyR\n" +
+	"priceMoney\x121\n" +
+	"\x06status\x18\x10 \x01(\x0e2\x19.inventory.Product.StatusR\x06status\x12B\n" +
+	"\n" +
+	"attributes\x18\x11 \x03(\v2\".inventory.Product.AttributesEntryR\n" +
+	"attributes\x12<\n" +
+	"\ftranslations\x18\x12 \x03(\v2\x18.inventory.LocalizedTextR\ftranslations\x12.\n" +
+	"\bvariants\x18\x13 \x03(\v2\x12.inventory.VariantR\bvariants\x12#\n" +
+	"\rreorder_point\x18\x14 \x01(\x05R\freorderPoint\x12)\n" +
+	"\x10reorder_quantity\x18\x15 \x01(\x05R\x0freorderQuantity\x1a=\n" +
+	"\x0fAttributesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"W\n" +
+	"\x06Status\x12\x16\n" +
+	"\x12STATUS_UNSPECIFIED\x10\x00\x12\t\n" +
+	"\x05DRAFT\x10\x01\x12\n" +
+	"\n" +
+	"\x06ACTIVE\x10\x02\x12\f\n" +
+	"\bARCHIVED\x10\x03\x12\x10\n" +
+	"\fDISCONTINUED\x10\x04\"\xae\x03\n" +
+	"\aVariant\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x02 \x01(\tR\tproductId\x12\x10\n" +
+	"\x03sku\x18\x03 \x01(\tR\x03sku\x129\n" +
+	"\aoptions\x18\x04 \x03(\v2\x1f.inventory.Variant.OptionsEntryR\aoptions\x121\n" +
+	"\vprice_delta\x18\x05 \x01(\v2\x10.inventory.MoneyR\n" +
+	"priceDelta\x12\x1a\n" +
+	"\bquantity\x18\x06 \x01(\x05R\bquantity\x12&\n" +
+	"\x05price\x18\a \x01(\v2\x10.inventory.MoneyR\x05price\x129\n" +
 	"\n" +
 	"created_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
 	"\n" +
-	"updated_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"z\n" +
+	"updated_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x1a:\n" +
+	"\fOptionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"]\n" +
+	"\rLocalizedText\x12\x16\n" +
+	"\x06locale\x18\x01 \x01(\tR\x06locale\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\"X\n" +
+	"\x05Money\x12#\n" +
+	"\rcurrency_code\x18\x01 \x01(\tR\fcurrencyCode\x12\x14\n" +
+	"\x05units\x18\x02 \x01(\x03R\x05units\x12\x14\n" +
+	"\x05nanos\x18\x03 \x01(\x05R\x05nanos\"\xcc\x05\n" +
 	"\vListRequest\x12\x1b\n" +
-	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\x12\x1b\n" +
-	"\tprev_size\x18\x02 \x01(\x05R\bprevSize\x12\x16\n" +
+	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\x12\x16\n" +
 	"\x06filter\x18\x03 \x01(\tR\x06filter\x12\x19\n" +
-	"\border_by\x18\x04 \x01(\tR\aorderBy\"]\n" +
+	"\border_by\x18\x04 \x01(\tR\aorderBy\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x05 \x01(\tR\tpageToken\x12\x1b\n" +
+	"\tmin_price\x18\x06 \x01(\x01R\bminPrice\x12\x1b\n" +
+	"\tmax_price\x18\a \x01(\x01R\bmaxPrice\x12\x1d\n" +
+	"\n" +
+	"name_query\x18\b \x01(\tR\tnameQuery\x12\x12\n" +
+	"\x04tags\x18\t \x03(\tR\x04tags\x12!\n" +
+	"\tavailable\x18\n" +
+	" \x01(\bH\x00R\tavailable\x88\x01\x01\x12\x1f\n" +
+	"\vcategory_id\x18\v \x01(\tR\n" +
+	"categoryId\x127\n" +
+	"\tread_mask\x18\f \x01(\v2\x1a.google.protobuf.FieldMaskR\breadMask\x12\x10\n" +
+	"\x03sku\x18\r \x01(\tR\x03sku\x12\x18\n" +
+	"\abarcode\x18\x0e \x01(\tR\abarcode\x125\n" +
+	"\bstatuses\x18\x0f \x03(\x0e2\x19.inventory.Product.StatusR\bstatuses\x12F\n" +
+	"\n" +
+	"attributes\x18\x10 \x03(\v2&.inventory.ListRequest.AttributesEntryR\n" +
+	"attributes\x12\x16\n" +
+	"\x06locale\x18\x11 \x01(\tR\x06locale\x12'\n" +
+	"\x0fexpand_variants\x18\x12 \x01(\bR\x0eexpandVariants\x12\x1a\n" +
+	"\bcurrency\x18\x13 \x01(\tR\bcurrency\x1a=\n" +
+	"\x0fAttributesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\f\n" +
+	"\n" +
+	"_availableJ\x04\b\x02\x10\x03R\tprev_size\"\x85\x01\n" +
 	"\fListResponse\x12.\n" +
-	"\bproducts\x18\x01 \x03(\v2\x12.inventory.ProductR\bproducts\x12\x1d\n" +
+	"\bproducts\x18\x01 \x03(\v2\x12.inventory.ProductR\bproducts\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\x12\x1d\n" +
 	"\n" +
-	"total_size\x18\x03 \x01(\x05R\ttotalSize\"\x1c\n" +
+	"total_size\x18\x03 \x01(\x05R\ttotalSize\"\xb2\x01\n" +
 	"\n" +
 	"GetRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\";\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x127\n" +
+	"\tread_mask\x18\x02 \x01(\v2\x1a.google.protobuf.FieldMaskR\breadMask\x12\x16\n" +
+	"\x06locale\x18\x03 \x01(\tR\x06locale\x12'\n" +
+	"\x0fexpand_variants\x18\x04 \x01(\bR\x0eexpandVariants\x12\x1a\n" +
+	"\bcurrency\x18\x05 \x01(\tR\bcurrency\"#\n" +
+	"\x0fGetBySkuRequest\x12\x10\n" +
+	"\x03sku\x18\x01 \x01(\tR\x03sku\"/\n" +
+	"\x13GetByBarcodeRequest\x12\x18\n" +
+	"\abarcode\x18\x01 \x01(\tR\abarcode\";\n" +
 	"\vGetResponse\x12,\n" +
-	"\aproduct\x18\x01 \x01(\v2\x12.inventory.ProductR\aproduct\"=\n" +
+	"\aproduct\x18\x01 \x01(\v2\x12.inventory.ProductR\aproduct\"f\n" +
 	"\rCreateRequest\x12,\n" +
-	"\aproduct\x18\x01 \x01(\v2\x12.inventory.ProductR\aproduct\">\n" +
+	"\aproduct\x18\x01 \x01(\v2\x12.inventory.ProductR\aproduct\x12'\n" +
+	"\x0fidempotency_key\x18\x02 \x01(\tR\x0eidempotencyKey\">\n" +
 	"\x0eCreateResponse\x12,\n" +
 	"\aproduct\x18\x01 \x01(\v2\x12.inventory.ProductR\aproduct\"z\n" +
 	"\rUpdateRequest\x12,\n" +
@@ -655,14 +5158,330 @@ const file_inventory_proto_rawDesc = "" +
 	"\rDeleteRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\"*\n" +
 	"\x0eDeleteResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess2\xe2\x02\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"\xd3\x01\n" +
+	"\x12BatchDeleteRequest\x12\x1b\n" +
+	"\tmin_price\x18\x01 \x01(\x01R\bminPrice\x12\x1b\n" +
+	"\tmax_price\x18\x02 \x01(\x01R\bmaxPrice\x12\x1d\n" +
+	"\n" +
+	"name_query\x18\x03 \x01(\tR\tnameQuery\x12\x12\n" +
+	"\x04tags\x18\x04 \x03(\tR\x04tags\x12!\n" +
+	"\tavailable\x18\x05 \x01(\bH\x00R\tavailable\x88\x01\x01\x12\x1f\n" +
+	"\vcategory_id\x18\x06 \x01(\tR\n" +
+	"categoryIdB\f\n" +
+	"\n" +
+	"_available\":\n" +
+	"\x13BatchDeleteResponse\x12#\n" +
+	"\rdeleted_count\x18\x01 \x01(\x03R\fdeletedCount\"R\n" +
+	"\x1aBatchUpdateProductsRequest\x124\n" +
+	"\brequests\x18\x01 \x03(\v2\x18.inventory.UpdateRequestR\brequests\"o\n" +
+	"\x11BatchUpdateResult\x12,\n" +
+	"\aproduct\x18\x01 \x01(\v2\x12.inventory.ProductR\aproduct\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\"o\n" +
+	"\x1bBatchUpdateProductsResponse\x12\x18\n" +
+	"\aapplied\x18\x01 \x01(\bR\aapplied\x126\n" +
+	"\aresults\x18\x02 \x03(\v2\x1c.inventory.BatchUpdateResultR\aresults\"E\n" +
+	"\x18ImportProductsCsvRequest\x12\x10\n" +
+	"\x03csv\x18\x01 \x01(\fR\x03csv\x12\x17\n" +
+	"\adry_run\x18\x02 \x01(\bR\x06dryRun\"d\n" +
+	"\x0eImportRowError\x12\x12\n" +
+	"\x04line\x18\x01 \x01(\x05R\x04line\x12\x10\n" +
+	"\x03sku\x18\x02 \x01(\tR\x03sku\x12\x12\n" +
+	"\x04code\x18\x03 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x04 \x01(\tR\amessage\"~\n" +
+	"\x19ImportProductsCsvResponse\x12\x12\n" +
+	"\x04rows\x18\x01 \x01(\x05R\x04rows\x12\x1a\n" +
+	"\bimported\x18\x02 \x01(\x05R\bimported\x121\n" +
+	"\x06errors\x18\x03 \x03(\v2\x19.inventory.ImportRowErrorR\x06errors\"\xbf\x01\n" +
+	"\x15ExportProductsRequest\x12?\n" +
+	"\x06format\x18\x01 \x01(\x0e2'.inventory.ExportProductsRequest.FormatR\x06format\x12.\n" +
+	"\x06filter\x18\x02 \x01(\v2\x16.inventory.ListRequestR\x06filter\"5\n" +
+	"\x06Format\x12\x16\n" +
+	"\x12FORMAT_UNSPECIFIED\x10\x00\x12\a\n" +
+	"\x03CSV\x10\x01\x12\n" +
+	"\n" +
+	"\x06NDJSON\x10\x02\")\n" +
+	"\x13ExportProductsChunk\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\"x\n" +
+	"\x03Tag\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1e\n" +
+	"\n" +
+	"registered\x18\x02 \x01(\bR\n" +
+	"registered\x12\x18\n" +
+	"\aaliases\x18\x03 \x03(\tR\aaliases\x12#\n" +
+	"\rproduct_count\x18\x04 \x01(\x03R\fproductCount\"\x11\n" +
+	"\x0fListTagsRequest\"6\n" +
+	"\x10ListTagsResponse\x12\"\n" +
+	"\x04tags\x18\x01 \x03(\v2\x0e.inventory.TagR\x04tags\":\n" +
+	"\x10MergeTagsRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04from\x18\x02 \x03(\tR\x04from\"b\n" +
+	"\x11MergeTagsResponse\x12 \n" +
+	"\x03tag\x18\x01 \x01(\v2\x0e.inventory.TagR\x03tag\x12+\n" +
+	"\x11retagged_products\x18\x02 \x01(\x03R\x10retaggedProducts\"Q\n" +
+	"\vFieldChange\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12\x16\n" +
+	"\x06before\x18\x02 \x01(\tR\x06before\x12\x14\n" +
+	"\x05after\x18\x03 \x01(\tR\x05after\"\xdb\x02\n" +
+	"\n" +
+	"AuditEntry\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x02 \x01(\tR\tproductId\x12=\n" +
+	"\toperation\x18\x03 \x01(\x0e2\x1f.inventory.AuditEntry.OperationR\toperation\x12\x14\n" +
+	"\x05actor\x18\x04 \x01(\tR\x05actor\x12\x10\n" +
+	"\x03rpc\x18\x05 \x01(\tR\x03rpc\x120\n" +
+	"\achanges\x18\x06 \x03(\v2\x16.inventory.FieldChangeR\achanges\x129\n" +
+	"\n" +
+	"created_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"J\n" +
+	"\tOperation\x12\x19\n" +
+	"\x15OPERATION_UNSPECIFIED\x10\x00\x12\n" +
+	"\n" +
+	"\x06INSERT\x10\x01\x12\n" +
+	"\n" +
+	"\x06UPDATE\x10\x02\x12\n" +
+	"\n" +
+	"\x06DELETE\x10\x03\"\xfc\x01\n" +
+	"\x17ListAuditEntriesRequest\x12\x1b\n" +
+	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x02 \x01(\tR\tpageToken\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x03 \x01(\tR\tproductId\x12\x14\n" +
+	"\x05actor\x18\x04 \x01(\tR\x05actor\x129\n" +
+	"\n" +
+	"start_time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\"s\n" +
+	"\x18ListAuditEntriesResponse\x12/\n" +
+	"\aentries\x18\x01 \x03(\v2\x15.inventory.AuditEntryR\aentries\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"L\n" +
+	"\x0fPurchaseRequest\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x1a\n" +
+	"\bquantity\x18\x02 \x01(\x05R\bquantity\"A\n" +
+	"\x10PurchaseResponse\x12-\n" +
+	"\x12remaining_quantity\x18\x01 \x01(\x05R\x11remainingQuantity\"\x94\x02\n" +
+	"\x12AdjustStockRequest\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x14\n" +
+	"\x05delta\x18\x02 \x01(\x05R\x05delta\x12<\n" +
+	"\x06reason\x18\x03 \x01(\x0e2$.inventory.AdjustStockRequest.ReasonR\x06reason\x12\x1c\n" +
+	"\treference\x18\x04 \x01(\tR\treference\"m\n" +
+	"\x06Reason\x12\x16\n" +
+	"\x12REASON_UNSPECIFIED\x10\x00\x12\f\n" +
+	"\bRECEIVED\x10\x01\x12\v\n" +
+	"\aDAMAGED\x10\x02\x12\x0e\n" +
+	"\n" +
+	"CORRECTION\x10\x03\x12\b\n" +
+	"\x04SOLD\x10\x04\x12\f\n" +
+	"\bRETURNED\x10\x05\x12\b\n" +
+	"\x04LOST\x10\x06\"1\n" +
+	"\x13AdjustStockResponse\x12\x1a\n" +
+	"\bquantity\x18\x01 \x01(\x05R\bquantity\"\xd3\x02\n" +
+	"\x11ReorderSuggestion\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x1a\n" +
+	"\bquantity\x18\x02 \x01(\x05R\bquantity\x12#\n" +
+	"\rreorder_point\x18\x03 \x01(\x05R\freorderPoint\x12)\n" +
+	"\x10reorder_quantity\x18\x04 \x01(\x05R\x0freorderQuantity\x12#\n" +
+	"\rdaily_outflow\x18\x05 \x01(\x01R\fdailyOutflow\x12\"\n" +
+	"\rdays_of_cover\x18\x06 \x01(\x01R\vdaysOfCover\x12-\n" +
+	"\x12suggested_quantity\x18\a \x01(\x05R\x11suggestedQuantity\x12;\n" +
+	"\vcomputed_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"computedAt\"[\n" +
+	"\x1dListReorderSuggestionsRequest\x12\x1b\n" +
+	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x02 \x01(\tR\tpageToken\"\x88\x01\n" +
+	"\x1eListReorderSuggestionsResponse\x12>\n" +
+	"\vsuggestions\x18\x01 \x03(\v2\x1c.inventory.ReorderSuggestionR\vsuggestions\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"\xcb\x01\n" +
+	"\x03Lot\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x02 \x01(\tR\tproductId\x12\x1d\n" +
+	"\n" +
+	"lot_number\x18\x03 \x01(\tR\tlotNumber\x12\x1f\n" +
+	"\vexpiry_date\x18\x04 \x01(\tR\n" +
+	"expiryDate\x12\x1a\n" +
+	"\bquantity\x18\x05 \x01(\x05R\bquantity\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xac\x01\n" +
+	"\x11ReceiveLotRequest\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x1d\n" +
+	"\n" +
+	"lot_number\x18\x02 \x01(\tR\tlotNumber\x12\x1f\n" +
+	"\vexpiry_date\x18\x03 \x01(\tR\n" +
+	"expiryDate\x12\x1a\n" +
+	"\bquantity\x18\x04 \x01(\x05R\bquantity\x12\x1c\n" +
+	"\treference\x18\x05 \x01(\tR\treference\"R\n" +
+	"\x12ReceiveLotResponse\x12 \n" +
+	"\x03lot\x18\x01 \x01(\v2\x0e.inventory.LotR\x03lot\x12\x1a\n" +
+	"\bquantity\x18\x02 \x01(\x05R\bquantity\"\x88\x01\n" +
+	"\x17ListExpiringLotsRequest\x12\x12\n" +
+	"\x04days\x18\x01 \x01(\x05R\x04days\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x02 \x01(\tR\tproductId\x12\x1b\n" +
+	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x04 \x01(\tR\tpageToken\"f\n" +
+	"\x18ListExpiringLotsResponse\x12\"\n" +
+	"\x04lots\x18\x01 \x03(\v2\x0e.inventory.LotR\x04lots\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"\xf8\x02\n" +
+	"\vReservation\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x02 \x01(\tR\tproductId\x12\x19\n" +
+	"\border_id\x18\x03 \x01(\tR\aorderId\x12\x1a\n" +
+	"\bquantity\x18\x04 \x01(\x05R\bquantity\x125\n" +
+	"\x06status\x18\x05 \x01(\x0e2\x1d.inventory.Reservation.StatusR\x06status\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"expires_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"V\n" +
+	"\x06Status\x12\x16\n" +
+	"\x12STATUS_UNSPECIFIED\x10\x00\x12\n" +
+	"\n" +
+	"\x06ACTIVE\x10\x01\x12\r\n" +
+	"\tCONFIRMED\x10\x02\x12\f\n" +
+	"\bRELEASED\x10\x03\x12\v\n" +
+	"\aEXPIRED\x10\x04\"\x98\x01\n" +
+	"\x13ReserveStockRequest\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x1a\n" +
+	"\bquantity\x18\x02 \x01(\x05R\bquantity\x12\x19\n" +
+	"\border_id\x18\x03 \x01(\tR\aorderId\x12+\n" +
+	"\x03ttl\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\x03ttl\"$\n" +
+	"\x12ReservationRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"O\n" +
+	"\x13ReservationResponse\x128\n" +
+	"\vreservation\x18\x01 \x01(\v2\x16.inventory.ReservationR\vreservation\"\x9d\x01\n" +
+	"\x18CheckAvailabilityRequest\x12>\n" +
+	"\x05items\x18\x01 \x03(\v2(.inventory.CheckAvailabilityRequest.ItemR\x05items\x1aA\n" +
+	"\x04Item\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x1a\n" +
+	"\bquantity\x18\x02 \x01(\x05R\bquantity\"\xc3\x01\n" +
+	"\x10ItemAvailability\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12-\n" +
+	"\x12requested_quantity\x18\x02 \x01(\x05R\x11requestedQuantity\x12\x14\n" +
+	"\x05found\x18\x03 \x01(\bR\x05found\x12-\n" +
+	"\x12available_quantity\x18\x04 \x01(\x05R\x11availableQuantity\x12\x1c\n" +
+	"\tavailable\x18\x05 \x01(\bR\tavailable\"N\n" +
+	"\x19CheckAvailabilityResponse\x121\n" +
+	"\x05items\x18\x01 \x03(\v2\x1b.inventory.ItemAvailabilityR\x05items\"\x16\n" +
+	"\x14WatchProductsRequest\"\xc0\x01\n" +
+	"\rProductChange\x121\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x1d.inventory.ProductChange.TypeR\x04type\x12,\n" +
+	"\aproduct\x18\x02 \x01(\v2\x12.inventory.ProductR\aproduct\"N\n" +
+	"\x04Type\x12\x14\n" +
+	"\x10TYPE_UNSPECIFIED\x10\x00\x12\v\n" +
+	"\aCREATED\x10\x01\x12\v\n" +
+	"\aUPDATED\x10\x02\x12\v\n" +
+	"\aDELETED\x10\x03\x12\t\n" +
+	"\x05RESET\x10\x04\"D\n" +
+	"\x14CreateVariantRequest\x12,\n" +
+	"\avariant\x18\x01 \x01(\v2\x12.inventory.VariantR\avariant\"#\n" +
+	"\x11GetVariantRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x81\x01\n" +
+	"\x14UpdateVariantRequest\x12,\n" +
+	"\avariant\x18\x01 \x01(\v2\x12.inventory.VariantR\avariant\x12;\n" +
+	"\vupdate_mask\x18\x02 \x01(\v2\x1a.google.protobuf.FieldMaskR\n" +
+	"updateMask\"&\n" +
+	"\x14DeleteVariantRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"1\n" +
+	"\x15DeleteVariantResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"4\n" +
+	"\x13ListVariantsRequest\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\"F\n" +
+	"\x14ListVariantsResponse\x12.\n" +
+	"\bvariants\x18\x01 \x03(\v2\x12.inventory.VariantR\bvariants\"?\n" +
+	"\x0fVariantResponse\x12,\n" +
+	"\avariant\x18\x01 \x01(\v2\x12.inventory.VariantR\avariant\"\x16\n" +
+	"\x14GetServerInfoRequest\"\xa3\x01\n" +
+	"\n" +
+	"ServerInfo\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12\x16\n" +
+	"\x06commit\x18\x02 \x01(\tR\x06commit\x12\x1d\n" +
+	"\n" +
+	"build_date\x18\x03 \x01(\tR\tbuildDate\x12%\n" +
+	"\x0eschema_version\x18\x04 \x01(\tR\rschemaVersion\x12\x1d\n" +
+	"\n" +
+	"go_version\x18\x05 \x01(\tR\tgoVersion\"{\n" +
+	"\x0eProductCreated\x12,\n" +
+	"\aproduct\x18\x01 \x01(\v2\x12.inventory.ProductR\aproduct\x12;\n" +
+	"\voccurred_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"occurredAt\"\xa2\x01\n" +
+	"\x0eProductUpdated\x12,\n" +
+	"\aproduct\x18\x01 \x01(\v2\x12.inventory.ProductR\aproduct\x12%\n" +
+	"\x0echanged_fields\x18\x02 \x03(\tR\rchangedFields\x12;\n" +
+	"\voccurred_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"occurredAt\"]\n" +
+	"\x0eProductDeleted\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12;\n" +
+	"\voccurred_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"occurredAt\"\xda\x01\n" +
+	"\fStockChanged\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\tR\tproductId\x12\x14\n" +
+	"\x05delta\x18\x02 \x01(\x05R\x05delta\x12\x1a\n" +
+	"\bquantity\x18\x03 \x01(\x05R\bquantity\x12<\n" +
+	"\x06reason\x18\x04 \x01(\x0e2$.inventory.AdjustStockRequest.ReasonR\x06reason\x12;\n" +
+	"\voccurred_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"occurredAt\"_\n" +
+	"\tOrderLine\x12\x17\n" +
+	"\aline_id\x18\x01 \x01(\tR\x06lineId\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x02 \x01(\tR\tproductId\x12\x1a\n" +
+	"\bquantity\x18\x03 \x01(\x05R\bquantity\"\x91\x01\n" +
+	"\vOrderPlaced\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\tR\aorderId\x12*\n" +
+	"\x05lines\x18\x02 \x03(\v2\x14.inventory.OrderLineR\x05lines\x12;\n" +
+	"\voccurred_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"occurredAt\"\x94\x01\n" +
+	"\x0eOrderCancelled\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\tR\aorderId\x12*\n" +
+	"\x05lines\x18\x02 \x03(\v2\x14.inventory.OrderLineR\x05lines\x12;\n" +
+	"\voccurred_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"occurredAt2\xd0\x13\n" +
 	"\x10InventoryService\x12?\n" +
 	"\fListProducts\x12\x16.inventory.ListRequest\x1a\x17.inventory.ListResponse\x12;\n" +
 	"\n" +
-	"GetProduct\x12\x15.inventory.GetRequest\x1a\x16.inventory.GetResponse\x12D\n" +
+	"GetProduct\x12\x15.inventory.GetRequest\x1a\x16.inventory.GetResponse\x12E\n" +
+	"\x0fGetProductBySku\x12\x1a.inventory.GetBySkuRequest\x1a\x16.inventory.GetResponse\x12M\n" +
+	"\x13GetProductByBarcode\x12\x1e.inventory.GetByBarcodeRequest\x1a\x16.inventory.GetResponse\x12D\n" +
 	"\rCreateProduct\x12\x18.inventory.CreateRequest\x1a\x19.inventory.CreateResponse\x12D\n" +
 	"\rUpdateProduct\x12\x18.inventory.UpdateRequest\x1a\x19.inventory.UpdateResponse\x12D\n" +
-	"\rDeleteProduct\x12\x18.inventory.DeleteRequest\x1a\x19.inventory.DeleteResponseB\x0fZ\r./proto;protob\x06proto3"
+	"\rDeleteProduct\x12\x18.inventory.DeleteRequest\x1a\x19.inventory.DeleteResponse\x12T\n" +
+	"\x13BatchDeleteProducts\x12\x1d.inventory.BatchDeleteRequest\x1a\x1e.inventory.BatchDeleteResponse\x12d\n" +
+	"\x13BatchUpdateProducts\x12%.inventory.BatchUpdateProductsRequest\x1a&.inventory.BatchUpdateProductsResponse\x12^\n" +
+	"\x11ImportProductsCsv\x12#.inventory.ImportProductsCsvRequest\x1a$.inventory.ImportProductsCsvResponse\x12T\n" +
+	"\x0eExportProducts\x12 .inventory.ExportProductsRequest\x1a\x1e.inventory.ExportProductsChunk0\x01\x12C\n" +
+	"\bListTags\x12\x1a.inventory.ListTagsRequest\x1a\x1b.inventory.ListTagsResponse\x12F\n" +
+	"\tMergeTags\x12\x1b.inventory.MergeTagsRequest\x1a\x1c.inventory.MergeTagsResponse\x12[\n" +
+	"\x10ListAuditEntries\x12\".inventory.ListAuditEntriesRequest\x1a#.inventory.ListAuditEntriesResponse\x12J\n" +
+	"\x0fPurchaseProduct\x12\x1a.inventory.PurchaseRequest\x1a\x1b.inventory.PurchaseResponse\x12L\n" +
+	"\vAdjustStock\x12\x1d.inventory.AdjustStockRequest\x1a\x1e.inventory.AdjustStockResponse\x12m\n" +
+	"\x16ListReorderSuggestions\x12(.inventory.ListReorderSuggestionsRequest\x1a).inventory.ListReorderSuggestionsResponse\x12I\n" +
+	"\n" +
+	"ReceiveLot\x12\x1c.inventory.ReceiveLotRequest\x1a\x1d.inventory.ReceiveLotResponse\x12[\n" +
+	"\x10ListExpiringLots\x12\".inventory.ListExpiringLotsRequest\x1a#.inventory.ListExpiringLotsResponse\x12N\n" +
+	"\fReserveStock\x12\x1e.inventory.ReserveStockRequest\x1a\x1e.inventory.ReservationResponse\x12S\n" +
+	"\x12ConfirmReservation\x12\x1d.inventory.ReservationRequest\x1a\x1e.inventory.ReservationResponse\x12S\n" +
+	"\x12ReleaseReservation\x12\x1d.inventory.ReservationRequest\x1a\x1e.inventory.ReservationResponse\x12O\n" +
+	"\x0eGetReservation\x12\x1d.inventory.ReservationRequest\x1a\x1e.inventory.ReservationResponse\x12^\n" +
+	"\x11CheckAvailability\x12#.inventory.CheckAvailabilityRequest\x1a$.inventory.CheckAvailabilityResponse\x12L\n" +
+	"\rWatchProducts\x12\x1f.inventory.WatchProductsRequest\x1a\x18.inventory.ProductChange0\x01\x12L\n" +
+	"\rCreateVariant\x12\x1f.inventory.CreateVariantRequest\x1a\x1a.inventory.VariantResponse\x12F\n" +
+	"\n" +
+	"GetVariant\x12\x1c.inventory.GetVariantRequest\x1a\x1a.inventory.VariantResponse\x12L\n" +
+	"\rUpdateVariant\x12\x1f.inventory.UpdateVariantRequest\x1a\x1a.inventory.VariantResponse\x12R\n" +
+	"\rDeleteVariant\x12\x1f.inventory.DeleteVariantRequest\x1a .inventory.DeleteVariantResponse\x12O\n" +
+	"\fListVariants\x12\x1e.inventory.ListVariantsRequest\x1a\x1f.inventory.ListVariantsResponse\x12G\n" +
+	"\rGetServerInfo\x12\x1f.inventory.GetServerInfoRequest\x1a\x15.inventory.ServerInfoB\x0fZ\r./proto;protob\x06proto3"
 
 var (
 	file_inventory_proto_rawDescOnce sync.Once
@@ -676,47 +5495,232 @@ func file_inventory_proto_rawDescGZIP() []byte {
 	return file_inventory_proto_rawDescData
 }
 
-var file_inventory_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_inventory_proto_enumTypes = make([]protoimpl.EnumInfo, 6)
+var file_inventory_proto_msgTypes = make([]protoimpl.MessageInfo, 77)
 var file_inventory_proto_goTypes = []any{
-	(*Product)(nil),               // 0: inventory.Product
-	(*ListRequest)(nil),           // 1: inventory.ListRequest
-	(*ListResponse)(nil),          // 2: inventory.ListResponse
-	(*GetRequest)(nil),            // 3: inventory.GetRequest
-	(*GetResponse)(nil),           // 4: inventory.GetResponse
-	(*CreateRequest)(nil),         // 5: inventory.CreateRequest
-	(*CreateResponse)(nil),        // 6: inventory.CreateResponse
-	(*UpdateRequest)(nil),         // 7: inventory.UpdateRequest
-	(*UpdateResponse)(nil),        // 8: inventory.UpdateResponse
-	(*DeleteRequest)(nil),         // 9: inventory.DeleteRequest
-	(*DeleteResponse)(nil),        // 10: inventory.DeleteResponse
-	(*timestamppb.Timestamp)(nil), // 11: google.protobuf.Timestamp
-	(*fieldmaskpb.FieldMask)(nil), // 12: google.protobuf.FieldMask
+	(Product_Status)(0),                    // 0: inventory.Product.Status
+	(ExportProductsRequest_Format)(0),      // 1: inventory.ExportProductsRequest.Format
+	(AuditEntry_Operation)(0),              // 2: inventory.AuditEntry.Operation
+	(AdjustStockRequest_Reason)(0),         // 3: inventory.AdjustStockRequest.Reason
+	(Reservation_Status)(0),                // 4: inventory.Reservation.Status
+	(ProductChange_Type)(0),                // 5: inventory.ProductChange.Type
+	(*Product)(nil),                        // 6: inventory.Product
+	(*Variant)(nil),                        // 7: inventory.Variant
+	(*LocalizedText)(nil),                  // 8: inventory.LocalizedText
+	(*Money)(nil),                          // 9: inventory.Money
+	(*ListRequest)(nil),                    // 10: inventory.ListRequest
+	(*ListResponse)(nil),                   // 11: inventory.ListResponse
+	(*GetRequest)(nil),                     // 12: inventory.GetRequest
+	(*GetBySkuRequest)(nil),                // 13: inventory.GetBySkuRequest
+	(*GetByBarcodeRequest)(nil),            // 14: inventory.GetByBarcodeRequest
+	(*GetResponse)(nil),                    // 15: inventory.GetResponse
+	(*CreateRequest)(nil),                  // 16: inventory.CreateRequest
+	(*CreateResponse)(nil),                 // 17: inventory.CreateResponse
+	(*UpdateRequest)(nil),                  // 18: inventory.UpdateRequest
+	(*UpdateResponse)(nil),                 // 19: inventory.UpdateResponse
+	(*DeleteRequest)(nil),                  // 20: inventory.DeleteRequest
+	(*DeleteResponse)(nil),                 // 21: inventory.DeleteResponse
+	(*BatchDeleteRequest)(nil),             // 22: inventory.BatchDeleteRequest
+	(*BatchDeleteResponse)(nil),            // 23: inventory.BatchDeleteResponse
+	(*BatchUpdateProductsRequest)(nil),     // 24: inventory.BatchUpdateProductsRequest
+	(*BatchUpdateResult)(nil),              // 25: inventory.BatchUpdateResult
+	(*BatchUpdateProductsResponse)(nil),    // 26: inventory.BatchUpdateProductsResponse
+	(*ImportProductsCsvRequest)(nil),       // 27: inventory.ImportProductsCsvRequest
+	(*ImportRowError)(nil),                 // 28: inventory.ImportRowError
+	(*ImportProductsCsvResponse)(nil),      // 29: inventory.ImportProductsCsvResponse
+	(*ExportProductsRequest)(nil),          // 30: inventory.ExportProductsRequest
+	(*ExportProductsChunk)(nil),            // 31: inventory.ExportProductsChunk
+	(*Tag)(nil),                            // 32: inventory.Tag
+	(*ListTagsRequest)(nil),                // 33: inventory.ListTagsRequest
+	(*ListTagsResponse)(nil),               // 34: inventory.ListTagsResponse
+	(*MergeTagsRequest)(nil),               // 35: inventory.MergeTagsRequest
+	(*MergeTagsResponse)(nil),              // 36: inventory.MergeTagsResponse
+	(*FieldChange)(nil),                    // 37: inventory.FieldChange
+	(*AuditEntry)(nil),                     // 38: inventory.AuditEntry
+	(*ListAuditEntriesRequest)(nil),        // 39: inventory.ListAuditEntriesRequest
+	(*ListAuditEntriesResponse)(nil),       // 40: inventory.ListAuditEntriesResponse
+	(*PurchaseRequest)(nil),                // 41: inventory.PurchaseRequest
+	(*PurchaseResponse)(nil),               // 42: inventory.PurchaseResponse
+	(*AdjustStockRequest)(nil),             // 43: inventory.AdjustStockRequest
+	(*AdjustStockResponse)(nil),            // 44: inventory.AdjustStockResponse
+	(*ReorderSuggestion)(nil),              // 45: inventory.ReorderSuggestion
+	(*ListReorderSuggestionsRequest)(nil),  // 46: inventory.ListReorderSuggestionsRequest
+	(*ListReorderSuggestionsResponse)(nil), // 47: inventory.ListReorderSuggestionsResponse
+	(*Lot)(nil),                            // 48: inventory.Lot
+	(*ReceiveLotRequest)(nil),              // 49: inventory.ReceiveLotRequest
+	(*ReceiveLotResponse)(nil),             // 50: inventory.ReceiveLotResponse
+	(*ListExpiringLotsRequest)(nil),        // 51: inventory.ListExpiringLotsRequest
+	(*ListExpiringLotsResponse)(nil),       // 52: inventory.ListExpiringLotsResponse
+	(*Reservation)(nil),                    // 53: inventory.Reservation
+	(*ReserveStockRequest)(nil),            // 54: inventory.ReserveStockRequest
+	(*ReservationRequest)(nil),             // 55: inventory.ReservationRequest
+	(*ReservationResponse)(nil),            // 56: inventory.ReservationResponse
+	(*CheckAvailabilityRequest)(nil),       // 57: inventory.CheckAvailabilityRequest
+	(*ItemAvailability)(nil),               // 58: inventory.ItemAvailability
+	(*CheckAvailabilityResponse)(nil),      // 59: inventory.CheckAvailabilityResponse
+	(*WatchProductsRequest)(nil),           // 60: inventory.WatchProductsRequest
+	(*ProductChange)(nil),                  // 61: inventory.ProductChange
+	(*CreateVariantRequest)(nil),           // 62: inventory.CreateVariantRequest
+	(*GetVariantRequest)(nil),              // 63: inventory.GetVariantRequest
+	(*UpdateVariantRequest)(nil),           // 64: inventory.UpdateVariantRequest
+	(*DeleteVariantRequest)(nil),           // 65: inventory.DeleteVariantRequest
+	(*DeleteVariantResponse)(nil),          // 66: inventory.DeleteVariantResponse
+	(*ListVariantsRequest)(nil),            // 67: inventory.ListVariantsRequest
+	(*ListVariantsResponse)(nil),           // 68: inventory.ListVariantsResponse
+	(*VariantResponse)(nil),                // 69: inventory.VariantResponse
+	(*GetServerInfoRequest)(nil),           // 70: inventory.GetServerInfoRequest
+	(*ServerInfo)(nil),                     // 71: inventory.ServerInfo
+	(*ProductCreated)(nil),                 // 72: inventory.ProductCreated
+	(*ProductUpdated)(nil),                 // 73: inventory.ProductUpdated
+	(*ProductDeleted)(nil),                 // 74: inventory.ProductDeleted
+	(*StockChanged)(nil),                   // 75: inventory.StockChanged
+	(*OrderLine)(nil),                      // 76: inventory.OrderLine
+	(*OrderPlaced)(nil),                    // 77: inventory.OrderPlaced
+	(*OrderCancelled)(nil),                 // 78: inventory.OrderCancelled
+	nil,                                    // 79: inventory.Product.AttributesEntry
+	nil,                                    // 80: inventory.Variant.OptionsEntry
+	nil,                                    // 81: inventory.ListRequest.AttributesEntry
+	(*CheckAvailabilityRequest_Item)(nil),  // 82: inventory.CheckAvailabilityRequest.Item
+	(*timestamppb.Timestamp)(nil),          // 83: google.protobuf.Timestamp
+	(*fieldmaskpb.FieldMask)(nil),          // 84: google.protobuf.FieldMask
+	(*durationpb.Duration)(nil),            // 85: google.protobuf.Duration
 }
 var file_inventory_proto_depIdxs = []int32{
-	11, // 0: inventory.Product.created_at:type_name -> google.protobuf.Timestamp
-	11, // 1: inventory.Product.updated_at:type_name -> google.protobuf.Timestamp
-	0,  // 2: inventory.ListResponse.products:type_name -> inventory.Product
-	0,  // 3: inventory.GetResponse.product:type_name -> inventory.Product
-	0,  // 4: inventory.CreateRequest.product:type_name -> inventory.Product
-	0,  // 5: inventory.CreateResponse.product:type_name -> inventory.Product
-	0,  // 6: inventory.UpdateRequest.product:type_name -> inventory.Product
-	12, // 7: inventory.UpdateRequest.update_mask:type_name -> google.protobuf.FieldMask
-	0,  // 8: inventory.UpdateResponse.product:type_name -> inventory.Product
-	1,  // 9: inventory.InventoryService.ListProducts:input_type -> inventory.ListRequest
-	3,  // 10: inventory.InventoryService.GetProduct:input_type -> inventory.GetRequest
-	5,  // 11: inventory.InventoryService.CreateProduct:input_type -> inventory.CreateRequest
-	7,  // 12: inventory.InventoryService.UpdateProduct:input_type -> inventory.UpdateRequest
-	9,  // 13: inventory.InventoryService.DeleteProduct:input_type -> inventory.DeleteRequest
-	2,  // 14: inventory.InventoryService.ListProducts:output_type -> inventory.ListResponse
-	4,  // 15: inventory.InventoryService.GetProduct:output_type -> inventory.GetResponse
-	6,  // 16: inventory.InventoryService.CreateProduct:output_type -> inventory.CreateResponse
-	8,  // 17: inventory.InventoryService.UpdateProduct:output_type -> inventory.UpdateResponse
-	10, // 18: inventory.InventoryService.DeleteProduct:output_type -> inventory.DeleteResponse
-	14, // [14:19] is the sub-list for method output_type
-	9,  // [9:14] is the sub-list for method input_type
-	9,  // [9:9] is the sub-list for extension type_name
-	9,  // [9:9] is the sub-list for extension extendee
-	0,  // [0:9] is the sub-list for field type_name
+	83, // 0: inventory.Product.created_at:type_name -> google.protobuf.Timestamp
+	83, // 1: inventory.Product.updated_at:type_name -> google.protobuf.Timestamp
+	9,  // 2: inventory.Product.price_money:type_name -> inventory.Money
+	0,  // 3: inventory.Product.status:type_name -> inventory.Product.Status
+	79, // 4: inventory.Product.attributes:type_name -> inventory.Product.AttributesEntry
+	8,  // 5: inventory.Product.translations:type_name -> inventory.LocalizedText
+	7,  // 6: inventory.Product.variants:type_name -> inventory.Variant
+	80, // 7: inventory.Variant.options:type_name -> inventory.Variant.OptionsEntry
+	9,  // 8: inventory.Variant.price_delta:type_name -> inventory.Money
+	9,  // 9: inventory.Variant.price:type_name -> inventory.Money
+	83, // 10: inventory.Variant.created_at:type_name -> google.protobuf.Timestamp
+	83, // 11: inventory.Variant.updated_at:type_name -> google.protobuf.Timestamp
+	84, // 12: inventory.ListRequest.read_mask:type_name -> google.protobuf.FieldMask
+	0,  // 13: inventory.ListRequest.statuses:type_name -> inventory.Product.Status
+	81, // 14: inventory.ListRequest.attributes:type_name -> inventory.ListRequest.AttributesEntry
+	6,  // 15: inventory.ListResponse.products:type_name -> inventory.Product
+	84, // 16: inventory.GetRequest.read_mask:type_name -> google.protobuf.FieldMask
+	6,  // 17: inventory.GetResponse.product:type_name -> inventory.Product
+	6,  // 18: inventory.CreateRequest.product:type_name -> inventory.Product
+	6,  // 19: inventory.CreateResponse.product:type_name -> inventory.Product
+	6,  // 20: inventory.UpdateRequest.product:type_name -> inventory.Product
+	84, // 21: inventory.UpdateRequest.update_mask:type_name -> google.protobuf.FieldMask
+	6,  // 22: inventory.UpdateResponse.product:type_name -> inventory.Product
+	18, // 23: inventory.BatchUpdateProductsRequest.requests:type_name -> inventory.UpdateRequest
+	6,  // 24: inventory.BatchUpdateResult.product:type_name -> inventory.Product
+	25, // 25: inventory.BatchUpdateProductsResponse.results:type_name -> inventory.BatchUpdateResult
+	28, // 26: inventory.ImportProductsCsvResponse.errors:type_name -> inventory.ImportRowError
+	1,  // 27: inventory.ExportProductsRequest.format:type_name -> inventory.ExportProductsRequest.Format
+	10, // 28: inventory.ExportProductsRequest.filter:type_name -> inventory.ListRequest
+	32, // 29: inventory.ListTagsResponse.tags:type_name -> inventory.Tag
+	32, // 30: inventory.MergeTagsResponse.tag:type_name -> inventory.Tag
+	2,  // 31: inventory.AuditEntry.operation:type_name -> inventory.AuditEntry.Operation
+	37, // 32: inventory.AuditEntry.changes:type_name -> inventory.FieldChange
+	83, // 33: inventory.AuditEntry.created_at:type_name -> google.protobuf.Timestamp
+	83, // 34: inventory.ListAuditEntriesRequest.start_time:type_name -> google.protobuf.Timestamp
+	83, // 35: inventory.ListAuditEntriesRequest.end_time:type_name -> google.protobuf.Timestamp
+	38, // 36: inventory.ListAuditEntriesResponse.entries:type_name -> inventory.AuditEntry
+	3,  // 37: inventory.AdjustStockRequest.reason:type_name -> inventory.AdjustStockRequest.Reason
+	83, // 38: inventory.ReorderSuggestion.computed_at:type_name -> google.protobuf.Timestamp
+	45, // 39: inventory.ListReorderSuggestionsResponse.suggestions:type_name -> inventory.ReorderSuggestion
+	83, // 40: inventory.Lot.created_at:type_name -> google.protobuf.Timestamp
+	48, // 41: inventory.ReceiveLotResponse.lot:type_name -> inventory.Lot
+	48, // 42: inventory.ListExpiringLotsResponse.lots:type_name -> inventory.Lot
+	4,  // 43: inventory.Reservation.status:type_name -> inventory.Reservation.Status
+	83, // 44: inventory.Reservation.created_at:type_name -> google.protobuf.Timestamp
+	83, // 45: inventory.Reservation.expires_at:type_name -> google.protobuf.Timestamp
+	85, // 46: inventory.ReserveStockRequest.ttl:type_name -> google.protobuf.Duration
+	53, // 47: inventory.ReservationResponse.reservation:type_name -> inventory.Reservation
+	82, // 48: inventory.CheckAvailabilityRequest.items:type_name -> inventory.CheckAvailabilityRequest.Item
+	58, // 49: inventory.CheckAvailabilityResponse.items:type_name -> inventory.ItemAvailability
+	5,  // 50: inventory.ProductChange.type:type_name -> inventory.ProductChange.Type
+	6,  // 51: inventory.ProductChange.product:type_name -> inventory.Product
+	7,  // 52: inventory.CreateVariantRequest.variant:type_name -> inventory.Variant
+	7,  // 53: inventory.UpdateVariantRequest.variant:type_name -> inventory.Variant
+	84, // 54: inventory.UpdateVariantRequest.update_mask:type_name -> google.protobuf.FieldMask
+	7,  // 55: inventory.ListVariantsResponse.variants:type_name -> inventory.Variant
+	7,  // 56: inventory.VariantResponse.variant:type_name -> inventory.Variant
+	6,  // 57: inventory.ProductCreated.product:type_name -> inventory.Product
+	83, // 58: inventory.ProductCreated.occurred_at:type_name -> google.protobuf.Timestamp
+	6,  // 59: inventory.ProductUpdated.product:type_name -> inventory.Product
+	83, // 60: inventory.ProductUpdated.occurred_at:type_name -> google.protobuf.Timestamp
+	83, // 61: inventory.ProductDeleted.occurred_at:type_name -> google.protobuf.Timestamp
+	3,  // 62: inventory.StockChanged.reason:type_name -> inventory.AdjustStockRequest.Reason
+	83, // 63: inventory.StockChanged.occurred_at:type_name -> google.protobuf.Timestamp
+	76, // 64: inventory.OrderPlaced.lines:type_name -> inventory.OrderLine
+	83, // 65: inventory.OrderPlaced.occurred_at:type_name -> google.protobuf.Timestamp
+	76, // 66: inventory.OrderCancelled.lines:type_name -> inventory.OrderLine
+	83, // 67: inventory.OrderCancelled.occurred_at:type_name -> google.protobuf.Timestamp
+	10, // 68: inventory.InventoryService.ListProducts:input_type -> inventory.ListRequest
+	12, // 69: inventory.InventoryService.GetProduct:input_type -> inventory.GetRequest
+	13, // 70: inventory.InventoryService.GetProductBySku:input_type -> inventory.GetBySkuRequest
+	14, // 71: inventory.InventoryService.GetProductByBarcode:input_type -> inventory.GetByBarcodeRequest
+	16, // 72: inventory.InventoryService.CreateProduct:input_type -> inventory.CreateRequest
+	18, // 73: inventory.InventoryService.UpdateProduct:input_type -> inventory.UpdateRequest
+	20, // 74: inventory.InventoryService.DeleteProduct:input_type -> inventory.DeleteRequest
+	22, // 75: inventory.InventoryService.BatchDeleteProducts:input_type -> inventory.BatchDeleteRequest
+	24, // 76: inventory.InventoryService.BatchUpdateProducts:input_type -> inventory.BatchUpdateProductsRequest
+	27, // 77: inventory.InventoryService.ImportProductsCsv:input_type -> inventory.ImportProductsCsvRequest
+	30, // 78: inventory.InventoryService.ExportProducts:input_type -> inventory.ExportProductsRequest
+	33, // 79: inventory.InventoryService.ListTags:input_type -> inventory.ListTagsRequest
+	35, // 80: inventory.InventoryService.MergeTags:input_type -> inventory.MergeTagsRequest
+	39, // 81: inventory.InventoryService.ListAuditEntries:input_type -> inventory.ListAuditEntriesRequest
+	41, // 82: inventory.InventoryService.PurchaseProduct:input_type -> inventory.PurchaseRequest
+	43, // 83: inventory.InventoryService.AdjustStock:input_type -> inventory.AdjustStockRequest
+	46, // 84: inventory.InventoryService.ListReorderSuggestions:input_type -> inventory.ListReorderSuggestionsRequest
+	49, // 85: inventory.InventoryService.ReceiveLot:input_type -> inventory.ReceiveLotRequest
+	51, // 86: inventory.InventoryService.ListExpiringLots:input_type -> inventory.ListExpiringLotsRequest
+	54, // 87: inventory.InventoryService.ReserveStock:input_type -> inventory.ReserveStockRequest
+	55, // 88: inventory.InventoryService.ConfirmReservation:input_type -> inventory.ReservationRequest
+	55, // 89: inventory.InventoryService.ReleaseReservation:input_type -> inventory.ReservationRequest
+	55, // 90: inventory.InventoryService.GetReservation:input_type -> inventory.ReservationRequest
+	57, // 91: inventory.InventoryService.CheckAvailability:input_type -> inventory.CheckAvailabilityRequest
+	60, // 92: inventory.InventoryService.WatchProducts:input_type -> inventory.WatchProductsRequest
+	62, // 93: inventory.InventoryService.CreateVariant:input_type -> inventory.CreateVariantRequest
+	63, // 94: inventory.InventoryService.GetVariant:input_type -> inventory.GetVariantRequest
+	64, // 95: inventory.InventoryService.UpdateVariant:input_type -> inventory.UpdateVariantRequest
+	65, // 96: inventory.InventoryService.DeleteVariant:input_type -> inventory.DeleteVariantRequest
+	67, // 97: inventory.InventoryService.ListVariants:input_type -> inventory.ListVariantsRequest
+	70, // 98: inventory.InventoryService.GetServerInfo:input_type -> inventory.GetServerInfoRequest
+	11, // 99: inventory.InventoryService.ListProducts:output_type -> inventory.ListResponse
+	15, // 100: inventory.InventoryService.GetProduct:output_type -> inventory.GetResponse
+	15, // 101: inventory.InventoryService.GetProductBySku:output_type -> inventory.GetResponse
+	15, // 102: inventory.InventoryService.GetProductByBarcode:output_type -> inventory.GetResponse
+	17, // 103: inventory.InventoryService.CreateProduct:output_type -> inventory.CreateResponse
+	19, // 104: inventory.InventoryService.UpdateProduct:output_type -> inventory.UpdateResponse
+	21, // 105: inventory.InventoryService.DeleteProduct:output_type -> inventory.DeleteResponse
+	23, // 106: inventory.InventoryService.BatchDeleteProducts:output_type -> inventory.BatchDeleteResponse
+	26, // 107: inventory.InventoryService.BatchUpdateProducts:output_type -> inventory.BatchUpdateProductsResponse
+	29, // 108: inventory.InventoryService.ImportProductsCsv:output_type -> inventory.ImportProductsCsvResponse
+	31, // 109: inventory.InventoryService.ExportProducts:output_type -> inventory.ExportProductsChunk
+	34, // 110: inventory.InventoryService.ListTags:output_type -> inventory.ListTagsResponse
+	36, // 111: inventory.InventoryService.MergeTags:output_type -> inventory.MergeTagsResponse
+	40, // 112: inventory.InventoryService.ListAuditEntries:output_type -> inventory.ListAuditEntriesResponse
+	42, // 113: inventory.InventoryService.PurchaseProduct:output_type -> inventory.PurchaseResponse
+	44, // 114: inventory.InventoryService.AdjustStock:output_type -> inventory.AdjustStockResponse
+	47, // 115: inventory.InventoryService.ListReorderSuggestions:output_type -> inventory.ListReorderSuggestionsResponse
+	50, // 116: inventory.InventoryService.ReceiveLot:output_type -> inventory.ReceiveLotResponse
+	52, // 117: inventory.InventoryService.ListExpiringLots:output_type -> inventory.ListExpiringLotsResponse
+	56, // 118: inventory.InventoryService.ReserveStock:output_type -> inventory.ReservationResponse
+	56, // 119: inventory.InventoryService.ConfirmReservation:output_type -> inventory.ReservationResponse
+	56, // 120: inventory.InventoryService.ReleaseReservation:output_type -> inventory.ReservationResponse
+	56, // 121: inventory.InventoryService.GetReservation:output_type -> inventory.ReservationResponse
+	59, // 122: inventory.InventoryService.CheckAvailability:output_type -> inventory.CheckAvailabilityResponse
+	61, // 123: inventory.InventoryService.WatchProducts:output_type -> inventory.ProductChange
+	69, // 124: inventory.InventoryService.CreateVariant:output_type -> inventory.VariantResponse
+	69, // 125: inventory.InventoryService.GetVariant:output_type -> inventory.VariantResponse
+	69, // 126: inventory.InventoryService.UpdateVariant:output_type -> inventory.VariantResponse
+	66, // 127: inventory.InventoryService.DeleteVariant:output_type -> inventory.DeleteVariantResponse
+	68, // 128: inventory.InventoryService.ListVariants:output_type -> inventory.ListVariantsResponse
+	71, // 129: inventory.InventoryService.GetServerInfo:output_type -> inventory.ServerInfo
+	99, // [99:130] is the sub-list for method output_type
+	68, // [68:99] is the sub-list for method input_type
+	68, // [68:68] is the sub-list for extension type_name
+	68, // [68:68] is the sub-list for extension extendee
+	0,  // [0:68] is the sub-list for field type_name
 }
 
 func init() { file_inventory_proto_init() }
@@ -724,18 +5728,21 @@ func file_inventory_proto_init() {
 	if File_inventory_proto != nil {
 		return
 	}
+	file_inventory_proto_msgTypes[4].OneofWrappers = []any{}
+	file_inventory_proto_msgTypes[16].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_inventory_proto_rawDesc), len(file_inventory_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   11,
+			NumEnums:      6,
+			NumMessages:   77,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_inventory_proto_goTypes,
 		DependencyIndexes: file_inventory_proto_depIdxs,
+		EnumInfos:         file_inventory_proto_enumTypes,
 		MessageInfos:      file_inventory_proto_msgTypes,
 	}.Build()
 	File_inventory_proto = out.File
@@ -19,11 +19,37 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	InventoryService_ListProducts_FullMethodName  = "/inventory.InventoryService/ListProducts"
-	InventoryService_GetProduct_FullMethodName    = "/inventory.InventoryService/GetProduct"
-	InventoryService_CreateProduct_FullMethodName = "/inventory.InventoryService/CreateProduct"
-	InventoryService_UpdateProduct_FullMethodName = "/inventory.InventoryService/UpdateProduct"
-	InventoryService_DeleteProduct_FullMethodName = "/inventory.InventoryService/DeleteProduct"
+	InventoryService_ListProducts_FullMethodName           = "/inventory.InventoryService/ListProducts"
+	InventoryService_GetProduct_FullMethodName             = "/inventory.InventoryService/GetProduct"
+	InventoryService_GetProductBySku_FullMethodName        = "/inventory.InventoryService/GetProductBySku"
+	InventoryService_GetProductByBarcode_FullMethodName    = "/inventory.InventoryService/GetProductByBarcode"
+	InventoryService_CreateProduct_FullMethodName          = "/inventory.InventoryService/CreateProduct"
+	InventoryService_UpdateProduct_FullMethodName          = "/inventory.InventoryService/UpdateProduct"
+	InventoryService_DeleteProduct_FullMethodName          = "/inventory.InventoryService/DeleteProduct"
+	InventoryService_BatchDeleteProducts_FullMethodName    = "/inventory.InventoryService/BatchDeleteProducts"
+	InventoryService_BatchUpdateProducts_FullMethodName    = "/inventory.InventoryService/BatchUpdateProducts"
+	InventoryService_ImportProductsCsv_FullMethodName      = "/inventory.InventoryService/ImportProductsCsv"
+	InventoryService_ExportProducts_FullMethodName         = "/inventory.InventoryService/ExportProducts"
+	InventoryService_ListTags_FullMethodName               = "/inventory.InventoryService/ListTags"
+	InventoryService_MergeTags_FullMethodName              = "/inventory.InventoryService/MergeTags"
+	InventoryService_ListAuditEntries_FullMethodName       = "/inventory.InventoryService/ListAuditEntries"
+	InventoryService_PurchaseProduct_FullMethodName        = "/inventory.InventoryService/PurchaseProduct"
+	InventoryService_AdjustStock_FullMethodName            = "/inventory.InventoryService/AdjustStock"
+	InventoryService_ListReorderSuggestions_FullMethodName = "/inventory.InventoryService/ListReorderSuggestions"
+	InventoryService_ReceiveLot_FullMethodName             = "/inventory.InventoryService/ReceiveLot"
+	InventoryService_ListExpiringLots_FullMethodName       = "/inventory.InventoryService/ListExpiringLots"
+	InventoryService_ReserveStock_FullMethodName           = "/inventory.InventoryService/ReserveStock"
+	InventoryService_ConfirmReservation_FullMethodName     = "/inventory.InventoryService/ConfirmReservation"
+	InventoryService_ReleaseReservation_FullMethodName     = "/inventory.InventoryService/ReleaseReservation"
+	InventoryService_GetReservation_FullMethodName         = "/inventory.InventoryService/GetReservation"
+	InventoryService_CheckAvailability_FullMethodName      = "/inventory.InventoryService/CheckAvailability"
+	InventoryService_WatchProducts_FullMethodName          = "/inventory.InventoryService/WatchProducts"
+	InventoryService_CreateVariant_FullMethodName          = "/inventory.InventoryService/CreateVariant"
+	InventoryService_GetVariant_FullMethodName             = "/inventory.InventoryService/GetVariant"
+	InventoryService_UpdateVariant_FullMethodName          = "/inventory.InventoryService/UpdateVariant"
+	InventoryService_DeleteVariant_FullMethodName          = "/inventory.InventoryService/DeleteVariant"
+	InventoryService_ListVariants_FullMethodName           = "/inventory.InventoryService/ListVariants"
+	InventoryService_GetServerInfo_FullMethodName          = "/inventory.InventoryService/GetServerInfo"
 )
 
 // InventoryServiceClient is the client API for InventoryService service.
@@ -32,9 +58,97 @@ const (
 type InventoryServiceClient interface {
 	ListProducts(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
 	GetProduct(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error)
+	GetProductBySku(ctx context.Context, in *GetBySkuRequest, opts ...grpc.CallOption) (*GetResponse, error)
+	GetProductByBarcode(ctx context.Context, in *GetByBarcodeRequest, opts ...grpc.CallOption) (*GetResponse, error)
 	CreateProduct(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*CreateResponse, error)
 	UpdateProduct(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*UpdateResponse, error)
 	DeleteProduct(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	BatchDeleteProducts(ctx context.Context, in *BatchDeleteRequest, opts ...grpc.CallOption) (*BatchDeleteResponse, error)
+	// BatchUpdateProducts applies all updates in one transaction: either
+	// every update is applied or none is. The results report each update
+	// in request order.
+	BatchUpdateProducts(ctx context.Context, in *BatchUpdateProductsRequest, opts ...grpc.CallOption) (*BatchUpdateProductsResponse, error)
+	// ImportProductsCsv creates or overwrites products by SKU from a CSV
+	// file; see the README for its columns. Rows are upserted in batches,
+	// each committed on its own, and rows that fail are skipped and
+	// reported. A file without a valid header fails with INVALID_ARGUMENT.
+	ImportProductsCsv(ctx context.Context, in *ImportProductsCsvRequest, opts ...grpc.CallOption) (*ImportProductsCsvResponse, error)
+	// ExportProducts streams the products matching the filter of a
+	// ListProducts request as a CSV or NDJSON file, e.g. for feeds and
+	// backups. The file is read in one transaction, so it is a consistent
+	// snapshot however long the export runs.
+	ExportProducts(ctx context.Context, in *ExportProductsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportProductsChunk], error)
+	// ListTags returns the tags of the registry and of products with the
+	// number of products of each, ordered by name. Tags are matched
+	// case-insensitively: writes and filters use the registered name of a
+	// tag or alias, and other tags in lower case.
+	ListTags(ctx context.Context, in *ListTagsRequest, opts ...grpc.CallOption) (*ListTagsResponse, error)
+	// MergeTags registers a tag in its casing and merges other tags into
+	// it: products with any of them are retagged, and the merged tags
+	// become aliases of the tag for later writes and filters.
+	MergeTags(ctx context.Context, in *MergeTagsRequest, opts ...grpc.CallOption) (*MergeTagsResponse, error)
+	// ListAuditEntries returns the recorded changes of products, newest
+	// first: who changed which fields, through which RPC and when. Every
+	// committed change of a product is recorded, including bulk deletes,
+	// imports and background jobs.
+	ListAuditEntries(ctx context.Context, in *ListAuditEntriesRequest, opts ...grpc.CallOption) (*ListAuditEntriesResponse, error)
+	// PurchaseProduct atomically takes quantity units out of stock. With
+	// fewer units left it fails with FAILED_PRECONDITION and an ErrorInfo
+	// (reason INSUFFICIENT_STOCK) whose "available" metadata holds the
+	// remaining quantity; nothing changes then.
+	PurchaseProduct(ctx context.Context, in *PurchaseRequest, opts ...grpc.CallOption) (*PurchaseResponse, error)
+	// AdjustStock changes the quantity of a product by a signed delta for
+	// a reason, e.g. received goods or damaged units, and records the
+	// movement in the stock ledger in the same transaction. The quantity
+	// never goes negative; that fails like PurchaseProduct.
+	AdjustStock(ctx context.Context, in *AdjustStockRequest, opts ...grpc.CallOption) (*AdjustStockResponse, error)
+	// ListReorderSuggestions returns the active products at or below their
+	// reorder point, the soonest to run out first, with the quantity to
+	// order to cover their recent outflow. Suggestions are computed
+	// periodically, not on request; see ReorderSuggestion.computed_at.
+	ListReorderSuggestions(ctx context.Context, in *ListReorderSuggestionsRequest, opts ...grpc.CallOption) (*ListReorderSuggestionsResponse, error)
+	// ReceiveLot books received units of a product to a lot with its
+	// expiry date and records them in the stock ledger as RECEIVED. The
+	// first receipt of a lot number creates the lot; later ones add to it
+	// and must give the same expiry date. Decrements of the quantity take
+	// units from the lots first expiring first (FEFO).
+	ReceiveLot(ctx context.Context, in *ReceiveLotRequest, opts ...grpc.CallOption) (*ReceiveLotResponse, error)
+	// ListExpiringLots returns the lots with units left that expire within
+	// a number of days, already expired ones included, the soonest first.
+	ListExpiringLots(ctx context.Context, in *ListExpiringLotsRequest, opts ...grpc.CallOption) (*ListExpiringLotsResponse, error)
+	// ReserveStock holds quantity units of a product for an order until
+	// the reservation expires. Units held by active reservations cannot be
+	// reserved again; with too few free units it fails like
+	// PurchaseProduct.
+	ReserveStock(ctx context.Context, in *ReserveStockRequest, opts ...grpc.CallOption) (*ReservationResponse, error)
+	// ConfirmReservation takes the reserved units out of stock. Confirming
+	// twice is a no-op; released and expired reservations fail with
+	// FAILED_PRECONDITION.
+	ConfirmReservation(ctx context.Context, in *ReservationRequest, opts ...grpc.CallOption) (*ReservationResponse, error)
+	// ReleaseReservation frees the reserved units, e.g. for a cancelled
+	// order. Releasing twice is a no-op; confirmed reservations fail with
+	// FAILED_PRECONDITION.
+	ReleaseReservation(ctx context.Context, in *ReservationRequest, opts ...grpc.CallOption) (*ReservationResponse, error)
+	GetReservation(ctx context.Context, in *ReservationRequest, opts ...grpc.CallOption) (*ReservationResponse, error)
+	// CheckAvailability reports for each item whether its quantity can be
+	// bought now, reading all products in one query. Items are answered in
+	// request order and independently of each other.
+	CheckAvailability(ctx context.Context, in *CheckAvailabilityRequest, opts ...grpc.CallOption) (*CheckAvailabilityResponse, error)
+	// WatchProducts streams the product changes committed from now on, by
+	// any instance of the service. A RESET change means changes were lost,
+	// e.g. while the database connection was down or the client fell
+	// behind; the client should reload what it keeps in sync.
+	WatchProducts(ctx context.Context, in *WatchProductsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ProductChange], error)
+	// CreateVariant adds a variant to a product, e.g. a size and color of
+	// a shirt. Each combination of options exists once per product.
+	CreateVariant(ctx context.Context, in *CreateVariantRequest, opts ...grpc.CallOption) (*VariantResponse, error)
+	GetVariant(ctx context.Context, in *GetVariantRequest, opts ...grpc.CallOption) (*VariantResponse, error)
+	UpdateVariant(ctx context.Context, in *UpdateVariantRequest, opts ...grpc.CallOption) (*VariantResponse, error)
+	DeleteVariant(ctx context.Context, in *DeleteVariantRequest, opts ...grpc.CallOption) (*DeleteVariantResponse, error)
+	// ListVariants returns the variants of a product ordered by SKU.
+	ListVariants(ctx context.Context, in *ListVariantsRequest, opts ...grpc.CallOption) (*ListVariantsResponse, error)
+	// GetServerInfo returns the build of the serving instance.
+	GetServerInfo(ctx context.Context, in *GetServerInfoRequest, opts ...grpc.CallOption) (*ServerInfo, error)
 }
 
 type inventoryServiceClient struct {
@@ -65,6 +179,26 @@ func (c *inventoryServiceClient) GetProduct(ctx context.Context, in *GetRequest,
 	return out, nil
 }
 
+func (c *inventoryServiceClient) GetProductBySku(ctx context.Context, in *GetBySkuRequest, opts ...grpc.CallOption) (*GetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetResponse)
+	err := c.cc.Invoke(ctx, InventoryService_GetProductBySku_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) GetProductByBarcode(ctx context.Context, in *GetByBarcodeRequest, opts ...grpc.CallOption) (*GetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetResponse)
+	err := c.cc.Invoke(ctx, InventoryService_GetProductByBarcode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *inventoryServiceClient) CreateProduct(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*CreateResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(CreateResponse)
@@ -95,15 +229,361 @@ func (c *inventoryServiceClient) DeleteProduct(ctx context.Context, in *DeleteRe
 	return out, nil
 }
 
+func (c *inventoryServiceClient) BatchDeleteProducts(ctx context.Context, in *BatchDeleteRequest, opts ...grpc.CallOption) (*BatchDeleteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchDeleteResponse)
+	err := c.cc.Invoke(ctx, InventoryService_BatchDeleteProducts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) BatchUpdateProducts(ctx context.Context, in *BatchUpdateProductsRequest, opts ...grpc.CallOption) (*BatchUpdateProductsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchUpdateProductsResponse)
+	err := c.cc.Invoke(ctx, InventoryService_BatchUpdateProducts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) ImportProductsCsv(ctx context.Context, in *ImportProductsCsvRequest, opts ...grpc.CallOption) (*ImportProductsCsvResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ImportProductsCsvResponse)
+	err := c.cc.Invoke(ctx, InventoryService_ImportProductsCsv_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) ExportProducts(ctx context.Context, in *ExportProductsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportProductsChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &InventoryService_ServiceDesc.Streams[0], InventoryService_ExportProducts_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExportProductsRequest, ExportProductsChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type InventoryService_ExportProductsClient = grpc.ServerStreamingClient[ExportProductsChunk]
+
+func (c *inventoryServiceClient) ListTags(ctx context.Context, in *ListTagsRequest, opts ...grpc.CallOption) (*ListTagsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTagsResponse)
+	err := c.cc.Invoke(ctx, InventoryService_ListTags_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) MergeTags(ctx context.Context, in *MergeTagsRequest, opts ...grpc.CallOption) (*MergeTagsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MergeTagsResponse)
+	err := c.cc.Invoke(ctx, InventoryService_MergeTags_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) ListAuditEntries(ctx context.Context, in *ListAuditEntriesRequest, opts ...grpc.CallOption) (*ListAuditEntriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListAuditEntriesResponse)
+	err := c.cc.Invoke(ctx, InventoryService_ListAuditEntries_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) PurchaseProduct(ctx context.Context, in *PurchaseRequest, opts ...grpc.CallOption) (*PurchaseResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PurchaseResponse)
+	err := c.cc.Invoke(ctx, InventoryService_PurchaseProduct_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) AdjustStock(ctx context.Context, in *AdjustStockRequest, opts ...grpc.CallOption) (*AdjustStockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AdjustStockResponse)
+	err := c.cc.Invoke(ctx, InventoryService_AdjustStock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) ListReorderSuggestions(ctx context.Context, in *ListReorderSuggestionsRequest, opts ...grpc.CallOption) (*ListReorderSuggestionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListReorderSuggestionsResponse)
+	err := c.cc.Invoke(ctx, InventoryService_ListReorderSuggestions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) ReceiveLot(ctx context.Context, in *ReceiveLotRequest, opts ...grpc.CallOption) (*ReceiveLotResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReceiveLotResponse)
+	err := c.cc.Invoke(ctx, InventoryService_ReceiveLot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) ListExpiringLots(ctx context.Context, in *ListExpiringLotsRequest, opts ...grpc.CallOption) (*ListExpiringLotsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListExpiringLotsResponse)
+	err := c.cc.Invoke(ctx, InventoryService_ListExpiringLots_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) ReserveStock(ctx context.Context, in *ReserveStockRequest, opts ...grpc.CallOption) (*ReservationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReservationResponse)
+	err := c.cc.Invoke(ctx, InventoryService_ReserveStock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) ConfirmReservation(ctx context.Context, in *ReservationRequest, opts ...grpc.CallOption) (*ReservationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReservationResponse)
+	err := c.cc.Invoke(ctx, InventoryService_ConfirmReservation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) ReleaseReservation(ctx context.Context, in *ReservationRequest, opts ...grpc.CallOption) (*ReservationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReservationResponse)
+	err := c.cc.Invoke(ctx, InventoryService_ReleaseReservation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) GetReservation(ctx context.Context, in *ReservationRequest, opts ...grpc.CallOption) (*ReservationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReservationResponse)
+	err := c.cc.Invoke(ctx, InventoryService_GetReservation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) CheckAvailability(ctx context.Context, in *CheckAvailabilityRequest, opts ...grpc.CallOption) (*CheckAvailabilityResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckAvailabilityResponse)
+	err := c.cc.Invoke(ctx, InventoryService_CheckAvailability_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) WatchProducts(ctx context.Context, in *WatchProductsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ProductChange], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &InventoryService_ServiceDesc.Streams[1], InventoryService_WatchProducts_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchProductsRequest, ProductChange]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type InventoryService_WatchProductsClient = grpc.ServerStreamingClient[ProductChange]
+
+func (c *inventoryServiceClient) CreateVariant(ctx context.Context, in *CreateVariantRequest, opts ...grpc.CallOption) (*VariantResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VariantResponse)
+	err := c.cc.Invoke(ctx, InventoryService_CreateVariant_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) GetVariant(ctx context.Context, in *GetVariantRequest, opts ...grpc.CallOption) (*VariantResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VariantResponse)
+	err := c.cc.Invoke(ctx, InventoryService_GetVariant_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) UpdateVariant(ctx context.Context, in *UpdateVariantRequest, opts ...grpc.CallOption) (*VariantResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VariantResponse)
+	err := c.cc.Invoke(ctx, InventoryService_UpdateVariant_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) DeleteVariant(ctx context.Context, in *DeleteVariantRequest, opts ...grpc.CallOption) (*DeleteVariantResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteVariantResponse)
+	err := c.cc.Invoke(ctx, InventoryService_DeleteVariant_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) ListVariants(ctx context.Context, in *ListVariantsRequest, opts ...grpc.CallOption) (*ListVariantsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListVariantsResponse)
+	err := c.cc.Invoke(ctx, InventoryService_ListVariants_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) GetServerInfo(ctx context.Context, in *GetServerInfoRequest, opts ...grpc.CallOption) (*ServerInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ServerInfo)
+	err := c.cc.Invoke(ctx, InventoryService_GetServerInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // InventoryServiceServer is the server API for InventoryService service.
 // All implementations must embed UnimplementedInventoryServiceServer
 // for forward compatibility.
 type InventoryServiceServer interface {
 	ListProducts(context.Context, *ListRequest) (*ListResponse, error)
 	GetProduct(context.Context, *GetRequest) (*GetResponse, error)
+	GetProductBySku(context.Context, *GetBySkuRequest) (*GetResponse, error)
+	GetProductByBarcode(context.Context, *GetByBarcodeRequest) (*GetResponse, error)
 	CreateProduct(context.Context, *CreateRequest) (*CreateResponse, error)
 	UpdateProduct(context.Context, *UpdateRequest) (*UpdateResponse, error)
 	DeleteProduct(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	BatchDeleteProducts(context.Context, *BatchDeleteRequest) (*BatchDeleteResponse, error)
+	// BatchUpdateProducts applies all updates in one transaction: either
+	// every update is applied or none is. The results report each update
+	// in request order.
+	BatchUpdateProducts(context.Context, *BatchUpdateProductsRequest) (*BatchUpdateProductsResponse, error)
+	// ImportProductsCsv creates or overwrites products by SKU from a CSV
+	// file; see the README for its columns. Rows are upserted in batches,
+	// each committed on its own, and rows that fail are skipped and
+	// reported. A file without a valid header fails with INVALID_ARGUMENT.
+	ImportProductsCsv(context.Context, *ImportProductsCsvRequest) (*ImportProductsCsvResponse, error)
+	// ExportProducts streams the products matching the filter of a
+	// ListProducts request as a CSV or NDJSON file, e.g. for feeds and
+	// backups. The file is read in one transaction, so it is a consistent
+	// snapshot however long the export runs.
+	ExportProducts(*ExportProductsRequest, grpc.ServerStreamingServer[ExportProductsChunk]) error
+	// ListTags returns the tags of the registry and of products with the
+	// number of products of each, ordered by name. Tags are matched
+	// case-insensitively: writes and filters use the registered name of a
+	// tag or alias, and other tags in lower case.
+	ListTags(context.Context, *ListTagsRequest) (*ListTagsResponse, error)
+	// MergeTags registers a tag in its casing and merges other tags into
+	// it: products with any of them are retagged, and the merged tags
+	// become aliases of the tag for later writes and filters.
+	MergeTags(context.Context, *MergeTagsRequest) (*MergeTagsResponse, error)
+	// ListAuditEntries returns the recorded changes of products, newest
+	// first: who changed which fields, through which RPC and when. Every
+	// committed change of a product is recorded, including bulk deletes,
+	// imports and background jobs.
+	ListAuditEntries(context.Context, *ListAuditEntriesRequest) (*ListAuditEntriesResponse, error)
+	// PurchaseProduct atomically takes quantity units out of stock. With
+	// fewer units left it fails with FAILED_PRECONDITION and an ErrorInfo
+	// (reason INSUFFICIENT_STOCK) whose "available" metadata holds the
+	// remaining quantity; nothing changes then.
+	PurchaseProduct(context.Context, *PurchaseRequest) (*PurchaseResponse, error)
+	// AdjustStock changes the quantity of a product by a signed delta for
+	// a reason, e.g. received goods or damaged units, and records the
+	// movement in the stock ledger in the same transaction. The quantity
+	// never goes negative; that fails like PurchaseProduct.
+	AdjustStock(context.Context, *AdjustStockRequest) (*AdjustStockResponse, error)
+	// ListReorderSuggestions returns the active products at or below their
+	// reorder point, the soonest to run out first, with the quantity to
+	// order to cover their recent outflow. Suggestions are computed
+	// periodically, not on request; see ReorderSuggestion.computed_at.
+	ListReorderSuggestions(context.Context, *ListReorderSuggestionsRequest) (*ListReorderSuggestionsResponse, error)
+	// ReceiveLot books received units of a product to a lot with its
+	// expiry date and records them in the stock ledger as RECEIVED. The
+	// first receipt of a lot number creates the lot; later ones add to it
+	// and must give the same expiry date. Decrements of the quantity take
+	// units from the lots first expiring first (FEFO).
+	ReceiveLot(context.Context, *ReceiveLotRequest) (*ReceiveLotResponse, error)
+	// ListExpiringLots returns the lots with units left that expire within
+	// a number of days, already expired ones included, the soonest first.
+	ListExpiringLots(context.Context, *ListExpiringLotsRequest) (*ListExpiringLotsResponse, error)
+	// ReserveStock holds quantity units of a product for an order until
+	// the reservation expires. Units held by active reservations cannot be
+	// reserved again; with too few free units it fails like
+	// PurchaseProduct.
+	ReserveStock(context.Context, *ReserveStockRequest) (*ReservationResponse, error)
+	// ConfirmReservation takes the reserved units out of stock. Confirming
+	// twice is a no-op; released and expired reservations fail with
+	// FAILED_PRECONDITION.
+	ConfirmReservation(context.Context, *ReservationRequest) (*ReservationResponse, error)
+	// ReleaseReservation frees the reserved units, e.g. for a cancelled
+	// order. Releasing twice is a no-op; confirmed reservations fail with
+	// FAILED_PRECONDITION.
+	ReleaseReservation(context.Context, *ReservationRequest) (*ReservationResponse, error)
+	GetReservation(context.Context, *ReservationRequest) (*ReservationResponse, error)
+	// CheckAvailability reports for each item whether its quantity can be
+	// bought now, reading all products in one query. Items are answered in
+	// request order and independently of each other.
+	CheckAvailability(context.Context, *CheckAvailabilityRequest) (*CheckAvailabilityResponse, error)
+	// WatchProducts streams the product changes committed from now on, by
+	// any instance of the service. A RESET change means changes were lost,
+	// e.g. while the database connection was down or the client fell
+	// behind; the client should reload what it keeps in sync.
+	WatchProducts(*WatchProductsRequest, grpc.ServerStreamingServer[ProductChange]) error
+	// CreateVariant adds a variant to a product, e.g. a size and color of
+	// a shirt. Each combination of options exists once per product.
+	CreateVariant(context.Context, *CreateVariantRequest) (*VariantResponse, error)
+	GetVariant(context.Context, *GetVariantRequest) (*VariantResponse, error)
+	UpdateVariant(context.Context, *UpdateVariantRequest) (*VariantResponse, error)
+	DeleteVariant(context.Context, *DeleteVariantRequest) (*DeleteVariantResponse, error)
+	// ListVariants returns the variants of a product ordered by SKU.
+	ListVariants(context.Context, *ListVariantsRequest) (*ListVariantsResponse, error)
+	// GetServerInfo returns the build of the serving instance.
+	GetServerInfo(context.Context, *GetServerInfoRequest) (*ServerInfo, error)
 	mustEmbedUnimplementedInventoryServiceServer()
 }
 
@@ -120,6 +600,12 @@ func (UnimplementedInventoryServiceServer) ListProducts(context.Context, *ListRe
 func (UnimplementedInventoryServiceServer) GetProduct(context.Context, *GetRequest) (*GetResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetProduct not implemented")
 }
+func (UnimplementedInventoryServiceServer) GetProductBySku(context.Context, *GetBySkuRequest) (*GetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProductBySku not implemented")
+}
+func (UnimplementedInventoryServiceServer) GetProductByBarcode(context.Context, *GetByBarcodeRequest) (*GetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProductByBarcode not implemented")
+}
 func (UnimplementedInventoryServiceServer) CreateProduct(context.Context, *CreateRequest) (*CreateResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateProduct not implemented")
 }
@@ -129,6 +615,78 @@ func (UnimplementedInventoryServiceServer) UpdateProduct(context.Context, *Updat
 func (UnimplementedInventoryServiceServer) DeleteProduct(context.Context, *DeleteRequest) (*DeleteResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteProduct not implemented")
 }
+func (UnimplementedInventoryServiceServer) BatchDeleteProducts(context.Context, *BatchDeleteRequest) (*BatchDeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchDeleteProducts not implemented")
+}
+func (UnimplementedInventoryServiceServer) BatchUpdateProducts(context.Context, *BatchUpdateProductsRequest) (*BatchUpdateProductsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchUpdateProducts not implemented")
+}
+func (UnimplementedInventoryServiceServer) ImportProductsCsv(context.Context, *ImportProductsCsvRequest) (*ImportProductsCsvResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ImportProductsCsv not implemented")
+}
+func (UnimplementedInventoryServiceServer) ExportProducts(*ExportProductsRequest, grpc.ServerStreamingServer[ExportProductsChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method ExportProducts not implemented")
+}
+func (UnimplementedInventoryServiceServer) ListTags(context.Context, *ListTagsRequest) (*ListTagsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTags not implemented")
+}
+func (UnimplementedInventoryServiceServer) MergeTags(context.Context, *MergeTagsRequest) (*MergeTagsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MergeTags not implemented")
+}
+func (UnimplementedInventoryServiceServer) ListAuditEntries(context.Context, *ListAuditEntriesRequest) (*ListAuditEntriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAuditEntries not implemented")
+}
+func (UnimplementedInventoryServiceServer) PurchaseProduct(context.Context, *PurchaseRequest) (*PurchaseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurchaseProduct not implemented")
+}
+func (UnimplementedInventoryServiceServer) AdjustStock(context.Context, *AdjustStockRequest) (*AdjustStockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AdjustStock not implemented")
+}
+func (UnimplementedInventoryServiceServer) ListReorderSuggestions(context.Context, *ListReorderSuggestionsRequest) (*ListReorderSuggestionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListReorderSuggestions not implemented")
+}
+func (UnimplementedInventoryServiceServer) ReceiveLot(context.Context, *ReceiveLotRequest) (*ReceiveLotResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReceiveLot not implemented")
+}
+func (UnimplementedInventoryServiceServer) ListExpiringLots(context.Context, *ListExpiringLotsRequest) (*ListExpiringLotsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListExpiringLots not implemented")
+}
+func (UnimplementedInventoryServiceServer) ReserveStock(context.Context, *ReserveStockRequest) (*ReservationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReserveStock not implemented")
+}
+func (UnimplementedInventoryServiceServer) ConfirmReservation(context.Context, *ReservationRequest) (*ReservationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfirmReservation not implemented")
+}
+func (UnimplementedInventoryServiceServer) ReleaseReservation(context.Context, *ReservationRequest) (*ReservationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReleaseReservation not implemented")
+}
+func (UnimplementedInventoryServiceServer) GetReservation(context.Context, *ReservationRequest) (*ReservationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReservation not implemented")
+}
+func (UnimplementedInventoryServiceServer) CheckAvailability(context.Context, *CheckAvailabilityRequest) (*CheckAvailabilityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckAvailability not implemented")
+}
+func (UnimplementedInventoryServiceServer) WatchProducts(*WatchProductsRequest, grpc.ServerStreamingServer[ProductChange]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchProducts not implemented")
+}
+func (UnimplementedInventoryServiceServer) CreateVariant(context.Context, *CreateVariantRequest) (*VariantResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateVariant not implemented")
+}
+func (UnimplementedInventoryServiceServer) GetVariant(context.Context, *GetVariantRequest) (*VariantResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVariant not implemented")
+}
+func (UnimplementedInventoryServiceServer) UpdateVariant(context.Context, *UpdateVariantRequest) (*VariantResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateVariant not implemented")
+}
+func (UnimplementedInventoryServiceServer) DeleteVariant(context.Context, *DeleteVariantRequest) (*DeleteVariantResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteVariant not implemented")
+}
+func (UnimplementedInventoryServiceServer) ListVariants(context.Context, *ListVariantsRequest) (*ListVariantsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListVariants not implemented")
+}
+func (UnimplementedInventoryServiceServer) GetServerInfo(context.Context, *GetServerInfoRequest) (*ServerInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetServerInfo not implemented")
+}
 func (UnimplementedInventoryServiceServer) mustEmbedUnimplementedInventoryServiceServer() {}
 func (UnimplementedInventoryServiceServer) testEmbeddedByValue()                          {}
 
@@ -186,6 +744,42 @@ func _InventoryService_GetProduct_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _InventoryService_GetProductBySku_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBySkuRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).GetProductBySku(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_GetProductBySku_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).GetProductBySku(ctx, req.(*GetBySkuRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_GetProductByBarcode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetByBarcodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).GetProductByBarcode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_GetProductByBarcode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).GetProductByBarcode(ctx, req.(*GetByBarcodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _InventoryService_CreateProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CreateRequest)
 	if err := dec(in); err != nil {
@@ -240,21 +834,447 @@ func _InventoryService_DeleteProduct_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
-// InventoryService_ServiceDesc is the grpc.ServiceDesc for InventoryService service.
-// It's only intended for direct use with grpc.RegisterService,
-// and not to be introspected or modified (even as a copy)
-var InventoryService_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "inventory.InventoryService",
-	HandlerType: (*InventoryServiceServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "ListProducts",
-			Handler:    _InventoryService_ListProducts_Handler,
-		},
-		{
+func _InventoryService_BatchDeleteProducts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchDeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).BatchDeleteProducts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_BatchDeleteProducts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).BatchDeleteProducts(ctx, req.(*BatchDeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_BatchUpdateProducts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchUpdateProductsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).BatchUpdateProducts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_BatchUpdateProducts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).BatchUpdateProducts(ctx, req.(*BatchUpdateProductsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_ImportProductsCsv_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportProductsCsvRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).ImportProductsCsv(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_ImportProductsCsv_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).ImportProductsCsv(ctx, req.(*ImportProductsCsvRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_ExportProducts_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportProductsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(InventoryServiceServer).ExportProducts(m, &grpc.GenericServerStream[ExportProductsRequest, ExportProductsChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type InventoryService_ExportProductsServer = grpc.ServerStreamingServer[ExportProductsChunk]
+
+func _InventoryService_ListTags_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTagsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).ListTags(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_ListTags_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).ListTags(ctx, req.(*ListTagsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_MergeTags_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MergeTagsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).MergeTags(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_MergeTags_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).MergeTags(ctx, req.(*MergeTagsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_ListAuditEntries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAuditEntriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).ListAuditEntries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_ListAuditEntries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).ListAuditEntries(ctx, req.(*ListAuditEntriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_PurchaseProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurchaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).PurchaseProduct(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_PurchaseProduct_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).PurchaseProduct(ctx, req.(*PurchaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_AdjustStock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdjustStockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).AdjustStock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_AdjustStock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).AdjustStock(ctx, req.(*AdjustStockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_ListReorderSuggestions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListReorderSuggestionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).ListReorderSuggestions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_ListReorderSuggestions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).ListReorderSuggestions(ctx, req.(*ListReorderSuggestionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_ReceiveLot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReceiveLotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).ReceiveLot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_ReceiveLot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).ReceiveLot(ctx, req.(*ReceiveLotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_ListExpiringLots_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListExpiringLotsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).ListExpiringLots(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_ListExpiringLots_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).ListExpiringLots(ctx, req.(*ListExpiringLotsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_ReserveStock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReserveStockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).ReserveStock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_ReserveStock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).ReserveStock(ctx, req.(*ReserveStockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_ConfirmReservation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReservationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).ConfirmReservation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_ConfirmReservation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).ConfirmReservation(ctx, req.(*ReservationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_ReleaseReservation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReservationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).ReleaseReservation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_ReleaseReservation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).ReleaseReservation(ctx, req.(*ReservationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_GetReservation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReservationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).GetReservation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_GetReservation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).GetReservation(ctx, req.(*ReservationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_CheckAvailability_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckAvailabilityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).CheckAvailability(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_CheckAvailability_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).CheckAvailability(ctx, req.(*CheckAvailabilityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_WatchProducts_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchProductsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(InventoryServiceServer).WatchProducts(m, &grpc.GenericServerStream[WatchProductsRequest, ProductChange]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type InventoryService_WatchProductsServer = grpc.ServerStreamingServer[ProductChange]
+
+func _InventoryService_CreateVariant_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateVariantRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).CreateVariant(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_CreateVariant_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).CreateVariant(ctx, req.(*CreateVariantRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_GetVariant_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetVariantRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).GetVariant(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_GetVariant_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).GetVariant(ctx, req.(*GetVariantRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_UpdateVariant_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateVariantRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).UpdateVariant(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_UpdateVariant_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).UpdateVariant(ctx, req.(*UpdateVariantRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_DeleteVariant_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteVariantRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).DeleteVariant(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_DeleteVariant_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).DeleteVariant(ctx, req.(*DeleteVariantRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_ListVariants_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListVariantsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).ListVariants(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_ListVariants_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).ListVariants(ctx, req.(*ListVariantsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_GetServerInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetServerInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).GetServerInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_GetServerInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).GetServerInfo(ctx, req.(*GetServerInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// InventoryService_ServiceDesc is the grpc.ServiceDesc for InventoryService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var InventoryService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "inventory.InventoryService",
+	HandlerType: (*InventoryServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListProducts",
+			Handler:    _InventoryService_ListProducts_Handler,
+		},
+		{
 			MethodName: "GetProduct",
 			Handler:    _InventoryService_GetProduct_Handler,
 		},
+		{
+			MethodName: "GetProductBySku",
+			Handler:    _InventoryService_GetProductBySku_Handler,
+		},
+		{
+			MethodName: "GetProductByBarcode",
+			Handler:    _InventoryService_GetProductByBarcode_Handler,
+		},
 		{
 			MethodName: "CreateProduct",
 			Handler:    _InventoryService_CreateProduct_Handler,
@@ -267,7 +1287,106 @@ var InventoryService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteProduct",
 			Handler:    _InventoryService_DeleteProduct_Handler,
 		},
+		{
+			MethodName: "BatchDeleteProducts",
+			Handler:    _InventoryService_BatchDeleteProducts_Handler,
+		},
+		{
+			MethodName: "BatchUpdateProducts",
+			Handler:    _InventoryService_BatchUpdateProducts_Handler,
+		},
+		{
+			MethodName: "ImportProductsCsv",
+			Handler:    _InventoryService_ImportProductsCsv_Handler,
+		},
+		{
+			MethodName: "ListTags",
+			Handler:    _InventoryService_ListTags_Handler,
+		},
+		{
+			MethodName: "MergeTags",
+			Handler:    _InventoryService_MergeTags_Handler,
+		},
+		{
+			MethodName: "ListAuditEntries",
+			Handler:    _InventoryService_ListAuditEntries_Handler,
+		},
+		{
+			MethodName: "PurchaseProduct",
+			Handler:    _InventoryService_PurchaseProduct_Handler,
+		},
+		{
+			MethodName: "AdjustStock",
+			Handler:    _InventoryService_AdjustStock_Handler,
+		},
+		{
+			MethodName: "ListReorderSuggestions",
+			Handler:    _InventoryService_ListReorderSuggestions_Handler,
+		},
+		{
+			MethodName: "ReceiveLot",
+			Handler:    _InventoryService_ReceiveLot_Handler,
+		},
+		{
+			MethodName: "ListExpiringLots",
+			Handler:    _InventoryService_ListExpiringLots_Handler,
+		},
+		{
+			MethodName: "ReserveStock",
+			Handler:    _InventoryService_ReserveStock_Handler,
+		},
+		{
+			MethodName: "ConfirmReservation",
+			Handler:    _InventoryService_ConfirmReservation_Handler,
+		},
+		{
+			MethodName: "ReleaseReservation",
+			Handler:    _InventoryService_ReleaseReservation_Handler,
+		},
+		{
+			MethodName: "GetReservation",
+			Handler:    _InventoryService_GetReservation_Handler,
+		},
+		{
+			MethodName: "CheckAvailability",
+			Handler:    _InventoryService_CheckAvailability_Handler,
+		},
+		{
+			MethodName: "CreateVariant",
+			Handler:    _InventoryService_CreateVariant_Handler,
+		},
+		{
+			MethodName: "GetVariant",
+			Handler:    _InventoryService_GetVariant_Handler,
+		},
+		{
+			MethodName: "UpdateVariant",
+			Handler:    _InventoryService_UpdateVariant_Handler,
+		},
+		{
+			MethodName: "DeleteVariant",
+			Handler:    _InventoryService_DeleteVariant_Handler,
+		},
+		{
+			MethodName: "ListVariants",
+			Handler:    _InventoryService_ListVariants_Handler,
+		},
+		{
+			MethodName: "GetServerInfo",
+			Handler:    _InventoryService_GetServerInfo_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExportProducts",
+			Handler:       _InventoryService_ExportProducts_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchProducts",
+			Handler:       _InventoryService_WatchProducts_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "inventory.proto",
 }